@@ -21,6 +21,15 @@ func (a *arrayObject) init() {
 
 	a._put("length", &a.lengthProp)
 }
+// 在末尾追加一个值，nil表示空位，用于数组字面量
+func (a *arrayObject) appendValue(v Value) {
+	a.val.runtime.allocate(sizeofValue)
+	a.values = append(a.values, v)
+	a.length++
+	if v != nil {
+		a.objCount++
+	}
+}
 // 设置长度
 func (a *arrayObject) _setLengthInt(l int64, throw bool) bool {
 	if l >= 0 && l <= math.MaxUint32 {
@@ -359,6 +368,7 @@ func (a *arrayObject) expand(idx int64) bool {
 						}
 					}
 				}
+				a.val.runtime.allocate(int(newcap-int64(cap(a.values))) * sizeofValue)
 				newValues := make([]Value, targetLen, newcap)
 				copy(newValues, a.values)
 				a.values = newValues
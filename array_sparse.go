@@ -194,6 +194,7 @@ func (a *sparseArrayObject) putIdx(idx int64, val Value, throw bool, origNameStr
 		}
 
 		if a.expand() {
+			a.val.runtime.allocate(sizeofSparseItem)
 			a.items = append(a.items, sparseArrayItem{})
 			copy(a.items[i+1:], a.items[i:])
 			a.items[i] = sparseArrayItem{
@@ -348,6 +349,7 @@ func (a *sparseArrayObject) defineOwnProperty(n Value, descr propertyDescr, thro
 			}
 			if i >= len(a.items) || a.items[i].idx != idx {
 				if a.expand() {
+					a.val.runtime.allocate(sizeofSparseItem)
 					a.items = append(a.items, sparseArrayItem{})
 					copy(a.items[i+1:], a.items[i:])
 					a.items[i] = sparseArrayItem{
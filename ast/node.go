@@ -44,6 +44,32 @@ type (
 		Value        []Expression
 	}
 
+	// 数组解构模式[a, , b = 1, ...rest]，Elements中的nil表示空位
+	ArrayPattern struct {
+		LeftBracket  file.Idx
+		RightBracket file.Idx
+		Elements     []*Binding
+		Rest         Expression
+	}
+
+	// 箭头函数
+	ArrowFunctionLiteral struct {
+		Start         file.Idx
+		ParameterList *ParameterList
+		Body          ConciseBody
+		Source        string
+		// async箭头函数，Start是async的位置
+		Async bool
+
+		DeclarationList []Declaration
+	}
+
+	// async函数中的await expr
+	AwaitExpression struct {
+		Await    file.Idx
+		Argument Expression
+	}
+
 	AssignExpression struct {
 		Operator token.Token
 		Left     Expression
@@ -55,6 +81,13 @@ type (
 		To   file.Idx
 	}
 
+	// 解构模式中的一个目标及其默认值。声明和参数中Target为标识符或嵌套的模式，
+	// 赋值中还可以是成员表达式
+	Binding struct {
+		Target      Expression
+		Initializer Expression
+	}
+
 	BinaryExpression struct {
 		Operator   token.Token
 		Left       Expression
@@ -82,6 +115,16 @@ type (
 		RightParenthesis file.Idx
 	}
 
+	// 类，用于类声明和类表达式
+	ClassLiteral struct {
+		Class      file.Idx
+		Name       *Identifier
+		SuperClass Expression
+		Body       []*MethodDefinition
+		RightBrace file.Idx
+		Source     string
+	}
+
 	ConditionalExpression struct {
 		Test       Expression
 		Consequent Expression
@@ -99,6 +142,10 @@ type (
 		ParameterList *ParameterList
 		Body          Statement
 		Source        string
+		// function*声明的生成器函数
+		Generator bool
+		// async函数，Function是async的位置
+		Async bool
 
 		DeclarationList []Declaration
 	}
@@ -108,6 +155,24 @@ type (
 		Idx  file.Idx
 	}
 
+	// import(specifier)形式的动态导入
+	ImportCall struct {
+		Import           file.Idx
+		Argument         Expression
+		RightParenthesis file.Idx
+	}
+
+	// 类中的方法，Kind为"constructor"、"method"、"get"或"set"
+	MethodDefinition struct {
+		Idx file.Idx
+		Key string
+		// [expr]形式的计算方法名，此时Key为空
+		Computed Expression
+		Kind     string
+		Static   bool
+		Body     *FunctionLiteral
+	}
+
 	NewExpression struct {
 		New              file.Idx
 		Callee           Expression
@@ -133,16 +198,38 @@ type (
 		Value      []Property
 	}
 
+	// 对象解构模式{a, b: c, d = 1}
+	ObjectPattern struct {
+		LeftBrace  file.Idx
+		RightBrace file.Idx
+		Properties []*PatternProperty
+	}
+
 	ParameterList struct {
 		Opening file.Idx
-		List    []*Identifier
+		List    []*Binding
+		// ...rest参数，没有时为nil
+		Rest    Expression
 		Closing file.Idx
 	}
 
+	// 对象解构模式中的一项，Key为属性名
+	PatternProperty struct {
+		Key string
+		// [expr]形式的计算属性名，此时Key为空
+		Computed Expression
+		Value    *Binding
+	}
+
 	Property struct {
-		Key   string
-		Kind  string
-		Value Expression
+		Key string
+		// [expr]形式的计算属性名，此时Key为空
+		Computed Expression
+		// "value"、"get"、"set"或"method"
+		Kind string
+		// {a}形式的简写属性
+		Shorthand bool
+		Value     Expression
 	}
 
 	RegExpLiteral struct {
@@ -156,12 +243,52 @@ type (
 		Sequence []Expression
 	}
 
+	// 数组字面量或调用参数中的...expr
+	SpreadElement struct {
+		Ellipsis   file.Idx
+		Expression Expression
+	}
+
+	// yield expr或yield* expr，Argument可以为nil
+	YieldExpression struct {
+		Yield    file.Idx
+		Argument Expression
+		Delegate bool
+	}
+
 	StringLiteral struct {
 		Idx     file.Idx
 		Literal string
 		Value   string
 	}
 
+	// tag`...`形式的带标签模板
+	TaggedTemplate struct {
+		Tag      Expression
+		Template *TemplateLiteral
+	}
+
+	// 模板中${}之间的一段文本，Parsed是处理转义后的值，转义无效时Valid为false
+	TemplateElement struct {
+		Idx     file.Idx
+		Literal string
+		Parsed  string
+		Valid   bool
+	}
+
+	// `...${expr}...`模板字符串，Elements比Expressions多一个
+	TemplateLiteral struct {
+		OpenQuote   file.Idx
+		CloseQuote  file.Idx
+		Elements    []*TemplateElement
+		Expressions []Expression
+	}
+
+	// super()调用或super.x中的super
+	SuperExpression struct {
+		Idx file.Idx
+	}
+
 	ThisExpression struct {
 		Idx file.Idx
 	}
@@ -177,33 +304,59 @@ type (
 		Name        string
 		Idx         file.Idx
 		Initializer Expression
+		// 解构声明的模式，此时Name为空
+		Pattern Expression
+	}
+
+	// 箭头函数体：BlockStatement或者ExpressionBody
+	ConciseBody interface {
+		Node
+		_conciseBody()
+	}
+
+	ExpressionBody struct {
+		Expression Expression
 	}
 )
 
 // _expressionNode
 
 func (*ArrayLiteral) _expressionNode()          {}
+func (*ArrayPattern) _expressionNode()          {}
+func (*ArrowFunctionLiteral) _expressionNode()  {}
 func (*AssignExpression) _expressionNode()      {}
+func (*AwaitExpression) _expressionNode()       {}
 func (*BadExpression) _expressionNode()         {}
 func (*BinaryExpression) _expressionNode()      {}
 func (*BooleanLiteral) _expressionNode()        {}
 func (*BracketExpression) _expressionNode()     {}
 func (*CallExpression) _expressionNode()        {}
+func (*ClassLiteral) _expressionNode()          {}
 func (*ConditionalExpression) _expressionNode() {}
 func (*DotExpression) _expressionNode()         {}
 func (*FunctionLiteral) _expressionNode()       {}
 func (*Identifier) _expressionNode()            {}
+func (*ImportCall) _expressionNode()            {}
 func (*NewExpression) _expressionNode()         {}
 func (*NullLiteral) _expressionNode()           {}
 func (*NumberLiteral) _expressionNode()         {}
 func (*ObjectLiteral) _expressionNode()         {}
+func (*ObjectPattern) _expressionNode()         {}
 func (*RegExpLiteral) _expressionNode()         {}
 func (*SequenceExpression) _expressionNode()    {}
+func (*SpreadElement) _expressionNode()         {}
+func (*YieldExpression) _expressionNode()       {}
 func (*StringLiteral) _expressionNode()         {}
+func (*SuperExpression) _expressionNode()       {}
+func (*TaggedTemplate) _expressionNode()        {}
+func (*TemplateLiteral) _expressionNode()       {}
 func (*ThisExpression) _expressionNode()        {}
 func (*UnaryExpression) _expressionNode()       {}
 func (*VariableExpression) _expressionNode()    {}
 
+func (*BlockStatement) _conciseBody() {}
+func (*ExpressionBody) _conciseBody() {}
+
 // ========= //
 // Statement //
 // ========= //
@@ -242,7 +395,9 @@ type (
 	CatchStatement struct {
 		Catch     file.Idx
 		Parameter *Identifier
-		Body      Statement
+		// catch参数为解构模式时Parameter为nil
+		Pattern Expression
+		Body    Statement
 	}
 
 	DebuggerStatement struct {
@@ -255,6 +410,40 @@ type (
 		Body Statement
 	}
 
+	// 类声明
+	ClassDeclaration struct {
+		Class *ClassLiteral
+	}
+
+	// export声明，有以下几种形式：
+	//   export var/let/const/class ...，Declaration为其中的声明
+	//   export function f() {}，Function为其中的函数，函数同时登记在DeclarationList中
+	//   export default ...，Default为true，导出的是Function、Declaration中的类或者Expression
+	//   export {a, b as c} [from "m"]，Specifiers为导出的名称列表
+	//   export * [as ns] from "m"，Star为true，Namespace为as后的名称
+	ExportDeclaration struct {
+		Export          file.Idx
+		Declaration     Statement
+		Function        *FunctionLiteral
+		Default         bool
+		Expression      Expression
+		Specifiers      []*ExportSpecifier
+		Star            bool
+		Namespace       *Identifier
+		ModuleSpecifier string
+	}
+
+	// import声明，import "m"时Default、Namespace和Specifiers都为空
+	ImportDeclaration struct {
+		Import file.Idx
+		// import d from "m"中的d
+		Default *Identifier
+		// import * as ns from "m"中的ns
+		Namespace       *Identifier
+		Specifiers      []*ImportSpecifier
+		ModuleSpecifier string
+	}
+
 	EmptyStatement struct {
 		Semicolon file.Idx
 	}
@@ -268,6 +457,17 @@ type (
 		Into   Expression
 		Source Expression
 		Body   Statement
+		// for (let/const x in ...) 时的词法声明，此时Into为其中的变量
+		Declaration *LexicalDeclaration
+	}
+
+	ForOfStatement struct {
+		For    file.Idx
+		Into   Expression
+		Source Expression
+		Body   Statement
+		// for (let/const x of ...) 时的词法声明，此时Into为其中的变量
+		Declaration *LexicalDeclaration
 	}
 
 	ForStatement struct {
@@ -276,6 +476,8 @@ type (
 		Update      Expression
 		Test        Expression
 		Body        Statement
+		// for (let/const ...; ...; ...) 时的词法声明，此时Initializer为nil
+		Declaration *LexicalDeclaration
 	}
 
 	IfStatement struct {
@@ -285,6 +487,13 @@ type (
 		Alternate  Statement
 	}
 
+	// let/const声明
+	LexicalDeclaration struct {
+		Idx   file.Idx
+		Token token.Token // token.LET 或 token.CONST
+		List  []*VariableExpression
+	}
+
 	LabelledStatement struct {
 		Label     *Identifier
 		Colon     file.Idx
@@ -340,13 +549,19 @@ func (*BlockStatement) _statementNode()      {}
 func (*BranchStatement) _statementNode()     {}
 func (*CaseStatement) _statementNode()       {}
 func (*CatchStatement) _statementNode()      {}
+func (*ClassDeclaration) _statementNode()    {}
 func (*DebuggerStatement) _statementNode()   {}
 func (*DoWhileStatement) _statementNode()    {}
 func (*EmptyStatement) _statementNode()      {}
+func (*ExportDeclaration) _statementNode()   {}
 func (*ExpressionStatement) _statementNode() {}
 func (*ForInStatement) _statementNode()      {}
+func (*FunctionDeclaration) _statementNode() {}
+func (*ForOfStatement) _statementNode()      {}
 func (*ForStatement) _statementNode()        {}
 func (*IfStatement) _statementNode()         {}
+func (*ImportDeclaration) _statementNode()   {}
+func (*LexicalDeclaration) _statementNode()  {}
 func (*LabelledStatement) _statementNode()   {}
 func (*ReturnStatement) _statementNode()     {}
 func (*SwitchStatement) _statementNode()     {}
@@ -367,6 +582,7 @@ type (
 		_declarationNode()
 	}
 
+	// 函数声明，同时也是语句。函数体顶层的声明登记在DeclarationList中，块中的声明在进入块时创建
 	FunctionDeclaration struct {
 		Function *FunctionLiteral
 	}
@@ -377,6 +593,26 @@ type (
 	}
 )
 
+// ================= //
+// Module specifiers //
+// ================= //
+
+type (
+	// import {a as b}中的一项，ImportName为a，LocalName为b，没有as时两者相同
+	ImportSpecifier struct {
+		Idx        file.Idx
+		ImportName string
+		LocalName  string
+	}
+
+	// export {a as b}中的一项，LocalName为a，ExportName为b，没有as时两者相同
+	ExportSpecifier struct {
+		Idx        file.Idx
+		LocalName  string
+		ExportName string
+	}
+)
+
 // _declarationNode
 
 func (*FunctionDeclaration) _declarationNode() {}
@@ -401,39 +637,57 @@ type Program struct {
 // ==== //
 
 func (self *ArrayLiteral) Idx0() file.Idx          { return self.LeftBracket }
+func (self *ArrayPattern) Idx0() file.Idx          { return self.LeftBracket }
+func (self *ArrowFunctionLiteral) Idx0() file.Idx  { return self.Start }
 func (self *AssignExpression) Idx0() file.Idx      { return self.Left.Idx0() }
+func (self *AwaitExpression) Idx0() file.Idx       { return self.Await }
 func (self *BadExpression) Idx0() file.Idx         { return self.From }
 func (self *BinaryExpression) Idx0() file.Idx      { return self.Left.Idx0() }
 func (self *BooleanLiteral) Idx0() file.Idx        { return self.Idx }
 func (self *BracketExpression) Idx0() file.Idx     { return self.Left.Idx0() }
 func (self *CallExpression) Idx0() file.Idx        { return self.Callee.Idx0() }
+func (self *ClassLiteral) Idx0() file.Idx          { return self.Class }
 func (self *ConditionalExpression) Idx0() file.Idx { return self.Test.Idx0() }
 func (self *DotExpression) Idx0() file.Idx         { return self.Left.Idx0() }
 func (self *FunctionLiteral) Idx0() file.Idx       { return self.Function }
 func (self *Identifier) Idx0() file.Idx            { return self.Idx }
+func (self *ImportCall) Idx0() file.Idx            { return self.Import }
 func (self *NewExpression) Idx0() file.Idx         { return self.New }
 func (self *NullLiteral) Idx0() file.Idx           { return self.Idx }
 func (self *NumberLiteral) Idx0() file.Idx         { return self.Idx }
 func (self *ObjectLiteral) Idx0() file.Idx         { return self.LeftBrace }
+func (self *ObjectPattern) Idx0() file.Idx         { return self.LeftBrace }
 func (self *RegExpLiteral) Idx0() file.Idx         { return self.Idx }
 func (self *SequenceExpression) Idx0() file.Idx    { return self.Sequence[0].Idx0() }
+func (self *SpreadElement) Idx0() file.Idx         { return self.Ellipsis }
+func (self *YieldExpression) Idx0() file.Idx       { return self.Yield }
 func (self *StringLiteral) Idx0() file.Idx         { return self.Idx }
+func (self *SuperExpression) Idx0() file.Idx       { return self.Idx }
+func (self *TaggedTemplate) Idx0() file.Idx        { return self.Tag.Idx0() }
+func (self *TemplateLiteral) Idx0() file.Idx       { return self.OpenQuote }
 func (self *ThisExpression) Idx0() file.Idx        { return self.Idx }
 func (self *UnaryExpression) Idx0() file.Idx       { return self.Idx }
 func (self *VariableExpression) Idx0() file.Idx    { return self.Idx }
+func (self *ExpressionBody) Idx0() file.Idx        { return self.Expression.Idx0() }
 
 func (self *BadStatement) Idx0() file.Idx        { return self.From }
 func (self *BlockStatement) Idx0() file.Idx      { return self.LeftBrace }
 func (self *BranchStatement) Idx0() file.Idx     { return self.Idx }
 func (self *CaseStatement) Idx0() file.Idx       { return self.Case }
 func (self *CatchStatement) Idx0() file.Idx      { return self.Catch }
+func (self *ClassDeclaration) Idx0() file.Idx    { return self.Class.Idx0() }
 func (self *DebuggerStatement) Idx0() file.Idx   { return self.Debugger }
 func (self *DoWhileStatement) Idx0() file.Idx    { return self.Do }
 func (self *EmptyStatement) Idx0() file.Idx      { return self.Semicolon }
+func (self *ExportDeclaration) Idx0() file.Idx   { return self.Export }
 func (self *ExpressionStatement) Idx0() file.Idx { return self.Expression.Idx0() }
 func (self *ForInStatement) Idx0() file.Idx      { return self.For }
+func (self *FunctionDeclaration) Idx0() file.Idx { return self.Function.Idx0() }
+func (self *ForOfStatement) Idx0() file.Idx      { return self.For }
 func (self *ForStatement) Idx0() file.Idx        { return self.For }
 func (self *IfStatement) Idx0() file.Idx         { return self.If }
+func (self *ImportDeclaration) Idx0() file.Idx   { return self.Import }
+func (self *LexicalDeclaration) Idx0() file.Idx  { return self.Idx }
 func (self *LabelledStatement) Idx0() file.Idx   { return self.Label.Idx0() }
 func (self *Program) Idx0() file.Idx             { return self.Body[0].Idx0() }
 func (self *ReturnStatement) Idx0() file.Idx     { return self.Return }
@@ -449,23 +703,39 @@ func (self *WithStatement) Idx0() file.Idx       { return self.With }
 // ==== //
 
 func (self *ArrayLiteral) Idx1() file.Idx          { return self.RightBracket }
+func (self *ArrayPattern) Idx1() file.Idx          { return self.RightBracket + 1 }
+func (self *ArrowFunctionLiteral) Idx1() file.Idx  { return self.Body.Idx1() }
 func (self *AssignExpression) Idx1() file.Idx      { return self.Right.Idx1() }
+func (self *AwaitExpression) Idx1() file.Idx       { return self.Argument.Idx1() }
 func (self *BadExpression) Idx1() file.Idx         { return self.To }
 func (self *BinaryExpression) Idx1() file.Idx      { return self.Right.Idx1() }
 func (self *BooleanLiteral) Idx1() file.Idx        { return file.Idx(int(self.Idx) + len(self.Literal)) }
 func (self *BracketExpression) Idx1() file.Idx     { return self.RightBracket + 1 }
 func (self *CallExpression) Idx1() file.Idx        { return self.RightParenthesis + 1 }
+func (self *ClassLiteral) Idx1() file.Idx          { return self.RightBrace + 1 }
 func (self *ConditionalExpression) Idx1() file.Idx { return self.Test.Idx1() }
 func (self *DotExpression) Idx1() file.Idx         { return self.Identifier.Idx1() }
 func (self *FunctionLiteral) Idx1() file.Idx       { return self.Body.Idx1() }
 func (self *Identifier) Idx1() file.Idx            { return file.Idx(int(self.Idx) + len(self.Name)) }
+func (self *ImportCall) Idx1() file.Idx            { return self.RightParenthesis + 1 }
 func (self *NewExpression) Idx1() file.Idx         { return self.RightParenthesis + 1 }
 func (self *NullLiteral) Idx1() file.Idx           { return file.Idx(int(self.Idx) + 4) } // "null"
 func (self *NumberLiteral) Idx1() file.Idx         { return file.Idx(int(self.Idx) + len(self.Literal)) }
 func (self *ObjectLiteral) Idx1() file.Idx         { return self.RightBrace }
+func (self *ObjectPattern) Idx1() file.Idx         { return self.RightBrace + 1 }
 func (self *RegExpLiteral) Idx1() file.Idx         { return file.Idx(int(self.Idx) + len(self.Literal)) }
 func (self *SequenceExpression) Idx1() file.Idx    { return self.Sequence[0].Idx1() }
+func (self *SpreadElement) Idx1() file.Idx         { return self.Expression.Idx1() }
+func (self *YieldExpression) Idx1() file.Idx {
+	if self.Argument != nil {
+		return self.Argument.Idx1()
+	}
+	return self.Yield + 5 // "yield"
+}
 func (self *StringLiteral) Idx1() file.Idx         { return file.Idx(int(self.Idx) + len(self.Literal)) }
+func (self *SuperExpression) Idx1() file.Idx       { return self.Idx + 5 } // "super"
+func (self *TaggedTemplate) Idx1() file.Idx        { return self.Template.Idx1() }
+func (self *TemplateLiteral) Idx1() file.Idx       { return self.CloseQuote + 1 }
 func (self *ThisExpression) Idx1() file.Idx        { return self.Idx }
 func (self *UnaryExpression) Idx1() file.Idx {
 	if self.Postfix {
@@ -474,22 +744,40 @@ func (self *UnaryExpression) Idx1() file.Idx {
 	return self.Operand.Idx1()
 }
 func (self *VariableExpression) Idx1() file.Idx {
+	if self.Initializer == nil && self.Pattern != nil {
+		return self.Pattern.Idx1()
+	}
 	if self.Initializer == nil {
 		return file.Idx(int(self.Idx) + len(self.Name) + 1)
 	}
 	return self.Initializer.Idx1()
 }
+func (self *ExpressionBody) Idx1() file.Idx { return self.Expression.Idx1() }
 
 func (self *BadStatement) Idx1() file.Idx        { return self.To }
 func (self *BlockStatement) Idx1() file.Idx      { return self.RightBrace + 1 }
 func (self *BranchStatement) Idx1() file.Idx     { return self.Idx }
 func (self *CaseStatement) Idx1() file.Idx       { return self.Consequent[len(self.Consequent)-1].Idx1() }
 func (self *CatchStatement) Idx1() file.Idx      { return self.Body.Idx1() }
+func (self *ClassDeclaration) Idx1() file.Idx    { return self.Class.Idx1() }
 func (self *DebuggerStatement) Idx1() file.Idx   { return self.Debugger + 8 }
 func (self *DoWhileStatement) Idx1() file.Idx    { return self.Test.Idx1() }
 func (self *EmptyStatement) Idx1() file.Idx      { return self.Semicolon + 1 }
+func (self *ExportDeclaration) Idx1() file.Idx {
+	switch {
+	case self.Function != nil:
+		return self.Function.Idx1()
+	case self.Declaration != nil:
+		return self.Declaration.Idx1()
+	case self.Expression != nil:
+		return self.Expression.Idx1()
+	}
+	return self.Export
+}
 func (self *ExpressionStatement) Idx1() file.Idx { return self.Expression.Idx1() }
 func (self *ForInStatement) Idx1() file.Idx      { return self.Body.Idx1() }
+func (self *FunctionDeclaration) Idx1() file.Idx { return self.Function.Idx1() }
+func (self *ForOfStatement) Idx1() file.Idx      { return self.Body.Idx1() }
 func (self *ForStatement) Idx1() file.Idx        { return self.Body.Idx1() }
 func (self *IfStatement) Idx1() file.Idx {
 	if self.Alternate != nil {
@@ -497,7 +785,11 @@ func (self *IfStatement) Idx1() file.Idx {
 	}
 	return self.Consequent.Idx1()
 }
+func (self *ImportDeclaration) Idx1() file.Idx { return self.Import }
 func (self *LabelledStatement) Idx1() file.Idx { return self.Colon + 1 }
+func (self *LexicalDeclaration) Idx1() file.Idx {
+	return self.List[len(self.List)-1].Idx1()
+}
 func (self *Program) Idx1() file.Idx           { return self.Body[len(self.Body)-1].Idx1() }
 func (self *ReturnStatement) Idx1() file.Idx   { return self.Return }
 func (self *SwitchStatement) Idx1() file.Idx   { return self.Body[len(self.Body)-1].Idx1() }
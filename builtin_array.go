@@ -107,22 +107,29 @@ func (r *Runtime) arrayproto_join(call FunctionCall) Value {
 	if l == 0 {
 		return stringEmpty
 	}
+	r.useGas(int64(l))
 
 	var buf bytes.Buffer
 
 	element0 := o.self.get(intToValue(0))
 	if element0 != nil && element0 != _undefined && element0 != _null {
-		buf.WriteString(element0.String())
+		s := element0.String()
+		r.allocate(len(s))
+		buf.WriteString(s)
 	}
 
 	for i := 1; i < l; i++ {
+		r.allocate(len(sep))
 		buf.WriteString(sep)
 		element := o.self.get(intToValue(int64(i)))
 		if element != nil && element != _undefined && element != _null {
-			buf.WriteString(element.String())
+			s := element.String()
+			r.allocate(len(s))
+			buf.WriteString(s)
 		}
 	}
 
+	r.allocate(sizeofStringValue)
 	return newStringValue(buf.String())
 }
 // Array.prototype.toString()
@@ -161,6 +168,7 @@ func (r *Runtime) writeItemLocaleString(item Value, buf *bytes.Buffer) {
 func (r *Runtime) arrayproto_toLocaleString_generic(obj *Object, start int64, buf *bytes.Buffer) Value {
 	length := toLength(obj.self.getStr("length"))
 	for i := int64(start); i < length; i++ {
+		r.useGas(1)
 		if i > 0 {
 			buf.WriteByte(',')
 		}
@@ -178,6 +186,7 @@ func (r *Runtime) arrayproto_toLocaleString(call FunctionCall) Value {
 	if a, ok := array.self.(*arrayObject); ok {
 		var buf bytes.Buffer
 		for i := int64(0); i < a.length; i++ {
+			r.useGas(1)
 			var item Value
 			if i < int64(len(a.values)) {
 				item = a.values[i]
@@ -212,6 +221,7 @@ func (r *Runtime) arrayproto_concat_append(a *Object, item Value) {
 		if isArray(obj) {
 			length := toLength(obj.self.getStr("length"))
 			for i := int64(0); i < length; i++ {
+				r.useGas(1)
 				v := obj.self.get(intToValue(i))
 				if v != nil {
 					descr.Value = v
@@ -289,6 +299,7 @@ func (r *Runtime) arrayproto_slice(call FunctionCall) Value {
 		Configurable: FLAG_TRUE,
 	}
 	for start < end {
+		r.useGas(1)
 		p := o.self.get(intToValue(start))
 		if p != nil && p != _undefined {
 			descr.Value = p
@@ -312,6 +323,7 @@ func (r *Runtime) arrayproto_sort(call FunctionCall) Value {
 	}
 
 	ctx := arraySortCtx{
+		r:       r,
 		obj:     o.self,
 		compare: compareFn,
 	}
@@ -336,6 +348,7 @@ func (r *Runtime) arrayproto_splice(call FunctionCall) Value {
 	actualDeleteCount := min(max(call.Argument(1).ToInteger(), 0), length-actualStart)
 
 	for k := int64(0); k < actualDeleteCount; k++ {
+		r.useGas(1)
 		from := intToValue(k + actualStart)
 		if o.self.hasProperty(from) {
 			a.self.put(intToValue(k), o.self.get(from), false)
@@ -345,6 +358,7 @@ func (r *Runtime) arrayproto_splice(call FunctionCall) Value {
 	itemCount := max(int64(len(call.Arguments)-2), 0)
 	if itemCount < actualDeleteCount {
 		for k := actualStart; k < length-actualDeleteCount; k++ {
+			r.useGas(1)
 			from := intToValue(k + actualDeleteCount)
 			to := intToValue(k + itemCount)
 			if o.self.hasProperty(from) {
@@ -355,10 +369,12 @@ func (r *Runtime) arrayproto_splice(call FunctionCall) Value {
 		}
 
 		for k := length; k > length-actualDeleteCount+itemCount; k-- {
+			r.useGas(1)
 			o.self.delete(intToValue(k-1), true)
 		}
 	} else if itemCount > actualDeleteCount {
 		for k := length - actualDeleteCount; k > actualStart; k-- {
+			r.useGas(1)
 			from := intToValue(k + actualDeleteCount - 1)
 			to := intToValue(k + itemCount - 1)
 			if o.self.hasProperty(from) {
@@ -386,6 +402,7 @@ func (r *Runtime) arrayproto_unshift(call FunctionCall) Value {
 	length := toLength(o.self.getStr("length"))
 	argCount := int64(len(call.Arguments))
 	for k := length - 1; k >= 0; k-- {
+		r.useGas(1)
 		from := intToValue(k)
 		to := intToValue(k + argCount)
 		if o.self.hasProperty(from) {
@@ -424,6 +441,7 @@ func (r *Runtime) arrayproto_indexOf(call FunctionCall) Value {
 	searchElement := call.Argument(0)
 
 	for ; n < length; n++ {
+		r.useGas(1)
 		idx := intToValue(n)
 		if val := o.self.get(idx); val != nil {
 			if searchElement.StrictEquals(val) {
@@ -460,6 +478,7 @@ func (r *Runtime) arrayproto_lastIndexOf(call FunctionCall) Value {
 	searchElement := call.Argument(0)
 
 	for k := fromIndex; k >= 0; k-- {
+		r.useGas(1)
 		idx := intToValue(k)
 		if val := o.self.get(idx); val != nil {
 			if searchElement.StrictEquals(val) {
@@ -482,6 +501,7 @@ func (r *Runtime) arrayproto_every(call FunctionCall) Value {
 			Arguments: []Value{nil, nil, o},
 		}
 		for k := int64(0); k < length; k++ {
+			r.useGas(1)
 			idx := intToValue(k)
 			if val := o.self.get(idx); val != nil {
 				fc.Arguments[0] = val
@@ -508,6 +528,7 @@ func (r *Runtime) arrayproto_some(call FunctionCall) Value {
 			Arguments: []Value{nil, nil, o},
 		}
 		for k := int64(0); k < length; k++ {
+			r.useGas(1)
 			idx := intToValue(k)
 			if val := o.self.get(idx); val != nil {
 				fc.Arguments[0] = val
@@ -534,6 +555,7 @@ func (r *Runtime) arrayproto_forEach(call FunctionCall) Value {
 			Arguments: []Value{nil, nil, o},
 		}
 		for k := int64(0); k < length; k++ {
+			r.useGas(1)
 			idx := intToValue(k)
 			if val := o.self.get(idx); val != nil {
 				fc.Arguments[0] = val
@@ -561,6 +583,7 @@ func (r *Runtime) arrayproto_map(call FunctionCall) Value {
 		a._setLengthInt(length, true)
 		a.values = make([]Value, length)
 		for k := int64(0); k < length; k++ {
+			r.useGas(1)
 			idx := intToValue(k)
 			if val := o.self.get(idx); val != nil {
 				fc.Arguments[0] = val
@@ -588,6 +611,7 @@ func (r *Runtime) arrayproto_filter(call FunctionCall) Value {
 			Arguments: []Value{nil, nil, o},
 		}
 		for k := int64(0); k < length; k++ {
+			r.useGas(1)
 			idx := intToValue(k)
 			if val := o.self.get(idx); val != nil {
 				fc.Arguments[0] = val
@@ -623,6 +647,7 @@ func (r *Runtime) arrayproto_reduce(call FunctionCall) Value {
 			fc.Arguments[0] = call.Argument(1)
 		} else {
 			for ; k < length; k++ {
+				r.useGas(1)
 				idx := intToValue(k)
 				if val := o.self.get(idx); val != nil {
 					fc.Arguments[0] = val
@@ -637,6 +662,7 @@ func (r *Runtime) arrayproto_reduce(call FunctionCall) Value {
 		}
 
 		for ; k < length; k++ {
+			r.useGas(1)
 			idx := intToValue(k)
 			if val := o.self.get(idx); val != nil {
 				fc.Arguments[1] = val
@@ -668,6 +694,7 @@ func (r *Runtime) arrayproto_reduceRight(call FunctionCall) Value {
 			fc.Arguments[0] = call.Argument(1)
 		} else {
 			for ; k >= 0; k-- {
+				r.useGas(1)
 				idx := intToValue(k)
 				if val := o.self.get(idx); val != nil {
 					fc.Arguments[0] = val
@@ -682,6 +709,7 @@ func (r *Runtime) arrayproto_reduceRight(call FunctionCall) Value {
 		}
 
 		for ; k >= 0; k-- {
+			r.useGas(1)
 			idx := intToValue(k)
 			if val := o.self.get(idx); val != nil {
 				fc.Arguments[1] = val
@@ -717,6 +745,7 @@ func (r *Runtime) arrayproto_reverse_generic(o *Object, start int64) {
 	l := toLength(o.self.getStr("length"))
 	middle := l / 2
 	for lower := start; lower != middle; lower++ {
+		r.useGas(1)
 		arrayproto_reverse_generic_step(o, lower, l-lower-1)
 	}
 }
@@ -729,6 +758,7 @@ func (r *Runtime) arrayproto_reverse(call FunctionCall) Value {
 		middle := l / 2
 		al := int64(len(a.values))
 		for lower := int64(0); lower != middle; lower++ {
+			r.useGas(1)
 			upper := l - lower - 1
 			var lowerValue, upperValue Value
 			if upper >= al || lower >= al {
@@ -770,6 +800,7 @@ func (r *Runtime) arrayproto_shift(call FunctionCall) Value {
 	}
 	first := o.self.get(intToValue(0))
 	for i := int64(1); i < length; i++ {
+		r.useGas(1)
 		v := o.self.get(intToValue(i))
 		if v != nil && v != _undefined {
 			o.self.put(intToValue(i-1), v, true)
@@ -784,6 +815,204 @@ func (r *Runtime) arrayproto_shift(call FunctionCall) Value {
 
 	return first
 }
+//Array.prototype.copyWithin()实现，copyWithin() 方法浅复制数组的一部分到同一数组中的另一个位置，并返回它，不会改变原数组的长度。
+func (r *Runtime) arrayproto_copyWithin(call FunctionCall) Value {
+	o := call.This.ToObject(r)
+	length := toLength(o.self.getStr("length"))
+	to := relToIdx64(call.Argument(0), length)
+	from := relToIdx64(call.Argument(1), length)
+	end := length
+	if arg := call.Argument(2); arg != _undefined {
+		end = relToIdx64(arg, length)
+	}
+	count := min(end-from, length-to)
+	dir := int64(1)
+	if from < to && to < from+count {
+		dir = -1
+		from += count - 1
+		to += count - 1
+	}
+	for ; count > 0; count-- {
+		r.useGas(1)
+		fromIdx, toIdx := intToValue(from), intToValue(to)
+		if val := o.self.get(fromIdx); val != nil {
+			o.self.put(toIdx, val, true)
+		} else {
+			o.self.delete(toIdx, true)
+		}
+		from += dir
+		to += dir
+	}
+	return o
+}
+//Array.prototype.fill()实现，fill() 方法用一个固定值填充一个数组中从起始索引到终止索引内的全部元素。不包括终止索引。
+func (r *Runtime) arrayproto_fill(call FunctionCall) Value {
+	o := call.This.ToObject(r)
+	length := toLength(o.self.getStr("length"))
+	value := call.Argument(0)
+	start := relToIdx64(call.Argument(1), length)
+	end := length
+	if arg := call.Argument(2); arg != _undefined {
+		end = relToIdx64(arg, length)
+	}
+	for ; start < end; start++ {
+		r.useGas(1)
+		o.self.put(intToValue(start), value, true)
+	}
+	return o
+}
+// find和findIndex的实现，返回第一个使回调函数返回true的下标，空位按undefined处理
+func (r *Runtime) arrayproto_findIndex_generic(o *Object, call FunctionCall) (int64, Value) {
+	length := toLength(o.self.getStr("length"))
+	callbackFn := r.toCallbackFn(call.Argument(0))
+	fc := FunctionCall{
+		This:      call.Argument(1),
+		Arguments: []Value{nil, nil, o},
+	}
+	for k := int64(0); k < length; k++ {
+		r.useGas(1)
+		idx := intToValue(k)
+		val := nilSafe(o.self.get(idx))
+		fc.Arguments[0] = val
+		fc.Arguments[1] = idx
+		if callbackFn(fc).ToBoolean() {
+			return k, val
+		}
+	}
+	return -1, _undefined
+}
+//Array.prototype.find()实现，find() 方法返回数组中满足提供的测试函数的第一个元素的值。否则返回 undefined。
+func (r *Runtime) arrayproto_find(call FunctionCall) Value {
+	_, val := r.arrayproto_findIndex_generic(call.This.ToObject(r), call)
+	return val
+}
+//Array.prototype.findIndex()实现，findIndex()方法返回数组中满足提供的测试函数的第一个元素的索引。否则返回-1。
+func (r *Runtime) arrayproto_findIndex(call FunctionCall) Value {
+	k, _ := r.arrayproto_findIndex_generic(call.This.ToObject(r), call)
+	return intToValue(k)
+}
+//Array.prototype.includes()实现，includes() 方法用来判断一个数组是否包含一个指定的值，使用SameValueZero比较，NaN与自身相等。
+func (r *Runtime) arrayproto_includes(call FunctionCall) Value {
+	o := call.This.ToObject(r)
+	length := toLength(o.self.getStr("length"))
+	if length == 0 {
+		return valueFalse
+	}
+	searchElement := call.Argument(0)
+	for n := relToIdx64(call.Argument(1), length); n < length; n++ {
+		r.useGas(1)
+		val := nilSafe(o.self.get(intToValue(n)))
+		if searchElement.StrictEquals(val) || isNaN(searchElement) && isNaN(val) {
+			return valueTrue
+		}
+	}
+	return valueFalse
+}
+// 把source的元素依次追加到values，depth大于0时展开其中的数组，mapFn不为nil时先对元素调用mapFn
+func (r *Runtime) flattenIntoArray(values []Value, source *Object, depth float64, mapFn func(FunctionCall) Value, thisArg Value) []Value {
+	length := toLength(source.self.getStr("length"))
+	for k := int64(0); k < length; k++ {
+		r.useGas(1)
+		idx := intToValue(k)
+		val := source.self.get(idx)
+		if val == nil {
+			continue
+		}
+		if mapFn != nil {
+			val = mapFn(FunctionCall{
+				This:      thisArg,
+				Arguments: []Value{val, idx, source},
+			})
+		}
+		if obj, ok := val.(*Object); ok && depth > 0 && isArray(obj) {
+			values = r.flattenIntoArray(values, obj, depth-1, nil, nil)
+		} else {
+			values = append(values, val)
+		}
+	}
+	return values
+}
+//Array.prototype.flat()实现，flat() 方法会按照一个可指定的深度递归遍历数组，并将所有元素与遍历到的子数组中的元素合并为一个新数组返回。
+func (r *Runtime) arrayproto_flat(call FunctionCall) Value {
+	o := call.This.ToObject(r)
+	depth := float64(1)
+	if arg := call.Argument(0); arg != _undefined {
+		depth = arg.ToFloat()
+		if math.IsNaN(depth) {
+			depth = 0
+		}
+	}
+	return r.newArrayValues(r.flattenIntoArray(nil, o, depth, nil, nil))
+}
+//Array.prototype.flatMap()实现，flatMap() 方法首先使用映射函数映射每个元素，然后将结果展开一层压缩成一个新数组。
+func (r *Runtime) arrayproto_flatMap(call FunctionCall) Value {
+	o := call.This.ToObject(r)
+	mapFn := r.toCallbackFn(call.Argument(0))
+	return r.newArrayValues(r.flattenIntoArray(nil, o, 1, mapFn, call.Argument(1)))
+}
+// 相对位置转换为[0, length]内的下标，负数从末尾算起
+func relToIdx64(rel Value, length int64) int64 {
+	i := rel.ToInteger()
+	if i < 0 {
+		return max(length+i, 0)
+	}
+	return min(i, length)
+}
+// 用this作为构造函数创建Array.from和Array.of的结果，this不是构造函数时创建普通数组
+func (r *Runtime) arrayFromConstructor(c Value, values []Value) *Object {
+	ctor, ok := c.(*Object)
+	if !ok || ctor == r.global.Array || !r.isConstructor(ctor) {
+		return r.newArrayValues(values)
+	}
+	a := r.constructWith(ctor, []Value{intToValue(int64(len(values)))}, ctor)
+	descr := propertyDescr{
+		Writable:     FLAG_TRUE,
+		Enumerable:   FLAG_TRUE,
+		Configurable: FLAG_TRUE,
+	}
+	for i, v := range values {
+		descr.Value = v
+		a.self.defineOwnProperty(intToValue(int64(i)), descr, true)
+	}
+	a.self.putStr("length", intToValue(int64(len(values))), true)
+	return a
+}
+// Array.from实现，从可迭代对象或类数组对象创建数组
+func (r *Runtime) array_from(call FunctionCall) Value {
+	var mapFn func(FunctionCall) Value
+	if arg := call.Argument(1); arg != _undefined {
+		mapFn = r.toCallbackFn(arg)
+	}
+	thisArg := call.Argument(2)
+	items := call.Argument(0)
+	var values []Value
+	add := func(v Value) {
+		if mapFn != nil {
+			v = mapFn(FunctionCall{
+				This:      thisArg,
+				Arguments: []Value{v, intToValue(int64(len(values)))},
+			})
+		}
+		values = append(values, v)
+	}
+	src := items.ToObject(r)
+	if method := src.self.get(symIterator); method != nil && method != _undefined && method != _null {
+		r.iterateClose(items, add)
+	} else {
+		length := toLength(src.self.getStr("length"))
+		for k := int64(0); k < length; k++ {
+			r.useGas(1)
+			add(nilSafe(src.self.get(intToValue(k))))
+		}
+	}
+	return r.arrayFromConstructor(call.This, values)
+}
+// Array.of实现，用参数创建数组
+func (r *Runtime) array_of(call FunctionCall) Value {
+	values := make([]Value, len(call.Arguments))
+	copy(values, call.Arguments)
+	return r.arrayFromConstructor(call.This, values)
+}
 // Array.isArray实现
 func (r *Runtime) array_isArray(call FunctionCall) Value {
 	if o, ok := call.Argument(0).(*Object); ok {
@@ -794,6 +1023,21 @@ func (r *Runtime) array_isArray(call FunctionCall) Value {
 	return valueFalse
 }
 // Array Proto实现
+//Array.prototype.values()
+//返回一个按顺序迭代数组元素的迭代器，也是数组的Symbol.iterator
+func (r *Runtime) arrayproto_values(call FunctionCall) Value {
+	return r.createArrayIterator(call.This.ToObject(r), iterationKindValue)
+}
+//Array.prototype.keys()
+//返回一个迭代数组下标的迭代器
+func (r *Runtime) arrayproto_keys(call FunctionCall) Value {
+	return r.createArrayIterator(call.This.ToObject(r), iterationKindKey)
+}
+//Array.prototype.entries()
+//返回一个迭代[下标, 元素]的迭代器
+func (r *Runtime) arrayproto_entries(call FunctionCall) Value {
+	return r.createArrayIterator(call.This.ToObject(r), iterationKindKeyValue)
+}
 func (r *Runtime) createArrayProto(val *Object) objectImpl {
 	o := &arrayObject{
 		baseObject: baseObject{
@@ -827,19 +1071,33 @@ func (r *Runtime) createArrayProto(val *Object) objectImpl {
 	o._putProp("filter", r.newNativeFunc(r.arrayproto_filter, nil, "filter", nil, 1), true, false, true)
 	o._putProp("reduce", r.newNativeFunc(r.arrayproto_reduce, nil, "reduce", nil, 1), true, false, true)
 	o._putProp("reduceRight", r.newNativeFunc(r.arrayproto_reduceRight, nil, "reduceRight", nil, 1), true, false, true)
+	o._putProp("keys", r.newNativeFunc(r.arrayproto_keys, nil, "keys", nil, 0), true, false, true)
+	o._putProp("entries", r.newNativeFunc(r.arrayproto_entries, nil, "entries", nil, 0), true, false, true)
+	o._putProp("copyWithin", r.newNativeFunc(r.arrayproto_copyWithin, nil, "copyWithin", nil, 2), true, false, true)
+	o._putProp("fill", r.newNativeFunc(r.arrayproto_fill, nil, "fill", nil, 1), true, false, true)
+	o._putProp("find", r.newNativeFunc(r.arrayproto_find, nil, "find", nil, 1), true, false, true)
+	o._putProp("findIndex", r.newNativeFunc(r.arrayproto_findIndex, nil, "findIndex", nil, 1), true, false, true)
+	o._putProp("includes", r.newNativeFunc(r.arrayproto_includes, nil, "includes", nil, 1), true, false, true)
+	o._putProp("flat", r.newNativeFunc(r.arrayproto_flat, nil, "flat", nil, 0), true, false, true)
+	o._putProp("flatMap", r.newNativeFunc(r.arrayproto_flatMap, nil, "flatMap", nil, 1), true, false, true)
+	o._putProp("values", r.global.arrayValues, true, false, true)
+	o._putSym(symIterator, r.global.arrayValues, true, false, true)
 
 	return o
 }
-// Array类仅挂载了一个函数isArray
+// Array类挂载isArray、from和of
 func (r *Runtime) createArray(val *Object) objectImpl {
 	o := r.newNativeFuncConstructObj(val, r.builtin_newArray, "Array", r.global.ArrayPrototype, 1)
 	o._putProp("isArray", r.newNativeFunc(r.array_isArray, nil, "isArray", nil, 1), true, false, true)
+	o._putProp("from", r.newNativeFunc(r.array_from, nil, "from", nil, 1), true, false, true)
+	o._putProp("of", r.newNativeFunc(r.array_of, nil, "of", nil, 0), true, false, true)
 	return o
 }
 // Array类实现
 func (r *Runtime) initArray() {
 	//r.global.ArrayPrototype = r.newArray(r.global.ObjectPrototype).val
 	//o := r.global.ArrayPrototype.self
+	r.global.arrayValues = r.newNativeFunc(r.arrayproto_values, nil, "values", nil, 0)
 	r.global.ArrayPrototype = r.newLazyObject(r.createArrayProto)
 
 	//r.global.Array = r.newNativeFuncConstruct(r.builtin_newArray, "Array", r.global.ArrayPrototype, 1)
@@ -858,6 +1116,7 @@ type sortable interface {
 }
 
 type arraySortCtx struct {
+	r       *Runtime
 	obj     sortable
 	compare func(FunctionCall) Value
 }
@@ -915,6 +1174,8 @@ func (a *arraySortCtx) Len() int {
 }
 // 如果 i 索引的数据小于 j 索引的数据，返回 true，且不会调用下面的 Swap()，即数据升序排序。
 func (a *arraySortCtx) Less(j, k int) bool {
+	// 每次比较消耗1个gas
+	a.r.useGas(1)
 	return a.sortCompare(a.obj.sortGet(int64(j)), a.obj.sortGet(int64(k))) < 0
 }
 // 交换 i 和 j 索引的两个元素的位置
@@ -0,0 +1,40 @@
+package goja
+
+import "testing"
+
+func TestArrayFromOf(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(Array.from("ab").join(), "a,b", "from string");
+	assert.sameValue(Array.from(new Set([1, 2, 2])).length, 2, "from iterable");
+	assert.sameValue(Array.from({length: 2, 0: 1, 1: 2}, function(v, i) {
+		return v * this.k + i;
+	}, {k: 10}).join(), "10,21", "from array-like with mapFn");
+	assert.sameValue(Array.of(7).length, 1, "of");
+	assert.sameValue(Array.of(1, 2, 3).join(), "1,2,3", "of values");
+	function C() {}
+	var c = Array.of.call(C, 1, 2);
+	assert.sameValue(c instanceof C, true, "of with constructor");
+	assert.sameValue(c.length, 2, "of with constructor length");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestArrayES6Methods(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue([1, 2, 3, 4, 5].copyWithin(0, 3).join(), "4,5,3,4,5", "copyWithin");
+	assert.sameValue([1, 2, 3, 4, 5].copyWithin(1, 0, 3).join(), "1,1,2,3,5", "copyWithin overlapping");
+	assert.sameValue([1, 2, 3].fill(0, 1).join(), "1,0,0", "fill");
+	assert.sameValue([1, 2, 3].fill(9, -1).join(), "1,2,9", "fill negative");
+	assert.sameValue([1, 2, 3].find(function(x) { return x > 1; }), 2, "find");
+	assert.sameValue([1, 2].findIndex(function(x) { return x > 5; }), -1, "findIndex");
+	assert.sameValue([, 1].findIndex(function(x) { return x === undefined; }), 0, "findIndex visits holes");
+	assert.sameValue([NaN].includes(NaN), true, "includes NaN");
+	assert.sameValue([NaN].indexOf(NaN), -1, "indexOf NaN");
+	assert.sameValue([1, 2, 3].includes(1, 1), false, "includes fromIndex");
+	assert.sameValue([1, [2, [3, [4]]]].flat().length, 3, "flat");
+	assert.sameValue([1, [2, [3, [4]]]].flat(Infinity).join(), "1,2,3,4", "flat Infinity");
+	assert.sameValue([1, , 2].flat().length, 2, "flat skips holes");
+	assert.sameValue([1, 2].flatMap(function(x) { return [x, [x * 2]]; }).length, 4, "flatMap");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
@@ -0,0 +1,76 @@
+package goja
+
+// async函数的一次调用，函数体在生成器的栈帧中执行，每个await暂停一次
+type asyncFunction struct {
+	gen     *generatorObject
+	promise *Promise
+	// 正在等待的promise
+	awaited *Promise
+}
+
+// 创建async函数调用的状态，返回的promise在函数体结束时决议
+func (r *Runtime) newAsyncFunction() *asyncFunction {
+	o := &Object{runtime: r}
+
+	g := &generatorObject{}
+	g.class = classObject
+	g.val = o
+	g.extensible = true
+	o.self = g
+	g.init()
+	return &asyncFunction{
+		gen:     g,
+		promise: r.newPromise(r.global.PromisePrototype),
+	}
+}
+// 恢复执行函数体直到下一个await或函数结束，异常时拒绝返回的promise
+func (a *asyncFunction) step(mode resumeMode, v Value, ex *Exception) {
+	r := a.promise.val.runtime
+	var res Value
+	var done bool
+	if ex1 := r.vm.try(func() {
+		res, done = a.gen.resumeWith(mode, v, ex)
+	}); ex1 != nil {
+		a.promise.exception = ex1
+		a.promise.reject(ex1.val)
+		return
+	}
+	if done {
+		a.promise.resolve(res)
+		return
+	}
+	var p *Object
+	if ex1 := r.vm.try(func() {
+		p = r.promiseResolve(r.global.Promise, res)
+	}); ex1 != nil {
+		a.step(resumeThrow, ex1.val, ex1)
+		return
+	}
+	a.awaited = p.self.(*Promise)
+	a.awaited.addReactions(a.onFulfilled, a.onRejected, nil)
+}
+// 等待的promise兑现，以其结果继续执行
+func (a *asyncFunction) onFulfilled(call FunctionCall) Value {
+	a.awaited = nil
+	a.step(resumeNext, call.Argument(0), nil)
+	return _undefined
+}
+// 等待的promise被拒绝，在await处抛出异常。如果promise来自另一个async函数中的异常，沿用其调用栈
+func (a *asyncFunction) onRejected(call FunctionCall) Value {
+	reason := call.Argument(0)
+	var ex *Exception
+	if p := a.awaited; p.exception != nil {
+		ex = &Exception{
+			val:   reason,
+			stack: append([]stackFrame(nil), p.exception.stack...),
+		}
+	}
+	a.awaited = nil
+	a.step(resumeThrow, reason, ex)
+	return _undefined
+}
+// %AsyncFunction.prototype%，没有对应的全局构造函数
+func (r *Runtime) initAsyncFunctions() {
+	r.global.AsyncFunctionPrototype = r.newBaseObject(r.global.FunctionPrototype, classObject).val
+	r.global.AsyncFunctionPrototype.self._putSym(symToStringTag, asciiString("AsyncFunction"), false, false, true)
+}
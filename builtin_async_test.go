@@ -0,0 +1,132 @@
+package goja
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAsyncFunctionOrder(t *testing.T) {
+	const SCRIPT = `
+	var log = [];
+	async function a1() {
+		log.push(1);
+		await a2();
+		log.push(2);
+	}
+	async function a2() {
+		log.push(3);
+	}
+	log.push(4);
+	a1();
+	new Promise(function(resolve) {
+		log.push(5);
+		resolve();
+	}).then(() => log.push(6)).then(() => log.push(7));
+	log.push(8);
+	`
+	testPromiseScript(SCRIPT, "log.join()", "4,1,3,5,8,2,6,7", t)
+}
+
+func TestAsyncFunctionResult(t *testing.T) {
+	const SCRIPT = `
+	var log = [];
+	async function f(x) {
+		var y = await x;
+		try {
+			await Promise.reject(new Error("boom"));
+		} catch (e) {
+			log.push(e.message);
+		} finally {
+			log.push(await "f");
+		}
+		return y * 2;
+	}
+	const g = async a => await f(a) + 1;
+	class C {
+		async m() {
+			return this.v + await 1;
+		}
+	}
+	var c = new C();
+	c.v = 4;
+	g(Promise.resolve(5)).then(v => log.push(v));
+	c.m().then(v => log.push(v));
+	(async function() {
+		await null;
+		throw new TypeError();
+	})().catch(e => log.push(e instanceof TypeError));
+	`
+	testPromiseScript(SCRIPT, "log.join()", "boom,5,true,f,11", t)
+}
+
+func TestAsyncFunctionLoops(t *testing.T) {
+	const SCRIPT = `
+	var res;
+	async function f() {
+		var log = [];
+		for (let i = 0; i < 3; i++) {
+			log.push(await i);
+		}
+		function* gen() {
+			yield 1;
+			yield 2;
+		}
+		for (var x of gen()) {
+			log.push(await ("g" + x));
+		}
+		return log.join();
+	}
+	f().then(v => res = v);
+	`
+	testPromiseScript(SCRIPT, "res", "0,1,2,g1,g2", t)
+}
+
+func TestAsyncFunctionPrototype(t *testing.T) {
+	const SCRIPT = `
+	async function f() {}
+	var AsyncFunctionPrototype = Object.getPrototypeOf(f);
+	assert.sameValue(Object.getPrototypeOf(AsyncFunctionPrototype), Function.prototype, "proto");
+	assert.sameValue(Object.prototype.toString.call(f), "[object AsyncFunction]", "toStringTag");
+	assert.sameValue(f.hasOwnProperty("prototype"), false, "no prototype");
+	assert.sameValue(f() instanceof Promise, true, "returns a promise");
+	assert.throws(TypeError, function() { new f(); }, "not a constructor");
+	var async = 1;
+	assert.sameValue(async, 1, "async is an identifier");
+	function await() {
+		return 2;
+	}
+	assert.sameValue(await(), 2, "await is an identifier outside async functions");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestAsyncFunctionExceptionStack(t *testing.T) {
+	const SCRIPT = `
+	async function inner() {
+		await 1;
+		throw new Error("deep");
+	}
+	async function outer() {
+		await inner();
+	}
+	outer;
+	`
+	r := New()
+	v, err := r.RunString(SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, _ := AssertFunction(v)
+	res, err := f(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := res.Export().(*Promise)
+	if p.State() != PromiseStateRejected {
+		t.Fatalf("Unexpected state: %v", p.State())
+	}
+	stack := p.exception.String()
+	if !strings.Contains(stack, "at inner") || !strings.Contains(stack, "at outer") {
+		t.Fatalf("Unexpected stack: %s", stack)
+	}
+}
@@ -0,0 +1,295 @@
+package goja
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// BigInt的最大位数，超过时抛出RangeError
+const maxBigIntBits = 1 << 30
+
+var (
+	bigIntZero = big.NewInt(0)
+	bigIntOne  = big.NewInt(1)
+)
+
+// StringToBigInt：支持十进制(可带符号)和0x、0o、0b前缀，空字符串为0，无法解析时返回nil
+func stringToBigInt(s string) *big.Int {
+	s = strings.TrimFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '\uFEFF'
+	})
+	if s == "" {
+		return new(big.Int)
+	}
+	base := 10
+	if len(s) > 2 && s[0] == '0' {
+		switch s[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if base != 10 {
+			s = s[2:]
+		}
+	}
+	if base != 10 || s[0] == '+' || s[0] == '-' {
+		// 带前缀时不允许符号，符号后面必须是数字
+		digits := s
+		if base == 10 {
+			digits = s[1:]
+		}
+		if digits == "" || digits[0] == '+' || digits[0] == '-' {
+			return nil
+		}
+	}
+	for _, c := range s {
+		if c == '_' {
+			return nil
+		}
+	}
+	b, ok := new(big.Int).SetString(s, base)
+	if !ok {
+		return nil
+	}
+	return b
+}
+// 比较BigInt和数字，f为NaN时ok为false
+func compareBigIntFloat(b *big.Int, f float64) (c int, ok bool) {
+	if math.IsNaN(f) {
+		return 0, false
+	}
+	if math.IsInf(f, 1) {
+		return -1, true
+	}
+	if math.IsInf(f, -1) {
+		return 1, true
+	}
+	return new(big.Float).SetInt(b).Cmp(big.NewFloat(f)), true
+}
+// 比较BigInt和另一个原始值，字符串先转换为BigInt，无法比较时ok为false
+func compareBigInt(b *big.Int, v Value) (c int, ok bool) {
+	switch v := v.(type) {
+	case *valueBigInt:
+		return b.Cmp((*big.Int)(v)), true
+	case valueString:
+		if o := stringToBigInt(v.String()); o != nil {
+			return b.Cmp(o), true
+		}
+		return 0, false
+	}
+	return compareBigIntFloat(b, v.ToFloat())
+}
+// BigInt转换为最接近的数字
+func bigIntToFloat(b *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(b).Float64()
+	return f
+}
+// ToNumeric：对象先转换为原始值，BigInt保持不变，其它值转换为数字
+func toNumeric(v Value) Value {
+	if o, ok := v.(*Object); ok {
+		v = o.self.toPrimitiveNumber()
+	}
+	if b, ok := v.(*valueBigInt); ok {
+		return b
+	}
+	return v.ToNumber()
+}
+// 检查BigInt结果的大小
+func (r *Runtime) checkBigIntSize(b *big.Int) *valueBigInt {
+	if b.BitLen() > maxBigIntBits {
+		panic(r.newError(r.global.RangeError, "Maximum BigInt size exceeded"))
+	}
+	return (*valueBigInt)(b)
+}
+// BigInt移位，移位数为负时反向移动
+func (r *Runtime) bigIntShift(x, y *big.Int, left bool) Value {
+	if y.Sign() < 0 {
+		left = !left
+		y = new(big.Int).Neg(y)
+	}
+	if !left {
+		if !y.IsInt64() || y.Int64() > int64(x.BitLen()) {
+			if x.Sign() < 0 {
+				return (*valueBigInt)(big.NewInt(-1))
+			}
+			return (*valueBigInt)(new(big.Int))
+		}
+		return (*valueBigInt)(new(big.Int).Rsh(x, uint(y.Int64())))
+	}
+	if x.Sign() == 0 {
+		return (*valueBigInt)(new(big.Int))
+	}
+	if !y.IsInt64() || y.Int64() > maxBigIntBits {
+		panic(r.newError(r.global.RangeError, "Maximum BigInt size exceeded"))
+	}
+	return r.checkBigIntSize(new(big.Int).Lsh(x, uint(y.Int64())))
+}
+// ToBigInt：布尔值和字符串可以转换，数字、undefined、null和symbol抛出TypeError
+func (r *Runtime) toBigInt(v Value) *big.Int {
+	if o, ok := v.(*Object); ok {
+		v = o.self.toPrimitiveNumber()
+	}
+	switch v := v.(type) {
+	case *valueBigInt:
+		return (*big.Int)(v)
+	case valueBool:
+		if v {
+			return bigIntOne
+		}
+		return bigIntZero
+	case valueString:
+		if b := stringToBigInt(v.String()); b != nil {
+			return b
+		}
+		panic(r.newError(r.global.SyntaxError, "Cannot convert %s to a BigInt", v.String()))
+	case *valueSymbol:
+		r.typeErrorResult(true, "Cannot convert a Symbol value to a BigInt")
+	}
+	r.typeErrorResult(true, "Cannot convert %s to a BigInt", v.String())
+	return nil
+}
+// NumberToBigInt：数字必须是整数
+func (r *Runtime) numberToBigInt(v Value) *valueBigInt {
+	if i, ok := v.assertInt(); ok {
+		return (*valueBigInt)(big.NewInt(i))
+	}
+	f := v.ToFloat()
+	if math.IsNaN(f) || math.IsInf(f, 0) || f != math.Trunc(f) {
+		panic(r.newError(r.global.RangeError, "The number %s cannot be converted to a BigInt because it is not an integer", v.String()))
+	}
+	b, _ := big.NewFloat(f).Int(nil)
+	return (*valueBigInt)(b)
+}
+// BigInt导出为数字类型或big.Int，值超出目标类型范围时返回错误，ok为false表示不能直接转换
+func bigIntToReflectValue(b *big.Int, typ reflect.Type) (rv reflect.Value, ok bool, err error) {
+	if typ == reflectTypeBigInt.Elem() {
+		return reflect.ValueOf(new(big.Int).Set(b)).Elem(), true, nil
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		rv = reflect.New(typ).Elem()
+		if !b.IsInt64() || rv.OverflowInt(b.Int64()) {
+			return reflect.Value{}, true, fmt.Errorf("BigInt %s is out of range for %v", b.String(), typ)
+		}
+		rv.SetInt(b.Int64())
+		return rv, true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		rv = reflect.New(typ).Elem()
+		if !b.IsUint64() || rv.OverflowUint(b.Uint64()) {
+			return reflect.Value{}, true, fmt.Errorf("BigInt %s is out of range for %v", b.String(), typ)
+		}
+		rv.SetUint(b.Uint64())
+		return rv, true, nil
+	case reflect.Float32, reflect.Float64:
+		rv = reflect.New(typ).Elem()
+		rv.SetFloat(bigIntToFloat(b))
+		return rv, true, nil
+	}
+	return reflect.Value{}, false, nil
+}
+// BigInt(value)
+func (r *Runtime) builtin_BigInt(call FunctionCall) Value {
+	v := call.Argument(0)
+	if o, ok := v.(*Object); ok {
+		v = o.self.toPrimitiveNumber()
+	}
+	switch v.(type) {
+	case valueInt, valueFloat:
+		return r.numberToBigInt(v)
+	}
+	return (*valueBigInt)(r.toBigInt(v))
+}
+// BigInt不能作为构造函数使用
+func (r *Runtime) builtin_newBigInt(args []Value) *Object {
+	r.typeErrorResult(true, "BigInt is not a constructor")
+	panic("Unreachable")
+}
+// 取出this对应的BigInt值
+func (r *Runtime) thisBigIntValue(v Value, method string) *big.Int {
+	switch o := v.(type) {
+	case *valueBigInt:
+		return (*big.Int)(o)
+	case *Object:
+		if p, ok := o.self.(*primitiveValueObject); ok {
+			if b, ok := p.pValue.(*valueBigInt); ok {
+				return (*big.Int)(b)
+			}
+		}
+	}
+	r.typeErrorResult(true, "BigInt.prototype.%s requires that 'this' be a BigInt", method)
+	return nil
+}
+// 读取asIntN/asUintN的位数参数
+func (r *Runtime) bigIntBits(v Value) uint {
+	bits := r.toIndex(v, "Invalid value: not (convertible to) a safe integer")
+	if bits > maxBigIntBits {
+		panic(r.newError(r.global.RangeError, "Maximum BigInt size exceeded"))
+	}
+	return uint(bits)
+}
+// BigInt.asUintN(bits, bigint)：取低bits位作为无符号数
+func (r *Runtime) bigInt_asUintN(call FunctionCall) Value {
+	bits := r.bigIntBits(call.Argument(0))
+	b := r.toBigInt(call.Argument(1))
+	mod := new(big.Int).Lsh(bigIntOne, bits)
+	return (*valueBigInt)(new(big.Int).Mod(b, mod))
+}
+// BigInt.asIntN(bits, bigint)：取低bits位作为有符号数
+func (r *Runtime) bigInt_asIntN(call FunctionCall) Value {
+	bits := r.bigIntBits(call.Argument(0))
+	b := r.toBigInt(call.Argument(1))
+	if bits == 0 {
+		return (*valueBigInt)(new(big.Int))
+	}
+	mod := new(big.Int).Lsh(bigIntOne, bits)
+	res := new(big.Int).Mod(b, mod)
+	if res.Bit(int(bits-1)) == 1 {
+		res.Sub(res, mod)
+	}
+	return (*valueBigInt)(res)
+}
+// BigInt.prototype.toString([radix])
+func (r *Runtime) bigIntProto_toString(call FunctionCall) Value {
+	b := r.thisBigIntValue(call.This, "toString")
+	radix := 10
+	if arg := call.Argument(0); arg != _undefined {
+		radix = int(arg.ToInteger())
+		if radix < 2 || radix > 36 {
+			panic(r.newError(r.global.RangeError, "toString() radix argument must be between 2 and 36"))
+		}
+	}
+	return asciiString(b.Text(radix))
+}
+// BigInt.prototype.toLocaleString([locales[, options]])
+func (r *Runtime) bigIntProto_toLocaleString(call FunctionCall) Value {
+	b := r.thisBigIntValue(call.This, "toLocaleString")
+	nf := r.newNumberFormat(call.Argument(0), call.Argument(1))
+	return newStringValue(nf.formatBigInt(b))
+}
+// BigInt.prototype.valueOf()
+func (r *Runtime) bigIntProto_valueOf(call FunctionCall) Value {
+	return (*valueBigInt)(r.thisBigIntValue(call.This, "valueOf"))
+}
+// BigInt类实现
+func (r *Runtime) initBigInt() {
+	r.global.BigIntPrototype = r.newBaseObject(r.global.ObjectPrototype, classObject).val
+	o := r.global.BigIntPrototype.self
+	o._putProp("toLocaleString", r.newNativeFunc(r.bigIntProto_toLocaleString, nil, "toLocaleString", nil, 0), true, false, true)
+	o._putProp("toString", r.newNativeFunc(r.bigIntProto_toString, nil, "toString", nil, 0), true, false, true)
+	o._putProp("valueOf", r.newNativeFunc(r.bigIntProto_valueOf, nil, "valueOf", nil, 0), true, false, true)
+	o._putSym(symToStringTag, asciiString(classBigInt), false, false, true)
+
+	r.global.BigInt = r.newNativeFunc(r.builtin_BigInt, r.builtin_newBigInt, "BigInt", r.global.BigIntPrototype, 1)
+	o = r.global.BigInt.self
+	o._putProp("asIntN", r.newNativeFunc(r.bigInt_asIntN, nil, "asIntN", nil, 2), true, false, true)
+	o._putProp("asUintN", r.newNativeFunc(r.bigInt_asUintN, nil, "asUintN", nil, 2), true, false, true)
+
+	r.addToGlobal("BigInt", r.global.BigInt)
+}
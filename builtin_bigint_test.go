@@ -0,0 +1,199 @@
+package goja
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestBigIntArithmetic(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(typeof 1n, "bigint");
+	assert.sameValue(typeof Object(1n), "object");
+	assert.sameValue(9007199254740993n + 1n, 9007199254740994n);
+	assert.sameValue(0x1Fn, 31n);
+	assert.sameValue(7n / 2n, 3n);
+	assert.sameValue(-7n / 2n, -3n);
+	assert.sameValue(-7n % 2n, -1n);
+	assert.sameValue(-(5n), -5n);
+	assert.sameValue(~5n, -6n);
+	assert.sameValue(6n & 3n, 2n);
+	assert.sameValue(6n | 3n, 7n);
+	assert.sameValue(6n ^ 3n, 5n);
+	assert.sameValue(1n << 64n, 18446744073709551616n);
+	assert.sameValue(-9n >> 1n, -5n);
+	assert.sameValue(1n << -1n, 0n);
+	var i = 1n; i++;
+	assert.sameValue(i, 2n);
+	assert.sameValue("x" + 10n, "x10");
+	assert.sameValue(String(123456789012345678901234567890n), "123456789012345678901234567890");
+	assert.sameValue((255n).toString(16), "ff");
+	assert.sameValue((12345678n).toLocaleString("en-US"), "12,345,678");
+	assert.sameValue((123456789012345678901234567890n).toLocaleString("de-DE"), "123.456.789.012.345.678.901.234.567.890");
+	assert.sameValue(new Intl.NumberFormat("en-US").format(1n), "1");
+	assert.sameValue(new Intl.NumberFormat("en-US").format(123456789012345678901234567890n), "123,456,789,012,345,678,901,234,567,890");
+	assert.sameValue(new Intl.NumberFormat("en-US").format(Object(-5n)), "-5");
+
+	assert.throws(TypeError, function() { 1n + 1 });
+	assert.throws(TypeError, function() { 1n * 1.5 });
+	assert.throws(TypeError, function() { 1n >>> 0n });
+	assert.throws(TypeError, function() { +1n });
+	assert.throws(TypeError, function() { Math.abs(1n) });
+	assert.throws(RangeError, function() { 1n / 0n });
+	assert.throws(RangeError, function() { 1n % 0n });
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestBigIntComparison(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(1n == 1, true);
+	assert.sameValue(1n === 1, false);
+	assert.sameValue(1n < 2, true);
+	assert.sameValue(2n > 1.5, true);
+	assert.sameValue(1n < NaN, false);
+	assert.sameValue(1n < Infinity, true);
+	assert.sameValue("10" == 10n, true);
+	assert.sameValue("x" == 10n, false);
+	assert.sameValue(0n == false, true);
+	assert.sameValue(9007199254740993n == 9007199254740992, false);
+	assert.sameValue(Object(5n) == 5n, true);
+	assert.sameValue([3n, 1n, 2n].sort(function(a, b) { return a < b ? -1 : 1 }).join(), "1,2,3");
+	assert.sameValue(!!0n, false);
+	assert.sameValue(!!1n, true);
+
+	var m = new Map();
+	m.set(1n, "a");
+	assert.sameValue(m.get(1n), "a");
+	assert.sameValue(m.has(1), false);
+	assert.sameValue(m.has("1"), false);
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestBigIntBuiltin(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(BigInt(10), 10n);
+	assert.sameValue(BigInt("0x10"), 16n);
+	assert.sameValue(BigInt(" -42 "), -42n);
+	assert.sameValue(BigInt(""), 0n);
+	assert.sameValue(BigInt(true), 1n);
+	assert.sameValue(BigInt.asUintN(8, 257n), 1n);
+	assert.sameValue(BigInt.asUintN(64, -1n), 18446744073709551615n);
+	assert.sameValue(BigInt.asIntN(8, 255n), -1n);
+	assert.sameValue(BigInt.asIntN(64, 9223372036854775808n), -9223372036854775808n);
+	assert.sameValue(Object.prototype.toString.call(1n), "[object BigInt]");
+	assert.sameValue(Object(3n).valueOf(), 3n);
+
+	assert.throws(TypeError, function() { new BigInt(1) });
+	assert.throws(RangeError, function() { BigInt(1.5) });
+	assert.throws(SyntaxError, function() { BigInt("1.5") });
+	assert.throws(TypeError, function() { BigInt(undefined) });
+	assert.throws(TypeError, function() { BigInt(Symbol()) });
+	assert.throws(TypeError, function() { BigInt.prototype.valueOf.call(1) });
+	assert.throws(TypeError, function() { JSON.stringify({a: 1n}) });
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestBigIntTypedArrays(t *testing.T) {
+	const SCRIPT = `
+	var a = new BigInt64Array(2);
+	a[0] = -1n;
+	a[1] = 9223372036854775808n;
+	assert.sameValue(a[0], -1n);
+	assert.sameValue(a[1], -9223372036854775808n);
+	var u = new BigUint64Array(a.buffer);
+	assert.sameValue(u[0], 18446744073709551615n);
+	assert.sameValue(BigInt64Array.BYTES_PER_ELEMENT, 8);
+	assert.sameValue(new BigInt64Array([3n, 1n, 2n]).sort().join(), "1,2,3");
+
+	var dv = new DataView(new ArrayBuffer(8));
+	dv.setBigInt64(0, -2n);
+	assert.sameValue(dv.getBigInt64(0), -2n);
+	assert.sameValue(dv.getBigUint64(0), 18446744073709551614n);
+	dv.setBigUint64(0, 1n, true);
+	assert.sameValue(dv.getUint8(0), 1);
+
+	assert.throws(TypeError, function() { a[0] = 1 });
+	assert.throws(TypeError, function() { new Int8Array(1)[0] = 1n });
+	assert.throws(TypeError, function() { new BigInt64Array([1]) });
+	assert.throws(TypeError, function() { new Int32Array(a) });
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestBigIntToValue(t *testing.T) {
+	r := New()
+	b, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	r.Set("b", b)
+	r.Set("u", uint64(math.MaxUint64))
+	r.Set("i", int64(math.MaxInt64))
+	v, err := r.RunString(`typeof b + " " + (b + 1n) + " " + typeof u + " " + u + " " + typeof i`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := v.String(); s != "bigint 123456789012345678901234567891 bigint 18446744073709551615 number" {
+		t.Fatalf("Unexpected result: %q", s)
+	}
+
+	v, err = r.RunString(`b * 2n`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp, ok := v.Export().(*big.Int)
+	if !ok {
+		t.Fatalf("Unexpected export type: %T", v.Export())
+	}
+	if exp.String() != "246913578024691357802469135780" {
+		t.Fatalf("Unexpected value: %s", exp)
+	}
+	if b.String() != "123456789012345678901234567890" {
+		t.Fatal("Original value was modified")
+	}
+}
+
+func TestBigIntExportTo(t *testing.T) {
+	r := New()
+	var i64 int64
+	if err := r.ExportTo(r.ToValue(big.NewInt(math.MinInt64)), &i64); err != nil {
+		t.Fatal(err)
+	}
+	if i64 != math.MinInt64 {
+		t.Fatalf("Unexpected value: %d", i64)
+	}
+
+	var u64 uint64
+	v, err := r.RunString(`18446744073709551615n`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.ExportTo(v, &u64); err != nil {
+		t.Fatal(err)
+	}
+	if u64 != math.MaxUint64 {
+		t.Fatalf("Unexpected value: %d", u64)
+	}
+	if err := r.ExportTo(v, &i64); err == nil {
+		t.Fatal("Expected an overflow error")
+	}
+
+	var b *big.Int
+	if err := r.ExportTo(v, &b); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "18446744073709551615" {
+		t.Fatalf("Unexpected value: %s", b)
+	}
+
+	var f func(*big.Int) *big.Int
+	if _, err := r.RunString(`function twice(x) { return BigInt(x) * 2n }`); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.ExportTo(r.Get("twice"), &f); err != nil {
+		t.Fatal(err)
+	}
+	if res := f(big.NewInt(math.MaxInt64)); res.String() != "18446744073709551614" {
+		t.Fatalf("Unexpected value: %s", res)
+	}
+}
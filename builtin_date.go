@@ -234,7 +234,8 @@ func (r *Runtime) dateproto_toLocaleString(call FunctionCall) Value {
 	obj := r.toObject(call.This)
 	if d, ok := obj.self.(*dateObject); ok {
 		if d.isSet {
-			return asciiString(d.time.Format(datetimeLayout_en_GB))
+			df := r.newDateTimeFormat(call.Argument(0), call.Argument(1), "any", "all")
+			return newStringValue(df.format(d.time))
 		} else {
 			return stringInvalidDate
 		}
@@ -247,7 +248,8 @@ func (r *Runtime) dateproto_toLocaleDateString(call FunctionCall) Value {
 	obj := r.toObject(call.This)
 	if d, ok := obj.self.(*dateObject); ok {
 		if d.isSet {
-			return asciiString(d.time.Format(dateLayout_en_GB))
+			df := r.newDateTimeFormat(call.Argument(0), call.Argument(1), "date", "date")
+			return newStringValue(df.format(d.time))
 		} else {
 			return stringInvalidDate
 		}
@@ -260,7 +262,8 @@ func (r *Runtime) dateproto_toLocaleTimeString(call FunctionCall) Value {
 	obj := r.toObject(call.This)
 	if d, ok := obj.self.(*dateObject); ok {
 		if d.isSet {
-			return asciiString(d.time.Format(timeLayout_en_GB))
+			df := r.newDateTimeFormat(call.Argument(0), call.Argument(1), "time", "time")
+			return newStringValue(df.format(d.time))
 		} else {
 			return stringInvalidDate
 		}
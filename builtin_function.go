@@ -32,6 +32,10 @@ repeat:
 		return newStringValue(fmt.Sprintf("function %s() { [native code] }", f.nameProp.get(call.This).ToString()))
 	case *boundFuncObject:
 		return newStringValue(fmt.Sprintf("function %s() { [native code] }", f.nameProp.get(call.This).ToString()))
+	case *Proxy:
+		if _, ok := f.assertCallable(); ok {
+			return newStringValue("function () { [native code] }")
+		}
 	case *lazyObject:
 		obj.self = f.create(obj)
 		goto repeat
@@ -124,7 +128,9 @@ repeat:
 	switch ff := f.(type) {
 	case *funcObject:
 		fcall = ff.Call
-		construct = ff.construct
+		if ff.isConstructor() {
+			construct = ff.construct
+		}
 	case *nativeFuncObject:
 		fcall = ff.f
 		construct = ff.construct
@@ -158,6 +164,16 @@ repeat:
 	return v
 }
 // Function全局类的实现
+//Function.prototype[@@hasInstance]
+//instanceof的默认实现，沿原型链查找this.prototype
+func (r *Runtime) functionproto_hasInstance(call FunctionCall) Value {
+	if o, ok := call.This.(*Object); ok {
+		if _, ok := o.self.assertCallable(); ok {
+			return r.toBoolean(o.self.hasInstance(call.Argument(0)))
+		}
+	}
+	return valueFalse
+}
 func (r *Runtime) initFunction() {
 	o := r.global.FunctionPrototype.self
 	o.(*nativeFuncObject).prototype = r.global.ObjectPrototype
@@ -165,6 +181,8 @@ func (r *Runtime) initFunction() {
 	o._putProp("apply", r.newNativeFunc(r.functionproto_apply, nil, "apply", nil, 2), true, false, true)
 	o._putProp("call", r.newNativeFunc(r.functionproto_call, nil, "call", nil, 1), true, false, true)
 	o._putProp("bind", r.newNativeFunc(r.functionproto_bind, nil, "bind", nil, 1), true, false, true)
+	r.global.hasInstance = r.newNativeFunc(r.functionproto_hasInstance, nil, "[Symbol.hasInstance]", nil, 1)
+	o._putSym(symHasInstance, r.global.hasInstance, false, false, false)
 
 	r.global.Function = r.newNativeFuncConstruct(r.builtin_Function, "Function", r.global.FunctionPrototype, 1)
 	r.addToGlobal("Function", r.global.Function)
@@ -0,0 +1,308 @@
+package goja
+
+const classGenerator = "Generator"
+
+type generatorState int
+
+const (
+	generatorStateSuspendedStart generatorState = iota
+	generatorStateSuspendedYield
+	generatorStateExecuting
+	generatorStateCompleted
+)
+
+type resumeMode int
+
+const (
+	resumeNext resumeMode = iota
+	resumeReturn
+	resumeThrow
+)
+
+// 生成器暂停时保存的栈帧，位置都是相对于帧的起始位置
+type generatorFrame struct {
+	ctx       context
+	stack     []Value
+	iterStack []iterStackItem
+	refStack  []ref
+	tryStack  []*tryFrame
+}
+
+// 生成器对象，调用function*声明的函数时创建
+type generatorObject struct {
+	baseObject
+	state generatorState
+	frame generatorFrame
+
+	// 恢复执行时各个栈的起始位置
+	base, ctxBase, iterBase, refBase, tryBase int
+
+	// yield的值，delegate不为nil时是yield*中内层迭代器返回的结果对象
+	yielded  Value
+	delegate *Object
+}
+// 保存从callee开始的栈帧并退出当前函数
+func (g *generatorObject) suspend(vm *vm) {
+	f := &g.frame
+	base := vm.sb - 1
+	vm.saveCtx(&f.ctx)
+	f.ctx.sb -= base
+	f.stack = append(f.stack[:0], vm.stack[base:vm.sp]...)
+	f.iterStack = append(f.iterStack[:0], vm.iterStack[g.iterBase:]...)
+	f.refStack = append(f.refStack[:0], vm.refStack[g.refBase:]...)
+	f.tryStack = append(f.tryStack[:0], vm.tryStack[g.tryBase:]...)
+	ctxOffset := len(vm.callStack)
+	for _, t := range f.tryStack {
+		t.scope.shift(-base, -ctxOffset, -g.iterBase, -g.refBase, -g.tryBase)
+	}
+
+	iterTail := vm.iterStack[g.iterBase:]
+	for i := range iterTail {
+		iterTail[i] = iterStackItem{}
+	}
+	vm.iterStack = vm.iterStack[:g.iterBase]
+	refTail := vm.refStack[g.refBase:]
+	for i := range refTail {
+		refTail[i] = nil
+	}
+	vm.refStack = vm.refStack[:g.refBase]
+	tryTail := vm.tryStack[g.tryBase:]
+	for i := range tryTail {
+		tryTail[i] = nil
+	}
+	vm.tryStack = vm.tryStack[:g.tryBase]
+	stackTail := vm.stack[base:vm.sp]
+	for i := range stackTail {
+		stackTail[i] = nil
+	}
+	vm.sp = base
+	vm.popCtx()
+}
+// 把保存的栈帧放回vm并继续执行，返回yield或return的值以及是否已结束
+func (g *generatorObject) resume(mode resumeMode, v Value) (Value, bool) {
+	return g.resumeWith(mode, v, nil)
+}
+// 同resume，以throw恢复且ex不为nil时抛出ex，保留其中的调用栈
+func (g *generatorObject) resumeWith(mode resumeMode, v Value, ex *Exception) (Value, bool) {
+	r := g.val.runtime
+	vm := r.vm
+	switch g.state {
+	case generatorStateExecuting:
+		r.typeErrorResult(true, "Generator is already running")
+	case generatorStateSuspendedStart:
+		if mode != resumeNext {
+			g.complete()
+		}
+	}
+	if g.state == generatorStateCompleted {
+		switch mode {
+		case resumeReturn:
+			return v, true
+		case resumeThrow:
+			if ex != nil {
+				panic(ex)
+			}
+			panic(v)
+		}
+		return _undefined, true
+	}
+
+	start := g.state == generatorStateSuspendedStart
+	prevGen := vm.gen
+	vm.gen = g
+	g.state = generatorStateExecuting
+	defer func() {
+		vm.gen = prevGen
+		if g.state == generatorStateExecuting {
+			// 生成器中抛出了异常
+			g.complete()
+		}
+	}()
+
+	f := &g.frame
+	pc := vm.pc
+	g.base = vm.sp
+	vm.stack.expand(g.base + len(f.stack))
+	copy(vm.stack[g.base:], f.stack)
+	vm.sp = g.base + len(f.stack)
+	vm.pc = -1
+	vm.pushCtx()
+	g.ctxBase = len(vm.callStack)
+	ctx := f.ctx
+	ctx.sb += g.base
+	vm.restoreCtx(&ctx)
+
+	g.iterBase = len(vm.iterStack)
+	vm.iterStack = append(vm.iterStack, f.iterStack...)
+	g.refBase = len(vm.refStack)
+	vm.refStack = append(vm.refStack, f.refStack...)
+	g.tryBase = len(vm.tryStack)
+	for _, t := range f.tryStack {
+		t.scope.shift(g.base, g.ctxBase, g.iterBase, g.refBase, g.tryBase)
+	}
+	vm.tryStack = append(vm.tryStack, f.tryStack...)
+	frames := vm.tryStack[g.tryBase:]
+
+	if !start {
+		// yield指令之后是return的处理代码
+		switch mode {
+		case resumeNext:
+			vm.push(v)
+			vm.pc++
+		case resumeReturn:
+			vm.push(v)
+			vm.pc += 2
+		}
+	} else {
+		vm.pc++
+	}
+	vm.resumeTryFrames(frames, func() {
+		if mode == resumeThrow {
+			if ex != nil {
+				panic(ex)
+			}
+			panic(v)
+		}
+		vm.run()
+	})
+	vm.pc = pc
+	vm.halt = false
+
+	if vm.suspended {
+		vm.suspended = false
+		g.state = generatorStateSuspendedYield
+		return g.yielded, false
+	}
+	g.complete()
+	return vm.pop(), true
+}
+// 依次重新进入暂停时所在的各层try，最内层执行inner
+func (vm *vm) resumeTryFrames(frames []*tryFrame, inner func()) {
+	if len(frames) == 0 {
+		inner()
+		return
+	}
+	vm.runTryFrame(frames[0], func() {
+		vm.resumeTryFrames(frames[1:], inner)
+	})
+	if !vm.suspended {
+		vm.run()
+	}
+}
+// 生成器执行结束，释放保存的栈帧
+func (g *generatorObject) complete() {
+	g.state = generatorStateCompleted
+	g.frame = generatorFrame{}
+	g.yielded = nil
+	g.delegate = nil
+}
+// 构造{value, done}，yield*委托时直接返回内层的结果
+func (g *generatorObject) result(v Value, done bool) Value {
+	if !done && g.delegate != nil {
+		return v
+	}
+	return g.val.runtime.createIterResultObject(v, done)
+}
+// next/return/throw的共同实现，yield*委托时先交给内层迭代器处理
+func (g *generatorObject) step(mode resumeMode, v Value) Value {
+	r := g.val.runtime
+	if g.state == generatorStateExecuting {
+		r.typeErrorResult(true, "Generator is already running")
+	}
+	if iter := g.delegate; iter != nil {
+		var res Value
+		switch mode {
+		case resumeNext:
+			res = r.invoke(iter, "next", v)
+		case resumeThrow:
+			throw := r.toHandler(iter.self.getStr("throw"))
+			if throw == nil {
+				g.delegate = nil
+				r.iteratorClose(iter)
+				mode = resumeThrow
+				v = r.NewTypeError("The iterator does not provide a 'throw' method")
+				break
+			}
+			res = throw(FunctionCall{This: iter, Arguments: []Value{v}})
+		case resumeReturn:
+			ret := r.toHandler(iter.self.getStr("return"))
+			if ret == nil {
+				g.delegate = nil
+				break
+			}
+			res = ret(FunctionCall{This: iter, Arguments: []Value{v}})
+		}
+		if g.delegate != nil {
+			resObj, ok := res.(*Object)
+			if !ok {
+				r.typeErrorResult(true, "Iterator result %s is not an object", res.String())
+			}
+			if !resObj.self.getStr("done").ToBoolean() {
+				return resObj
+			}
+			g.delegate = nil
+			if v = resObj.self.getStr("value"); v == nil {
+				v = _undefined
+			}
+			if mode == resumeThrow {
+				mode = resumeNext
+			}
+		}
+	}
+	value, done := g.resume(mode, v)
+	return g.result(value, done)
+}
+// 取出this对应的生成器
+func (r *Runtime) thisGenerator(v Value, method string) *generatorObject {
+	if o, ok := v.(*Object); ok {
+		if g, ok := o.self.(*generatorObject); ok {
+			return g
+		}
+	}
+	r.typeErrorResult(true, "Method [Generator].prototype.%s called on incompatible receiver %s", method, v.String())
+	return nil
+}
+// %GeneratorPrototype%.next实现
+func (r *Runtime) generatorproto_next(call FunctionCall) Value {
+	return r.thisGenerator(call.This, "next").step(resumeNext, call.Argument(0))
+}
+// %GeneratorPrototype%.return实现
+func (r *Runtime) generatorproto_return(call FunctionCall) Value {
+	return r.thisGenerator(call.This, "return").step(resumeReturn, call.Argument(0))
+}
+// %GeneratorPrototype%.throw实现
+func (r *Runtime) generatorproto_throw(call FunctionCall) Value {
+	return r.thisGenerator(call.This, "throw").step(resumeThrow, call.Argument(0))
+}
+// 创建生成器对象，原型取自生成器函数的prototype属性
+func (r *Runtime) newGenerator(f *funcObject) *generatorObject {
+	o := &Object{runtime: r}
+
+	g := &generatorObject{}
+	g.class = classGenerator
+	g.val = o
+	g.extensible = true
+	o.self = g
+	if proto, ok := f.getStr("prototype").(*Object); ok {
+		g.prototype = proto
+	} else {
+		g.prototype = r.global.GeneratorPrototype
+	}
+	g.init()
+	return g
+}
+// 生成器相关的原型，%GeneratorFunction.prototype%没有对应的全局构造函数
+func (r *Runtime) initGenerators() {
+	r.global.GeneratorPrototype = r.newBaseObject(r.global.IteratorPrototype, classObject).val
+	o := r.global.GeneratorPrototype.self
+	o._putProp("next", r.newNativeFunc(r.generatorproto_next, nil, "next", nil, 1), true, false, true)
+	o._putProp("return", r.newNativeFunc(r.generatorproto_return, nil, "return", nil, 1), true, false, true)
+	o._putProp("throw", r.newNativeFunc(r.generatorproto_throw, nil, "throw", nil, 1), true, false, true)
+	o._putSym(symToStringTag, asciiString(classGenerator), false, false, true)
+
+	r.global.GeneratorFunctionPrototype = r.newBaseObject(r.global.FunctionPrototype, classObject).val
+	o = r.global.GeneratorFunctionPrototype.self
+	o._putProp("prototype", r.global.GeneratorPrototype, false, false, true)
+	o._putSym(symToStringTag, asciiString("GeneratorFunction"), false, false, true)
+	r.global.GeneratorPrototype.self._putProp("constructor", r.global.GeneratorFunctionPrototype, false, false, true)
+}
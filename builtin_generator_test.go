@@ -0,0 +1,65 @@
+package goja
+
+import "testing"
+
+func TestGeneratorPrototype(t *testing.T) {
+	const SCRIPT = `
+	function* g() {
+		yield 1;
+	}
+	var it = g();
+	var GeneratorPrototype = Object.getPrototypeOf(g.prototype);
+	assert.sameValue(Object.getPrototypeOf(it), g.prototype, "generator prototype");
+	assert.sameValue(typeof GeneratorPrototype.next, "function", "next");
+	assert.sameValue(it[Symbol.iterator](), it, "iterator returns itself");
+	assert.sameValue(Object.prototype.toString.call(it), "[object Generator]", "toStringTag");
+	assert.sameValue(g.prototype.hasOwnProperty("constructor"), false, "no own constructor");
+	assert.sameValue(Object.getPrototypeOf(g), Object.getPrototypeOf(function*() {}), "GeneratorFunction.prototype");
+	assert.throws(TypeError, function() { GeneratorPrototype.next.call({}); }, "incompatible receiver");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestGeneratorMethods(t *testing.T) {
+	const SCRIPT = `
+	function* g() {
+		yield 1;
+		yield 2;
+	}
+	var it = g();
+	var r = it.return(5);
+	assert.sameValue(r.value, 5, "return before start");
+	assert.sameValue(r.done, true, "return before start done");
+	assert.sameValue(it.next().done, true, "completed");
+
+	it = g();
+	it.next();
+	assert.throws(SyntaxError, function() { it.throw(new SyntaxError()); }, "throw");
+	assert.sameValue(it.next().done, true, "completed after throw");
+
+	var self;
+	function* running() {
+		self.next();
+		yield 1;
+	}
+	self = running();
+	assert.throws(TypeError, function() { self.next(); }, "already running");
+
+	function* fail() {
+		throw new RangeError("x");
+	}
+	it = fail();
+	assert.throws(RangeError, function() { it.next(); }, "exception from body");
+	assert.sameValue(it.next().done, true, "completed after exception");
+
+	class A {
+		*values() {
+			yield this.v;
+		}
+	}
+	var a = new A();
+	a.v = 7;
+	assert.sameValue(a.values().next().value, 7, "generator method");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
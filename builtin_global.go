@@ -336,8 +336,13 @@ func (r *Runtime) initGlobalObject() {
 	o._putProp("Infinity", _positiveInf, false, false, false)
 
 	o._putProp("isNaN", r.newNativeFunc(r.builtin_isNaN, nil, "isNaN", nil, 1), true, false, true)
-	o._putProp("parseInt", r.newNativeFunc(r.builtin_parseInt, nil, "parseInt", nil, 2), true, false, true)
-	o._putProp("parseFloat", r.newNativeFunc(r.builtin_parseFloat, nil, "parseFloat", nil, 1), true, false, true)
+	parseInt := r.newNativeFunc(r.builtin_parseInt, nil, "parseInt", nil, 2)
+	parseFloat := r.newNativeFunc(r.builtin_parseFloat, nil, "parseFloat", nil, 1)
+	o._putProp("parseInt", parseInt, true, false, true)
+	o._putProp("parseFloat", parseFloat, true, false, true)
+	// Number.parseInt和Number.parseFloat与全局函数是同一个对象
+	r.global.Number.self._putProp("parseInt", parseInt, true, false, true)
+	r.global.Number.self._putProp("parseFloat", parseFloat, true, false, true)
 	o._putProp("isFinite", r.newNativeFunc(r.builtin_isFinite, nil, "isFinite", nil, 1), true, false, true)
 	o._putProp("decodeURI", r.newNativeFunc(r.builtin_decodeURI, nil, "decodeURI", nil, 1), true, false, true)
 	o._putProp("decodeURIComponent", r.newNativeFunc(r.builtin_decodeURIComponent, nil, "decodeURIComponent", nil, 1), true, false, true)
@@ -0,0 +1,1112 @@
+package goja
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	classCollator       = "Intl.Collator"
+	classNumberFormat   = "Intl.NumberFormat"
+	classDateTimeFormat = "Intl.DateTimeFormat"
+)
+
+// Intl.Collator对象
+type collatorObject struct {
+	baseObject
+	locale            language.Tag
+	usage             string
+	sensitivity       string
+	numeric           bool
+	ignorePunctuation bool
+	collator          *collate.Collator
+	boundCompare      *Object
+}
+
+// Intl.NumberFormat对象
+type numberFormatObject struct {
+	baseObject
+	locale          language.Tag
+	printer         *message.Printer
+	style           string
+	currency        currency.Unit
+	currencyDisplay string
+	minInt          int
+	minFrac         int
+	maxFrac         int
+	minSig          int // 有效数字位数，未指定时为0，此时按小数位数舍入
+	maxSig          int
+	useGrouping     bool
+	boundFormat     *Object
+}
+
+// Intl.DateTimeFormat对象
+type dateTimeFormatObject struct {
+	baseObject
+	locale   language.Tag
+	data     *dateLocaleData
+	location *time.Location
+	timeZone string
+	hour12   bool
+
+	weekday, year, month, day string
+	hour, minute, second      string
+	timeZoneName              string
+	dateStyle, timeStyle      string
+	boundFormat               *Object
+}
+
+// 解析locales参数，返回第一个有效的语言标签，未指定时使用Runtime的默认语言
+func (r *Runtime) resolveLocale(locales Value) language.Tag {
+	if list := r.canonicalizeLocaleList(locales); len(list) > 0 {
+		return list[0]
+	}
+	return r.locale
+}
+
+// CanonicalizeLocaleList：locales可以是字符串或字符串数组。
+// 格式错误的标签抛出RangeError，格式正确但无法识别的标签不被支持，直接忽略
+func (r *Runtime) canonicalizeLocaleList(locales Value) []language.Tag {
+	if locales == _undefined {
+		return nil
+	}
+	var values []Value
+	if _, ok := locales.assertString(); ok {
+		values = []Value{locales}
+	} else {
+		o := r.toObject(locales)
+		l := toLength(o.self.getStr("length"))
+		for i := int64(0); i < l; i++ {
+			if v := o.self.get(intToValue(i)); v != nil {
+				values = append(values, v)
+			}
+		}
+	}
+	var tags []language.Tag
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		if _, ok := v.assertString(); !ok {
+			if _, ok := v.(*Object); !ok {
+				r.typeErrorResult(true, "Language ID should be string or object.")
+			}
+		}
+		tag, err := language.Parse(v.String())
+		if _, ok := err.(language.ValueError); ok {
+			continue
+		}
+		if err != nil {
+			panic(r.newError(r.global.RangeError, "Incorrect locale information provided"))
+		}
+		if s := tag.String(); !seen[s] {
+			seen[s] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// 将选项参数转换为对象，undefined返回nil
+func (r *Runtime) intlOptions(options Value) *Object {
+	if options == _undefined {
+		return nil
+	}
+	return r.toObject(options)
+}
+
+// 读取字符串选项，值不在allowed中时抛出RangeError
+func (r *Runtime) intlStringOption(options *Object, name string, allowed []string, fallback string) string {
+	if options == nil {
+		return fallback
+	}
+	v := nilSafe(options.self.getStr(name))
+	if v == _undefined {
+		return fallback
+	}
+	s := v.String()
+	for _, a := range allowed {
+		if s == a {
+			return s
+		}
+	}
+	panic(r.newError(r.global.RangeError, "Value %s out of range for %s options property %s", s, "Intl", name))
+}
+
+// 读取布尔选项，ok为false表示未指定
+func (r *Runtime) intlBoolOption(options *Object, name string) (value, ok bool) {
+	if options == nil {
+		return false, false
+	}
+	v := nilSafe(options.self.getStr(name))
+	if v == _undefined {
+		return false, false
+	}
+	return v.ToBoolean(), true
+}
+
+// 读取数值选项，必须在[min, max]范围内
+func (r *Runtime) intlNumberOption(options *Object, name string, min, max, fallback int) int {
+	if options == nil {
+		return fallback
+	}
+	v := nilSafe(options.self.getStr(name))
+	if v == _undefined {
+		return fallback
+	}
+	f := v.ToFloat()
+	if math.IsNaN(f) || f < float64(min) || f > float64(max) {
+		panic(r.newError(r.global.RangeError, "%s value is out of range.", name))
+	}
+	return int(math.Floor(f))
+}
+
+// Intl.getCanonicalLocales()
+func (r *Runtime) intl_getCanonicalLocales(call FunctionCall) Value {
+	tags := r.canonicalizeLocaleList(call.Argument(0))
+	values := make([]Value, len(tags))
+	for i, tag := range tags {
+		values[i] = newStringValue(tag.String())
+	}
+	return r.newArrayValues(values)
+}
+
+// supportedLocalesOf()的实现，supported为nil时支持所有语言
+func (r *Runtime) supportedLocalesOf(call FunctionCall, supported func(language.Tag) bool) Value {
+	var values []Value
+	for _, tag := range r.canonicalizeLocaleList(call.Argument(0)) {
+		if supported == nil || supported(tag) {
+			values = append(values, newStringValue(tag.String()))
+		}
+	}
+	return r.newArrayValues(values)
+}
+
+// 创建绑定到对象上的format/compare函数
+func (r *Runtime) newBoundIntlFunc(f func(FunctionCall) Value, length int) *Object {
+	return r.newNativeFunc(f, nil, "", nil, length)
+}
+
+// 创建Intl对象的基础部分
+func (r *Runtime) initIntlObject(o *baseObject, class string, proto *Object) {
+	v := &Object{runtime: r}
+	o.class = class
+	o.val = v
+	o.extensible = true
+	o.prototype = proto
+	v.self = o
+}
+
+// 创建Intl.Collator
+func (r *Runtime) newCollator(locales, optionsValue Value) *collatorObject {
+	c := &collatorObject{}
+	r.initIntlObject(&c.baseObject, classCollator, r.global.CollatorPrototype)
+	c.val.self = c
+	c.init()
+
+	options := r.intlOptions(optionsValue)
+	c.locale = r.resolveLocale(locales)
+	c.usage = r.intlStringOption(options, "usage", []string{"sort", "search"}, "sort")
+	c.numeric, _ = r.intlBoolOption(options, "numeric")
+	c.sensitivity = r.intlStringOption(options, "sensitivity", []string{"base", "accent", "case", "variant"}, "variant")
+	c.ignorePunctuation, _ = r.intlBoolOption(options, "ignorePunctuation")
+	c.collator = newCollate(c.locale, c.sensitivity, c.numeric)
+	return c
+}
+
+// 按比较强度和numeric选项创建collate.Collator
+func newCollate(tag language.Tag, sensitivity string, numeric bool) *collate.Collator {
+	var opts []collate.Option
+	switch sensitivity {
+	case "base":
+		opts = append(opts, collate.IgnoreCase, collate.IgnoreDiacritics, collate.IgnoreWidth)
+	case "accent":
+		opts = append(opts, collate.IgnoreCase, collate.IgnoreWidth)
+	case "case":
+		opts = append(opts, collate.IgnoreDiacritics, collate.IgnoreWidth)
+	}
+	if numeric {
+		opts = append(opts, collate.Numeric)
+	}
+	return collate.New(tag, opts...)
+}
+
+// 比较两个字符串
+func (c *collatorObject) compare(x, y string) int {
+	if c.ignorePunctuation {
+		x, y = stripPunctuation(x), stripPunctuation(y)
+	}
+	return c.collator.CompareString(norm.NFD.String(x), norm.NFD.String(y))
+}
+
+// 去掉字符串中的标点符号
+func stripPunctuation(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// Intl.Collator()和new Intl.Collator()
+func (r *Runtime) builtin_newCollator(args []Value) *Object {
+	return r.newCollator(argAt(args, 0), argAt(args, 1)).val
+}
+
+// 取出this对应的Collator
+func (r *Runtime) thisCollator(v Value, method string) *collatorObject {
+	if o, ok := v.(*Object); ok {
+		if c, ok := o.self.(*collatorObject); ok {
+			return c
+		}
+	}
+	r.typeErrorResult(true, "Method Intl.Collator.prototype.%s called on incompatible receiver %s", method, v.String())
+	return nil
+}
+
+// Intl.Collator.prototype.compare的getter，返回绑定的比较函数
+func (r *Runtime) collatorProto_getCompare(call FunctionCall) Value {
+	c := r.thisCollator(call.This, "compare")
+	if c.boundCompare == nil {
+		c.boundCompare = r.newBoundIntlFunc(func(call FunctionCall) Value {
+			return intToValue(int64(c.compare(call.Argument(0).String(), call.Argument(1).String())))
+		}, 2)
+	}
+	return c.boundCompare
+}
+
+// Intl.Collator.prototype.resolvedOptions()
+func (r *Runtime) collatorProto_resolvedOptions(call FunctionCall) Value {
+	c := r.thisCollator(call.This, "resolvedOptions")
+	o := r.NewObject()
+	o.self.putStr("locale", newStringValue(c.locale.String()), false)
+	o.self.putStr("usage", newStringValue(c.usage), false)
+	o.self.putStr("sensitivity", newStringValue(c.sensitivity), false)
+	o.self.putStr("ignorePunctuation", r.toBoolean(c.ignorePunctuation), false)
+	o.self.putStr("collation", asciiString("default"), false)
+	o.self.putStr("numeric", r.toBoolean(c.numeric), false)
+	o.self.putStr("caseFirst", asciiString("false"), false)
+	return o
+}
+
+// 货币符号放在数字后面的语言
+var currencySuffixLanguages = map[string]bool{
+	"bg": true, "cs": true, "da": true, "de": true, "es": true, "et": true, "fi": true, "fr": true,
+	"hr": true, "hu": true, "it": true, "lt": true, "lv": true, "nb": true, "no": true, "pl": true,
+	"pt": true, "ro": true, "ru": true, "sk": true, "sl": true, "sv": true, "uk": true,
+}
+
+// 创建Intl.NumberFormat
+func (r *Runtime) newNumberFormat(locales, optionsValue Value) *numberFormatObject {
+	nf := &numberFormatObject{}
+	r.initIntlObject(&nf.baseObject, classNumberFormat, r.global.NumberFormatPrototype)
+	nf.val.self = nf
+	nf.init()
+
+	options := r.intlOptions(optionsValue)
+	nf.locale = r.resolveLocale(locales)
+	nf.printer = message.NewPrinter(nf.locale)
+	nf.style = r.intlStringOption(options, "style", []string{"decimal", "percent", "currency"}, "decimal")
+
+	var currencyCode string
+	if options != nil {
+		if v := nilSafe(options.self.getStr("currency")); v != _undefined {
+			currencyCode = strings.ToUpper(v.String())
+			unit, err := currency.ParseISO(currencyCode)
+			if err != nil || len(currencyCode) != 3 {
+				panic(r.newError(r.global.RangeError, "Invalid currency code : %s", v.String()))
+			}
+			nf.currency = unit
+		}
+	}
+	if nf.style == "currency" && currencyCode == "" {
+		r.typeErrorResult(true, "Currency code is required with currency style.")
+	}
+	nf.currencyDisplay = r.intlStringOption(options, "currencyDisplay", []string{"symbol", "narrowSymbol", "code"}, "symbol")
+
+	minFracDefault, maxFracDefault := 0, 3
+	switch nf.style {
+	case "percent":
+		maxFracDefault = 0
+	case "currency":
+		scale, _ := currency.Standard.Rounding(nf.currency)
+		minFracDefault, maxFracDefault = scale, scale
+	}
+	nf.minInt = r.intlNumberOption(options, "minimumIntegerDigits", 1, 21, 1)
+	nf.minFrac = r.intlNumberOption(options, "minimumFractionDigits", 0, 20, minFracDefault)
+	if maxFracDefault < nf.minFrac {
+		maxFracDefault = nf.minFrac
+	}
+	nf.maxFrac = r.intlNumberOption(options, "maximumFractionDigits", nf.minFrac, 20, maxFracDefault)
+	nf.minSig = r.intlNumberOption(options, "minimumSignificantDigits", 1, 21, 0)
+	minSig := nf.minSig
+	if minSig == 0 {
+		minSig = 1
+	}
+	nf.maxSig = r.intlNumberOption(options, "maximumSignificantDigits", minSig, 21, 0)
+	if nf.minSig > 0 || nf.maxSig > 0 {
+		nf.minSig = minSig
+		if nf.maxSig == 0 {
+			nf.maxSig = 21
+		}
+	}
+	nf.useGrouping = true
+	if v, ok := r.intlBoolOption(options, "useGrouping"); ok {
+		nf.useGrouping = v
+	}
+	return nf
+}
+
+// 返回非负数x最短的十进制表示，x的值为0.digits×10^exp。x为0时digits为空
+func shortestDigits(x float64) (digits string, exp int) {
+	if x == 0 {
+		return "", 1
+	}
+	s := strconv.FormatFloat(x, 'e', -1, 64)
+	e := strings.IndexByte(s, 'e')
+	exp, _ = strconv.Atoi(s[e+1:])
+	return strings.Replace(s[:e], ".", "", 1), exp + 1
+}
+
+// 按JavaScript的规则(远离零的方向)把0.digits×10^exp舍入到前keep位数字，去掉结果末尾的0
+func roundDigits(digits string, exp, keep int) (string, int) {
+	if len(digits) <= keep {
+		return digits, exp
+	}
+	if keep < 0 {
+		return "", exp
+	}
+	d := []byte(digits[:keep])
+	if digits[keep] >= '5' {
+		i := len(d) - 1
+		for ; i >= 0 && d[i] == '9'; i-- {
+			d[i] = '0'
+		}
+		if i < 0 {
+			d = append([]byte{'1'}, d...)
+			exp++
+		} else {
+			d[i]++
+		}
+	}
+	return strings.TrimRight(string(d), "0"), exp
+}
+
+// 格式化一个数字。先按最短的十进制表示舍入，再交给x/text按精确的位数输出，
+// 避免大数按二进制的精确值输出多余的数字
+func (nf *numberFormatObject) format(x float64) string {
+	if math.IsNaN(x) {
+		return "NaN"
+	}
+	negative := x < 0 || x == 0 && math.Signbit(x)
+	x = math.Abs(x)
+	var s string
+	if math.IsInf(x, 0) {
+		s = "∞"
+		if nf.style == "percent" {
+			s = nf.printer.Sprint(number.Percent(1))
+			s = strings.Replace(s, "100", "∞", 1)
+		}
+	} else {
+		digits, exp := shortestDigits(x)
+		shift := 0
+		if nf.style == "percent" {
+			shift = 2
+		}
+		minFrac := nf.minFrac
+		if nf.maxSig > 0 {
+			digits, exp = roundDigits(digits, exp+shift, nf.maxSig)
+			if digits == "" {
+				exp = 1
+			}
+			if minFrac = nf.minSig - exp; minFrac < 0 {
+				minFrac = 0
+			}
+		} else {
+			digits, exp = roundDigits(digits, exp+shift, exp+shift+nf.maxFrac)
+		}
+		var v float64
+		precision := 1
+		if digits != "" {
+			v, _ = strconv.ParseFloat("0."+digits+"e"+strconv.Itoa(exp-shift), 64)
+			precision = len(digits)
+		}
+		maxFrac := len(digits) - exp
+		if maxFrac < minFrac {
+			maxFrac = minFrac
+		}
+		opts := []number.Option{
+			number.MinIntegerDigits(nf.minInt),
+			number.Precision(precision),
+			number.MinFractionDigits(minFrac),
+			number.MaxFractionDigits(maxFrac),
+		}
+		if !nf.useGrouping {
+			opts = append(opts, number.NoSeparator())
+		}
+		if nf.style == "percent" {
+			s = nf.printer.Sprint(number.Percent(v, opts...))
+		} else {
+			s = nf.printer.Sprint(number.Decimal(v, opts...))
+		}
+	}
+	return nf.decorate(s, negative)
+}
+
+// 给格式化后的数字加上货币符号和负号
+func (nf *numberFormatObject) decorate(s string, negative bool) string {
+	if nf.style == "currency" {
+		var symbol string
+		switch nf.currencyDisplay {
+		case "code":
+			symbol = nf.currency.String()
+		case "narrowSymbol":
+			symbol = nf.printer.Sprint(currency.NarrowSymbol(nf.currency))
+		default:
+			symbol = nf.printer.Sprint(currency.Symbol(nf.currency))
+		}
+		base, _ := nf.locale.Base()
+		if currencySuffixLanguages[base.String()] {
+			s = s + " " + symbol
+		} else if nf.currencyDisplay == "code" {
+			s = symbol + " " + s
+		} else {
+			s = symbol + s
+		}
+	}
+	if negative {
+		s = "-" + s
+	}
+	return s
+}
+
+// 格式化一个BigInt，超出数字精度时按本地化的分隔符逐位输出
+func (nf *numberFormatObject) formatBigInt(b *big.Int) string {
+	if b.IsInt64() {
+		if i := b.Int64(); i > -maxInt && i < maxInt {
+			return nf.format(float64(i))
+		}
+	}
+	digits := new(big.Int).Abs(b)
+	if nf.style == "percent" {
+		digits.Mul(digits, big.NewInt(100))
+	}
+	s := digits.String()
+	minFrac := nf.minFrac
+	if nf.maxSig > 0 {
+		d, exp := roundDigits(s, len(s), nf.maxSig)
+		s = d + strings.Repeat("0", exp-len(d))
+		if minFrac = nf.minSig - exp; minFrac < 0 {
+			minFrac = 0
+		}
+	}
+	if len(s) < nf.minInt {
+		s = strings.Repeat("0", nf.minInt-len(s)) + s
+	}
+	// 从1000.5的格式中取出分组和小数分隔符
+	sample := nf.printer.Sprint(number.Decimal(1000.5, number.MinFractionDigits(1)))
+	idx := strings.Index(sample, "000")
+	group, decimal := sample[1:idx], sample[idx+3:len(sample)-1]
+	if nf.useGrouping {
+		var buf strings.Builder
+		for i, c := range s {
+			if i > 0 && (len(s)-i)%3 == 0 {
+				buf.WriteString(group)
+			}
+			buf.WriteRune(c)
+		}
+		s = buf.String()
+	}
+	if minFrac > 0 {
+		s += decimal + strings.Repeat("0", minFrac)
+	}
+	if nf.style == "percent" {
+		s = strings.Replace(nf.printer.Sprint(number.Percent(0)), "0", s, 1)
+	}
+	return nf.decorate(s, b.Sign() < 0)
+}
+
+// Intl.NumberFormat()和new Intl.NumberFormat()
+func (r *Runtime) builtin_newNumberFormat(args []Value) *Object {
+	return r.newNumberFormat(argAt(args, 0), argAt(args, 1)).val
+}
+
+// 取出this对应的NumberFormat
+func (r *Runtime) thisNumberFormat(v Value, method string) *numberFormatObject {
+	if o, ok := v.(*Object); ok {
+		if nf, ok := o.self.(*numberFormatObject); ok {
+			return nf
+		}
+	}
+	r.typeErrorResult(true, "Method Intl.NumberFormat.prototype.%s called on incompatible receiver %s", method, v.String())
+	return nil
+}
+
+// Intl.NumberFormat.prototype.format的getter，返回绑定的格式化函数
+func (r *Runtime) numberFormatProto_getFormat(call FunctionCall) Value {
+	nf := r.thisNumberFormat(call.This, "format")
+	if nf.boundFormat == nil {
+		nf.boundFormat = r.newBoundIntlFunc(func(call FunctionCall) Value {
+			x := toNumeric(call.Argument(0))
+			if b, ok := x.(*valueBigInt); ok {
+				return newStringValue(nf.formatBigInt((*big.Int)(b)))
+			}
+			return newStringValue(nf.format(x.ToFloat()))
+		}, 1)
+	}
+	return nf.boundFormat
+}
+
+// Intl.NumberFormat.prototype.resolvedOptions()
+func (r *Runtime) numberFormatProto_resolvedOptions(call FunctionCall) Value {
+	nf := r.thisNumberFormat(call.This, "resolvedOptions")
+	o := r.NewObject()
+	o.self.putStr("locale", newStringValue(nf.locale.String()), false)
+	o.self.putStr("numberingSystem", asciiString("latn"), false)
+	o.self.putStr("style", newStringValue(nf.style), false)
+	if nf.style == "currency" {
+		o.self.putStr("currency", newStringValue(nf.currency.String()), false)
+		o.self.putStr("currencyDisplay", newStringValue(nf.currencyDisplay), false)
+	}
+	o.self.putStr("minimumIntegerDigits", intToValue(int64(nf.minInt)), false)
+	o.self.putStr("minimumFractionDigits", intToValue(int64(nf.minFrac)), false)
+	o.self.putStr("maximumFractionDigits", intToValue(int64(nf.maxFrac)), false)
+	if nf.maxSig > 0 {
+		o.self.putStr("minimumSignificantDigits", intToValue(int64(nf.minSig)), false)
+		o.self.putStr("maximumSignificantDigits", intToValue(int64(nf.maxSig)), false)
+	}
+	o.self.putStr("useGrouping", r.toBoolean(nf.useGrouping), false)
+	return o
+}
+
+// 日期字段及其后的分隔符，分隔符只在后面还有字段时输出
+type dateToken struct {
+	field  byte // 'w'星期、'y'年、'm'月、'd'日
+	format string
+	sep    string
+}
+
+// 一种语言的日期时间格式数据
+type dateLocaleData struct {
+	tag           language.Tag
+	months        []string
+	shortMonths   []string
+	weekdays      []string // 从星期日开始
+	shortWeekdays []string
+	longDate      []dateToken // 文字月份的日期格式
+	numericOrder  string      // 数字日期的年月日顺序
+	numericSep    string
+	numericPad    bool // 数字日期的月和日补0
+	shortYear     bool // dateStyle为short时使用两位年份
+	hour12        bool
+	padHour24     bool
+	am, pm        string
+	ampmPrefix    bool
+	dateTimeSep   string
+}
+
+var dateLocales = []*dateLocaleData{
+	{
+		tag:           language.AmericanEnglish,
+		months:        []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		shortMonths:   []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		weekdays:      []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		shortWeekdays: []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		longDate:      []dateToken{{'w', "%s", ", "}, {'m', "%s", " "}, {'d', "%s", ", "}, {'y', "%s", ""}},
+		numericOrder:  "mdy",
+		numericSep:    "/",
+		shortYear:     true,
+		hour12:        true,
+		am:            "AM",
+		pm:            "PM",
+		dateTimeSep:   ", ",
+	},
+	{
+		tag:           language.BritishEnglish,
+		months:        []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		shortMonths:   []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sept", "Oct", "Nov", "Dec"},
+		weekdays:      []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		shortWeekdays: []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		longDate:      []dateToken{{'w', "%s", ", "}, {'d', "%s", " "}, {'m', "%s", " "}, {'y', "%s", ""}},
+		numericOrder:  "dmy",
+		numericSep:    "/",
+		numericPad:    true,
+		padHour24:     true,
+		am:            "am",
+		pm:            "pm",
+		dateTimeSep:   ", ",
+	},
+	{
+		tag:           language.German,
+		months:        []string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		shortMonths:   []string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		weekdays:      []string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		shortWeekdays: []string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+		longDate:      []dateToken{{'w', "%s", ", "}, {'d', "%s.", " "}, {'m', "%s", " "}, {'y', "%s", ""}},
+		numericOrder:  "dmy",
+		numericSep:    ".",
+		padHour24:     true,
+		am:            "AM",
+		pm:            "PM",
+		dateTimeSep:   ", ",
+	},
+	{
+		tag:           language.French,
+		months:        []string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		shortMonths:   []string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		weekdays:      []string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		shortWeekdays: []string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+		longDate:      []dateToken{{'w', "%s", " "}, {'d', "%s", " "}, {'m', "%s", " "}, {'y', "%s", ""}},
+		numericOrder:  "dmy",
+		numericSep:    "/",
+		numericPad:    true,
+		padHour24:     true,
+		am:            "AM",
+		pm:            "PM",
+		dateTimeSep:   " ",
+	},
+	{
+		tag:           language.Spanish,
+		months:        []string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		shortMonths:   []string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+		weekdays:      []string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		shortWeekdays: []string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+		longDate:      []dateToken{{'w', "%s", ", "}, {'d', "%s", " de "}, {'m', "%s", " de "}, {'y', "%s", ""}},
+		numericOrder:  "dmy",
+		numericSep:    "/",
+		am:            "a. m.",
+		pm:            "p. m.",
+		dateTimeSep:   ", ",
+	},
+	{
+		tag:           language.Chinese,
+		months:        []string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		shortMonths:   []string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		weekdays:      []string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"},
+		shortWeekdays: []string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"},
+		longDate:      []dateToken{{'y', "%s年", ""}, {'m', "%s", ""}, {'d', "%s日", ""}, {'w', "%s", ""}},
+		numericOrder:  "ymd",
+		numericSep:    "/",
+		padHour24:     true,
+		am:            "上午",
+		pm:            "下午",
+		ampmPrefix:    true,
+		dateTimeSep:   " ",
+	},
+	{
+		tag:           language.Japanese,
+		months:        []string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		shortMonths:   []string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		weekdays:      []string{"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日"},
+		shortWeekdays: []string{"日", "月", "火", "水", "木", "金", "土"},
+		longDate:      []dateToken{{'y', "%s年", ""}, {'m', "%s", ""}, {'d', "%s日", ""}, {'w', "%s", ""}},
+		numericOrder:  "ymd",
+		numericSep:    "/",
+		am:            "午前",
+		pm:            "午後",
+		ampmPrefix:    true,
+		dateTimeSep:   " ",
+	},
+}
+
+var dateLocaleMatcher = func() language.Matcher {
+	tags := make([]language.Tag, len(dateLocales))
+	for i, data := range dateLocales {
+		tags[i] = data.tag
+	}
+	return language.NewMatcher(tags)
+}()
+
+// 查找最匹配的日期格式数据，没有匹配时使用en-US
+func lookupDateLocale(tag language.Tag) (*dateLocaleData, bool) {
+	_, idx, _ := dateLocaleMatcher.Match(tag)
+	data := dateLocales[idx]
+	want, _ := tag.Base()
+	have, _ := data.tag.Base()
+	if want != have {
+		return dateLocales[0], false
+	}
+	return data, true
+}
+
+// 创建Intl.DateTimeFormat，required和defaults对应规范中ToDateTimeOptions的参数
+func (r *Runtime) newDateTimeFormat(locales, optionsValue Value, required, defaults string) *dateTimeFormatObject {
+	df := &dateTimeFormatObject{}
+	r.initIntlObject(&df.baseObject, classDateTimeFormat, r.global.DateTimeFormatPrototype)
+	df.val.self = df
+	df.init()
+
+	options := r.intlOptions(optionsValue)
+	df.data, _ = lookupDateLocale(r.resolveLocale(locales))
+	df.locale = df.data.tag
+
+	df.location = time.Local
+	df.timeZone = time.Local.String()
+	if options != nil {
+		if v := nilSafe(options.self.getStr("timeZone")); v != _undefined {
+			name := v.String()
+			if strings.EqualFold(name, "UTC") {
+				df.location, df.timeZone = time.UTC, "UTC"
+			} else {
+				loc, err := time.LoadLocation(name)
+				if err != nil || name == "" || name == "Local" {
+					panic(r.newError(r.global.RangeError, "Invalid time zone specified: %s", name))
+				}
+				df.location, df.timeZone = loc, loc.String()
+			}
+		}
+	}
+	if df.timeZone == "Local" {
+		df.timeZone = "UTC"
+	}
+
+	df.hour12 = df.data.hour12
+	if v, ok := r.intlBoolOption(options, "hour12"); ok {
+		df.hour12 = v
+	}
+	numeric2 := []string{"numeric", "2-digit"}
+	textual := []string{"narrow", "short", "long"}
+	df.weekday = r.intlStringOption(options, "weekday", textual, "")
+	df.year = r.intlStringOption(options, "year", numeric2, "")
+	df.month = r.intlStringOption(options, "month", append(numeric2, textual...), "")
+	df.day = r.intlStringOption(options, "day", numeric2, "")
+	df.hour = r.intlStringOption(options, "hour", numeric2, "")
+	df.minute = r.intlStringOption(options, "minute", numeric2, "")
+	df.second = r.intlStringOption(options, "second", numeric2, "")
+	df.timeZoneName = r.intlStringOption(options, "timeZoneName", []string{"short", "long"}, "")
+	styles := []string{"full", "long", "medium", "short"}
+	df.dateStyle = r.intlStringOption(options, "dateStyle", styles, "")
+	df.timeStyle = r.intlStringOption(options, "timeStyle", styles, "")
+
+	hasDate := df.weekday != "" || df.year != "" || df.month != "" || df.day != ""
+	hasTime := df.hour != "" || df.minute != "" || df.second != ""
+	if df.dateStyle != "" || df.timeStyle != "" {
+		if hasDate || hasTime || df.timeZoneName != "" {
+			r.typeErrorResult(true, "Can't set option dateStyle or timeStyle together with other date or time options")
+		}
+		df.applyStyles()
+		return df
+	}
+	needDefaults := true
+	if required == "date" || required == "any" {
+		needDefaults = needDefaults && !hasDate
+	}
+	if required == "time" || required == "any" {
+		needDefaults = needDefaults && !hasTime
+	}
+	if needDefaults && (defaults == "date" || defaults == "all") {
+		df.year, df.month, df.day = "numeric", "numeric", "numeric"
+	}
+	if needDefaults && (defaults == "time" || defaults == "all") {
+		df.hour, df.minute, df.second = "numeric", "numeric", "numeric"
+	}
+	return df
+}
+
+// 根据dateStyle和timeStyle设置各个字段
+func (df *dateTimeFormatObject) applyStyles() {
+	switch df.dateStyle {
+	case "full":
+		df.weekday, df.year, df.month, df.day = "long", "numeric", "long", "numeric"
+	case "long":
+		df.year, df.month, df.day = "numeric", "long", "numeric"
+	case "medium":
+		df.year, df.month, df.day = "numeric", "short", "numeric"
+	case "short":
+		df.year, df.month, df.day = "numeric", "numeric", "numeric"
+		if df.data.shortYear {
+			df.year = "2-digit"
+		}
+	}
+	switch df.timeStyle {
+	case "full", "long":
+		df.timeZoneName = "short"
+		fallthrough
+	case "medium":
+		df.second = "numeric"
+		fallthrough
+	case "short":
+		df.hour, df.minute = "numeric", "2-digit"
+	}
+}
+
+// 按numeric或2-digit格式化数字
+func formatDateNumber(n int, style string, pad bool) string {
+	s := strconv.Itoa(n)
+	switch {
+	case style == "2-digit" && len(s) > 2:
+		return s[len(s)-2:]
+	case (style == "2-digit" || pad) && len(s) < 2:
+		return "0" + s
+	}
+	return s
+}
+
+// 格式化日期部分
+func (df *dateTimeFormatObject) formatDate(t time.Time) string {
+	data := df.data
+	var weekday string
+	switch df.weekday {
+	case "long":
+		weekday = data.weekdays[t.Weekday()]
+	case "short", "narrow":
+		weekday = data.shortWeekdays[t.Weekday()]
+	}
+	if df.month == "" || df.month == "numeric" || df.month == "2-digit" {
+		var parts []string
+		for _, f := range data.numericOrder {
+			switch f {
+			case 'y':
+				if df.year != "" {
+					parts = append(parts, formatDateNumber(t.Year(), df.year, false))
+				}
+			case 'm':
+				if df.month != "" {
+					parts = append(parts, formatDateNumber(int(t.Month()), df.month, data.numericPad))
+				}
+			case 'd':
+				if df.day != "" {
+					parts = append(parts, formatDateNumber(t.Day(), df.day, data.numericPad))
+				}
+			}
+		}
+		s := strings.Join(parts, data.numericSep)
+		if weekday != "" {
+			if s == "" {
+				return weekday
+			}
+			return weekday + data.longDate[0].sep + s
+		}
+		return s
+	}
+
+	values := make(map[byte]string, 4)
+	if weekday != "" {
+		values['w'] = weekday
+	}
+	if df.year != "" {
+		values['y'] = formatDateNumber(t.Year(), df.year, false)
+	}
+	if df.month == "long" {
+		values['m'] = data.months[t.Month()-1]
+	} else {
+		values['m'] = data.shortMonths[t.Month()-1]
+	}
+	if df.day != "" {
+		values['d'] = formatDateNumber(t.Day(), df.day, false)
+	}
+	var b strings.Builder
+	sep := ""
+	for _, token := range data.longDate {
+		if v, ok := values[token.field]; ok {
+			b.WriteString(sep)
+			b.WriteString(strings.Replace(token.format, "%s", v, 1))
+			sep = token.sep
+		}
+	}
+	return b.String()
+}
+
+// 格式化时间部分
+func (df *dateTimeFormatObject) formatTime(t time.Time) string {
+	data := df.data
+	var parts []string
+	if df.hour != "" {
+		h := t.Hour()
+		if df.hour12 {
+			h %= 12
+			if h == 0 {
+				h = 12
+			}
+			parts = append(parts, formatDateNumber(h, df.hour, false))
+		} else {
+			parts = append(parts, formatDateNumber(h, df.hour, data.padHour24))
+		}
+	}
+	if df.minute != "" {
+		parts = append(parts, formatDateNumber(t.Minute(), df.minute, df.hour != ""))
+	}
+	if df.second != "" {
+		parts = append(parts, formatDateNumber(t.Second(), df.second, df.minute != ""))
+	}
+	s := strings.Join(parts, ":")
+	if df.hour != "" && df.hour12 {
+		ampm := data.am
+		if t.Hour() >= 12 {
+			ampm = data.pm
+		}
+		if data.ampmPrefix {
+			s = ampm + s
+		} else {
+			s = s + " " + ampm
+		}
+	}
+	switch df.timeZoneName {
+	case "short":
+		s += " " + t.Format("MST")
+	case "long":
+		if df.location == time.UTC {
+			s += " Coordinated Universal Time"
+		} else {
+			s += " " + df.timeZone
+		}
+	}
+	return s
+}
+
+// 格式化时间
+func (df *dateTimeFormatObject) format(t time.Time) string {
+	t = t.In(df.location)
+	date := df.formatDate(t)
+	tm := df.formatTime(t)
+	switch {
+	case date == "":
+		return tm
+	case tm == "":
+		return date
+	}
+	return date + df.data.dateTimeSep + tm
+}
+
+// 将参数转换为时间，undefined表示当前时间
+func (r *Runtime) toIntlDate(v Value) time.Time {
+	if v == _undefined {
+		return r.now()
+	}
+	f := v.ToFloat()
+	if math.IsNaN(f) || math.IsInf(f, 0) || math.Abs(f) > maxTime {
+		panic(r.newError(r.global.RangeError, "Invalid time value"))
+	}
+	return timeFromMsec(int64(f))
+}
+
+// Intl.DateTimeFormat()和new Intl.DateTimeFormat()
+func (r *Runtime) builtin_newDateTimeFormat(args []Value) *Object {
+	return r.newDateTimeFormat(argAt(args, 0), argAt(args, 1), "any", "date").val
+}
+
+// 取出this对应的DateTimeFormat
+func (r *Runtime) thisDateTimeFormat(v Value, method string) *dateTimeFormatObject {
+	if o, ok := v.(*Object); ok {
+		if df, ok := o.self.(*dateTimeFormatObject); ok {
+			return df
+		}
+	}
+	r.typeErrorResult(true, "Method Intl.DateTimeFormat.prototype.%s called on incompatible receiver %s", method, v.String())
+	return nil
+}
+
+// Intl.DateTimeFormat.prototype.format的getter，返回绑定的格式化函数
+func (r *Runtime) dateTimeFormatProto_getFormat(call FunctionCall) Value {
+	df := r.thisDateTimeFormat(call.This, "format")
+	if df.boundFormat == nil {
+		df.boundFormat = r.newBoundIntlFunc(func(call FunctionCall) Value {
+			return newStringValue(df.format(r.toIntlDate(call.Argument(0))))
+		}, 1)
+	}
+	return df.boundFormat
+}
+
+// Intl.DateTimeFormat.prototype.resolvedOptions()
+func (r *Runtime) dateTimeFormatProto_resolvedOptions(call FunctionCall) Value {
+	df := r.thisDateTimeFormat(call.This, "resolvedOptions")
+	o := r.NewObject()
+	o.self.putStr("locale", newStringValue(df.locale.String()), false)
+	o.self.putStr("calendar", asciiString("gregory"), false)
+	o.self.putStr("numberingSystem", asciiString("latn"), false)
+	o.self.putStr("timeZone", newStringValue(df.timeZone), false)
+	if df.hour != "" {
+		o.self.putStr("hour12", r.toBoolean(df.hour12), false)
+	}
+	for _, p := range []struct{ name, value string }{
+		{"weekday", df.weekday}, {"year", df.year}, {"month", df.month}, {"day", df.day},
+		{"hour", df.hour}, {"minute", df.minute}, {"second", df.second}, {"timeZoneName", df.timeZoneName},
+		{"dateStyle", df.dateStyle}, {"timeStyle", df.timeStyle},
+	} {
+		if p.value != "" {
+			o.self.putStr(p.name, newStringValue(p.value), false)
+		}
+	}
+	return o
+}
+
+// 取参数列表中的第i个参数
+func argAt(args []Value, i int) Value {
+	if i < len(args) {
+		return args[i]
+	}
+	return _undefined
+}
+
+// Intl命名空间及Collator、NumberFormat、DateTimeFormat
+func (r *Runtime) initIntl() {
+	intl := r.newBaseObject(r.global.ObjectPrototype, classObject)
+	intl._putSym(symToStringTag, asciiString("Intl"), false, false, true)
+	intl._putProp("getCanonicalLocales", r.newNativeFunc(r.intl_getCanonicalLocales, nil, "getCanonicalLocales", nil, 1), true, false, true)
+
+	r.global.CollatorPrototype = r.newBaseObject(r.global.ObjectPrototype, classObject).val
+	o := r.global.CollatorPrototype.self
+	o._putProp("resolvedOptions", r.newNativeFunc(r.collatorProto_resolvedOptions, nil, "resolvedOptions", nil, 0), true, false, true)
+	o.putStr("compare", &valueProperty{
+		configurable: true,
+		getterFunc:   r.newNativeFunc(r.collatorProto_getCompare, nil, "get compare", nil, 0),
+		accessor:     true,
+	}, false)
+	o._putSym(symToStringTag, asciiString(classCollator), false, false, true)
+	collator := r.newNativeFunc(func(call FunctionCall) Value {
+		return r.builtin_newCollator(call.Arguments)
+	}, r.builtin_newCollator, "Collator", r.global.CollatorPrototype, 0)
+	collator.self._putProp("supportedLocalesOf", r.newNativeFunc(func(call FunctionCall) Value {
+		return r.supportedLocalesOf(call, nil)
+	}, nil, "supportedLocalesOf", nil, 1), true, false, true)
+	intl._putProp("Collator", collator, true, false, true)
+
+	r.global.NumberFormatPrototype = r.newBaseObject(r.global.ObjectPrototype, classObject).val
+	o = r.global.NumberFormatPrototype.self
+	o._putProp("resolvedOptions", r.newNativeFunc(r.numberFormatProto_resolvedOptions, nil, "resolvedOptions", nil, 0), true, false, true)
+	o.putStr("format", &valueProperty{
+		configurable: true,
+		getterFunc:   r.newNativeFunc(r.numberFormatProto_getFormat, nil, "get format", nil, 0),
+		accessor:     true,
+	}, false)
+	o._putSym(symToStringTag, asciiString(classNumberFormat), false, false, true)
+	numberFormat := r.newNativeFunc(func(call FunctionCall) Value {
+		return r.builtin_newNumberFormat(call.Arguments)
+	}, r.builtin_newNumberFormat, "NumberFormat", r.global.NumberFormatPrototype, 0)
+	numberFormat.self._putProp("supportedLocalesOf", r.newNativeFunc(func(call FunctionCall) Value {
+		return r.supportedLocalesOf(call, nil)
+	}, nil, "supportedLocalesOf", nil, 1), true, false, true)
+	intl._putProp("NumberFormat", numberFormat, true, false, true)
+
+	r.global.DateTimeFormatPrototype = r.newBaseObject(r.global.ObjectPrototype, classObject).val
+	o = r.global.DateTimeFormatPrototype.self
+	o._putProp("resolvedOptions", r.newNativeFunc(r.dateTimeFormatProto_resolvedOptions, nil, "resolvedOptions", nil, 0), true, false, true)
+	o.putStr("format", &valueProperty{
+		configurable: true,
+		getterFunc:   r.newNativeFunc(r.dateTimeFormatProto_getFormat, nil, "get format", nil, 0),
+		accessor:     true,
+	}, false)
+	o._putSym(symToStringTag, asciiString(classDateTimeFormat), false, false, true)
+	dateTimeFormat := r.newNativeFunc(func(call FunctionCall) Value {
+		return r.builtin_newDateTimeFormat(call.Arguments)
+	}, r.builtin_newDateTimeFormat, "DateTimeFormat", r.global.DateTimeFormatPrototype, 0)
+	dateTimeFormat.self._putProp("supportedLocalesOf", r.newNativeFunc(func(call FunctionCall) Value {
+		return r.supportedLocalesOf(call, func(tag language.Tag) bool {
+			_, ok := lookupDateLocale(tag)
+			return ok
+		})
+	}, nil, "supportedLocalesOf", nil, 1), true, false, true)
+	intl._putProp("DateTimeFormat", dateTimeFormat, true, false, true)
+
+	r.addToGlobal("Intl", intl.val)
+}
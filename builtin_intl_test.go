@@ -0,0 +1,162 @@
+package goja
+
+import (
+	"testing"
+)
+
+func TestIntlCollator(t *testing.T) {
+	const SCRIPT = `
+	var c = new Intl.Collator("de");
+	assert.sameValue(c.compare("a", "b"), -1);
+	assert.sameValue(c.compare("ä", "a") > 0, true);
+	assert.sameValue(["z", "ä", "a"].sort(c.compare).join(), "a,ä,z");
+	assert.sameValue(c.compare, c.compare, "bound compare is cached");
+
+	assert.sameValue(Intl.Collator("en", {sensitivity: "base"}).compare("a", "Á"), 0);
+	assert.sameValue(Intl.Collator("en", {sensitivity: "accent"}).compare("a", "A"), 0);
+	assert.sameValue(Intl.Collator("en", {sensitivity: "accent"}).compare("a", "á"), -1);
+	assert.sameValue(new Intl.Collator("en", {numeric: true}).compare("2", "10"), -1);
+	assert.sameValue(new Intl.Collator("en").compare("2", "10"), 1);
+	assert.sameValue(new Intl.Collator("en", {ignorePunctuation: true}).compare("a.b", "ab"), 0);
+
+	var opts = new Intl.Collator("sv", {numeric: true}).resolvedOptions();
+	assert.sameValue(opts.locale, "sv");
+	assert.sameValue(opts.numeric, true);
+	assert.sameValue(opts.sensitivity, "variant");
+
+	assert.sameValue("a".localeCompare("B"), -1);
+	assert.sameValue("a".localeCompare("A", "en", {sensitivity: "base"}), 0);
+	assert.throws(RangeError, function() { new Intl.Collator("en", {sensitivity: "bogus"}) });
+	assert.throws(RangeError, function() { new Intl.Collator("not a locale!") });
+	assert.throws(TypeError, function() { Intl.Collator.prototype.compare });
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestIntlNumberFormat(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(new Intl.NumberFormat("en-US").format(1234567.891), "1,234,567.891");
+	assert.sameValue(new Intl.NumberFormat("de-DE").format(1234567.891), "1.234.567,891");
+	assert.sameValue(new Intl.NumberFormat("en-IN").format(1234567), "12,34,567");
+	assert.sameValue(new Intl.NumberFormat("en", {useGrouping: false}).format(1234.5), "1234.5");
+	assert.sameValue(new Intl.NumberFormat("en", {maximumFractionDigits: 2}).format(1.005), "1.01");
+	assert.sameValue(new Intl.NumberFormat("en", {maximumFractionDigits: 2}).format(0.125), "0.13");
+	assert.sameValue(new Intl.NumberFormat("en", {minimumFractionDigits: 2}).format(3), "3.00");
+	assert.sameValue(new Intl.NumberFormat("en", {minimumIntegerDigits: 3}).format(7), "007");
+	assert.sameValue(new Intl.NumberFormat("en").format(-0.5), "-0.5");
+	assert.sameValue(new Intl.NumberFormat("en").format(NaN), "NaN");
+	assert.sameValue(new Intl.NumberFormat("en").format(-Infinity), "-∞");
+	assert.sameValue(new Intl.NumberFormat("en").format(123456789012345680000), "123,456,789,012,345,680,000");
+	assert.sameValue(new Intl.NumberFormat("en").format(0.1 + 0.2), "0.3");
+	assert.sameValue(new Intl.NumberFormat("en").format(0.0005), "0.001");
+
+	assert.sameValue(new Intl.NumberFormat("en", {maximumSignificantDigits: 3}).format(123456), "123,000");
+	assert.sameValue(new Intl.NumberFormat("en", {maximumSignificantDigits: 3}).format(999.9), "1,000");
+	assert.sameValue(new Intl.NumberFormat("en", {maximumSignificantDigits: 3}).format(0.00012345), "0.000123");
+	assert.sameValue(new Intl.NumberFormat("en", {minimumSignificantDigits: 3}).format(1), "1.00");
+	assert.sameValue(new Intl.NumberFormat("en", {minimumSignificantDigits: 3}).format(0.05), "0.0500");
+	assert.sameValue(new Intl.NumberFormat("en", {maximumSignificantDigits: 2}).format(12345678901234567890n), "12,000,000,000,000,000,000");
+	var sig = new Intl.NumberFormat("en", {maximumSignificantDigits: 3}).resolvedOptions();
+	assert.sameValue(sig.minimumSignificantDigits, 1);
+	assert.sameValue(sig.maximumSignificantDigits, 3);
+	assert.sameValue("maximumSignificantDigits" in new Intl.NumberFormat("en").resolvedOptions(), false);
+	assert.throws(RangeError, function() { new Intl.NumberFormat("en", {maximumSignificantDigits: 0}) });
+	assert.throws(RangeError, function() { new Intl.NumberFormat("en", {minimumSignificantDigits: 5, maximumSignificantDigits: 4}) });
+
+	assert.sameValue(new Intl.NumberFormat("en", {style: "percent"}).format(0.256), "26%");
+	assert.sameValue(new Intl.NumberFormat("en", {style: "percent", maximumFractionDigits: 1}).format(0.2567), "25.7%");
+
+	assert.sameValue(new Intl.NumberFormat("en-US", {style: "currency", currency: "USD"}).format(1234.5), "$1,234.50");
+	assert.sameValue(new Intl.NumberFormat("en-US", {style: "currency", currency: "usd"}).format(-3), "-$3.00");
+	assert.sameValue(new Intl.NumberFormat("de-DE", {style: "currency", currency: "EUR"}).format(1234.5), "1.234,50 €");
+	assert.sameValue(new Intl.NumberFormat("ja-JP", {style: "currency", currency: "JPY"}).format(1234.5), "￥1,235");
+	assert.sameValue(new Intl.NumberFormat("en", {style: "currency", currency: "EUR", currencyDisplay: "code"}).format(1), "EUR 1.00");
+
+	var opts = new Intl.NumberFormat("en", {style: "currency", currency: "JPY"}).resolvedOptions();
+	assert.sameValue(opts.style, "currency");
+	assert.sameValue(opts.currency, "JPY");
+	assert.sameValue(opts.maximumFractionDigits, 0);
+
+	assert.throws(TypeError, function() { new Intl.NumberFormat("en", {style: "currency"}) });
+	assert.throws(RangeError, function() { new Intl.NumberFormat("en", {style: "currency", currency: "US"}) });
+	assert.throws(RangeError, function() { new Intl.NumberFormat("en", {maximumFractionDigits: 21}) });
+
+	assert.sameValue((1234.5).toLocaleString(), "1,234.5");
+	assert.sameValue((1234.5).toLocaleString("fr-FR", {minimumFractionDigits: 2}), "1 234,50");
+	var f = new Intl.NumberFormat("en").format;
+	assert.sameValue([1000, 2000].map(f).join(";"), "1,000;2,000");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestIntlDateTimeFormat(t *testing.T) {
+	const SCRIPT = `
+	var d = new Date(Date.UTC(2020, 0, 5, 14, 3, 9));
+	function fmt(locale, opts) {
+		opts = opts || {};
+		opts.timeZone = opts.timeZone || "UTC";
+		return new Intl.DateTimeFormat(locale, opts).format(d);
+	}
+	assert.sameValue(fmt("en-US"), "1/5/2020");
+	assert.sameValue(fmt("en-GB"), "05/01/2020");
+	assert.sameValue(fmt("de-DE"), "5.1.2020");
+	assert.sameValue(fmt("ja-JP"), "2020/1/5");
+	assert.sameValue(fmt("en-US", {hour: "numeric", minute: "2-digit"}), "2:03 PM");
+	assert.sameValue(fmt("en-US", {hour: "numeric", minute: "2-digit", hour12: false}), "14:03");
+	assert.sameValue(fmt("en-US", {timeZone: "Asia/Tokyo", hour: "numeric", minute: "2-digit", second: "2-digit"}), "11:03:09 PM");
+	assert.sameValue(fmt("en-US", {weekday: "long", year: "numeric", month: "long", day: "numeric"}), "Sunday, January 5, 2020");
+	assert.sameValue(fmt("en-GB", {year: "numeric", month: "short", day: "numeric"}), "5 Jan 2020");
+	assert.sameValue(fmt("de-DE", {year: "numeric", month: "long", day: "numeric"}), "5. Januar 2020");
+	assert.sameValue(fmt("fr-FR", {weekday: "long", year: "numeric", month: "long", day: "numeric"}), "dimanche 5 janvier 2020");
+	assert.sameValue(fmt("zh-CN", {year: "numeric", month: "long", day: "numeric"}), "2020年1月5日");
+	assert.sameValue(fmt("en-US", {month: "long", year: "numeric"}), "January 2020");
+	assert.sameValue(fmt("en-US", {dateStyle: "medium", timeStyle: "short"}), "Jan 5, 2020, 2:03 PM");
+	assert.sameValue(fmt("en-US", {hour: "numeric", timeZoneName: "short"}), "2 PM UTC");
+
+	var opts = new Intl.DateTimeFormat("en-US", {timeZone: "Europe/Berlin"}).resolvedOptions();
+	assert.sameValue(opts.timeZone, "Europe/Berlin");
+	assert.sameValue(opts.year, "numeric");
+	assert.sameValue(Intl.DateTimeFormat.supportedLocalesOf(["de-AT", "tlh"]).join(), "de-AT");
+	assert.sameValue(Intl.NumberFormat.supportedLocalesOf(["de", "xx"]).join(), "de");
+	assert.sameValue(Intl.Collator.supportedLocalesOf("xx").length, 0);
+	assert.sameValue(new Intl.NumberFormat(["xx", "de"]).resolvedOptions().locale, "de");
+	assert.throws(RangeError, function() { Intl.NumberFormat.supportedLocalesOf(["de", "123"]) });
+
+	assert.throws(RangeError, function() { fmt("en", {timeZone: "Mars/Olympus"}) });
+	assert.throws(RangeError, function() { new Intl.DateTimeFormat("en").format(NaN) });
+	assert.throws(TypeError, function() { fmt("en", {dateStyle: "short", year: "numeric"}) });
+
+	assert.sameValue(d.toLocaleDateString("en-US", {timeZone: "UTC"}), "1/5/2020");
+	assert.sameValue(d.toLocaleTimeString("en-US", {timeZone: "UTC"}), "2:03:09 PM");
+	assert.sameValue(d.toLocaleString("en-GB", {timeZone: "UTC"}), "05/01/2020, 14:03:09");
+	assert.sameValue(new Date(NaN).toLocaleString(), "Invalid Date");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestIntlObject(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(Object.prototype.toString.call(Intl), "[object Intl]");
+	assert.sameValue(Intl.getCanonicalLocales(["EN-us", "en-US", "de"]).join(), "en-US,de");
+	assert.throws(RangeError, function() { Intl.getCanonicalLocales("x!") });
+	assert.sameValue(Object.prototype.toString.call(new Intl.NumberFormat()), "[object Intl.NumberFormat]");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestRuntimeSetLocale(t *testing.T) {
+	r := New()
+	if err := r.SetLocale("de-DE"); err != nil {
+		t.Fatal(err)
+	}
+	v, err := r.RunString(`(1234.5).toLocaleString() + " " + new Intl.NumberFormat().resolvedOptions().locale`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := v.String(); s != "1.234,5 de-DE" {
+		t.Fatalf("Unexpected result: %q", s)
+	}
+	if err := r.SetLocale("not a locale!"); err == nil {
+		t.Fatal("Expected an error")
+	}
+}
@@ -0,0 +1,301 @@
+package goja
+
+const (
+	classArrayIterator  = "Array Iterator"
+	classStringIterator = "String Iterator"
+
+	classRegExpStringIterator = "RegExp String Iterator"
+)
+
+type iterationKind int
+
+const (
+	iterationKindKey iterationKind = iota
+	iterationKindValue
+	iterationKindKeyValue
+)
+
+// 数组迭代器，Array.prototype.values/keys/entries返回的对象
+type arrayIterObject struct {
+	baseObject
+	obj     *Object // 为nil时表示已迭代完
+	nextIdx int64
+	kind    iterationKind
+}
+
+// 字符串迭代器，按码点迭代
+type stringIterObject struct {
+	baseObject
+	str valueString // 为nil时表示已迭代完
+	pos int64
+}
+
+// 正则表达式字符串迭代器，String.prototype.matchAll返回的对象
+type regexpStringIterObject struct {
+	baseObject
+	matcher         *Object
+	s               valueString
+	global, unicode bool
+	done            bool
+}
+// 构造{value: value, done: done}
+func (r *Runtime) createIterResultObject(value Value, done bool) Value {
+	o := r.NewObject()
+	o.self.putStr("value", value, false)
+	o.self.putStr("done", r.toBoolean(done), false)
+	return o
+}
+// 数组迭代器的下一个结果
+func (ai *arrayIterObject) next() Value {
+	r := ai.val.runtime
+	if ai.obj == nil {
+		return r.createIterResultObject(_undefined, true)
+	}
+	l := toLength(ai.obj.self.getStr("length"))
+	index := ai.nextIdx
+	if index >= l {
+		ai.obj = nil
+		return r.createIterResultObject(_undefined, true)
+	}
+	ai.nextIdx++
+	idxVal := intToValue(index)
+	if ai.kind == iterationKindKey {
+		return r.createIterResultObject(idxVal, false)
+	}
+	elementValue := ai.obj.self.get(idxVal)
+	if elementValue == nil {
+		elementValue = _undefined
+	}
+	var result Value
+	if ai.kind == iterationKindValue {
+		result = elementValue
+	} else {
+		result = r.newArrayValues([]Value{idxVal, elementValue})
+	}
+	return r.createIterResultObject(result, false)
+}
+// 字符串迭代器的下一个结果
+func (si *stringIterObject) next() Value {
+	r := si.val.runtime
+	if si.str == nil {
+		return r.createIterResultObject(_undefined, true)
+	}
+	l := si.str.length()
+	if si.pos >= l {
+		si.str = nil
+		return r.createIterResultObject(_undefined, true)
+	}
+	start := si.pos
+	si.pos++
+	// 代理对作为一个码点
+	if c := si.str.charAt(start); c >= 0xD800 && c <= 0xDBFF && si.pos < l {
+		if c1 := si.str.charAt(si.pos); c1 >= 0xDC00 && c1 <= 0xDFFF {
+			si.pos++
+		}
+	}
+	return r.createIterResultObject(si.str.substring(start, si.pos), false)
+}
+// 正则表达式字符串迭代器的下一个结果
+func (ri *regexpStringIterObject) next() Value {
+	r := ri.val.runtime
+	if ri.done {
+		return r.createIterResultObject(_undefined, true)
+	}
+	match := r.regExpExec(ri.matcher, ri.s)
+	if match == _null {
+		ri.done = true
+		return r.createIterResultObject(_undefined, true)
+	}
+	if !ri.global {
+		ri.done = true
+		return r.createIterResultObject(match, false)
+	}
+	if nilSafe(r.toObject(match).self.getStr("0")).ToString().length() == 0 {
+		r.advanceLastIndex(ri.matcher, ri.s, ri.unicode)
+	}
+	return r.createIterResultObject(match, false)
+}
+// 创建数组迭代器
+func (r *Runtime) createArrayIterator(iterObj *Object, kind iterationKind) Value {
+	o := &Object{runtime: r}
+
+	ai := &arrayIterObject{
+		obj:  iterObj,
+		kind: kind,
+	}
+	ai.class = classArrayIterator
+	ai.val = o
+	ai.extensible = true
+	o.self = ai
+	ai.prototype = r.global.ArrayIteratorPrototype
+	ai.init()
+
+	return o
+}
+// 创建字符串迭代器
+func (r *Runtime) createStringIterator(s valueString) Value {
+	o := &Object{runtime: r}
+
+	si := &stringIterObject{
+		str: s,
+	}
+	si.class = classStringIterator
+	si.val = o
+	si.extensible = true
+	o.self = si
+	si.prototype = r.global.StringIteratorPrototype
+	si.init()
+
+	return o
+}
+// 创建正则表达式字符串迭代器
+func (r *Runtime) createRegExpStringIterator(matcher *Object, s valueString, global, unicode bool) Value {
+	o := &Object{runtime: r}
+
+	ri := &regexpStringIterObject{
+		matcher: matcher,
+		s:       s,
+		global:  global,
+		unicode: unicode,
+	}
+	ri.class = classRegExpStringIterator
+	ri.val = o
+	ri.extensible = true
+	o.self = ri
+	ri.prototype = r.global.RegExpStringIteratorPrototype
+	ri.init()
+
+	return o
+}
+// %ArrayIteratorPrototype%.next实现
+func (r *Runtime) arrayIterProto_next(call FunctionCall) Value {
+	if o, ok := call.This.(*Object); ok {
+		if ai, ok := o.self.(*arrayIterObject); ok {
+			return ai.next()
+		}
+	}
+	r.typeErrorResult(true, "Method Array Iterator.prototype.next called on incompatible receiver %s", call.This.String())
+	return nil
+}
+// %StringIteratorPrototype%.next实现
+func (r *Runtime) stringIterProto_next(call FunctionCall) Value {
+	if o, ok := call.This.(*Object); ok {
+		if si, ok := o.self.(*stringIterObject); ok {
+			return si.next()
+		}
+	}
+	r.typeErrorResult(true, "Method String Iterator.prototype.next called on incompatible receiver %s", call.This.String())
+	return nil
+}
+// %RegExpStringIteratorPrototype%.next实现
+func (r *Runtime) regexpStringIterProto_next(call FunctionCall) Value {
+	if o, ok := call.This.(*Object); ok {
+		if ri, ok := o.self.(*regexpStringIterObject); ok {
+			return ri.next()
+		}
+	}
+	r.typeErrorResult(true, "Method RegExp String Iterator.prototype.next called on incompatible receiver %s", call.This.String())
+	return nil
+}
+// %IteratorPrototype%[@@iterator]，返回this
+func (r *Runtime) iterProto_iterator(call FunctionCall) Value {
+	return call.This
+}
+// 获取v的迭代器，即调用v[Symbol.iterator]()
+func (r *Runtime) getIterator(v Value) *Object {
+	var method *Object
+	if v != _undefined && v != _null {
+		method, _ = v.ToObject(r).self.get(symIterator).(*Object)
+	}
+	if method == nil {
+		r.typeErrorResult(true, "%s is not iterable", v.String())
+	}
+	call, ok := method.self.assertCallable()
+	if !ok {
+		r.typeErrorResult(true, "Symbol.iterator is not a function")
+	}
+	iter, ok := call(FunctionCall{This: v}).(*Object)
+	if !ok {
+		r.typeErrorResult(true, "Result of the Symbol.iterator method is not an object")
+	}
+	return iter
+}
+// 调用iter.next()，返回value和是否结束
+func (r *Runtime) iteratorNext(iter *Object) (value Value, done bool) {
+	next := r.toCallable(iter.self.getStr("next"))
+	res, ok := next(FunctionCall{This: iter}).(*Object)
+	if !ok {
+		r.typeErrorResult(true, "Iterator result is not an object")
+	}
+	if res.self.getStr("done").ToBoolean() {
+		return nil, true
+	}
+	value = res.self.getStr("value")
+	if value == nil {
+		value = _undefined
+	}
+	return value, false
+}
+// 提前结束迭代时调用iter.return()
+func (r *Runtime) iteratorClose(iter *Object) {
+	if ret, ok := iter.self.getStr("return").(*Object); ok {
+		if call, ok := ret.self.assertCallable(); ok {
+			if _, ok := call(FunctionCall{This: iter}).(*Object); !ok {
+				r.typeErrorResult(true, "Iterator result is not an object")
+			}
+		}
+	}
+}
+// 依次对可迭代对象v的每个值调用step
+func (r *Runtime) iterate(v Value, step func(Value)) {
+	iter := r.getIterator(v)
+	for {
+		r.useGas(1)
+		value, done := r.iteratorNext(iter)
+		if done {
+			break
+		}
+		step(value)
+	}
+}
+// 同iterate，step抛出异常时先关闭迭代器
+func (r *Runtime) iterateClose(v Value, step func(Value)) {
+	iter := r.getIterator(v)
+	for {
+		r.useGas(1)
+		value, done := r.iteratorNext(iter)
+		if done {
+			break
+		}
+		if ex := r.vm.try(func() {
+			step(value)
+		}); ex != nil {
+			// 关闭迭代器时的异常被忽略，抛出原来的异常
+			r.vm.try(func() {
+				r.iteratorClose(iter)
+			})
+			panic(ex)
+		}
+	}
+}
+// 迭代器相关的原型
+func (r *Runtime) initIterators() {
+	r.global.IteratorPrototype = r.newBaseObject(r.global.ObjectPrototype, classObject).val
+	o := r.global.IteratorPrototype.self
+	o._putSym(symIterator, r.newNativeFunc(r.iterProto_iterator, nil, "[Symbol.iterator]", nil, 0), true, false, true)
+
+	r.global.ArrayIteratorPrototype = r.newBaseObject(r.global.IteratorPrototype, classObject).val
+	o = r.global.ArrayIteratorPrototype.self
+	o._putProp("next", r.newNativeFunc(r.arrayIterProto_next, nil, "next", nil, 0), true, false, true)
+	o._putSym(symToStringTag, asciiString(classArrayIterator), false, false, true)
+
+	r.global.StringIteratorPrototype = r.newBaseObject(r.global.IteratorPrototype, classObject).val
+	o = r.global.StringIteratorPrototype.self
+	o._putProp("next", r.newNativeFunc(r.stringIterProto_next, nil, "next", nil, 0), true, false, true)
+	o._putSym(symToStringTag, asciiString(classStringIterator), false, false, true)
+
+	r.global.RegExpStringIteratorPrototype = r.newBaseObject(r.global.IteratorPrototype, classObject).val
+	o = r.global.RegExpStringIteratorPrototype.self
+	o._putProp("next", r.newNativeFunc(r.regexpStringIterProto_next, nil, "next", nil, 0), true, false, true)
+	o._putSym(symToStringTag, asciiString(classRegExpStringIterator), false, false, true)
+}
@@ -0,0 +1,75 @@
+package goja
+
+import "testing"
+
+func TestArrayIterator(t *testing.T) {
+	const SCRIPT = `
+	var a = ["x", "y"];
+	var it = a.values();
+	assert.sameValue(it[Symbol.iterator](), it, "iterator returns itself");
+	assert.sameValue(Object.prototype.toString.call(it), "[object Array Iterator]", "toStringTag");
+	var r = it.next();
+	assert.sameValue(r.value, "x", "first value");
+	assert.sameValue(r.done, false, "first done");
+	it.next();
+	r = it.next();
+	assert.sameValue(r.value, undefined, "last value");
+	assert.sameValue(r.done, true, "last done");
+	assert.sameValue(Array.prototype[Symbol.iterator], Array.prototype.values, "@@iterator");
+	assert.sameValue([...a.keys()].join(), "0,1", "keys");
+	assert.sameValue([...a.entries()].join(";"), "0,x;1,y", "entries");
+	assert.throws(TypeError, function() { it.next.call({}); }, "incompatible receiver");
+	function f() {
+		return [...arguments].join();
+	}
+	assert.sameValue(f(1, 2), "1,2", "arguments");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestStringIterator(t *testing.T) {
+	const SCRIPT = `
+	var res = [];
+	for (var c of "a😀b") {
+		res.push(c);
+	}
+	assert.sameValue(res.length, 3, "length");
+	assert.sameValue(res[1], "😀", "surrogate pair");
+	assert.sameValue(Object.prototype.toString.call(""[Symbol.iterator]()), "[object String Iterator]", "toStringTag");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestGoIterable(t *testing.T) {
+	r := New()
+	o := r.NewObject()
+	err := o.SetSymbol(SymIterator, func(call FunctionCall) Value {
+		i := 0
+		iter := r.NewObject()
+		iter.Set("next", func(call FunctionCall) Value {
+			res := r.NewObject()
+			i++
+			res.Set("value", i)
+			res.Set("done", i > 3)
+			return res
+		})
+		return iter
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.GetSymbol(SymIterator) == nil {
+		t.Fatal("GetSymbol returned nil")
+	}
+	r.Set("o", o)
+	v, err := r.RunString(`var s = 0; for (var x of o) s += x; s + [...o].length`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.ToInteger() != 9 {
+		t.Fatalf("Unexpected result: %v", v)
+	}
+	if err := o.SetSymbol(r.ToValue("x"), 1); err == nil {
+		t.Fatal("Expected an error for a non-symbol key")
+	}
+}
@@ -13,7 +13,9 @@ var hex = "0123456789abcdef"
 //JSON.parse() 方法用来解析JSON字符串，构造由字符串描述的JavaScript值或对象。
 //提供可选的 reviver 函数用以在返回之前对所得到的对象执行变换(操作)。
 func (r *Runtime) builtinJSON_parse(call FunctionCall) Value {
-	d := json.NewDecoder(bytes.NewBufferString(call.Argument(0).String()))
+	text := call.Argument(0).String()
+	r.useGas(int64(len(text)))
+	d := json.NewDecoder(bytes.NewBufferString(text))
 
 	value, err := r.builtinJSON_decodeValue(d)
 	if err != nil {
@@ -190,6 +192,8 @@ type _builtinJSON_stringifyContext struct {
 	replacerFunction func(FunctionCall) Value
 	gap, indent      string
 	buf              bytes.Buffer
+	// buf中已经计入内存统计的长度
+	accounted int
 }
 //JSON.stringify() 方法将一个 JavaScript 值（对象或者数组）转换为一个 JSON 字符串，
 //如果指定了 replacer 是一个函数，则可以选择性地替换值，或者如果指定了 replacer 是一个数组，
@@ -271,6 +275,8 @@ func (r *Runtime) builtinJSON_stringify(call FunctionCall) Value {
 	}
 
 	if ctx.do(call.Argument(0)) {
+		ctx.account()
+		r.allocate(sizeofStringValue)
 		return newStringValue(ctx.buf.String())
 	}
 	return _undefined
@@ -282,12 +288,26 @@ func (ctx *_builtinJSON_stringifyContext) do(v Value) bool {
 	return ctx.str(stringEmpty, holder)
 }
 
+// 把buf新增的长度计入内存统计
+func (ctx *_builtinJSON_stringifyContext) account() {
+	if l := ctx.buf.Len(); l > ctx.accounted {
+		ctx.r.allocate(l - ctx.accounted)
+		ctx.accounted = l
+	}
+}
+
 func (ctx *_builtinJSON_stringifyContext) str(key Value, holder *Object) bool {
+	ctx.r.useGas(1)
+	ctx.account()
 	value := holder.self.get(key)
 	if value == nil {
 		value = _undefined
 	}
 
+	// BigInt可以通过BigInt.prototype.toJSON自定义序列化
+	if b, ok := value.(*valueBigInt); ok {
+		value = b.ToObject(ctx.r)
+	}
 	if object, ok := value.(*Object); ok {
 		if toJSON, ok := object.self.getStr("toJSON").(*Object); ok {
 			if c, ok := toJSON.self.assertCallable(); ok {
@@ -358,6 +378,8 @@ func (ctx *_builtinJSON_stringifyContext) str(key Value, holder *Object) bool {
 		}
 	case valueNull:
 		ctx.buf.WriteString("null")
+	case *valueBigInt:
+		ctx.r.typeErrorResult(true, "Do not know how to serialize a BigInt")
 	case *Object:
 		for _, object := range ctx.stack {
 			if value1 == object {
@@ -518,6 +540,7 @@ func (r *Runtime) initJSON() {
 	JSON := r.newBaseObject(r.global.ObjectPrototype, "JSON")
 	JSON._putProp("parse", r.newNativeFunc(r.builtinJSON_parse, nil, "parse", nil, 2), true, false, true)
 	JSON._putProp("stringify", r.newNativeFunc(r.builtinJSON_stringify, nil, "stringify", nil, 3), true, false, true)
+	JSON._putSym(symToStringTag, asciiString("JSON"), false, false, true)
 
 	r.addToGlobal("JSON", JSON.val)
 }
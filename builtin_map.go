@@ -0,0 +1,220 @@
+package goja
+
+import "reflect"
+
+const classMapIterator = "Map Iterator"
+
+var reflectTypeMapAny = reflect.TypeOf(map[interface{}]interface{}{})
+
+// Map对象
+type mapObject struct {
+	baseObject
+	m *orderedMap
+}
+
+// Map迭代器，Map.prototype.entries/keys/values返回的对象
+type mapIterObject struct {
+	baseObject
+	iter *orderedMapIter
+	kind iterationKind
+}
+// 导出为map[interface{}]interface{}
+func (mo *mapObject) export() interface{} {
+	m := make(map[interface{}]interface{}, mo.m.size)
+	for entry := mo.m.iterFirst; entry != nil; entry = entry.iterNext {
+		m[exportMapKey(entry.key)] = entry.value.Export()
+	}
+	return m
+}
+// 返回map[interface{}]interface{}的类型
+func (mo *mapObject) exportType() reflect.Type {
+	return reflectTypeMapAny
+}
+// 导出的键不能作为Go map的键时(例如对象导出的map)，使用键本身
+func exportMapKey(key Value) interface{} {
+	k := key.Export()
+	if k != nil && !reflect.TypeOf(k).Comparable() {
+		return key
+	}
+	return k
+}
+// Map迭代器的下一个结果
+func (mi *mapIterObject) next() Value {
+	r := mi.val.runtime
+	if mi.iter == nil {
+		return r.createIterResultObject(_undefined, true)
+	}
+	entry := mi.iter.next()
+	if entry == nil {
+		mi.iter = nil
+		return r.createIterResultObject(_undefined, true)
+	}
+	var result Value
+	switch mi.kind {
+	case iterationKindKey:
+		result = entry.key
+	case iterationKindValue:
+		result = entry.value
+	default:
+		result = r.newArrayValues([]Value{entry.key, entry.value})
+	}
+	return r.createIterResultObject(result, false)
+}
+// 创建空的Map对象
+func (r *Runtime) newMapObject(proto *Object) *mapObject {
+	o := &Object{runtime: r}
+
+	mo := &mapObject{
+		m: newOrderedMap(),
+	}
+	mo.class = classMap
+	mo.val = o
+	mo.extensible = true
+	o.self = mo
+	mo.prototype = proto
+	mo.init()
+	return mo
+}
+// 创建Map迭代器
+func (r *Runtime) createMapIterator(m *orderedMap, kind iterationKind) Value {
+	o := &Object{runtime: r}
+
+	mi := &mapIterObject{
+		iter: m.newIter(),
+		kind: kind,
+	}
+	mi.class = classMapIterator
+	mi.val = o
+	mi.extensible = true
+	o.self = mi
+	mi.prototype = r.global.MapIteratorPrototype
+	mi.init()
+
+	return o
+}
+// 取出this对应的Map
+func (r *Runtime) thisMap(v Value, method string) *mapObject {
+	if o, ok := v.(*Object); ok {
+		if mo, ok := o.self.(*mapObject); ok {
+			return mo
+		}
+	}
+	r.typeErrorResult(true, "Method Map.prototype.%s called on incompatible receiver %s", method, v.String())
+	return nil
+}
+// Map()不能作为普通函数调用
+func (r *Runtime) builtin_Map(call FunctionCall) Value {
+	r.typeErrorResult(true, "Constructor Map requires 'new'")
+	return nil
+}
+// new Map(iterable)实现，iterable中的每一项是[key, value]
+func (r *Runtime) builtin_newMap(args []Value) *Object {
+	mo := r.newMapObject(r.global.MapPrototype)
+	if len(args) > 0 && args[0] != _undefined && args[0] != _null {
+		adder := r.toCallable(mo.getStr("set"))
+		r.iterateClose(args[0], func(item Value) {
+			itemObj, ok := item.(*Object)
+			if !ok {
+				r.typeErrorResult(true, "Iterator value %s is not an entry object", item.String())
+			}
+			k := nilSafe(itemObj.self.get(intToValue(0)))
+			v := nilSafe(itemObj.self.get(intToValue(1)))
+			adder(FunctionCall{This: mo.val, Arguments: []Value{k, v}})
+		})
+	}
+	return mo.val
+}
+// Map.prototype.clear实现
+func (r *Runtime) mapProto_clear(call FunctionCall) Value {
+	r.thisMap(call.This, "clear").m.clear()
+	return _undefined
+}
+// Map.prototype.delete实现
+func (r *Runtime) mapProto_delete(call FunctionCall) Value {
+	return r.toBoolean(r.thisMap(call.This, "delete").m.remove(call.Argument(0)))
+}
+// Map.prototype.entries实现
+func (r *Runtime) mapProto_entries(call FunctionCall) Value {
+	return r.createMapIterator(r.thisMap(call.This, "entries").m, iterationKindKeyValue)
+}
+// Map.prototype.forEach实现，迭代过程中添加的项也会被访问
+func (r *Runtime) mapProto_forEach(call FunctionCall) Value {
+	mo := r.thisMap(call.This, "forEach")
+	callback := r.toHandler(call.Argument(0))
+	if callback == nil {
+		r.typeErrorResult(true, "%s is not a function", call.Argument(0).String())
+	}
+	thisArg := call.Argument(1)
+	iter := mo.m.newIter()
+	for entry := iter.next(); entry != nil; entry = iter.next() {
+		callback(FunctionCall{This: thisArg, Arguments: []Value{entry.value, entry.key, mo.val}})
+	}
+	return _undefined
+}
+// Map.prototype.get实现
+func (r *Runtime) mapProto_get(call FunctionCall) Value {
+	return nilSafe(r.thisMap(call.This, "get").m.get(call.Argument(0)))
+}
+// Map.prototype.has实现
+func (r *Runtime) mapProto_has(call FunctionCall) Value {
+	return r.toBoolean(r.thisMap(call.This, "has").m.has(call.Argument(0)))
+}
+// Map.prototype.keys实现
+func (r *Runtime) mapProto_keys(call FunctionCall) Value {
+	return r.createMapIterator(r.thisMap(call.This, "keys").m, iterationKindKey)
+}
+// Map.prototype.set实现
+func (r *Runtime) mapProto_set(call FunctionCall) Value {
+	if r.thisMap(call.This, "set").m.set(call.Argument(0), call.Argument(1)) {
+		r.allocate(sizeofMapEntry)
+	}
+	return call.This
+}
+// Map.prototype.size访问器
+func (r *Runtime) mapProto_getSize(call FunctionCall) Value {
+	return intToValue(int64(r.thisMap(call.This, "size").m.size))
+}
+// Map.prototype.values实现
+func (r *Runtime) mapProto_values(call FunctionCall) Value {
+	return r.createMapIterator(r.thisMap(call.This, "values").m, iterationKindValue)
+}
+// %MapIteratorPrototype%.next实现
+func (r *Runtime) mapIterProto_next(call FunctionCall) Value {
+	if o, ok := call.This.(*Object); ok {
+		if mi, ok := o.self.(*mapIterObject); ok {
+			return mi.next()
+		}
+	}
+	r.typeErrorResult(true, "Method Map Iterator.prototype.next called on incompatible receiver %s", call.This.String())
+	return nil
+}
+// Map类实现
+func (r *Runtime) initMap() {
+	r.global.MapIteratorPrototype = r.newBaseObject(r.global.IteratorPrototype, classObject).val
+	o := r.global.MapIteratorPrototype.self
+	o._putProp("next", r.newNativeFunc(r.mapIterProto_next, nil, "next", nil, 0), true, false, true)
+	o._putSym(symToStringTag, asciiString(classMapIterator), false, false, true)
+
+	proto := r.newBaseObject(r.global.ObjectPrototype, classObject)
+	r.global.MapPrototype = proto.val
+	proto._putProp("clear", r.newNativeFunc(r.mapProto_clear, nil, "clear", nil, 0), true, false, true)
+	proto._putProp("delete", r.newNativeFunc(r.mapProto_delete, nil, "delete", nil, 1), true, false, true)
+	entries := r.newNativeFunc(r.mapProto_entries, nil, "entries", nil, 0)
+	proto._putProp("entries", entries, true, false, true)
+	proto._putProp("forEach", r.newNativeFunc(r.mapProto_forEach, nil, "forEach", nil, 1), true, false, true)
+	proto._putProp("get", r.newNativeFunc(r.mapProto_get, nil, "get", nil, 1), true, false, true)
+	proto._putProp("has", r.newNativeFunc(r.mapProto_has, nil, "has", nil, 1), true, false, true)
+	proto._putProp("keys", r.newNativeFunc(r.mapProto_keys, nil, "keys", nil, 0), true, false, true)
+	proto._putProp("set", r.newNativeFunc(r.mapProto_set, nil, "set", nil, 2), true, false, true)
+	proto._put("size", &valueProperty{
+		accessor:     true,
+		configurable: true,
+		getterFunc:   r.newNativeFunc(r.mapProto_getSize, nil, "get size", nil, 0),
+	})
+	proto._putProp("values", r.newNativeFunc(r.mapProto_values, nil, "values", nil, 0), true, false, true)
+	proto._putSym(symIterator, entries, true, false, true)
+	proto._putSym(symToStringTag, asciiString(classMap), false, false, true)
+
+	r.global.Map = r.newNativeFunc(r.builtin_Map, r.builtin_newMap, "Map", r.global.MapPrototype, 0)
+	r.addToGlobal("Map", r.global.Map)
+}
@@ -0,0 +1,79 @@
+package goja
+
+import "testing"
+
+func TestMap(t *testing.T) {
+	const SCRIPT = `
+	var o = {};
+	var m = new Map([[1, "a"], ["1", "b"], [o, "c"]]);
+	assert.sameValue(m.size, 3, "size");
+	assert.sameValue(m.get(1), "a", "number key");
+	assert.sameValue(m.get("1"), "b", "string key");
+	assert.sameValue(m.get(o), "c", "object key");
+	assert.sameValue(m.get({}), undefined, "other object");
+	assert.sameValue(m.set(NaN, "nan"), m, "set returns this");
+	assert.sameValue(m.get(NaN), "nan", "NaN key");
+	m.set(-0, "zero");
+	assert.sameValue(m.get(0), "zero", "-0 and +0");
+	assert.sameValue(1 / new Map([[-0, 1]]).keys().next().value, Infinity, "-0 key is normalized");
+	assert.sameValue(m.delete("1"), true, "delete");
+	assert.sameValue(m.delete("1"), false, "delete missing");
+	assert.sameValue(m.has("1"), false, "has");
+	assert.sameValue([...m.keys()].length, 4, "keys");
+	m.clear();
+	assert.sameValue(m.size, 0, "clear");
+	assert.throws(TypeError, function() { Map(); }, "call");
+	assert.throws(TypeError, function() { new Map([1]); }, "not an entry object");
+	assert.throws(TypeError, function() { Map.prototype.get.call({}, 1); }, "incompatible receiver");
+	assert.sameValue(Map.prototype[Symbol.iterator], Map.prototype.entries, "@@iterator");
+	assert.sameValue(Object.prototype.toString.call(m), "[object Map]", "toStringTag");
+	assert.sameValue(Object.prototype.toString.call(m.keys()), "[object Map Iterator]", "iterator toStringTag");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestMapIteration(t *testing.T) {
+	const SCRIPT = `
+	var m = new Map([["a", 1], ["b", 2], ["c", 3]]);
+	var res = [];
+	m.forEach(function(v, k, map) {
+		res.push(k + v);
+		if (k === "a") {
+			map.delete("b");
+			map.set("d", 4);
+		}
+	});
+	for (var e of m.entries()) {
+		res.push(e[0]);
+	}
+	res.join();
+	`
+	testScript1(SCRIPT, asciiString("a1,c3,d4,a,c,d"), t)
+}
+
+func TestMapExport(t *testing.T) {
+	vm := New()
+	v, err := vm.RunString(`new Map([["a", 1], [2, "b"]])`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, ok := v.Export().(map[interface{}]interface{})
+	if !ok {
+		t.Fatalf("Unexpected export type: %T", v.Export())
+	}
+	if len(m) != 2 || m["a"] != int64(1) || m[int64(2)] != "b" {
+		t.Fatalf("Unexpected export: %v", m)
+	}
+
+	v, err = vm.RunString(`new Map([["a", 1], ["b", 2]])`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m1 map[string]int
+	if err := vm.ExportTo(v, &m1); err != nil {
+		t.Fatal(err)
+	}
+	if len(m1) != 2 || m1["a"] != 1 || m1["b"] != 2 {
+		t.Fatalf("Unexpected export: %v", m1)
+	}
+}
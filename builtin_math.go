@@ -2,6 +2,7 @@ package goja
 
 import (
 	"math"
+	"math/bits"
 )
 //Math.abs(x)
 //返回一个数的绝对值。
@@ -164,6 +165,131 @@ func (r *Runtime) math_sqrt(call FunctionCall) Value {
 func (r *Runtime) math_tan(call FunctionCall) Value {
 	return floatToValue(math.Tan(call.Argument(0).ToFloat()))
 }
+//Math.acosh(x)
+//返回一个数的反双曲余弦值。
+func (r *Runtime) math_acosh(call FunctionCall) Value {
+	return floatToValue(math.Acosh(call.Argument(0).ToFloat()))
+}
+//Math.asinh(x)
+//返回一个数的反双曲正弦值。
+func (r *Runtime) math_asinh(call FunctionCall) Value {
+	return floatToValue(math.Asinh(call.Argument(0).ToFloat()))
+}
+//Math.atanh(x)
+//返回一个数的反双曲正切值。
+func (r *Runtime) math_atanh(call FunctionCall) Value {
+	return floatToValue(math.Atanh(call.Argument(0).ToFloat()))
+}
+//Math.cbrt(x)
+//返回一个数的立方根。
+func (r *Runtime) math_cbrt(call FunctionCall) Value {
+	return floatToValue(math.Cbrt(call.Argument(0).ToFloat()))
+}
+//Math.clz32(x)
+//返回一个32位无符号整数二进制表示中前导零的个数。
+func (r *Runtime) math_clz32(call FunctionCall) Value {
+	return intToValue(int64(bits.LeadingZeros32(toUInt32(call.Argument(0)))))
+}
+//Math.cosh(x)
+//返回一个数的双曲余弦值。
+func (r *Runtime) math_cosh(call FunctionCall) Value {
+	return floatToValue(math.Cosh(call.Argument(0).ToFloat()))
+}
+//Math.expm1(x)
+//返回 exp(x) - 1 的值。
+func (r *Runtime) math_expm1(call FunctionCall) Value {
+	return floatToValue(math.Expm1(call.Argument(0).ToFloat()))
+}
+//Math.fround(x)
+//返回最接近一个数的单精度浮点型表示。
+func (r *Runtime) math_fround(call FunctionCall) Value {
+	return floatToValue(float64(float32(call.Argument(0).ToFloat())))
+}
+//Math.hypot([x[, y[, …]]])
+//返回所有参数的平方和的平方根。
+func (r *Runtime) math_hypot(call FunctionCall) Value {
+	var max float64
+	var hasNaN, hasInf bool
+	values := make([]float64, len(call.Arguments))
+	for i, arg := range call.Arguments {
+		f := arg.ToFloat()
+		values[i] = f
+		switch {
+		case math.IsNaN(f):
+			hasNaN = true
+		case math.IsInf(f, 0):
+			hasInf = true
+		default:
+			max = math.Max(max, math.Abs(f))
+		}
+	}
+	if hasInf {
+		return _positiveInf
+	}
+	if hasNaN {
+		return _NaN
+	}
+	if max == 0 {
+		return intToValue(0)
+	}
+	// 按最大值缩放，避免中间结果溢出
+	var sum float64
+	for _, f := range values {
+		f /= max
+		sum += f * f
+	}
+	return floatToValue(math.Sqrt(sum) * max)
+}
+//Math.imul(x, y)
+//返回两个参数的类C的32位整数乘法运算的运算结果。
+func (r *Runtime) math_imul(call FunctionCall) Value {
+	x := toUInt32(call.Argument(0))
+	y := toUInt32(call.Argument(1))
+	return intToValue(int64(int32(x * y)))
+}
+//Math.log1p(x)
+//返回一个数加1的和的自然对数。
+func (r *Runtime) math_log1p(call FunctionCall) Value {
+	return floatToValue(math.Log1p(call.Argument(0).ToFloat()))
+}
+//Math.log10(x)
+//返回一个数以10为底数的对数。
+func (r *Runtime) math_log10(call FunctionCall) Value {
+	return floatToValue(math.Log10(call.Argument(0).ToFloat()))
+}
+//Math.log2(x)
+//返回一个数以2为底数的对数。
+func (r *Runtime) math_log2(call FunctionCall) Value {
+	return floatToValue(math.Log2(call.Argument(0).ToFloat()))
+}
+//Math.sign(x)
+//返回一个数的符号，得知一个数是正数、负数还是0。
+func (r *Runtime) math_sign(call FunctionCall) Value {
+	f := call.Argument(0).ToFloat()
+	switch {
+	case f > 0:
+		return intToValue(1)
+	case f < 0:
+		return intToValue(-1)
+	}
+	// NaN、0和-0原样返回
+	return floatToValue(f)
+}
+//Math.sinh(x)
+//返回一个数的双曲正弦值。
+func (r *Runtime) math_sinh(call FunctionCall) Value {
+	return floatToValue(math.Sinh(call.Argument(0).ToFloat()))
+}
+//Math.tanh(x)
+//返回一个数的双曲正切值。
+func (r *Runtime) math_tanh(call FunctionCall) Value {
+	return floatToValue(math.Tanh(call.Argument(0).ToFloat()))
+}
+//Math.trunc(x)
+//返回一个数的整数部分，直接去除其小数点及之后的部分。
+func (r *Runtime) math_trunc(call FunctionCall) Value {
+	return floatToValue(math.Trunc(call.Argument(0).ToFloat()))
+}
 // Math库函数注入
 func (r *Runtime) createMath(val *Object) objectImpl {
 	m := &baseObject{
@@ -208,6 +334,24 @@ func (r *Runtime) createMath(val *Object) objectImpl {
 	m._putProp("sin", r.newNativeFunc(r.math_sin, nil, "sin", nil, 1), true, false, true)
 	m._putProp("sqrt", r.newNativeFunc(r.math_sqrt, nil, "sqrt", nil, 1), true, false, true)
 	m._putProp("tan", r.newNativeFunc(r.math_tan, nil, "tan", nil, 1), true, false, true)
+	m._putProp("acosh", r.newNativeFunc(r.math_acosh, nil, "acosh", nil, 1), true, false, true)
+	m._putProp("asinh", r.newNativeFunc(r.math_asinh, nil, "asinh", nil, 1), true, false, true)
+	m._putProp("atanh", r.newNativeFunc(r.math_atanh, nil, "atanh", nil, 1), true, false, true)
+	m._putProp("cbrt", r.newNativeFunc(r.math_cbrt, nil, "cbrt", nil, 1), true, false, true)
+	m._putProp("clz32", r.newNativeFunc(r.math_clz32, nil, "clz32", nil, 1), true, false, true)
+	m._putProp("cosh", r.newNativeFunc(r.math_cosh, nil, "cosh", nil, 1), true, false, true)
+	m._putProp("expm1", r.newNativeFunc(r.math_expm1, nil, "expm1", nil, 1), true, false, true)
+	m._putProp("fround", r.newNativeFunc(r.math_fround, nil, "fround", nil, 1), true, false, true)
+	m._putProp("hypot", r.newNativeFunc(r.math_hypot, nil, "hypot", nil, 2), true, false, true)
+	m._putProp("imul", r.newNativeFunc(r.math_imul, nil, "imul", nil, 2), true, false, true)
+	m._putProp("log1p", r.newNativeFunc(r.math_log1p, nil, "log1p", nil, 1), true, false, true)
+	m._putProp("log10", r.newNativeFunc(r.math_log10, nil, "log10", nil, 1), true, false, true)
+	m._putProp("log2", r.newNativeFunc(r.math_log2, nil, "log2", nil, 1), true, false, true)
+	m._putProp("sign", r.newNativeFunc(r.math_sign, nil, "sign", nil, 1), true, false, true)
+	m._putProp("sinh", r.newNativeFunc(r.math_sinh, nil, "sinh", nil, 1), true, false, true)
+	m._putProp("tanh", r.newNativeFunc(r.math_tanh, nil, "tanh", nil, 1), true, false, true)
+	m._putProp("trunc", r.newNativeFunc(r.math_trunc, nil, "trunc", nil, 1), true, false, true)
+	m._putSym(symToStringTag, asciiString("Math"), false, false, true)
 
 	return m
 }
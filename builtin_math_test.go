@@ -0,0 +1,40 @@
+package goja
+
+import "testing"
+
+func TestMathES6Functions(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(Math.trunc(-4.7), -4, "trunc");
+	assert.sameValue(Math.sign(-3), -1, "sign");
+	assert.sameValue(1 / Math.sign(-0), -Infinity, "sign -0");
+	assert.sameValue(Math.log2(8), 3, "log2");
+	assert.sameValue(Math.log10(1000), 3, "log10");
+	assert.sameValue(Math.hypot(3, 4), 5, "hypot");
+	assert.sameValue(Math.hypot(NaN, Infinity), Infinity, "hypot Infinity");
+	assert.sameValue(Math.hypot(), 0, "hypot no arguments");
+	assert.sameValue(Math.cbrt(27), 3, "cbrt");
+	assert.sameValue(Math.fround(5.5), 5.5, "fround");
+	assert.sameValue(Math.fround(5.05) !== 5.05, true, "fround precision");
+	assert.sameValue(Math.clz32(1), 31, "clz32");
+	assert.sameValue(Math.imul(0xffffffff, 5), -5, "imul");
+	assert.sameValue(Math.expm1(0), 0, "expm1");
+	assert.sameValue(Math.tanh(0), 0, "tanh");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestNumberES6Functions(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(Number.isInteger(5.0), true, "isInteger");
+	assert.sameValue(Number.isInteger("5"), false, "isInteger string");
+	assert.sameValue(Number.isSafeInteger(Math.pow(2, 53) - 1), true, "isSafeInteger");
+	assert.sameValue(Number.isSafeInteger(Math.pow(2, 53)), false, "isSafeInteger unsafe");
+	assert.sameValue(Number.isNaN("x"), false, "isNaN does not coerce");
+	assert.sameValue(Number.isFinite("1"), false, "isFinite does not coerce");
+	assert.sameValue(Number.parseInt, parseInt, "parseInt");
+	assert.sameValue(Number.parseFloat, parseFloat, "parseFloat");
+	assert.sameValue(Number.MAX_SAFE_INTEGER, 9007199254740991, "MAX_SAFE_INTEGER");
+	assert.sameValue(Number.MIN_SAFE_INTEGER, -9007199254740991, "MIN_SAFE_INTEGER");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
@@ -41,6 +41,14 @@ func isNumber(v Value) bool {
 	}
 	return false
 }
+//toLocaleString() 方法返回这个数字在特定语言环境下的表示字符串。
+func (r *Runtime) numberproto_toLocaleString(call FunctionCall) Value {
+	if !isNumber(call.This) {
+		r.typeErrorResult(true, "Value is not a number")
+	}
+	nf := r.newNumberFormat(call.Argument(0), call.Argument(1))
+	return newStringValue(nf.format(call.This.ToFloat()))
+}
 //toString() 方法返回指定 Number 对象的字符串表示形式。
 func (r *Runtime) numberproto_toString(call FunctionCall) Value {
 	if !isNumber(call.This) {
@@ -134,13 +142,53 @@ func (r *Runtime) numberproto_toPrecision(call FunctionCall) Value {
 	}
 	return asciiString(strconv.FormatFloat(num, 'g', int(prec), 64))
 }
+// 非数字类型返回false，否则返回数值
+func numberArg(v Value) (float64, bool) {
+	switch v.(type) {
+	case valueInt, valueFloat:
+		return v.ToFloat(), true
+	}
+	return 0, false
+}
+//Number.isFinite()
+//判断传入的参数是否为有穷数，与全局的isFinite()不同，不会把参数转换为数字。
+func (r *Runtime) number_isFinite(call FunctionCall) Value {
+	f, ok := numberArg(call.Argument(0))
+	return r.toBoolean(ok && !math.IsNaN(f) && !math.IsInf(f, 0))
+}
+//Number.isNaN()
+//判断传入的参数是否为NaN，与全局的isNaN()不同，不会把参数转换为数字。
+func (r *Runtime) number_isNaN(call FunctionCall) Value {
+	f, ok := numberArg(call.Argument(0))
+	return r.toBoolean(ok && math.IsNaN(f))
+}
+// 判断v是否为整数值的数字
+func isIntegerNumber(v Value) (float64, bool) {
+	f, ok := numberArg(v)
+	if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, math.Trunc(f) == f
+}
+//Number.isInteger()
+//判断传入的参数是否为整数。
+func (r *Runtime) number_isInteger(call FunctionCall) Value {
+	_, ok := isIntegerNumber(call.Argument(0))
+	return r.toBoolean(ok)
+}
+//Number.isSafeInteger()
+//判断传入的参数是否为安全整数，即在-(2^53 - 1)到2^53 - 1之间的整数。
+func (r *Runtime) number_isSafeInteger(call FunctionCall) Value {
+	f, ok := isIntegerNumber(call.Argument(0))
+	return r.toBoolean(ok && math.Abs(f) <= maxInt-1)
+}
 // 构造Number类
 func (r *Runtime) initNumber() {
 	r.global.NumberPrototype = r.newPrimitiveObject(valueInt(0), r.global.ObjectPrototype, classNumber)
 	o := r.global.NumberPrototype.self
 	o._putProp("valueOf", r.newNativeFunc(r.numberproto_valueOf, nil, "valueOf", nil, 0), true, false, true)
 	o._putProp("toString", r.newNativeFunc(r.numberproto_toString, nil, "toString", nil, 0), true, false, true)
-	o._putProp("toLocaleString", r.newNativeFunc(r.numberproto_toString, nil, "toLocaleString", nil, 0), true, false, true)
+	o._putProp("toLocaleString", r.newNativeFunc(r.numberproto_toLocaleString, nil, "toLocaleString", nil, 0), true, false, true)
 	o._putProp("toFixed", r.newNativeFunc(r.numberproto_toFixed, nil, "toFixed", nil, 1), true, false, true)
 	o._putProp("toExponential", r.newNativeFunc(r.numberproto_toExponential, nil, "toExponential", nil, 1), true, false, true)
 	o._putProp("toPrecision", r.newNativeFunc(r.numberproto_toPrecision, nil, "toPrecision", nil, 1), true, false, true)
@@ -163,6 +211,14 @@ func (r *Runtime) initNumber() {
 	//Number.EPSILON
 	//两个可表示(representable)数之间的最小间隔。
 	o._putProp("EPSILON", _epsilon, false, false, false)
+	//Number.MAX_SAFE_INTEGER和Number.MIN_SAFE_INTEGER
+	//最大和最小的安全整数，即2^53 - 1和-(2^53 - 1)。
+	o._putProp("MAX_SAFE_INTEGER", intToValue(maxInt-1), false, false, false)
+	o._putProp("MIN_SAFE_INTEGER", intToValue(-(maxInt - 1)), false, false, false)
+	o._putProp("isFinite", r.newNativeFunc(r.number_isFinite, nil, "isFinite", nil, 1), true, false, true)
+	o._putProp("isNaN", r.newNativeFunc(r.number_isNaN, nil, "isNaN", nil, 1), true, false, true)
+	o._putProp("isInteger", r.newNativeFunc(r.number_isInteger, nil, "isInteger", nil, 1), true, false, true)
+	o._putProp("isSafeInteger", r.newNativeFunc(r.number_isSafeInteger, nil, "isSafeInteger", nil, 1), true, false, true)
 	r.addToGlobal("Number", r.global.Number)
 
 }
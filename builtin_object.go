@@ -25,8 +25,10 @@ func (r *Runtime) object_getPrototypeOf(call FunctionCall) Value {
 
 func (r *Runtime) object_getOwnPropertyDescriptor(call FunctionCall) Value {
 	obj := call.Argument(0).ToObject(r)
-	propName := call.Argument(1).String()
-	desc := obj.self.getOwnProp(propName)
+	return r.valuePropToDescriptorObject(getOwnPropValue(obj, call.Argument(1)))
+}
+// 把属性值转换为属性描述符对象，不存在的属性返回undefined
+func (r *Runtime) valuePropToDescriptorObject(desc Value) Value {
 	if desc == nil {
 		return _undefined
 	}
@@ -83,6 +85,19 @@ func (r *Runtime) object_getOwnPropertyNames(call FunctionCall) Value {
 	return r.newArrayValues(values)
 }
 
+// ES6 Object.getOwnPropertySymbols
+func (r *Runtime) object_getOwnPropertySymbols(call FunctionCall) Value {
+	obj := call.Argument(0).ToObject(r)
+	return r.newArrayValues(obj.self.ownSymbols())
+}
+// 按属性键获取自有属性，键可以是symbol
+func getOwnPropValue(o *Object, key Value) Value {
+	if s, ok := key.(*valueSymbol); ok {
+		return o.self.getOwnPropSym(s)
+	}
+	return o.self.getOwnProp(key.String())
+}
+
 func (r *Runtime) toPropertyDescr(v Value) (ret propertyDescr) {
 	if o, ok := v.(*Object); ok {
 		descr := o.self
@@ -125,6 +140,31 @@ func (r *Runtime) toPropertyDescr(v Value) (ret propertyDescr) {
 	return
 }
 
+// 把属性描述符转换为对象，只包含描述符中存在的字段
+func (r *Runtime) fromPropertyDescr(descr propertyDescr) *Object {
+	ret := r.NewObject()
+	o := ret.self
+	if descr.Value != nil {
+		o.putStr("value", descr.Value, false)
+	}
+	if descr.Writable != FLAG_NOT_SET {
+		o.putStr("writable", r.toBoolean(descr.Writable.Bool()), false)
+	}
+	if descr.Getter != nil {
+		o.putStr("get", descr.Getter, false)
+	}
+	if descr.Setter != nil {
+		o.putStr("set", descr.Setter, false)
+	}
+	if descr.Enumerable != FLAG_NOT_SET {
+		o.putStr("enumerable", r.toBoolean(descr.Enumerable.Bool()), false)
+	}
+	if descr.Configurable != FLAG_NOT_SET {
+		o.putStr("configurable", r.toBoolean(descr.Configurable.Bool()), false)
+	}
+	return ret
+}
+
 func (r *Runtime) _defineProperties(o *Object, p Value) {
 	type propItem struct {
 		name string
@@ -200,7 +240,7 @@ func (r *Runtime) object_seal(call FunctionCall) Value {
 				//obj.self._putProp(item.name, v, true, true, false)
 			}
 		}
-		obj.self.preventExtensions()
+		obj.self.preventExtensions(true)
 		return obj
 	}
 	return arg
@@ -226,7 +266,7 @@ func (r *Runtime) object_freeze(call FunctionCall) Value {
 				obj.self.defineOwnProperty(newStringValue(item.name), descr, true)
 			}
 		}
-		obj.self.preventExtensions()
+		obj.self.preventExtensions(true)
 		return obj
 	} else {
 		// ES6 behavior
@@ -237,7 +277,7 @@ func (r *Runtime) object_freeze(call FunctionCall) Value {
 func (r *Runtime) object_preventExtensions(call FunctionCall) (ret Value) {
 	arg := call.Argument(0)
 	if obj, ok := arg.(*Object); ok {
-		obj.self.preventExtensions()
+		obj.self.preventExtensions(true)
 		return obj
 	}
 	// ES6
@@ -320,10 +360,108 @@ func (r *Runtime) object_keys(call FunctionCall) Value {
 	//return nil
 }
 
+// 判断key是否为o的可枚举自有属性
+func isEnumerableOwnProp(o *Object, key Value) bool {
+	pv := getOwnPropValue(o, key)
+	if pv == nil {
+		return false
+	}
+	if prop, ok := pv.(*valueProperty); ok {
+		return prop.enumerable
+	}
+	return true
+}
+// ES6 Object.assign，把各个源对象的可枚举自有属性复制到目标对象
+func (r *Runtime) object_assign(call FunctionCall) Value {
+	to := call.Argument(0).ToObject(r)
+	if len(call.Arguments) > 1 {
+		for _, arg := range call.Arguments[1:] {
+			if arg == _undefined || arg == _null {
+				continue
+			}
+			from := arg.ToObject(r)
+			for _, key := range ownKeys(from) {
+				if isEnumerableOwnProp(from, key) {
+					to.self.put(key, nilSafe(from.self.get(key)), true)
+				}
+			}
+		}
+	}
+	return to
+}
+// ES6 Object.is，使用SameValue比较
+func (r *Runtime) object_is(call FunctionCall) Value {
+	return r.toBoolean(call.Argument(0).SameAs(call.Argument(1)))
+}
+// ES6 Object.setPrototypeOf
+func (r *Runtime) object_setPrototypeOf(call FunctionCall) Value {
+	o := call.Argument(0)
+	r.checkObjectCoercible(o)
+	var proto *Object
+	if arg := call.Argument(1); arg != _null {
+		if p, ok := arg.(*Object); ok {
+			proto = p
+		} else {
+			r.typeErrorResult(true, "Object prototype may only be an Object or null: %s", arg.String())
+		}
+	}
+	if obj, ok := o.(*Object); ok {
+		obj.self.setProto(proto, true)
+	}
+	return o
+}
+// ES2017 Object.values
+func (r *Runtime) object_values(call FunctionCall) Value {
+	obj := call.Argument(0).ToObject(r)
+	var values []Value
+	for item, f := obj.self.enumerate(false, false)(); f != nil; item, f = f() {
+		values = append(values, nilSafe(obj.self.getStr(item.name)))
+	}
+	return r.newArrayValues(values)
+}
+// ES2017 Object.entries，返回[key, value]数组
+func (r *Runtime) object_entries(call FunctionCall) Value {
+	obj := call.Argument(0).ToObject(r)
+	var values []Value
+	for item, f := obj.self.enumerate(false, false)(); f != nil; item, f = f() {
+		values = append(values, r.newArrayValues([]Value{newStringValue(item.name), nilSafe(obj.self.getStr(item.name))}))
+	}
+	return r.newArrayValues(values)
+}
+// ES2017 Object.getOwnPropertyDescriptors
+func (r *Runtime) object_getOwnPropertyDescriptors(call FunctionCall) Value {
+	obj := call.Argument(0).ToObject(r)
+	ret := r.NewObject()
+	for _, key := range ownKeys(obj) {
+		if desc := r.valuePropToDescriptorObject(getOwnPropValue(obj, key)); desc != _undefined {
+			ret.self.put(key, desc, true)
+		}
+	}
+	return ret
+}
+// ES2019 Object.fromEntries，从[key, value]的可迭代对象创建对象
+func (r *Runtime) object_fromEntries(call FunctionCall) Value {
+	iterable := call.Argument(0)
+	r.checkObjectCoercible(iterable)
+	ret := r.NewObject()
+	r.iterateClose(iterable, func(item Value) {
+		entry, ok := item.(*Object)
+		if !ok {
+			r.typeErrorResult(true, "Iterator value %s is not an entry object", item.String())
+		}
+		key := toPropertyKey(nilSafe(entry.self.getStr("0")))
+		ret.self.put(key, nilSafe(entry.self.getStr("1")), true)
+	})
+	return ret
+}
+
 func (r *Runtime) objectproto_hasOwnProperty(call FunctionCall) Value {
-	p := call.Argument(0).String()
+	p := call.Argument(0)
 	o := call.This.ToObject(r)
-	if o.self.hasOwnPropertyStr(p) {
+	if s, ok := p.(*valueSymbol); ok {
+		return r.toBoolean(o.self.hasOwnProperty(s))
+	}
+	if o.self.hasOwnPropertyStr(p.String()) {
 		return valueTrue
 	} else {
 		return valueFalse
@@ -347,9 +485,8 @@ func (r *Runtime) objectproto_isPrototypeOf(call FunctionCall) Value {
 }
 
 func (r *Runtime) objectproto_propertyIsEnumerable(call FunctionCall) Value {
-	p := call.Argument(0).ToString()
 	o := call.This.ToObject(r)
-	pv := o.self.getOwnProp(p.String())
+	pv := getOwnPropValue(o, call.Argument(0))
 	if pv == nil {
 		return valueFalse
 	}
@@ -362,16 +499,18 @@ func (r *Runtime) objectproto_propertyIsEnumerable(call FunctionCall) Value {
 }
 // 对象转字符串
 func (r *Runtime) objectproto_toString(call FunctionCall) Value {
-	switch o := call.This.(type) {
+	switch call.This.(type) {
 	case valueNull:
 		return stringObjectNull
 	case valueUndefined:
 		return stringObjectUndefined
-	case *Object:
-		return newStringValue(fmt.Sprintf("[object %s]", o.self.className()))
 	default:
 		obj := call.This.ToObject(r)
-		return newStringValue(fmt.Sprintf("[object %s]", obj.self.className()))
+		tag := obj.self.className()
+		if t, ok := obj.self.get(symToStringTag).(valueString); ok {
+			tag = t.String()
+		}
+		return newStringValue(fmt.Sprintf("[object %s]", tag))
 	}
 }
 
@@ -399,6 +538,7 @@ func (r *Runtime) initObject() {
 	o._putProp("getOwnPropertyDescriptor", r.newNativeFunc(r.object_getOwnPropertyDescriptor, nil, "getOwnPropertyDescriptor", nil, 2), true, false, true)
 	o._putProp("getPrototypeOf", r.newNativeFunc(r.object_getPrototypeOf, nil, "getPrototypeOf", nil, 1), true, false, true)
 	o._putProp("getOwnPropertyNames", r.newNativeFunc(r.object_getOwnPropertyNames, nil, "getOwnPropertyNames", nil, 1), true, false, true)
+	o._putProp("getOwnPropertySymbols", r.newNativeFunc(r.object_getOwnPropertySymbols, nil, "getOwnPropertySymbols", nil, 1), true, false, true)
 	o._putProp("create", r.newNativeFunc(r.object_create, nil, "create", nil, 2), true, false, true)
 	o._putProp("seal", r.newNativeFunc(r.object_seal, nil, "seal", nil, 1), true, false, true)
 	o._putProp("freeze", r.newNativeFunc(r.object_freeze, nil, "freeze", nil, 1), true, false, true)
@@ -407,6 +547,13 @@ func (r *Runtime) initObject() {
 	o._putProp("isFrozen", r.newNativeFunc(r.object_isFrozen, nil, "isFrozen", nil, 1), true, false, true)
 	o._putProp("isExtensible", r.newNativeFunc(r.object_isExtensible, nil, "isExtensible", nil, 1), true, false, true)
 	o._putProp("keys", r.newNativeFunc(r.object_keys, nil, "keys", nil, 1), true, false, true)
+	o._putProp("assign", r.newNativeFunc(r.object_assign, nil, "assign", nil, 2), true, false, true)
+	o._putProp("is", r.newNativeFunc(r.object_is, nil, "is", nil, 2), true, false, true)
+	o._putProp("setPrototypeOf", r.newNativeFunc(r.object_setPrototypeOf, nil, "setPrototypeOf", nil, 2), true, false, true)
+	o._putProp("values", r.newNativeFunc(r.object_values, nil, "values", nil, 1), true, false, true)
+	o._putProp("entries", r.newNativeFunc(r.object_entries, nil, "entries", nil, 1), true, false, true)
+	o._putProp("getOwnPropertyDescriptors", r.newNativeFunc(r.object_getOwnPropertyDescriptors, nil, "getOwnPropertyDescriptors", nil, 1), true, false, true)
+	o._putProp("fromEntries", r.newNativeFunc(r.object_fromEntries, nil, "fromEntries", nil, 1), true, false, true)
 
 	r.addToGlobal("Object", r.global.Object)
 }
@@ -0,0 +1,33 @@
+package goja
+
+import "testing"
+
+func TestObjectES6Functions(t *testing.T) {
+	const SCRIPT = `
+	var s = Symbol("s");
+	var src = {b: 2};
+	src[s] = 3;
+	Object.defineProperty(src, "hidden", {value: 4, enumerable: false});
+	var target = Object.assign({a: 1}, null, src, undefined);
+	assert.sameValue(target.a + target.b + target[s], 6, "assign");
+	assert.sameValue(target.hasOwnProperty("hidden"), false, "assign skips non-enumerable");
+	assert.sameValue(Object.is(NaN, NaN), true, "is NaN");
+	assert.sameValue(Object.is(0, -0), false, "is -0");
+	assert.sameValue(Object.keys({x: 1}).length, 1);
+	assert.sameValue(Object.values({x: 1, y: 2}).join(), "1,2", "values");
+	assert.sameValue(Object.entries({x: 1, y: 2}).join(";"), "x,1;y,2", "entries");
+	var o = Object.fromEntries(new Map([["a", 1], ["b", 2]]));
+	assert.sameValue(o.a + o.b, 3, "fromEntries");
+	var d = Object.getOwnPropertyDescriptors({get x() { return 1; }, y: 2});
+	assert.sameValue(typeof d.x.get, "function", "getOwnPropertyDescriptors getter");
+	assert.sameValue(d.y.value, 2, "getOwnPropertyDescriptors value");
+	var proto = {p: 1};
+	var child = Object.setPrototypeOf({}, proto);
+	assert.sameValue(child.p, 1, "setPrototypeOf");
+	assert.sameValue(Object.setPrototypeOf(1, null), 1, "setPrototypeOf primitive");
+	assert.throws(TypeError, function() {
+		Object.setPrototypeOf(proto, child);
+	}, "setPrototypeOf cycle");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
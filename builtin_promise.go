@@ -0,0 +1,587 @@
+package goja
+
+import "sync"
+
+// PromiseState is the state of a Promise
+type PromiseState int
+
+const (
+	PromiseStatePending PromiseState = iota
+	PromiseStateFulfilled
+	PromiseStateRejected
+)
+
+type promiseReactionType int
+
+const (
+	promiseReactionFulfill promiseReactionType = iota
+	promiseReactionReject
+)
+
+// 由then注册的回调
+type promiseReaction struct {
+	capability *promiseCapability
+	typ        promiseReactionType
+	handler    func(FunctionCall) Value // 为nil时直接传递结果
+}
+
+// 新建的promise及其resolve/reject函数
+type promiseCapability struct {
+	promise       *Object
+	resolveObject *Object
+	rejectObject  *Object
+}
+
+// Promise is the implementation of a JavaScript Promise object. It can be obtained with Export() from
+// a Promise Value or with Runtime.NewPromise().
+type Promise struct {
+	baseObject
+	state            PromiseState
+	result           Value
+	fulfillReactions []*promiseReaction
+	rejectReactions  []*promiseReaction
+	// async函数因异常而拒绝时的异常，await时重新抛出以保留调用栈
+	exception *Exception
+}
+
+// 宿主代码通过QueueHostJob提交的任务，可以从任意goroutine调用
+type hostJobQueue struct {
+	mu    sync.Mutex
+	jobs  []func()
+	ready chan struct{}
+}
+
+// State returns the current state of the Promise.
+func (p *Promise) State() PromiseState {
+	return p.state
+}
+
+// Result returns the fulfillment value or the rejection reason of the Promise, or nil if it is still pending.
+func (p *Promise) Result() Value {
+	return p.result
+}
+
+func (p *Promise) export() interface{} {
+	return p
+}
+// 创建resolve和reject函数，两者共享同一个已决议标志
+func (p *Promise) createResolvingFunctions() (resolve, reject *Object) {
+	r := p.val.runtime
+	alreadyResolved := false
+	resolve = r.newNativeFunc(func(call FunctionCall) Value {
+		if alreadyResolved {
+			return _undefined
+		}
+		alreadyResolved = true
+		p.resolve(call.Argument(0))
+		return _undefined
+	}, nil, "", nil, 1)
+	reject = r.newNativeFunc(func(call FunctionCall) Value {
+		if alreadyResolved {
+			return _undefined
+		}
+		alreadyResolved = true
+		p.reject(call.Argument(0))
+		return _undefined
+	}, nil, "", nil, 1)
+	return
+}
+// 用resolution决议promise，如果resolution是thenable则跟随其状态
+func (p *Promise) resolve(resolution Value) {
+	r := p.val.runtime
+	if resolution == p.val {
+		p.reject(r.NewTypeError("Chaining cycle detected for promise"))
+		return
+	}
+	obj, ok := resolution.(*Object)
+	if !ok {
+		p.fulfill(resolution)
+		return
+	}
+	var then Value
+	if ex := r.vm.try(func() {
+		then = obj.self.getStr("then")
+	}); ex != nil {
+		p.reject(ex.val)
+		return
+	}
+	thenObj, ok := then.(*Object)
+	if !ok {
+		p.fulfill(resolution)
+		return
+	}
+	call, ok := thenObj.self.assertCallable()
+	if !ok {
+		p.fulfill(resolution)
+		return
+	}
+	r.enqueuePromiseJob(func() {
+		resolve, reject := p.createResolvingFunctions()
+		if ex := r.vm.try(func() {
+			call(FunctionCall{
+				This:      obj,
+				Arguments: []Value{resolve, reject},
+			})
+		}); ex != nil {
+			r.toCallable(reject)(FunctionCall{Arguments: []Value{ex.val}})
+		}
+	})
+}
+// 以value兑现
+func (p *Promise) fulfill(value Value) {
+	reactions := p.fulfillReactions
+	p.result = value
+	p.fulfillReactions = nil
+	p.rejectReactions = nil
+	p.state = PromiseStateFulfilled
+	p.triggerReactions(reactions, value)
+}
+// 以reason拒绝
+func (p *Promise) reject(reason Value) {
+	reactions := p.rejectReactions
+	p.result = reason
+	p.fulfillReactions = nil
+	p.rejectReactions = nil
+	p.state = PromiseStateRejected
+	p.triggerReactions(reactions, reason)
+}
+// 为每个回调添加一个任务
+func (p *Promise) triggerReactions(reactions []*promiseReaction, argument Value) {
+	r := p.val.runtime
+	for _, reaction := range reactions {
+		r.enqueuePromiseJob(r.newPromiseReactionJob(reaction, argument))
+	}
+}
+// 执行回调并用其结果决议派生的promise
+func (r *Runtime) newPromiseReactionJob(reaction *promiseReaction, argument Value) func() {
+	return func() {
+		var handlerResult Value
+		fulfill := false
+		if reaction.handler == nil {
+			handlerResult = argument
+			fulfill = reaction.typ == promiseReactionFulfill
+		} else {
+			if ex := r.vm.try(func() {
+				handlerResult = reaction.handler(FunctionCall{Arguments: []Value{argument}})
+				fulfill = true
+			}); ex != nil {
+				handlerResult = ex.val
+			}
+		}
+		if cap := reaction.capability; cap != nil {
+			if fulfill {
+				cap.resolve(handlerResult)
+			} else {
+				cap.reject(handlerResult)
+			}
+		}
+	}
+}
+// 调用capability的resolve函数
+func (c *promiseCapability) resolve(result Value) {
+	r := c.promise.runtime
+	r.toCallable(c.resolveObject)(FunctionCall{Arguments: []Value{result}})
+}
+// 调用capability的reject函数
+func (c *promiseCapability) reject(reason Value) {
+	r := c.promise.runtime
+	r.toCallable(c.rejectObject)(FunctionCall{Arguments: []Value{reason}})
+}
+// 用try执行f，出现异常时拒绝capability的promise
+func (c *promiseCapability) try(f func()) bool {
+	r := c.promise.runtime
+	if ex := r.vm.try(f); ex != nil {
+		c.reject(ex.val)
+		return false
+	}
+	return true
+}
+// 创建一个pending状态的promise
+func (r *Runtime) newPromise(proto *Object) *Promise {
+	o := &Object{runtime: r}
+
+	p := &Promise{}
+	p.class = classPromise
+	p.val = o
+	p.extensible = true
+	o.self = p
+	p.prototype = proto
+	p.init()
+	return p
+}
+// 为构造函数c创建一个新的promise及其resolve/reject函数
+func (r *Runtime) newPromiseCapability(c *Object) *promiseCapability {
+	if c == r.global.Promise {
+		p := r.newPromise(r.global.PromisePrototype)
+		resolve, reject := p.createResolvingFunctions()
+		return &promiseCapability{
+			promise:       p.val,
+			resolveObject: resolve,
+			rejectObject:  reject,
+		}
+	}
+	var resolve, reject Value
+	executor := r.newNativeFunc(func(call FunctionCall) Value {
+		if resolve != nil && resolve != _undefined || reject != nil && reject != _undefined {
+			r.typeErrorResult(true, "Promise executor has already been invoked with non-undefined arguments")
+		}
+		resolve = call.Argument(0)
+		reject = call.Argument(1)
+		return _undefined
+	}, nil, "", nil, 2)
+	promise := r.builtin_new(c, []Value{executor})
+	resolveObj, ok := resolve.(*Object)
+	if !ok || !r.isCallable(resolveObj) {
+		r.typeErrorResult(true, "Promise resolve function is not callable")
+	}
+	rejectObj, ok := reject.(*Object)
+	if !ok || !r.isCallable(rejectObj) {
+		r.typeErrorResult(true, "Promise reject function is not callable")
+	}
+	return &promiseCapability{
+		promise:       promise,
+		resolveObject: resolveObj,
+		rejectObject:  rejectObj,
+	}
+}
+// 判断对象是否可调用
+func (r *Runtime) isCallable(o *Object) bool {
+	_, ok := o.self.assertCallable()
+	return ok
+}
+// 注册回调，返回派生的promise
+func (p *Promise) then(onFulfilled, onRejected Value, cap *promiseCapability) Value {
+	r := p.val.runtime
+	return p.addReactions(r.toHandler(onFulfilled), r.toHandler(onRejected), cap)
+}
+// 注册Go实现的回调，cap为nil时不创建派生的promise
+func (p *Promise) addReactions(onFulfilled, onRejected func(FunctionCall) Value, cap *promiseCapability) Value {
+	r := p.val.runtime
+	fulfillReaction := &promiseReaction{
+		capability: cap,
+		typ:        promiseReactionFulfill,
+		handler:    onFulfilled,
+	}
+	rejectReaction := &promiseReaction{
+		capability: cap,
+		typ:        promiseReactionReject,
+		handler:    onRejected,
+	}
+	switch p.state {
+	case PromiseStatePending:
+		p.fulfillReactions = append(p.fulfillReactions, fulfillReaction)
+		p.rejectReactions = append(p.rejectReactions, rejectReaction)
+	case PromiseStateFulfilled:
+		r.enqueuePromiseJob(r.newPromiseReactionJob(fulfillReaction, p.result))
+	default:
+		r.enqueuePromiseJob(r.newPromiseReactionJob(rejectReaction, p.result))
+	}
+	if cap == nil {
+		return _undefined
+	}
+	return cap.promise
+}
+// 不可调用的回调被忽略
+func (r *Runtime) toHandler(v Value) func(FunctionCall) Value {
+	if o, ok := v.(*Object); ok {
+		if call, ok := o.self.assertCallable(); ok {
+			return call
+		}
+	}
+	return nil
+}
+// 取出this对应的Promise
+func (r *Runtime) thisPromise(v Value, method string) *Promise {
+	if o, ok := v.(*Object); ok {
+		if p, ok := o.self.(*Promise); ok {
+			return p
+		}
+	}
+	r.typeErrorResult(true, "Method Promise.prototype.%s called on incompatible receiver %s", method, v.String())
+	return nil
+}
+// this必须是构造函数
+func (r *Runtime) thisConstructor(v Value, method string) *Object {
+	if o, ok := v.(*Object); ok {
+		switch o.self.(type) {
+		case *nativeFuncObject, *boundFuncObject, *funcObject, *lazyObject:
+			return o
+		}
+	}
+	r.typeErrorResult(true, "Promise.%s called on non-object", method)
+	return nil
+}
+// Promise()不能作为普通函数调用
+func (r *Runtime) builtin_Promise(call FunctionCall) Value {
+	r.typeErrorResult(true, "Promise constructor cannot be invoked without 'new'")
+	return nil
+}
+// new Promise(executor)实现
+func (r *Runtime) builtin_newPromise(args []Value) *Object {
+	var arg0 Value = _undefined
+	if len(args) > 0 {
+		arg0 = args[0]
+	}
+	executor := r.toHandler(arg0)
+	if executor == nil {
+		r.typeErrorResult(true, "Promise resolver %s is not a function", arg0.String())
+	}
+	p := r.newPromise(r.global.PromisePrototype)
+	resolve, reject := p.createResolvingFunctions()
+	if ex := r.vm.try(func() {
+		executor(FunctionCall{Arguments: []Value{resolve, reject}})
+	}); ex != nil {
+		r.toCallable(reject)(FunctionCall{Arguments: []Value{ex.val}})
+	}
+	return p.val
+}
+// Promise.prototype.then实现
+func (r *Runtime) promiseproto_then(call FunctionCall) Value {
+	p := r.thisPromise(call.This, "then")
+	cap := r.newPromiseCapability(r.global.Promise)
+	return p.then(call.Argument(0), call.Argument(1), cap)
+}
+// 调用o[name](args...)
+func (r *Runtime) invoke(v Value, name string, args ...Value) Value {
+	o := v.ToObject(r)
+	return r.toCallable(o.self.getStr(name))(FunctionCall{This: v, Arguments: args})
+}
+// Promise.prototype.catch实现
+func (r *Runtime) promiseproto_catch(call FunctionCall) Value {
+	return r.invoke(call.This, "then", _undefined, call.Argument(0))
+}
+// Promise.prototype.finally实现，回调不接收参数，也不改变结果(除非抛出异常)
+func (r *Runtime) promiseproto_finally(call FunctionCall) Value {
+	if _, ok := call.This.(*Object); !ok {
+		r.typeErrorResult(true, "Method Promise.prototype.finally called on incompatible receiver %s", call.This.String())
+	}
+	onFinally := r.toHandler(call.Argument(0))
+	if onFinally == nil {
+		return r.invoke(call.This, "then", call.Argument(0), call.Argument(0))
+	}
+	thenFinally := r.newNativeFunc(func(call FunctionCall) Value {
+		value := call.Argument(0)
+		result := onFinally(FunctionCall{})
+		promise := r.promiseResolve(r.global.Promise, result)
+		valueThunk := r.newNativeFunc(func(FunctionCall) Value {
+			return value
+		}, nil, "", nil, 0)
+		return r.invoke(promise, "then", valueThunk)
+	}, nil, "", nil, 1)
+	catchFinally := r.newNativeFunc(func(call FunctionCall) Value {
+		reason := call.Argument(0)
+		result := onFinally(FunctionCall{})
+		promise := r.promiseResolve(r.global.Promise, result)
+		thrower := r.newNativeFunc(func(FunctionCall) Value {
+			panic(reason)
+		}, nil, "", nil, 0)
+		return r.invoke(promise, "then", thrower)
+	}, nil, "", nil, 1)
+	return r.invoke(call.This, "then", thenFinally, catchFinally)
+}
+// 把x转换为构造函数c的promise
+func (r *Runtime) promiseResolve(c *Object, x Value) *Object {
+	if obj, ok := x.(*Object); ok {
+		if _, ok := obj.self.(*Promise); ok && obj.self.getStr("constructor") == c {
+			return obj
+		}
+	}
+	cap := r.newPromiseCapability(c)
+	cap.resolve(x)
+	return cap.promise
+}
+// Promise.resolve实现
+func (r *Runtime) promise_resolve(call FunctionCall) Value {
+	c := r.thisConstructor(call.This, "resolve")
+	return r.promiseResolve(c, call.Argument(0))
+}
+// Promise.reject实现
+func (r *Runtime) promise_reject(call FunctionCall) Value {
+	c := r.thisConstructor(call.This, "reject")
+	cap := r.newPromiseCapability(c)
+	cap.reject(call.Argument(0))
+	return cap.promise
+}
+// 对iterable中每个值调用c.resolve，再调用step
+func (r *Runtime) promiseIterate(c *Object, iterable Value, step func(index int, next Value)) {
+	resolve := r.toCallable(c.self.getStr("resolve"))
+	iter := r.getIterator(iterable)
+	index := 0
+	for {
+		value, done := r.iteratorNext(iter)
+		if done {
+			break
+		}
+		var next Value
+		if ex := r.vm.try(func() {
+			next = resolve(FunctionCall{This: c, Arguments: []Value{value}})
+			step(index, next)
+		}); ex != nil {
+			r.iteratorClose(iter)
+			panic(ex)
+		}
+		index++
+	}
+}
+// Promise.all实现
+func (r *Runtime) promise_all(call FunctionCall) Value {
+	c := r.thisConstructor(call.This, "all")
+	cap := r.newPromiseCapability(c)
+	cap.try(func() {
+		var values []Value
+		remaining := 1
+		r.promiseIterate(c, call.Argument(0), func(index int, next Value) {
+			values = append(values, _undefined)
+			alreadyCalled := false
+			resolveElement := r.newNativeFunc(func(call FunctionCall) Value {
+				if alreadyCalled {
+					return _undefined
+				}
+				alreadyCalled = true
+				values[index] = call.Argument(0)
+				remaining--
+				if remaining == 0 {
+					cap.resolve(r.newArrayValues(values))
+				}
+				return _undefined
+			}, nil, "", nil, 1)
+			remaining++
+			r.invoke(next, "then", resolveElement, cap.rejectObject)
+		})
+		remaining--
+		if remaining == 0 {
+			cap.resolve(r.newArrayValues(values))
+		}
+	})
+	return cap.promise
+}
+// Promise.race实现
+func (r *Runtime) promise_race(call FunctionCall) Value {
+	c := r.thisConstructor(call.This, "race")
+	cap := r.newPromiseCapability(c)
+	cap.try(func() {
+		r.promiseIterate(c, call.Argument(0), func(index int, next Value) {
+			r.invoke(next, "then", cap.resolveObject, cap.rejectObject)
+		})
+	})
+	return cap.promise
+}
+// Promise类实现
+func (r *Runtime) initPromise() {
+	r.global.PromisePrototype = r.newBaseObject(r.global.ObjectPrototype, classObject).val
+	o := r.global.PromisePrototype.self
+	o._putProp("then", r.newNativeFunc(r.promiseproto_then, nil, "then", nil, 2), true, false, true)
+	o._putProp("catch", r.newNativeFunc(r.promiseproto_catch, nil, "catch", nil, 1), true, false, true)
+	o._putProp("finally", r.newNativeFunc(r.promiseproto_finally, nil, "finally", nil, 1), true, false, true)
+	o._putSym(symToStringTag, asciiString(classPromise), false, false, true)
+
+	r.global.Promise = r.newNativeFunc(r.builtin_Promise, r.builtin_newPromise, "Promise", r.global.PromisePrototype, 1)
+	o = r.global.Promise.self
+	o._putProp("all", r.newNativeFunc(r.promise_all, nil, "all", nil, 1), true, false, true)
+	o._putProp("race", r.newNativeFunc(r.promise_race, nil, "race", nil, 1), true, false, true)
+	o._putProp("resolve", r.newNativeFunc(r.promise_resolve, nil, "resolve", nil, 1), true, false, true)
+	o._putProp("reject", r.newNativeFunc(r.promise_reject, nil, "reject", nil, 1), true, false, true)
+
+	r.addToGlobal("Promise", r.global.Promise)
+}
+// 添加一个promise任务，在当前脚本或回调执行完后运行
+func (r *Runtime) enqueuePromiseJob(job func()) {
+	r.jobQueue = append(r.jobQueue, job)
+}
+// 依次执行所有宿主任务和promise任务，直到队列为空
+func (r *Runtime) leave() {
+	if r.runningJobs {
+		return
+	}
+	r.runningJobs = true
+	defer func() {
+		r.runningJobs = false
+	}()
+	for {
+		jobs := r.hostJobs.take()
+		jobs = append(jobs, r.jobQueue...)
+		r.jobQueue = nil
+		if len(jobs) == 0 {
+			break
+		}
+		for i, job := range jobs {
+			jobs[i] = nil
+			r.vm.try(job)
+			r.vm.clearStack()
+		}
+	}
+}
+// 执行被中止时丢弃尚未执行的promise任务，避免它们在下一次执行时运行
+func (r *Runtime) dropJobs() {
+	r.jobQueue = nil
+}
+// 取出所有宿主任务
+func (q *hostJobQueue) take() []func() {
+	q.mu.Lock()
+	jobs := q.jobs
+	q.jobs = nil
+	q.mu.Unlock()
+	return jobs
+}
+
+// NewPromise creates and returns a Promise and resolving functions for it.
+//
+// The resolve and reject functions are not goroutine-safe, they must be called on the goroutine that runs
+// the Runtime. To settle the promise from another goroutine hand the call over with QueueHostJob():
+//
+//     p, resolve, _ := vm.NewPromise()
+//     vm.Set("p", p)
+//     go func() {
+//         result := doWork()
+//         vm.QueueHostJob(func() {
+//             resolve(result)
+//         })
+//     }()
+//
+// When called while no JavaScript code is running, resolve and reject run the resulting promise jobs
+// (i.e. the then() callbacks) before returning.
+func (r *Runtime) NewPromise() (promise *Promise, resolve func(result interface{}), reject func(reason interface{})) {
+	p := r.newPromise(r.global.PromisePrototype)
+	resolveF, rejectF := p.createResolvingFunctions()
+	resolveCall := r.toCallable(resolveF)
+	rejectCall := r.toCallable(rejectF)
+	return p, func(result interface{}) {
+			r.settle(resolveCall, result)
+		}, func(reason interface{}) {
+			r.settle(rejectCall, reason)
+		}
+}
+// 调用resolve或reject函数，如果不在JS代码中则立即执行产生的任务
+func (r *Runtime) settle(f func(FunctionCall) Value, value interface{}) {
+	toplevel := len(r.vm.callStack) == 0
+	f(FunctionCall{Arguments: []Value{r.ToValue(value)}})
+	if toplevel {
+		r.leave()
+	}
+}
+
+// QueueHostJob schedules job to be run on the goroutine that runs the Runtime. It is safe to call it from
+// any goroutine. The job runs when the current Run*() call or Callable invocation has finished, or when
+// RunHostJobs() is called. Exceptions thrown by the job are ignored.
+func (r *Runtime) QueueHostJob(job func()) {
+	q := &r.hostJobs
+	q.mu.Lock()
+	q.jobs = append(q.jobs, job)
+	q.mu.Unlock()
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// HostJobReady returns a channel that receives a value when a job has been queued with QueueHostJob().
+// It can be used by the goroutine that owns the Runtime to wait for jobs before calling RunHostJobs().
+func (r *Runtime) HostJobReady() <-chan struct{} {
+	return r.hostJobs.ready
+}
+
+// RunHostJobs runs all jobs queued with QueueHostJob() followed by the promise jobs they produced. It must be
+// called on the goroutine that runs the Runtime, when no JavaScript code is running.
+func (r *Runtime) RunHostJobs() {
+	r.leave()
+}
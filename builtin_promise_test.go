@@ -0,0 +1,209 @@
+package goja
+
+import "testing"
+
+// 执行脚本(包括产生的promise任务)，再对expr求值
+func testPromiseScript(script, expr string, expected string, t *testing.T) {
+	r := New()
+	if _, err := r.RunString(script); err != nil {
+		t.Fatal(err)
+	}
+	v, err := r.RunString(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := v.String(); s != expected {
+		t.Fatalf("Result: %s, expected: %s", s, expected)
+	}
+}
+
+func TestPromiseJobOrder(t *testing.T) {
+	const SCRIPT = `
+	var log = [];
+	Promise.resolve().then(() => log.push(1)).then(() => log.push(3));
+	Promise.resolve().then(() => log.push(2)).then(() => log.push(4));
+	log.push(0);
+	`
+	testPromiseScript(SCRIPT, "log.join()", "0,1,2,3,4", t)
+}
+
+func TestPromiseThenCatchFinally(t *testing.T) {
+	const SCRIPT = `
+	var log = [];
+	new Promise(function(resolve) {
+		resolve(5);
+	}).then(function(v) {
+		log.push(v);
+		throw 7;
+	}).then(function() {
+		log.push("not reached");
+	}).catch(function(e) {
+		log.push("c" + e);
+		return 8;
+	}).finally(function() {
+		log.push("f");
+		return 9;
+	}).then(function(v) {
+		log.push(v);
+	});
+	new Promise(function() {
+		throw 1;
+	}).then(null, function(e) {
+		log.push("r" + e);
+	});
+	Promise.reject(2).finally(function() {}).catch(function(e) {
+		log.push("r" + e);
+	});
+	var thenable = {then: function(resolve) { resolve("t"); }};
+	Promise.resolve(thenable).then(function(v) {
+		log.push(v);
+	});
+	var p = Promise.resolve().then(function() {
+		return p;
+	});
+	p.catch(function(e) {
+		log.push(e instanceof TypeError);
+	});
+	`
+	testPromiseScript(SCRIPT, "log.join()", "5,r1,t,true,c7,f,r2,8", t)
+}
+
+func TestPromiseAllRace(t *testing.T) {
+	const SCRIPT = `
+	var log = [];
+	Promise.all([1, Promise.resolve(2), new Promise(function(r) { r(3); })]).then(function(v) {
+		log.push("all:" + v.join("|"));
+	});
+	Promise.all([]).then(function(v) {
+		log.push("empty:" + v.length);
+	});
+	Promise.all([1, Promise.reject("e")]).catch(function(e) {
+		log.push("allrej:" + e);
+	});
+	Promise.race([new Promise(function() {}), Promise.resolve("r")]).then(function(v) {
+		log.push("race:" + v);
+	});
+	Promise.all(1).catch(function(e) {
+		log.push(e instanceof TypeError);
+	});
+	`
+	testPromiseScript(SCRIPT, "log.sort().join()", "all:1|2|3,allrej:e,empty:0,race:r,true", t)
+}
+
+func TestPromiseBuiltin(t *testing.T) {
+	const SCRIPT = `
+	assert.throws(TypeError, function() { Promise(function() {}); }, "call without new");
+	assert.throws(TypeError, function() { new Promise(1); }, "non-callable executor");
+	assert.throws(TypeError, function() { Promise.prototype.then.call({}); }, "incompatible receiver");
+	assert.sameValue(Object.prototype.toString.call(Promise.resolve()), "[object Promise]", "toStringTag");
+	var p = Promise.resolve(1);
+	assert.sameValue(Promise.resolve(p), p, "resolve returns the same promise");
+	class MyPromise extends Promise {}
+	assert(MyPromise.resolve(1) instanceof MyPromise, "subclass");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestNewPromise(t *testing.T) {
+	r := New()
+	p, resolve, reject := r.NewPromise()
+	r.Set("p", p)
+	if _, err := r.RunString(`var res; p.then(function(v) { res = v; });`); err != nil {
+		t.Fatal(err)
+	}
+	if p.State() != PromiseStatePending {
+		t.Fatal("Expected a pending promise")
+	}
+	resolve(42)
+	if p.State() != PromiseStateFulfilled || p.Result().ToInteger() != 42 {
+		t.Fatalf("Unexpected state: %v, %v", p.State(), p.Result())
+	}
+	if v := r.Get("res"); v.ToInteger() != 42 {
+		t.Fatalf("Callback has not run: %v", v)
+	}
+	reject("ignored")
+	if p.State() != PromiseStateFulfilled {
+		t.Fatal("Settled promise has changed its state")
+	}
+	if exp, ok := r.Get("p").Export().(*Promise); !ok || exp != p {
+		t.Fatal("Unexpected Export() result")
+	}
+}
+
+func TestPromiseHostJob(t *testing.T) {
+	r := New()
+	p, resolve, _ := r.NewPromise()
+	r.Set("p", p)
+	_, err := r.RunString(`
+	var res;
+	p.then(function(v) {
+		res = v + 1;
+	});
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		r.QueueHostJob(func() {
+			resolve(1)
+		})
+	}()
+	<-r.HostJobReady()
+	r.RunHostJobs()
+	if v := r.Get("res"); v.ToInteger() != 2 {
+		t.Fatalf("Unexpected result: %v", v)
+	}
+}
+
+func TestPromiseCallableDrainsJobs(t *testing.T) {
+	r := New()
+	_, err := r.RunString(`
+	var res = 0;
+	function f() {
+		Promise.resolve().then(function() {
+			res++;
+		});
+	}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, _ := AssertFunction(r.Get("f"))
+	if _, err := f(nil); err != nil {
+		t.Fatal(err)
+	}
+	if v := r.Get("res"); v.ToInteger() != 1 {
+		t.Fatalf("Unexpected result: %v", v)
+	}
+}
+
+func TestPromiseJobsDroppedOnAbort(t *testing.T) {
+	r := New()
+	_, err := r.RunString(`
+	var res = 0;
+	function f() {
+		Promise.resolve().then(function() {
+			res++;
+		});
+		for (;;) {}
+	}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetGasLimit(r.GasUsed() + 10000)
+	if _, err := r.RunString(`f()`); err == nil {
+		t.Fatal("Expected an error")
+	}
+	fn, _ := AssertFunction(r.Get("f"))
+	if _, err := fn(nil); err == nil {
+		t.Fatal("Expected an error")
+	}
+	r.SetGasLimit(0)
+	if _, err := r.RunString(`Promise.resolve().then(function() { res += 10; })`); err != nil {
+		t.Fatal(err)
+	}
+	if v := r.Get("res"); v.ToInteger() != 10 {
+		t.Fatalf("Unexpected result: %v", v)
+	}
+}
@@ -0,0 +1,42 @@
+package goja
+
+// 用JS对象作为处理器创建代理
+func (r *Runtime) newProxy(args []Value) *Proxy {
+	if len(args) >= 2 {
+		if target, ok := args[0].(*Object); ok {
+			if handler, ok := args[1].(*Object); ok {
+				return r.newProxyObject(target, &jsProxyHandler{handler: handler})
+			}
+		}
+	}
+	r.typeErrorResult(true, "Cannot create proxy with a non-object as target or handler")
+	return nil
+}
+// Proxy()不能作为普通函数调用
+func (r *Runtime) builtin_Proxy(call FunctionCall) Value {
+	r.typeErrorResult(true, "Constructor Proxy requires 'new'")
+	return nil
+}
+// new Proxy(target, handler)实现
+func (r *Runtime) builtin_newProxy(args []Value) *Object {
+	return r.newProxy(args).val
+}
+// Proxy.revocable实现，返回{proxy, revoke}
+func (r *Runtime) proxy_revocable(call FunctionCall) Value {
+	p := r.newProxy(call.Arguments)
+	revoke := r.newNativeFunc(func(FunctionCall) Value {
+		p.Revoke()
+		return _undefined
+	}, nil, "", nil, 0)
+	ret := r.NewObject()
+	ret.self._putProp("proxy", p.val, true, true, true)
+	ret.self._putProp("revoke", revoke, true, true, true)
+	return ret
+}
+// Proxy类实现，没有prototype属性
+func (r *Runtime) initProxy() {
+	r.global.Proxy = r.newNativeFunc(r.builtin_Proxy, r.builtin_newProxy, "Proxy", nil, 2)
+	o := r.global.Proxy.self
+	o._putProp("revocable", r.newNativeFunc(r.proxy_revocable, nil, "revocable", nil, 2), true, false, true)
+	r.addToGlobal("Proxy", r.global.Proxy)
+}
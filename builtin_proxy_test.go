@@ -0,0 +1,257 @@
+package goja
+
+import "testing"
+
+func TestProxyTraps(t *testing.T) {
+	const SCRIPT = `
+	var log = [];
+	var target = {a: 1};
+	var p = new Proxy(target, {
+		get: function(t, k, receiver) {
+			log.push("get " + String(k));
+			return k in t ? t[k] : "default";
+		},
+		set: function(t, k, v) { log.push("set " + k); t[k] = v * 2; return true; },
+		has: function(t, k) { return k === "hidden" ? false : k in t; },
+		deleteProperty: function(t, k) { log.push("delete " + k); return delete t[k]; },
+		ownKeys: function(t) { return Object.keys(t).concat(["extra"]); },
+		getOwnPropertyDescriptor: function(t, k) {
+			if (k === "extra") {
+				return {value: "x", enumerable: true, configurable: true, writable: true};
+			}
+			return Object.getOwnPropertyDescriptor(t, k);
+		}
+	});
+	assert.sameValue(p.a, 1, "get existing");
+	assert.sameValue(p.b, "default", "get missing");
+	p.b = 2;
+	assert.sameValue(target.b, 4, "set");
+	target.hidden = true;
+	assert.sameValue("hidden" in p, false, "has");
+	assert.sameValue("a" in p, true, "has existing");
+	assert.sameValue(delete p.b, true, "delete");
+	assert.sameValue(target.hasOwnProperty("b"), false, "deleted");
+	assert.sameValue(Object.keys(p).join(), "a,hidden,extra", "ownKeys");
+	assert.sameValue(log.join(), "get a,get b,set b,delete b", "log");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestProxyWith(t *testing.T) {
+	const SCRIPT = `
+	var x = "outer", r = [];
+	with (new Proxy({}, {})) {
+		r.push(x);
+	}
+	assert.sameValue(r.join(), "outer", "empty handler");
+
+	var log = [];
+	var target = {x: "inner", y: "hidden"};
+	var p = new Proxy(target, {
+		has: function(t, k) {
+			log.push("has " + k);
+			return k !== "y" && k in t;
+		},
+		get: function(t, k) {
+			log.push("get " + String(k));
+			return t[k];
+		}
+	});
+	var y = "outer y";
+	with (p) {
+		r = [x, y];
+		x = "changed";
+	}
+	assert.sameValue(r.join(), "inner,outer y", "has trap");
+	assert.sameValue(target.x, "changed", "assignment");
+	assert.sameValue(y, "outer y");
+	assert.sameValue(log.indexOf("get y"), -1, "get is not called when has returns false");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestProxyDelete(t *testing.T) {
+	const SCRIPT = `
+	var log = [];
+	var p = new Proxy({a: 1}, {
+		has: function(t, k) { log.push("has " + k); return k in t; },
+		deleteProperty: function(t, k) { log.push("delete " + k); return k !== "a"; }
+	});
+	assert.sameValue(delete p.a, false, "trap returned false");
+	assert.sameValue(delete p["a"], false, "trap returned false (elem)");
+	assert.sameValue(delete p.missing, true, "missing");
+	assert.sameValue(log.join(), "delete a,delete a,delete missing", "only deleteProperty is called");
+	assert.throws(TypeError, function() {
+		"use strict";
+		delete p.a;
+	});
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestProxyForwarding(t *testing.T) {
+	const SCRIPT = `
+	var target = [1, 2, 3];
+	var p = new Proxy(target, {});
+	assert.sameValue(p.length, 3, "length");
+	p.push(4);
+	assert.sameValue(target.length, 4, "push");
+	assert.sameValue(Array.isArray(p), true, "isArray");
+	assert.sameValue(Object.prototype.toString.call(p), "[object Array]", "toString");
+	assert.sameValue(typeof new Proxy({}, {}), "object", "typeof object");
+	var fp = new Proxy(function(a, b) { return a + b; }, {});
+	assert.sameValue(typeof fp, "function", "typeof function");
+	assert.sameValue(fp(1, 2), 3, "call");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestProxyApplyConstruct(t *testing.T) {
+	const SCRIPT = `
+	function F(x) { this.x = x; }
+	var p = new Proxy(F, {
+		apply: function(t, thisArg, args) { return args.length; },
+		construct: function(t, args, newTarget) {
+			assert.sameValue(newTarget, p, "newTarget");
+			return new t(args[0] + 1);
+		}
+	});
+	assert.sameValue(p(1, 2, 3), 3, "apply");
+	assert.sameValue(p.call(null, 1), 1, "call");
+	var o = new p(1);
+	assert.sameValue(o.x, 2, "construct");
+	assert.sameValue(o instanceof p, true, "instanceof");
+	var bad = new Proxy(F, {construct: function() { return 1; }});
+	assert.throws(TypeError, function() { new bad(); }, "construct returned a primitive");
+	assert.throws(TypeError, function() { new (new Proxy({}, {}))(); }, "not a constructor");
+
+	class Base extends new Proxy(F, {}) {}
+	assert.sameValue(new Base(5).x, 5, "extends a proxy");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestProxyInvariants(t *testing.T) {
+	const SCRIPT = `
+	var target = {};
+	Object.defineProperty(target, "fixed", {value: 1, writable: false, configurable: false});
+	var p = new Proxy(target, {
+		get: function() { return 2; },
+		has: function() { return false; },
+		deleteProperty: function() { return true; },
+		ownKeys: function() { return []; },
+		getOwnPropertyDescriptor: function() { return undefined; },
+		defineProperty: function() { return true; }
+	});
+	assert.throws(TypeError, function() { p.fixed; }, "get");
+	assert.throws(TypeError, function() { "fixed" in p; }, "has");
+	assert.throws(TypeError, function() { delete p.fixed; }, "deleteProperty");
+	assert.throws(TypeError, function() { Object.keys(p); }, "ownKeys");
+	assert.throws(TypeError, function() { Object.getOwnPropertyDescriptor(p, "fixed"); }, "getOwnPropertyDescriptor");
+	assert.throws(TypeError, function() {
+		Object.defineProperty(p, "other", {value: 1, configurable: false});
+	}, "defineProperty");
+
+	var ne = Object.preventExtensions({});
+	assert.throws(TypeError, function() {
+		Object.isExtensible(new Proxy(ne, {isExtensible: function() { return true; }}));
+	}, "isExtensible");
+	assert.throws(TypeError, function() {
+		Object.getPrototypeOf(new Proxy(ne, {getPrototypeOf: function() { return null; }}));
+	}, "getPrototypeOf");
+	assert.throws(TypeError, function() {
+		Object.preventExtensions(new Proxy({}, {preventExtensions: function() { return true; }}));
+	}, "preventExtensions");
+	assert.throws(TypeError, function() {
+		Object.keys(new Proxy({}, {ownKeys: function() { return ["a", "a"]; }}));
+	}, "duplicate keys");
+	assert.throws(TypeError, function() {
+		Object.keys(new Proxy({}, {ownKeys: function() { return [1]; }}));
+	}, "invalid key");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestProxyRevocable(t *testing.T) {
+	const SCRIPT = `
+	var r = Proxy.revocable({a: 1}, {});
+	assert.sameValue(r.proxy.a, 1, "before revoke");
+	r.revoke();
+	assert.throws(TypeError, function() { r.proxy.a; }, "get after revoke");
+	assert.throws(TypeError, function() { r.proxy.a = 1; }, "set after revoke");
+	assert.throws(TypeError, function() { Proxy({}, {}); }, "call without new");
+	assert.throws(TypeError, function() { new Proxy(1, {}); }, "non-object target");
+	assert.sameValue(Proxy.hasOwnProperty("prototype"), false, "no prototype");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestProxyAsPrototype(t *testing.T) {
+	const SCRIPT = `
+	var p = new Proxy({}, {
+		get: function(t, k, receiver) { return k === "x" ? receiver : undefined; },
+		has: function(t, k) { return k === "x"; }
+	});
+	var o = Object.create(p);
+	assert.sameValue("x" in o, true, "in");
+	assert.sameValue("y" in o, false, "in missing");
+	var keys = [];
+	for (var k in new Proxy({a: 1, b: 2}, {})) {
+		keys.push(k);
+	}
+	assert.sameValue(keys.join(), "a,b", "for-in");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestNativeProxy(t *testing.T) {
+	vm := New()
+	target := vm.NewObject()
+	target.Set("a", 1)
+	var deleted []string
+	proxy := vm.NewProxy(target, &ProxyTrapConfig{
+		Get: func(target *Object, property string, receiver Value) Value {
+			if property == "answer" {
+				return vm.ToValue(42)
+			}
+			return target.Get(property)
+		},
+		Set: func(target *Object, property string, value Value, receiver Value) bool {
+			return property != "readonly"
+		},
+		Has: func(target *Object, property string) bool {
+			return property == "answer"
+		},
+		DeleteProperty: func(target *Object, property string) bool {
+			deleted = append(deleted, property)
+			return true
+		},
+		OwnKeys: func(target *Object) *Object {
+			return vm.newArrayValues([]Value{vm.ToValue("answer")})
+		},
+	})
+	vm.Set("p", proxy)
+	v, err := vm.RunString(`
+	"use strict";
+	var res = [p.answer, p.a, "answer" in p, "a" in p, delete p.x, Object.getOwnPropertyNames(p).join()];
+	try {
+		p.readonly = 1;
+		res.push("no error");
+	} catch (e) {
+		res.push(e instanceof TypeError);
+	}
+	res.join();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := v.String(); s != "42,1,true,false,true,answer,true" {
+		t.Fatalf("Unexpected result: %s", s)
+	}
+	if len(deleted) != 1 || deleted[0] != "x" {
+		t.Fatalf("Unexpected deleted: %v", deleted)
+	}
+	if p, ok := vm.Get("p").Export().(*Proxy); !ok || p != proxy || p.Target() != target {
+		t.Fatal("Unexpected export")
+	}
+}
@@ -0,0 +1,123 @@
+package goja
+
+const classReflect = "Reflect"
+
+// Reflect.apply实现
+func (r *Runtime) reflect_apply(call FunctionCall) Value {
+	return r.toCallable(call.Argument(0))(FunctionCall{
+		This:      call.Argument(1),
+		Arguments: r.toValueArray(call.Argument(2)),
+	})
+}
+// 检查值是否为构造函数
+func (r *Runtime) toConstructor(v Value) *Object {
+	if o, ok := v.(*Object); ok && r.isConstructor(o) {
+		return o
+	}
+	r.typeErrorResult(true, "%s is not a constructor", v.String())
+	return nil
+}
+// Reflect.construct实现，newTarget默认为target
+func (r *Runtime) reflect_construct(call FunctionCall) Value {
+	target := r.toConstructor(call.Argument(0))
+	newTarget := target
+	if len(call.Arguments) > 2 {
+		newTarget = r.toConstructor(call.Arguments[2])
+	}
+	return r.constructWith(target, r.toValueArray(call.Argument(1)), newTarget)
+}
+// Reflect.defineProperty实现
+func (r *Runtime) reflect_defineProperty(call FunctionCall) Value {
+	target := r.toObject(call.Argument(0), "Reflect.defineProperty called on non-object")
+	key := toPropertyKey(call.Argument(1))
+	return r.toBoolean(target.self.defineOwnProperty(key, r.toPropertyDescr(call.Argument(2)), false))
+}
+// Reflect.deleteProperty实现
+func (r *Runtime) reflect_deleteProperty(call FunctionCall) Value {
+	target := r.toObject(call.Argument(0), "Reflect.deleteProperty called on non-object")
+	return r.toBoolean(target.self.delete(toPropertyKey(call.Argument(1)), false))
+}
+// Reflect.get实现，receiver默认为target
+func (r *Runtime) reflect_get(call FunctionCall) Value {
+	target := r.toObject(call.Argument(0), "Reflect.get called on non-object")
+	var receiver Value = target
+	if len(call.Arguments) > 2 {
+		receiver = call.Arguments[2]
+	}
+	return r.getWithReceiver(target, toPropertyKey(call.Argument(1)), receiver)
+}
+// Reflect.getOwnPropertyDescriptor实现
+func (r *Runtime) reflect_getOwnPropertyDescriptor(call FunctionCall) Value {
+	target := r.toObject(call.Argument(0), "Reflect.getOwnPropertyDescriptor called on non-object")
+	return r.valuePropToDescriptorObject(getOwnPropValue(target, toPropertyKey(call.Argument(1))))
+}
+// Reflect.getPrototypeOf实现
+func (r *Runtime) reflect_getPrototypeOf(call FunctionCall) Value {
+	target := r.toObject(call.Argument(0), "Reflect.getPrototypeOf called on non-object")
+	if p := target.self.proto(); p != nil {
+		return p
+	}
+	return _null
+}
+// Reflect.has实现
+func (r *Runtime) reflect_has(call FunctionCall) Value {
+	target := r.toObject(call.Argument(0), "Reflect.has called on non-object")
+	return r.toBoolean(target.self.hasProperty(toPropertyKey(call.Argument(1))))
+}
+// Reflect.isExtensible实现
+func (r *Runtime) reflect_isExtensible(call FunctionCall) Value {
+	target := r.toObject(call.Argument(0), "Reflect.isExtensible called on non-object")
+	return r.toBoolean(target.self.isExtensible())
+}
+// Reflect.ownKeys实现
+func (r *Runtime) reflect_ownKeys(call FunctionCall) Value {
+	target := r.toObject(call.Argument(0), "Reflect.ownKeys called on non-object")
+	return r.newArrayValues(ownKeys(target))
+}
+// Reflect.preventExtensions实现
+func (r *Runtime) reflect_preventExtensions(call FunctionCall) Value {
+	target := r.toObject(call.Argument(0), "Reflect.preventExtensions called on non-object")
+	return r.toBoolean(target.self.preventExtensions(false))
+}
+// Reflect.set实现，receiver默认为target
+func (r *Runtime) reflect_set(call FunctionCall) Value {
+	target := r.toObject(call.Argument(0), "Reflect.set called on non-object")
+	var receiver Value = target
+	if len(call.Arguments) > 3 {
+		receiver = call.Arguments[3]
+	}
+	return r.toBoolean(r.setWithReceiver(target, toPropertyKey(call.Argument(1)), call.Argument(2), receiver))
+}
+// Reflect.setPrototypeOf实现
+func (r *Runtime) reflect_setPrototypeOf(call FunctionCall) Value {
+	target := r.toObject(call.Argument(0), "Reflect.setPrototypeOf called on non-object")
+	var proto *Object
+	if arg := call.Argument(1); arg != _null {
+		if o, ok := arg.(*Object); ok {
+			proto = o
+		} else {
+			r.typeErrorResult(true, "Object prototype may only be an Object or null: %s", arg.String())
+		}
+	}
+	return r.toBoolean(target.self.setProto(proto, false))
+}
+// Reflect对象实现
+func (r *Runtime) initReflect() {
+	o := r.newBaseObject(r.global.ObjectPrototype, classObject)
+	o._putProp("apply", r.newNativeFunc(r.reflect_apply, nil, "apply", nil, 3), true, false, true)
+	o._putProp("construct", r.newNativeFunc(r.reflect_construct, nil, "construct", nil, 2), true, false, true)
+	o._putProp("defineProperty", r.newNativeFunc(r.reflect_defineProperty, nil, "defineProperty", nil, 3), true, false, true)
+	o._putProp("deleteProperty", r.newNativeFunc(r.reflect_deleteProperty, nil, "deleteProperty", nil, 2), true, false, true)
+	o._putProp("get", r.newNativeFunc(r.reflect_get, nil, "get", nil, 2), true, false, true)
+	o._putProp("getOwnPropertyDescriptor", r.newNativeFunc(r.reflect_getOwnPropertyDescriptor, nil, "getOwnPropertyDescriptor", nil, 2), true, false, true)
+	o._putProp("getPrototypeOf", r.newNativeFunc(r.reflect_getPrototypeOf, nil, "getPrototypeOf", nil, 1), true, false, true)
+	o._putProp("has", r.newNativeFunc(r.reflect_has, nil, "has", nil, 2), true, false, true)
+	o._putProp("isExtensible", r.newNativeFunc(r.reflect_isExtensible, nil, "isExtensible", nil, 1), true, false, true)
+	o._putProp("ownKeys", r.newNativeFunc(r.reflect_ownKeys, nil, "ownKeys", nil, 1), true, false, true)
+	o._putProp("preventExtensions", r.newNativeFunc(r.reflect_preventExtensions, nil, "preventExtensions", nil, 1), true, false, true)
+	o._putProp("set", r.newNativeFunc(r.reflect_set, nil, "set", nil, 3), true, false, true)
+	o._putProp("setPrototypeOf", r.newNativeFunc(r.reflect_setPrototypeOf, nil, "setPrototypeOf", nil, 2), true, false, true)
+	o._putSym(symToStringTag, asciiString(classReflect), false, false, true)
+
+	r.addToGlobal("Reflect", o.val)
+}
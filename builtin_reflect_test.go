@@ -0,0 +1,74 @@
+package goja
+
+import "testing"
+
+func TestReflect(t *testing.T) {
+	const SCRIPT = `
+	var o = {a: 1};
+	assert.sameValue(Reflect.get(o, "a"), 1, "get");
+	var getterObj = {get x() { return this.v; }};
+	assert.sameValue(Reflect.get(getterObj, "x", {v: 2}), 2, "get with receiver");
+	assert.sameValue(Reflect.set(o, "b", 2), true, "set");
+	assert.sameValue(o.b, 2, "set value");
+	var receiver = {};
+	assert.sameValue(Reflect.set(o, "c", 3, receiver), true, "set with receiver");
+	assert.sameValue(receiver.c, 3, "set defines on receiver");
+	assert.sameValue(o.hasOwnProperty("c"), false, "set does not define on target");
+	Object.defineProperty(o, "ro", {value: 1, writable: false});
+	assert.sameValue(Reflect.set(o, "ro", 2), false, "set read-only");
+	assert.sameValue(Reflect.has(o, "a"), true, "has");
+	assert.sameValue(Reflect.has(o, "toString"), true, "has inherited");
+	assert.sameValue(Reflect.deleteProperty(o, "a"), true, "deleteProperty");
+	assert.sameValue(Reflect.deleteProperty(o, "ro"), false, "deleteProperty non-configurable");
+	assert.sameValue(Reflect.defineProperty(o, "d", {value: 4}), true, "defineProperty");
+	assert.sameValue(Reflect.defineProperty(o, "d", {value: 5}), false, "defineProperty non-writable");
+	assert.sameValue(Reflect.getOwnPropertyDescriptor(o, "d").value, 4, "getOwnPropertyDescriptor");
+	assert.sameValue(Reflect.getOwnPropertyDescriptor(o, "none"), undefined, "getOwnPropertyDescriptor missing");
+	var s = Symbol("s");
+	var k = {x: 1};
+	k[s] = 2;
+	var keys = Reflect.ownKeys(k);
+	assert.sameValue(keys.length, 2, "ownKeys length");
+	assert.sameValue(keys[0], "x", "ownKeys string");
+	assert.sameValue(keys[1], s, "ownKeys symbol");
+	assert.sameValue(Reflect.getPrototypeOf(o), Object.prototype, "getPrototypeOf");
+	var proto = {};
+	assert.sameValue(Reflect.setPrototypeOf(o, proto), true, "setPrototypeOf");
+	assert.sameValue(Object.getPrototypeOf(o), proto, "setPrototypeOf result");
+	assert.sameValue(Reflect.setPrototypeOf(proto, o), false, "setPrototypeOf cycle");
+	assert.sameValue(Reflect.isExtensible(o), true, "isExtensible");
+	assert.sameValue(Reflect.preventExtensions(o), true, "preventExtensions");
+	assert.sameValue(Reflect.isExtensible(o), false, "isExtensible after preventExtensions");
+	assert.sameValue(Reflect.setPrototypeOf(o, {}), false, "setPrototypeOf non-extensible");
+	assert.throws(TypeError, function() { Reflect.get(1, "a"); }, "non-object target");
+	assert.sameValue(Object.prototype.toString.call(Reflect), "[object Reflect]", "toStringTag");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestReflectApplyConstruct(t *testing.T) {
+	const SCRIPT = `
+	function sum() {
+		var s = this.base;
+		for (var i = 0; i < arguments.length; i++) {
+			s += arguments[i];
+		}
+		return s;
+	}
+	assert.sameValue(Reflect.apply(sum, {base: 1}, [2, 3]), 6, "apply");
+	assert.throws(TypeError, function() { Reflect.apply(sum, null); }, "apply without arguments list");
+
+	function A(x) { this.x = x; }
+	function B() {}
+	var a = Reflect.construct(A, [1]);
+	assert.sameValue(a.x, 1, "construct");
+	assert.sameValue(Object.getPrototypeOf(a), A.prototype, "construct prototype");
+	var b = Reflect.construct(A, [2], B);
+	assert.sameValue(b.x, 2, "construct with newTarget");
+	assert.sameValue(Object.getPrototypeOf(b), B.prototype, "newTarget prototype");
+	var d = Reflect.construct(Date, [0], B);
+	assert.sameValue(Object.getPrototypeOf(d), B.prototype, "builtin with newTarget");
+	assert.throws(TypeError, function() { Reflect.construct(function() {}.bind(), [], Math.max); }, "newTarget not a constructor");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
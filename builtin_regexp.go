@@ -1,10 +1,13 @@
 package goja
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/dlclark/regexp2"
 	"github.com/oracle3/goja/parser"
+	"math"
 	"regexp"
+	"strings"
 )
 // 创建一个正则表达式对象
 func (r *Runtime) newRegexpObject(proto *Object) *regexpObject {
@@ -20,58 +23,56 @@ func (r *Runtime) newRegexpObject(proto *Object) *regexpObject {
 	return o
 }
 // 创建一个正则表达式对象
-func (r *Runtime) newRegExpp(pattern regexpPattern, patternStr valueString, global, ignoreCase, multiline bool, proto *Object) *Object {
+func (r *Runtime) newRegExpp(pattern regexpPattern, patternStr valueString, flags regexpFlags, proto *Object) *Object {
 	o := r.newRegexpObject(proto)
 
 	o.pattern = pattern
 	o.source = patternStr
-	o.global = global
-	o.ignoreCase = ignoreCase
-	o.multiline = multiline
+	o.regexpFlags = flags
 
 	return o.val
 }
-// 编译正则表达式
-func compileRegexp(patternStr, flags string) (p regexpPattern, global, ignoreCase, multiline bool, err error) {
-
-	if flags != "" {
-		invalidFlags := func() {
-			err = fmt.Errorf("Invalid flags supplied to RegExp constructor '%s'", flags)
+// 解析正则表达式标志，重复或未知的标志返回错误
+func parseRegexpFlags(flags string) (f regexpFlags, err error) {
+	for _, chr := range flags {
+		var flag *bool
+		switch chr {
+		case 'g':
+			flag = &f.global
+		case 'm':
+			flag = &f.multiline
+		case 'i':
+			flag = &f.ignoreCase
+		case 's':
+			flag = &f.dotAll
+		case 'y':
+			flag = &f.sticky
+		case 'u':
+			flag = &f.unicode
 		}
-		for _, chr := range flags {
-			switch chr {
-			case 'g':
-				if global {
-					invalidFlags()
-					return
-				}
-				global = true
-			case 'm':
-				if multiline {
-					invalidFlags()
-					return
-				}
-				multiline = true
-			case 'i':
-				if ignoreCase {
-					invalidFlags()
-					return
-				}
-				ignoreCase = true
-			default:
-				invalidFlags()
-				return
-			}
+		if flag == nil || *flag {
+			err = fmt.Errorf("Invalid flags supplied to RegExp constructor '%s'", flags)
+			return
 		}
+		*flag = true
+	}
+	return
+}
+// 编译正则表达式
+func compileRegexp(patternStr, flagsStr string) (p regexpPattern, flags regexpFlags, err error) {
+	flags, err = parseRegexpFlags(flagsStr)
+	if err != nil {
+		return
 	}
 
-	re2Str, err1 := parser.TransformRegExp(patternStr)
-	if /*false &&*/ err1 == nil {
+	re2Str, err1 := parser.TransformRegExpFlags(patternStr, flags.dotAll, flags.unicode)
+	// re2只能从lastIndex处截断匹配，会丢失^和\b所需的上下文，sticky模式使用regexp2
+	if /*false &&*/ err1 == nil && !flags.sticky {
 		re2flags := ""
-		if multiline {
+		if flags.multiline {
 			re2flags += "m"
 		}
-		if ignoreCase {
+		if flags.ignoreCase {
 			re2flags += "i"
 		}
 		if len(re2flags) > 0 {
@@ -87,28 +88,29 @@ func compileRegexp(patternStr, flags string) (p regexpPattern, global, ignoreCas
 		p = (*regexpWrapper)(pattern)
 	} else {
 		var opts regexp2.RegexOptions = regexp2.ECMAScript
-		if multiline {
+		if flags.multiline {
 			opts |= regexp2.Multiline
 		}
-		if ignoreCase {
+		if flags.ignoreCase {
 			opts |= regexp2.IgnoreCase
 		}
-		regexp2Pattern, err1 := regexp2.Compile(patternStr, opts)
+		regexp2Str, names := parser.TransformRegExp2(patternStr, flags.dotAll, flags.unicode)
+		regexp2Pattern, err1 := regexp2.Compile(regexp2Str, opts)
 		if err1 != nil {
 			err = fmt.Errorf("Invalid regular expression (regexp2): %s (%v)", patternStr, err1)
 			return
 		}
-		p = (*regexp2Wrapper)(regexp2Pattern)
+		p = &regexp2Wrapper{rx: regexp2Pattern, names: names}
 	}
 	return
 }
 
 func (r *Runtime) newRegExp(patternStr valueString, flags string, proto *Object) *Object {
-	pattern, global, ignoreCase, multiline, err := compileRegexp(patternStr.String(), flags)
+	pattern, f, err := compileRegexp(patternStr.String(), flags)
 	if err != nil {
 		panic(r.newSyntaxError(err.Error(), -1))
 	}
-	return r.newRegExpp(pattern, patternStr, global, ignoreCase, multiline, proto)
+	return r.newRegExpp(pattern, patternStr, f, proto)
 }
 
 func (r *Runtime) builtin_newRegExp(args []Value) *Object {
@@ -175,82 +177,557 @@ func (r *Runtime) regexpproto_test(call FunctionCall) Value {
 	}
 }
 //RegExp.prototype.toString()
-//toString() 返回一个表示该正则表达式的字符串。
+//toString() 返回一个表示该正则表达式的字符串。由this的source和flags属性拼接而成，对任意对象都适用
 func (r *Runtime) regexpproto_toString(call FunctionCall) Value {
-	if this, ok := r.toObject(call.This).self.(*regexpObject); ok {
-		var g, i, m string
-		if this.global {
-			g = "g"
-		}
-		if this.ignoreCase {
-			i = "i"
-		}
-		if this.multiline {
-			m = "m"
-		}
-		return newStringValue(fmt.Sprintf("/%s/%s%s%s", this.source.String(), g, i, m))
+	if this, ok := call.This.(*Object); ok {
+		source := nilSafe(this.self.getStr("source"))
+		flags := nilSafe(this.self.getStr("flags"))
+		return newStringValue(fmt.Sprintf("/%s/%s", source.String(), flags.String()))
 	} else {
-		r.typeErrorResult(true, "Method RegExp.prototype.toString called on incompatible receiver %s", call.This)
+		r.typeErrorResult(true, "Method RegExp.prototype.toString called on non-object")
 		return nil
 	}
 }
 //RegExp.prototype.source
 //source 属性返回一个值为当前正则表达式对象的模式文本的字符串，该字符串不会包含正则字面量两边的斜杠以及任何的标志字符。
 func (r *Runtime) regexpproto_getSource(call FunctionCall) Value {
-	if this, ok := r.toObject(call.This).self.(*regexpObject); ok {
-		return this.source
-	} else {
-		r.typeErrorResult(true, "Method RegExp.prototype.source getter called on incompatible receiver %s", call.This.ToString())
-		return nil
+	if this, ok := call.This.(*Object); ok {
+		if regexp, ok := this.self.(*regexpObject); ok {
+			return regexp.source
+		}
+		if this == r.global.RegExpPrototype {
+			return asciiString("(?:)")
+		}
+	}
+	r.typeErrorResult(true, "Method RegExp.prototype.source getter called on incompatible receiver")
+	return nil
+}
+// 标志属性getter的公共部分：this为RegExp.prototype时返回undefined，不是正则表达式对象时抛出TypeError。
+// 错误信息里不对this做字符串转换，否则会经由toString再次调用这些getter
+func (r *Runtime) regexpproto_getFlag(call FunctionCall, name string, flag func(*regexpObject) bool) Value {
+	if this, ok := call.This.(*Object); ok {
+		if regexp, ok := this.self.(*regexpObject); ok {
+			return r.toBoolean(flag(regexp))
+		}
+		if this == r.global.RegExpPrototype {
+			return _undefined
+		}
 	}
+	r.typeErrorResult(true, "Method RegExp.prototype.%s getter called on incompatible receiver", name)
+	return nil
 }
 //RegExp.prototype.global
 //global 属性表明正则表达式是否使用了 "g" 标志。global 是一个正则表达式实例的只读属性。
 func (r *Runtime) regexpproto_getGlobal(call FunctionCall) Value {
-	if this, ok := r.toObject(call.This).self.(*regexpObject); ok {
-		if this.global {
-			return valueTrue
-		} else {
-			return valueFalse
-		}
-	} else {
-		r.typeErrorResult(true, "Method RegExp.prototype.global getter called on incompatible receiver %s", call.This.ToString())
-		return nil
-	}
+	return r.regexpproto_getFlag(call, "global", func(this *regexpObject) bool {
+		return this.global
+	})
 }
 //RegExp.prototype.multiline
 //multiline 属性表明正则表达式是否使用了 "m" 标志。multiline 是正则表达式实例的一个只读属性。
 func (r *Runtime) regexpproto_getMultiline(call FunctionCall) Value {
-	if this, ok := r.toObject(call.This).self.(*regexpObject); ok {
-		if this.multiline {
-			return valueTrue
-		} else {
-			return valueFalse
-		}
-	} else {
-		r.typeErrorResult(true, "Method RegExp.prototype.multiline getter called on incompatible receiver %s", call.This.ToString())
-		return nil
-	}
+	return r.regexpproto_getFlag(call, "multiline", func(this *regexpObject) bool {
+		return this.multiline
+	})
 }
 //RegExp.prototype.ignoreCase
 //ignoreCase 属性表明正则表达式是否使用了 "i" 标志。ignoreCase 是正则表达式实例的只读属性。
 func (r *Runtime) regexpproto_getIgnoreCase(call FunctionCall) Value {
-	if this, ok := r.toObject(call.This).self.(*regexpObject); ok {
-		if this.ignoreCase {
-			return valueTrue
+	return r.regexpproto_getFlag(call, "ignoreCase", func(this *regexpObject) bool {
+		return this.ignoreCase
+	})
+}
+//RegExp.prototype.flags
+//flags 属性返回一个字符串，按"gimsuy"的顺序读取this上对应的标志属性拼接而成，对任意对象都适用。
+func (r *Runtime) regexpproto_getFlags(call FunctionCall) Value {
+	this, ok := call.This.(*Object)
+	if !ok {
+		r.typeErrorResult(true, "Method RegExp.prototype.flags getter called on non-object")
+		return nil
+	}
+	var flags []byte
+	for _, f := range []struct {
+		name string
+		char byte
+	}{
+		{"global", 'g'},
+		{"ignoreCase", 'i'},
+		{"multiline", 'm'},
+		{"dotAll", 's'},
+		{"unicode", 'u'},
+		{"sticky", 'y'},
+	} {
+		if nilSafe(this.self.getStr(f.name)).ToBoolean() {
+			flags = append(flags, f.char)
+		}
+	}
+	return asciiString(flags)
+}
+//RegExp.prototype.dotAll
+//dotAll 属性表明正则表达式是否使用了 "s" 标志。
+func (r *Runtime) regexpproto_getDotAll(call FunctionCall) Value {
+	return r.regexpproto_getFlag(call, "dotAll", func(this *regexpObject) bool {
+		return this.dotAll
+	})
+}
+//RegExp.prototype.sticky
+//sticky 属性表明正则表达式是否使用了 "y" 标志，即仅从lastIndex处开始匹配。
+func (r *Runtime) regexpproto_getSticky(call FunctionCall) Value {
+	return r.regexpproto_getFlag(call, "sticky", func(this *regexpObject) bool {
+		return this.sticky
+	})
+}
+//RegExp.prototype.unicode
+//unicode 属性表明正则表达式是否使用了 "u" 标志。
+func (r *Runtime) regexpproto_getUnicode(call FunctionCall) Value {
+	return r.regexpproto_getFlag(call, "unicode", func(this *regexpObject) bool {
+		return this.unicode
+	})
+}
+// IsRegExp(v)：优先根据v[Symbol.match]判断
+func (r *Runtime) isRegExp(v Value) bool {
+	o, ok := v.(*Object)
+	if !ok {
+		return false
+	}
+	if m := nilSafe(o.self.get(symMatch)); m != _undefined {
+		return m.ToBoolean()
+	}
+	_, ok = o.self.(*regexpObject)
+	return ok
+}
+// 判断o是否为未修改exec的正则表达式对象，是则可以直接使用内部实现
+func (r *Runtime) standardRegexp(o *Object) *regexpObject {
+	if rx, ok := o.self.(*regexpObject); ok {
+		if exec, ok := o.self.getStr("exec").(*Object); ok && exec == r.global.regexpProtoExec {
+			return rx
+		}
+	}
+	return nil
+}
+// RegExpExec(R, S)：优先调用R上可调用的exec方法
+func (r *Runtime) regExpExec(rx *Object, s valueString) Value {
+	if exec, ok := rx.self.getStr("exec").(*Object); ok {
+		if call, ok := exec.self.assertCallable(); ok {
+			result := call(FunctionCall{This: rx, Arguments: []Value{s}})
+			if _, ok := result.(*Object); !ok && result != _null {
+				r.typeErrorResult(true, "exec result must be an object or null")
+			}
+			return result
+		}
+	}
+	if this, ok := rx.self.(*regexpObject); ok {
+		return this.exec(s)
+	}
+	r.typeErrorResult(true, "Method RegExp.prototype.exec called on incompatible receiver %s", rx.String())
+	return nil
+}
+// AdvanceStringIndex：unicode模式下跳过整个代理对
+func advanceStringIndex(s valueString, index int64, unicode bool) int64 {
+	next := index + 1
+	if !unicode || next >= s.length() {
+		return next
+	}
+	if c := s.charAt(index); c >= 0xD800 && c < 0xDC00 {
+		if c1 := s.charAt(next); c1 >= 0xDC00 && c1 < 0xE000 {
+			next++
+		}
+	}
+	return next
+}
+// 空匹配时将lastIndex向前推进，避免死循环
+func (r *Runtime) advanceLastIndex(rx *Object, s valueString, unicode bool) {
+	thisIndex := toLength(rx.self.getStr("lastIndex"))
+	rx.self.putStr("lastIndex", intToValue(advanceStringIndex(s, thisIndex, unicode)), true)
+}
+// 读取flags属性并判断是否包含标志flag
+func (r *Runtime) regexpHasFlag(rx *Object, flag rune) bool {
+	return strings.ContainsRune(nilSafe(rx.self.getStr("flags")).String(), flag)
+}
+//RegExp.prototype[@@match]()
+//String.prototype.match()通过该方法执行匹配，全局模式返回所有匹配的字符串。
+func (r *Runtime) regexpproto_stdMatch(call FunctionCall) Value {
+	rxObj := r.toObject(call.This)
+	s := call.Argument(0).ToString()
+	rx := r.standardRegexp(rxObj)
+	if !nilSafe(rxObj.self.getStr("global")).ToBoolean() {
+		if rx != nil {
+			return rx.exec(s)
+		}
+		return r.regExpExec(rxObj, s)
+	}
+	unicode := nilSafe(rxObj.self.getStr("unicode")).ToBoolean()
+	rxObj.self.putStr("lastIndex", intToValue(0), true)
+	var a []Value
+	for {
+		var matchStr valueString
+		if rx != nil {
+			match, result := rx.execRegexp(s)
+			if !match {
+				break
+			}
+			matchStr = s.substring(int64(result[0]), int64(result[1]))
 		} else {
-			return valueFalse
+			res := r.regExpExec(rxObj, s)
+			if res == _null {
+				break
+			}
+			matchStr = nilSafe(r.toObject(res).self.getStr("0")).ToString()
+		}
+		a = append(a, matchStr)
+		if matchStr.length() == 0 {
+			r.advanceLastIndex(rxObj, s, unicode)
+		}
+	}
+	if len(a) == 0 {
+		return _null
+	}
+	return r.newArrayValues(a)
+}
+//RegExp.prototype[@@matchAll]()
+//返回一个迭代器，依次产生字符串中所有匹配的结果。
+func (r *Runtime) regexpproto_stdMatchAll(call FunctionCall) Value {
+	rxObj := r.toObject(call.This)
+	s := call.Argument(0).ToString()
+	flags := nilSafe(rxObj.self.getStr("flags")).ToString()
+	matcher := r.constructWith(r.regexpSpeciesConstructor(rxObj), []Value{rxObj, flags}, r.regexpSpeciesConstructor(rxObj))
+	matcher.self.putStr("lastIndex", intToValue(toLength(rxObj.self.getStr("lastIndex"))), true)
+	f := flags.String()
+	return r.createRegExpStringIterator(matcher, s, strings.ContainsRune(f, 'g'), strings.ContainsRune(f, 'u'))
+}
+// 返回用于创建新正则表达式的构造函数，默认为RegExp
+func (r *Runtime) regexpSpeciesConstructor(rx *Object) *Object {
+	if c, ok := rx.self.getStr("constructor").(*Object); ok && r.isConstructor(c) {
+		return c
+	}
+	return r.global.RegExp
+}
+// 一次匹配的结果，用于生成替换字符串
+type regexpReplaceMatch struct {
+	matched  valueString
+	position int64
+	captures []Value
+	groups   Value
+}
+// 将匹配结果数组转换为regexpReplaceMatch
+func (r *Runtime) toReplaceMatch(s valueString, res *Object) regexpReplaceMatch {
+	m := regexpReplaceMatch{
+		matched: nilSafe(res.self.getStr("0")).ToString(),
+	}
+	nCaptures := toLength(res.self.getStr("length")) - 1
+	if nCaptures < 0 {
+		nCaptures = 0
+	}
+	m.position = nilSafe(res.self.getStr("index")).ToInteger()
+	if m.position < 0 {
+		m.position = 0
+	} else if m.position > s.length() {
+		m.position = s.length()
+	}
+	m.captures = make([]Value, nCaptures)
+	for i := int64(1); i <= nCaptures; i++ {
+		c := nilSafe(res.self.get(intToValue(i)))
+		if c != _undefined {
+			c = c.ToString()
+		}
+		m.captures[i-1] = c
+	}
+	m.groups = nilSafe(res.self.getStr("groups"))
+	return m
+}
+// 根据内部匹配位置生成regexpReplaceMatch
+func (rx *regexpObject) toReplaceMatch(s valueString, result []int) regexpReplaceMatch {
+	m := regexpReplaceMatch{
+		matched:  s.substring(int64(result[0]), int64(result[1])),
+		position: int64(result[0]),
+		captures: make([]Value, len(result)/2-1),
+		groups:   _undefined,
+	}
+	for i := range m.captures {
+		if offset := (i + 1) * 2; result[offset] >= 0 {
+			m.captures[i] = s.substring(int64(result[offset]), int64(result[offset+1]))
+		} else {
+			m.captures[i] = _undefined
+		}
+	}
+	if names := rx.pattern.groupNames(); names != nil {
+		o := rx.val.runtime.newBaseObject(nil, classObject)
+		for i, name := range names {
+			if name != "" && i > 0 && i <= len(m.captures) {
+				o._putProp(name, m.captures[i-1], true, true, true)
+			}
+		}
+		m.groups = o.val
+	}
+	return m
+}
+//RegExp.prototype[@@replace]()
+//String.prototype.replace()和replaceAll()通过该方法执行替换，替换值可以是字符串模板或回调函数。
+func (r *Runtime) regexpproto_stdReplace(call FunctionCall) Value {
+	rxObj := r.toObject(call.This)
+	s := call.Argument(0).ToString()
+	replaceValue := call.Argument(1)
+	replaceStr := toReplaceString(replaceValue)
+	global := nilSafe(rxObj.self.getStr("global")).ToBoolean()
+
+	var matches []regexpReplaceMatch
+	if rx := r.standardRegexp(rxObj); rx != nil && !rx.sticky {
+		r.useGas(s.length())
+		find := 1
+		if global {
+			find = -1
+			rxObj.self.putStr("lastIndex", intToValue(0), true)
+		}
+		for _, result := range rx.pattern.FindAllSubmatchIndex(s, find) {
+			matches = append(matches, rx.toReplaceMatch(s, result))
 		}
 	} else {
-		r.typeErrorResult(true, "Method RegExp.prototype.ignoreCase getter called on incompatible receiver %s", call.This.ToString())
-		return nil
+		var unicode bool
+		if global {
+			unicode = nilSafe(rxObj.self.getStr("unicode")).ToBoolean()
+			rxObj.self.putStr("lastIndex", intToValue(0), true)
+		}
+		for {
+			res := r.regExpExec(rxObj, s)
+			if res == _null {
+				break
+			}
+			m := r.toReplaceMatch(s, r.toObject(res))
+			matches = append(matches, m)
+			if !global {
+				break
+			}
+			if m.matched.length() == 0 {
+				r.advanceLastIndex(rxObj, s, unicode)
+			}
+		}
+	}
+	return r.applyReplaceMatches(s, matches, replaceValue, replaceStr)
+}
+// 替换值不可调用时转换为替换模板，否则返回nil
+func toReplaceString(replaceValue Value) valueString {
+	if o, ok := replaceValue.(*Object); ok {
+		if _, ok := o.self.assertCallable(); ok {
+			return nil
+		}
+	}
+	return replaceValue.ToString()
+}
+// 用匹配结果替换字符串s中的内容，replaceStr为nil时replaceValue为回调函数
+func (r *Runtime) applyReplaceMatches(s valueString, matches []regexpReplaceMatch, replaceValue Value, replaceStr valueString) Value {
+	if len(matches) == 0 {
+		return s
+	}
+	var buf bytes.Buffer
+	var template string
+	var rcall func(FunctionCall) Value
+	if replaceStr != nil {
+		template = replaceStr.String()
+	} else {
+		rcall = r.toCallable(replaceValue)
+	}
+	nextSourcePosition := int64(0)
+	allocated := 0
+	for i := range matches {
+		m := &matches[i]
+		var replacement string
+		if rcall != nil {
+			args := make([]Value, 0, len(m.captures)+4)
+			args = append(args, m.matched)
+			args = append(args, m.captures...)
+			args = append(args, intToValue(m.position), s)
+			if m.groups != _undefined {
+				args = append(args, m.groups)
+			}
+			replacement = rcall(FunctionCall{This: _undefined, Arguments: args}).String()
+		} else {
+			if m.groups != _undefined {
+				m.groups = r.toObject(m.groups)
+			}
+			var sub bytes.Buffer
+			r.getSubstitution(&sub, s, m, template)
+			replacement = sub.String()
+		}
+		r.useGas(int64(len(replacement)) + 1)
+		if m.position >= nextSourcePosition {
+			buf.WriteString(s.substring(nextSourcePosition, m.position).String())
+			buf.WriteString(replacement)
+			nextSourcePosition = m.position + m.matched.length()
+		}
+		// 结果随着替换不断增长，按已经写入的长度计入内存使用
+		r.allocate(buf.Len() - allocated)
+		allocated = buf.Len()
+	}
+	if nextSourcePosition < s.length() {
+		buf.WriteString(s.substring(nextSourcePosition, s.length()).String())
+	}
+	r.allocateString(int64(buf.Len() - allocated))
+	return newStringValue(buf.String())
+}
+//RegExp.prototype[@@search]()
+//String.prototype.search()通过该方法执行搜索，返回第一个匹配的位置，不改变lastIndex。
+func (r *Runtime) regexpproto_stdSearch(call FunctionCall) Value {
+	rxObj := r.toObject(call.This)
+	s := call.Argument(0).ToString()
+	if rx := r.standardRegexp(rxObj); rx != nil {
+		result := rx.pattern.FindSubmatchIndex(s, 0)
+		if result == nil || rx.sticky && result[0] != 0 {
+			return intToValue(-1)
+		}
+		return intToValue(int64(result[0]))
+	}
+	previousLastIndex := nilSafe(rxObj.self.getStr("lastIndex"))
+	if !previousLastIndex.SameAs(intToValue(0)) {
+		rxObj.self.putStr("lastIndex", intToValue(0), true)
+	}
+	res := r.regExpExec(rxObj, s)
+	if !nilSafe(rxObj.self.getStr("lastIndex")).SameAs(previousLastIndex) {
+		rxObj.self.putStr("lastIndex", previousLastIndex, true)
+	}
+	if res == _null {
+		return intToValue(-1)
+	}
+	return nilSafe(r.toObject(res).self.getStr("index"))
+}
+//RegExp.prototype[@@split]()
+//String.prototype.split()通过该方法用正则表达式分割字符串。
+func (r *Runtime) regexpproto_stdSplit(call FunctionCall) Value {
+	rxObj := r.toObject(call.This)
+	s := call.Argument(0).ToString()
+	limitValue := call.Argument(1)
+	if rx := r.standardRegexp(rxObj); rx != nil {
+		limit := -1
+		if limitValue != _undefined {
+			limit = int(toUInt32(limitValue))
+		}
+		if limit == 0 {
+			return r.newArrayValues(nil)
+		}
+		return r.regexpSplit(s, rx, limit)
+	}
+
+	c := r.regexpSpeciesConstructor(rxObj)
+	flags := nilSafe(rxObj.self.getStr("flags")).String()
+	unicode := strings.ContainsRune(flags, 'u')
+	if !strings.ContainsRune(flags, 'y') {
+		flags += "y"
+	}
+	splitter := r.constructWith(c, []Value{rxObj, newStringValue(flags)}, c)
+	lim := int64(math.MaxUint32)
+	if limitValue != _undefined {
+		lim = int64(toUInt32(limitValue))
+	}
+	var a []Value
+	if lim == 0 {
+		return r.newArrayValues(a)
+	}
+	size := s.length()
+	if size == 0 {
+		if r.regExpExec(splitter, s) != _null {
+			return r.newArrayValues(a)
+		}
+		return r.newArrayValues([]Value{s})
+	}
+	p, q := int64(0), int64(0)
+	for q < size {
+		splitter.self.putStr("lastIndex", intToValue(q), true)
+		z := r.regExpExec(splitter, s)
+		if z == _null {
+			q = advanceStringIndex(s, q, unicode)
+			continue
+		}
+		e := toLength(splitter.self.getStr("lastIndex"))
+		if e > size {
+			e = size
+		}
+		if e == p {
+			q = advanceStringIndex(s, q, unicode)
+			continue
+		}
+		a = append(a, s.substring(p, q))
+		if int64(len(a)) == lim {
+			return r.newArrayValues(a)
+		}
+		p = e
+		zObj := r.toObject(z)
+		nCaptures := toLength(zObj.self.getStr("length")) - 1
+		for i := int64(1); i <= nCaptures; i++ {
+			a = append(a, nilSafe(zObj.self.get(intToValue(i))))
+			if int64(len(a)) == lim {
+				return r.newArrayValues(a)
+			}
+		}
+		q = p
+	}
+	a = append(a, s.substring(p, size))
+	return r.newArrayValues(a)
+}
+// 使用内部实现按正则表达式分割字符串，limit为-1时不限制数量
+func (r *Runtime) regexpSplit(s valueString, search *regexpObject, limit int) Value {
+	targetLength := s.length()
+	valueArray := []Value{}
+	r.useGas(targetLength)
+	result := search.pattern.FindAllSubmatchIndex(s, -1)
+	lastIndex := 0
+	found := 0
+
+	for _, match := range result {
+		if match[0] == match[1] {
+			// FIXME Ugh, this is a hack
+			if match[0] == 0 || int64(match[0]) == targetLength {
+				continue
+			}
+		}
+
+		if lastIndex != match[0] {
+			valueArray = append(valueArray, s.substring(int64(lastIndex), int64(match[0])))
+			found++
+		} else if lastIndex == match[0] {
+			if lastIndex != -1 {
+				valueArray = append(valueArray, stringEmpty)
+				found++
+			}
+		}
+
+		lastIndex = match[1]
+		if found == limit {
+			goto RETURN
+		}
+
+		captureCount := len(match) / 2
+		for index := 1; index < captureCount; index++ {
+			offset := index * 2
+			var value Value
+			if match[offset] != -1 {
+				value = s.substring(int64(match[offset]), int64(match[offset+1]))
+			} else {
+				value = _undefined
+			}
+			valueArray = append(valueArray, value)
+			found++
+			if found == limit {
+				goto RETURN
+			}
+		}
 	}
+
+	if found != limit {
+		if int64(lastIndex) != targetLength {
+			valueArray = append(valueArray, s.substring(int64(lastIndex), targetLength))
+		} else {
+			valueArray = append(valueArray, stringEmpty)
+		}
+	}
+
+RETURN:
+	return r.newArrayValues(valueArray)
 }
 //RegExp类构造
 func (r *Runtime) initRegExp() {
 	r.global.RegExpPrototype = r.NewObject()
 	o := r.global.RegExpPrototype.self
-	o._putProp("exec", r.newNativeFunc(r.regexpproto_exec, nil, "exec", nil, 1), true, false, true)
+	r.global.regexpProtoExec = r.newNativeFunc(r.regexpproto_exec, nil, "exec", nil, 1)
+	o._putProp("exec", r.global.regexpProtoExec, true, false, true)
 	o._putProp("test", r.newNativeFunc(r.regexpproto_test, nil, "test", nil, 1), true, false, true)
 	o._putProp("toString", r.newNativeFunc(r.regexpproto_toString, nil, "toString", nil, 0), true, false, true)
 	o.putStr("source", &valueProperty{
@@ -273,6 +750,32 @@ func (r *Runtime) initRegExp() {
 		getterFunc:   r.newNativeFunc(r.regexpproto_getIgnoreCase, nil, "get ignoreCase", nil, 0),
 		accessor:     true,
 	}, false)
+	o.putStr("flags", &valueProperty{
+		configurable: true,
+		getterFunc:   r.newNativeFunc(r.regexpproto_getFlags, nil, "get flags", nil, 0),
+		accessor:     true,
+	}, false)
+	o.putStr("dotAll", &valueProperty{
+		configurable: true,
+		getterFunc:   r.newNativeFunc(r.regexpproto_getDotAll, nil, "get dotAll", nil, 0),
+		accessor:     true,
+	}, false)
+	o.putStr("sticky", &valueProperty{
+		configurable: true,
+		getterFunc:   r.newNativeFunc(r.regexpproto_getSticky, nil, "get sticky", nil, 0),
+		accessor:     true,
+	}, false)
+	o.putStr("unicode", &valueProperty{
+		configurable: true,
+		getterFunc:   r.newNativeFunc(r.regexpproto_getUnicode, nil, "get unicode", nil, 0),
+		accessor:     true,
+	}, false)
+
+	o._putSym(symMatch, r.newNativeFunc(r.regexpproto_stdMatch, nil, "[Symbol.match]", nil, 1), true, false, true)
+	o._putSym(symMatchAll, r.newNativeFunc(r.regexpproto_stdMatchAll, nil, "[Symbol.matchAll]", nil, 1), true, false, true)
+	o._putSym(symReplace, r.newNativeFunc(r.regexpproto_stdReplace, nil, "[Symbol.replace]", nil, 2), true, false, true)
+	o._putSym(symSearch, r.newNativeFunc(r.regexpproto_stdSearch, nil, "[Symbol.search]", nil, 1), true, false, true)
+	o._putSym(symSplit, r.newNativeFunc(r.regexpproto_stdSplit, nil, "[Symbol.split]", nil, 2), true, false, true)
 
 	r.global.RegExp = r.newNativeFunc(r.builtin_RegExp, r.builtin_newRegExp, "RegExp", r.global.RegExpPrototype, 2)
 	r.addToGlobal("RegExp", r.global.RegExp)
@@ -0,0 +1,192 @@
+package goja
+
+import "reflect"
+
+const classSetIterator = "Set Iterator"
+
+// Set对象，值保存在orderedMap的键中
+type setObject struct {
+	baseObject
+	m *orderedMap
+}
+
+// Set迭代器，Set.prototype.entries/values返回的对象
+type setIterObject struct {
+	baseObject
+	iter *orderedMapIter
+	kind iterationKind
+}
+// 导出为[]interface{}
+func (so *setObject) export() interface{} {
+	a := make([]interface{}, 0, so.m.size)
+	for entry := so.m.iterFirst; entry != nil; entry = entry.iterNext {
+		a = append(a, entry.key.Export())
+	}
+	return a
+}
+// 返回[]interface{}的类型
+func (so *setObject) exportType() reflect.Type {
+	return reflectTypeArray
+}
+// Set迭代器的下一个结果
+func (si *setIterObject) next() Value {
+	r := si.val.runtime
+	if si.iter == nil {
+		return r.createIterResultObject(_undefined, true)
+	}
+	entry := si.iter.next()
+	if entry == nil {
+		si.iter = nil
+		return r.createIterResultObject(_undefined, true)
+	}
+	var result Value
+	if si.kind == iterationKindKeyValue {
+		result = r.newArrayValues([]Value{entry.key, entry.key})
+	} else {
+		result = entry.key
+	}
+	return r.createIterResultObject(result, false)
+}
+// 创建空的Set对象
+func (r *Runtime) newSetObject(proto *Object) *setObject {
+	o := &Object{runtime: r}
+
+	so := &setObject{
+		m: newOrderedMap(),
+	}
+	so.class = classSet
+	so.val = o
+	so.extensible = true
+	o.self = so
+	so.prototype = proto
+	so.init()
+	return so
+}
+// 创建Set迭代器
+func (r *Runtime) createSetIterator(m *orderedMap, kind iterationKind) Value {
+	o := &Object{runtime: r}
+
+	si := &setIterObject{
+		iter: m.newIter(),
+		kind: kind,
+	}
+	si.class = classSetIterator
+	si.val = o
+	si.extensible = true
+	o.self = si
+	si.prototype = r.global.SetIteratorPrototype
+	si.init()
+
+	return o
+}
+// 取出this对应的Set
+func (r *Runtime) thisSet(v Value, method string) *setObject {
+	if o, ok := v.(*Object); ok {
+		if so, ok := o.self.(*setObject); ok {
+			return so
+		}
+	}
+	r.typeErrorResult(true, "Method Set.prototype.%s called on incompatible receiver %s", method, v.String())
+	return nil
+}
+// Set()不能作为普通函数调用
+func (r *Runtime) builtin_Set(call FunctionCall) Value {
+	r.typeErrorResult(true, "Constructor Set requires 'new'")
+	return nil
+}
+// new Set(iterable)实现
+func (r *Runtime) builtin_newSet(args []Value) *Object {
+	so := r.newSetObject(r.global.SetPrototype)
+	if len(args) > 0 && args[0] != _undefined && args[0] != _null {
+		adder := r.toCallable(so.getStr("add"))
+		r.iterateClose(args[0], func(item Value) {
+			adder(FunctionCall{This: so.val, Arguments: []Value{item}})
+		})
+	}
+	return so.val
+}
+// Set.prototype.add实现
+func (r *Runtime) setProto_add(call FunctionCall) Value {
+	if r.thisSet(call.This, "add").m.set(call.Argument(0), nil) {
+		r.allocate(sizeofMapEntry)
+	}
+	return call.This
+}
+// Set.prototype.clear实现
+func (r *Runtime) setProto_clear(call FunctionCall) Value {
+	r.thisSet(call.This, "clear").m.clear()
+	return _undefined
+}
+// Set.prototype.delete实现
+func (r *Runtime) setProto_delete(call FunctionCall) Value {
+	return r.toBoolean(r.thisSet(call.This, "delete").m.remove(call.Argument(0)))
+}
+// Set.prototype.entries实现，每一项是[value, value]
+func (r *Runtime) setProto_entries(call FunctionCall) Value {
+	return r.createSetIterator(r.thisSet(call.This, "entries").m, iterationKindKeyValue)
+}
+// Set.prototype.forEach实现，迭代过程中添加的值也会被访问
+func (r *Runtime) setProto_forEach(call FunctionCall) Value {
+	so := r.thisSet(call.This, "forEach")
+	callback := r.toHandler(call.Argument(0))
+	if callback == nil {
+		r.typeErrorResult(true, "%s is not a function", call.Argument(0).String())
+	}
+	thisArg := call.Argument(1)
+	iter := so.m.newIter()
+	for entry := iter.next(); entry != nil; entry = iter.next() {
+		callback(FunctionCall{This: thisArg, Arguments: []Value{entry.key, entry.key, so.val}})
+	}
+	return _undefined
+}
+// Set.prototype.has实现
+func (r *Runtime) setProto_has(call FunctionCall) Value {
+	return r.toBoolean(r.thisSet(call.This, "has").m.has(call.Argument(0)))
+}
+// Set.prototype.size访问器
+func (r *Runtime) setProto_getSize(call FunctionCall) Value {
+	return intToValue(int64(r.thisSet(call.This, "size").m.size))
+}
+// Set.prototype.values实现
+func (r *Runtime) setProto_values(call FunctionCall) Value {
+	return r.createSetIterator(r.thisSet(call.This, "values").m, iterationKindValue)
+}
+// %SetIteratorPrototype%.next实现
+func (r *Runtime) setIterProto_next(call FunctionCall) Value {
+	if o, ok := call.This.(*Object); ok {
+		if si, ok := o.self.(*setIterObject); ok {
+			return si.next()
+		}
+	}
+	r.typeErrorResult(true, "Method Set Iterator.prototype.next called on incompatible receiver %s", call.This.String())
+	return nil
+}
+// Set类实现，keys和values是同一个函数
+func (r *Runtime) initSet() {
+	r.global.SetIteratorPrototype = r.newBaseObject(r.global.IteratorPrototype, classObject).val
+	o := r.global.SetIteratorPrototype.self
+	o._putProp("next", r.newNativeFunc(r.setIterProto_next, nil, "next", nil, 0), true, false, true)
+	o._putSym(symToStringTag, asciiString(classSetIterator), false, false, true)
+
+	proto := r.newBaseObject(r.global.ObjectPrototype, classObject)
+	r.global.SetPrototype = proto.val
+	proto._putProp("add", r.newNativeFunc(r.setProto_add, nil, "add", nil, 1), true, false, true)
+	proto._putProp("clear", r.newNativeFunc(r.setProto_clear, nil, "clear", nil, 0), true, false, true)
+	proto._putProp("delete", r.newNativeFunc(r.setProto_delete, nil, "delete", nil, 1), true, false, true)
+	proto._putProp("entries", r.newNativeFunc(r.setProto_entries, nil, "entries", nil, 0), true, false, true)
+	proto._putProp("forEach", r.newNativeFunc(r.setProto_forEach, nil, "forEach", nil, 1), true, false, true)
+	proto._putProp("has", r.newNativeFunc(r.setProto_has, nil, "has", nil, 1), true, false, true)
+	proto._put("size", &valueProperty{
+		accessor:     true,
+		configurable: true,
+		getterFunc:   r.newNativeFunc(r.setProto_getSize, nil, "get size", nil, 0),
+	})
+	values := r.newNativeFunc(r.setProto_values, nil, "values", nil, 0)
+	proto._putProp("values", values, true, false, true)
+	proto._putProp("keys", values, true, false, true)
+	proto._putSym(symIterator, values, true, false, true)
+	proto._putSym(symToStringTag, asciiString(classSet), false, false, true)
+
+	r.global.Set = r.newNativeFunc(r.builtin_Set, r.builtin_newSet, "Set", r.global.SetPrototype, 0)
+	r.addToGlobal("Set", r.global.Set)
+}
@@ -0,0 +1,61 @@
+package goja
+
+import "testing"
+
+func TestSet(t *testing.T) {
+	const SCRIPT = `
+	var o = {};
+	var s = new Set([1, "1", o, 1, NaN, NaN]);
+	assert.sameValue(s.size, 4, "size");
+	assert.sameValue(s.has(1), true, "number");
+	assert.sameValue(s.has(o), true, "object");
+	assert.sameValue(s.has({}), false, "other object");
+	assert.sameValue(s.has(NaN), true, "NaN");
+	assert.sameValue(s.add(-0), s, "add returns this");
+	assert.sameValue(s.has(0), true, "-0 and +0");
+	assert.sameValue(s.delete(o), true, "delete");
+	assert.sameValue(s.delete(o), false, "delete missing");
+	assert.sameValue([...s].join(), "1,1,NaN,0", "values");
+	assert.sameValue([...s.entries()][0].join(), "1,1", "entries");
+	assert.sameValue(Set.prototype.keys, Set.prototype.values, "keys");
+	assert.sameValue(Set.prototype[Symbol.iterator], Set.prototype.values, "@@iterator");
+	var res = [];
+	s.forEach(function(v, k, set) {
+		res.push(v);
+		if (v === 1) {
+			set.delete("1");
+			set.add(2);
+		}
+	});
+	assert.sameValue(res.join(), "1,NaN,0,2", "forEach");
+	s.clear();
+	assert.sameValue(s.size, 0, "clear");
+	assert.throws(TypeError, function() { Set(); }, "call");
+	assert.throws(TypeError, function() { Set.prototype.has.call(new Map(), 1); }, "incompatible receiver");
+	assert.sameValue(Object.prototype.toString.call(s), "[object Set]", "toStringTag");
+	assert.sameValue(Object.prototype.toString.call(s.values()), "[object Set Iterator]", "iterator toStringTag");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestSetExport(t *testing.T) {
+	vm := New()
+	v, err := vm.RunString(`new Set([3, 1, 2, 1])`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, ok := v.Export().([]interface{})
+	if !ok {
+		t.Fatalf("Unexpected export type: %T", v.Export())
+	}
+	if len(a) != 3 || a[0] != int64(3) || a[1] != int64(1) || a[2] != int64(2) {
+		t.Fatalf("Unexpected export: %v", a)
+	}
+	var a1 []int
+	if err := vm.ExportTo(v, &a1); err != nil {
+		t.Fatal(err)
+	}
+	if len(a1) != 3 || a1[0] != 3 || a1[1] != 1 || a1[2] != 2 {
+		t.Fatalf("Unexpected export: %v", a1)
+	}
+}
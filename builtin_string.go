@@ -4,17 +4,17 @@ import (
 	"bytes"
 	"github.com/oracle3/goja/parser"
 	"golang.org/x/text/collate"
-	"golang.org/x/text/language"
 	"golang.org/x/text/unicode/norm"
 	"math"
 	"strings"
+	"unicode/utf16"
 	"unicode/utf8"
 )
 
 func (r *Runtime) collator() *collate.Collator {
 	collator := r._collator
 	if collator == nil {
-		collator = collate.New(language.Und)
+		collator = collate.New(r.locale)
 		r._collator = collator
 	}
 	return collator
@@ -26,6 +26,9 @@ func (r *Runtime) builtin_String(call FunctionCall) Value {
 		if _, ok := arg.assertString(); ok {
 			return arg
 		}
+		if s, ok := arg.(*valueSymbol); ok {
+			return newStringValue(s.String())
+		}
 		return arg.ToString()
 	} else {
 		return newStringValue("")
@@ -58,19 +61,6 @@ func (r *Runtime) builtin_newString(args []Value) *Object {
 	return r._newString(s)
 }
 
-func searchSubstringUTF8(str, search string) (ret [][]int) {
-	searchPos := 0
-	l := len(str)
-	if searchPos < l {
-		p := strings.Index(str[searchPos:], search)
-		if p != -1 {
-			p += searchPos
-			searchPos = p + len(search)
-			ret = append(ret, []int{p, searchPos})
-		}
-	}
-	return
-}
 //返回指定对象的字符串形式
 func (r *Runtime) stringproto_toStringValueOf(this Value, funcName string) Value {
 	if str, ok := this.assertString(); ok {
@@ -157,6 +147,7 @@ func (r *Runtime) stringproto_concat(call FunctionCall) Value {
 		strs[i+1] = s
 		totalLen += s.length()
 	}
+	r.allocateString(totalLen)
 
 	if allAscii {
 		buf := bytes.NewBuffer(make([]byte, 0, totalLen))
@@ -231,6 +222,10 @@ func (r *Runtime) stringproto_lastIndexOf(call FunctionCall) Value {
 //localeCompare() 方法返回一个数字来指示一个参考字符串是否在排序顺序前面或之后或与给定字符串相同。
 func (r *Runtime) stringproto_localeCompare(call FunctionCall) Value {
 	r.checkObjectCoercible(call.This)
+	if locales, options := call.Argument(1), call.Argument(2); locales != _undefined || options != _undefined {
+		c := r.newCollator(locales, options)
+		return intToValue(int64(c.compare(call.This.String(), call.Argument(0).String())))
+	}
 	this := norm.NFD.String(call.This.String())
 	that := norm.NFD.String(call.Argument(0).String())
 	return intToValue(int64(r.collator().CompareString(this, that)))
@@ -239,207 +234,158 @@ func (r *Runtime) stringproto_localeCompare(call FunctionCall) Value {
 //match() 方法检索返回一个字符串匹配正则表达式的的结果
 func (r *Runtime) stringproto_match(call FunctionCall) Value {
 	r.checkObjectCoercible(call.This)
-	s := call.This.ToString()
 	regexp := call.Argument(0)
-	var rx *regexpObject
-	if regexp, ok := regexp.(*Object); ok {
-		rx, _ = regexp.self.(*regexpObject)
-	}
-
-	if rx == nil {
-		rx = r.builtin_newRegExp([]Value{regexp}).self.(*regexpObject)
+	if regexp != _undefined && regexp != _null {
+		if matcher := r.getMethod(regexp, symMatch); matcher != nil {
+			return matcher(FunctionCall{This: regexp, Arguments: []Value{call.This}})
+		}
 	}
-
-	if rx.global {
-		rx.putStr("lastIndex", intToValue(0), false)
-		var a []Value
-		var previousLastIndex int64
-		for {
-			match, result := rx.execRegexp(s)
-			if !match {
-				break
-			}
-			thisIndex := rx.getStr("lastIndex").ToInteger()
-			if thisIndex == previousLastIndex {
-				previousLastIndex++
-				rx.putStr("lastIndex", intToValue(previousLastIndex), false)
-			} else {
-				previousLastIndex = thisIndex
+	s := call.This.ToString()
+	rx := r.builtin_newRegExp([]Value{regexp})
+	return r.invokeSym(rx, symMatch, s)
+}
+//String.prototype.matchAll()
+//matchAll() 方法返回一个包含所有匹配正则表达式的结果的迭代器，正则表达式必须带有g标志。
+func (r *Runtime) stringproto_matchAll(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	regexp := call.Argument(0)
+	if regexp != _undefined && regexp != _null {
+		if r.isRegExp(regexp) {
+			flags := nilSafe(r.toObject(regexp).self.getStr("flags"))
+			r.checkObjectCoercible(flags)
+			if !strings.ContainsRune(flags.String(), 'g') {
+				r.typeErrorResult(true, "String.prototype.matchAll called with a non-global RegExp argument")
 			}
-			a = append(a, s.substring(int64(result[0]), int64(result[1])))
 		}
-		if len(a) == 0 {
-			return _null
+		if matcher := r.getMethod(regexp, symMatchAll); matcher != nil {
+			return matcher(FunctionCall{This: regexp, Arguments: []Value{call.This}})
 		}
-		return r.newArrayValues(a)
-	} else {
-		return rx.exec(s)
 	}
+	s := call.This.ToString()
+	rx := r.builtin_newRegExp([]Value{regexp, asciiString("g")})
+	return r.invokeSym(rx, symMatchAll, s)
 }
 //String.prototype.replace()
 //replace() 方法返回一个由替换值（replacement）替换一些或所有匹配的模式（pattern）后的新字符串。
 //模式可以是一个字符串或者一个正则表达式，替换值可以是一个字符串或者一个每次匹配都要调用的回调函数。
 func (r *Runtime) stringproto_replace(call FunctionCall) Value {
-	s := call.This.ToString()
-	var str string
-	var isASCII bool
-	if astr, ok := s.(asciiString); ok {
-		str = string(astr)
-		isASCII = true
-	} else {
-		str = s.String()
-	}
+	return r.stringReplace(call, false)
+}
+//String.prototype.replaceAll()
+//replaceAll() 方法返回一个新字符串，所有满足pattern的部分都被替换，正则表达式必须带有g标志。
+func (r *Runtime) stringproto_replaceAll(call FunctionCall) Value {
+	return r.stringReplace(call, true)
+}
+// replace和replaceAll的实现，searchValue有Symbol.replace方法时交给它处理
+func (r *Runtime) stringReplace(call FunctionCall, all bool) Value {
+	r.checkObjectCoercible(call.This)
 	searchValue := call.Argument(0)
 	replaceValue := call.Argument(1)
-
-	var found [][]int
-
-	if searchValue, ok := searchValue.(*Object); ok {
-		if regexp, ok := searchValue.self.(*regexpObject); ok {
-			find := 1
-			if regexp.global {
-				find = -1
-			}
-			if isASCII {
-				found = regexp.pattern.FindAllSubmatchIndexASCII(str, find)
-			} else {
-				found = regexp.pattern.FindAllSubmatchIndexUTF8(str, find)
-			}
-			if found == nil {
-				return s
+	if searchValue != _undefined && searchValue != _null {
+		if all && r.isRegExp(searchValue) {
+			flags := nilSafe(r.toObject(searchValue).self.getStr("flags"))
+			r.checkObjectCoercible(flags)
+			if !strings.ContainsRune(flags.String(), 'g') {
+				r.typeErrorResult(true, "String.prototype.replaceAll called with a non-global RegExp argument")
 			}
 		}
+		if replacer := r.getMethod(searchValue, symReplace); replacer != nil {
+			return replacer(FunctionCall{This: searchValue, Arguments: []Value{call.This, replaceValue}})
+		}
 	}
 
-	if found == nil {
-		found = searchSubstringUTF8(str, searchValue.String())
-	}
-
-	if len(found) == 0 {
-		return s
-	}
-
-	var buf bytes.Buffer
-	lastIndex := 0
-
-	var rcall func(FunctionCall) Value
-
-	if replaceValue, ok := replaceValue.(*Object); ok {
-		if c, ok := replaceValue.self.assertCallable(); ok {
-			rcall = c
+	s := call.This.ToString()
+	search := searchValue.ToString()
+	replaceStr := toReplaceString(replaceValue)
+	searchLength := search.length()
+	advanceBy := searchLength
+	if advanceBy == 0 {
+		advanceBy = 1
+	}
+
+	r.useGas(s.length())
+	var matches []regexpReplaceMatch
+	for pos := s.index(search, 0); pos != -1; {
+		matches = append(matches, regexpReplaceMatch{
+			matched:  search,
+			position: pos,
+			groups:   _undefined,
+		})
+		if !all || pos+advanceBy > s.length() {
+			break
 		}
+		pos = s.index(search, pos+advanceBy)
 	}
-
-	if rcall != nil {
-		for _, item := range found {
-			if item[0] != lastIndex {
-				buf.WriteString(str[lastIndex:item[0]])
-			}
-			matchCount := len(item) / 2
-			argumentList := make([]Value, matchCount+2)
-			for index := 0; index < matchCount; index++ {
-				offset := 2 * index
-				if item[offset] != -1 {
-					if isASCII {
-						argumentList[index] = asciiString(str[item[offset]:item[offset+1]])
-					} else {
-						argumentList[index] = newStringValue(str[item[offset]:item[offset+1]])
-					}
-				} else {
-					argumentList[index] = _undefined
+	return r.applyReplaceMatches(s, matches, replaceValue, replaceStr)
+}
+// GetSubstitution：展开替换模板中的$$、$&、$`、$'、$n、$nn和$<name>
+func (r *Runtime) getSubstitution(buf *bytes.Buffer, s valueString, m *regexpReplaceMatch, template string) {
+	tailPos := m.position + m.matched.length()
+	if tailPos > s.length() {
+		tailPos = s.length()
+	}
+	for i := 0; i < len(template); i++ {
+		ch := template[i]
+		if ch != '$' || i+1 >= len(template) {
+			buf.WriteByte(ch)
+			continue
+		}
+		switch next := template[i+1]; {
+		case next == '$':
+			buf.WriteByte('$')
+			i++
+		case next == '&':
+			buf.WriteString(m.matched.String())
+			i++
+		case next == '`':
+			buf.WriteString(s.substring(0, m.position).String())
+			i++
+		case next == '\'':
+			buf.WriteString(s.substring(tailPos, s.length()).String())
+			i++
+		case next >= '0' && next <= '9':
+			n, l := int(next-'0'), 1
+			if i+2 < len(template) && template[i+2] >= '0' && template[i+2] <= '9' {
+				if nn := n*10 + int(template[i+2]-'0'); nn >= 1 && nn <= len(m.captures) {
+					n, l = nn, 2
 				}
 			}
-			argumentList[matchCount] = valueInt(item[0])
-			argumentList[matchCount+1] = s
-			replacement := rcall(FunctionCall{
-				This:      _undefined,
-				Arguments: argumentList,
-			}).String()
-			buf.WriteString(replacement)
-			lastIndex = item[1]
-		}
-	} else {
-		newstring := replaceValue.String()
-
-		for _, item := range found {
-			if item[0] != lastIndex {
-				buf.WriteString(str[lastIndex:item[0]])
+			if n < 1 || n > len(m.captures) {
+				buf.WriteByte('$')
+				continue
 			}
-			matches := len(item) / 2
-			for i := 0; i < len(newstring); i++ {
-				if newstring[i] == '$' && i < len(newstring)-1 {
-					ch := newstring[i+1]
-					switch ch {
-					case '$':
-						buf.WriteByte('$')
-					case '`':
-						buf.WriteString(str[0:item[0]])
-					case '\'':
-						buf.WriteString(str[item[1]:])
-					case '&':
-						buf.WriteString(str[item[0]:item[1]])
-					default:
-						matchNumber := 0
-						l := 0
-						for _, ch := range newstring[i+1:] {
-							if ch >= '0' && ch <= '9' {
-								m := matchNumber*10 + int(ch-'0')
-								if m >= matches {
-									break
-								}
-								matchNumber = m
-								l++
-							} else {
-								break
-							}
-						}
-						if l > 0 {
-							offset := 2 * matchNumber
-							if offset < len(item) && item[offset] != -1 {
-								buf.WriteString(str[item[offset]:item[offset+1]])
-							}
-							i += l - 1
-						} else {
-							buf.WriteByte('$')
-							buf.WriteByte(ch)
-						}
-
-					}
-					i++
-				} else {
-					buf.WriteByte(newstring[i])
-				}
+			if c := m.captures[n-1]; c != _undefined {
+				buf.WriteString(c.String())
 			}
-			lastIndex = item[1]
+			i += l
+		case next == '<' && m.groups != _undefined:
+			end := strings.IndexByte(template[i+2:], '>')
+			if end < 0 {
+				buf.WriteByte('$')
+				continue
+			}
+			if c := nilSafe(r.toObject(m.groups).self.getStr(template[i+2 : i+2+end])); c != _undefined {
+				buf.WriteString(c.ToString().String())
+			}
+			i += 2 + end
+		default:
+			buf.WriteByte('$')
 		}
 	}
-
-	if lastIndex != len(str) {
-		buf.WriteString(str[lastIndex:])
-	}
-
-	return newStringValue(buf.String())
 }
 //String.prototype.search()
 //search() 方法执行正则表达式和 String 对象之间的一个搜索匹配。
 func (r *Runtime) stringproto_search(call FunctionCall) Value {
 	r.checkObjectCoercible(call.This)
-	s := call.This.ToString()
 	regexp := call.Argument(0)
-	var rx *regexpObject
-	if regexp, ok := regexp.(*Object); ok {
-		rx, _ = regexp.self.(*regexpObject)
-	}
-
-	if rx == nil {
-		rx = r.builtin_newRegExp([]Value{regexp}).self.(*regexpObject)
-	}
-
-	match, result := rx.execRegexp(s)
-	if !match {
-		return intToValue(-1)
+	if regexp != _undefined && regexp != _null {
+		if searcher := r.getMethod(regexp, symSearch); searcher != nil {
+			return searcher(FunctionCall{This: regexp, Arguments: []Value{call.This}})
+		}
 	}
-	return intToValue(int64(result[0]))
+	s := call.This.ToString()
+	rx := r.builtin_newRegExp([]Value{regexp})
+	return r.invokeSym(rx, symSearch, s)
 }
 //String.prototype.slice()
 //slice() 方法提取某个字符串的一部分，并返回一个新的字符串，且不会改动原字符串。
@@ -487,10 +433,15 @@ func (r *Runtime) stringproto_slice(call FunctionCall) Value {
 //split() 方法使用指定的分隔符字符串将一个String对象分割成子字符串数组，以一个指定的分割字串来决定每个拆分的位置。
 func (r *Runtime) stringproto_split(call FunctionCall) Value {
 	r.checkObjectCoercible(call.This)
-	s := call.This.ToString()
-
 	separatorValue := call.Argument(0)
 	limitValue := call.Argument(1)
+	if separatorValue != _undefined && separatorValue != _null {
+		if splitter := r.getMethod(separatorValue, symSplit); splitter != nil {
+			return splitter(FunctionCall{This: separatorValue, Arguments: []Value{call.This, limitValue}})
+		}
+	}
+	s := call.This.ToString()
+
 	limit := -1
 	if limitValue != _undefined {
 		limit = int(toUInt32(limitValue))
@@ -504,97 +455,32 @@ func (r *Runtime) stringproto_split(call FunctionCall) Value {
 		return r.newArrayValues([]Value{s})
 	}
 
-	var search *regexpObject
-	if o, ok := separatorValue.(*Object); ok {
-		search, _ = o.self.(*regexpObject)
-	}
-
-	if search != nil {
-		targetLength := s.length()
-		valueArray := []Value{}
-		result := search.pattern.FindAllSubmatchIndex(s, -1)
-		lastIndex := 0
-		found := 0
-
-		for _, match := range result {
-			if match[0] == match[1] {
-				// FIXME Ugh, this is a hack
-				if match[0] == 0 || int64(match[0]) == targetLength {
-					continue
-				}
-			}
-
-			if lastIndex != match[0] {
-				valueArray = append(valueArray, s.substring(int64(lastIndex), int64(match[0])))
-				found++
-			} else if lastIndex == match[0] {
-				if lastIndex != -1 {
-					valueArray = append(valueArray, stringEmpty)
-					found++
-				}
-			}
-
-			lastIndex = match[1]
-			if found == limit {
-				goto RETURN
-			}
-
-			captureCount := len(match) / 2
-			for index := 1; index < captureCount; index++ {
-				offset := index * 2
-				var value Value
-				if match[offset] != -1 {
-					value = s.substring(int64(match[offset]), int64(match[offset+1]))
-				} else {
-					value = _undefined
-				}
-				valueArray = append(valueArray, value)
-				found++
-				if found == limit {
-					goto RETURN
-				}
-			}
-		}
-
-		if found != limit {
-			if int64(lastIndex) != targetLength {
-				valueArray = append(valueArray, s.substring(int64(lastIndex), targetLength))
-			} else {
-				valueArray = append(valueArray, stringEmpty)
-			}
-		}
-
-	RETURN:
-		return r.newArrayValues(valueArray)
-
-	} else {
-		separator := separatorValue.String()
-
-		excess := false
-		str := s.String()
-		if limit > len(str) {
-			limit = len(str)
-		}
-		splitLimit := limit
-		if limit > 0 {
-			splitLimit = limit + 1
-			excess = true
-		}
+	separator := separatorValue.String()
 
-		split := strings.SplitN(str, separator, splitLimit)
+	excess := false
+	str := s.String()
+	if limit > len(str) {
+		limit = len(str)
+	}
+	splitLimit := limit
+	if limit > 0 {
+		splitLimit = limit + 1
+		excess = true
+	}
 
-		if excess && len(split) > limit {
-			split = split[:limit]
-		}
+	r.useGas(int64(len(str)))
+	split := strings.SplitN(str, separator, splitLimit)
 
-		valueArray := make([]Value, len(split))
-		for index, value := range split {
-			valueArray[index] = newStringValue(value)
-		}
+	if excess && len(split) > limit {
+		split = split[:limit]
+	}
 
-		return r.newArrayValues(valueArray)
+	valueArray := make([]Value, len(split))
+	for index, value := range split {
+		valueArray[index] = newStringValue(value)
 	}
 
+	return r.newArrayValues(valueArray)
 }
 //String.prototype.substring()
 //substring() 方法返回一个字符串在开始索引到结束索引之间的一个子集, 或从开始索引直到字符串的末尾的一个子集。
@@ -653,6 +539,12 @@ func (r *Runtime) stringproto_trim(call FunctionCall) Value {
 
 	return newStringValue(strings.Trim(s.String(), parser.WhitespaceChars))
 }
+//String.prototype[Symbol.iterator]()
+//返回一个按码点迭代字符串的迭代器
+func (r *Runtime) stringproto_iterator(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	return r.createStringIterator(call.This.ToString())
+}
 //String.prototype.substr()
 //substr() 方法返回一个字符串中从指定位置开始到指定字符数的字符。
 func (r *Runtime) stringproto_substr(call FunctionCall) Value {
@@ -677,6 +569,229 @@ func (r *Runtime) stringproto_substr(call FunctionCall) Value {
 
 	return s.substring(start, start+length)
 }
+//String.prototype.codePointAt()
+//codePointAt() 方法返回一个 Unicode 编码点值的非负整数，位置处是代理对的高位时返回整个代理对的码点。
+func (r *Runtime) stringproto_codePointAt(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	s := call.This.ToString()
+	pos := call.Argument(0).ToInteger()
+	size := s.length()
+	if pos < 0 || pos >= size {
+		return _undefined
+	}
+	first := s.charAt(pos)
+	if utf16.IsSurrogate(first) && first < 0xDC00 && pos+1 < size {
+		if cp := utf16.DecodeRune(first, s.charAt(pos+1)); cp != utf8.RuneError {
+			return intToValue(int64(cp))
+		}
+	}
+	return intToValue(int64(first & 0xFFFF))
+}
+// 检查参数不是正则表达式，用于includes、startsWith和endsWith
+func (r *Runtime) toSearchString(v Value, funcName string) valueString {
+	if r.isRegExp(v) {
+		r.typeErrorResult(true, "First argument to String.prototype.%s must not be a regular expression", funcName)
+	}
+	return v.ToString()
+}
+//String.prototype.includes()
+//includes() 方法用于判断一个字符串是否包含在另一个字符串中，根据情况返回 true 或 false。
+func (r *Runtime) stringproto_includes(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	s := call.This.ToString()
+	search := r.toSearchString(call.Argument(0), "includes")
+	pos := min(max(call.Argument(1).ToInteger(), 0), s.length())
+	return r.toBoolean(s.index(search, pos) != -1)
+}
+//String.prototype.startsWith()
+//startsWith() 方法用来判断当前字符串是否以另外一个给定的子字符串开头，并根据判断结果返回 true 或 false。
+func (r *Runtime) stringproto_startsWith(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	s := call.This.ToString()
+	search := r.toSearchString(call.Argument(0), "startsWith")
+	l := s.length()
+	start := min(max(call.Argument(1).ToInteger(), 0), l)
+	end := start + search.length()
+	if end > l {
+		return valueFalse
+	}
+	return r.toBoolean(s.index(search, start) == start)
+}
+//String.prototype.endsWith()
+//endsWith()方法用来判断当前字符串是否是以另外一个给定的子字符串“结尾”的，根据判断结果返回 true 或 false。
+func (r *Runtime) stringproto_endsWith(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	s := call.This.ToString()
+	search := r.toSearchString(call.Argument(0), "endsWith")
+	l := s.length()
+	end := l
+	if arg := call.Argument(1); arg != _undefined {
+		end = min(max(arg.ToInteger(), 0), l)
+	}
+	start := end - search.length()
+	if start < 0 {
+		return valueFalse
+	}
+	return r.toBoolean(s.index(search, start) == start)
+}
+// 把s重复count次
+func repeatString(s valueString, count int64) valueString {
+	var ret valueString = stringEmpty
+	for ; count > 0; count >>= 1 {
+		if count&1 != 0 {
+			ret = ret.concat(s)
+		}
+		if count > 1 {
+			s = s.concat(s)
+		}
+	}
+	return ret
+}
+//String.prototype.repeat()
+//repeat() 构造并返回一个新字符串，该字符串包含被连接在一起的指定数量的字符串的副本。
+func (r *Runtime) stringproto_repeat(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	s := call.This.ToString()
+	n := call.Argument(0).ToFloat()
+	if n < 0 || math.IsInf(n, 1) {
+		panic(r.newError(r.global.RangeError, "Invalid count value"))
+	}
+	count := call.Argument(0).ToInteger()
+	if count == 0 || s.length() == 0 {
+		return stringEmpty
+	}
+	if s.length()*count >= maxInt {
+		panic(r.newError(r.global.RangeError, "Invalid string length"))
+	}
+	r.allocateString(s.length() * count)
+	r.useGas(s.length() * count)
+	return repeatString(s, count)
+}
+// padStart和padEnd的实现，返回需要填充的字符串
+func (r *Runtime) stringPadding(call FunctionCall) (valueString, valueString) {
+	r.checkObjectCoercible(call.This)
+	s := call.This.ToString()
+	maxLength := toLength(call.Argument(0))
+	l := s.length()
+	if maxLength <= l {
+		return s, stringEmpty
+	}
+	var filler valueString = asciiString(" ")
+	if arg := call.Argument(1); arg != _undefined {
+		filler = arg.ToString()
+	}
+	fl := filler.length()
+	if fl == 0 {
+		return s, stringEmpty
+	}
+	fillLen := maxLength - l
+	r.allocateString(maxLength)
+	r.useGas(fillLen)
+	padding := repeatString(filler, fillLen/fl+1)
+	return s, padding.substring(0, fillLen)
+}
+//String.prototype.padStart()
+//padStart() 方法用另一个字符串填充当前字符串(如果需要的话，会重复多次)，以便产生的字符串达到给定的长度。从当前字符串的左侧开始填充。
+func (r *Runtime) stringproto_padStart(call FunctionCall) Value {
+	s, padding := r.stringPadding(call)
+	return padding.concat(s)
+}
+//String.prototype.padEnd()
+//padEnd() 方法会用一个字符串填充当前字符串（如果需要的话则重复填充），返回填充后达到指定长度的字符串。从当前字符串的末尾（右侧）开始填充。
+func (r *Runtime) stringproto_padEnd(call FunctionCall) Value {
+	s, padding := r.stringPadding(call)
+	return s.concat(padding)
+}
+//String.prototype.trimStart()
+//trimStart() 方法从字符串的开头删除空格，trimLeft() 是此方法的别名。
+func (r *Runtime) stringproto_trimStart(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	s := call.This.ToString()
+
+	return newStringValue(strings.TrimLeft(s.String(), parser.WhitespaceChars))
+}
+//String.prototype.trimEnd()
+//trimEnd() 方法从一个字符串的末端移除空白字符，trimRight() 是这个方法的别名。
+func (r *Runtime) stringproto_trimEnd(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	s := call.This.ToString()
+
+	return newStringValue(strings.TrimRight(s.String(), parser.WhitespaceChars))
+}
+//String.prototype.normalize()
+//normalize() 方法会按照指定的一种 Unicode 正规形式将当前字符串正规化。
+func (r *Runtime) stringproto_normalize(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	s := call.This.ToString()
+	var form norm.Form
+	switch f := call.Argument(0); {
+	case f == _undefined:
+		form = norm.NFC
+	default:
+		switch f.String() {
+		case "NFC":
+			form = norm.NFC
+		case "NFD":
+			form = norm.NFD
+		case "NFKC":
+			form = norm.NFKC
+		case "NFKD":
+			form = norm.NFKD
+		default:
+			panic(r.newError(r.global.RangeError, "The normalization form should be one of NFC, NFD, NFKC, NFKD."))
+		}
+	}
+	if _, ok := s.(asciiString); ok {
+		return s
+	}
+	return newStringValue(form.String(s.String()))
+}
+//String.fromCodePoint()
+//String.fromCodePoint() 静态方法返回使用指定的代码点序列创建的字符串。
+func (r *Runtime) string_fromCodePoint(call FunctionCall) Value {
+	buf := make([]uint16, 0, len(call.Arguments))
+	ascii := true
+	for _, arg := range call.Arguments {
+		num := arg.ToNumber()
+		f := num.ToFloat()
+		if f != math.Trunc(f) || f < 0 || f > utf8.MaxRune {
+			panic(r.newError(r.global.RangeError, "Invalid code point %s", num.String()))
+		}
+		cp := rune(f)
+		if cp >= utf8.RuneSelf {
+			ascii = false
+		}
+		if cp >= 0x10000 {
+			r1, r2 := utf16.EncodeRune(cp)
+			buf = append(buf, uint16(r1), uint16(r2))
+		} else {
+			buf = append(buf, uint16(cp))
+		}
+	}
+	if ascii {
+		b := make([]byte, len(buf))
+		for i, c := range buf {
+			b[i] = byte(c)
+		}
+		return asciiString(b)
+	}
+	return unicodeString(buf)
+}
+//String.raw()
+//String.raw() 是一个模板字符串的标签函数，用来获取一个模板字符串的原始字符串。
+func (r *Runtime) string_raw(call FunctionCall) Value {
+	cooked := call.Argument(0).ToObject(r)
+	raw := nilSafe(cooked.self.getStr("raw")).ToObject(r)
+	l := toLength(raw.self.getStr("length"))
+	var ret valueString = stringEmpty
+	for i := int64(0); i < l; i++ {
+		ret = ret.concat(nilSafe(raw.self.get(intToValue(i))).ToString())
+		if i+1 < l && i+1 < int64(len(call.Arguments)) {
+			ret = ret.concat(call.Arguments[i+1].ToString())
+		}
+	}
+	return ret
+}
 //String类构造
 func (r *Runtime) initString() {
 	r.global.StringPrototype = r.builtin_newString([]Value{stringEmpty})
@@ -692,7 +807,9 @@ func (r *Runtime) initString() {
 	o._putProp("lastIndexOf", r.newNativeFunc(r.stringproto_lastIndexOf, nil, "lastIndexOf", nil, 1), true, false, true)
 	o._putProp("localeCompare", r.newNativeFunc(r.stringproto_localeCompare, nil, "localeCompare", nil, 1), true, false, true)
 	o._putProp("match", r.newNativeFunc(r.stringproto_match, nil, "match", nil, 1), true, false, true)
+	o._putProp("matchAll", r.newNativeFunc(r.stringproto_matchAll, nil, "matchAll", nil, 1), true, false, true)
 	o._putProp("replace", r.newNativeFunc(r.stringproto_replace, nil, "replace", nil, 2), true, false, true)
+	o._putProp("replaceAll", r.newNativeFunc(r.stringproto_replaceAll, nil, "replaceAll", nil, 2), true, false, true)
 	o._putProp("search", r.newNativeFunc(r.stringproto_search, nil, "search", nil, 1), true, false, true)
 	o._putProp("slice", r.newNativeFunc(r.stringproto_slice, nil, "slice", nil, 2), true, false, true)
 	o._putProp("split", r.newNativeFunc(r.stringproto_split, nil, "split", nil, 2), true, false, true)
@@ -702,13 +819,30 @@ func (r *Runtime) initString() {
 	o._putProp("toUpperCase", r.newNativeFunc(r.stringproto_toUpperCase, nil, "toUpperCase", nil, 0), true, false, true)
 	o._putProp("toLocaleUpperCase", r.newNativeFunc(r.stringproto_toUpperCase, nil, "toLocaleUpperCase", nil, 0), true, false, true)
 	o._putProp("trim", r.newNativeFunc(r.stringproto_trim, nil, "trim", nil, 0), true, false, true)
+	o._putProp("codePointAt", r.newNativeFunc(r.stringproto_codePointAt, nil, "codePointAt", nil, 1), true, false, true)
+	o._putProp("includes", r.newNativeFunc(r.stringproto_includes, nil, "includes", nil, 1), true, false, true)
+	o._putProp("startsWith", r.newNativeFunc(r.stringproto_startsWith, nil, "startsWith", nil, 1), true, false, true)
+	o._putProp("endsWith", r.newNativeFunc(r.stringproto_endsWith, nil, "endsWith", nil, 1), true, false, true)
+	o._putProp("repeat", r.newNativeFunc(r.stringproto_repeat, nil, "repeat", nil, 1), true, false, true)
+	o._putProp("padStart", r.newNativeFunc(r.stringproto_padStart, nil, "padStart", nil, 1), true, false, true)
+	o._putProp("padEnd", r.newNativeFunc(r.stringproto_padEnd, nil, "padEnd", nil, 1), true, false, true)
+	o._putProp("normalize", r.newNativeFunc(r.stringproto_normalize, nil, "normalize", nil, 0), true, false, true)
+	trimStart := r.newNativeFunc(r.stringproto_trimStart, nil, "trimStart", nil, 0)
+	trimEnd := r.newNativeFunc(r.stringproto_trimEnd, nil, "trimEnd", nil, 0)
+	o._putProp("trimStart", trimStart, true, false, true)
+	o._putProp("trimEnd", trimEnd, true, false, true)
+	o._putSym(symIterator, r.newNativeFunc(r.stringproto_iterator, nil, "[Symbol.iterator]", nil, 0), true, false, true)
 
 	// Annex B
 	o._putProp("substr", r.newNativeFunc(r.stringproto_substr, nil, "substr", nil, 2), true, false, true)
+	o._putProp("trimLeft", trimStart, true, false, true)
+	o._putProp("trimRight", trimEnd, true, false, true)
 
 	r.global.String = r.newNativeFunc(r.builtin_String, r.builtin_newString, "String", r.global.StringPrototype, 1)
 	o = r.global.String.self
 	o._putProp("fromCharCode", r.newNativeFunc(r.string_fromcharcode, nil, "fromCharCode", nil, 1), true, false, true)
+	o._putProp("fromCodePoint", r.newNativeFunc(r.string_fromCodePoint, nil, "fromCodePoint", nil, 1), true, false, true)
+	o._putProp("raw", r.newNativeFunc(r.string_raw, nil, "raw", nil, 1), true, false, true)
 
 	r.addToGlobal("String", r.global.String)
 
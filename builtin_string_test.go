@@ -88,3 +88,35 @@ assert.sameValue('A—', String.fromCharCode(65, 0x2014));
 
 	testScript1(TESTLIB+SCRIPT, _undefined, t)
 }
+
+func TestStringES6Methods(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue("abc".includes("b"), true, "includes");
+	assert.sameValue("abc".includes("a", 1), false, "includes position");
+	assert.sameValue("abc".startsWith("bc", 1), true, "startsWith");
+	assert.sameValue("abc".endsWith("ab", 2), true, "endsWith");
+	assert.throws(TypeError, function() {
+		"abc".startsWith(/a/);
+	}, "startsWith regexp");
+	assert.sameValue("ab".repeat(3), "ababab", "repeat");
+	assert.sameValue("ab".repeat(0), "", "repeat 0");
+	assert.throws(RangeError, function() {
+		"ab".repeat(-1);
+	}, "repeat negative");
+	assert.sameValue("5".padStart(3, "0"), "005", "padStart");
+	assert.sameValue("5".padEnd(4, "ab"), "5aba", "padEnd");
+	assert.sameValue("abc".padStart(2), "abc", "padStart shorter");
+	assert.sameValue("  x ".trimStart(), "x ", "trimStart");
+	assert.sameValue("  x ".trimEnd(), "  x", "trimEnd");
+	assert.sameValue(String.prototype.trimLeft, String.prototype.trimStart, "trimLeft alias");
+	assert.sameValue("\uD835\uDCB3".codePointAt(0), 0x1D4B3, "codePointAt");
+	assert.sameValue("\uD835\uDCB3".codePointAt(1), 0xDCB3, "codePointAt low surrogate");
+	assert.sameValue(String.fromCodePoint(0x1D4B3, 65), "\uD835\uDCB3A", "fromCodePoint");
+	assert.throws(RangeError, function() {
+		String.fromCodePoint(0x110000);
+	}, "fromCodePoint range");
+	assert.sameValue("Å".normalize("NFC"), "Å", "normalize");
+	assert.sameValue(String.raw({raw: ["a", "b", "c"]}, 1, 2, 3), "a1b2c", "raw");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
@@ -0,0 +1,120 @@
+package goja
+
+// 内置的well-known symbol，所有Runtime共享
+var (
+	symHasInstance = &valueSymbol{descr: "Symbol.hasInstance"}
+	symIterator    = &valueSymbol{descr: "Symbol.iterator"}
+	symMatch       = &valueSymbol{descr: "Symbol.match"}
+	symMatchAll    = &valueSymbol{descr: "Symbol.matchAll"}
+	symReplace     = &valueSymbol{descr: "Symbol.replace"}
+	symSearch      = &valueSymbol{descr: "Symbol.search"}
+	symSplit       = &valueSymbol{descr: "Symbol.split"}
+	symToPrimitive = &valueSymbol{descr: "Symbol.toPrimitive"}
+	symToStringTag = &valueSymbol{descr: "Symbol.toStringTag"}
+)
+// 导出给Go代码使用的well-known symbol，可配合Object.SetSymbol使用
+var (
+	SymHasInstance Value = symHasInstance
+	SymIterator    Value = symIterator
+	SymMatch       Value = symMatch
+	SymMatchAll    Value = symMatchAll
+	SymReplace     Value = symReplace
+	SymSearch      Value = symSearch
+	SymSplit       Value = symSplit
+	SymToPrimitive Value = symToPrimitive
+	SymToStringTag Value = symToStringTag
+)
+// Symbol([description])实现，每次调用都返回一个新的symbol
+func (r *Runtime) builtin_Symbol(call FunctionCall) Value {
+	var descr string
+	if arg := call.Argument(0); arg != _undefined {
+		descr = arg.ToString().String()
+	}
+	return &valueSymbol{descr: descr}
+}
+// Symbol不能作为构造函数使用
+func (r *Runtime) builtin_newSymbol(args []Value) *Object {
+	r.typeErrorResult(true, "Symbol is not a constructor")
+	panic("Unreachable")
+}
+// 取出this对应的symbol值
+func (r *Runtime) thisSymbolValue(v Value, method string) *valueSymbol {
+	switch o := v.(type) {
+	case *valueSymbol:
+		return o
+	case *Object:
+		if p, ok := o.self.(*primitiveValueObject); ok {
+			if s, ok := p.pValue.(*valueSymbol); ok {
+				return s
+			}
+		}
+	}
+	r.typeErrorResult(true, "Method Symbol.prototype.%s is called on incompatible receiver", method)
+	return nil
+}
+// Symbol.for(key)实现，在全局注册表中查找或创建symbol
+func (r *Runtime) symbol_for(call FunctionCall) Value {
+	key := call.Argument(0).ToString().String()
+	if s, ok := r.symbolRegistry[key]; ok {
+		return s
+	}
+	if r.symbolRegistry == nil {
+		r.symbolRegistry = make(map[string]*valueSymbol)
+	}
+	s := &valueSymbol{descr: key}
+	r.symbolRegistry[key] = s
+	return s
+}
+// Symbol.keyFor(sym)实现，返回注册表中symbol对应的key
+func (r *Runtime) symbol_keyFor(call FunctionCall) Value {
+	arg := call.Argument(0)
+	s, ok := arg.(*valueSymbol)
+	if !ok {
+		r.typeErrorResult(true, "%s is not a symbol", arg.String())
+	}
+	if r.symbolRegistry[s.descr] == s {
+		return newStringValue(s.descr)
+	}
+	return _undefined
+}
+// Symbol.prototype.toString实现
+func (r *Runtime) symbolproto_toString(call FunctionCall) Value {
+	return newStringValue(r.thisSymbolValue(call.This, "toString").String())
+}
+// Symbol.prototype.valueOf实现
+func (r *Runtime) symbolproto_valueOf(call FunctionCall) Value {
+	return r.thisSymbolValue(call.This, "valueOf")
+}
+// Symbol.prototype.description的getter
+func (r *Runtime) symbolproto_getDescription(call FunctionCall) Value {
+	return newStringValue(r.thisSymbolValue(call.This, "description").descr)
+}
+// Symbol类实现
+func (r *Runtime) initSymbol() {
+	r.global.SymbolPrototype = r.newBaseObject(r.global.ObjectPrototype, classObject).val
+	o := r.global.SymbolPrototype.self
+	o._putProp("toString", r.newNativeFunc(r.symbolproto_toString, nil, "toString", nil, 0), true, false, true)
+	o._putProp("valueOf", r.newNativeFunc(r.symbolproto_valueOf, nil, "valueOf", nil, 0), true, false, true)
+	o.defineOwnProperty(newStringValue("description"), propertyDescr{
+		Getter:       r.newNativeFunc(r.symbolproto_getDescription, nil, "get description", nil, 0),
+		Configurable: FLAG_TRUE,
+	}, true)
+	o._putSym(symToPrimitive, r.newNativeFunc(r.symbolproto_valueOf, nil, "[Symbol.toPrimitive]", nil, 1), false, false, true)
+	o._putSym(symToStringTag, newStringValue(classSymbol), false, false, true)
+
+	r.global.Symbol = r.newNativeFunc(r.builtin_Symbol, r.builtin_newSymbol, "Symbol", r.global.SymbolPrototype, 0)
+	o = r.global.Symbol.self
+	o._putProp("for", r.newNativeFunc(r.symbol_for, nil, "for", nil, 1), true, false, true)
+	o._putProp("keyFor", r.newNativeFunc(r.symbol_keyFor, nil, "keyFor", nil, 1), true, false, true)
+	o._putProp("hasInstance", symHasInstance, false, false, false)
+	o._putProp("iterator", symIterator, false, false, false)
+	o._putProp("match", symMatch, false, false, false)
+	o._putProp("matchAll", symMatchAll, false, false, false)
+	o._putProp("replace", symReplace, false, false, false)
+	o._putProp("search", symSearch, false, false, false)
+	o._putProp("split", symSplit, false, false, false)
+	o._putProp("toPrimitive", symToPrimitive, false, false, false)
+	o._putProp("toStringTag", symToStringTag, false, false, false)
+
+	r.addToGlobal("Symbol", r.global.Symbol)
+}
@@ -0,0 +1,88 @@
+package goja
+
+import "testing"
+
+func TestSymbol(t *testing.T) {
+	const SCRIPT = `
+var s = Symbol("foo");
+assert.sameValue(typeof s, "symbol", "typeof");
+assert.sameValue(s.toString(), "Symbol(foo)", "toString");
+assert.sameValue(String(s), "Symbol(foo)", "String()");
+assert.sameValue(s.description, "foo", "description");
+assert(s !== Symbol("foo"), "unique");
+assert.sameValue(Object(s) == s, true, "wrapper equality");
+assert.sameValue(Symbol.for("bar"), Symbol.for("bar"), "Symbol.for");
+assert.sameValue(Symbol.keyFor(Symbol.for("bar")), "bar", "keyFor registered");
+assert.sameValue(Symbol.keyFor(s), undefined, "keyFor unregistered");
+assert.throws(TypeError, function() { new Symbol(); }, "new Symbol");
+assert.throws(TypeError, function() { return "" + s; }, "to string");
+assert.throws(TypeError, function() { return s * 1; }, "to number");
+`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestSymbolProperties(t *testing.T) {
+	const SCRIPT = `
+var s = Symbol("key");
+var o = {a: 1};
+o[s] = 2;
+assert.sameValue(o[s], 2, "get");
+assert(s in o, "in");
+assert(o.hasOwnProperty(s), "hasOwnProperty");
+assert.sameValue(Object.keys(o).length, 1, "keys");
+assert.sameValue(Object.getOwnPropertyNames(o).length, 1, "getOwnPropertyNames");
+var syms = Object.getOwnPropertySymbols(o);
+assert.sameValue(syms.length, 1, "getOwnPropertySymbols length");
+assert.sameValue(syms[0], s, "getOwnPropertySymbols");
+assert.sameValue(JSON.stringify(o), '{"a":1}', "JSON");
+
+Object.defineProperty(o, s, {value: 3, enumerable: false});
+var d = Object.getOwnPropertyDescriptor(o, s);
+assert.sameValue(d.value, 3, "descriptor value");
+assert.sameValue(d.enumerable, false, "descriptor enumerable");
+assert.sameValue(o.propertyIsEnumerable(s), false, "propertyIsEnumerable");
+assert(delete o[s], "delete");
+assert.sameValue(Object.getOwnPropertySymbols(o).length, 0, "after delete");
+
+var a = [];
+a[s] = "x";
+assert.sameValue(a[s], "x", "array");
+assert.sameValue(a.length, 0, "array length");
+var p = Object.create({});
+Object.getPrototypeOf(p)[s] = "inherited";
+assert.sameValue(p[s], "inherited", "inherited");
+`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestSymbolWellKnown(t *testing.T) {
+	const SCRIPT = `
+var o = {};
+o[Symbol.toPrimitive] = function(hint) {
+	return hint === "number" ? 42 : hint;
+};
+assert.sameValue(+o, 42, "number hint");
+assert.sameValue(String(o), "string", "string hint");
+assert.sameValue(o + "", "default", "default hint");
+
+var bad = {};
+bad[Symbol.toPrimitive] = function() { return {}; };
+assert.throws(TypeError, function() { return +bad; }, "non-primitive result");
+
+var tagged = {};
+tagged[Symbol.toStringTag] = "Custom";
+assert.sameValue(Object.prototype.toString.call(tagged), "[object Custom]", "toStringTag");
+assert.sameValue(Object.prototype.toString.call(Math), "[object Math]", "Math");
+assert.sameValue(Object.prototype.toString.call(JSON), "[object JSON]", "JSON");
+assert.sameValue(Object.prototype.toString.call(Symbol()), "[object Symbol]", "Symbol");
+
+var Even = {};
+Even[Symbol.hasInstance] = function(v) { return v % 2 === 0; };
+assert(2 instanceof Even, "hasInstance true");
+assert(!(3 instanceof Even), "hasInstance false");
+function F() {}
+assert(F[Symbol.hasInstance](new F()), "Function.prototype[Symbol.hasInstance]");
+assert.sameValue(typeof Symbol.iterator, "symbol", "Symbol.iterator");
+`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
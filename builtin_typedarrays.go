@@ -1,5 +1,18 @@
 package goja
 
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// 能够分配的最大ArrayBuffer长度
+const maxArrayBufferLength = math.MaxInt32
+
 type objectArrayBuffer struct {
 	baseObject
 	data []byte
@@ -9,13 +22,17 @@ func (o *objectArrayBuffer) export() interface{} {
 	return o.data
 }
 
+func (o *objectArrayBuffer) exportType() reflect.Type {
+	return typeBytes
+}
+
 func (r *Runtime) _newArrayBuffer(proto *Object, o *Object) *objectArrayBuffer {
 	if o == nil {
 		o = &Object{runtime: r}
 	}
 	b := &objectArrayBuffer{
 		baseObject: baseObject{
-			class:      classObject,
+			class:      classArrayBuffer,
 			val:        o,
 			prototype:  proto,
 			extensible: true,
@@ -25,84 +42,898 @@ func (r *Runtime) _newArrayBuffer(proto *Object, o *Object) *objectArrayBuffer {
 	b.init()
 	return b
 }
+// 转换为非负的整数索引(ToIndex)，超出范围时抛出RangeError
+func (r *Runtime) toIndex(v Value, msg string) int {
+	if v == _undefined {
+		return 0
+	}
+	i := v.ToInteger()
+	if i < 0 || i > maxArrayBufferLength {
+		panic(r.newError(r.global.RangeError, "%s", msg))
+	}
+	return int(i)
+}
+// 相对位置转换为[0, length]内的索引，负数从末尾算起
+func relToIdx(rel Value, length int) int {
+	i := rel.ToInteger()
+	if i < 0 {
+		return int(max(int64(length)+i, 0))
+	}
+	return int(min(i, int64(length)))
+}
+// 同relToIdx，undefined时返回def
+func relToIdxDefault(rel Value, length, def int) int {
+	if rel == _undefined {
+		return def
+	}
+	return relToIdx(rel, length)
+}
 
 func (r *Runtime) builtin_ArrayBuffer(args []Value, proto *Object) *Object {
 	b := r._newArrayBuffer(proto, nil)
 	if len(args) > 0 {
-		b.data = make([]byte, toLength(args[0]))
+		l := r.toIndex(args[0], "Array buffer allocation failed")
+		r.allocate(int(l))
+		b.data = make([]byte, l)
 	}
 	return b.val
 }
+// ArrayBuffer()不能作为普通函数调用
+func (r *Runtime) builtin_ArrayBufferCall(call FunctionCall) Value {
+	r.typeErrorResult(true, "Constructor ArrayBuffer requires 'new'")
+	return nil
+}
+// 取出this对应的ArrayBuffer
+func (r *Runtime) thisArrayBuffer(v Value, method string) *objectArrayBuffer {
+	if o, ok := v.(*Object); ok {
+		if b, ok := o.self.(*objectArrayBuffer); ok {
+			return b
+		}
+	}
+	r.typeErrorResult(true, "Method ArrayBuffer.prototype.%s called on incompatible receiver %s", method, v.String())
+	return nil
+}
 //ArrayBuffer.prototype.byteLength
 //byteLength访问器属性表示一个ArrayBuffer 对象的字节长度。
 func (r *Runtime) arrayBufferProto_getByteLength(call FunctionCall) Value {
-	o := r.toObject(call.This)
-	if b, ok := o.self.(*objectArrayBuffer); ok {
-		return intToValue(int64(len(b.data)))
-	}
-	r.typeErrorResult(true, "Object is not ArrayBuffer: %s", o)
-	panic("unreachable")
+	b := r.thisArrayBuffer(call.This, "byteLength")
+	return intToValue(int64(len(b.data)))
 }
 //ArrayBuffer.prototype.slice()
 //slice()方法返回一个新的 ArrayBuffer ，它的内容是这个ArrayBuffer的字节副本，从begin（包括），到end（不包括）
 func (r *Runtime) arrayBufferProto_slice(call FunctionCall) Value {
-	o := r.toObject(call.This)
-	if b, ok := o.self.(*objectArrayBuffer); ok {
-		l := int64(len(b.data))
-		start := toLength(call.Argument(0))
-		if start < 0 {
-			start = l + start
-		}
-		if start < 0 {
-			start = 0
-		} else if start > l {
-			start = l
-		}
-		var stop int64
-		if arg := call.Argument(1); arg != _undefined {
-			stop = toLength(arg)
-			if stop < 0 {
-				stop = int64(len(b.data)) + stop
+	b := r.thisArrayBuffer(call.This, "slice")
+	l := len(b.data)
+	start := relToIdx(call.Argument(0), l)
+	stop := relToIdxDefault(call.Argument(1), l, l)
+
+	ret := r._newArrayBuffer(r.global.ArrayBufferPrototype, nil)
+	if stop > start {
+		r.allocate(stop - start)
+		ret.data = make([]byte, stop-start)
+		copy(ret.data, b.data[start:stop])
+	} else {
+		ret.data = []byte{}
+	}
+	return ret.val
+}
+//ArrayBuffer.isView()
+//判断参数是否为类型化数组或DataView
+func (r *Runtime) arrayBuffer_isView(call FunctionCall) Value {
+	if o, ok := call.Argument(0).(*Object); ok {
+		switch o.self.(type) {
+		case *typedArrayObject, *dataViewObject:
+			return valueTrue
+		}
+	}
+	return valueFalse
+}
+// 取出this对应的类型化数组
+func (r *Runtime) thisTypedArray(v Value, method string) *typedArrayObject {
+	if o, ok := v.(*Object); ok {
+		if a, ok := o.self.(*typedArrayObject); ok {
+			return a
+		}
+	}
+	r.typeErrorResult(true, "Method %%TypedArray%%.prototype.%s called on incompatible receiver %s", method, v.String())
+	return nil
+}
+// 检查参数是否为回调函数
+func (r *Runtime) toCallbackFn(v Value) func(FunctionCall) Value {
+	if o, ok := v.(*Object); ok {
+		if call, ok := o.self.assertCallable(); ok {
+			return call
+		}
+	}
+	r.typeErrorResult(true, "%s is not a function", v.String())
+	return nil
+}
+// %TypedArray%()是抽象类，不能直接调用或构造
+func (r *Runtime) builtin_TypedArray(call FunctionCall) Value {
+	r.typeErrorResult(true, "Abstract class TypedArray not directly constructable")
+	return nil
+}
+// 用构造函数c创建类型化数组(TypedArrayCreate)
+func (r *Runtime) typedArrayCreate(c *Object, args []Value) *typedArrayObject {
+	obj := r.constructWith(r.toConstructor(c), args, c)
+	a, ok := obj.self.(*typedArrayObject)
+	if !ok {
+		r.typeErrorResult(true, "Result is not a typed array")
+	}
+	if len(args) == 1 {
+		if l, ok := args[0].assertInt(); ok && int64(a.length) < l {
+			r.typeErrorResult(true, "Derived TypedArray constructor created an array which was too small")
+		}
+	}
+	return a
+}
+// 创建与a同类型的新类型化数组
+func (r *Runtime) typedArraySpeciesCreate(a *typedArrayObject, length int) *typedArrayObject {
+	return r.newTypedArray(a.kind, length, r.typedArrayProto(a.kind))
+}
+// 获取kind对应的原型
+func (r *Runtime) typedArrayProto(kind *typedArrayKind) *Object {
+	return r.global.typedArrayCtors[kind].self.getStr("prototype").(*Object)
+}
+// 创建长度为length的类型化数组
+func (r *Runtime) newTypedArray(kind *typedArrayKind, length int, proto *Object) *typedArrayObject {
+	if length > maxArrayBufferLength/kind.size {
+		panic(r.newError(r.global.RangeError, "Invalid typed array length: %d", length))
+	}
+	buf := r._newArrayBuffer(r.global.ArrayBufferPrototype, nil)
+	r.allocate(length * kind.size)
+	buf.data = make([]byte, length*kind.size)
+	return r.newTypedArrayObject(kind, buf, 0, length, proto)
+}
+// BigInt数组和数字数组之间不能互相复制
+func (r *Runtime) checkTypedArrayContentType(target, source *typedArrayKind) {
+	if target.bigInt != source.bigInt {
+		r.typeErrorResult(true, "Content type of %s is incompatible with %s", source.name, target.name)
+	}
+}
+// 类型化数组构造函数的实现，支持长度、类型化数组、可迭代对象、类数组对象和ArrayBuffer参数
+func (r *Runtime) typedArrayConstructor(kind *typedArrayKind) func(args []Value, proto *Object) *Object {
+	return func(args []Value, proto *Object) *Object {
+		if len(args) == 0 {
+			return r.newTypedArray(kind, 0, proto).val
+		}
+		src, ok := args[0].(*Object)
+		if !ok {
+			return r.newTypedArray(kind, r.toIndex(args[0], "Invalid typed array length"), proto).val
+		}
+		switch s := src.self.(type) {
+		case *objectArrayBuffer:
+			return r.typedArrayFromBuffer(kind, s, args[1:], proto).val
+		case *typedArrayObject:
+			r.checkTypedArrayContentType(kind, s.kind)
+			a := r.newTypedArray(kind, s.length, proto)
+			for i := 0; i < s.length; i++ {
+				r.useGas(1)
+				a.setIdx(i, s.getIdx(i))
 			}
-			if stop < 0 {
-				stop = 0
-			} else if stop > l {
-				stop = l
+			return a.val
+		}
+		var values []Value
+		if method, ok := src.self.get(symIterator).(*Object); ok && method != nil {
+			r.iterate(src, func(item Value) {
+				values = append(values, item)
+			})
+		} else {
+			l := toLength(src.self.getStr("length"))
+			for i := int64(0); i < l; i++ {
+				r.useGas(1)
+				values = append(values, nilSafe(src.self.get(intToValue(i))))
 			}
-
+		}
+		a := r.newTypedArray(kind, len(values), proto)
+		for i, v := range values {
+			a.setIdx(i, r.toTypedArrayValue(kind, v))
+		}
+		return a.val
+	}
+}
+// 在ArrayBuffer上创建视图，args为byteOffset和length
+func (r *Runtime) typedArrayFromBuffer(kind *typedArrayKind, buf *objectArrayBuffer, args []Value, proto *Object) *typedArrayObject {
+	var offsetArg, lengthArg Value = _undefined, _undefined
+	if len(args) > 0 {
+		offsetArg = args[0]
+	}
+	if len(args) > 1 {
+		lengthArg = args[1]
+	}
+	byteOffset := r.toIndex(offsetArg, "Start offset is outside the bounds of the buffer")
+	if byteOffset%kind.size != 0 {
+		panic(r.newError(r.global.RangeError, "start offset of %s should be a multiple of %d", kind.name, kind.size))
+	}
+	bufLen := len(buf.data)
+	var length int
+	if lengthArg == _undefined {
+		if bufLen%kind.size != 0 {
+			panic(r.newError(r.global.RangeError, "byte length of %s should be a multiple of %d", kind.name, kind.size))
+		}
+		if byteOffset > bufLen {
+			panic(r.newError(r.global.RangeError, "Start offset %d is outside the bounds of the buffer", byteOffset))
+		}
+		length = (bufLen - byteOffset) / kind.size
+	} else {
+		length = r.toIndex(lengthArg, "Invalid typed array length")
+		if byteOffset+length*kind.size > bufLen {
+			panic(r.newError(r.global.RangeError, "Invalid typed array length: %d", length))
+		}
+	}
+	return r.newTypedArrayObject(kind, buf, byteOffset, length, proto)
+}
+// %TypedArray%.from实现
+func (r *Runtime) typedArray_from(call FunctionCall) Value {
+	var mapFn func(FunctionCall) Value
+	if arg := call.Argument(1); arg != _undefined {
+		mapFn = r.toCallbackFn(arg)
+	}
+	thisArg := call.Argument(2)
+	src := call.Argument(0).ToObject(r)
+	var values []Value
+	if method, ok := src.self.get(symIterator).(*Object); ok && method != nil {
+		r.iterate(src, func(item Value) {
+			values = append(values, item)
+		})
+	} else {
+		l := toLength(src.self.getStr("length"))
+		for i := int64(0); i < l; i++ {
+			r.useGas(1)
+			values = append(values, nilSafe(src.self.get(intToValue(i))))
+		}
+	}
+	c := r.toObject(call.This)
+	a := r.typedArrayCreate(c, []Value{intToValue(int64(len(values)))})
+	for i, v := range values {
+		if mapFn != nil {
+			v = mapFn(FunctionCall{This: thisArg, Arguments: []Value{v, intToValue(int64(i))}})
+		}
+		a.val.self.put(intToValue(int64(i)), v, true)
+	}
+	return a.val
+}
+// %TypedArray%.of实现
+func (r *Runtime) typedArray_of(call FunctionCall) Value {
+	c := r.toObject(call.This)
+	a := r.typedArrayCreate(c, []Value{intToValue(int64(len(call.Arguments)))})
+	for i, v := range call.Arguments {
+		a.val.self.put(intToValue(int64(i)), v, true)
+	}
+	return a.val
+}
+// %TypedArray%.prototype.buffer
+func (r *Runtime) typedArrayProto_getBuffer(call FunctionCall) Value {
+	return r.thisTypedArray(call.This, "buffer").buffer.val
+}
+// %TypedArray%.prototype.byteLength
+func (r *Runtime) typedArrayProto_getByteLength(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "byteLength")
+	return intToValue(int64(a.length * a.kind.size))
+}
+// %TypedArray%.prototype.byteOffset
+func (r *Runtime) typedArrayProto_getByteOffset(call FunctionCall) Value {
+	return intToValue(int64(r.thisTypedArray(call.This, "byteOffset").byteOffset))
+}
+// %TypedArray%.prototype.length
+func (r *Runtime) typedArrayProto_getLength(call FunctionCall) Value {
+	return intToValue(int64(r.thisTypedArray(call.This, "length").length))
+}
+// %TypedArray%.prototype[@@toStringTag]，不是类型化数组时返回undefined
+func (r *Runtime) typedArrayProto_getToStringTag(call FunctionCall) Value {
+	if o, ok := call.This.(*Object); ok {
+		if a, ok := o.self.(*typedArrayObject); ok {
+			return newStringValue(a.kind.name)
+		}
+	}
+	return _undefined
+}
+// %TypedArray%.prototype.copyWithin实现
+func (r *Runtime) typedArrayProto_copyWithin(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "copyWithin")
+	l := a.length
+	to := relToIdx(call.Argument(0), l)
+	from := relToIdx(call.Argument(1), l)
+	final := relToIdxDefault(call.Argument(2), l, l)
+	count := min(int64(final-from), int64(l-to))
+	if count > 0 {
+		r.useGas(count)
+		size := a.kind.size
+		data := a.buffer.data[a.byteOffset:]
+		copy(data[to*size:(to+int(count))*size], data[from*size:(from+int(count))*size])
+	}
+	return a.val
+}
+// %TypedArray%.prototype.entries实现
+func (r *Runtime) typedArrayProto_entries(call FunctionCall) Value {
+	return r.createArrayIterator(r.thisTypedArray(call.This, "entries").val, iterationKindKeyValue)
+}
+// %TypedArray%.prototype.keys实现
+func (r *Runtime) typedArrayProto_keys(call FunctionCall) Value {
+	return r.createArrayIterator(r.thisTypedArray(call.This, "keys").val, iterationKindKey)
+}
+// %TypedArray%.prototype.values实现，也是@@iterator
+func (r *Runtime) typedArrayProto_values(call FunctionCall) Value {
+	return r.createArrayIterator(r.thisTypedArray(call.This, "values").val, iterationKindValue)
+}
+// 对每个元素调用callbackfn(value, index, array)，返回true时停止并返回该索引，否则返回-1
+func (r *Runtime) typedArrayFindIndex(a *typedArrayObject, call FunctionCall, fromEnd bool) int {
+	callbackFn := r.toCallbackFn(call.Argument(0))
+	fc := FunctionCall{
+		This:      call.Argument(1),
+		Arguments: []Value{nil, nil, a.val},
+	}
+	for k := 0; k < a.length; k++ {
+		r.useGas(1)
+		i := k
+		if fromEnd {
+			i = a.length - 1 - k
+		}
+		fc.Arguments[0] = a.getIdx(i)
+		fc.Arguments[1] = intToValue(int64(i))
+		if callbackFn(fc).ToBoolean() {
+			return i
+		}
+	}
+	return -1
+}
+// %TypedArray%.prototype.every实现
+func (r *Runtime) typedArrayProto_every(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "every")
+	callbackFn := r.toCallbackFn(call.Argument(0))
+	fc := FunctionCall{
+		This:      call.Argument(1),
+		Arguments: []Value{nil, nil, a.val},
+	}
+	for i := 0; i < a.length; i++ {
+		r.useGas(1)
+		fc.Arguments[0] = a.getIdx(i)
+		fc.Arguments[1] = intToValue(int64(i))
+		if !callbackFn(fc).ToBoolean() {
+			return valueFalse
+		}
+	}
+	return valueTrue
+}
+// %TypedArray%.prototype.some实现
+func (r *Runtime) typedArrayProto_some(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "some")
+	return r.toBoolean(r.typedArrayFindIndex(a, call, false) >= 0)
+}
+// %TypedArray%.prototype.find实现
+func (r *Runtime) typedArrayProto_find(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "find")
+	if i := r.typedArrayFindIndex(a, call, false); i >= 0 {
+		return a.getIdx(i)
+	}
+	return _undefined
+}
+// %TypedArray%.prototype.findIndex实现
+func (r *Runtime) typedArrayProto_findIndex(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "findIndex")
+	return intToValue(int64(r.typedArrayFindIndex(a, call, false)))
+}
+// %TypedArray%.prototype.fill实现
+func (r *Runtime) typedArrayProto_fill(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "fill")
+	value := r.toTypedArrayValue(a.kind, call.Argument(0))
+	start := relToIdx(call.Argument(1), a.length)
+	end := relToIdxDefault(call.Argument(2), a.length, a.length)
+	for i := start; i < end; i++ {
+		r.useGas(1)
+		a.setIdx(i, value)
+	}
+	return a.val
+}
+// %TypedArray%.prototype.filter实现
+func (r *Runtime) typedArrayProto_filter(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "filter")
+	callbackFn := r.toCallbackFn(call.Argument(0))
+	fc := FunctionCall{
+		This:      call.Argument(1),
+		Arguments: []Value{nil, nil, a.val},
+	}
+	var kept []Value
+	for i := 0; i < a.length; i++ {
+		r.useGas(1)
+		v := a.getIdx(i)
+		fc.Arguments[0] = v
+		fc.Arguments[1] = intToValue(int64(i))
+		if callbackFn(fc).ToBoolean() {
+			kept = append(kept, v)
+		}
+	}
+	ret := r.typedArraySpeciesCreate(a, len(kept))
+	for i, v := range kept {
+		ret.setIdx(i, v)
+	}
+	return ret.val
+}
+// %TypedArray%.prototype.forEach实现
+func (r *Runtime) typedArrayProto_forEach(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "forEach")
+	callbackFn := r.toCallbackFn(call.Argument(0))
+	fc := FunctionCall{
+		This:      call.Argument(1),
+		Arguments: []Value{nil, nil, a.val},
+	}
+	for i := 0; i < a.length; i++ {
+		r.useGas(1)
+		fc.Arguments[0] = a.getIdx(i)
+		fc.Arguments[1] = intToValue(int64(i))
+		callbackFn(fc)
+	}
+	return _undefined
+}
+// %TypedArray%.prototype.includes实现，使用SameValueZero比较
+func (r *Runtime) typedArrayProto_includes(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "includes")
+	search := call.Argument(0)
+	for i := relToIdx(call.Argument(1), a.length); i < a.length; i++ {
+		r.useGas(1)
+		if v := a.getIdx(i); v.StrictEquals(search) || isNaN(v) && isNaN(search) {
+			return valueTrue
+		}
+	}
+	return valueFalse
+}
+// 判断v是否为NaN
+func isNaN(v Value) bool {
+	f, ok := v.assertFloat()
+	return ok && math.IsNaN(f)
+}
+// %TypedArray%.prototype.indexOf实现
+func (r *Runtime) typedArrayProto_indexOf(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "indexOf")
+	search := call.Argument(0)
+	for i := relToIdx(call.Argument(1), a.length); i < a.length; i++ {
+		r.useGas(1)
+		if a.getIdx(i).StrictEquals(search) {
+			return intToValue(int64(i))
+		}
+	}
+	return intToValue(-1)
+}
+// %TypedArray%.prototype.lastIndexOf实现
+func (r *Runtime) typedArrayProto_lastIndexOf(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "lastIndexOf")
+	search := call.Argument(0)
+	from := int64(a.length - 1)
+	if len(call.Arguments) > 1 {
+		from = call.Arguments[1].ToInteger()
+		if from < 0 {
+			from += int64(a.length)
 		} else {
-			stop = l
+			from = min(from, int64(a.length-1))
+		}
+	}
+	for i := from; i >= 0; i-- {
+		r.useGas(1)
+		if a.getIdx(int(i)).StrictEquals(search) {
+			return intToValue(i)
 		}
+	}
+	return intToValue(-1)
+}
+// %TypedArray%.prototype.join实现
+func (r *Runtime) typedArrayProto_join(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "join")
+	sep := ","
+	if s := call.Argument(0); s != _undefined {
+		sep = s.String()
+	}
+	var buf bytes.Buffer
+	for i := 0; i < a.length; i++ {
+		r.useGas(1)
+		if i > 0 {
+			buf.WriteString(sep)
+		}
+		buf.WriteString(a.getIdx(i).String())
+	}
+	return newStringValue(buf.String())
+}
+// %TypedArray%.prototype.map实现
+func (r *Runtime) typedArrayProto_map(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "map")
+	callbackFn := r.toCallbackFn(call.Argument(0))
+	fc := FunctionCall{
+		This:      call.Argument(1),
+		Arguments: []Value{nil, nil, a.val},
+	}
+	ret := r.typedArraySpeciesCreate(a, a.length)
+	for i := 0; i < a.length; i++ {
+		r.useGas(1)
+		fc.Arguments[0] = a.getIdx(i)
+		fc.Arguments[1] = intToValue(int64(i))
+		ret.setIdx(i, r.toTypedArrayValue(ret.kind, callbackFn(fc)))
+	}
+	return ret.val
+}
+// reduce和reduceRight的共同实现
+func (r *Runtime) typedArrayReduce(a *typedArrayObject, call FunctionCall, fromEnd bool) Value {
+	callbackFn := r.toCallbackFn(call.Argument(0))
+	k := 0
+	var acc Value
+	if len(call.Arguments) > 1 {
+		acc = call.Arguments[1]
+	} else {
+		if a.length == 0 {
+			r.typeErrorResult(true, "Reduce of empty array with no initial value")
+		}
+		if fromEnd {
+			acc = a.getIdx(a.length - 1)
+		} else {
+			acc = a.getIdx(0)
+		}
+		k = 1
+	}
+	fc := FunctionCall{
+		This:      _undefined,
+		Arguments: []Value{nil, nil, nil, a.val},
+	}
+	for ; k < a.length; k++ {
+		r.useGas(1)
+		i := k
+		if fromEnd {
+			i = a.length - 1 - k
+		}
+		fc.Arguments[0] = acc
+		fc.Arguments[1] = a.getIdx(i)
+		fc.Arguments[2] = intToValue(int64(i))
+		acc = callbackFn(fc)
+	}
+	return acc
+}
+// %TypedArray%.prototype.reduce实现
+func (r *Runtime) typedArrayProto_reduce(call FunctionCall) Value {
+	return r.typedArrayReduce(r.thisTypedArray(call.This, "reduce"), call, false)
+}
+// %TypedArray%.prototype.reduceRight实现
+func (r *Runtime) typedArrayProto_reduceRight(call FunctionCall) Value {
+	return r.typedArrayReduce(r.thisTypedArray(call.This, "reduceRight"), call, true)
+}
+// %TypedArray%.prototype.reverse实现
+func (r *Runtime) typedArrayProto_reverse(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "reverse")
+	for i, j := 0, a.length-1; i < j; i, j = i+1, j-1 {
+		r.useGas(1)
+		a.swap(int64(i), int64(j))
+	}
+	return a.val
+}
+// %TypedArray%.prototype.set实现，source可以是类型化数组或类数组对象
+func (r *Runtime) typedArrayProto_set(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "set")
+	offset := call.Argument(1).ToInteger()
+	if offset < 0 {
+		panic(r.newError(r.global.RangeError, "offset is out of bounds"))
+	}
+	src := call.Argument(0).ToObject(r)
+	if s, ok := src.self.(*typedArrayObject); ok {
+		if offset+int64(s.length) > int64(a.length) {
+			panic(r.newError(r.global.RangeError, "offset is out of bounds"))
+		}
+		r.checkTypedArrayContentType(a.kind, s.kind)
+		if s.kind == a.kind {
+			// 同类型时直接复制字节，copy能正确处理同一buffer中重叠的区域
+			r.useGas(int64(s.length))
+			size := a.kind.size
+			copy(a.buffer.data[a.byteOffset+int(offset)*size:], s.buffer.data[s.byteOffset:s.byteOffset+s.length*size])
+			return _undefined
+		}
+		// 先读出所有值，避免共享buffer时读到已写入的数据
+		values := make([]Value, s.length)
+		for i := range values {
+			r.useGas(1)
+			values[i] = s.getIdx(i)
+		}
+		for i, v := range values {
+			a.setIdx(int(offset)+i, v)
+		}
+		return _undefined
+	}
+	l := toLength(src.self.getStr("length"))
+	if offset+l > int64(a.length) {
+		panic(r.newError(r.global.RangeError, "offset is out of bounds"))
+	}
+	for i := int64(0); i < l; i++ {
+		r.useGas(1)
+		a.setIdx(int(offset+i), r.toTypedArrayValue(a.kind, nilSafe(src.self.get(intToValue(i)))))
+	}
+	return _undefined
+}
+// %TypedArray%.prototype.slice实现，复制数据到新的buffer
+func (r *Runtime) typedArrayProto_slice(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "slice")
+	start := relToIdx(call.Argument(0), a.length)
+	end := relToIdxDefault(call.Argument(1), a.length, a.length)
+	count := 0
+	if end > start {
+		count = end - start
+	}
+	r.useGas(int64(count))
+	ret := r.typedArraySpeciesCreate(a, count)
+	size := a.kind.size
+	copy(ret.buffer.data, a.buffer.data[a.byteOffset+start*size:a.byteOffset+(start+count)*size])
+	return ret.val
+}
 
-		ret := r._newArrayBuffer(r.global.ArrayBufferPrototype, nil)
+// 类型化数组的排序
+type typedArraySortCtx struct {
+	r       *Runtime
+	a       *typedArrayObject
+	compare func(FunctionCall) Value
+}
 
-		if stop > start {
-			ret.data = b.data[start:stop]
-		}
+func (ctx *typedArraySortCtx) Len() int {
+	return ctx.a.length
+}
+
+func (ctx *typedArraySortCtx) Less(i, j int) bool {
+	ctx.r.useGas(1)
+	x, y := ctx.a.getIdx(i), ctx.a.getIdx(j)
+	if ctx.compare != nil {
+		f := ctx.compare(FunctionCall{
+			This:      _undefined,
+			Arguments: []Value{x, y},
+		}).ToFloat()
+		return f < 0
+	}
+	if xb, ok := x.(*valueBigInt); ok {
+		return (*big.Int)(xb).Cmp((*big.Int)(y.(*valueBigInt))) < 0
+	}
+	xf, yf := x.ToFloat(), y.ToFloat()
+	switch {
+	case math.IsNaN(xf):
+		return false
+	case math.IsNaN(yf):
+		return true
+	case xf == 0 && yf == 0:
+		return math.Signbit(xf) && !math.Signbit(yf)
+	}
+	return xf < yf
+}
 
-		return ret.val
+func (ctx *typedArraySortCtx) Swap(i, j int) {
+	ctx.a.swap(int64(i), int64(j))
+}
+// %TypedArray%.prototype.sort实现，默认按数值排序
+func (r *Runtime) typedArrayProto_sort(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "sort")
+	ctx := typedArraySortCtx{r: r, a: a}
+	if arg := call.Argument(0); arg != _undefined {
+		ctx.compare = r.toCallbackFn(arg)
 	}
-	r.typeErrorResult(true, "Object is not ArrayBuffer: %s", o)
-	panic("unreachable")
+	sort.Stable(&ctx)
+	return a.val
 }
-// 注入ArrayBuffer类
-func (r *Runtime) createArrayBufferProto(val *Object) objectImpl {
-	b := r._newArrayBuffer(r.global.Object, val)
-	byteLengthProp := &valueProperty{
+// %TypedArray%.prototype.subarray实现，返回共享同一buffer的视图
+func (r *Runtime) typedArrayProto_subarray(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "subarray")
+	begin := relToIdx(call.Argument(0), a.length)
+	end := relToIdxDefault(call.Argument(1), a.length, a.length)
+	count := 0
+	if end > begin {
+		count = end - begin
+	}
+	return r.newTypedArrayObject(a.kind, a.buffer, a.byteOffset+begin*a.kind.size, count, r.typedArrayProto(a.kind)).val
+}
+// %TypedArray%.prototype.toLocaleString实现
+func (r *Runtime) typedArrayProto_toLocaleString(call FunctionCall) Value {
+	a := r.thisTypedArray(call.This, "toLocaleString")
+	var buf bytes.Buffer
+	for i := 0; i < a.length; i++ {
+		r.useGas(1)
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		r.writeItemLocaleString(a.getIdx(i), &buf)
+	}
+	return newStringValue(buf.String())
+}
+// 取出this对应的DataView
+func (r *Runtime) thisDataView(v Value, method string) *dataViewObject {
+	if o, ok := v.(*Object); ok {
+		if d, ok := o.self.(*dataViewObject); ok {
+			return d
+		}
+	}
+	r.typeErrorResult(true, "Method DataView.prototype.%s called on incompatible receiver %s", method, v.String())
+	return nil
+}
+// DataView()不能作为普通函数调用
+func (r *Runtime) builtin_DataViewCall(call FunctionCall) Value {
+	r.typeErrorResult(true, "Constructor DataView requires 'new'")
+	return nil
+}
+// new DataView(buffer, byteOffset, byteLength)实现
+func (r *Runtime) builtin_DataView(args []Value, proto *Object) *Object {
+	var bufArg, offsetArg, lengthArg Value = _undefined, _undefined, _undefined
+	switch {
+	case len(args) > 2:
+		lengthArg = args[2]
+		fallthrough
+	case len(args) > 1:
+		offsetArg = args[1]
+		fallthrough
+	case len(args) > 0:
+		bufArg = args[0]
+	}
+	var buf *objectArrayBuffer
+	if o, ok := bufArg.(*Object); ok {
+		buf, _ = o.self.(*objectArrayBuffer)
+	}
+	if buf == nil {
+		r.typeErrorResult(true, "First argument to DataView constructor must be an ArrayBuffer")
+	}
+	byteOffset := r.toIndex(offsetArg, "Start offset is outside the bounds of the buffer")
+	bufLen := len(buf.data)
+	if byteOffset > bufLen {
+		panic(r.newError(r.global.RangeError, "Start offset %d is outside the bounds of the buffer", byteOffset))
+	}
+	byteLength := bufLen - byteOffset
+	if lengthArg != _undefined {
+		byteLength = r.toIndex(lengthArg, "Invalid DataView length")
+		if byteOffset+byteLength > bufLen {
+			panic(r.newError(r.global.RangeError, "Invalid DataView length %d", byteLength))
+		}
+	}
+	return r.newDataViewObject(buf, byteOffset, byteLength, proto).val
+}
+// DataView.prototype.buffer
+func (r *Runtime) dataViewProto_getBuffer(call FunctionCall) Value {
+	return r.thisDataView(call.This, "buffer").buffer.val
+}
+// DataView.prototype.byteLength
+func (r *Runtime) dataViewProto_getByteLength(call FunctionCall) Value {
+	return intToValue(int64(r.thisDataView(call.This, "byteLength").byteLength))
+}
+// DataView.prototype.byteOffset
+func (r *Runtime) dataViewProto_getByteOffset(call FunctionCall) Value {
+	return intToValue(int64(r.thisDataView(call.This, "byteOffset").byteOffset))
+}
+// DataView的字节序参数，默认为大端
+func dataViewByteOrder(littleEndian Value) binary.ByteOrder {
+	if littleEndian.ToBoolean() {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
+// 获取DataView中从requestIndex开始的size个字节
+func (r *Runtime) dataViewBytes(d *dataViewObject, requestIndex Value, size int) []byte {
+	idx := r.toIndex(requestIndex, "Offset is outside the bounds of the DataView")
+	if idx+size > d.byteLength {
+		panic(r.newError(r.global.RangeError, "Offset is outside the bounds of the DataView"))
+	}
+	offset := d.byteOffset + idx
+	return d.buffer.data[offset : offset+size]
+}
+// DataView.prototype.getXXX(byteOffset, littleEndian)的实现
+func (r *Runtime) dataViewGetter(kind *typedArrayKind) func(FunctionCall) Value {
+	method := "get" + strings.TrimSuffix(kind.name, "Array")
+	return func(call FunctionCall) Value {
+		d := r.thisDataView(call.This, method)
+		b := r.dataViewBytes(d, call.Argument(0), kind.size)
+		return kind.get(b, dataViewByteOrder(call.Argument(1)))
+	}
+}
+// DataView.prototype.setXXX(byteOffset, value, littleEndian)的实现
+func (r *Runtime) dataViewSetter(kind *typedArrayKind) func(FunctionCall) Value {
+	method := "set" + strings.TrimSuffix(kind.name, "Array")
+	return func(call FunctionCall) Value {
+		d := r.thisDataView(call.This, method)
+		idx := call.Argument(0)
+		value := r.toTypedArrayValue(kind, call.Argument(1))
+		b := r.dataViewBytes(d, idx, kind.size)
+		kind.set(b, value, dataViewByteOrder(call.Argument(2)))
+		return _undefined
+	}
+}
+// 创建只读的访问器属性
+func (r *Runtime) newGetterProp(getter func(FunctionCall) Value, name string) *valueProperty {
+	return &valueProperty{
 		accessor:     true,
 		configurable: true,
-		getterFunc:   r.newNativeFunc(r.arrayBufferProto_getByteLength, nil, "get byteLength", nil, 0),
+		getterFunc:   r.newNativeFunc(getter, nil, "get "+name, nil, 0),
 	}
-	b._put("byteLength", byteLengthProp)
-	b._putProp("slice", r.newNativeFunc(r.arrayBufferProto_slice, nil, "slice", nil, 2), true, false, true)
-	return b
 }
-//ArrayBuffer 对象用来表示通用的、固定长度的原始二进制数据缓冲区。
-//它是一个字节数组，通常在其他语言中称为“byte array”。
-func (r *Runtime) initTypedArrays() {
-
-	r.global.ArrayBufferPrototype = r.newLazyObject(r.createArrayBufferProto)
+// ArrayBuffer类实现
+func (r *Runtime) initArrayBuffer() {
+	o := r.newBaseObject(r.global.ObjectPrototype, classObject)
+	r.global.ArrayBufferPrototype = o.val
+	o._put("byteLength", r.newGetterProp(r.arrayBufferProto_getByteLength, "byteLength"))
+	o._putProp("slice", r.newNativeFunc(r.arrayBufferProto_slice, nil, "slice", nil, 2), true, false, true)
+	o._putSym(symToStringTag, asciiString(classArrayBuffer), false, false, true)
 
 	r.global.ArrayBuffer = r.newNativeFuncConstruct(r.builtin_ArrayBuffer, "ArrayBuffer", r.global.ArrayBufferPrototype, 1)
+	r.global.ArrayBuffer.self.(*nativeFuncObject).f = r.builtin_ArrayBufferCall
+	r.global.ArrayBuffer.self._putProp("isView", r.newNativeFunc(r.arrayBuffer_isView, nil, "isView", nil, 1), true, false, true)
 	r.addToGlobal("ArrayBuffer", r.global.ArrayBuffer)
 }
+// %TypedArray%及各个类型化数组类实现
+func (r *Runtime) initTypedArray() {
+	o := r.newBaseObject(r.global.ObjectPrototype, classObject)
+	r.global.TypedArrayPrototype = o.val
+	o._put("buffer", r.newGetterProp(r.typedArrayProto_getBuffer, "buffer"))
+	o._put("byteLength", r.newGetterProp(r.typedArrayProto_getByteLength, "byteLength"))
+	o._put("byteOffset", r.newGetterProp(r.typedArrayProto_getByteOffset, "byteOffset"))
+	o._put("length", r.newGetterProp(r.typedArrayProto_getLength, "length"))
+	o._putProp("copyWithin", r.newNativeFunc(r.typedArrayProto_copyWithin, nil, "copyWithin", nil, 2), true, false, true)
+	o._putProp("entries", r.newNativeFunc(r.typedArrayProto_entries, nil, "entries", nil, 0), true, false, true)
+	o._putProp("every", r.newNativeFunc(r.typedArrayProto_every, nil, "every", nil, 1), true, false, true)
+	o._putProp("fill", r.newNativeFunc(r.typedArrayProto_fill, nil, "fill", nil, 1), true, false, true)
+	o._putProp("filter", r.newNativeFunc(r.typedArrayProto_filter, nil, "filter", nil, 1), true, false, true)
+	o._putProp("find", r.newNativeFunc(r.typedArrayProto_find, nil, "find", nil, 1), true, false, true)
+	o._putProp("findIndex", r.newNativeFunc(r.typedArrayProto_findIndex, nil, "findIndex", nil, 1), true, false, true)
+	o._putProp("forEach", r.newNativeFunc(r.typedArrayProto_forEach, nil, "forEach", nil, 1), true, false, true)
+	o._putProp("includes", r.newNativeFunc(r.typedArrayProto_includes, nil, "includes", nil, 1), true, false, true)
+	o._putProp("indexOf", r.newNativeFunc(r.typedArrayProto_indexOf, nil, "indexOf", nil, 1), true, false, true)
+	o._putProp("join", r.newNativeFunc(r.typedArrayProto_join, nil, "join", nil, 1), true, false, true)
+	o._putProp("keys", r.newNativeFunc(r.typedArrayProto_keys, nil, "keys", nil, 0), true, false, true)
+	o._putProp("lastIndexOf", r.newNativeFunc(r.typedArrayProto_lastIndexOf, nil, "lastIndexOf", nil, 1), true, false, true)
+	o._putProp("map", r.newNativeFunc(r.typedArrayProto_map, nil, "map", nil, 1), true, false, true)
+	o._putProp("reduce", r.newNativeFunc(r.typedArrayProto_reduce, nil, "reduce", nil, 1), true, false, true)
+	o._putProp("reduceRight", r.newNativeFunc(r.typedArrayProto_reduceRight, nil, "reduceRight", nil, 1), true, false, true)
+	o._putProp("reverse", r.newNativeFunc(r.typedArrayProto_reverse, nil, "reverse", nil, 0), true, false, true)
+	o._putProp("set", r.newNativeFunc(r.typedArrayProto_set, nil, "set", nil, 1), true, false, true)
+	o._putProp("slice", r.newNativeFunc(r.typedArrayProto_slice, nil, "slice", nil, 2), true, false, true)
+	o._putProp("some", r.newNativeFunc(r.typedArrayProto_some, nil, "some", nil, 1), true, false, true)
+	o._putProp("sort", r.newNativeFunc(r.typedArrayProto_sort, nil, "sort", nil, 1), true, false, true)
+	o._putProp("subarray", r.newNativeFunc(r.typedArrayProto_subarray, nil, "subarray", nil, 2), true, false, true)
+	o._putProp("toLocaleString", r.newNativeFunc(r.typedArrayProto_toLocaleString, nil, "toLocaleString", nil, 0), true, false, true)
+	o._putProp("toString", r.global.ArrayPrototype.self.getStr("toString"), true, false, true)
+	values := r.newNativeFunc(r.typedArrayProto_values, nil, "values", nil, 0)
+	o._putProp("values", values, true, false, true)
+	o._putSym(symIterator, values, true, false, true)
+	o._putSymValue(symToStringTag, r.newGetterProp(r.typedArrayProto_getToStringTag, "[Symbol.toStringTag]"))
+
+	r.global.TypedArray = r.newNativeFunc(r.builtin_TypedArray, nil, "TypedArray", r.global.TypedArrayPrototype, 0)
+	c := r.global.TypedArray.self
+	c._putProp("from", r.newNativeFunc(r.typedArray_from, nil, "from", nil, 1), true, false, true)
+	c._putProp("of", r.newNativeFunc(r.typedArray_of, nil, "of", nil, 0), true, false, true)
+
+	r.global.typedArrayCtors = make(map[*typedArrayKind]*Object, len(typedArrayKinds))
+	for _, kind := range typedArrayKinds {
+		proto := r.newBaseObject(r.global.TypedArrayPrototype, classObject)
+		bytesPerElement := intToValue(int64(kind.size))
+		proto._putProp("BYTES_PER_ELEMENT", bytesPerElement, false, false, false)
+
+		c := r.newNativeFuncConstructProto(r.typedArrayConstructor(kind), kind.name, proto.val, r.global.TypedArray, 3)
+		name := kind.name
+		c.self.(*nativeFuncObject).f = func(FunctionCall) Value {
+			r.typeErrorResult(true, "Constructor %s requires 'new'", name)
+			return nil
+		}
+		c.self._putProp("BYTES_PER_ELEMENT", bytesPerElement, false, false, false)
+		r.global.typedArrayCtors[kind] = c
+		r.addToGlobal(kind.name, c)
+	}
+}
+// DataView类实现
+func (r *Runtime) initDataView() {
+	o := r.newBaseObject(r.global.ObjectPrototype, classObject)
+	r.global.DataViewPrototype = o.val
+	o._put("buffer", r.newGetterProp(r.dataViewProto_getBuffer, "buffer"))
+	o._put("byteLength", r.newGetterProp(r.dataViewProto_getByteLength, "byteLength"))
+	o._put("byteOffset", r.newGetterProp(r.dataViewProto_getByteOffset, "byteOffset"))
+	for _, kind := range typedArrayKinds {
+		if kind == typedArrayUint8Clamped {
+			continue
+		}
+		typ := strings.TrimSuffix(kind.name, "Array")
+		o._putProp("get"+typ, r.newNativeFunc(r.dataViewGetter(kind), nil, "get"+typ, nil, 1), true, false, true)
+		o._putProp("set"+typ, r.newNativeFunc(r.dataViewSetter(kind), nil, "set"+typ, nil, 2), true, false, true)
+	}
+	o._putSym(symToStringTag, asciiString(classDataView), false, false, true)
+
+	r.global.DataView = r.newNativeFuncConstruct(r.builtin_DataView, classDataView, r.global.DataViewPrototype, 3)
+	r.global.DataView.self.(*nativeFuncObject).f = r.builtin_DataViewCall
+	r.addToGlobal("DataView", r.global.DataView)
+}
+//ArrayBuffer 对象用来表示通用的、固定长度的原始二进制数据缓冲区。
+//它是一个字节数组，通常在其他语言中称为“byte array”。
+func (r *Runtime) initTypedArrays() {
+	r.initArrayBuffer()
+	r.initTypedArray()
+	r.initDataView()
+}
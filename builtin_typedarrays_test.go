@@ -1,6 +1,10 @@
 package goja
 
-/*
+import (
+	"bytes"
+	"testing"
+)
+
 func TestArrayBufferNew(t *testing.T) {
 	const SCRIPT = `
 	var b = new ArrayBuffer(16);
@@ -9,4 +13,239 @@ func TestArrayBufferNew(t *testing.T) {
 
 	testScript1(SCRIPT, intToValue(16), t)
 }
-*/
+
+func TestArrayBufferSlice(t *testing.T) {
+	const SCRIPT = `
+	var b = new ArrayBuffer(8);
+	var u = new Uint8Array(b);
+	u[2] = 7;
+	var s = b.slice(2, -2);
+	assert.sameValue(s.byteLength, 4, "byteLength");
+	assert.sameValue(new Uint8Array(s)[0], 7, "copied");
+	new Uint8Array(s)[0] = 1;
+	assert.sameValue(u[2], 7, "not shared");
+	assert.sameValue(ArrayBuffer.isView(u), true, "isView");
+	assert.sameValue(ArrayBuffer.isView(b), false, "isView buffer");
+	assert.sameValue(Object.prototype.toString.call(b), "[object ArrayBuffer]");
+	assert.throws(TypeError, function() { ArrayBuffer(1); });
+	assert.throws(RangeError, function() { new ArrayBuffer(-1); });
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestTypedArraySharedBuffer(t *testing.T) {
+	const SCRIPT = `
+	var b = new ArrayBuffer(8);
+	var u8 = new Uint8Array(b);
+	var u32 = new Uint32Array(b, 4, 1);
+	var i16 = new Int16Array(b);
+	u32[0] = 0x01020304;
+	assert.sameValue(u8[4], 4, "little endian");
+	assert.sameValue(u8[7], 1);
+	i16[0] = -2;
+	assert.sameValue(u8[0], 0xfe);
+	assert.sameValue(u8[1], 0xff);
+	var sub = u8.subarray(4, 6);
+	sub[0] = 9;
+	assert.sameValue(u32[0], 0x01020309, "subarray shares buffer");
+	assert.sameValue(sub.byteOffset, 4);
+	assert.sameValue(sub.buffer, b);
+	assert.throws(RangeError, function() { new Int32Array(b, 2); });
+	assert.throws(RangeError, function() { new Int32Array(b, 4, 2); });
+	assert.throws(TypeError, function() { Uint8Array(1); });
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestTypedArrayConversions(t *testing.T) {
+	const SCRIPT = `
+	var c = new Uint8ClampedArray([300, -5, 1.5, 2.5, NaN]);
+	assert.sameValue(c.join(), "255,0,2,2,0", "clamped");
+	var u = new Uint8Array([256, -1, 1.9]);
+	assert.sameValue(u.join(), "0,255,1", "wrapped");
+	var i8 = new Int8Array([128, 255]);
+	assert.sameValue(i8.join(), "-128,-1");
+	var f = new Float32Array([1.1]);
+	assert.sameValue(f[0], 1.100000023841858, "float32 precision");
+	var f64 = new Float64Array(new Set([0.5, 1.5]));
+	assert.sameValue(f64.length, 2, "from iterable");
+	assert.sameValue(f64[1], 1.5);
+	var i32 = new Int32Array(u);
+	assert.sameValue(i32.join(), "0,255,1", "from typed array");
+	assert.sameValue(Int16Array.BYTES_PER_ELEMENT, 2);
+	assert.sameValue(Float64Array.prototype.BYTES_PER_ELEMENT, 8);
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestTypedArrayProps(t *testing.T) {
+	const SCRIPT = `
+	var a = new Int16Array(2);
+	a[5] = 1;
+	a.foo = 1;
+	assert.sameValue(a[5], undefined, "out of range");
+	assert.sameValue(a["-0"], undefined, "canonical numeric");
+	assert.sameValue(a.foo, 1);
+	assert.sameValue(Object.keys(a).join(), "0,1,foo");
+	assert.sameValue(1 in a, true);
+	assert.sameValue(2 in a, false);
+	assert.sameValue(a.length, 2);
+	assert.sameValue(a[Symbol.toStringTag], "Int16Array");
+	assert.sameValue(Object.getPrototypeOf(Int16Array), Object.getPrototypeOf(Uint8Array), "%TypedArray%");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestTypedArrayMethods(t *testing.T) {
+	const SCRIPT = `
+	var a = new Int32Array([5, -1, 10, 3]);
+	assert.sameValue(a.slice().sort().join(), "-1,3,5,10", "numeric sort");
+	assert.sameValue(a.map(function(x) { return x * 2; }).join(), "10,-2,20,6");
+	assert.sameValue(a.filter(function(x) { return x > 0; }) instanceof Int32Array, true);
+	assert.sameValue(a.reduce(function(acc, x) { return acc + x; }), 17);
+	assert.sameValue(a.indexOf(10), 2);
+	assert.sameValue(a.includes(3), true);
+	assert.sameValue(a.find(function(x) { return x > 6; }), 10);
+	assert.sameValue(a.findIndex(function(x) { return x < 0; }), 1);
+	var s = "";
+	for (var v of a) {
+		s += v + ";";
+	}
+	assert.sameValue(s, "5;-1;10;3;");
+	var e = [];
+	for (var kv of a.entries()) {
+		e.push(kv.join());
+	}
+	assert.sameValue(e.join("|"), "0,5|1,-1|2,10|3,3");
+	a.set([7, 8], 2);
+	assert.sameValue(a.join(), "5,-1,7,8");
+	a.copyWithin(0, 2);
+	assert.sameValue(a.join(), "7,8,7,8");
+	a.fill(0, 1, 3);
+	assert.sameValue(a.toString(), "7,0,0,8");
+	assert.sameValue(a.reverse().join(), "8,0,0,7");
+	assert.throws(RangeError, function() { a.set([1, 2], 3); });
+	assert.sameValue(Uint8Array.from([1, 2], function(x) { return x * 3; }).join(), "3,6");
+	assert.sameValue(Float32Array.of(1, 2.5).join(), "1,2.5");
+	assert.throws(TypeError, function() { Int8Array.prototype.join.call([]); });
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestDataView(t *testing.T) {
+	const SCRIPT = `
+	var b = new ArrayBuffer(8);
+	var dv = new DataView(b, 2);
+	assert.sameValue(dv.byteLength, 6);
+	assert.sameValue(dv.byteOffset, 2);
+	dv.setUint16(0, 0x0102);
+	var u8 = new Uint8Array(b);
+	assert.sameValue(u8[2], 1, "big endian by default");
+	assert.sameValue(u8[3], 2);
+	dv.setUint16(0, 0x0102, true);
+	assert.sameValue(u8[2], 2, "little endian");
+	assert.sameValue(dv.getUint16(0), 0x0201);
+	assert.sameValue(dv.getUint16(0, true), 0x0102);
+	assert.throws(RangeError, function() { dv.setFloat64(0, 1.5); }, "out of bounds");
+	assert.throws(RangeError, function() { dv.getInt32(3); }, "out of bounds");
+	var full = new DataView(b);
+	full.setFloat64(0, 1.5);
+	assert.sameValue(full.getFloat64(0), 1.5);
+	dv.setFloat32(2, -2.5, true);
+	assert.sameValue(dv.getFloat32(2, true), -2.5);
+	dv.setInt8(5, -1);
+	assert.sameValue(dv.getUint8(5), 255);
+	dv.setInt32(0, -2);
+	assert.sameValue(dv.getUint32(0), 0xfffffffe);
+	assert.throws(RangeError, function() { new DataView(b, 9); });
+	assert.throws(TypeError, function() { new DataView({}); });
+	assert.sameValue(Object.prototype.toString.call(dv), "[object DataView]");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestTypedArrayGoBytes(t *testing.T) {
+	vm := New()
+	data := []byte{1, 2, 3, 4}
+	vm.Set("data", data)
+	buf := vm.NewArrayBuffer(make([]byte, 4))
+	vm.Set("buf", buf)
+	v, err := vm.RunString(`
+	if (!(data instanceof Uint8Array)) {
+		throw new Error("not a Uint8Array");
+	}
+	data[0] = 10;
+	new DataView(buf).setUint32(0, 0x01020304);
+	data;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[0] != 10 {
+		t.Fatalf("data[0] = %d, expected 10", data[0])
+	}
+	if b := buf.Bytes(); b[0] != 1 || b[3] != 4 {
+		t.Fatalf("unexpected buffer contents: %v", b)
+	}
+	exported, ok := v.Export().([]byte)
+	if !ok {
+		t.Fatalf("unexpected export type: %T", v.Export())
+	}
+	exported[1] = 20
+	if data[1] != 20 {
+		t.Fatal("exported slice does not share memory")
+	}
+	data[2] = 30
+	if v, _ := vm.RunString("data[2]"); v.ToInteger() != 30 {
+		t.Fatalf("data[2] = %v, expected 30", v)
+	}
+}
+
+func TestTypedArrayGoBytesRoundTrip(t *testing.T) {
+	type S struct {
+		Data []byte
+	}
+	vm := New()
+	orig := []byte{1, 2, 3}
+	s := &S{Data: orig}
+	vm.Set("s", s)
+	var got [][]byte
+	vm.Set("f", func(b []byte) int {
+		got = append(got, b)
+		return len(b)
+	})
+	_, err := vm.RunString(`
+	if (!(s.Data instanceof Uint8Array)) {
+		throw new Error("field is not a Uint8Array");
+	}
+	s.Data[0] = 10;
+	var u = new Uint8Array([4, 5, 6, 7]);
+	if (f(u) !== 4 || f(u.buffer) !== 4) {
+		throw new Error("unexpected length");
+	}
+	s.Data = u.subarray(1, 3);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if orig[0] != 10 {
+		t.Fatalf("field does not share memory: %v", orig)
+	}
+	if len(got) != 2 || !bytes.Equal(got[0], []byte{4, 5, 6, 7}) || !bytes.Equal(got[1], []byte{4, 5, 6, 7}) {
+		t.Fatalf("unexpected arguments: %v", got)
+	}
+	got[1][0] = 40
+	if !bytes.Equal(s.Data, []byte{5, 6}) {
+		t.Fatalf("unexpected field value: %v", s.Data)
+	}
+	s.Data[0] = 50
+	if v, _ := vm.RunString("u[0] * 1000 + u[1]"); v.ToInteger() != 40050 {
+		t.Fatalf("memory is not shared: %v", v)
+	}
+}
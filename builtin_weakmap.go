@@ -0,0 +1,137 @@
+package goja
+
+// WeakMap和WeakSet的标识。数据保存在作为键的对象中(Object.weakRefs)，集合本身不引用键，
+// 因此键对象被回收时对应的值也随之释放
+type weakMap uint64
+
+// WeakMap对象
+type weakMapObject struct {
+	baseObject
+	m weakMap
+}
+// 分配新的WeakMap/WeakSet标识
+func (r *Runtime) newWeakMap() weakMap {
+	r.lastWeakMap++
+	return r.lastWeakMap
+}
+// 取值，不存在时返回nil
+func (m weakMap) get(key *Object) Value {
+	return key.weakRefs[m]
+}
+// 判断键是否存在
+func (m weakMap) has(key *Object) bool {
+	_, exists := key.weakRefs[m]
+	return exists
+}
+// 设置值，新的项计入键所在运行时的内存统计
+func (m weakMap) set(key *Object, value Value) {
+	if key.weakRefs == nil {
+		key.weakRefs = make(map[weakMap]Value)
+	}
+	if _, exists := key.weakRefs[m]; !exists {
+		key.runtime.allocate(sizeofMapEntry)
+	}
+	key.weakRefs[m] = value
+}
+// 删除键，返回键是否存在
+func (m weakMap) remove(key *Object) bool {
+	if _, exists := key.weakRefs[m]; exists {
+		delete(key.weakRefs, m)
+		return true
+	}
+	return false
+}
+// 创建空的WeakMap对象
+func (r *Runtime) newWeakMapObject(proto *Object) *weakMapObject {
+	o := &Object{runtime: r}
+
+	wmo := &weakMapObject{
+		m: r.newWeakMap(),
+	}
+	wmo.class = classWeakMap
+	wmo.val = o
+	wmo.extensible = true
+	o.self = wmo
+	wmo.prototype = proto
+	wmo.init()
+	return wmo
+}
+// 取出this对应的WeakMap
+func (r *Runtime) thisWeakMap(v Value, method string) *weakMapObject {
+	if o, ok := v.(*Object); ok {
+		if wmo, ok := o.self.(*weakMapObject); ok {
+			return wmo
+		}
+	}
+	r.typeErrorResult(true, "Method WeakMap.prototype.%s called on incompatible receiver %s", method, v.String())
+	return nil
+}
+// WeakMap()不能作为普通函数调用
+func (r *Runtime) builtin_WeakMap(call FunctionCall) Value {
+	r.typeErrorResult(true, "Constructor WeakMap requires 'new'")
+	return nil
+}
+// new WeakMap(iterable)实现
+func (r *Runtime) builtin_newWeakMap(args []Value) *Object {
+	wmo := r.newWeakMapObject(r.global.WeakMapPrototype)
+	if len(args) > 0 && args[0] != _undefined && args[0] != _null {
+		adder := r.toCallable(wmo.getStr("set"))
+		r.iterateClose(args[0], func(item Value) {
+			itemObj, ok := item.(*Object)
+			if !ok {
+				r.typeErrorResult(true, "Iterator value %s is not an entry object", item.String())
+			}
+			k := nilSafe(itemObj.self.get(intToValue(0)))
+			v := nilSafe(itemObj.self.get(intToValue(1)))
+			adder(FunctionCall{This: wmo.val, Arguments: []Value{k, v}})
+		})
+	}
+	return wmo.val
+}
+// WeakMap.prototype.delete实现
+func (r *Runtime) weakMapProto_delete(call FunctionCall) Value {
+	wmo := r.thisWeakMap(call.This, "delete")
+	if key, ok := call.Argument(0).(*Object); ok {
+		return r.toBoolean(wmo.m.remove(key))
+	}
+	return valueFalse
+}
+// WeakMap.prototype.get实现
+func (r *Runtime) weakMapProto_get(call FunctionCall) Value {
+	wmo := r.thisWeakMap(call.This, "get")
+	if key, ok := call.Argument(0).(*Object); ok {
+		return nilSafe(wmo.m.get(key))
+	}
+	return _undefined
+}
+// WeakMap.prototype.has实现
+func (r *Runtime) weakMapProto_has(call FunctionCall) Value {
+	wmo := r.thisWeakMap(call.This, "has")
+	if key, ok := call.Argument(0).(*Object); ok {
+		return r.toBoolean(wmo.m.has(key))
+	}
+	return valueFalse
+}
+// WeakMap.prototype.set实现，键必须是对象
+func (r *Runtime) weakMapProto_set(call FunctionCall) Value {
+	wmo := r.thisWeakMap(call.This, "set")
+	key, ok := call.Argument(0).(*Object)
+	if !ok {
+		r.typeErrorResult(true, "Invalid value used as weak map key")
+	}
+	wmo.m.set(key, call.Argument(1))
+	return call.This
+}
+// WeakMap类实现
+func (r *Runtime) initWeakMap() {
+	r.global.WeakMapPrototype = r.newBaseObject(r.global.ObjectPrototype, classObject).val
+	o := r.global.WeakMapPrototype.self
+	o._putProp("delete", r.newNativeFunc(r.weakMapProto_delete, nil, "delete", nil, 1), true, false, true)
+	o._putProp("get", r.newNativeFunc(r.weakMapProto_get, nil, "get", nil, 1), true, false, true)
+	o._putProp("has", r.newNativeFunc(r.weakMapProto_has, nil, "has", nil, 1), true, false, true)
+	o._putProp("set", r.newNativeFunc(r.weakMapProto_set, nil, "set", nil, 2), true, false, true)
+	o._putSym(symToStringTag, asciiString(classWeakMap), false, false, true)
+
+	r.global.WeakMap = r.newNativeFunc(r.builtin_WeakMap, r.builtin_newWeakMap, "WeakMap", r.global.WeakMapPrototype, 0)
+	r.addToGlobal("WeakMap", r.global.WeakMap)
+}
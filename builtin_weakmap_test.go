@@ -0,0 +1,56 @@
+package goja
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestWeakMap(t *testing.T) {
+	const SCRIPT = `
+	var k1 = {}, k2 = function() {};
+	var wm = new WeakMap([[k1, 1]]);
+	assert.sameValue(wm.get(k1), 1, "get");
+	assert.sameValue(wm.set(k2, 2), wm, "set returns this");
+	assert.sameValue(wm.has(k2), true, "has");
+	assert.sameValue(wm.has({}), false, "other object");
+	assert.sameValue(wm.get(1), undefined, "primitive key");
+	assert.sameValue(new WeakMap().has(k1), false, "separate maps");
+	assert.sameValue(wm.delete(k1), true, "delete");
+	assert.sameValue(wm.has(k1), false, "deleted");
+	assert.sameValue(wm.delete(1), false, "delete primitive");
+	assert.throws(TypeError, function() { wm.set(1, 1); }, "invalid key");
+	assert.throws(TypeError, function() { WeakMap(); }, "call");
+	assert.sameValue(Object.prototype.toString.call(wm), "[object WeakMap]", "toStringTag");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestWeakMapKeyNotRetained(t *testing.T) {
+	vm := New()
+	_, err := vm.RunString(`
+	var wm = new WeakMap();
+	(function() {
+		wm.set({}, new Array(100));
+	})();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wmo := vm.Get("wm").(*Object).self.(*weakMapObject)
+	key := &Object{runtime: vm}
+	collected := make(chan struct{})
+	runtime.SetFinalizer(key, func(*Object) {
+		close(collected)
+	})
+	wmo.m.set(key, valueTrue)
+	key = nil
+	for i := 0; i < 10; i++ {
+		runtime.GC()
+		select {
+		case <-collected:
+			return
+		default:
+		}
+	}
+	t.Fatal("WeakMap key was not collected")
+}
@@ -0,0 +1,86 @@
+package goja
+
+// WeakSet对象，与WeakMap一样把数据保存在值对象中
+type weakSetObject struct {
+	baseObject
+	s weakMap
+}
+// 创建空的WeakSet对象
+func (r *Runtime) newWeakSetObject(proto *Object) *weakSetObject {
+	o := &Object{runtime: r}
+
+	wso := &weakSetObject{
+		s: r.newWeakMap(),
+	}
+	wso.class = classWeakSet
+	wso.val = o
+	wso.extensible = true
+	o.self = wso
+	wso.prototype = proto
+	wso.init()
+	return wso
+}
+// 取出this对应的WeakSet
+func (r *Runtime) thisWeakSet(v Value, method string) *weakSetObject {
+	if o, ok := v.(*Object); ok {
+		if wso, ok := o.self.(*weakSetObject); ok {
+			return wso
+		}
+	}
+	r.typeErrorResult(true, "Method WeakSet.prototype.%s called on incompatible receiver %s", method, v.String())
+	return nil
+}
+// WeakSet()不能作为普通函数调用
+func (r *Runtime) builtin_WeakSet(call FunctionCall) Value {
+	r.typeErrorResult(true, "Constructor WeakSet requires 'new'")
+	return nil
+}
+// new WeakSet(iterable)实现
+func (r *Runtime) builtin_newWeakSet(args []Value) *Object {
+	wso := r.newWeakSetObject(r.global.WeakSetPrototype)
+	if len(args) > 0 && args[0] != _undefined && args[0] != _null {
+		adder := r.toCallable(wso.getStr("add"))
+		r.iterateClose(args[0], func(item Value) {
+			adder(FunctionCall{This: wso.val, Arguments: []Value{item}})
+		})
+	}
+	return wso.val
+}
+// WeakSet.prototype.add实现，值必须是对象
+func (r *Runtime) weakSetProto_add(call FunctionCall) Value {
+	wso := r.thisWeakSet(call.This, "add")
+	value, ok := call.Argument(0).(*Object)
+	if !ok {
+		r.typeErrorResult(true, "Invalid value used in weak set")
+	}
+	wso.s.set(value, valueTrue)
+	return call.This
+}
+// WeakSet.prototype.delete实现
+func (r *Runtime) weakSetProto_delete(call FunctionCall) Value {
+	wso := r.thisWeakSet(call.This, "delete")
+	if value, ok := call.Argument(0).(*Object); ok {
+		return r.toBoolean(wso.s.remove(value))
+	}
+	return valueFalse
+}
+// WeakSet.prototype.has实现
+func (r *Runtime) weakSetProto_has(call FunctionCall) Value {
+	wso := r.thisWeakSet(call.This, "has")
+	if value, ok := call.Argument(0).(*Object); ok {
+		return r.toBoolean(wso.s.has(value))
+	}
+	return valueFalse
+}
+// WeakSet类实现
+func (r *Runtime) initWeakSet() {
+	r.global.WeakSetPrototype = r.newBaseObject(r.global.ObjectPrototype, classObject).val
+	o := r.global.WeakSetPrototype.self
+	o._putProp("add", r.newNativeFunc(r.weakSetProto_add, nil, "add", nil, 1), true, false, true)
+	o._putProp("delete", r.newNativeFunc(r.weakSetProto_delete, nil, "delete", nil, 1), true, false, true)
+	o._putProp("has", r.newNativeFunc(r.weakSetProto_has, nil, "has", nil, 1), true, false, true)
+	o._putSym(symToStringTag, asciiString(classWeakSet), false, false, true)
+
+	r.global.WeakSet = r.newNativeFunc(r.builtin_WeakSet, r.builtin_newWeakSet, "WeakSet", r.global.WeakSetPrototype, 0)
+	r.addToGlobal("WeakSet", r.global.WeakSet)
+}
@@ -0,0 +1,21 @@
+package goja
+
+import "testing"
+
+func TestWeakSet(t *testing.T) {
+	const SCRIPT = `
+	var o1 = {}, o2 = {};
+	var ws = new WeakSet([o1]);
+	assert.sameValue(ws.has(o1), true, "has");
+	assert.sameValue(ws.has(o2), false, "has missing");
+	assert.sameValue(ws.add(o2), ws, "add returns this");
+	assert.sameValue(ws.has(o2), true, "added");
+	assert.sameValue(ws.delete(o1), true, "delete");
+	assert.sameValue(ws.has(o1), false, "deleted");
+	assert.sameValue(ws.has(1), false, "primitive");
+	assert.throws(TypeError, function() { ws.add(1); }, "invalid value");
+	assert.throws(TypeError, function() { new WeakSet([1]); }, "invalid value in iterable");
+	assert.sameValue(Object.prototype.toString.call(ws), "[object WeakSet]", "toStringTag");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
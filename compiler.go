@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/oracle3/goja/ast"
 	"github.com/oracle3/goja/file"
+	"github.com/oracle3/goja/token"
 	"sort"
 	"strconv"
 )
@@ -15,6 +16,7 @@ const (
 	blockBranch
 	blockSwitch
 	blockWith
+	blockScope
 )
 
 type CompilerError struct {
@@ -66,6 +68,24 @@ type scope struct {
 	accessed   bool
 	argsNeeded bool
 	thisNeeded bool
+	// let/const的块级作用域
+	block bool
+	// 箭头函数的作用域，没有自己的arguments
+	arrow bool
+	// 类的方法或构造函数的作用域，可以使用super.x
+	method bool
+	// 派生类构造函数的作用域，可以调用super()
+	derived bool
+	// 模块代码的作用域，没有arguments
+	module bool
+	// 作用域中的let/const名称，值为true表示const
+	lexNames map[string]bool
+	// 块级作用域中声明的函数名称
+	blockFuncs map[string]bool
+	// 按附录B为块中的函数声明绑定的var名称
+	funcVars map[string]bool
+	// 参数列表有表达式时函数体的var声明在单独的作用域中，params为外层的参数名称
+	params map[string]bool
 
 	namesMap    map[string]string
 	lastFreeTmp int
@@ -78,6 +98,8 @@ type block struct {
 	cont       int
 	breaks     []int
 	conts      []int
+	exits      []int // 跳出块级作用域的占位指令
+	copies     []int // for循环进入下一次迭代时复制绑定的占位指令
 	outer      *block
 }
 // 跳出当前语句块
@@ -171,7 +193,7 @@ func (p *Program) sourceOffset(pc int) int {
 }
 
 func (s *scope) isFunction() bool {
-	if !s.lexical {
+	if !s.lexical && !s.block {
 		return s.outer != nil
 	}
 	return s.outer.isFunction()
@@ -180,8 +202,12 @@ func (s *scope) isFunction() bool {
 func (s *scope) lookupName(name string) (idx uint32, found, noDynamics bool) {
 	var level uint32 = 0
 	noDynamics = true
+	// 只经过了块级作用域时仍在同一个函数内，不需要标记为accessed
+	blocksOnly := true
+	// 箭头函数中的arguments是外层函数的arguments
+	argsLookup := true
 	for curScope := s; curScope != nil; curScope = curScope.outer {
-		if curScope != s {
+		if curScope != s && !blocksOnly {
 			curScope.accessed = true
 		}
 		if curScope.dynamic {
@@ -199,17 +225,37 @@ func (s *scope) lookupName(name string) (idx uint32, found, noDynamics bool) {
 				return
 			}
 		}
-		if name == "arguments" && !s.lexical && s.isFunction() {
-			s.argsNeeded = true
-			s.accessed = true
-			idx, _ = s.bindName(name)
+		if name == "arguments" && argsLookup && !curScope.lexical && !curScope.block && !curScope.arrow && !curScope.module && curScope.outer != nil {
+			curScope.argsNeeded = true
+			curScope.accessed = true
+			idx, _ = curScope.bindName(name)
+			idx |= level << 24
 			found = true
 			return
 		}
+		blocksOnly = blocksOnly && curScope.block
+		argsLookup = argsLookup && (curScope.block || curScope.arrow)
 		level++
 	}
 	return
 }
+// 查找名称对应的绑定是否为let/const
+func (s *scope) lookupLexical(name string) (lexical, isConst bool) {
+	for curScope := s; curScope != nil; curScope = curScope.outer {
+		if curScope.dynamic {
+			continue
+		}
+		mapped := name
+		if m, exists := curScope.namesMap[name]; exists {
+			mapped = m
+		}
+		if _, exists := curScope.names[mapped]; exists {
+			isConst, lexical = curScope.lexNames[mapped]
+			return
+		}
+	}
+	return
+}
 // 记录名称
 func (s *scope) bindName(name string) (uint32, bool) {
 	if s.lexical {
@@ -224,6 +270,19 @@ func (s *scope) bindName(name string) (uint32, bool) {
 	return idx, true
 }
 
+// 在当前作用域中分配一个临时名称
+func (s *scope) bindTmp() uint32 {
+	for {
+		name := " __tmp" + strconv.Itoa(s.lastFreeTmp)
+		s.lastFreeTmp++
+		if _, exists := s.names[name]; !exists {
+			idx := uint32(len(s.names))
+			s.names[name] = idx
+			return idx
+		}
+	}
+}
+
 func (s *scope) bindNameShadow(name string) (uint32, bool) {
 	if s.lexical {
 		return s.outer.bindName(name)
@@ -256,15 +315,32 @@ func (c *compiler) compile(in *ast.Program) {
 		}
 	}
 
-	c.compileDeclList(in.DeclarationList, false)
-	c.compileFunctions(in.DeclarationList)
+	hoisted, nested := c.splitDeclList(in.DeclarationList, in.Body)
+	decls := c.lexicalDeclarations(in.Body)
+	c.bindBlockFunctionVars(nested, decls)
+	c.compileDeclList(hoisted, false)
+
+	var globalLex *bindGlobalLex
+	scopeStart := -1
+	if len(decls) > 0 {
+		if c.scope.eval {
+			// eval中的let/const只在eval代码中可见
+			scopeStart = c.openBlockScope(decls)
+		} else {
+			globalLex = c.compileGlobalLexicals(decls)
+		}
+	}
+	c.compileFunctions(hoisted)
 
 	c.markBlockStart()
 	c.compileStatements(in.Body, true)
+	if scopeStart != -1 {
+		c.closeBlockScope(scopeStart)
+	}
 
 	c.p.code = append(c.p.code, halt)
 	code := c.p.code
-	c.p.code = make([]instruction, 0, len(code)+len(c.scope.names)+2)
+	c.p.code = make([]instruction, 0, len(code)+len(c.scope.names)+3)
 	if c.scope.eval {
 		if !c.scope.strict {
 			c.emit(jne(2), newStash)
@@ -272,6 +348,18 @@ func (c *compiler) compile(in *ast.Program) {
 			c.emit(pop, newStash)
 		}
 	}
+	prologueLen := len(c.scope.names)
+	if globalLex == nil && !c.scope.eval && len(c.scope.names) > 0 {
+		// 检查var和函数声明是否与之前脚本中的let/const冲突
+		globalLex = &bindGlobalLex{}
+	}
+	if globalLex != nil {
+		for name := range c.scope.names {
+			globalLex.vars = append(globalLex.vars, name)
+		}
+		c.emit(globalLex)
+		prologueLen++
+	}
 	l := len(c.p.code)
 	c.p.code = c.p.code[:l+len(c.scope.names)]
 	for name, nameIdx := range c.scope.names {
@@ -280,9 +368,217 @@ func (c *compiler) compile(in *ast.Program) {
 
 	c.p.code = append(c.p.code, code...)
 	for i := range c.p.srcMap {
-		c.p.srcMap[i].pc += len(c.scope.names)
+		c.p.srcMap[i].pc += prologueLen
+	}
+
+}
+// 编译模块。模块代码编译为严格模式的生成器函数，实例化时执行到函数体开始处暂停，求值时再恢复执行
+func (c *compiler) compileModule(in *ast.Program) (*moduleInfo, *newFunc) {
+	c.p.src = NewSrcFile(in.File.Name(), in.File.Source(), in.SourceMap)
+	c.scope.strict = true
+
+	info := c.compileModuleInfo(in)
+	base := in.File.Base()
+	f := &compiledFunctionLiteral{
+		expr: &ast.FunctionLiteral{
+			Function:      file.Idx(base),
+			ParameterList: &ast.ParameterList{},
+			Body: &ast.BlockStatement{
+				LeftBrace:  file.Idx(base),
+				List:       in.Body,
+				RightBrace: file.Idx(base - 1 + len(in.File.Source())),
+			},
+			Generator:       true,
+			DeclarationList: in.DeclarationList,
+		},
+		isExpr: true,
+		module: true,
+	}
+	f.init(c, file.Idx(base))
+	f.emitGetter(true)
+	return info, c.p.code[len(c.p.code)-1].(*newFunc)
+}
+// 收集模块的导入和导出项，并检查重复的导入、导出以及导出未声明的名称
+func (c *compiler) compileModuleInfo(in *ast.Program) *moduleInfo {
+	info := &moduleInfo{}
+	requested := make(map[string]bool)
+	addRequest := func(specifier string) {
+		if !requested[specifier] {
+			requested[specifier] = true
+			info.requests = append(info.requests, specifier)
+		}
+	}
+
+	declared := make(map[string]bool)
+	hoisted, _ := c.splitDeclList(in.DeclarationList, in.Body)
+	for _, decl := range hoisted {
+		switch decl := decl.(type) {
+		case *ast.FunctionDeclaration:
+			declared[functionDeclName(decl)] = true
+		case *ast.VariableDeclaration:
+			for _, item := range decl.List {
+				for _, name := range c.boundNames(item) {
+					declared[name.Name] = true
+				}
+			}
+		}
+	}
+	for _, decl := range c.lexicalDeclarations(in.Body) {
+		for _, item := range decl.List {
+			for _, name := range c.boundNames(item) {
+				declared[name.Name] = true
+			}
+		}
+	}
+
+	imports := make(map[string]*moduleImportEntry)
+	for _, st := range in.Body {
+		st, ok := st.(*ast.ImportDeclaration)
+		if !ok {
+			continue
+		}
+		addRequest(st.ModuleSpecifier)
+		addImport := func(localName, importName string, idx file.Idx) {
+			c.checkLexicalName(localName, int(idx)-1)
+			if declared[localName] || imports[localName] != nil {
+				c.throwSyntaxError(int(idx)-1, "Identifier '%s' has already been declared", localName)
+			}
+			entry := moduleImportEntry{
+				moduleRequest: st.ModuleSpecifier,
+				importName:    importName,
+				localName:     localName,
+			}
+			info.imports = append(info.imports, entry)
+			imports[localName] = &entry
+		}
+		if st.Default != nil {
+			addImport(st.Default.Name, "default", st.Default.Idx)
+		}
+		if st.Namespace != nil {
+			addImport(st.Namespace.Name, "*", st.Namespace.Idx)
+		}
+		for _, spec := range st.Specifiers {
+			addImport(spec.LocalName, spec.ImportName, spec.Idx)
+		}
 	}
 
+	exported := make(map[string]bool)
+	addExport := func(list *[]moduleExportEntry, entry moduleExportEntry, idx file.Idx) {
+		if entry.exportName != "" {
+			if exported[entry.exportName] {
+				c.throwSyntaxError(int(idx)-1, "Duplicate export of '%s'", entry.exportName)
+			}
+			exported[entry.exportName] = true
+		}
+		*list = append(*list, entry)
+	}
+	addLocal := func(name *ast.Identifier) {
+		addExport(&info.localExports, moduleExportEntry{exportName: name.Name, localName: name.Name}, name.Idx)
+	}
+	for _, st := range in.Body {
+		st, ok := st.(*ast.ExportDeclaration)
+		if !ok {
+			continue
+		}
+		switch {
+		case st.ModuleSpecifier != "":
+			addRequest(st.ModuleSpecifier)
+			switch {
+			case st.Star && st.Namespace != nil:
+				addExport(&info.indirectExports, moduleExportEntry{
+					exportName:    st.Namespace.Name,
+					moduleRequest: st.ModuleSpecifier,
+					importName:    "*",
+				}, st.Namespace.Idx)
+			case st.Star:
+				addExport(&info.starExports, moduleExportEntry{moduleRequest: st.ModuleSpecifier}, st.Export)
+			}
+			for _, spec := range st.Specifiers {
+				addExport(&info.indirectExports, moduleExportEntry{
+					exportName:    spec.ExportName,
+					moduleRequest: st.ModuleSpecifier,
+					importName:    spec.LocalName,
+				}, spec.Idx)
+			}
+		case st.Default:
+			entry := moduleExportEntry{
+				exportName: "default",
+				localName:  defaultExportName,
+			}
+			if st.Function != nil && st.Function.Name != nil {
+				entry.localName = st.Function.Name.Name
+			} else if d, ok := st.Declaration.(*ast.ClassDeclaration); ok {
+				entry.localName = d.Class.Name.Name
+			}
+			addExport(&info.localExports, entry, st.Export)
+		case st.Function != nil:
+			addLocal(st.Function.Name)
+		case st.Declaration != nil:
+			switch d := st.Declaration.(type) {
+			case *ast.VariableStatement:
+				for _, item := range d.List {
+					if item, ok := item.(*ast.VariableExpression); ok {
+						for _, name := range c.boundNames(item) {
+							addLocal(name)
+						}
+					}
+				}
+			case *ast.LexicalDeclaration:
+				for _, item := range d.List {
+					for _, name := range c.boundNames(item) {
+						addLocal(name)
+					}
+				}
+			case *ast.ClassDeclaration:
+				addLocal(d.Class.Name)
+			}
+		default:
+			for _, spec := range st.Specifiers {
+				entry := moduleExportEntry{
+					exportName: spec.ExportName,
+					localName:  spec.LocalName,
+				}
+				if imp := imports[spec.LocalName]; imp != nil {
+					if imp.importName != "*" {
+						// 导出导入的绑定相当于从原模块间接导出
+						entry = moduleExportEntry{
+							exportName:    spec.ExportName,
+							moduleRequest: imp.moduleRequest,
+							importName:    imp.importName,
+						}
+						addExport(&info.indirectExports, entry, spec.Idx)
+						continue
+					}
+				} else if !declared[spec.LocalName] {
+					c.throwSyntaxError(int(spec.Idx)-1, "Export '%s' is not defined in module", spec.LocalName)
+				}
+				addExport(&info.localExports, entry, spec.Idx)
+			}
+		}
+	}
+	return info
+}
+// 登记顶层的let/const声明，它们在运行时绑定到Runtime的globalLex中
+func (c *compiler) compileGlobalLexicals(decls []*ast.LexicalDeclaration) *bindGlobalLex {
+	b := &bindGlobalLex{}
+	seen := make(map[string]bool)
+	for _, decl := range decls {
+		for _, item := range decl.List {
+			for _, name := range c.boundNames(item) {
+				c.checkLexicalName(name.Name, int(name.Idx)-1)
+				if _, exists := c.scope.names[name.Name]; exists || seen[name.Name] {
+					c.throwSyntaxError(int(name.Idx)-1, "Identifier '%s' has already been declared", name.Name)
+				}
+				seen[name.Name] = true
+				if decl.Token == token.CONST {
+					b.consts = append(b.consts, name.Name)
+				} else {
+					b.lets = append(b.lets, name.Name)
+				}
+			}
+		}
+	}
+	return b
 }
 // 记录函数和变量名称
 func (c *compiler) compileDeclList(v []ast.Declaration, inFunc bool) {
@@ -297,6 +593,58 @@ func (c *compiler) compileDeclList(v []ast.Declaration, inFunc bool) {
 		}
 	}
 }
+// 把声明列表分为函数体顶层的声明(var和函数)和块中的函数声明，后者在进入所在的块时才创建
+func (c *compiler) splitDeclList(v []ast.Declaration, body []ast.Statement) (hoisted []ast.Declaration, nested []*ast.FunctionDeclaration) {
+	top := make(map[*ast.FunctionLiteral]bool)
+	for _, f := range c.functionDeclarations(body) {
+		top[f.Function] = true
+	}
+	for _, st := range body {
+		if st, ok := st.(*ast.ExportDeclaration); ok && st.Function != nil {
+			top[st.Function] = true
+		}
+	}
+	for _, decl := range v {
+		if f, ok := decl.(*ast.FunctionDeclaration); ok && !top[f.Function] {
+			nested = append(nested, f)
+			continue
+		}
+		hoisted = append(hoisted, decl)
+	}
+	return
+}
+// 非严格模式下按附录B.3.3为块中的函数声明在函数作用域中绑定同名的var，
+// 与函数体顶层的let/const或参数同名时不绑定
+func (c *compiler) bindBlockFunctionVars(funcs []*ast.FunctionDeclaration, lexicals []*ast.LexicalDeclaration) {
+	if c.scope.strict || len(funcs) == 0 {
+		return
+	}
+	skip := make(map[string]bool)
+	for name := range c.scope.names {
+		skip[name] = true
+	}
+	for name := range c.scope.params {
+		skip[name] = true
+	}
+	for _, decl := range lexicals {
+		for _, item := range decl.List {
+			for _, name := range c.boundNames(item) {
+				skip[name.Name] = true
+			}
+		}
+	}
+	if c.scope.funcVars == nil {
+		c.scope.funcVars = make(map[string]bool)
+	}
+	for _, f := range funcs {
+		name := f.Function.Name.Name
+		if skip[name] {
+			continue
+		}
+		c.scope.bindName(name)
+		c.scope.funcVars[name] = true
+	}
+}
 //登记函数名
 func (c *compiler) compileFunctions(v []ast.Declaration) {
 	for _, value := range v {
@@ -357,6 +705,8 @@ func (c *compiler) convertFunctionToStashless(code []instruction, args int) {
 			code[pc] = retStashless
 		}
 		switch instr := instr.(type) {
+		case createRest:
+			code[pc] = createRestStashless(instr)
 		case getLocal:
 			if newIdx, convert := c.convertInstrToStashless(uint32(instr), args); convert {
 				code[pc] = loadStack(newIdx)
@@ -390,9 +740,16 @@ func (c *compiler) convertFunctionToStashless(code []instruction, args int) {
 		}
 	}
 }
+// 函数声明绑定的名称，export default的匿名函数绑定到*default*
+func functionDeclName(v *ast.FunctionDeclaration) string {
+	if v.Function.Name == nil {
+		return defaultExportName
+	}
+	return v.Function.Name.Name
+}
 // 记录函数名称
 func (c *compiler) compileFunctionDecl(v *ast.FunctionDeclaration) {
-	idx, ok := c.scope.bindName(v.Function.Name.Name)
+	idx, ok := c.scope.bindName(functionDeclName(v))
 	if !ok {
 		// TODO: error
 	}
@@ -402,10 +759,14 @@ func (c *compiler) compileFunctionDecl(v *ast.FunctionDeclaration) {
 //登记函数名
 func (c *compiler) compileFunction(v *ast.FunctionDeclaration) {
 	e := &compiledIdentifierExpr{
-		name: v.Function.Name.Name,
+		name: functionDeclName(v),
 	}
 	e.init(c, v.Function.Idx0())
-	e.emitSetter(c.compileFunctionLiteral(v.Function, false))
+	f := c.compileFunctionLiteral(v.Function, false)
+	if v.Function.Name == nil {
+		f.(*compiledFunctionLiteral).name = "default"
+	}
+	e.emitSetter(f)
 	c.emit(pop)
 }
 // 添加指令
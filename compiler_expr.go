@@ -5,7 +5,9 @@ import (
 	"github.com/oracle3/goja/ast"
 	"github.com/oracle3/goja/file"
 	"github.com/oracle3/goja/token"
+	"math/big"
 	"regexp"
+	"strconv"
 )
 
 var (
@@ -42,11 +44,31 @@ type compiledArrayLiteral struct {
 	expr *ast.ArrayLiteral
 }
 
+// 解构模式：赋值的左边，或者var解构声明
+type compiledPatternExpr struct {
+	baseCompiledExpr
+	pattern     ast.Expression
+	initializer compiledExpr
+}
+
 type compiledRegexpLiteral struct {
 	baseCompiledExpr
 	expr *ast.RegExpLiteral
 }
 
+// 模板字符串，编译为字符串连接
+type compiledTemplateLiteral struct {
+	baseCompiledExpr
+	elements    []*ast.TemplateElement
+	expressions []compiledExpr
+}
+
+// 带标签模板的字符串数组
+type compiledTemplateObject struct {
+	baseCompiledExpr
+	expr *ast.TemplateLiteral
+}
+
 type compiledLiteral struct {
 	baseCompiledExpr
 	val Value
@@ -96,8 +118,33 @@ type compiledIdentifierExpr struct {
 
 type compiledFunctionLiteral struct {
 	baseCompiledExpr
-	expr   *ast.FunctionLiteral
-	isExpr bool
+	expr    *ast.FunctionLiteral
+	isExpr  bool
+	isArrow bool
+	// 类的方法和构造函数没有Name，name为函数的名称
+	isMethod bool
+	name     string
+	// 类的构造函数，defaultCtor表示类中没有定义constructor
+	class       *ast.ClassLiteral
+	defaultCtor bool
+	// 模块代码，其中的绑定需要保留在stash中以便按名称导出
+	module bool
+}
+
+type compiledClassLiteral struct {
+	baseCompiledExpr
+	expr *ast.ClassLiteral
+	// 匿名类的名称，export default的匿名类为"default"
+	name string
+}
+
+type compiledImportCall struct {
+	baseCompiledExpr
+	arg compiledExpr
+}
+
+type compiledSuperExpr struct {
+	baseCompiledExpr
 }
 
 type compiledBracketExpr struct {
@@ -159,6 +206,23 @@ type compiledEnumGetExpr struct {
 	baseCompiledExpr
 }
 
+// 数组字面量或调用参数中的...expr
+type compiledSpreadExpr struct {
+	baseCompiledExpr
+	expr compiledExpr
+}
+
+type compiledYieldExpr struct {
+	baseCompiledExpr
+	arg      compiledExpr
+	delegate bool
+}
+
+type compiledAwaitExpr struct {
+	baseCompiledExpr
+	arg compiledExpr
+}
+
 type defaultDeleteExpr struct {
 	baseCompiledExpr
 	expr compiledExpr
@@ -210,6 +274,16 @@ func (c *compiler) compileExpression(v ast.Expression) compiledExpr {
 		return c.compileConditionalExpression(v)
 	case *ast.FunctionLiteral:
 		return c.compileFunctionLiteral(v, true)
+	case *ast.ArrowFunctionLiteral:
+		return c.compileArrowFunctionLiteral(v)
+	case *ast.ClassLiteral:
+		return c.compileClassLiteral(v)
+	case *ast.SuperExpression:
+		r := &compiledSuperExpr{}
+		r.init(c, v.Idx0())
+		return r
+	case *ast.ImportCall:
+		return c.compileImportCall(v)
 	case *ast.DotExpression:
 		r := &compiledDotExpr{
 			left: c.compileExpression(v.Left),
@@ -232,6 +306,37 @@ func (c *compiler) compileExpression(v ast.Expression) compiledExpr {
 		return c.compileSequenceExpression(v)
 	case *ast.NewExpression:
 		return c.compileNewExpression(v)
+	case *ast.SpreadElement:
+		r := &compiledSpreadExpr{
+			expr: c.compileExpression(v.Expression),
+		}
+		r.init(c, v.Idx0())
+		return r
+	case *ast.AwaitExpression:
+		r := &compiledAwaitExpr{
+			arg: c.compileExpression(v.Argument),
+		}
+		r.init(c, v.Idx0())
+		return r
+	case *ast.ArrayPattern, *ast.ObjectPattern:
+		r := &compiledPatternExpr{
+			pattern: v,
+		}
+		r.init(c, v.Idx0())
+		return r
+	case *ast.TemplateLiteral:
+		return c.compileTemplateLiteral(v)
+	case *ast.TaggedTemplate:
+		return c.compileTaggedTemplate(v)
+	case *ast.YieldExpression:
+		r := &compiledYieldExpr{
+			delegate: v.Delegate,
+		}
+		if v.Argument != nil {
+			r.arg = c.compileExpression(v.Argument)
+		}
+		r.init(c, v.Idx0())
+		return r
 	default:
 		panic(fmt.Errorf("Unknown expression type: %T", v))
 	}
@@ -279,7 +384,12 @@ func (e *compiledIdentifierExpr) emitGetter(putOnStack bool) {
 	e.addSrcMap()
 	if idx, found, noDynamics := e.c.scope.lookupName(e.name); noDynamics {
 		if found {
-			if putOnStack {
+			if lexical, _ := e.c.scope.lookupLexical(e.name); lexical {
+				e.c.emit(getLocal(idx), checkInit(e.name))
+				if !putOnStack {
+					e.c.emit(pop)
+				}
+			} else if putOnStack {
 				e.c.emit(getLocal(idx))
 			}
 		} else {
@@ -302,6 +412,9 @@ func (e *compiledIdentifierExpr) emitGetterOrRef() {
 	if idx, found, noDynamics := e.c.scope.lookupName(e.name); noDynamics {
 		if found {
 			e.c.emit(getLocal(idx))
+			if lexical, _ := e.c.scope.lookupLexical(e.name); lexical {
+				e.c.emit(checkInit(e.name))
+			}
 		} else {
 			panic("No dynamics and not found")
 		}
@@ -322,6 +435,14 @@ func (c *compiler) emitVarSetter1(name string, offset int, emitRight func(isRef
 	if idx, found, noDynamics := c.scope.lookupName(name); noDynamics {
 		emitRight(false)
 		if found {
+			if lexical, isConst := c.scope.lookupLexical(name); lexical {
+				c.emit(getLocal(idx), checkInit(name))
+				if isConst {
+					c.emit(throwConstAssign)
+					return
+				}
+				c.emit(pop)
+			}
 			c.emit(setLocal(idx))
 		} else {
 			if c.scope.strict {
@@ -721,6 +842,11 @@ func (e *compiledLiteral) constant() bool {
 
 func (e *compiledFunctionLiteral) emitGetter(putOnStack bool) {
 	e.c.newScope()
+	e.c.scope.arrow = e.isArrow
+	e.c.scope.method = e.isMethod || e.class != nil
+	e.c.scope.derived = e.class != nil && e.class.SuperClass != nil
+	e.c.scope.module = e.module
+	e.c.scope.accessed = e.module
 	savedBlockStart := e.c.blockStart
 	savedPrg := e.c.p
 	e.c.p = &Program{
@@ -730,6 +856,8 @@ func (e *compiledFunctionLiteral) emitGetter(putOnStack bool) {
 
 	if e.expr.Name != nil {
 		e.c.p.funcName = e.expr.Name.Name
+	} else {
+		e.c.p.funcName = e.name
 	}
 	block := e.c.block
 	e.c.block = nil
@@ -745,23 +873,87 @@ func (e *compiledFunctionLiteral) emitGetter(putOnStack bool) {
 		if e.expr.Name != nil {
 			e.c.checkIdentifierLName(e.expr.Name.Name, int(e.expr.Name.Idx)-1)
 		}
-		for _, item := range e.expr.ParameterList.List {
-			e.c.checkIdentifierName(item.Name, int(item.Idx)-1)
-			e.c.checkIdentifierLName(item.Name, int(item.Idx)-1)
+		for _, name := range paramNames(e.expr.ParameterList) {
+			e.c.checkIdentifierName(name.Name, int(name.Idx)-1)
+			e.c.checkIdentifierLName(name.Name, int(name.Idx)-1)
 		}
 	}
 
-	length := len(e.expr.ParameterList.List)
+	// length只计算第一个有默认值的参数之前的参数
+	params := e.expr.ParameterList
+	length := len(params.List)
+	simple := params.Rest == nil
+	for i, item := range params.List {
+		if item.Initializer != nil && length > i {
+			length = i
+		}
+		if _, ok := item.Target.(*ast.Identifier); !ok || item.Initializer != nil {
+			simple = false
+		}
+	}
+	if !simple && e.c.isStrictStatement(e.expr.Body) {
+		e.c.throwSyntaxError(int(params.Opening)-1, "Illegal 'use strict' directive in function with non-simple parameter list")
+	}
+
+	// 参数列表有默认值或计算属性名时，参数在初始化之前处于暂时性死区，函数体的var在单独的作用域中
+	paramExprs := hasParameterExpressions(params)
 
-	for _, item := range e.expr.ParameterList.List {
-		_, unique := e.c.scope.bindNameShadow(item.Name)
+	// 解构参数先绑定到隐藏的名称，进入函数后再解构到模式中的名称。有参数表达式时所有参数都这样处理
+	for i, item := range params.List {
+		id, ok := item.Target.(*ast.Identifier)
+		if !ok || paramExprs {
+			e.c.scope.bindNameShadow(paramPatternName(i))
+			continue
+		}
+		_, unique := e.c.scope.bindNameShadow(id.Name)
 		if !unique && e.c.scope.strict {
-			e.c.throwSyntaxError(int(item.Idx)-1, "Strict mode function may not have duplicate parameter names (%s)", item.Name)
+			e.c.throwSyntaxError(int(id.Idx)-1, "Strict mode function may not have duplicate parameter names (%s)", id.Name)
+			return
+		}
+		if !unique && !simple {
+			e.c.throwSyntaxError(int(id.Idx)-1, "Duplicate parameter name not allowed in this context")
 			return
 		}
 	}
 	paramsCount := len(e.c.scope.names)
-	e.c.compileDeclList(e.expr.DeclarationList, true)
+	// 模式和剩余参数中的名称绑定在位置参数之后
+	var names []*ast.Identifier
+	for _, item := range params.List {
+		if _, ok := item.Target.(*ast.Identifier); !ok || paramExprs {
+			names = patternNames(item.Target, names)
+		}
+	}
+	if params.Rest != nil {
+		names = patternNames(params.Rest, names)
+	}
+	for _, name := range names {
+		if _, unique := e.c.scope.bindName(name.Name); !unique {
+			e.c.throwSyntaxError(int(name.Idx)-1, "Duplicate parameter name not allowed in this context")
+			return
+		}
+	}
+	var body []ast.Statement
+	if b, ok := e.expr.Body.(*ast.BlockStatement); ok {
+		body = b.List
+	}
+	decls := e.c.lexicalDeclarations(body)
+	hoisted, nested := e.c.splitDeclList(e.expr.DeclarationList, body)
+	if !paramExprs {
+		e.c.bindBlockFunctionVars(nested, decls)
+		e.c.compileDeclList(hoisted, true)
+	}
+	checkLexicals := func() {
+		for _, decl := range decls {
+			for _, item := range decl.List {
+				for _, name := range e.c.boundNames(item) {
+					if _, exists := e.c.scope.names[name.Name]; exists {
+						e.c.throwSyntaxError(int(name.Idx)-1, "Identifier '%s' has already been declared", name.Name)
+					}
+				}
+			}
+		}
+	}
+	checkLexicals()
 	var needCallee bool
 	var calleeIdx uint32
 	if e.isExpr && e.expr.Name != nil {
@@ -775,13 +967,72 @@ func (e *compiledFunctionLiteral) emitGetter(putOnStack bool) {
 	if needCallee {
 		e.c.emit(loadCallee, setLocalP(calleeIdx))
 	}
-
-	e.c.compileFunctions(e.expr.DeclarationList)
-	e.c.markBlockStart()
-	e.c.compileStatement(e.expr.Body, false)
+	var init func(name *ast.Identifier)
+	if paramExprs {
+		e.c.scope.lexNames = make(map[string]bool)
+		for _, name := range names {
+			e.c.scope.lexNames[name.Name] = false
+			e.c.emit(loadNil, setLocalP(e.c.scope.names[name.Name]))
+		}
+		init = func(name *ast.Identifier) {
+			e.c.emitLexicalInit(name.Name)
+		}
+	}
+	// 依次计算参数的默认值并解构，然后创建剩余参数
+	for i, item := range params.List {
+		id, ok := item.Target.(*ast.Identifier)
+		if ok && item.Initializer == nil && !paramExprs {
+			continue
+		}
+		if !ok || paramExprs {
+			id = &ast.Identifier{
+				Name: paramPatternName(i),
+				Idx:  item.Target.Idx0(),
+			}
+		}
+		e.c.compileIdentifierExpression(id).emitGetter(true)
+		e.c.emitDestructBinding(item, init)
+	}
+	if params.Rest != nil {
+		e.c.emit(createRest(paramsCount))
+		e.c.emitDestructTarget(params.Rest, init)
+	}
+
+	var bodyStart int
+	if paramExprs {
+		// 参数都已初始化，函数体中访问它们不再需要检查
+		e.c.scope.lexNames = nil
+		bodyStart = e.c.openFuncBodyScope(hoisted, nested, decls, names)
+		checkLexicals()
+	}
+	if len(decls) > 0 {
+		// 函数体中的let/const放在单独的块级作用域中，函数声明在其中创建以便访问它们
+		start := e.c.openBlockScope(decls)
+		e.c.scope.accessed = e.module
+		e.c.compileFunctions(hoisted)
+		e.emitStart()
+		e.c.markBlockStart()
+		e.c.compileStatements(body, false)
+		e.c.closeBlockScope(start)
+	} else {
+		e.c.compileFunctions(hoisted)
+		e.emitStart()
+		e.c.markBlockStart()
+		if e.defaultCtor && e.c.scope.derived {
+			e.c.emit(superCallAll, pop)
+		}
+		e.c.compileStatement(e.expr.Body, false)
+	}
+	if paramExprs {
+		e.c.closeBlockScope(bodyStart)
+	}
 
 	if e.c.blockStart >= len(e.c.p.code)-1 || e.c.p.code[len(e.c.p.code)-1] != ret {
-		e.c.emit(loadUndef, ret)
+		if e.c.scope.derived {
+			e.c.emit(loadUndef, derivedResult, ret)
+		} else {
+			e.c.emit(loadUndef, ret)
+		}
 	}
 
 	if !e.c.scope.dynamic && !e.c.scope.accessed {
@@ -809,7 +1060,7 @@ func (e *compiledFunctionLiteral) emitGetter(putOnStack bool) {
 		}
 
 		code := make([]instruction, l+len(e.c.p.code)-maxPreambleLen)
-		code[0] = enterFunc(length)
+		code[0] = enterFunc(paramsCount)
 		for name, nameIdx := range e.c.scope.names {
 			code[nameIdx+1] = bindName(name)
 		}
@@ -821,10 +1072,11 @@ func (e *compiledFunctionLiteral) emitGetter(putOnStack bool) {
 		}
 
 		if e.c.scope.argsNeeded {
-			if e.c.scope.strict {
-				code[pos] = createArgsStrict(length)
+			// 参数列表不是简单参数时arguments不映射到参数
+			if e.c.scope.strict || !simple {
+				code[pos] = createArgsStrict(paramsCount)
 			} else {
-				code[pos] = createArgs(length)
+				code[pos] = createArgs(paramsCount)
 			}
 			pos++
 			idx, exists := e.c.scope.names["arguments"]
@@ -843,20 +1095,45 @@ func (e *compiledFunctionLiteral) emitGetter(putOnStack bool) {
 	}
 
 	strict := e.c.scope.strict
+	thisNeeded := e.c.scope.thisNeeded
+	derived := e.c.scope.derived
 	p := e.c.p
 	// e.c.p.dumpCode()
 	e.c.popScope()
 	e.c.p = savedPrg
 	e.c.blockStart = savedBlockStart
-	name := ""
+	name := e.name
 	if e.expr.Name != nil {
 		name = e.expr.Name.Name
 	}
-	e.c.emit(&newFunc{prg: p, length: uint32(length), name: name, srcStart: uint32(e.expr.Idx0() - 1), srcEnd: uint32(e.expr.Idx1() - 1), strict: strict})
+	f := newFunc{prg: p, length: uint32(length), name: name, srcStart: uint32(e.expr.Idx0() - 1), srcEnd: uint32(e.expr.Idx1() - 1), strict: strict, generator: e.expr.Generator, async: e.expr.Async}
+	if e.isArrow {
+		// 箭头函数的this是创建时外层的this
+		inFunc := nearestNonLexical(e.c.scope).eval || e.c.scope.isFunction()
+		if thisNeeded && inFunc {
+			nearestNonLexical(e.c.scope).thisNeeded = true
+		}
+		e.c.emit(&newArrowFunc{newFunc: f, globalThis: !inFunc})
+	} else if e.class != nil {
+		// 类的构造函数的源码是整个类
+		f.srcStart = uint32(e.class.Idx0() - 1)
+		f.srcEnd = uint32(e.class.Idx1() - 1)
+		e.c.emit(&newClass{newFunc: f, derived: derived})
+	} else {
+		e.c.emit(&f)
+	}
 	if !putOnStack {
 		e.c.emit(pop)
 	}
 }
+// 生成器和async函数在初始化参数之后暂停，函数体在恢复时执行
+func (e *compiledFunctionLiteral) emitStart() {
+	if e.expr.Generator {
+		e.c.emit(genStart)
+	} else if e.expr.Async {
+		e.c.emit(asyncStart)
+	}
+}
 // 编译函数表达式
 func (c *compiler) compileFunctionLiteral(v *ast.FunctionLiteral, isExpr bool) compiledExpr {
 	if v.Name != nil && c.scope.strict {
@@ -870,8 +1147,162 @@ func (c *compiler) compileFunctionLiteral(v *ast.FunctionLiteral, isExpr bool) c
 	return r
 }
 
+// 编译箭头函数，表达式函数体转换为return语句
+func (c *compiler) compileArrowFunctionLiteral(v *ast.ArrowFunctionLiteral) compiledExpr {
+	var body *ast.BlockStatement
+	switch b := v.Body.(type) {
+	case *ast.BlockStatement:
+		body = b
+	case *ast.ExpressionBody:
+		body = &ast.BlockStatement{
+			LeftBrace: b.Idx0(),
+			List: []ast.Statement{
+				&ast.ReturnStatement{
+					Return:   b.Idx0(),
+					Argument: b.Expression,
+				},
+			},
+			RightBrace: b.Idx1() - 1,
+		}
+	}
+	r := &compiledFunctionLiteral{
+		expr: &ast.FunctionLiteral{
+			Function:        v.Start,
+			ParameterList:   v.ParameterList,
+			Body:            body,
+			Source:          v.Source,
+			Async:           v.Async,
+			DeclarationList: v.DeclarationList,
+		},
+		isExpr:  true,
+		isArrow: true,
+	}
+	r.init(c, v.Idx0())
+	return r
+}
+
+// 编译类，类的代码总是严格模式
+func (c *compiler) compileClassLiteral(v *ast.ClassLiteral) compiledExpr {
+	r := &compiledClassLiteral{
+		expr: v,
+	}
+	r.init(c, v.Idx0())
+	return r
+}
+
+func (e *compiledClassLiteral) emitGetter(putOnStack bool) {
+	c := e.c
+	cls := e.expr
+	var scopeStart int
+	if cls.Name != nil {
+		// 类中的类名是一个const绑定
+		scopeStart = c.openBlockScope([]*ast.LexicalDeclaration{{
+			Idx:   cls.Name.Idx,
+			Token: token.CONST,
+			List: []*ast.VariableExpression{
+				{Name: cls.Name.Name, Idx: cls.Name.Idx},
+			},
+		}})
+	}
+	strict := c.scope.strict
+	c.scope.strict = true
+
+	if cls.SuperClass != nil {
+		c.compileExpression(cls.SuperClass).emitGetter(true)
+	}
+	ctor := &compiledFunctionLiteral{
+		isExpr: true,
+		class:  cls,
+	}
+	if cls.Name != nil {
+		ctor.name = cls.Name.Name
+	} else {
+		ctor.name = e.name
+	}
+	for _, m := range cls.Body {
+		if m.Kind == "constructor" {
+			ctor.expr = m.Body
+		}
+	}
+	if ctor.expr == nil {
+		ctor.defaultCtor = true
+		ctor.expr = &ast.FunctionLiteral{
+			Function:      cls.Class,
+			ParameterList: &ast.ParameterList{},
+			Body: &ast.BlockStatement{
+				LeftBrace:  cls.Class,
+				RightBrace: cls.RightBrace,
+			},
+		}
+	}
+	ctor.init(c, cls.Idx0())
+	e.addSrcMap()
+	ctor.emitGetter(true)
+
+	for _, m := range cls.Body {
+		if m.Kind == "constructor" {
+			continue
+		}
+		if m.Computed != nil {
+			c.compileExpression(m.Computed).emitGetter(true)
+		}
+		f := &compiledFunctionLiteral{
+			expr:     m.Body,
+			isExpr:   true,
+			isMethod: true,
+		}
+		if m.Computed == nil {
+			f.name = m.Key
+			if m.Kind != "method" {
+				f.name = m.Kind + " " + m.Key
+			}
+		}
+		f.init(c, m.Body.Idx0())
+		f.emitGetter(true)
+		c.emit(&defineMethod{name: m.Key, kind: m.Kind, static: m.Static, computed: m.Computed != nil})
+	}
+	c.emit(pop) // 原型对象
+
+	c.scope.strict = strict
+	if cls.Name != nil {
+		c.emit(dup)
+		c.emitLexicalInit(cls.Name.Name)
+		c.closeBlockScope(scopeStart)
+	}
+	if !putOnStack {
+		c.emit(pop)
+	}
+}
+
+// super.x只能用在类的方法中，箭头函数使用外层方法的super
+func (s *scope) inMethod() bool {
+	for ; s != nil; s = s.outer {
+		if !s.lexical && !s.block && !s.arrow {
+			return s.method
+		}
+	}
+	return false
+}
+
+func (e *compiledSuperExpr) emitGetter(putOnStack bool) {
+	if !e.c.scope.inMethod() {
+		e.c.throwSyntaxError(e.offset, "'super' keyword unexpected here")
+	}
+	if putOnStack {
+		e.addSrcMap()
+		e.c.emit(loadSuper)
+	}
+}
+
+// super.method()调用时this是当前的this
+func (e *compiledSuperExpr) emitThis() {
+	r := &compiledThisExpr{}
+	r.init(e.c, file.Idx(e.offset+1))
+	r.emitGetter(true)
+}
+
 func nearestNonLexical(s *scope) *scope {
-	for ; s != nil && s.lexical; s = s.outer {
+	for ; s != nil && (s.lexical || s.block); s = s.outer {
 	}
 	return s
 }
@@ -879,8 +1310,10 @@ func nearestNonLexical(s *scope) *scope {
 func (e *compiledThisExpr) emitGetter(putOnStack bool) {
 	if putOnStack {
 		e.addSrcMap()
-		if e.c.scope.eval || e.c.scope.isFunction() {
-			nearestNonLexical(e.c.scope).thisNeeded = true
+		if s := nearestNonLexical(e.c.scope); s.derived {
+			e.c.emit(loadDerivedThis)
+		} else if s.eval || e.c.scope.isFunction() {
+			s.thisNeeded = true
 			e.c.emit(loadStack(0))
 		} else {
 			e.c.emit(loadGlobalObject)
@@ -900,15 +1333,33 @@ func (e *compiledThisExpr) deleteExpr() compiledExpr {
 
 func (e *compiledNewExpr) emitGetter(putOnStack bool) {
 	e.callee.emitGetter(true)
-	for _, expr := range e.args {
-		expr.emitGetter(true)
+	spread := e.c.emitArgs(e.args)
+	e.addSrcMap()
+	if spread {
+		e.c.emit(newSpread)
+	} else {
+		e.c.emit(_new(len(e.args)))
+	}
+	if !putOnStack {
+		e.c.emit(pop)
 	}
+}
+func (e *compiledImportCall) emitGetter(putOnStack bool) {
+	e.arg.emitGetter(true)
 	e.addSrcMap()
-	e.c.emit(_new(len(e.args)))
+	e.c.emit(importDynamic)
 	if !putOnStack {
 		e.c.emit(pop)
 	}
 }
+// 编译动态导入import(specifier)
+func (c *compiler) compileImportCall(v *ast.ImportCall) compiledExpr {
+	r := &compiledImportCall{
+		arg: c.compileExpression(v.Argument),
+	}
+	r.init(c, v.Idx0())
+	return r
+}
 //编译new表达式
 func (c *compiler) compileNewExpression(v *ast.NewExpression) compiledExpr {
 	args := make([]compiledExpr, len(v.ArgumentList))
@@ -952,7 +1403,7 @@ func (c *compiler) emitThrow(v Value) {
 	if o, ok := v.(*Object); ok {
 		t := o.self.getStr("name").String()
 		switch t {
-		case "TypeError":
+		case "TypeError", "RangeError":
 			c.emit(getVar1(t))
 			msg := o.self.getStr("message")
 			if msg != nil {
@@ -1322,6 +1773,14 @@ func (e *compiledVariableExpr) emitGetter(putOnStack bool) {
 }
 // 编译变量表达式
 func (c *compiler) compileVariableExpression(v *ast.VariableExpression) compiledExpr {
+	if v.Pattern != nil {
+		r := &compiledPatternExpr{
+			pattern:     v.Pattern,
+			initializer: c.compileExpression(v.Initializer),
+		}
+		r.init(c, v.Idx0())
+		return r
+	}
 	r := &compiledVariableExpr{
 		name:        v.Name,
 		initializer: c.compileExpression(v.Initializer),
@@ -1334,14 +1793,54 @@ func (e *compiledObjectLiteral) emitGetter(putOnStack bool) {
 	e.addSrcMap()
 	e.c.emit(newObject)
 	for _, prop := range e.expr.Value {
-		e.c.compileExpression(prop.Value).emitGetter(true)
+		if prop.Computed != nil {
+			e.c.compileExpression(prop.Computed).emitGetter(true)
+		}
+		if prop.Kind == "value" {
+			e.c.compileExpression(prop.Value).emitGetter(true)
+		} else {
+			// 方法和getter/setter以对象本身为home object，可以使用super
+			f := &compiledFunctionLiteral{
+				expr:     prop.Value.(*ast.FunctionLiteral),
+				isExpr:   true,
+				isMethod: true,
+			}
+			if prop.Computed == nil {
+				f.name = prop.Key
+				if prop.Kind != "method" {
+					f.name = prop.Kind + " " + prop.Key
+				}
+			}
+			f.init(e.c, prop.Value.Idx0())
+			f.emitGetter(true)
+			if prop.Computed != nil {
+				e.c.emit(setHomeObject(2))
+			} else {
+				e.c.emit(setHomeObject(1))
+			}
+		}
+		if prop.Computed != nil {
+			switch prop.Kind {
+			case "value", "method":
+				e.c.emit(setElem1)
+			case "get":
+				e.c.emit(setElemGetter)
+			case "set":
+				e.c.emit(setElemSetter)
+			default:
+				panic(fmt.Errorf("Unknown property kind: %s", prop.Kind))
+			}
+			continue
+		}
 		switch prop.Kind {
 		case "value":
-			if prop.Key == __proto__ {
+			if prop.Key == __proto__ && !prop.Shorthand {
 				e.c.emit(setProto)
 			} else {
 				e.c.emit(setProp1(prop.Key))
 			}
+		case "method":
+			e.c.emit(setProp1(prop.Key))
 		case "get":
 			e.c.emit(setPropGetter(prop.Key))
 		case "set":
@@ -1365,14 +1864,96 @@ func (c *compiler) compileObjectLiteral(v *ast.ObjectLiteral) compiledExpr {
 
 func (e *compiledArrayLiteral) emitGetter(putOnStack bool) {
 	e.addSrcMap()
-	for _, v := range e.expr.Value {
+	items := make([]compiledExpr, len(e.expr.Value))
+	for i, v := range e.expr.Value {
 		if v != nil {
-			e.c.compileExpression(v).emitGetter(true)
-		} else {
-			e.c.emit(loadNil)
+			items[i] = e.c.compileExpression(v)
 		}
 	}
-	e.c.emit(newArray(len(e.expr.Value)))
+	e.c.emitArrayItems(items)
+	if !putOnStack {
+		e.c.emit(pop)
+	}
+}
+// 把items放入新建的数组，nil表示空位
+func (c *compiler) emitArrayItems(items []compiledExpr) {
+	if !hasSpread(items) {
+		for _, item := range items {
+			if item != nil {
+				item.emitGetter(true)
+			} else {
+				c.emit(loadNil)
+			}
+		}
+		c.emit(newArray(len(items)))
+		return
+	}
+	c.emit(newArray(0))
+	for _, item := range items {
+		switch item := item.(type) {
+		case nil:
+			c.emit(loadNil, pushArrayItem)
+		case *compiledSpreadExpr:
+			item.expr.emitGetter(true)
+			item.addSrcMap()
+			c.emit(pushArraySpread)
+		default:
+			item.emitGetter(true)
+			c.emit(pushArrayItem)
+		}
+	}
+}
+// 是否包含...expr
+func hasSpread(items []compiledExpr) bool {
+	for _, item := range items {
+		if _, ok := item.(*compiledSpreadExpr); ok {
+			return true
+		}
+	}
+	return false
+}
+// 压入调用参数，包含...expr时参数被放入一个数组，返回true
+func (c *compiler) emitArgs(args []compiledExpr) bool {
+	if hasSpread(args) {
+		c.emitArrayItems(args)
+		return true
+	}
+	for _, expr := range args {
+		expr.emitGetter(true)
+	}
+	return false
+}
+
+func (e *compiledSpreadExpr) emitGetter(putOnStack bool) {
+	e.c.throwSyntaxError(e.offset, "Unexpected token ...")
+}
+// yield之后紧跟一个跳转，通过return()恢复时跳过它执行return的处理
+func (e *compiledYieldExpr) emitGetter(putOnStack bool) {
+	if e.arg != nil {
+		e.arg.emitGetter(true)
+	} else {
+		e.c.emit(loadUndef)
+	}
+	e.addSrcMap()
+	if e.delegate {
+		e.c.emit(yieldDelegate)
+	} else {
+		e.c.emit(yield)
+	}
+	lbl := len(e.c.p.code)
+	e.c.emit(nil)
+	e.c.emitReturnExits()
+	e.c.emit(retStashless)
+	e.c.p.code[lbl] = jump(len(e.c.p.code) - lbl)
+	if !putOnStack {
+		e.c.emit(pop)
+	}
+}
+// await之后的值在恢复执行时压入栈中
+func (e *compiledAwaitExpr) emitGetter(putOnStack bool) {
+	e.arg.emitGetter(true)
+	e.addSrcMap()
+	e.c.emit(await)
 	if !putOnStack {
 		e.c.emit(pop)
 	}
@@ -1388,16 +1969,14 @@ func (c *compiler) compileArrayLiteral(v *ast.ArrayLiteral) compiledExpr {
 
 func (e *compiledRegexpLiteral) emitGetter(putOnStack bool) {
 	if putOnStack {
-		pattern, global, ignoreCase, multiline, err := compileRegexp(e.expr.Pattern, e.expr.Flags)
+		pattern, flags, err := compileRegexp(e.expr.Pattern, e.expr.Flags)
 		if err != nil {
 			e.c.throwSyntaxError(e.offset, err.Error())
 		}
 
 		e.c.emit(&newRegexp{pattern: pattern,
-			src:        newStringValue(e.expr.Pattern),
-			global:     global,
-			ignoreCase: ignoreCase,
-			multiline:  multiline,
+			src:   newStringValue(e.expr.Pattern),
+			flags: flags,
 		})
 	}
 }
@@ -1410,16 +1989,66 @@ func (c *compiler) compileRegexpLiteral(v *ast.RegExpLiteral) compiledExpr {
 	return r
 }
 
+func (e *compiledTemplateLiteral) emitGetter(putOnStack bool) {
+	e.c.emit(loadVal(e.c.p.defineLiteralValue(newStringValue(e.elements[0].Parsed))))
+	for i, expr := range e.expressions {
+		e.c.emitExpr(expr, true)
+		e.addSrcMap()
+		e.c.emit(toStringVal, add)
+		if i+1 < len(e.elements) {
+			if s := e.elements[i+1].Parsed; s != "" {
+				e.c.emit(loadVal(e.c.p.defineLiteralValue(newStringValue(s))), add)
+			}
+		}
+	}
+	if !putOnStack {
+		e.c.emit(pop)
+	}
+}
+
+func (e *compiledTemplateObject) emitGetter(putOnStack bool) {
+	if !putOnStack {
+		return
+	}
+	t := &getTemplateObject{
+		cooked: make([]Value, len(e.expr.Elements)),
+		raw:    make([]Value, len(e.expr.Elements)),
+	}
+	for i, elt := range e.expr.Elements {
+		if elt.Valid {
+			t.cooked[i] = newStringValue(elt.Parsed)
+		} else {
+			t.cooked[i] = _undefined
+		}
+		t.raw[i] = newStringValue(elt.Literal)
+	}
+	e.c.emit(t)
+}
+
 func (e *compiledCallExpr) emitGetter(putOnStack bool) {
+	if _, ok := e.callee.(*compiledSuperExpr); ok {
+		e.emitSuperCall(putOnStack)
+		return
+	}
 	var calleeName string
 	switch callee := e.callee.(type) {
 	case *compiledDotExpr:
-		callee.left.emitGetter(true)
-		e.c.emit(dup)
+		if sup, ok := callee.left.(*compiledSuperExpr); ok {
+			sup.emitThis()
+			sup.emitGetter(true)
+		} else {
+			callee.left.emitGetter(true)
+			e.c.emit(dup)
+		}
 		e.c.emit(getPropCallee(callee.name))
 	case *compiledBracketExpr:
-		callee.left.emitGetter(true)
-		e.c.emit(dup)
+		if sup, ok := callee.left.(*compiledSuperExpr); ok {
+			sup.emitThis()
+			sup.emitGetter(true)
+		} else {
+			callee.left.emitGetter(true)
+			e.c.emit(dup)
+		}
 		callee.member.emitGetter(true)
 		e.c.emit(getElemCallee)
 	case *compiledIdentifierExpr:
@@ -1431,17 +2060,19 @@ func (e *compiledCallExpr) emitGetter(putOnStack bool) {
 		callee.emitGetter(true)
 	}
 
-	for _, expr := range e.args {
-		expr.emitGetter(true)
-	}
+	spread := e.c.emitArgs(e.args)
 
 	e.addSrcMap()
-	if calleeName == "eval" {
-		e.c.scope.dynamic = true
-		e.c.scope.thisNeeded = true
-		if e.c.scope.lexical {
-			e.c.scope.outer.dynamic = true
+	if spread {
+		e.c.emit(callSpread)
+	} else if calleeName == "eval" {
+		for s := e.c.scope; s != nil; s = s.outer {
+			s.dynamic = true
+			if !s.lexical && !s.block {
+				break
+			}
 		}
+		nearestNonLexical(e.c.scope).thisNeeded = true
 		e.c.scope.accessed = true
 		if e.c.scope.strict {
 			e.c.emit(callEvalStrict(len(e.args)))
@@ -1457,6 +2088,23 @@ func (e *compiledCallExpr) emitGetter(putOnStack bool) {
 	}
 }
 
+// 编译super()，只能用在派生类的构造函数中
+func (e *compiledCallExpr) emitSuperCall(putOnStack bool) {
+	if !nearestNonLexical(e.c.scope).derived {
+		e.c.throwSyntaxError(e.callee.(*compiledSuperExpr).offset, "'super' keyword unexpected here")
+	}
+	spread := e.c.emitArgs(e.args)
+	e.addSrcMap()
+	if spread {
+		e.c.emit(superCallSpread)
+	} else {
+		e.c.emit(superCall(len(e.args)))
+	}
+	if !putOnStack {
+		e.c.emit(pop)
+	}
+}
+
 func (e *compiledCallExpr) deleteExpr() compiledExpr {
 	r := &defaultDeleteExpr{
 		expr: e,
@@ -1479,6 +2127,36 @@ func (c *compiler) compileCallExpression(v *ast.CallExpression) compiledExpr {
 	r.init(c, v.LeftParenthesis)
 	return r
 }
+// 编译模板字符串
+func (c *compiler) compileTemplateLiteral(v *ast.TemplateLiteral) compiledExpr {
+	r := &compiledTemplateLiteral{
+		elements:    v.Elements,
+		expressions: make([]compiledExpr, len(v.Expressions)),
+	}
+	for i, expr := range v.Expressions {
+		r.expressions[i] = c.compileExpression(expr)
+	}
+	r.init(c, v.Idx0())
+	return r
+}
+// 编译带标签模板，即以字符串数组和各个替换值为参数调用tag
+func (c *compiler) compileTaggedTemplate(v *ast.TaggedTemplate) compiledExpr {
+	strings := &compiledTemplateObject{
+		expr: v.Template,
+	}
+	strings.init(c, v.Template.Idx0())
+	args := make([]compiledExpr, 0, len(v.Template.Expressions)+1)
+	args = append(args, strings)
+	for _, expr := range v.Template.Expressions {
+		args = append(args, c.compileExpression(expr))
+	}
+	r := &compiledCallExpr{
+		args:   args,
+		callee: c.compileExpression(v.Tag),
+	}
+	r.init(c, v.Template.Idx0())
+	return r
+}
 //编译标识符表达式
 func (c *compiler) compileIdentifierExpression(v *ast.Identifier) compiledExpr {
 	if c.scope.strict {
@@ -1505,6 +2183,8 @@ func (c *compiler) compileNumberLiteral(v *ast.NumberLiteral) compiledExpr {
 		val = intToValue(num)
 	case float64:
 		val = floatToValue(num)
+	case *big.Int:
+		val = (*valueBigInt)(num)
 	default:
 		panic(fmt.Errorf("Unsupported number literal type: %T", v.Value))
 	}
@@ -1556,3 +2236,143 @@ func (e *compiledEnumGetExpr) emitGetter(putOnStack bool) {
 		e.c.emit(pop)
 	}
 }
+
+func (e *compiledPatternExpr) emitGetter(putOnStack bool) {
+	if e.initializer == nil {
+		e.c.throwSyntaxError(e.offset, "Invalid destructuring assignment target")
+	}
+	e.c.emitExpr(e.initializer, true)
+	e.c.emitDestruct(e.pattern, nil)
+	if !putOnStack {
+		e.c.emit(pop)
+	}
+}
+
+func (e *compiledPatternExpr) emitSetter(valueExpr compiledExpr) {
+	e.c.emitExpr(valueExpr, true)
+	e.c.emitDestruct(e.pattern, nil)
+}
+// 返回参数列表中绑定的所有名称
+func paramNames(params *ast.ParameterList) []*ast.Identifier {
+	var names []*ast.Identifier
+	for _, item := range params.List {
+		names = patternNames(item.Target, names)
+	}
+	if params.Rest != nil {
+		names = patternNames(params.Rest, names)
+	}
+	return names
+}
+// 参数列表中是否有默认值或计算属性名
+func hasParameterExpressions(params *ast.ParameterList) bool {
+	for _, item := range params.List {
+		if item.Initializer != nil || patternHasExpressions(item.Target) {
+			return true
+		}
+	}
+	return params.Rest != nil && patternHasExpressions(params.Rest)
+}
+// 解构模式中是否有默认值或计算属性名
+func patternHasExpressions(target ast.Expression) bool {
+	switch target := target.(type) {
+	case *ast.ArrayPattern:
+		for _, elt := range target.Elements {
+			if elt != nil && (elt.Initializer != nil || patternHasExpressions(elt.Target)) {
+				return true
+			}
+		}
+		return target.Rest != nil && patternHasExpressions(target.Rest)
+	case *ast.ObjectPattern:
+		for _, prop := range target.Properties {
+			if prop.Computed != nil || prop.Value.Initializer != nil || patternHasExpressions(prop.Value.Target) {
+				return true
+			}
+		}
+	}
+	return false
+}
+// 解构参数绑定的隐藏名称，不可能与标识符冲突
+func paramPatternName(i int) string {
+	return " __param" + strconv.Itoa(i)
+}
+// 按照模式解构栈顶的源值，结束后源值仍留在栈顶。
+// init不为nil时用它初始化声明中绑定的名称，否则按普通赋值处理
+func (c *compiler) emitDestruct(pattern ast.Expression, init func(name *ast.Identifier)) {
+	switch pattern := pattern.(type) {
+	case *ast.ObjectPattern:
+		c.emit(checkObjectCoercible)
+		for _, prop := range pattern.Properties {
+			if prop.Computed != nil {
+				c.emit(dup)
+				c.compileExpression(prop.Computed).emitGetter(true)
+				c.emit(getElem)
+			} else {
+				c.emit(dup, getProp(prop.Key))
+			}
+			c.emitDestructBinding(prop.Value, init)
+		}
+	case *ast.ArrayPattern:
+		c.emit(dup, iterate)
+		for _, elt := range pattern.Elements {
+			c.emit(destructNext)
+			if elt == nil {
+				c.emit(pop)
+				continue
+			}
+			c.emitDestructBinding(elt, init)
+		}
+		if pattern.Rest != nil {
+			c.emit(destructRest)
+			c.emitDestructTarget(pattern.Rest, init)
+		}
+		c.emit(enumPop)
+	default:
+		c.throwSyntaxError(int(pattern.Idx0())-1, "Invalid destructuring assignment target")
+	}
+}
+// 栈顶的值为undefined时使用默认值，然后赋给目标
+func (c *compiler) emitDestructBinding(b *ast.Binding, init func(name *ast.Identifier)) {
+	if b.Initializer != nil {
+		j := len(c.p.code)
+		c.emit(nil)
+		c.emitExpr(c.compileExpression(b.Initializer), true)
+		c.p.code[j] = jdef(len(c.p.code) - j)
+	}
+	c.emitDestructTarget(b.Target, init)
+}
+// 把栈顶的值赋给解构目标并弹出
+func (c *compiler) emitDestructTarget(target ast.Expression, init func(name *ast.Identifier)) {
+	switch target := target.(type) {
+	case *ast.Identifier:
+		if init != nil {
+			init(target)
+			return
+		}
+		c.emitVarSetter1(target.Name, int(target.Idx)-1, func(bool) {})
+		c.emit(pop)
+	case *ast.DotExpression:
+		c.compileExpression(target.Left).emitGetter(true)
+		c.emit(dupN(1))
+		if c.scope.strict {
+			c.emit(setPropStrict(target.Identifier.Name))
+		} else {
+			c.emit(setProp(target.Identifier.Name))
+		}
+		c.emit(pop, pop)
+	case *ast.BracketExpression:
+		c.compileExpression(target.Left).emitGetter(true)
+		c.compileExpression(target.Member).emitGetter(true)
+		c.emit(dupN(2))
+		if c.scope.strict {
+			c.emit(setElemStrict)
+		} else {
+			c.emit(setElem)
+		}
+		c.emit(pop, pop)
+	case *ast.ArrayPattern, *ast.ObjectPattern:
+		c.emitDestruct(target, init)
+		c.emit(pop)
+	default:
+		c.throwSyntaxError(int(target.Idx0())-1, "Invalid destructuring assignment target")
+	}
+}
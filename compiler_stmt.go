@@ -18,6 +18,12 @@ func (c *compiler) compileStatement(v ast.Statement, needResult bool) {
 		c.compileExpressionStatement(v, needResult)
 	case *ast.VariableStatement:
 		c.compileVariableStatement(v, needResult)
+	case *ast.LexicalDeclaration:
+		c.compileLexicalDeclaration(v, needResult)
+	case *ast.ClassDeclaration:
+		c.compileClassDeclaration(v, needResult)
+	case *ast.FunctionDeclaration:
+		c.compileFunctionDeclaration(v, needResult)
 	case *ast.ReturnStatement:
 		c.compileReturnStatement(v)
 	case *ast.IfStatement:
@@ -28,6 +34,8 @@ func (c *compiler) compileStatement(v ast.Statement, needResult bool) {
 		c.compileForStatement(v, needResult)
 	case *ast.ForInStatement:
 		c.compileForInStatement(v, needResult)
+	case *ast.ForOfStatement:
+		c.compileForOfStatement(v, needResult)
 	case *ast.WhileStatement:
 		c.compileWhileStatement(v, needResult)
 	case *ast.BranchStatement:
@@ -47,6 +55,10 @@ func (c *compiler) compileStatement(v ast.Statement, needResult bool) {
 		c.compileEmptyStatement(needResult)
 	case *ast.WithStatement:
 		c.compileWithStatement(v, needResult)
+	case *ast.ImportDeclaration:
+		c.compileEmptyStatement(needResult)
+	case *ast.ExportDeclaration:
+		c.compileExportDeclaration(v, needResult)
 	case *ast.DebuggerStatement:
 	default:
 		panic(fmt.Errorf("Unknown statement type: %T", v))
@@ -63,6 +75,8 @@ func (c *compiler) compileLabeledStatement(v *ast.LabelledStatement, needResult
 	switch s := v.Statement.(type) {
 	case *ast.ForInStatement:
 		c.compileLabeledForInStatement(s, needResult, label)
+	case *ast.ForOfStatement:
+		c.compileLabeledForOfStatement(s, needResult, label)
 	case *ast.ForStatement:
 		c.compileLabeledForStatement(s, needResult, label)
 	case *ast.WhileStatement:
@@ -75,7 +89,7 @@ func (c *compiler) compileLabeledStatement(v *ast.LabelledStatement, needResult
 }
 // 编译try语句
 func (c *compiler) compileTryStatement(v *ast.TryStatement) {
-	if c.scope.strict && v.Catch != nil {
+	if c.scope.strict && v.Catch != nil && v.Catch.Parameter != nil {
 		switch v.Catch.Parameter.Name {
 		case "arguments", "eval":
 			c.throwSyntaxError(int(v.Catch.Parameter.Idx)-1, "Catch variable may not be eval or arguments in strict mode")
@@ -94,16 +108,26 @@ func (c *compiler) compileTryStatement(v *ast.TryStatement) {
 	var catchOffset int
 	dynamicCatch := true
 	if v.Catch != nil {
+		c.checkCatchBody(v.Catch)
 		dyn := nearestNonLexical(c.scope).dynamic
 		accessed := c.scope.accessed
+		// 解构的catch参数先绑定到隐藏的名称
+		catchName := " __catch"
+		if v.Catch.Parameter != nil {
+			catchName = v.Catch.Parameter.Name
+		}
 		c.newScope()
-		c.scope.bindName(v.Catch.Parameter.Name)
+		c.scope.bindName(catchName)
 		c.scope.lexical = true
 		start := len(c.p.code)
 		c.emit(nil)
 		catchOffset = len(c.p.code) - lbl
-		c.emit(enterCatch(v.Catch.Parameter.Name))
-		c.compileStatement(v.Catch.Body, false)
+		c.emit(enterCatch(catchName))
+		if v.Catch.Pattern != nil {
+			c.compileCatchPattern(v.Catch, catchName)
+		} else {
+			c.compileStatement(v.Catch.Body, false)
+		}
 		dyn1 := c.scope.dynamic
 		accessed1 := c.scope.accessed
 		c.popScope()
@@ -192,6 +216,36 @@ func (c *compiler) compileTryStatement(v *ast.TryStatement) {
 	c.p.code[lbl2] = jump(len(c.p.code) - lbl2)
 	c.leaveBlock()
 }
+// catch块中的let/const、类和函数声明不能与catch参数同名
+func (c *compiler) checkCatchBody(v *ast.CatchStatement) {
+	body, ok := v.Body.(*ast.BlockStatement)
+	if !ok {
+		return
+	}
+	params := make(map[string]bool)
+	if v.Parameter != nil {
+		params[v.Parameter.Name] = true
+	}
+	if v.Pattern != nil {
+		for _, name := range patternNames(v.Pattern, nil) {
+			params[name.Name] = true
+		}
+	}
+	var names []*ast.Identifier
+	for _, decl := range c.lexicalDeclarations(body.List) {
+		for _, item := range decl.List {
+			names = append(names, c.boundNames(item)...)
+		}
+	}
+	for _, f := range c.functionDeclarations(body.List) {
+		names = append(names, f.Function.Name)
+	}
+	for _, name := range names {
+		if params[name.Name] {
+			c.throwSyntaxError(int(name.Idx)-1, "Identifier '%s' has already been declared", name.Name)
+		}
+	}
+}
 // 编译throw语句
 func (c *compiler) compileThrowStatement(v *ast.ThrowStatement) {
 	//c.p.srcMap = append(c.p.srcMap, srcMapItem{pc: len(c.p.code), srcPos: int(v.Throw) - 1})
@@ -231,6 +285,13 @@ func (c *compiler) compileForStatement(v *ast.ForStatement, needResult bool) {
 }
 // 编译for语句
 func (c *compiler) compileLabeledForStatement(v *ast.ForStatement, needResult bool, label string) {
+	var scopeStart int
+	var scopeBlock *block
+	if v.Declaration != nil {
+		scopeStart = c.openBlockScope([]*ast.LexicalDeclaration{v.Declaration})
+		scopeBlock = c.block
+		c.compileLexicalDeclaration(v.Declaration, false)
+	}
 	c.block = &block{
 		typ:        blockLoop,
 		outer:      c.block,
@@ -284,6 +345,11 @@ func (c *compiler) compileLabeledForStatement(v *ast.ForStatement, needResult bo
 	c.markBlockStart()
 	c.compileStatement(v.Body, needResult)
 	c.block.cont = len(c.p.code)
+	if scopeBlock != nil {
+		// 每次迭代使用新的绑定
+		scopeBlock.copies = append(scopeBlock.copies, len(c.p.code))
+		c.emit(nil)
+	}
 	if v.Update != nil {
 		c.compileExpression(v.Update).emitGetter(false)
 	}
@@ -295,8 +361,30 @@ func (c *compiler) compileLabeledForStatement(v *ast.ForStatement, needResult bo
 	}
 end:
 	c.leaveBlock()
+	if scopeBlock != nil {
+		c.closeBlockScope(scopeStart)
+	}
 	c.markBlockStart()
 }
+// 编译解构的catch参数和catch块，模式中的名称和let声明一样绑定在块级作用域中
+func (c *compiler) compileCatchPattern(v *ast.CatchStatement, catchName string) {
+	decl := &ast.LexicalDeclaration{
+		Idx:   v.Pattern.Idx0(),
+		Token: token.LET,
+		List: []*ast.VariableExpression{{
+			Idx:     v.Pattern.Idx0(),
+			Pattern: v.Pattern,
+			Initializer: &ast.Identifier{
+				Name: catchName,
+				Idx:  v.Pattern.Idx0(),
+			},
+		}},
+	}
+	start := c.openBlockScope([]*ast.LexicalDeclaration{decl})
+	c.compileLexicalDeclaration(decl, false)
+	c.compileStatement(v.Body, false)
+	c.closeBlockScope(start)
+}
 // 编译forin语句
 func (c *compiler) compileForInStatement(v *ast.ForInStatement, needResult bool) {
 	c.compileLabeledForInStatement(v, needResult, "")
@@ -319,19 +407,78 @@ func (c *compiler) compileLabeledForInStatement(v *ast.ForInStatement, needResul
 	c.markBlockStart()
 	c.block.cont = start
 	c.emit(nil)
-	c.compileExpression(v.Into).emitSetter(&c.enumGetExpr)
-	c.emit(pop)
+	var scopeStart int
+	if v.Declaration != nil {
+		scopeStart = c.openBlockScope([]*ast.LexicalDeclaration{v.Declaration})
+		c.enumGetExpr.emitGetter(true)
+		c.emitLexicalBinding(v.Declaration.List[0])
+	} else {
+		c.compileExpression(v.Into).emitSetter(&c.enumGetExpr)
+		c.emit(pop)
+	}
 	if needResult {
 		c.emit(pop) // remove last result
 	}
 	c.markBlockStart()
 	c.compileStatement(v.Body, needResult)
+	if v.Declaration != nil {
+		c.closeBlockScope(scopeStart)
+	}
 	c.emit(jump(start - len(c.p.code)))
 	c.p.code[start] = enumNext(len(c.p.code) - start)
 	c.leaveBlock()
 	c.markBlockStart()
 	c.emit(enumPop)
 }
+// 编译for-of语句
+func (c *compiler) compileForOfStatement(v *ast.ForOfStatement, needResult bool) {
+	c.compileLabeledForOfStatement(v, needResult, "")
+}
+// 编译for-of语句，迭代器和for-in一样保存在iterStack中
+func (c *compiler) compileLabeledForOfStatement(v *ast.ForOfStatement, needResult bool, label string) {
+	c.block = &block{
+		typ:        blockLoopEnum,
+		outer:      c.block,
+		label:      label,
+		needResult: needResult,
+	}
+
+	src := c.compileExpression(v.Source)
+	src.emitGetter(true)
+	src.addSrcMap()
+	c.emit(iterate)
+	if needResult {
+		c.emit(loadUndef)
+	}
+	start := len(c.p.code)
+	c.markBlockStart()
+	c.block.cont = start
+	c.emit(nil)
+	var scopeStart int
+	if v.Declaration != nil {
+		scopeStart = c.openBlockScope([]*ast.LexicalDeclaration{v.Declaration})
+		c.enumGetExpr.emitGetter(true)
+		c.emitLexicalBinding(v.Declaration.List[0])
+	} else {
+		c.compileExpression(v.Into).emitSetter(&c.enumGetExpr)
+		c.emit(pop)
+	}
+	if needResult {
+		c.emit(pop) // remove last result
+	}
+	c.markBlockStart()
+	c.compileStatement(v.Body, needResult)
+	if v.Declaration != nil {
+		c.closeBlockScope(scopeStart)
+	}
+	c.emit(jump(start - len(c.p.code)))
+	c.leaveBlock()
+	// break跳到这里，关闭迭代器；迭代完成时iterNext已弹出迭代器
+	c.markBlockStart()
+	c.emit(enumPop)
+	c.p.code[start] = iterNext(len(c.p.code) - start)
+	c.markBlockStart()
+}
 // 编译while语句
 func (c *compiler) compileWhileStatement(v *ast.WhileStatement, needResult bool) {
 	c.compileLabeledWhileStatement(v, needResult, "")
@@ -464,17 +611,24 @@ func (c *compiler) findBreakBlock(label *ast.Identifier) (block *block) {
 // 编译break
 func (c *compiler) compileBreak(label *ast.Identifier, idx file.Idx) {
 	var block *block
+	// 在退出块级作用域等指令之前判断，它们不产生结果
+	atStart := len(c.p.code) == c.blockStart
 	if label != nil {
 		for b := c.block; b != nil; b = b.outer {
+			if b.label == label.Name {
+				block = b
+				break
+			}
 			switch b.typ {
 			case blockTry:
 				c.emit(halt)
 			case blockWith:
 				c.emit(leaveWith)
-			}
-			if b.label == label.Name {
-				block = b
-				break
+			case blockScope:
+				b.exits = append(b.exits, len(c.p.code))
+				c.emit(nil)
+			case blockLoopEnum:
+				c.emit(enumPop)
 			}
 		}
 	} else {
@@ -486,6 +640,9 @@ func (c *compiler) compileBreak(label *ast.Identifier, idx file.Idx) {
 				c.emit(halt)
 			case blockWith:
 				c.emit(leaveWith)
+			case blockScope:
+				b.exits = append(b.exits, len(c.p.code))
+				c.emit(nil)
 			case blockLoop, blockLoopEnum, blockSwitch:
 				block = b
 				break L
@@ -494,7 +651,7 @@ func (c *compiler) compileBreak(label *ast.Identifier, idx file.Idx) {
 	}
 
 	if block != nil {
-		if len(c.p.code) == c.blockStart && block.needResult {
+		if atStart && block.needResult {
 			c.emit(loadUndef)
 		}
 		block.breaks = append(block.breaks, len(c.p.code))
@@ -506,13 +663,20 @@ func (c *compiler) compileBreak(label *ast.Identifier, idx file.Idx) {
 // 编译continue
 func (c *compiler) compileContinue(label *ast.Identifier, idx file.Idx) {
 	var block *block
+	// 在退出块级作用域等指令之前判断，它们不产生结果
+	atStart := len(c.p.code) == c.blockStart
 	if label != nil {
 		for b := c.block; b != nil; b = b.outer {
 			if b.typ == blockTry {
 				c.emit(halt)
+			} else if b.typ == blockScope {
+				b.exits = append(b.exits, len(c.p.code))
+				c.emit(nil)
 			} else if (b.typ == blockLoop || b.typ == blockLoopEnum) && b.label == label.Name {
 				block = b
 				break
+			} else if b.typ == blockLoopEnum {
+				c.emit(enumPop)
 			}
 		}
 	} else {
@@ -520,6 +684,9 @@ func (c *compiler) compileContinue(label *ast.Identifier, idx file.Idx) {
 		for b := c.block; b != nil; b = b.outer {
 			if b.typ == blockTry {
 				c.emit(halt)
+			} else if b.typ == blockScope {
+				b.exits = append(b.exits, len(c.p.code))
+				c.emit(nil)
 			} else if b.typ == blockLoop || b.typ == blockLoopEnum {
 				block = b
 				break
@@ -528,7 +695,7 @@ func (c *compiler) compileContinue(label *ast.Identifier, idx file.Idx) {
 	}
 
 	if block != nil {
-		if len(c.p.code) == c.blockStart && block.needResult {
+		if atStart && block.needResult {
 			c.emit(loadUndef)
 		}
 		block.conts = append(block.conts, len(c.p.code))
@@ -607,6 +774,14 @@ func (c *compiler) compileReturnStatement(v *ast.ReturnStatement) {
 	} else {
 		c.emit(loadUndef)
 	}
+	if nearestNonLexical(c.scope).derived {
+		c.emit(derivedResult)
+	}
+	c.emitReturnExits()
+	c.emit(ret)
+}
+// return之前退出所在的try和for-in/for-of
+func (c *compiler) emitReturnExits() {
 	for b := c.block; b != nil; b = b.outer {
 		switch b.typ {
 		case blockTry:
@@ -615,7 +790,6 @@ func (c *compiler) compileReturnStatement(v *ast.ReturnStatement) {
 			c.emit(enumPop)
 		}
 	}
-	c.emit(ret)
 }
 // 编译变量表达式
 func (c *compiler) compileVariableStatement(v *ast.VariableStatement, needResult bool) {
@@ -626,6 +800,408 @@ func (c *compiler) compileVariableStatement(v *ast.VariableStatement, needResult
 		c.emit(loadUndef)
 	}
 }
+// 编译let/const声明
+func (c *compiler) compileLexicalDeclaration(v *ast.LexicalDeclaration, needResult bool) {
+	for _, item := range v.List {
+		if item.Initializer != nil {
+			c.emitExpr(c.compileExpression(item.Initializer), true)
+		} else {
+			c.emit(loadUndef)
+		}
+		c.emitLexicalBinding(item)
+	}
+	if needResult {
+		c.emit(loadUndef)
+	}
+}
+// 用栈顶的值初始化let/const声明中的一项，解构声明初始化模式中的所有名称
+func (c *compiler) emitLexicalBinding(item *ast.VariableExpression) {
+	if item.Pattern == nil {
+		c.emitLexicalInit(item.Name)
+		return
+	}
+	c.emitDestruct(item.Pattern, func(name *ast.Identifier) {
+		c.emitLexicalInit(name.Name)
+	})
+	c.emit(pop)
+}
+// 返回声明中绑定的名称，解构声明按照名称在模式中出现的顺序
+func (c *compiler) boundNames(item *ast.VariableExpression) []*ast.Identifier {
+	if item.Pattern == nil {
+		return []*ast.Identifier{{Name: item.Name, Idx: item.Idx}}
+	}
+	return patternNames(item.Pattern, nil)
+}
+// 把语句中var声明的名称追加到names中，包括嵌套块中的声明，但不进入函数
+func (c *compiler) varDeclaredNames(st ast.Statement, names []*ast.Identifier) []*ast.Identifier {
+	addVar := func(expr ast.Expression) {
+		if item, ok := expr.(*ast.VariableExpression); ok {
+			names = append(names, c.boundNames(item)...)
+		}
+	}
+	switch st := st.(type) {
+	case *ast.VariableStatement:
+		for _, expr := range st.List {
+			addVar(expr)
+		}
+	case *ast.BlockStatement:
+		for _, s := range st.List {
+			names = c.varDeclaredNames(s, names)
+		}
+	case *ast.IfStatement:
+		names = c.varDeclaredNames(st.Consequent, names)
+		names = c.varDeclaredNames(st.Alternate, names)
+	case *ast.DoWhileStatement:
+		names = c.varDeclaredNames(st.Body, names)
+	case *ast.WhileStatement:
+		names = c.varDeclaredNames(st.Body, names)
+	case *ast.ForStatement:
+		if seq, ok := st.Initializer.(*ast.SequenceExpression); ok {
+			for _, expr := range seq.Sequence {
+				addVar(expr)
+			}
+		}
+		names = c.varDeclaredNames(st.Body, names)
+	case *ast.ForInStatement:
+		addVar(st.Into)
+		names = c.varDeclaredNames(st.Body, names)
+	case *ast.ForOfStatement:
+		addVar(st.Into)
+		names = c.varDeclaredNames(st.Body, names)
+	case *ast.LabelledStatement:
+		names = c.varDeclaredNames(st.Statement, names)
+	case *ast.TryStatement:
+		names = c.varDeclaredNames(st.Body, names)
+		if st.Catch != nil {
+			names = c.varDeclaredNames(st.Catch.Body, names)
+		}
+		names = c.varDeclaredNames(st.Finally, names)
+	case *ast.SwitchStatement:
+		for _, s := range st.Body {
+			for _, cs := range s.Consequent {
+				names = c.varDeclaredNames(cs, names)
+			}
+		}
+	case *ast.WithStatement:
+		names = c.varDeclaredNames(st.Body, names)
+	}
+	return names
+}
+// 块中(包括嵌套的块)var声明的名称不能与块中的let/const和函数声明同名
+func (c *compiler) checkBlockVarNames(list []ast.Statement) {
+	for _, st := range list {
+		for _, name := range c.varDeclaredNames(st, nil) {
+			if _, exists := c.scope.lexNames[name.Name]; exists || c.scope.blockFuncs[name.Name] {
+				c.throwSyntaxError(int(name.Idx)-1, "Identifier '%s' has already been declared", name.Name)
+			}
+		}
+	}
+}
+// 把解构目标中绑定的名称追加到names中
+func patternNames(target ast.Expression, names []*ast.Identifier) []*ast.Identifier {
+	switch target := target.(type) {
+	case *ast.Identifier:
+		names = append(names, target)
+	case *ast.ArrayPattern:
+		for _, elt := range target.Elements {
+			if elt != nil {
+				names = patternNames(elt.Target, names)
+			}
+		}
+		if target.Rest != nil {
+			names = patternNames(target.Rest, names)
+		}
+	case *ast.ObjectPattern:
+		for _, prop := range target.Properties {
+			names = patternNames(prop.Value.Target, names)
+		}
+	}
+	return names
+}
+// 初始化当前作用域中的let/const绑定，栈顶为初始值
+func (c *compiler) emitLexicalInit(name string) {
+	if c.scope.outer == nil {
+		c.emit(initGlobalLex(name))
+	} else {
+		c.emit(setLocalP(c.scope.names[name]))
+	}
+}
+// 返回语句列表中直接包含的let/const声明，类声明与let相同，export default的表达式绑定到*default*
+func (c *compiler) lexicalDeclarations(list []ast.Statement) (decls []*ast.LexicalDeclaration) {
+	for _, st := range list {
+		switch st := st.(type) {
+		case *ast.ExportDeclaration:
+			if st.Declaration != nil {
+				decls = append(decls, c.lexicalDeclarations([]ast.Statement{st.Declaration})...)
+			} else if st.Expression != nil {
+				decls = append(decls, &ast.LexicalDeclaration{
+					Idx:   st.Export,
+					Token: token.LET,
+					List: []*ast.VariableExpression{
+						{Name: defaultExportName, Idx: st.Export},
+					},
+				})
+			}
+		case *ast.LexicalDeclaration:
+			decls = append(decls, st)
+		case *ast.ClassDeclaration:
+			name := st.Class.Name
+			decls = append(decls, &ast.LexicalDeclaration{
+				Idx:   name.Idx,
+				Token: token.LET,
+				List: []*ast.VariableExpression{
+					{Name: name.Name, Idx: name.Idx},
+				},
+			})
+		}
+	}
+	return
+}
+// 返回语句列表中直接包含的函数声明，包括带标签的函数声明
+func (c *compiler) functionDeclarations(list []ast.Statement) (funcs []*ast.FunctionDeclaration) {
+	for _, st := range list {
+		for {
+			l, ok := st.(*ast.LabelledStatement)
+			if !ok {
+				break
+			}
+			st = l.Statement
+		}
+		if f, ok := st.(*ast.FunctionDeclaration); ok {
+			funcs = append(funcs, f)
+		}
+	}
+	return
+}
+// 在当前的块级作用域中创建块中声明的函数。函数的绑定与let相同，但非严格模式下允许重复声明
+func (c *compiler) compileBlockFunctions(funcs []*ast.FunctionDeclaration) {
+	if len(funcs) == 0 {
+		return
+	}
+	if c.scope.blockFuncs == nil {
+		c.scope.blockFuncs = make(map[string]bool)
+	}
+	for _, f := range funcs {
+		name := f.Function.Name
+		if _, exists := c.scope.names[name.Name]; exists {
+			if c.scope.strict || !c.scope.blockFuncs[name.Name] {
+				c.throwSyntaxError(int(name.Idx)-1, "Identifier '%s' has already been declared", name.Name)
+			}
+			continue
+		}
+		c.scope.names[name.Name] = uint32(len(c.scope.names))
+		c.scope.blockFuncs[name.Name] = true
+	}
+	for _, f := range funcs {
+		c.compileFunctionLiteral(f.Function, false).emitGetter(true)
+		c.emit(setLocalP(c.scope.names[f.Function.Name.Name]))
+	}
+}
+// 编译函数声明语句。函数已经在进入函数或块时创建，块中的函数声明在非严格模式下
+// 按附录B.3.3把块中的绑定复制到函数作用域中同名的var
+func (c *compiler) compileFunctionDeclaration(v *ast.FunctionDeclaration, needResult bool) {
+	name := v.Function.Name.Name
+	if s := c.scope; s.block && s.blockFuncs[name] && !s.strict {
+		var level uint32 = 1
+		outer := s.outer
+		for ; outer != nil && (outer.block && outer.params == nil || outer.lexical); outer = outer.outer {
+			// 中间的块中有同名的绑定时不复制
+			if _, exists := outer.names[name]; exists {
+				outer = nil
+				break
+			}
+			level++
+		}
+		if outer != nil && outer.funcVars[name] {
+			c.emit(getLocal(s.names[name]))
+			if outer.outer == nil {
+				// 全局代码和eval代码的var在运行时才绑定
+				c.emit(setBlockFuncVar(name))
+			} else {
+				c.emit(setLocalP(level<<24 | outer.names[name]))
+			}
+		}
+	}
+	if needResult {
+		c.emit(loadUndef)
+	}
+}
+// 编译类声明
+func (c *compiler) compileClassDeclaration(v *ast.ClassDeclaration, needResult bool) {
+	c.compileClassLiteral(v.Class).emitGetter(true)
+	c.emitLexicalInit(v.Class.Name.Name)
+	if needResult {
+		c.emit(loadUndef)
+	}
+}
+// 编译export声明，函数声明已经提前创建
+func (c *compiler) compileExportDeclaration(v *ast.ExportDeclaration, needResult bool) {
+	switch {
+	case v.Declaration != nil:
+		c.compileStatement(v.Declaration, needResult)
+	case v.Expression != nil:
+		expr := c.compileExpression(v.Expression)
+		if cls, ok := expr.(*compiledClassLiteral); ok && cls.expr.Name == nil {
+			cls.name = "default"
+		}
+		c.emitExpr(expr, true)
+		c.emitLexicalInit(defaultExportName)
+		if needResult {
+			c.emit(loadUndef)
+		}
+	default:
+		c.compileEmptyStatement(needResult)
+	}
+}
+// 检查let/const声明的名称
+func (c *compiler) checkLexicalName(name string, offset int) {
+	if c.scope.strict {
+		c.checkIdentifierLName(name, offset)
+		c.checkIdentifierName(name, offset)
+	}
+	if name == "let" {
+		c.throwSyntaxError(offset, "let is disallowed as a lexically bound name")
+	}
+}
+// 为let/const声明创建块级作用域，返回占位指令的位置
+func (c *compiler) openBlockScope(decls []*ast.LexicalDeclaration) int {
+	c.newScope()
+	c.scope.block = true
+	c.scope.lexNames = make(map[string]bool)
+	for _, decl := range decls {
+		for _, item := range decl.List {
+			for _, name := range c.boundNames(item) {
+				c.checkLexicalName(name.Name, int(name.Idx)-1)
+				if _, exists := c.scope.names[name.Name]; exists {
+					c.throwSyntaxError(int(name.Idx)-1, "Identifier '%s' has already been declared", name.Name)
+				}
+				c.scope.names[name.Name] = uint32(len(c.scope.names))
+				c.scope.lexNames[name.Name] = decl.Token == token.CONST
+			}
+		}
+	}
+	c.block = &block{
+		typ:   blockScope,
+		outer: c.block,
+	}
+	start := len(c.p.code)
+	c.emit(nil)
+	// 块可能被重复执行(比如在循环中)，每次进入时恢复暂时性死区
+	for _, decl := range decls {
+		for _, item := range decl.List {
+			for _, name := range c.boundNames(item) {
+				c.emit(loadNil, setLocalP(c.scope.names[name.Name]))
+			}
+		}
+	}
+	c.markBlockStart()
+	return start
+}
+// 参数列表有表达式时为函数体的var和函数声明创建单独的作用域，与参数同名的var以参数的值初始化
+func (c *compiler) openFuncBodyScope(hoisted []ast.Declaration, nested []*ast.FunctionDeclaration, decls []*ast.LexicalDeclaration, params []*ast.Identifier) int {
+	c.newScope()
+	c.scope.block = true
+	c.scope.params = make(map[string]bool)
+	for _, name := range params {
+		c.scope.params[name.Name] = true
+	}
+	c.bindBlockFunctionVars(nested, decls)
+	c.compileDeclList(hoisted, true)
+	c.block = &block{
+		typ:   blockScope,
+		outer: c.block,
+	}
+	start := len(c.p.code)
+	c.emit(nil)
+	names := make([]string, len(c.scope.names))
+	for name, idx := range c.scope.names {
+		names[idx] = name
+	}
+	for idx, name := range names {
+		if c.scope.params[name] {
+			c.emit(getLocal(1<<24 | c.scope.outer.names[name]))
+		} else {
+			c.emit(loadUndef)
+		}
+		c.emit(setLocalP(idx))
+	}
+	c.markBlockStart()
+	return start
+}
+// 退出块级作用域。没有被闭包或eval访问的块不需要单独的stash，其中的绑定被移到外层作用域
+func (c *compiler) closeBlockScope(start int) {
+	b := c.block
+	s := c.scope
+	b.exits = append(b.exits, len(c.p.code))
+	c.emit(nil)
+	c.block = b.outer
+	c.popScope()
+
+	outer := c.scope
+	if s.dynamic || s.accessed || outer.outer == nil || outer.dynamic && outer.lexical {
+		consts := make(map[string]bool)
+		for name, isConst := range s.lexNames {
+			if isConst {
+				consts[name] = true
+			}
+		}
+		c.p.code[start] = &enterBlock{names: s.names, consts: consts, size: uint32(len(s.names))}
+		for _, pc := range b.exits {
+			c.p.code[pc] = exitBlock
+		}
+		for _, pc := range b.copies {
+			c.p.code[pc] = copyStash
+		}
+		// 外层的作用域需要真正的stash
+		for sc := outer; sc != nil; sc = sc.outer {
+			sc.accessed = true
+			if !sc.block && !sc.lexical {
+				break
+			}
+		}
+		return
+	}
+
+	c.p.code[start] = jump(1)
+	for _, pc := range b.exits {
+		c.p.code[pc] = jump(1)
+	}
+	for _, pc := range b.copies {
+		c.p.code[pc] = jump(1)
+	}
+	m := make(map[uint32]uint32)
+	remap := func(instr uint32) uint32 {
+		level := instr >> 24
+		idx := instr & 0x00FFFFFF
+		if level > 0 {
+			level--
+			return (level << 24) | idx
+		}
+		newIdx, exists := m[idx]
+		if !exists {
+			newIdx = outer.bindTmp()
+			m[idx] = newIdx
+		}
+		return newIdx
+	}
+	code := c.p.code[start+1:]
+	for pc, instr := range code {
+		switch instr := instr.(type) {
+		case getLocal:
+			code[pc] = getLocal(remap(uint32(instr)))
+		case setLocal:
+			code[pc] = setLocal(remap(uint32(instr)))
+		case setLocalP:
+			code[pc] = setLocalP(remap(uint32(instr)))
+		case getVar:
+			instr.idx = remap(instr.idx)
+			code[pc] = instr
+		case resolveVar:
+			instr.idx = remap(instr.idx)
+			code[pc] = instr
+		}
+	}
+}
 //返回中第一个非空语句
 func (c *compiler) getFirstNonEmptyStatement(st ast.Statement) ast.Statement {
 	switch st := st.(type) {
@@ -657,29 +1233,36 @@ func (c *compiler) compileStatements(list []ast.Statement, needResult bool) {
 		cur := list[0]
 		for idx := 0; idx < len(list); {
 			var next ast.Statement
+			// 函数声明的完成值为空，不作为语句列表的结果，跟在cur之后编译
+			var funcs []ast.Statement
 			// find next non-empty statement 查找下一个非空语句
 			for idx++; idx < len(list); idx++ {
-				if _, empty := list[idx].(*ast.EmptyStatement); !empty {
-					next = list[idx]
-					break
+				if _, empty := list[idx].(*ast.EmptyStatement); empty {
+					continue
+				}
+				if _, ok := list[idx].(*ast.FunctionDeclaration); ok {
+					funcs = append(funcs, list[idx])
+					continue
 				}
+				next = list[idx]
+				break
 			}
 
+			curNeedResult := needResult
 			if next != nil {
+				curNeedResult = false
 				bs := c.getFirstNonEmptyStatement(next)
 				if bs, ok := bs.(*ast.BranchStatement); ok {
-					block := c.findBranchBlock(bs)
-					if block != nil {
-						c.compileStatement(cur, block.needResult)
-						cur = next
-						continue
+					if block := c.findBranchBlock(bs); block != nil {
+						curNeedResult = block.needResult
 					}
 				}
-				c.compileStatement(cur, false)
-				cur = next
-			} else {
-				c.compileStatement(cur, needResult)
 			}
+			c.compileStatement(cur, curNeedResult)
+			for _, f := range funcs {
+				c.compileStatement(f, false)
+			}
+			cur = next
 		}
 	} else {
 		if needResult {
@@ -700,6 +1283,16 @@ func (c *compiler) compileGenericLabeledStatement(v ast.Statement, needResult bo
 }
 //编译块语句
 func (c *compiler) compileBlockStatement(v *ast.BlockStatement, needResult bool) {
+	decls := c.lexicalDeclarations(v.List)
+	funcs := c.functionDeclarations(v.List)
+	if len(decls) > 0 || len(funcs) > 0 {
+		start := c.openBlockScope(decls)
+		c.compileBlockFunctions(funcs)
+		c.checkBlockVarNames(v.List)
+		c.compileStatements(v.List, needResult)
+		c.closeBlockScope(start)
+		return
+	}
 	c.compileStatements(v.List, needResult)
 }
 // 编译表达式语句
@@ -735,14 +1328,28 @@ func (c *compiler) compileWithStatement(v *ast.WithStatement, needResult bool) {
 }
 // 编译switch语句
 func (c *compiler) compileSwitchStatement(v *ast.SwitchStatement, needResult bool) {
+	c.compileExpression(v.Discriminant).emitGetter(true)
+
+	var decls []*ast.LexicalDeclaration
+	var funcs []*ast.FunctionDeclaration
+	for _, s := range v.Body {
+		decls = append(decls, c.lexicalDeclarations(s.Consequent)...)
+		funcs = append(funcs, c.functionDeclarations(s.Consequent)...)
+	}
+	scopeStart := -1
+	if len(decls) > 0 || len(funcs) > 0 {
+		scopeStart = c.openBlockScope(decls)
+		c.compileBlockFunctions(funcs)
+		for _, s := range v.Body {
+			c.checkBlockVarNames(s.Consequent)
+		}
+	}
 	c.block = &block{
 		typ:        blockSwitch,
 		outer:      c.block,
 		needResult: needResult,
 	}
 
-	c.compileExpression(v.Discriminant).emitGetter(true)
-
 	jumps := make([]int, len(v.Body))
 
 	for i, s := range v.Body {
@@ -801,5 +1408,8 @@ func (c *compiler) compileSwitchStatement(v *ast.SwitchStatement, needResult boo
 		}
 	}
 	c.leaveBlock()
+	if scopeStart != -1 {
+		c.closeBlockScope(scopeStart)
+	}
 	c.markBlockStart()
 }
@@ -1993,6 +1993,1352 @@ func TestIfStackLeaks(t *testing.T) {
 	testScript1(SCRIPT, _positiveZero, t)
 }
 
+func TestLetBlockScope(t *testing.T) {
+	const SCRIPT = `
+	var res = [];
+	let x = 1;
+	{
+		let x = 2;
+		res.push(x);
+		{
+			const x = 3;
+			res.push(x);
+		}
+		res.push(x);
+	}
+	res.push(x);
+	res.join(",");
+	`
+	testScript1(SCRIPT, asciiString("2,3,2,1"), t)
+}
+
+func TestLetInFunction(t *testing.T) {
+	const SCRIPT = `
+	function f(a) {
+		let b = a + 1;
+		if (a > 0) {
+			let b = 10;
+			a += b;
+		}
+		return a + b;
+	}
+	f(1);
+	`
+	testScript1(SCRIPT, intToValue(13), t)
+}
+
+func TestLetClosureInFunction(t *testing.T) {
+	const SCRIPT = `
+	function f() {
+		let x = 1;
+		function inc() {
+			return ++x;
+		}
+		inc();
+		return inc() + x;
+	}
+	f();
+	`
+	testScript1(SCRIPT, intToValue(6), t)
+}
+
+func TestLetForPerIteration(t *testing.T) {
+	const SCRIPT = `
+	var fns = [];
+	for (let i = 0; i < 3; i++) {
+		fns.push(function() { return i; });
+	}
+	fns[0]() + "" + fns[1]() + fns[2]();
+	`
+	testScript1(SCRIPT, asciiString("012"), t)
+}
+
+func TestLetForInPerIteration(t *testing.T) {
+	const SCRIPT = `
+	var fns = [];
+	for (const k in {a: 1, b: 2}) {
+		fns.push(function() { return k; });
+	}
+	fns[0]() + fns[1]();
+	`
+	testScript1(SCRIPT, asciiString("ab"), t)
+}
+
+func TestLetLoopBreakContinue(t *testing.T) {
+	const SCRIPT = `
+	var fns = [];
+	outer: for (let i = 0; i < 5; i++) {
+		for (let j = 0; j < 5; j++) {
+			let k = i * 10 + j;
+			fns.push(function() { return k; });
+			if (j == 1) {
+				continue outer;
+			}
+			if (i == 2) {
+				break outer;
+			}
+		}
+	}
+	var res = [];
+	for (var n = 0; n < fns.length; n++) {
+		res.push(fns[n]());
+	}
+	res.join(",");
+	`
+	testScript1(SCRIPT, asciiString("0,1,10,11,20"), t)
+}
+
+func TestLetTDZ(t *testing.T) {
+	const SCRIPT = `
+	function f() {
+		try {
+			x;
+		} catch (e) {
+			if (e instanceof ReferenceError) {
+				return true;
+			}
+		}
+		let x = 1;
+		return false;
+	}
+	f();
+	`
+	testScript1(SCRIPT, valueTrue, t)
+}
+
+func TestLetTDZInClosure(t *testing.T) {
+	const SCRIPT = `
+	var res = [];
+	{
+		function get() {
+			return y;
+		}
+		try {
+			get();
+		} catch (e) {
+			res.push(e.name);
+		}
+		let y = 2;
+	}
+	res.join();
+	`
+	testScript1(SCRIPT, asciiString("ReferenceError"), t)
+}
+
+func TestLetTDZInLoop(t *testing.T) {
+	const SCRIPT = `
+	var res = [];
+	for (var i = 0; i < 2; i++) {
+		try {
+			res.push(x);
+		} catch (e) {
+			res.push(e.name);
+		}
+		let x = i;
+	}
+	res.join();
+	`
+	testScript1(SCRIPT, asciiString("ReferenceError,ReferenceError"), t)
+}
+
+func TestConstAssign(t *testing.T) {
+	const SCRIPT = `
+	var res = [];
+	const a = 1;
+	try {
+		a = 2;
+	} catch (e) {
+		res.push(e instanceof TypeError);
+	}
+	(function() {
+		const b = 1;
+		try {
+			b++;
+		} catch (e) {
+			res.push(e instanceof TypeError);
+		}
+		(function() {
+			try {
+				b += 1;
+			} catch (e) {
+				res.push(e instanceof TypeError);
+			}
+		})();
+	})();
+	res.push(a);
+	res.join();
+	`
+	testScript1(SCRIPT, asciiString("true,true,true,1"), t)
+}
+
+func TestLetSwitch(t *testing.T) {
+	const SCRIPT = `
+	function f(v) {
+		switch (v) {
+		case 1:
+			let x = "one";
+			return x;
+		default:
+			x = "other";
+			return x;
+		}
+	}
+	var res = f(1);
+	try {
+		f(2);
+	} catch (e) {
+		res += e.name;
+	}
+	res;
+	`
+	testScript1(SCRIPT, asciiString("oneReferenceError"), t)
+}
+
+func TestLetEval(t *testing.T) {
+	const SCRIPT = `
+	function f() {
+		let x = 1;
+		{
+			let y = 2;
+			eval("var z = x + y");
+		}
+		eval("let w = 10");
+		return z + typeof w;
+	}
+	f();
+	`
+	testScript1(SCRIPT, asciiString("3undefined"), t)
+}
+
+func TestLetGlobal(t *testing.T) {
+	vm := New()
+	_, err := vm.RunString(`
+	let x = 1;
+	const y = 2;
+	function f() {
+		return x + y;
+	}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := vm.RunString(`x = 5; f() + ("x" in this ? 1 : 0)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.SameAs(intToValue(7)) {
+		t.Fatalf("Unexpected result: %v", v)
+	}
+	if _, err := vm.RunString(`y = 1`); err == nil {
+		t.Fatal("Expected an error")
+	} else if e := err.Error(); e != "TypeError: Assignment to constant variable. at <eval>:1:1(2)" {
+		t.Fatalf("Unexpected error: '%s'", e)
+	}
+	if _, err := vm.RunString(`var x;`); err == nil {
+		t.Fatal("Expected an error")
+	} else if e := err.Error(); e != "SyntaxError: Identifier 'x' has already been declared at <eval>:1:1(0)" {
+		t.Fatalf("Unexpected error: '%s'", e)
+	}
+}
+
+func TestLetRedeclare(t *testing.T) {
+	for _, src := range []string{
+		"let a; let a;",
+		"var a; let a;",
+		"{ let a; const a = 1; }",
+		"function f(a) { let a; }",
+		"function f() { var a; let a; }",
+		"try {} catch (e) { let e; }",
+		"try {} catch ({a}) { const a = 1; }",
+		"try {} catch (e) { function e() {} }",
+		"{ let f; function f() {} }",
+		"'use strict'; { function f() {} function f() {} }",
+		"{ let w; var w; }",
+		"{ let q; { var q; } }",
+		"{ const c = 1; for (var c in {}); }",
+		"{ function f() {} var f; }",
+		"switch (0) { case 0: let s; default: var s; }",
+	} {
+		if _, err := Compile("test.js", src, false); err == nil {
+			t.Fatalf("Expected an error compiling '%s'", src)
+		}
+	}
+	for _, src := range []string{
+		"{ let z; function h() { var z; } }",
+		"{ var a; } { let a; }",
+		"try {} catch (e) { var e; }",
+	} {
+		if _, err := Compile("test.js", src, false); err != nil {
+			t.Fatalf("Unexpected error compiling '%s': %v", src, err)
+		}
+	}
+}
+
+func TestBlockFunctionScope(t *testing.T) {
+	const SCRIPT = `
+	var res = [];
+	if (true) {
+		let h = 3;
+		function g() {
+			return h;
+		}
+		res.push(g());
+	}
+	function f() {
+		var before = typeof inner;
+		{
+			let x = 1;
+			function inner() {
+				return x;
+			}
+		}
+		return before + inner();
+	}
+	res.push(f());
+	var fns = [];
+	for (let i = 0; i < 3; i++) {
+		function q() {
+			return i;
+		}
+		fns.push(q);
+	}
+	res.push(fns.map(function(fn) { return fn(); }).join(""));
+	res.join(",");
+	`
+	testScript1(SCRIPT, asciiString("3,undefined1,012"), t)
+}
+
+func TestBlockFunctionAnnexB(t *testing.T) {
+	const SCRIPT = `
+	function single() {
+		if (true) function f() { return 1; }
+		return f();
+	}
+	function strict() {
+		"use strict";
+		{
+			function f() {}
+		}
+		return typeof f;
+	}
+	function shadowed() {
+		let f = 5;
+		{
+			function f() {}
+		}
+		return f;
+	}
+	function param(f) {
+		{
+			function f() {}
+		}
+		return f;
+	}
+	{
+		function glob() {
+			return 2;
+		}
+	}
+	assert.sameValue(single(), 1, "single statement");
+	assert.sameValue(strict(), "undefined", "strict mode");
+	assert.sameValue(shadowed(), 5, "let binding");
+	assert.sameValue(param(7), 7, "parameter");
+	assert.sameValue(glob(), 2, "global");
+	assert.sameValue(eval("1; function decl() {}"), 1, "completion value");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestArrowFunction(t *testing.T) {
+	const SCRIPT = `
+	var add = (a, b) => a + b;
+	var sq = x => { return x * x; };
+	[1, 2, 3].map(x => add(sq(x), 1)).join(",");
+	`
+	testScript1(SCRIPT, asciiString("2,5,10"), t)
+}
+
+func TestArrowFunctionThis(t *testing.T) {
+	const SCRIPT = `
+	var o = {
+		v: 2,
+		m: function() {
+			var f = () => () => this.v;
+			return f()() + f.call({v: 10})();
+		}
+	};
+	o.m();
+	`
+	testScript1(SCRIPT, intToValue(4), t)
+}
+
+func TestArrowFunctionGlobalThis(t *testing.T) {
+	const SCRIPT = `
+	var f = () => this;
+	f.call({}) === this;
+	`
+	testScript1(SCRIPT, valueTrue, t)
+}
+
+func TestArrowFunctionArguments(t *testing.T) {
+	const SCRIPT = `
+	function f() {
+		var g = x => arguments[0] + x;
+		return g(1);
+	}
+	f(5);
+	`
+	testScript1(SCRIPT, intToValue(6), t)
+}
+
+func TestArrowFunctionNotConstructor(t *testing.T) {
+	const SCRIPT = `
+	var f = () => {};
+	var res;
+	try {
+		new f();
+	} catch (e) {
+		res = e instanceof TypeError;
+	}
+	res && f.prototype === undefined && !f.hasOwnProperty("prototype");
+	`
+	testScript1(SCRIPT, valueTrue, t)
+}
+
+func TestClass(t *testing.T) {
+	const SCRIPT = `
+	class A {
+		constructor(x) {
+			this.x = x;
+		}
+		get double() {
+			return this.x * 2;
+		}
+		static create(x) {
+			return new A(x);
+		}
+		describe() {
+			return "A" + this.x;
+		}
+	}
+	var a = A.create(3);
+	[a.describe(), a.double, a instanceof A, typeof A, Object.keys(A.prototype).length].join();
+	`
+	testScript1(SCRIPT, asciiString("A3,6,true,function,0"), t)
+}
+
+func TestClassExtends(t *testing.T) {
+	const SCRIPT = `
+	class A {
+		constructor(x) {
+			this.x = x;
+		}
+		describe() {
+			return "A" + this.x;
+		}
+		static kind() {
+			return "a";
+		}
+	}
+	class B extends A {
+		constructor(x, y) {
+			super(x);
+			this.y = y;
+		}
+		describe() {
+			var f = () => super.describe();
+			return "B" + f() + this.y;
+		}
+		static kind() {
+			return super.kind() + "b";
+		}
+	}
+	class C extends B {}
+	var c = new C(1, 2);
+	[c.describe(), C.kind(), c instanceof A, Object.getPrototypeOf(C) === B].join();
+	`
+	testScript1(SCRIPT, asciiString("BA12,ab,true,true"), t)
+}
+
+func TestClassExtendsBuiltin(t *testing.T) {
+	const SCRIPT = `
+	class MyError extends Error {
+		constructor(msg) {
+			super(msg);
+			this.name = "MyError";
+		}
+	}
+	var e = new MyError("oops");
+	e instanceof MyError && e instanceof Error && e.toString() === "MyError: oops";
+	`
+	testScript1(SCRIPT, valueTrue, t)
+}
+
+func TestClassCallWithoutNew(t *testing.T) {
+	const SCRIPT = `
+	class A {}
+	var res;
+	try {
+		A();
+	} catch (e) {
+		res = e instanceof TypeError;
+	}
+	res;
+	`
+	testScript1(SCRIPT, valueTrue, t)
+}
+
+func TestClassThisBeforeSuper(t *testing.T) {
+	const SCRIPT = `
+	class B extends Object {
+		constructor() {
+			this.x = 1;
+			super();
+		}
+	}
+	var res;
+	try {
+		new B();
+	} catch (e) {
+		res = e instanceof ReferenceError;
+	}
+	res;
+	`
+	testScript1(SCRIPT, valueTrue, t)
+}
+
+func TestClassName(t *testing.T) {
+	const SCRIPT = `
+	var res;
+	try {
+		new A();
+	} catch (e) {
+		res = e instanceof ReferenceError;
+	}
+	class A {}
+	var C = class D {
+		self() {
+			return D;
+		}
+	};
+	res && new C().self() === C && typeof D === "undefined";
+	`
+	testScript1(SCRIPT, valueTrue, t)
+}
+
+func TestClassComputedKeys(t *testing.T) {
+	const SCRIPT = `
+	var k = "val";
+	class A {
+		*[Symbol.iterator]() {
+			yield 1;
+			yield 2;
+		}
+		static [Symbol.hasInstance](v) {
+			return v === 42;
+		}
+		get [k]() {
+			return this._v;
+		}
+		set [k](v) {
+			this._v = v;
+		}
+		["constructor"]() {
+			return "method";
+		}
+		[k + "2"]() {
+			return super.toString === Object.prototype.toString;
+		}
+	}
+	var a = new A();
+	assert.sameValue([...a].join(), "1,2", "iterator");
+	assert.sameValue(42 instanceof A, true, "hasInstance");
+	assert.sameValue(a instanceof A, false, "hasInstance");
+	a.val = 3;
+	assert.sameValue(a.val, 3, "accessor");
+	assert.sameValue(a.constructor(), "method", "computed constructor is a method");
+	assert.sameValue(a.val2(), true, "super");
+	assert.sameValue(Object.getOwnPropertyDescriptor(A.prototype, "val2").enumerable, false, "enumerable");
+	assert.throws(TypeError, function() {
+		class B {
+			static ["proto" + "type"]() {}
+		}
+	});
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestClassSuperOutsideMethod(t *testing.T) {
+	for _, src := range []string{
+		"class A { constructor() { super(); } }",
+		"class A extends Object { m() { super(); } }",
+		"function f() { return super.x; }",
+		"class A {} class A {}",
+	} {
+		if _, err := Compile("test.js", src, false); err == nil {
+			t.Fatalf("Expected an error compiling '%s'", src)
+		}
+	}
+}
+
+func TestForOf(t *testing.T) {
+	const SCRIPT = `
+	var res = [];
+	for (var x of [1, 2, 3]) {
+		if (x === 2) {
+			continue;
+		}
+		res.push(x);
+	}
+	for (let c of "ab") {
+		res.push(c);
+	}
+	res.join();
+	`
+	testScript1(SCRIPT, asciiString("1,3,a,b"), t)
+}
+
+func TestForOfLetClosure(t *testing.T) {
+	const SCRIPT = `
+	var fns = [];
+	for (const x of [1, 2, 3]) {
+		fns.push(() => x);
+	}
+	fns[0]() + fns[1]() * 10 + fns[2]() * 100;
+	`
+	testScript1(SCRIPT, intToValue(321), t)
+}
+
+func TestForOfClose(t *testing.T) {
+	const SCRIPT = `
+	var closed = 0;
+	var iterable = {};
+	iterable[Symbol.iterator] = function() {
+		var i = 0;
+		return {
+			next: function() {
+				return {value: i++, done: false};
+			},
+			"return": function() {
+				closed++;
+				return {};
+			}
+		};
+	};
+	for (var v of iterable) {
+		if (v === 3) {
+			break;
+		}
+	}
+	function f() {
+		for (var v of iterable) {
+			return v;
+		}
+	}
+	f();
+	outer: for (var a of [1, 2]) {
+		for (var v of iterable) {
+			for (var k in {x: 1}) {
+				continue outer;
+			}
+		}
+	}
+	closed;
+	`
+	testScript1(SCRIPT, intToValue(4), t)
+}
+
+func TestForOfCloseOnThrow(t *testing.T) {
+	const SCRIPT = `
+	var closed = 0;
+	var nextThrows = false;
+	var iterable = {};
+	iterable[Symbol.iterator] = function() {
+		var i = 0;
+		return {
+			next: function() {
+				if (nextThrows) {
+					throw new Error("next");
+				}
+				return {value: i++, done: false};
+			},
+			"return": function() {
+				closed++;
+				throw new Error("return");
+			}
+		};
+	};
+	assert.throws(TypeError, function() {
+		for (var v of iterable) {
+			throw new TypeError("body");
+		}
+	});
+	assert.sameValue(closed, 1, "throw in body");
+
+	assert.throws(TypeError, function() {
+		for (var v of iterable) {
+			for (var w of iterable) {
+				null.x;
+			}
+		}
+	});
+	assert.sameValue(closed, 3, "nested loops");
+
+	nextThrows = true;
+	assert.throws(Error, function() {
+		for (var v of iterable) {}
+	});
+	assert.sameValue(closed, 3, "exception from next()");
+
+	var log = [];
+	function* gen() {
+		try {
+			yield 1;
+			yield 2;
+		} finally {
+			log.push("finally");
+		}
+	}
+	try {
+		for (var v of gen()) {
+			log.push(v);
+			throw 42;
+		}
+	} catch (e) {
+		log.push(e);
+	}
+	assert.sameValue(log.join(), "1,finally,42");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestForOfNotIterable(t *testing.T) {
+	const SCRIPT = `
+	var res;
+	try {
+		for (var x of {}) {}
+	} catch (e) {
+		res = e instanceof TypeError;
+	}
+	res;
+	`
+	testScript1(SCRIPT, valueTrue, t)
+}
+
+func TestSpread(t *testing.T) {
+	const SCRIPT = `
+	function sum() {
+		var s = 0;
+		for (var i = 0; i < arguments.length; i++) {
+			s += arguments[i];
+		}
+		return s;
+	}
+	function P(a, b) {
+		this.v = a - b;
+	}
+	var a = [0, ...[1, 2], , ...'ab'];
+	a.length === 6 && !(3 in a) && a[4] === "a" &&
+		sum(...[1, 2], 3, ...[4]) === 10 &&
+		Math.max(...[1, 5, 3]) === 5 &&
+		new P(...[5, 2]).v === 3;
+	`
+	testScript1(SCRIPT, valueTrue, t)
+}
+
+func TestSpreadSuper(t *testing.T) {
+	const SCRIPT = `
+	class A {
+		constructor(a, b) {
+			this.s = a + b;
+		}
+	}
+	class B extends A {
+		constructor(args) {
+			super(...args);
+		}
+	}
+	new B([1, 2]).s;
+	`
+	testScript1(SCRIPT, intToValue(3), t)
+}
+
+func TestGenerator(t *testing.T) {
+	const SCRIPT = `
+	function* g(a) {
+		var x = yield a;
+		var y = 1 + (yield x * 2);
+		return x + y;
+	}
+	var it = g(5);
+	var res = [];
+	res.push(it.next(1).value);
+	res.push(it.next(10).value);
+	var last = it.next(100);
+	res.push(last.value, last.done, it.next().done);
+	res.join();
+	`
+	testScript1(SCRIPT, asciiString("5,20,111,true,true"), t)
+}
+
+func TestGeneratorTryFinally(t *testing.T) {
+	const SCRIPT = `
+	var log = [];
+	function* g() {
+		try {
+			try {
+				yield 1;
+				yield 2;
+			} finally {
+				log.push("inner");
+			}
+		} finally {
+			log.push("outer");
+		}
+	}
+	var it = g();
+	it.next();
+	var r = it.return(5);
+	log.push(r.value, r.done);
+
+	function* h() {
+		try {
+			yield 1;
+		} catch (e) {
+			log.push("caught " + e);
+			yield 2;
+		}
+	}
+	it = h();
+	it.next();
+	log.push(it.throw("E").value);
+	log.join();
+	`
+	testScript1(SCRIPT, asciiString("inner,outer,5,true,caught E,2"), t)
+}
+
+func TestGeneratorLoops(t *testing.T) {
+	const SCRIPT = `
+	function* nat() {
+		let i = 0;
+		while (true) {
+			yield i++;
+		}
+	}
+	var res = [];
+	for (var x of nat()) {
+		if (x > 3) {
+			break;
+		}
+		res.push(x);
+	}
+	function* keys(o) {
+		for (var k in o) {
+			for (var c of k) {
+				yield c;
+			}
+		}
+	}
+	res.push(...keys({ab: 1, c: 2}));
+	res.join("");
+	`
+	testScript1(SCRIPT, asciiString("0123abc"), t)
+}
+
+func TestGeneratorDelegate(t *testing.T) {
+	const SCRIPT = `
+	function* inner() {
+		yield 2;
+		return 3;
+	}
+	function* outer() {
+		yield 1;
+		var r = yield* inner();
+		yield r;
+		yield* "ab";
+	}
+	[...outer()].join();
+	`
+	testScript1(SCRIPT, asciiString("1,2,3,a,b"), t)
+}
+
+func TestGeneratorNotConstructor(t *testing.T) {
+	const SCRIPT = `
+	function* g() {}
+	var res;
+	try {
+		new g();
+	} catch (e) {
+		res = e instanceof TypeError;
+	}
+	res;
+	`
+	testScript1(SCRIPT, valueTrue, t)
+}
+
+func TestAsyncFunction(t *testing.T) {
+	const SCRIPT = `
+	var log = [];
+	async function f(a) {
+		log.push(a);
+		var b = await (a + 1);
+		log.push(b);
+		return b + 1;
+	}
+	f(1).then(v => log.push(v));
+	log.push("sync");
+	log.join();
+	`
+	testScript1(SCRIPT, asciiString("1,sync"), t)
+}
+
+func TestTemplateLiteral(t *testing.T) {
+	// 脚本中含有`，不能使用原始字符串
+	const SCRIPT = "" +
+		"var a = 1, b = \"x\";\n" +
+		"var o = {\n" +
+		"\tvalueOf: function() { return 42; },\n" +
+		"\ttoString: function() { return \"obj\"; }\n" +
+		"};\n" +
+		"assert.sameValue(``, \"\");\n" +
+		"assert.sameValue(`a${a + 1}b${b}`, \"a2bx\");\n" +
+		"assert.sameValue(`${o}`, \"obj\", \"ToString, not ToPrimitive\");\n" +
+		"assert.sameValue(`${a}${a}`, \"11\");\n" +
+		"assert.sameValue(`outer ${`inner ${b}`}`, \"outer inner x\");\n" +
+		"assert.sameValue(`line1\n" +
+		"line2`, \"line1\\nline2\");\n" +
+		"assert.sameValue(`A\\`\\${`, \"A`${\");\n" +
+		"assert.sameValue(`${{a: 1}.a}`, \"1\");\n" +
+		"assert.throws(TypeError, function() { `${Symbol()}`; });\n"
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestTaggedTemplate(t *testing.T) {
+	const SCRIPT = "" +
+		"function tag(strings) {\n" +
+		"\treturn strings;\n" +
+		"}\n" +
+		"function get() {\n" +
+		"\treturn tag`a${1}b\\n`;\n" +
+		"}\n" +
+		"var s = get();\n" +
+		"assert.sameValue(s.length, 2);\n" +
+		"assert.sameValue(s[1], \"b\\n\");\n" +
+		"assert.sameValue(s.raw[1], \"b\\\\n\");\n" +
+		"assert.sameValue(Object.isFrozen(s), true, \"frozen\");\n" +
+		"assert.sameValue(Object.isFrozen(s.raw), true, \"raw frozen\");\n" +
+		"assert.sameValue(get(), s, \"cached per site\");\n" +
+		"assert.sameValue(tag`a${1}b\\n` === s, false, \"different site\");\n" +
+		"\n" +
+		"var bad = tag`\\unicode`;\n" +
+		"assert.sameValue(bad[0], undefined, \"invalid escape\");\n" +
+		"assert.sameValue(bad.raw[0], \"\\\\unicode\");\n" +
+		"\n" +
+		"var obj = {\n" +
+		"\tprefix: \">\",\n" +
+		"\tfmt: function(strings, x, y) {\n" +
+		"\t\treturn this.prefix + strings.join(\"|\") + x + y;\n" +
+		"\t}\n" +
+		"};\n" +
+		"assert.sameValue(obj.fmt`1${2}3${4}5`, \">1|3|524\", \"method call this\");\n"
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestObjectDestructuring(t *testing.T) {
+	const SCRIPT = `
+	var called = false;
+	function side() {
+		called = true;
+		return 0;
+	}
+	var {a, b: {c}, d = 3, e: f = side(), "g h": g} = {a: 1, b: {c: 2}, e: 5, "g h": 6};
+	assert.sameValue(a + c + d + f + g, 17);
+	assert.sameValue(called, false, "default is lazy");
+	const {length} = "abcd";
+	assert.sameValue(length, 4, "primitive source");
+	var o = {};
+	var src = {x: 10, y: 11};
+	assert.sameValue(({x: o.x, y: o["y"]} = src), src, "assignment result");
+	assert.sameValue(o.x + o.y, 21);
+	assert.throws(TypeError, function() { var {a} = null; });
+	assert.throws(TypeError, function() { var {} = undefined; });
+	assert.throws(TypeError, function() { ({a} = null); });
+	assert.throws(TypeError, function() { let {a: {b}} = {}; });
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestArrayDestructuring(t *testing.T) {
+	const SCRIPT = `
+	var [a, , b = 5, ...rest] = [1, 2, undefined, 8, 9];
+	assert.sameValue(a, 1);
+	assert.sameValue(b, 5);
+	assert.sameValue(rest.join(), "8,9");
+	let [x, y = x * 2] = [3];
+	assert.sameValue(y, 6);
+	var p = 1, q = 2;
+	[p, q] = [q, p];
+	assert.sameValue(p, 2, "swap");
+	assert.sameValue(q, 1, "swap");
+	var m, n;
+	[[m] = [7], {n}] = [undefined, {n: 8}];
+	assert.sameValue(m + n, 15, "nested");
+	var [...chars] = "ab";
+	assert.sameValue(chars.join(), "a,b", "iterable source");
+
+	var closed = false;
+	var it = {};
+	it[Symbol.iterator] = function() {
+		return {
+			next: function() { return {value: 1, done: false}; },
+			return: function() { closed = true; return {}; }
+		};
+	};
+	var [z] = it;
+	assert.sameValue(closed, true, "iterator closed");
+	assert.throws(TypeError, function() { let [a] = null; });
+	assert.throws(TypeError, function() { [a] = undefined; });
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestDestructuringParams(t *testing.T) {
+	const SCRIPT = `
+	function f({a, b = 2}, [c, d]) {
+		return a + b + c + d;
+	}
+	assert.sameValue(f({a: 1}, [3, 4]), 10);
+	assert.sameValue(f.length, 2);
+	assert.throws(TypeError, function() { f(null, []); });
+	var g = ([a, b]) => a * b;
+	assert.sameValue(g([6, 7]), 42);
+	function h({a}, b) {
+		return function() { return arguments.length + a + b; };
+	}
+	assert.sameValue(h({a: 1}, 2)(), 3, "closure");
+	class C {
+		m({x: [y]}) { return y; }
+	}
+	assert.sameValue(new C().m({x: [5]}), 5);
+	function* gen({n}) {
+		yield n;
+	}
+	assert.sameValue(gen({n: 9}).next().value, 9);
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestDestructuringCatchAndLoops(t *testing.T) {
+	const SCRIPT = `
+	var fns = [];
+	try {
+		throw {message: "m", code: 3};
+	} catch ({message, code}) {
+		fns.push(function() { return message + code; });
+	}
+	assert.sameValue(fns[0](), "m3");
+	var s = "";
+	for (var [k, v] of [[1, 2], [3, 4]]) {
+		s += k + v;
+	}
+	for (const [key, value] of new Map([["a", 1]])) {
+		s += key + value;
+	}
+	for ([k, v] of [[5, 6]]) {
+		s += k * v;
+	}
+	assert.sameValue(s, "37a130");
+	fns = [];
+	for (let [i] of [[1], [2]]) {
+		fns.push(function() { return i; });
+		if (i) continue;
+	}
+	assert.sameValue(fns[0]() + fns[1](), 3, "fresh bindings");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestDestructuringComputedKeys(t *testing.T) {
+	const SCRIPT = `
+	var log = [];
+	var k = "b";
+	var {[k]: v, ["a" + "1"]: w = 5} = {b: 2};
+	assert.sameValue(v, 2);
+	assert.sameValue(w, 5, "default");
+	var sym = Symbol("s");
+	let {[sym]: s} = {[sym]: "sym"};
+	assert.sameValue(s, "sym", "symbol key");
+	function f({[k + k]: x}) {
+		return x;
+	}
+	assert.sameValue(f({bb: 3}), 3, "parameter");
+	var o = {};
+	({[k]: o.x} = {b: 4});
+	assert.sameValue(o.x, 4, "assignment");
+	var src = {get a() { log.push("get a"); }, get b() { log.push("get b"); }};
+	var {[(log.push("key a"), "a")]: p, [(log.push("key b"), "b")]: q} = src;
+	assert.sameValue(log.join(), "key a,get a,key b,get b", "evaluation order");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestDefaultParams(t *testing.T) {
+	const SCRIPT = `
+	function f(a, b = a + 1, c) {
+		return [a, b, c].join();
+	}
+	assert.sameValue(f(1), "1,2,");
+	assert.sameValue(f(1, undefined, 3), "1,2,3");
+	assert.sameValue(f(1, null), "1,,", "only undefined triggers the default");
+	assert.sameValue(f.length, 1, "length");
+	function g(a, b = function() { return a; }) {
+		a = 3;
+		return b();
+	}
+	assert.sameValue(g(1), 3, "default closes over parameters");
+	function h(a, b = 2) {
+		a = 5;
+		return arguments[0];
+	}
+	assert.sameValue(h(1), 1, "arguments is not mapped");
+	var arrow = (x = 2, {y} = {y: 3}) => x * y;
+	assert.sameValue(arrow(), 6);
+	assert.sameValue(arrow.length, 0);
+	function* gen(a = 1) {
+		yield a;
+	}
+	assert.sameValue(gen().next().value, 1);
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestDefaultParamsScope(t *testing.T) {
+	const SCRIPT = `
+	function f(x = y, y) {
+		return x;
+	}
+	assert.throws(ReferenceError, function() { f(); }, "later parameter");
+	assert.throws(ReferenceError, function() { f(undefined, 1); }, "later parameter with argument");
+	assert.sameValue(f(1), 1);
+	function g(x = x) {}
+	assert.throws(ReferenceError, function() { g(); }, "self reference");
+	function h({a} = {a: 1}, b = () => a) {
+		return b();
+	}
+	assert.sameValue(h(), 1, "pattern parameter");
+
+	function sep(a, b = () => a) {
+		var a = 2;
+		return [a, b()].join();
+	}
+	assert.sameValue(sep(1), "2,1", "body var has its own scope");
+	function copy(a, b = 0) {
+		var a;
+		return a;
+	}
+	assert.sameValue(copy(3), 3, "var initialized with the parameter value");
+	function assign(a, b = () => a) {
+		a = 5;
+		var c = b;
+		return c();
+	}
+	assert.sameValue(assign(1), 5, "assignment without var changes the parameter");
+	function fn(a, b = () => typeof inner) {
+		function inner() {}
+		return b();
+	}
+	assert.sameValue(fn(), "undefined", "body functions are not visible to defaults");
+	function loop(n = 3) {
+		var s = 0;
+		for (let i = 0; i < n; i++) {
+			s += (() => i)();
+		}
+		{
+			function blk() { return s; }
+		}
+		return blk();
+	}
+	assert.sameValue(loop(), 3, "block function");
+	assert.throws(SyntaxError, function() {
+		new Function("a = 1", "let a;");
+	});
+	assert.throws(SyntaxError, function() {
+		new Function("a = 1", "var b; let b;");
+	});
+	function* gen(a, b = () => a) {
+		var a = 2;
+		yield b();
+		yield a;
+	}
+	assert.sameValue([...gen(1)].join(), "1,2", "generator");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestRestParams(t *testing.T) {
+	const SCRIPT = `
+	function f(a, ...rest) {
+		return a + ":" + rest.join();
+	}
+	assert.sameValue(f(1, 2, 3), "1:2,3");
+	assert.sameValue(f(1), "1:");
+	assert.sameValue(f.length, 1);
+	function g(a, ...rest) {
+		return function() {
+			return rest.length + a;
+		};
+	}
+	assert.sameValue(g(1, 2, 3)(), 3, "rest in stash");
+	function h(...[x, y]) {
+		return x + y;
+	}
+	assert.sameValue(h(1, 2, 3), 3, "rest pattern");
+	assert.sameValue(((...xs) => Array.isArray(xs) && xs.length)(), 0);
+	class A {
+		constructor(...xs) {
+			this.n = xs.length;
+		}
+	}
+	class B extends A {
+		constructor(...xs) {
+			super(...xs, 1);
+		}
+	}
+	assert.sameValue(new B(1, 2).n, 3, "spread into rest");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestNonSimpleParamsErrors(t *testing.T) {
+	for _, src := range []string{
+		"function f(a = 1) { 'use strict'; }",
+		"function f(a, a = 1) {}",
+		"function f(...a, b) {}",
+	} {
+		if _, err := Compile("", src, false); err == nil {
+			t.Fatalf("%s: expected a syntax error", src)
+		}
+	}
+}
+
+func TestObjectLiteralShorthand(t *testing.T) {
+	const SCRIPT = `
+	var a = 1, b = "x";
+	var o = {a, b};
+	assert.sameValue(o.a, 1);
+	assert.sameValue(o.b, "x");
+	var get = 2, set = 3, async = 4;
+	var p = {get, set, async};
+	assert.sameValue(p.get + p.set + p.async, 9, "contextual keywords");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestObjectLiteralMethods(t *testing.T) {
+	const SCRIPT = `
+	var o = {
+		x: 1,
+		m() {
+			return this.x + 1;
+		},
+		*gen() {
+			yield 1;
+		},
+		async() {
+			return "async";
+		}
+	};
+	assert.sameValue(o.m(), 2);
+	assert.sameValue(o.m.name, "m");
+	assert.sameValue(o.gen().next().value, 1);
+	assert.sameValue(o.async(), "async");
+	assert.throws(TypeError, function() {
+		new o.m();
+	}, "methods are not constructors");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestObjectLiteralSuper(t *testing.T) {
+	const SCRIPT = `
+	var base = {
+		hi() {
+			return "base:" + this.name;
+		}
+	};
+	var o = {
+		__proto__: base,
+		name: "o",
+		hi() {
+			return super.hi() + "!";
+		},
+		arrow() {
+			return (() => super.hi())();
+		},
+		get g() {
+			return super.hi();
+		}
+	};
+	assert.sameValue(o.hi(), "base:o!");
+	assert.sameValue(o.arrow(), "base:o");
+	assert.sameValue(o.g, "base:o");
+	var m = o.hi;
+	var other = {__proto__: {hi() { return "other"; }}, name: "other", m};
+	assert.sameValue(other.m(), "base:other!", "home object is fixed at definition");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestObjectLiteralComputedKeys(t *testing.T) {
+	const SCRIPT = `
+	var i = 0, sym = Symbol("s");
+	var o = {
+		["a" + ++i]: i,
+		[sym]: "sym",
+		["m" + i]() {
+			return "method";
+		},
+		get ["g"]() {
+			return this._v;
+		},
+		set ["g"](v) {
+			this._v = v;
+		}
+	};
+	assert.sameValue(o.a1, 1);
+	assert.sameValue(o[sym], "sym");
+	assert.sameValue(o.m1(), "method");
+	o.g = 5;
+	assert.sameValue(o.g, 5);
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestObjectLiteralProto(t *testing.T) {
+	const SCRIPT = `
+	var proto = {p: 1};
+	var o = {__proto__: proto};
+	assert.sameValue(Object.getPrototypeOf(o), proto);
+	assert.sameValue(o.hasOwnProperty("__proto__"), false);
+	assert.sameValue(Object.getPrototypeOf({__proto__: null}), null);
+	assert.sameValue(Object.getPrototypeOf({__proto__: 1}), Object.prototype, "non-object is ignored");
+	var c = {["__proto__"]: proto};
+	assert.sameValue(Object.getPrototypeOf(c), Object.prototype, "computed key");
+	assert.sameValue(c.hasOwnProperty("__proto__"), true);
+	var __proto__ = proto;
+	var s = {__proto__};
+	assert.sameValue(Object.getPrototypeOf(s), Object.prototype, "shorthand");
+	assert.sameValue(s.hasOwnProperty("__proto__"), true);
+	assert.throws(SyntaxError, function() {
+		eval("({__proto__: null, __proto__: null})");
+	});
+	assert.throws(SyntaxError, function() {
+		eval("({f: function() { return super.x; }})");
+	});
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
 // FIXME
 /*
 func TestDummyCompile(t *testing.T) {
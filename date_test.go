@@ -47,6 +47,23 @@ assert.sameValue = function (actual, expected, message) {
     $ERROR(message);
 };
 
+assert.throws = function (expectedErrorConstructor, func, message) {
+    if (message === undefined) {
+        message = '';
+    } else {
+        message += ' ';
+    }
+    try {
+        func();
+    } catch (thrown) {
+        if (thrown === null || typeof thrown !== 'object' || thrown.constructor !== expectedErrorConstructor) {
+            $ERROR(message + 'Expected a ' + expectedErrorConstructor.name + ' but got a different error');
+        }
+        return;
+    }
+    $ERROR(message + 'Expected a ' + expectedErrorConstructor.name + ' to be thrown but no exception was thrown at all');
+};
+
 
 `
 
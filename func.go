@@ -14,6 +14,22 @@ type funcObject struct {
 	stash *stash
 	prg   *Program
 	src   string
+
+	// 箭头函数不能作为构造函数，this为创建时记录的值
+	arrow bool
+	this  Value
+
+	// 类的构造函数只能通过new调用，derived表示类有extends
+	classCtor bool
+	derived   bool
+	// 类的方法不能作为构造函数
+	method bool
+	// 方法所属的对象，super.x从它的原型上查找
+	homeObject *Object
+	// function*声明的生成器函数，调用时返回生成器对象
+	generator bool
+	// async函数，调用时返回promise
+	async bool
 }
 
 type nativeFuncObject struct {
@@ -36,7 +52,7 @@ func (f *nativeFuncObject) exportType() reflect.Type {
 }
 
 func (f *funcObject) _addProto(n string) Value {
-	if n == "prototype" {
+	if n == "prototype" && f.hasPrototype() {
 		if _, exists := f.values["prototype"]; !exists {
 			return f.addPrototype()
 		}
@@ -58,6 +74,10 @@ func (f *funcObject) putStr(name string, val Value, throw bool) {
 }
 
 func (f *funcObject) put(n Value, val Value, throw bool) {
+	if s, ok := n.(*valueSymbol); ok {
+		f.putSym(s, val, throw)
+		return
+	}
 	f.putStr(n.String(), val, throw)
 }
 
@@ -67,16 +87,27 @@ func (f *funcObject) deleteStr(name string, throw bool) bool {
 }
 
 func (f *funcObject) delete(n Value, throw bool) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return f.deleteSym(s, throw)
+	}
 	return f.deleteStr(n.String(), throw)
 }
 
 func (f *funcObject) addPrototype() Value {
-	proto := f.val.runtime.NewObject()
+	r := f.val.runtime
+	if f.generator {
+		// 生成器函数的prototype是生成器对象的原型，没有constructor
+		return f._putProp("prototype", r.newBaseObject(r.global.GeneratorPrototype, classObject).val, true, false, false)
+	}
+	proto := r.NewObject()
 	proto.self._putProp("constructor", f.val, true, false, true)
 	return f._putProp("prototype", proto, true, false, false)
 }
 
 func (f *funcObject) getProp(n Value) Value {
+	if s, ok := n.(*valueSymbol); ok {
+		return f.getPropSym(s)
+	}
 	return f.getPropStr(n.String())
 }
 
@@ -86,7 +117,7 @@ func (f *funcObject) hasOwnProperty(n Value) bool {
 	}
 
 	name := n.String()
-	if name == "prototype" {
+	if name == "prototype" && f.hasPrototype() {
 		return true
 	}
 	return false
@@ -97,14 +128,36 @@ func (f *funcObject) hasOwnPropertyStr(name string) bool {
 		return true
 	}
 
-	if name == "prototype" {
+	if name == "prototype" && f.hasPrototype() {
 		return true
 	}
 	return false
 }
 
+// 箭头函数和类的方法既没有prototype属性，也不能作为构造函数
+func (f *funcObject) isConstructor() bool {
+	return !f.arrow && !f.method && !f.generator && !f.async
+}
+
+// 生成器函数不能作为构造函数，但有prototype属性
+func (f *funcObject) hasPrototype() bool {
+	return f.isConstructor() || f.generator
+}
+
 func (f *funcObject) construct(args []Value) *Object {
-	proto := f.getStr("prototype")
+	return f.constructWith(args, f.val)
+}
+// 构造对象，新对象的原型取自newTarget。派生类的this由构造函数中的super()创建
+func (f *funcObject) constructWith(args []Value, newTarget *Object) *Object {
+	if !f.isConstructor() {
+		f.val.runtime.typeErrorResult(true, "Not a constructor")
+	}
+	if f.derived {
+		return f.call(FunctionCall{
+			Arguments: args,
+		}, newTarget).(*Object)
+	}
+	proto := newTarget.self.getStr("prototype")
 	var protoObj *Object
 	if p, ok := proto.(*Object); ok {
 		protoObj = p
@@ -112,10 +165,10 @@ func (f *funcObject) construct(args []Value) *Object {
 		protoObj = f.val.runtime.global.ObjectPrototype
 	}
 	obj := f.val.runtime.newBaseObject(protoObj, classObject).val
-	ret := f.Call(FunctionCall{
+	ret := f.call(FunctionCall{
 		This:      obj,
 		Arguments: args,
-	})
+	}, newTarget)
 
 	if ret, ok := ret.(*Object); ok {
 		return ret
@@ -124,13 +177,22 @@ func (f *funcObject) construct(args []Value) *Object {
 }
 
 func (f *funcObject) Call(call FunctionCall) Value {
+	if f.classCtor {
+		f.val.runtime.throwClassCallError(f)
+	}
+	return f.call(call, nil)
+}
+
+func (f *funcObject) call(call FunctionCall, newTarget *Object) Value {
 	vm := f.val.runtime.vm
 	pc := vm.pc
 
 	vm.stack.expand(vm.sp + len(call.Arguments) + 1)
 	vm.stack[vm.sp] = f.val
 	vm.sp++
-	if call.This != nil {
+	if f.arrow {
+		vm.stack[vm.sp] = f.this
+	} else if call.This != nil {
 		vm.stack[vm.sp] = call.This
 	} else {
 		vm.stack[vm.sp] = _undefined
@@ -148,6 +210,7 @@ func (f *funcObject) Call(call FunctionCall) Value {
 	vm.pc = -1
 	vm.pushCtx()
 	vm.args = len(call.Arguments)
+	vm.newTarget = newTarget
 	vm.prg = f.prg
 	vm.stash = f.stash
 	vm.pc = 0
@@ -230,6 +293,9 @@ func (f *nativeFuncObject) assertCallable() (func(FunctionCall) Value, bool) {
 }
 
 func (f *boundFuncObject) getProp(n Value) Value {
+	if s, ok := n.(*valueSymbol); ok {
+		return f.getPropSym(s)
+	}
 	return f.getPropStr(n.String())
 }
 
@@ -242,6 +308,9 @@ func (f *boundFuncObject) getPropStr(name string) Value {
 }
 
 func (f *boundFuncObject) delete(n Value, throw bool) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return f.deleteSym(s, throw)
+	}
 	return f.deleteStr(n.String(), throw)
 }
 
@@ -260,5 +329,9 @@ func (f *boundFuncObject) putStr(name string, val Value, throw bool) {
 }
 
 func (f *boundFuncObject) put(n Value, val Value, throw bool) {
+	if s, ok := n.(*valueSymbol); ok {
+		f.putSym(s, val, throw)
+		return
+	}
 	f.putStr(n.String(), val, throw)
 }
@@ -0,0 +1,111 @@
+package goja
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// GasLimitError is returned when a script runs out of the gas set with SetGasLimit(). Like InterruptedError it
+// can not be caught by the script.
+// GasLimitError在脚本用完SetGasLimit()设置的gas时返回，和InterruptedError一样不能被脚本捕获。
+type GasLimitError struct {
+	Exception
+	used, limit int64
+}
+
+// GasHandler is called when the gas limit is exhausted. It returns the amount of additional gas the execution may
+// continue with; zero or a negative value aborts the execution with a *GasLimitError.
+// GasHandler在gas用完时调用，返回允许继续执行的额外gas，返回0或负数时以*GasLimitError中止执行。
+type GasHandler func(used int64) int64
+
+// Used returns the gas used at the moment the limit was exceeded.
+// Used返回超出限制时已使用的gas。
+func (e *GasLimitError) Used() int64 {
+	return e.used
+}
+// Limit returns the limit that was exceeded.
+// Limit返回被超出的限制。
+func (e *GasLimitError) Limit() int64 {
+	return e.limit
+}
+
+func (e *GasLimitError) message() string {
+	return fmt.Sprintf("gas limit exceeded: %d used, limit is %d", e.used, e.limit)
+}
+
+func (e *GasLimitError) String() string {
+	if e == nil {
+		return "<nil>"
+	}
+	var b bytes.Buffer
+	b.WriteString(e.message())
+	b.WriteByte('\n')
+	e.writeFullStack(&b)
+	return b.String()
+}
+
+func (e *GasLimitError) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+	var b bytes.Buffer
+	b.WriteString(e.message())
+	e.writeShortStack(&b)
+	return b.String()
+}
+// SetGasLimit sets the amount of gas scripts running in this Runtime may use. Every executed VM instruction costs
+// one unit of gas, built-in functions whose work depends on the size of their input (such as Array.prototype.sort,
+// String.prototype.split or JSON.stringify) are charged proportionally. Unlike Interrupt() the metering does not
+// depend on timing, the same script with the same limit always stops at the same point. When the gas is exhausted
+// the handler set with SetGasHandler() is consulted; without it the execution is aborted and the corresponding Go
+// call returns a *GasLimitError. The used gas is cumulative, see ResetGasUsed(). Zero (the default) means no limit.
+// SetGasLimit设置此运行时中脚本可以使用的gas。每条虚拟机指令消耗1个单位，工作量取决于输入大小的内置函数(例如Array.prototype.sort、
+// String.prototype.split或JSON.stringify)按比例计费。与Interrupt()不同，计量不依赖于时间，同样的脚本和限制总是在同一位置停止。
+// gas用完时先询问SetGasHandler()设置的处理函数，没有处理函数时中止执行，对应的Go调用返回*GasLimitError。
+// 已使用的gas是累计的，参见ResetGasUsed()。0(默认值)表示不限制。
+func (r *Runtime) SetGasLimit(limit int64) {
+	r.vm.gasLimit = limit
+}
+// SetGasHandler sets the function called when the gas limit is exhausted. The handler is called on the goroutine
+// running the script and must not use the Runtime.
+// SetGasHandler设置gas用完时调用的函数。处理函数在运行脚本的goroutine中调用，不能使用运行时。
+func (r *Runtime) SetGasHandler(handler GasHandler) {
+	r.vm.gasHandler = handler
+}
+// GasUsed returns the gas used since the Runtime was created or since the last call to ResetGasUsed().
+// GasUsed返回自运行时创建或上次调用ResetGasUsed()以来使用的gas。
+func (r *Runtime) GasUsed() int64 {
+	return r.vm.gasUsed
+}
+// ResetGasUsed resets the used gas to zero, typically before the Runtime is re-used.
+// ResetGasUsed把已使用的gas清零，通常在重用运行时之前调用。
+func (r *Runtime) ResetGasUsed() {
+	r.vm.gasUsed = 0
+}
+// 内置函数按工作量消耗gas
+func (r *Runtime) useGas(n int64) {
+	r.vm.useGas(n)
+}
+// 消耗gas，超出限制时中止执行
+func (vm *vm) useGas(n int64) {
+	vm.gasUsed += n
+	if vm.gasLimit > 0 && vm.gasUsed > vm.gasLimit {
+		vm.gasExhausted()
+	}
+}
+// gas用完时询问处理函数，没有处理函数或它不再提供gas时中止执行
+func (vm *vm) gasExhausted() {
+	for vm.gasUsed > vm.gasLimit {
+		var more int64
+		if vm.gasHandler != nil {
+			more = vm.gasHandler(vm.gasUsed)
+		}
+		if more <= 0 {
+			panic(&GasLimitError{
+				used:  vm.gasUsed,
+				limit: vm.gasLimit,
+			})
+		}
+		vm.gasLimit += more
+	}
+}
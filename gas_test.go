@@ -0,0 +1,160 @@
+package goja
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGasLimit(t *testing.T) {
+	const SCRIPT = `
+	var i = 0, caught = false;
+	try {
+		for (;;) {
+			i++;
+		}
+	} catch (e) {
+		caught = true;
+	}
+	`
+	var counts []Value
+	for n := 0; n < 2; n++ {
+		vm := New()
+		vm.SetGasLimit(10000)
+		_, err := vm.RunString(SCRIPT)
+		ex, ok := err.(*GasLimitError)
+		if !ok {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if ex.Used() != 10001 || ex.Limit() != 10000 {
+			t.Fatalf("Unexpected error values: %d, %d", ex.Used(), ex.Limit())
+		}
+		if caught := vm.Get("caught"); caught != valueFalse {
+			t.Fatal("The error must not be caught by the script")
+		}
+		counts = append(counts, vm.Get("i"))
+	}
+	if !counts[0].SameAs(counts[1]) {
+		t.Fatalf("Execution stopped at different points: %v, %v", counts[0], counts[1])
+	}
+}
+
+func TestGasLimitInPromiseJob(t *testing.T) {
+	vm := New()
+	vm.SetGasLimit(10000)
+	res, err := vm.RunString(`Promise.resolve().then(function() { for (;;) {} }); 1`)
+	if _, ok := err.(*GasLimitError); !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if res != nil {
+		t.Fatalf("Unexpected result: %v", res)
+	}
+
+	vm.ResetGasUsed()
+	v, err := vm.RunString(`(function() { Promise.resolve().then(function() { for (;;) {} }); return 1; })`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, _ := AssertFunction(v)
+	res, err = f(_undefined)
+	if _, ok := err.(*GasLimitError); !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if res != nil {
+		t.Fatalf("Unexpected result: %v", res)
+	}
+
+	vm.ResetGasUsed()
+	if res, err = vm.RunString("2"); err != nil || !res.SameAs(intToValue(2)) {
+		t.Fatalf("Unexpected result after the limit was hit: %v, %v", res, err)
+	}
+}
+
+func TestGasBuiltins(t *testing.T) {
+	used := func(script string) int64 {
+		vm := New()
+		if _, err := vm.RunString(script); err != nil {
+			t.Fatal(err)
+		}
+		return vm.GasUsed()
+	}
+	const n = 10000
+	base := used(`var a = []; a.length = 10000; a.fill("ab");`)
+	for _, script := range []string{
+		`a.sort()`,
+		`a.join()`,
+		`JSON.stringify(a)`,
+		`a.join().split(",")`,
+	} {
+		if gas := used(`var a = []; a.length = 10000; a.fill("ab");`+script) - base; gas < n {
+			t.Fatalf("%s: used %d gas", script, gas)
+		}
+	}
+
+	vm := New()
+	vm.SetGasLimit(1000)
+	_, err := vm.RunString(`JSON.parse("[" + "1,".repeat(1000) + "1]")`)
+	if _, ok := err.(*GasLimitError); !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestGasBuiltinsProportional(t *testing.T) {
+	for _, script := range []string{
+		`new Array(1e7).fill(0)`,
+		`var a = []; a.length = 1e7; a.indexOf(1)`,
+		`var a = []; a.length = 1e7; a.includes(1)`,
+		`var a = []; a.length = 1e7; a.lastIndexOf(1)`,
+		`var a = []; a.length = 1e7; a.slice()`,
+		`var a = []; a.length = 1e7; a.splice(0, 1)`,
+		`var a = []; a.length = 1e7; [].concat(a)`,
+		`Array.from({length: 1e7})`,
+		`Array.from(new Uint8Array(1e7))`,
+		`new Float64Array(1e6).sort()`,
+		`new Uint8Array(1e7).set(new Int8Array(1e7))`,
+		`new Uint8Array(1e7).fill(1)`,
+		`"".padStart(1e7)`,
+		`"".padEnd(1e7, "ab")`,
+		`long.replace("b", "c")`,
+		`long.replaceAll("a", "bb")`,
+		`long.replace(/b/, "c")`,
+	} {
+		vm := New()
+		vm.Set("long", strings.Repeat("a", 1e4))
+		vm.SetGasLimit(1000)
+		_, err := vm.RunString(script)
+		if _, ok := err.(*GasLimitError); !ok {
+			t.Fatalf("%s: unexpected error: %v", script, err)
+		}
+	}
+}
+
+func TestGasHandler(t *testing.T) {
+	vm := New()
+	vm.SetGasLimit(100)
+	calls := 0
+	vm.SetGasHandler(func(used int64) int64 {
+		calls++
+		if calls < 5 {
+			return 100
+		}
+		return 0
+	})
+	_, err := vm.RunString(`for (;;) {}`)
+	ex, ok := err.(*GasLimitError)
+	if !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls != 5 || ex.Limit() != 500 {
+		t.Fatalf("Unexpected calls: %d, limit: %d", calls, ex.Limit())
+	}
+
+	vm.ResetGasUsed()
+	vm.SetGasLimit(0)
+	v, err := vm.RunString(`1 + 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.ToInteger() != 2 || vm.GasUsed() == 0 {
+		t.Fatalf("Unexpected result: %v, gas: %d", v, vm.GasUsed())
+	}
+}
@@ -1,14 +1,17 @@
 package main
 
 import (
+	"context"
 	crand "crypto/rand"
 	"encoding/binary"
+	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"runtime/debug"
 	"runtime/pprof"
 	"time"
@@ -20,6 +23,12 @@ import (
 
 var cpuprofile = flag.String("cpuprofile", "", "write cpu profile to file")
 var timelimit = flag.Int("timelimit", 0, "max time to run (in seconds)")
+var module = flag.Bool("module", false, "run the file as an ES module")
+var memlimit = flag.Int64("memlimit", 0, "max memory the script may allocate (in bytes, approximate)")
+var gaslimit = flag.Int64("gaslimit", 0, "max gas (VM instructions) the script may use")
+
+// 从文件系统加载模块，相对路径相对于引用者所在的目录
+type fileModuleLoader struct{}
 //读取文件内容到buf
 func readSource(filename string) ([]byte, error) {
 	if filename == "" || filename == "-" {
@@ -40,6 +49,21 @@ func load(vm *goja.Runtime, call goja.FunctionCall) goja.Value {
 	}
 	return v
 }
+// 相对路径相对于引用者所在的目录解析
+func (fileModuleLoader) ResolveModule(referrer, specifier string) (string, error) {
+	if filepath.IsAbs(specifier) {
+		return filepath.Clean(specifier), nil
+	}
+	return filepath.Join(filepath.Dir(referrer), specifier), nil
+}
+// 读取模块文件
+func (fileModuleLoader) LoadModule(name string) (*goja.ModuleSource, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return &goja.ModuleSource{Source: string(b)}, nil
+}
 // 获得一个随机数
 func newRandSource() goja.RandSource {
 	var seed int64
@@ -51,6 +75,9 @@ func newRandSource() goja.RandSource {
 
 func run() error {
 	filename := flag.Arg(0)
+	if *module && (filename == "" || filename == "-") {
+		return errors.New("A module cannot be read from stdin")
+	}
 	src, err := readSource(filename)
 	if err != nil {
 		return err
@@ -62,6 +89,9 @@ func run() error {
 
 	vm := goja.New()
 	vm.SetRandSource(newRandSource())
+	vm.SetModuleLoader(fileModuleLoader{})
+	vm.SetMemoryLimit(*memlimit)
+	vm.SetGasLimit(*gaslimit)
 
 	new(require.Registry).Enable(vm)
 	console.Enable(vm)
@@ -78,10 +108,16 @@ func run() error {
 		return string(b), nil
 	})
 
+	ctx := context.Background()
 	if *timelimit > 0 {
-		time.AfterFunc(time.Duration(*timelimit)*time.Second, func() {
-			vm.Interrupt("timeout")
-		})
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(*timelimit)*time.Second)
+		defer cancel()
+	}
+
+	if *module {
+		_, err = vm.RunModuleContext(ctx, filename)
+		return err
 	}
 
 	//log.Println("Compiling...")
@@ -90,7 +126,7 @@ func run() error {
 		return err
 	}
 	//log.Println("Running...")
-	_, err = vm.RunProgram(prg)
+	_, err = vm.RunProgramContext(ctx, prg)
 	//log.Println("Finished.")
 	return err
 }
@@ -119,6 +155,10 @@ func main() {
 			fmt.Println(err.String())
 		case *goja.InterruptedError:
 			fmt.Println(err.String())
+		case *goja.MemoryLimitError:
+			fmt.Println(err.String())
+		case *goja.GasLimitError:
+			fmt.Println(err.String())
 		default:
 			fmt.Println(err)
 		}
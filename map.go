@@ -0,0 +1,172 @@
+package goja
+
+import (
+	"math"
+)
+
+// 有序哈希表中的一项，删除后仍保留链表指针，以便正在进行的迭代能继续
+type mapEntry struct {
+	key, value Value
+
+	iterPrev, iterNext *mapEntry
+	deleted            bool
+}
+
+// Map和Set的存储，按插入顺序迭代，键按SameValueZero比较
+type orderedMap struct {
+	hash                map[interface{}]*mapEntry
+	iterFirst, iterLast *mapEntry
+	size                int
+}
+
+// orderedMap的迭代器，迭代过程中可以增删元素
+type orderedMapIter struct {
+	m   *orderedMap
+	cur *mapEntry
+}
+
+// 用作NaN的哈希键
+type mapNaNKey struct{}
+
+// 非ASCII字符串的哈希键，与asciiString的键区分开
+type mapUnicodeKey string
+
+// BigInt的哈希键，与字符串的键区分开
+type mapBigIntKey string
+// 计算v的哈希键，SameValueZero相等的值得到相同的键
+func mapKey(v Value) interface{} {
+	switch v := v.(type) {
+	case valueInt:
+		return float64(v)
+	case valueFloat:
+		f := float64(v)
+		if math.IsNaN(f) {
+			return mapNaNKey{}
+		}
+		if f == 0 {
+			// -0和+0是同一个键
+			return float64(0)
+		}
+		return f
+	case asciiString:
+		return string(v)
+	case unicodeString:
+		b := make([]byte, 0, len(v)*2)
+		for _, c := range v {
+			b = append(b, byte(c), byte(c>>8))
+		}
+		return mapUnicodeKey(b)
+	case *valueBigInt:
+		return mapBigIntKey(v.String())
+	}
+	return v
+}
+// 创建空的orderedMap
+func newOrderedMap() *orderedMap {
+	return &orderedMap{
+		hash: make(map[interface{}]*mapEntry),
+	}
+}
+// 查找键对应的项
+func (m *orderedMap) lookup(key Value) *mapEntry {
+	return m.hash[mapKey(key)]
+}
+// 取值，不存在时返回nil
+func (m *orderedMap) get(key Value) Value {
+	if entry := m.lookup(key); entry != nil {
+		return entry.value
+	}
+	return nil
+}
+// 判断键是否存在
+func (m *orderedMap) has(key Value) bool {
+	return m.lookup(key) != nil
+}
+// 设置值，新的键添加到末尾，返回是否添加了新的项
+func (m *orderedMap) set(key, value Value) bool {
+	h := mapKey(key)
+	if entry := m.hash[h]; entry != nil {
+		entry.value = value
+		return false
+	}
+	if f, ok := key.(valueFloat); ok && f == 0 {
+		key = intToValue(0)
+	}
+	entry := &mapEntry{key: key, value: value}
+	m.hash[h] = entry
+	entry.iterPrev = m.iterLast
+	if m.iterLast != nil {
+		m.iterLast.iterNext = entry
+	} else {
+		m.iterFirst = entry
+	}
+	m.iterLast = entry
+	m.size++
+	return true
+}
+// 删除键，返回键是否存在
+func (m *orderedMap) remove(key Value) bool {
+	h := mapKey(key)
+	entry := m.hash[h]
+	if entry == nil {
+		return false
+	}
+	delete(m.hash, h)
+	entry.deleted = true
+	if entry.iterPrev != nil {
+		entry.iterPrev.iterNext = entry.iterNext
+	} else {
+		m.iterFirst = entry.iterNext
+	}
+	if entry.iterNext != nil {
+		entry.iterNext.iterPrev = entry.iterPrev
+	} else {
+		m.iterLast = entry.iterPrev
+	}
+	m.size--
+	return true
+}
+// 删除所有项，正在进行的迭代从之后添加的项继续
+func (m *orderedMap) clear() {
+	for entry := m.iterFirst; entry != nil; entry = entry.iterNext {
+		entry.deleted = true
+		entry.iterPrev = nil
+	}
+	m.hash = make(map[interface{}]*mapEntry)
+	m.iterFirst = nil
+	m.iterLast = nil
+	m.size = 0
+}
+// 创建迭代器
+func (m *orderedMap) newIter() *orderedMapIter {
+	return &orderedMapIter{
+		m: m,
+	}
+}
+// 返回下一项，迭代结束时返回nil
+func (iter *orderedMapIter) next() *mapEntry {
+	if iter.m == nil {
+		return nil
+	}
+	cur := iter.cur
+	// 当前项已被删除时，回退到仍然存在的前一项
+	for cur != nil && cur.deleted {
+		cur = cur.iterPrev
+	}
+	if cur != nil {
+		cur = cur.iterNext
+	} else {
+		cur = iter.m.iterFirst
+	}
+	if cur == nil {
+		iter.close()
+	} else {
+		iter.cur = cur
+	}
+	return cur
+}
+// 结束迭代，之后添加的项也不再返回
+func (iter *orderedMapIter) close() {
+	iter.m = nil
+	iter.cur = nil
+}
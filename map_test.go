@@ -0,0 +1,74 @@
+package goja
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOrderedMapKeys(t *testing.T) {
+	m := newOrderedMap()
+	obj := &Object{}
+	m.set(valueFloat(math.NaN()), intToValue(1))
+	m.set(valueFloat(math.Copysign(0, -1)), intToValue(2))
+	m.set(valueFloat(3), intToValue(3))
+	m.set(asciiString("a"), intToValue(4))
+	m.set(obj, intToValue(5))
+
+	if v := m.get(valueFloat(math.NaN())); v != intToValue(1) {
+		t.Fatalf("NaN: %v", v)
+	}
+	if v := m.get(intToValue(0)); v != intToValue(2) {
+		t.Fatalf("0: %v", v)
+	}
+	if k := m.iterFirst.iterNext.key; k != intToValue(0) {
+		t.Fatalf("-0 key is not normalized: %v", k)
+	}
+	if v := m.get(intToValue(3)); v != intToValue(3) {
+		t.Fatalf("3: %v", v)
+	}
+	if v := m.get(newStringValue("a")); v != intToValue(4) {
+		t.Fatalf("a: %v", v)
+	}
+	if v := m.get(obj); v != intToValue(5) {
+		t.Fatalf("obj: %v", v)
+	}
+	if m.has(&Object{}) || m.has(asciiString("3")) {
+		t.Fatal("Unexpected key")
+	}
+	if m.size != 5 {
+		t.Fatalf("size: %d", m.size)
+	}
+}
+
+func TestOrderedMapIterDelete(t *testing.T) {
+	m := newOrderedMap()
+	for i := int64(0); i < 5; i++ {
+		m.set(intToValue(i), intToValue(i))
+	}
+	iter := m.newIter()
+	var res []int64
+	for entry := iter.next(); entry != nil; entry = iter.next() {
+		i := entry.key.ToInteger()
+		res = append(res, i)
+		switch i {
+		case 1:
+			// 删除当前项和下一项
+			m.remove(intToValue(1))
+			m.remove(intToValue(2))
+		case 3:
+			m.set(intToValue(5), intToValue(5))
+		case 5:
+			m.clear()
+			m.set(intToValue(6), intToValue(6))
+		}
+	}
+	expected := []int64{0, 1, 3, 4, 5, 6}
+	if len(res) != len(expected) {
+		t.Fatalf("Result: %v", res)
+	}
+	for i, v := range expected {
+		if res[i] != v {
+			t.Fatalf("Result: %v", res)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package goja
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// 各类分配的近似大小(字节)，只用于内存限制的统计
+const (
+	sizeofObject      = 128
+	sizeofProperty    = 48
+	sizeofValue       = 16
+	sizeofSparseItem  = 24
+	sizeofStash       = 96
+	sizeofStringValue = 16
+	sizeofMapEntry    = 96
+)
+
+// MemoryLimitError is returned when a script exceeds the memory limit set with SetMemoryLimit(). Like InterruptedError
+// it can not be caught by the script.
+// MemoryLimitError在脚本超出SetMemoryLimit()设置的内存限制时返回，和InterruptedError一样不能被脚本捕获。
+type MemoryLimitError struct {
+	Exception
+	usage, limit int64
+}
+// Usage returns the approximate number of bytes allocated at the moment the limit was exceeded.
+// Usage返回超出限制时已分配的近似字节数。
+func (e *MemoryLimitError) Usage() int64 {
+	return e.usage
+}
+// Limit returns the limit that was exceeded.
+// Limit返回被超出的限制。
+func (e *MemoryLimitError) Limit() int64 {
+	return e.limit
+}
+
+func (e *MemoryLimitError) message() string {
+	return fmt.Sprintf("memory limit exceeded: %d bytes allocated, limit is %d", e.usage, e.limit)
+}
+
+func (e *MemoryLimitError) String() string {
+	if e == nil {
+		return "<nil>"
+	}
+	var b bytes.Buffer
+	b.WriteString(e.message())
+	b.WriteByte('\n')
+	e.writeFullStack(&b)
+	return b.String()
+}
+
+func (e *MemoryLimitError) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+	var b bytes.Buffer
+	b.WriteString(e.message())
+	e.writeShortStack(&b)
+	return b.String()
+}
+// SetMemoryLimit sets an approximate limit, in bytes, on the memory allocated by scripts running in this Runtime.
+// Objects, properties, array elements, strings, ArrayBuffer data, Map/Set/WeakMap entries and variable scopes are
+// accounted for. Once the limit is exceeded
+// the execution is aborted and the corresponding Go call returns a *MemoryLimitError. The usage is cumulative and
+// is not decreased when the memory is garbage collected, see ResetMemoryUsage(). Zero (the default) means no limit.
+// SetMemoryLimit设置此运行时中脚本分配内存的近似上限(字节)，统计对象、属性、数组元素、字符串、ArrayBuffer数据、Map/Set/WeakMap的项和变量作用域。
+// 超出限制时中止执行，对应的Go调用返回*MemoryLimitError。统计值是累计的，内存被回收时不会减少，参见ResetMemoryUsage()。0(默认值)表示不限制。
+func (r *Runtime) SetMemoryLimit(limit int64) {
+	r.memLimit = limit
+}
+// MemoryUsage returns the approximate number of bytes allocated since the Runtime was created or since the last
+// call to ResetMemoryUsage().
+// MemoryUsage返回自运行时创建或上次调用ResetMemoryUsage()以来分配的近似字节数。
+func (r *Runtime) MemoryUsage() int64 {
+	return r.memUsage
+}
+// ResetMemoryUsage resets the memory usage counter to zero, typically before the Runtime is re-used.
+// ResetMemoryUsage把内存统计清零，通常在重用运行时之前调用。
+func (r *Runtime) ResetMemoryUsage() {
+	r.memUsage = 0
+}
+// 记录分配的内存，超出限制时中止执行
+func (r *Runtime) allocate(size int) {
+	r.memUsage += int64(size)
+	if r.memLimit > 0 && r.memUsage > r.memLimit {
+		panic(&MemoryLimitError{
+			usage: r.memUsage,
+			limit: r.memLimit,
+		})
+	}
+}
+// 记录长度为length的新字符串
+func (r *Runtime) allocateString(length int64) {
+	r.allocate(sizeofStringValue + int(length))
+}
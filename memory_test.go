@@ -0,0 +1,108 @@
+package goja
+
+import (
+	"testing"
+)
+
+func TestMemoryLimit(t *testing.T) {
+	vm := New()
+	vm.SetMemoryLimit(1 << 20)
+	_, err := vm.RunString(`
+	var caught = false, list = [];
+	try {
+		for (;;) {
+			list.push({a: 1, b: "x"});
+		}
+	} catch (e) {
+		caught = true;
+	}
+	`)
+	if _, ok := err.(*MemoryLimitError); !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if caught := vm.Get("caught"); caught != valueFalse {
+		t.Fatal("The error must not be caught by the script")
+	}
+	if usage := vm.MemoryUsage(); usage <= 1<<20 {
+		t.Fatalf("Unexpected usage: %d", usage)
+	}
+
+	vm.ResetMemoryUsage()
+	vm.Set("list", nil)
+	v, err := vm.RunString(`[1, 2, 3].join("-")`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.String() != "1-2-3" {
+		t.Fatalf("Unexpected result: %v", v)
+	}
+	if usage := vm.MemoryUsage(); usage <= 0 || usage > 1<<20 {
+		t.Fatalf("Unexpected usage: %d", usage)
+	}
+}
+
+func TestMemoryLimitStrings(t *testing.T) {
+	for _, script := range []string{
+		`var s = "x"; for (;;) { s += s; }`,
+		`"x".repeat(1e9)`,
+		`"".padStart(1e9)`,
+		`var a = []; a[1e6 - 1] = 1; var s = a.join("xx");`,
+		`var a = ["0123456789"]; for (var i = 0; i < 20; i++) { a = [a, a]; } JSON.stringify(a);`,
+		`var s = "ab"; for (var i = 0; i < 26; i++) { s = s.replace(/./g, "$&$&"); }`,
+		`var s = "ab"; for (var i = 0; i < 26; i++) { s = s.replaceAll("a", "aa").replaceAll("b", "bb"); }`,
+		`var s = "ab"; for (var i = 0; i < 26; i++) { s = s.replace(/(.)/g, function(m) { return m + m; }); }`,
+	} {
+		vm := New()
+		vm.SetMemoryLimit(1 << 20)
+		_, err := vm.RunString(script)
+		if _, ok := err.(*MemoryLimitError); !ok {
+			t.Fatalf("%s: unexpected error: %v", script, err)
+		}
+	}
+}
+
+func TestMemoryLimitBuffersAndCollections(t *testing.T) {
+	for _, script := range []string{
+		`new ArrayBuffer(1e8)`,
+		`new ArrayBuffer(1e3).slice(0, 1e3); new Float64Array(1e7)`,
+		`var m = new Map(); for (var i = 0; ; i++) { m.set(i, i); }`,
+		`var s = new Set(); for (var i = 0; ; i++) { s.add(i); }`,
+		`var wm = new WeakMap(), keys = []; for (;;) { var k = {}; keys.push(k); wm.set(k, 1); }`,
+	} {
+		vm := New()
+		vm.SetMemoryLimit(1 << 20)
+		_, err := vm.RunString(script)
+		if _, ok := err.(*MemoryLimitError); !ok {
+			t.Fatalf("%s: unexpected error: %v", script, err)
+		}
+	}
+}
+
+func TestMemoryLimitStash(t *testing.T) {
+	vm := New()
+	vm.SetMemoryLimit(1 << 20)
+	v, err := vm.RunString(`
+	(function() {
+		var fns = [];
+		for (;;) {
+			let i = fns.length;
+			fns.push(function() { return i; });
+		}
+	})
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn, ok := AssertFunction(v)
+	if !ok {
+		t.Fatal("Not a function")
+	}
+	_, err = fn(nil)
+	ex, ok := err.(*MemoryLimitError)
+	if !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ex.Limit() != 1<<20 || ex.Usage() <= ex.Limit() {
+		t.Fatalf("Unexpected error values: %d, %d", ex.Usage(), ex.Limit())
+	}
+}
@@ -0,0 +1,646 @@
+package goja
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/oracle3/goja/parser"
+)
+
+const classModule = "Module"
+
+// export default的表达式和匿名函数、匿名类绑定的隐藏名称
+const defaultExportName = "*default*"
+
+// ModuleLoader resolves and loads the modules requested by Runtime.RunModule(), import declarations and import() calls.
+// All methods are called on the goroutine running the Runtime.
+// ModuleLoader解析并加载Runtime.RunModule()、import声明和import()调用请求的模块，所有方法都在运行Runtime的goroutine中调用。
+type ModuleLoader interface {
+	// ResolveModule returns the name of the module that specifier refers to when imported from the module (or script)
+	// named referrer. Modules with the same name are loaded and evaluated only once per Runtime.
+	// ResolveModule返回从名为referrer的模块（或脚本）导入specifier时对应的模块名称，同名的模块在每个Runtime中只加载和执行一次。
+	ResolveModule(referrer, specifier string) (string, error)
+
+	// LoadModule returns the content of the module with the given (resolved) name.
+	// LoadModule返回给定名称（已解析）的模块的内容。
+	LoadModule(name string) (*ModuleSource, error)
+}
+
+// ModuleSource is the content of a module. If Exports is not nil the module is a synthetic module provided by the host:
+// its exports are the keys of Exports, and the values are converted with Runtime.ToValue(). Otherwise Source is parsed
+// and evaluated as an ES module.
+// ModuleSource是模块的内容。Exports不为nil时模块由宿主提供，其导出为Exports中的键，值通过Runtime.ToValue()转换；否则Source作为ES模块解析执行。
+type ModuleSource struct {
+	Source  string
+	Exports map[string]interface{}
+}
+
+// 模块的import项，importName为"*"表示导入命名空间
+type moduleImportEntry struct {
+	moduleRequest string
+	importName    string
+	localName     string
+}
+
+// 模块的export项，本地导出只有localName，间接导出有moduleRequest和importName，export *只有moduleRequest
+type moduleExportEntry struct {
+	exportName    string
+	moduleRequest string
+	importName    string
+	localName     string
+}
+
+// 编译模块时收集的导入和导出信息，requests为依赖的模块，按出现顺序
+type moduleInfo struct {
+	requests        []string
+	imports         []moduleImportEntry
+	localExports    []moduleExportEntry
+	indirectExports []moduleExportEntry
+	starExports     []moduleExportEntry
+}
+
+type moduleStatus int
+
+const (
+	moduleUnlinked moduleStatus = iota
+	moduleLinking
+	moduleLinked
+	moduleEvaluating
+	moduleEvaluated
+)
+
+// 模块记录。源码模块的绑定保存在模块函数的stash中，宿主提供的模块的导出是固定的值
+type moduleRecord struct {
+	r    *Runtime
+	name string
+
+	info *moduleInfo
+	fn   *newFunc
+	// 宿主提供的模块导出的值
+	values map[string]Value
+
+	status    moduleStatus
+	requested map[string]*moduleRecord
+	// 模块函数在函数体开始处暂停后的作用域
+	env       *stash
+	gen       *generatorObject
+	namespace *Object
+	// 求值时抛出的异常，再次导入时重新抛出
+	evalErr *Exception
+	// 求值被中断(Interrupt、gas或内存限制)，模块函数无法继续执行
+	aborted bool
+}
+
+// 解析后的导出绑定，name为空表示模块的命名空间对象
+type moduleBinding struct {
+	module *moduleRecord
+	name   string
+}
+
+// 模块命名空间对象，属性为模块导出的绑定。同样的结构也用作模块中导入的绑定所在的作用域
+type moduleNamespace struct {
+	baseObject
+	bindings map[string]moduleBinding
+	names    []string
+	// 用作作用域时，给导入的绑定赋值抛出TypeError
+	env bool
+}
+// SetModuleLoader sets the loader used to resolve and load modules. Without a loader RunModule() and import() fail.
+// SetModuleLoader设置用于解析和加载模块的加载器，没有加载器时RunModule()和import()会失败。
+func (r *Runtime) SetModuleLoader(loader ModuleLoader) {
+	r.moduleLoader = loader
+}
+// RunModule loads the module that specifier refers to (resolved with an empty referrer), links and evaluates it along
+// with the modules it imports, and returns its namespace object. A module that has already been evaluated is not
+// evaluated again; if its evaluation threw, the same exception is returned. If the evaluation was aborted (see
+// Interrupt(), SetGasLimit() and SetMemoryLimit()), later imports of the module throw a TypeError.
+// RunModule加载specifier对应的模块（referrer为空），与其导入的模块一起链接并执行，返回模块的命名空间对象。已经执行过的模块不会再次执行，执行时抛出过异常则返回相同的异常。
+// 执行被中断时（参见Interrupt()、SetGasLimit()和SetMemoryLimit()），之后导入该模块抛出TypeError。
+func (r *Runtime) RunModule(specifier string) (ns *Object, err error) {
+	vm := r.vm
+	toplevel := len(vm.callStack) == 0
+	defer func() {
+		if x := recover(); x != nil {
+			switch x := x.(type) {
+			case *InterruptedError:
+				err = x
+			case *MemoryLimitError:
+				err = x
+			case *GasLimitError:
+				err = x
+			default:
+				panic(x)
+			}
+			ns = nil
+			if toplevel {
+				r.dropJobs()
+			}
+		}
+		vm.clearStack()
+	}()
+	ex := vm.try(func() {
+		ns = r.importModule("", specifier).getNamespace()
+	})
+	if ex != nil {
+		ns = nil
+		err = ex
+	}
+	if toplevel {
+		r.leave()
+	}
+	return
+}
+// 编译模块源码
+func compileModule(name, src string) (info *moduleInfo, fn *newFunc, err error) {
+	prg, err1 := parser.ParseFile(nil, name, src, parser.Module)
+	if err1 != nil {
+		err = &CompilerSyntaxError{
+			CompilerError: CompilerError{
+				Message: err1.Error(),
+			},
+		}
+		return
+	}
+	c := newCompiler()
+	defer func() {
+		if x := recover(); x != nil {
+			switch x1 := x.(type) {
+			case *CompilerSyntaxError:
+				err = x1
+			default:
+				panic(x)
+			}
+		}
+	}()
+	info, fn = c.compileModule(prg)
+	return
+}
+// 加载、链接并执行模块
+func (r *Runtime) importModule(referrer, specifier string) *moduleRecord {
+	m := r.loadModule(referrer, specifier)
+	r.loadRequested(m)
+	r.linkModule(m)
+	r.evaluateModule(m)
+	return m
+}
+// 解析模块名称并加载模块，已经加载的模块直接返回
+func (r *Runtime) loadModule(referrer, specifier string) *moduleRecord {
+	if r.moduleLoader == nil {
+		panic(r.NewTypeError("Cannot import module '%s': no module loader is set", specifier))
+	}
+	name, err := r.moduleLoader.ResolveModule(referrer, specifier)
+	if err != nil {
+		panic(r.NewGoError(err))
+	}
+	if m := r.modules[name]; m != nil {
+		return m
+	}
+	src, err := r.moduleLoader.LoadModule(name)
+	if err != nil {
+		panic(r.NewGoError(err))
+	}
+	if src == nil {
+		panic(r.NewTypeError("Cannot find module '%s'", name))
+	}
+	m := &moduleRecord{
+		r:    r,
+		name: name,
+	}
+	if src.Exports != nil {
+		m.values = make(map[string]Value, len(src.Exports))
+		for k, v := range src.Exports {
+			m.values[k] = r.ToValue(v)
+		}
+		m.status = moduleEvaluated
+	} else {
+		info, fn, err := compileModule(name, src.Source)
+		if err != nil {
+			panic(r.compileError(err))
+		}
+		m.info = info
+		m.fn = fn
+	}
+	if r.modules == nil {
+		r.modules = make(map[string]*moduleRecord)
+	}
+	r.modules[name] = m
+	return m
+}
+// 加载模块依赖的所有模块，加载失败时下次重新加载
+func (r *Runtime) loadRequested(m *moduleRecord) {
+	if m.info == nil || m.requested != nil {
+		return
+	}
+	m.requested = make(map[string]*moduleRecord, len(m.info.requests))
+	done := false
+	defer func() {
+		if !done {
+			m.requested = nil
+		}
+	}()
+	for _, specifier := range m.info.requests {
+		dep := r.loadModule(m.name, specifier)
+		m.requested[specifier] = dep
+		r.loadRequested(dep)
+	}
+	done = true
+}
+// 链接模块：解析导入的绑定并实例化模块函数。循环依赖时正在链接的模块直接返回
+func (r *Runtime) linkModule(m *moduleRecord) {
+	if m.status != moduleUnlinked {
+		return
+	}
+	m.status = moduleLinking
+	defer func() {
+		if m.status == moduleLinking {
+			m.status = moduleUnlinked
+		}
+	}()
+	for _, specifier := range m.info.requests {
+		r.linkModule(m.requested[specifier])
+	}
+	for _, e := range m.info.indirectExports {
+		if e.importName != "*" {
+			m.requested[e.moduleRequest].resolveImport(e.importName)
+		}
+	}
+	env := r.newModuleNamespace(true)
+	for _, e := range m.info.imports {
+		dep := m.requested[e.moduleRequest]
+		if e.importName == "*" {
+			env.add(e.localName, moduleBinding{module: dep})
+		} else {
+			env.add(e.localName, dep.resolveImport(e.importName))
+		}
+	}
+
+	f := r.newFunc(m.fn.name, int(m.fn.length), true)
+	f.prg = m.fn.prg
+	f.stash = &stash{
+		obj: env,
+	}
+	f.generator = true
+	f.prototype = r.global.GeneratorFunctionPrototype
+	m.gen = f.Call(FunctionCall{This: _undefined}).(*Object).self.(*generatorObject)
+	m.env = m.gen.frame.ctx.stash
+	m.status = moduleLinked
+}
+// 按依赖顺序执行模块，每个模块只执行一次。循环依赖时正在执行的模块直接返回
+func (r *Runtime) evaluateModule(m *moduleRecord) {
+	switch m.status {
+	case moduleEvaluating:
+		return
+	case moduleEvaluated:
+		if m.evalErr != nil {
+			panic(m.evalErr)
+		}
+		if m.aborted {
+			panic(r.NewTypeError("Evaluation of module '%s' was aborted", m.name))
+		}
+		return
+	}
+	m.status = moduleEvaluating
+	defer func() {
+		// 中断的错误不会被try捕获，直接穿过这里
+		if m.status == moduleEvaluating {
+			m.status = moduleEvaluated
+			m.gen = nil
+			m.aborted = true
+		}
+	}()
+	ex := r.vm.try(func() {
+		for _, specifier := range m.info.requests {
+			r.evaluateModule(m.requested[specifier])
+		}
+		m.gen.resume(resumeNext, _undefined)
+	})
+	m.status = moduleEvaluated
+	m.gen = nil
+	if ex != nil {
+		m.evalErr = ex
+		panic(ex)
+	}
+}
+// 动态导入import()，在promise任务中加载模块，返回以命名空间对象兑现的promise
+func (r *Runtime) importModuleDynamically(referrer string, specifier Value) Value {
+	p := r.newPromise(r.global.PromisePrototype)
+	resolve, reject := p.createResolvingFunctions()
+	var name string
+	if ex := r.vm.try(func() {
+		name = specifier.String()
+	}); ex != nil {
+		r.toCallable(reject)(FunctionCall{Arguments: []Value{ex.val}})
+		return p.val
+	}
+	r.enqueuePromiseJob(func() {
+		var ns *Object
+		if ex := r.vm.try(func() {
+			ns = r.importModule(referrer, name).getNamespace()
+		}); ex != nil {
+			r.toCallable(reject)(FunctionCall{Arguments: []Value{ex.val}})
+		} else {
+			r.toCallable(resolve)(FunctionCall{Arguments: []Value{ns}})
+		}
+	})
+	return p.val
+}
+// 解析导入的名称，找不到或有歧义时抛出SyntaxError
+func (m *moduleRecord) resolveImport(name string) moduleBinding {
+	b, ok, ambiguous := m.resolveExport(name, new([]moduleBinding))
+	if ambiguous {
+		panic(m.r.newError(m.r.global.SyntaxError, "The requested module '%s' contains conflicting star exports for name '%s'", m.name, name))
+	}
+	if !ok {
+		panic(m.r.newError(m.r.global.SyntaxError, "The requested module '%s' does not provide an export named '%s'", m.name, name))
+	}
+	return b
+}
+// ResolveExport：查找导出名称对应的绑定，set记录已经查找过的项以处理循环的间接导出。多个export *导出同一名称时ambiguous为true
+func (m *moduleRecord) resolveExport(name string, set *[]moduleBinding) (b moduleBinding, ok, ambiguous bool) {
+	for _, item := range *set {
+		if item.module == m && item.name == name {
+			return
+		}
+	}
+	*set = append(*set, moduleBinding{module: m, name: name})
+	if m.info == nil {
+		if _, exists := m.values[name]; exists {
+			return moduleBinding{module: m, name: name}, true, false
+		}
+		return
+	}
+	for _, e := range m.info.localExports {
+		if e.exportName == name {
+			return moduleBinding{module: m, name: e.localName}, true, false
+		}
+	}
+	for _, e := range m.info.indirectExports {
+		if e.exportName == name {
+			dep := m.requested[e.moduleRequest]
+			if e.importName == "*" {
+				return moduleBinding{module: dep}, true, false
+			}
+			return dep.resolveExport(e.importName, set)
+		}
+	}
+	if name == "default" {
+		// export *不导出default
+		return
+	}
+	for _, e := range m.info.starExports {
+		res, found, amb := m.requested[e.moduleRequest].resolveExport(name, set)
+		if amb {
+			return moduleBinding{}, false, true
+		}
+		if found {
+			if ok && res != b {
+				return moduleBinding{}, false, true
+			}
+			b, ok = res, true
+		}
+	}
+	return
+}
+// GetExportedNames：返回模块导出的所有名称，visited用于处理循环的export *
+func (m *moduleRecord) exportedNames(visited map[*moduleRecord]bool) (names []string) {
+	if visited[m] {
+		return nil
+	}
+	visited[m] = true
+	if m.info == nil {
+		for name := range m.values {
+			names = append(names, name)
+		}
+		return
+	}
+	for _, e := range m.info.localExports {
+		names = append(names, e.exportName)
+	}
+	for _, e := range m.info.indirectExports {
+		names = append(names, e.exportName)
+	}
+	for _, e := range m.info.starExports {
+		for _, name := range m.requested[e.moduleRequest].exportedNames(visited) {
+			if name == "default" {
+				continue
+			}
+			dup := false
+			for _, n := range names {
+				if n == name {
+					dup = true
+					break
+				}
+			}
+			if !dup {
+				names = append(names, name)
+			}
+		}
+	}
+	return
+}
+// 返回模块的命名空间对象，有歧义的导出名称不包含在内
+func (m *moduleRecord) getNamespace() *Object {
+	if m.namespace == nil {
+		ns := m.r.newModuleNamespace(false)
+		for _, name := range m.exportedNames(make(map[*moduleRecord]bool)) {
+			if b, ok, _ := m.resolveExport(name, new([]moduleBinding)); ok {
+				ns.add(name, b)
+			}
+		}
+		sort.Strings(ns.names)
+		m.namespace = ns.val
+	}
+	return m.namespace
+}
+// 读取模块中名为name的绑定的值，未初始化时返回nil
+func (m *moduleRecord) bindingValue(name string) Value {
+	if m.info == nil {
+		return m.values[name]
+	}
+	for s := m.env; s != nil; s = s.outer {
+		if v, exists := s.getByName(name, nil); exists {
+			return v
+		}
+	}
+	return nil
+}
+// 读取绑定的值，未初始化时抛出ReferenceError
+func (b moduleBinding) get() Value {
+	if b.name == "" {
+		return b.module.getNamespace()
+	}
+	v := b.module.bindingValue(b.name)
+	if v == nil {
+		name := b.name
+		if name == defaultExportName {
+			name = "default"
+		}
+		b.module.r.throwUninitializedError(name)
+	}
+	return v
+}
+// 创建模块命名空间对象，env为true时用作导入绑定的作用域
+func (r *Runtime) newModuleNamespace(env bool) *moduleNamespace {
+	v := &Object{runtime: r}
+	o := &moduleNamespace{
+		bindings: make(map[string]moduleBinding),
+		env:      env,
+	}
+	o.class = classModule
+	o.val = v
+	v.self = o
+	o.init()
+	o._putSym(symToStringTag, asciiString(classModule), false, false, false)
+	return o
+}
+// 添加一个绑定
+func (o *moduleNamespace) add(name string, b moduleBinding) {
+	o.bindings[name] = b
+	o.names = append(o.names, name)
+}
+// 获取属性name，导出的绑定总是可写、可枚举、不可删除
+func (o *moduleNamespace) getOwnProp(name string) Value {
+	if b, exists := o.bindings[name]; exists {
+		return &valueProperty{
+			value:      b.get(),
+			writable:   true,
+			enumerable: true,
+		}
+	}
+	return nil
+}
+// 命名空间对象没有原型
+func (o *moduleNamespace) getPropStr(name string) Value {
+	return o.getOwnProp(name)
+}
+// 获取绑定的值
+func (o *moduleNamespace) getStr(name string) Value {
+	if b, exists := o.bindings[name]; exists {
+		return b.get()
+	}
+	return nil
+}
+// 获取属性n的值
+func (o *moduleNamespace) get(n Value) Value {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.getSym(s)
+	}
+	return o.getStr(n.String())
+}
+// 判断是否有属性n，不读取绑定的值
+func (o *moduleNamespace) hasProperty(n Value) bool {
+	return o.hasOwnProperty(n)
+}
+// 判断是否有属性name，不读取绑定的值
+func (o *moduleNamespace) hasPropertyStr(name string) bool {
+	return o.hasOwnPropertyStr(name)
+}
+// 判断是否有自有属性n
+func (o *moduleNamespace) hasOwnProperty(n Value) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.hasOwnPropertySym(s)
+	}
+	return o.hasOwnPropertyStr(n.String())
+}
+// 判断是否有自有属性name
+func (o *moduleNamespace) hasOwnPropertyStr(name string) bool {
+	_, exists := o.bindings[name]
+	return exists
+}
+// 设置属性n的值
+func (o *moduleNamespace) put(n Value, val Value, throw bool) {
+	if s, ok := n.(*valueSymbol); ok {
+		o.putSym(s, val, throw)
+		return
+	}
+	o.putStr(n.String(), val, throw)
+}
+// 导出的绑定只能在模块内部修改，给导入的绑定赋值与给const赋值相同
+func (o *moduleNamespace) putStr(name string, val Value, throw bool) {
+	if o.env {
+		o.val.runtime.throwConstAssignError()
+	}
+	o.val.runtime.typeErrorResult(throw, "Cannot assign to read only property '%s' of object '[object Module]'", name)
+}
+// 只允许与现有属性相同的定义
+func (o *moduleNamespace) defineOwnProperty(name Value, descr propertyDescr, throw bool) bool {
+	if s, ok := name.(*valueSymbol); ok {
+		return o.defineOwnPropertySym(s, descr, throw)
+	}
+	n := name.String()
+	if b, exists := o.bindings[n]; exists {
+		if descr.Getter == nil && descr.Setter == nil && descr.Configurable != FLAG_TRUE && descr.Enumerable != FLAG_FALSE &&
+			descr.Writable != FLAG_FALSE && (descr.Value == nil || descr.Value.SameAs(b.get())) {
+			return true
+		}
+	}
+	o.val.runtime.typeErrorResult(throw, "Cannot redefine property: %s", n)
+	return false
+}
+// 导出的绑定不能删除
+func (o *moduleNamespace) deleteStr(name string, throw bool) bool {
+	if _, exists := o.bindings[name]; exists {
+		o.val.runtime.typeErrorResult(throw, "Cannot delete property '%s' of [object Module]", name)
+		return false
+	}
+	return true
+}
+// 删除属性n
+func (o *moduleNamespace) delete(n Value, throw bool) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.deleteSym(s, throw)
+	}
+	return o.deleteStr(n.String(), throw)
+}
+// 命名空间对象不可扩展，原型只能是null
+func (o *moduleNamespace) setProto(proto *Object, throw bool) bool {
+	if proto == nil {
+		return true
+	}
+	o.val.runtime.typeErrorResult(throw, "Immutable prototype object '[object Module]' cannot have their prototype set")
+	return false
+}
+// 命名空间对象不可扩展
+func (o *moduleNamespace) isExtensible() bool {
+	return false
+}
+
+type moduleNamespacePropIter struct {
+	o   *moduleNamespace
+	idx int
+}
+// 下一个
+func (i *moduleNamespacePropIter) next() (propIterItem, iterNextFunc) {
+	if i.idx < len(i.o.names) {
+		name := i.o.names[i.idx]
+		i.idx++
+		return propIterItem{name: name, enumerable: _ENUM_TRUE}, i.next
+	}
+	return propIterItem{}, nil
+}
+// 按名称顺序枚举导出的绑定
+func (o *moduleNamespace) _enumerate(recursive bool) iterNextFunc {
+	return (&moduleNamespacePropIter{
+		o: o,
+	}).next
+}
+// 构造枚举迭代
+func (o *moduleNamespace) enumerate(all, recursive bool) iterNextFunc {
+	return (&propFilterIter{
+		wrapped: o._enumerate(recursive),
+		all:     all,
+		seen:    make(map[string]bool),
+	}).next
+}
+// 导出为map
+func (o *moduleNamespace) export() interface{} {
+	m := make(map[string]interface{}, len(o.names))
+	for _, name := range o.names {
+		m[name] = o.bindings[name].get().Export()
+	}
+	return m
+}
+// 导出类型
+func (o *moduleNamespace) exportType() reflect.Type {
+	return reflectTypeMap
+}
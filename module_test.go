@@ -0,0 +1,320 @@
+package goja
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"testing"
+)
+
+// 测试用的模块加载器，名称相对于引用者所在的目录解析
+type testModuleLoader struct {
+	sources   map[string]string
+	synthetic map[string]map[string]interface{}
+	loads     map[string]int
+}
+
+func (l *testModuleLoader) ResolveModule(referrer, specifier string) (string, error) {
+	if strings.HasPrefix(specifier, "./") || strings.HasPrefix(specifier, "../") {
+		return path.Join(path.Dir(referrer), specifier), nil
+	}
+	return specifier, nil
+}
+
+func (l *testModuleLoader) LoadModule(name string) (*ModuleSource, error) {
+	if l.loads == nil {
+		l.loads = make(map[string]int)
+	}
+	l.loads[name]++
+	if exports, exists := l.synthetic[name]; exists {
+		return &ModuleSource{Exports: exports}, nil
+	}
+	if src, exists := l.sources[name]; exists {
+		return &ModuleSource{Source: src}, nil
+	}
+	return nil, errors.New("module not found: " + name)
+}
+
+func runTestModule(t *testing.T, loader *testModuleLoader, name string) (*Runtime, *Object) {
+	r := New()
+	r.SetModuleLoader(loader)
+	ns, err := r.RunModule(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, ns
+}
+
+func TestModuleImportExport(t *testing.T) {
+	loader := &testModuleLoader{
+		sources: map[string]string{
+			"lib/math.js": `
+			export const pi = 3;
+			export function square(x) { return x * x; }
+			export let counter = 0;
+			export function inc() { counter++; }
+			var hidden = 1, shown = 2;
+			export { shown as visible, hidden as if };
+			export default class { get name() { return "anon"; } }
+			`,
+			"lib/index.js": `
+			export * from "./math.js";
+			export { default as MathDefault } from "./math.js";
+			export * as math from "./math.js";
+			`,
+			"main.js": `
+			import Anon, { pi, square, counter, inc, visible, if as kw } from "./lib/math.js";
+			import * as lib from "./lib/index.js";
+			import "./lib/index.js";
+			const before = counter;
+			inc();
+			export const results = [pi, square(4), before, counter, visible, kw, new Anon().name, Anon.name,
+				lib.square === square, lib.math.pi, typeof lib.default, new lib.MathDefault().name];
+			export default function () { return this; }
+			`,
+		},
+	}
+	r, ns := runTestModule(t, loader, "main.js")
+	r.Set("ns", ns)
+	v, err := r.RunString(`ns.results.join() + "|" + (0, ns.default)() + "|" + ns.default.name`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := v.String(); s != "3,16,0,1,2,1,anon,default,true,3,undefined,anon|undefined|default" {
+		t.Fatalf("Unexpected result: %q", s)
+	}
+	if loader.loads["lib/index.js"] != 1 || loader.loads["lib/math.js"] != 1 {
+		t.Fatalf("Modules loaded more than once: %v", loader.loads)
+	}
+}
+
+func TestModuleNamespaceObject(t *testing.T) {
+	loader := &testModuleLoader{
+		sources: map[string]string{
+			"m.js": `
+			export var b = 1, a = 2;
+			export function setB(v) { b = v; }
+			`,
+			"main.js": `
+			import * as ns from "m.js";
+			import { setB } from "m.js";
+			export function test() {
+				assert.sameValue(Object.prototype.toString.call(ns), "[object Module]");
+				assert.sameValue(Object.getPrototypeOf(ns), null);
+				assert.sameValue(Object.isExtensible(ns), false);
+				assert.sameValue(Object.keys(ns).join(), "a,b,setB");
+				assert.sameValue("b" in ns, true);
+				assert.sameValue("c" in ns, false);
+				setB(5);
+				assert.sameValue(ns.b, 5, "live binding");
+				var desc = Object.getOwnPropertyDescriptor(ns, "b");
+				assert.sameValue(desc.value, 5);
+				assert.sameValue(desc.writable, true);
+				assert.sameValue(desc.enumerable, true);
+				assert.sameValue(desc.configurable, false);
+				assert.throws(TypeError, function() { ns.b = 1; });
+				assert.throws(TypeError, function() { ns.c = 1; });
+				assert.throws(TypeError, function() { delete ns.b; });
+				assert.sameValue(delete ns.c, true);
+				assert.throws(TypeError, function() { setB = null; });
+				assert.sameValue(this, undefined);
+				return true;
+			}
+			`,
+		},
+	}
+	r, ns := runTestModule(t, loader, "main.js")
+	if _, err := r.RunString(TESTLIB); err != nil {
+		t.Fatal(err)
+	}
+	test, ok := AssertFunction(ns.Get("test"))
+	if !ok {
+		t.Fatal("test is not a function")
+	}
+	v, err := test(_undefined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != valueTrue {
+		t.Fatalf("Unexpected result: %v", v)
+	}
+}
+
+func TestModuleCycle(t *testing.T) {
+	loader := &testModuleLoader{
+		sources: map[string]string{
+			"a.js": `
+			import { b, getA } from "b.js";
+			export function a() { return "a" + b(); }
+			export const order = [];
+			order.push("a", getA());
+			`,
+			"b.js": `
+			import { a, order } from "a.js";
+			export function b() { return "b"; }
+			export function getA() { return a(); }
+			var tdz;
+			try { order; } catch (e) { tdz = e instanceof ReferenceError; }
+			export { tdz };
+			`,
+		},
+	}
+	r, ns := runTestModule(t, loader, "a.js")
+	r.Set("ns", ns)
+	r.Set("b", r.modules["b.js"].getNamespace())
+	v, err := r.RunString(`ns.order.join() + "|" + b.tdz`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := v.String(); s != "a,ab|true" {
+		t.Fatalf("Unexpected result: %q", s)
+	}
+}
+
+func TestModuleSynthetic(t *testing.T) {
+	loader := &testModuleLoader{
+		sources: map[string]string{
+			"main.js": `
+			import fmt, { add, version } from "host:util";
+			export const result = add(2, 3) + " " + version + " " + fmt("x");
+			`,
+		},
+		synthetic: map[string]map[string]interface{}{
+			"host:util": {
+				"add":     func(a, b int) int { return a + b },
+				"version": "1.0",
+				"default": func(s string) string { return "<" + s + ">" },
+			},
+		},
+	}
+	_, ns := runTestModule(t, loader, "main.js")
+	if s := ns.Get("result").String(); s != "5 1.0 <x>" {
+		t.Fatalf("Unexpected result: %q", s)
+	}
+	if m, ok := ns.Export().(map[string]interface{}); !ok || m["result"] != "5 1.0 <x>" {
+		t.Fatalf("Unexpected export: %v", ns.Export())
+	}
+}
+
+func TestModuleDynamicImport(t *testing.T) {
+	loader := &testModuleLoader{
+		sources: map[string]string{
+			"dir/m.js":   `export const x = 42;`,
+			"dir/bad.js": `throw new Error("boom");`,
+			"dir/main.js": `
+			export var log = [];
+			import("./m.js").then(function(ns) { log.push(ns.x); });
+			import("./missing.js").catch(function(e) { log.push(e instanceof GoError); });
+			import("./bad.js").catch(function(e) { log.push(e.message); });
+			`,
+		},
+	}
+	_, ns := runTestModule(t, loader, "dir/main.js")
+	if s := ns.Get("log").String(); s != "42,true,boom" {
+		t.Fatalf("Unexpected result: %q", s)
+	}
+
+	r := New()
+	r.SetModuleLoader(loader)
+	if _, err := r.RunScript("dir/script.js", `
+	var res;
+	import("./m.js").then(function(ns) { res = ns.x; });
+	`); err != nil {
+		t.Fatal(err)
+	}
+	if res := r.Get("res"); res == nil || res.ToInteger() != 42 {
+		t.Fatalf("Unexpected result: %v", res)
+	}
+}
+
+func TestModuleErrors(t *testing.T) {
+	loader := &testModuleLoader{
+		sources: map[string]string{
+			"throws.js":    `export let x = 1; throw new Error("init failed");`,
+			"missing.js":   `import { nope } from "throws.js";`,
+			"a.js":         `export const x = 1;`,
+			"b.js":         `export const x = 2;`,
+			"ambig.js":     `export * from "a.js"; export * from "b.js";`,
+			"useambig.js":  `import { x } from "ambig.js";`,
+			"syntax.js":    `export { undeclared };`,
+			"dupexport.js": `var a; export { a, a };`,
+			"dupimport.js": `import { x } from "a.js"; let x;`,
+			"assign.js":    `import { x } from "a.js"; x = 2;`,
+		},
+	}
+	r := New()
+	r.SetModuleLoader(loader)
+	check := func(name, errType, msg string) {
+		_, err := r.RunModule(name)
+		ex, ok := err.(*Exception)
+		if !ok {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		s := ex.Value().String()
+		if !strings.HasPrefix(s, errType) || !strings.Contains(s, msg) {
+			t.Fatalf("%s: unexpected error: %s", name, s)
+		}
+	}
+	check("throws.js", "Error", "init failed")
+	check("throws.js", "Error", "init failed")
+	check("missing.js", "SyntaxError", "does not provide an export named 'nope'")
+	check("useambig.js", "SyntaxError", "conflicting star exports")
+	check("syntax.js", "SyntaxError", "Export 'undeclared' is not defined")
+	check("dupexport.js", "SyntaxError", "Duplicate export of 'a'")
+	check("dupimport.js", "SyntaxError", "Identifier 'x' has already been declared")
+	check("assign.js", "TypeError", "Assignment to constant variable")
+	check("notfound.js", "GoError", "module not found")
+
+	ns, err := r.RunModule("ambig.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ns.Get("x") != nil {
+		t.Fatal("Ambiguous export must not be in the namespace")
+	}
+
+	r = New()
+	if _, err := r.RunModule("a.js"); err == nil {
+		t.Fatal("Expected an error without a module loader")
+	}
+	if _, err := r.RunString(`import x from "a.js"`); err == nil {
+		t.Fatal("Expected a syntax error for import in a script")
+	}
+}
+
+func TestModuleAbortedEvaluation(t *testing.T) {
+	loader := &testModuleLoader{
+		sources: map[string]string{
+			"loop.js": `export let x = 1; for (;;) {}`,
+			"main.js": `import { x } from "loop.js"; export const y = x;`,
+		},
+	}
+	r := New()
+	r.SetModuleLoader(loader)
+	r.SetGasLimit(10000)
+	if _, err := r.RunModule("main.js"); err == nil {
+		t.Fatal("Expected an error")
+	} else if _, ok := err.(*GasLimitError); !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	r.SetGasLimit(0)
+	for _, name := range []string{"loop.js", "main.js"} {
+		ns, err := r.RunModule(name)
+		if ns != nil {
+			t.Fatalf("%s: unexpected namespace", name)
+		}
+		ex, ok := err.(*Exception)
+		if !ok {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if s := ex.Value().String(); !strings.Contains(s, "Evaluation of module '"+name+"' was aborted") {
+			t.Fatalf("%s: unexpected error: %s", name, s)
+		}
+	}
+	if _, err := r.RunString(`var res; import("loop.js").then(function() { res = "resolved"; }, function(e) { res = e instanceof TypeError; })`); err != nil {
+		t.Fatal(err)
+	}
+	if res := r.Get("res"); res != valueTrue {
+		t.Fatalf("Unexpected dynamic import result: %v", res)
+	}
+}
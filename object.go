@@ -12,11 +12,21 @@ const (
 	classError    = "Error"
 	classRegExp   = "RegExp"
 	classDate     = "Date"
+	classSymbol   = "Symbol"
+	classBigInt   = "BigInt"
+	classPromise  = "Promise"
+	classMap      = "Map"
+	classSet      = "Set"
+	classWeakMap  = "WeakMap"
+	classWeakSet  = "WeakSet"
 )
 
 type Object struct {
 	runtime *Runtime
 	self    objectImpl
+
+	// 以该对象为键的WeakMap/WeakSet中的值
+	weakRefs map[weakMap]Value
 }
 
 type iterNextFunc func() (propIterItem, iterNextFunc)
@@ -37,6 +47,7 @@ type objectImpl interface {
 	getPropStr(string) Value
 	getStr(string) Value
 	getOwnProp(string) Value
+	getOwnPropSym(*valueSymbol) Value
 	put(Value, Value, bool)
 	putStr(string, Value, bool)
 	hasProperty(Value) bool
@@ -44,6 +55,7 @@ type objectImpl interface {
 	hasOwnProperty(Value) bool
 	hasOwnPropertyStr(string) bool
 	_putProp(name string, value Value, writable, enumerable, configurable bool) Value
+	_putSym(s *valueSymbol, value Value, writable, enumerable, configurable bool) Value
 	defineOwnProperty(name Value, descr propertyDescr, throw bool) bool
 	toPrimitiveNumber() Value
 	toPrimitiveString() Value
@@ -53,10 +65,12 @@ type objectImpl interface {
 	delete(name Value, throw bool) bool
 	proto() *Object
 	hasInstance(v Value) bool
+	setProto(proto *Object, throw bool) bool
 	isExtensible() bool
-	preventExtensions()
+	preventExtensions(throw bool) bool
 	enumerate(all, recusrive bool) iterNextFunc
 	_enumerate(recursive bool) iterNextFunc
+	ownSymbols() []Value
 	export() interface{}
 	exportType() reflect.Type
 	equal(objectImpl) bool
@@ -70,6 +84,9 @@ type baseObject struct {
 
 	values    map[string]Value // 所有的属性和属性值，可以是函数名和函数
 	propNames []string // 所有的属性列表，便于遍历
+
+	symValues map[*valueSymbol]Value // 以symbol为键的属性，按需创建
+	symNames  []*valueSymbol // symbol属性列表，保持定义顺序
 }
 
 type primitiveValueObject struct {
@@ -110,6 +127,7 @@ func (f ConstructorCall) Argument(idx int) Value {
 }
 // 初始化map
 func (o *baseObject) init() {
+	o.val.runtime.allocate(sizeofObject)
 	o.values = make(map[string]Value)
 }
 // 获取类名
@@ -128,6 +146,9 @@ func (o *baseObject) getPropStr(name string) Value {
 }
 // 判断是否有属性n
 func (o *baseObject) getProp(n Value) Value {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.getPropSym(s)
+	}
 	return o.val.self.getPropStr(n.String())
 }
 // 判断是否有属性n
@@ -163,6 +184,9 @@ func (o *baseObject) getStr(name string) Value {
 }
 // 获取n的值
 func (o *baseObject) get(n Value) Value {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.getSym(s)
+	}
 	return o.getStr(n.String())
 }
 // 判断是否可删除
@@ -204,10 +228,17 @@ func (o *baseObject) deleteStr(name string, throw bool) bool {
 }
 // 删除属性name
 func (o *baseObject) delete(n Value, throw bool) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.deleteSym(s, throw)
+	}
 	return o.deleteStr(n.String(), throw)
 }
 // 设置属性name的值为val
 func (o *baseObject) put(n Value, val Value, throw bool) {
+	if s, ok := n.(*valueSymbol); ok {
+		o.putSym(s, val, throw)
+		return
+	}
 	o.putStr(n.String(), val, throw)
 }
 // 获取属性name的值
@@ -272,11 +303,15 @@ func (o *baseObject) putStr(name string, val Value, throw bool) {
 		}
 	}
 
+	o.val.runtime.allocate(sizeofProperty)
 	o.values[name] = val
 	o.propNames = append(o.propNames, name)
 }
 // 是否有属性n
 func (o *baseObject) hasOwnProperty(n Value) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.hasOwnPropertySym(s)
+	}
 	v := o.values[n.String()]
 	return v != nil
 }
@@ -384,17 +419,21 @@ func (o *baseObject) _defineOwnProperty(name, existingValue Value, descr propert
 	return existing, true
 
 Reject:
-	o.val.runtime.typeErrorResult(throw, "Cannot redefine property: %s", name.ToString())
+	o.val.runtime.typeErrorResult(throw, "Cannot redefine property: %s", name.String())
 	return nil, false
 
 }
 // 添加一个属性
 func (o *baseObject) defineOwnProperty(n Value, descr propertyDescr, throw bool) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.defineOwnPropertySym(s, descr, throw)
+	}
 	name := n.String()
 	existingVal := o.values[name]
 	if v, ok := o._defineOwnProperty(n, existingVal, descr, throw); ok {
 		o.values[name] = v
 		if existingVal == nil {
+			o.val.runtime.allocate(sizeofProperty)
 			o.propNames = append(o.propNames, name)
 		}
 		return true
@@ -404,6 +443,7 @@ func (o *baseObject) defineOwnProperty(n Value, descr propertyDescr, throw bool)
 // 设置name的值为val
 func (o *baseObject) _put(name string, v Value) {
 	if _, exists := o.values[name]; !exists {
+		o.val.runtime.allocate(sizeofProperty)
 		o.propNames = append(o.propNames, name)
 	}
 
@@ -425,6 +465,131 @@ func (o *baseObject) _putProp(name string, value Value, writable, enumerable, co
 		return p
 	}
 }
+// 获取symbol属性s，不查找原型
+func (o *baseObject) getOwnPropSym(s *valueSymbol) Value {
+	return o.symValues[s]
+}
+// 获取symbol属性s，沿原型链查找
+func (o *baseObject) getPropSym(s *valueSymbol) Value {
+	if v := o.symValues[s]; v != nil {
+		return v
+	}
+	if o.prototype != nil {
+		return o.prototype.self.getProp(s)
+	}
+	return nil
+}
+// 获取symbol属性s的值
+func (o *baseObject) getSym(s *valueSymbol) Value {
+	p := o.getPropSym(s)
+	if p, ok := p.(*valueProperty); ok {
+		return p.get(o.val)
+	}
+	return p
+}
+// 设置symbol属性s的值为val
+func (o *baseObject) putSym(s *valueSymbol, val Value, throw bool) {
+	if v, exists := o.symValues[s]; exists {
+		if prop, ok := v.(*valueProperty); ok {
+			if !prop.isWritable() {
+				o.val.runtime.typeErrorResult(throw, "Cannot assign to read only property '%s'", s.String())
+				return
+			}
+			prop.set(o.val, val)
+			return
+		}
+		o.symValues[s] = val
+		return
+	}
+
+	var pprop Value
+	if proto := o.prototype; proto != nil {
+		pprop = proto.self.getProp(s)
+	}
+
+	if pprop != nil {
+		if prop, ok := pprop.(*valueProperty); ok {
+			if !prop.isWritable() {
+				o.val.runtime.typeErrorResult(throw, "Cannot assign to read only property '%s'", s.String())
+				return
+			}
+			if prop.accessor {
+				prop.set(o.val, val)
+				return
+			}
+		}
+	} else {
+		if !o.extensible {
+			o.val.runtime.typeErrorResult(throw)
+			return
+		}
+	}
+
+	o._putSymValue(s, val)
+}
+// 是否有symbol属性s
+func (o *baseObject) hasOwnPropertySym(s *valueSymbol) bool {
+	return o.symValues[s] != nil
+}
+// 删除symbol属性s
+func (o *baseObject) deleteSym(s *valueSymbol, throw bool) bool {
+	if val, exists := o.symValues[s]; exists {
+		if !o.checkDelete(s.String(), val, throw) {
+			return false
+		}
+		delete(o.symValues, s)
+		for i, n := range o.symNames {
+			if n == s {
+				copy(o.symNames[i:], o.symNames[i+1:])
+				o.symNames = o.symNames[:len(o.symNames)-1]
+				break
+			}
+		}
+	}
+	return true
+}
+// 添加一个symbol属性
+func (o *baseObject) defineOwnPropertySym(s *valueSymbol, descr propertyDescr, throw bool) bool {
+	existingVal := o.symValues[s]
+	if v, ok := o._defineOwnProperty(s, existingVal, descr, throw); ok {
+		o._putSymValue(s, v)
+		return true
+	}
+	return false
+}
+// 设置symbol属性s的值为v
+func (o *baseObject) _putSymValue(s *valueSymbol, v Value) {
+	if o.symValues == nil {
+		o.symValues = make(map[*valueSymbol]Value)
+	}
+	if _, exists := o.symValues[s]; !exists {
+		o.symNames = append(o.symNames, s)
+	}
+	o.symValues[s] = v
+}
+// 设置symbol属性s的值为value
+func (o *baseObject) _putSym(s *valueSymbol, value Value, writable, enumerable, configurable bool) Value {
+	if writable && enumerable && configurable {
+		o._putSymValue(s, value)
+		return value
+	}
+	p := &valueProperty{
+		value:        value,
+		writable:     writable,
+		enumerable:   enumerable,
+		configurable: configurable,
+	}
+	o._putSymValue(s, p)
+	return p
+}
+// 返回所有自有的symbol属性
+func (o *baseObject) ownSymbols() []Value {
+	res := make([]Value, 0, len(o.symNames))
+	for _, s := range o.symNames {
+		res = append(res, s)
+	}
+	return res
+}
 // 尝试调用函数methodName
 func (o *baseObject) tryPrimitive(methodName string) Value {
 	if method, ok := o.getStr(methodName).(*Object); ok {
@@ -439,8 +604,33 @@ func (o *baseObject) tryPrimitive(methodName string) Value {
 	}
 	return nil
 }
+// 尝试调用[Symbol.toPrimitive](hint)
+func (o *baseObject) tryExoticToPrimitive(hint string) Value {
+	exoticToPrimitive := o.val.self.get(symToPrimitive)
+	if exoticToPrimitive == nil || exoticToPrimitive == _undefined || exoticToPrimitive == _null {
+		return nil
+	}
+	if method, ok := exoticToPrimitive.(*Object); ok {
+		if call, ok := method.self.assertCallable(); ok {
+			v := call(FunctionCall{
+				This:      o.val,
+				Arguments: []Value{newStringValue(hint)},
+			})
+			if _, fail := v.(*Object); !fail {
+				return v
+			}
+			o.val.runtime.typeErrorResult(true, "Cannot convert object to primitive value")
+		}
+	}
+	o.val.runtime.typeErrorResult(true, "Symbol.toPrimitive is not a function")
+	return nil
+}
 // 尝试转换为number
 func (o *baseObject) toPrimitiveNumber() Value {
+	if v := o.tryExoticToPrimitive("number"); v != nil {
+		return v
+	}
+
 	if v := o.tryPrimitive("valueOf"); v != nil {
 		return v
 	}
@@ -454,6 +644,10 @@ func (o *baseObject) toPrimitiveNumber() Value {
 }
 // 尝试转换为string
 func (o *baseObject) toPrimitiveString() Value {
+	if v := o.tryExoticToPrimitive("string"); v != nil {
+		return v
+	}
+
 	if v := o.tryPrimitive("toString"); v != nil {
 		return v
 	}
@@ -467,6 +661,9 @@ func (o *baseObject) toPrimitiveString() Value {
 }
 // 尝试转换为number
 func (o *baseObject) toPrimitive() Value {
+	if v := o.tryExoticToPrimitive("default"); v != nil {
+		return v
+	}
 	return o.toPrimitiveNumber()
 }
 // 找不到对应的函数
@@ -477,13 +674,35 @@ func (o *baseObject) assertCallable() (func(FunctionCall) Value, bool) {
 func (o *baseObject) proto() *Object {
 	return o.prototype
 }
+// 设置原型，不可扩展或形成循环时失败
+func (o *baseObject) setProto(proto *Object, throw bool) bool {
+	if o.prototype == proto {
+		return true
+	}
+	if !o.extensible {
+		o.val.runtime.typeErrorResult(throw, "%s is not extensible", o.val)
+		return false
+	}
+	for p := proto; p != nil; p = p.self.proto() {
+		if p == o.val {
+			o.val.runtime.typeErrorResult(throw, "Cyclic __proto__ value")
+			return false
+		}
+		if _, ok := p.self.(*Proxy); ok {
+			break
+		}
+	}
+	o.prototype = proto
+	return true
+}
 // 获得扩展属性
 func (o *baseObject) isExtensible() bool {
 	return o.extensible
 }
 // 禁止扩展
-func (o *baseObject) preventExtensions() {
+func (o *baseObject) preventExtensions(throw bool) bool {
 	o.extensible = false
+	return true
 }
 // 获取length
 func (o *baseObject) sortLen() int64 {
@@ -18,6 +18,9 @@ func (a *argumentsObject) getPropStr(name string) Value {
 }
 // 获取属性
 func (a *argumentsObject) getProp(n Value) Value {
+	if s, ok := n.(*valueSymbol); ok {
+		return a.getPropSym(s)
+	}
 	return a.getPropStr(n.String())
 }
 // 初始化
@@ -27,6 +30,10 @@ func (a *argumentsObject) init() {
 }
 // 设置属性
 func (a *argumentsObject) put(n Value, val Value, throw bool) {
+	if s, ok := n.(*valueSymbol); ok {
+		a.putSym(s, val, throw)
+		return
+	}
 	a.putStr(n.String(), val, throw)
 }
 // 设置属性
@@ -55,6 +62,9 @@ func (a *argumentsObject) deleteStr(name string, throw bool) bool {
 }
 // 删除属性
 func (a *argumentsObject) delete(n Value, throw bool) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return a.deleteSym(s, throw)
+	}
 	return a.deleteStr(n.String(), throw)
 }
 
@@ -94,6 +104,9 @@ func (a *argumentsObject) enumerate(all, recursive bool) iterNextFunc {
 }
 // 定义属性
 func (a *argumentsObject) defineOwnProperty(n Value, descr propertyDescr, throw bool) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return a.defineOwnPropertySym(s, descr, throw)
+	}
 	name := n.String()
 	if mapped, ok := a.values[name].(*mappedProperty); ok {
 		existing := &valueProperty{
@@ -30,10 +30,16 @@ func (o *objectGoMapSimple) _getStr(name string) Value {
 }
 // 获取map中n的值
 func (o *objectGoMapSimple) get(n Value) Value {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.getSym(s)
+	}
 	return o.getStr(n.String())
 }
 // 获取map中n的值
 func (o *objectGoMapSimple) getProp(n Value) Value {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.getPropSym(s)
+	}
 	return o.getPropStr(n.String())
 }
 // 获取map中n的值
@@ -59,6 +65,10 @@ func (o *objectGoMapSimple) getOwnProp(name string) Value {
 }
 // 保存n和val到map中
 func (o *objectGoMapSimple) put(n Value, val Value, throw bool) {
+	if s, ok := n.(*valueSymbol); ok {
+		o.putSym(s, val, throw)
+		return
+	}
 	o.putStr(n.String(), val, throw)
 }
 // 判断map中是否包含name
@@ -80,6 +90,9 @@ func (o *objectGoMapSimple) putStr(name string, val Value, throw bool) {
 }
 // 判断map中是否包含n
 func (o *objectGoMapSimple) hasProperty(n Value) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.getPropSym(s) != nil
+	}
 	if o._has(n) {
 		return true
 	}
@@ -94,6 +107,9 @@ func (o *objectGoMapSimple) hasPropertyStr(name string) bool {
 }
 // 判断map中是否包含n
 func (o *objectGoMapSimple) hasOwnProperty(n Value) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.hasOwnPropertySym(s)
+	}
 	return o._has(n)
 }
 // 判断map中是否包含name
@@ -107,6 +123,9 @@ func (o *objectGoMapSimple) _putProp(name string, value Value, writable, enumera
 }
 // 保存name和descr.Value到map中
 func (o *objectGoMapSimple) defineOwnProperty(name Value, descr propertyDescr, throw bool) bool {
+	if s, ok := name.(*valueSymbol); ok {
+		return o.defineOwnPropertySym(s, descr, throw)
+	}
 	if descr.Getter != nil || descr.Setter != nil {
 		o.val.runtime.typeErrorResult(throw, "Host objects do not support accessor properties")
 		return false
@@ -139,6 +158,9 @@ func (o *objectGoMapSimple) deleteStr(name string, throw bool) bool {
 }
 // 删除map中的name
 func (o *objectGoMapSimple) delete(name Value, throw bool) bool {
+	if s, ok := name.(*valueSymbol); ok {
+		return o.deleteSym(s, throw)
+	}
 	return o.deleteStr(name.String(), throw)
 }
 
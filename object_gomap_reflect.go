@@ -47,6 +47,9 @@ func (o *objectGoMapReflect) _getStr(name string) Value {
 }
 // 由n获取对应的value
 func (o *objectGoMapReflect) get(n Value) Value {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.getSym(s)
+	}
 	if v := o._get(n); v != nil {
 		return v
 	}
@@ -61,6 +64,9 @@ func (o *objectGoMapReflect) getStr(name string) Value {
 }
 // 由n获取对应的value
 func (o *objectGoMapReflect) getProp(n Value) Value {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.getPropSym(s)
+	}
 	return o.get(n)
 }
 // 由name获取对应的value
@@ -90,6 +96,10 @@ func (o *objectGoMapReflect) toValue(val Value, throw bool) (reflect.Value, bool
 }
 // 保存js的kv
 func (o *objectGoMapReflect) put(key, val Value, throw bool) {
+	if s, ok := key.(*valueSymbol); ok {
+		o.putSym(s, val, throw)
+		return
+	}
 	k := o.toKey(key)
 	v, ok := o.toValue(val, throw)
 	if !ok {
@@ -113,6 +123,9 @@ func (o *objectGoMapReflect) _putProp(name string, value Value, writable, enumer
 }
 // 保存js的kv
 func (o *objectGoMapReflect) defineOwnProperty(n Value, descr propertyDescr, throw bool) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.defineOwnPropertySym(s, descr, throw)
+	}
 	name := n.String()
 	if !o.val.runtime.checkHostObjectPropertyDescr(name, descr, throw) {
 		return false
@@ -127,10 +140,16 @@ func (o *objectGoMapReflect) hasOwnPropertyStr(name string) bool {
 }
 // 判断是否存在n的值
 func (o *objectGoMapReflect) hasOwnProperty(n Value) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.hasOwnPropertySym(s)
+	}
 	return o.value.MapIndex(o.toKey(n)).IsValid()
 }
 // 判断是否存在n的值
 func (o *objectGoMapReflect) hasProperty(n Value) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.getPropSym(s) != nil
+	}
 	if o.hasOwnProperty(n) {
 		return true
 	}
@@ -145,6 +164,9 @@ func (o *objectGoMapReflect) hasPropertyStr(name string) bool {
 }
 // 删除n的值
 func (o *objectGoMapReflect) delete(n Value, throw bool) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.deleteSym(s, throw)
+	}
 	o.value.SetMapIndex(o.toKey(n), reflect.Value{})
 	return true
 }
@@ -90,6 +90,9 @@ func (o *objectGoReflect) valueOfFunc(call FunctionCall) Value {
 }
 // 如果是结构，就返回对应字段，否则返回对应函数
 func (o *objectGoReflect) get(n Value) Value {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.getSym(s)
+	}
 	return o.getStr(n.String())
 }
 // 返回jsName字段的值
@@ -132,6 +135,9 @@ func (o *objectGoReflect) getStr(name string) Value {
 }
 // 如果是结构，就返回对应字段，否则返回对应函数
 func (o *objectGoReflect) getProp(n Value) Value {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.getPropSym(s)
+	}
 	name := n.String()
 	if p := o.getOwnProp(name); p != nil {
 		return p
@@ -150,7 +156,8 @@ func (o *objectGoReflect) getOwnProp(name string) Value {
 	if o.value.Kind() == reflect.Struct {
 		if v := o._getField(name); v.IsValid() {
 			canSet := v.CanSet()
-			if (v.Kind() == reflect.Struct || v.Kind() == reflect.Slice) && v.CanAddr() {
+			// []byte字段和ToValue()一样转换为共享内存的Uint8Array
+			if (v.Kind() == reflect.Struct || v.Kind() == reflect.Slice && v.Type() != typeBytes) && v.CanAddr() {
 				v = v.Addr()
 			}
 			return &valueProperty{
@@ -172,6 +179,10 @@ func (o *objectGoReflect) getOwnProp(name string) Value {
 }
 // 设置name的值为val
 func (o *objectGoReflect) put(n Value, val Value, throw bool) {
+	if s, ok := n.(*valueSymbol); ok {
+		o.putSym(s, val, throw)
+		return
+	}
 	o.putStr(n.String(), val, throw)
 }
 // 设置name的值为val
@@ -224,6 +235,9 @@ func (r *Runtime) checkHostObjectPropertyDescr(name string, descr propertyDescr,
 }
 // 添加属性n
 func (o *objectGoReflect) defineOwnProperty(n Value, descr propertyDescr, throw bool) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.defineOwnPropertySym(s, descr, throw)
+	}
 	if o.value.Kind() == reflect.Struct {
 		name := n.String()
 		if v := o._getField(name); v.IsValid() {
@@ -260,6 +274,9 @@ func (o *objectGoReflect) _has(name string) bool {
 }
 // 检查是否有属性name或函数name
 func (o *objectGoReflect) hasProperty(n Value) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.getPropSym(s) != nil
+	}
 	name := n.String()
 	if o._has(name) {
 		return true
@@ -275,6 +292,9 @@ func (o *objectGoReflect) hasPropertyStr(name string) bool {
 }
 // 检查是否有属性name或函数name
 func (o *objectGoReflect) hasOwnProperty(n Value) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.hasOwnPropertySym(s)
+	}
 	return o._has(n.String())
 }
 // 检查是否有属性name或函数name
@@ -348,6 +368,9 @@ func (o *objectGoReflect) deleteStr(name string, throw bool) bool {
 }
 // 删除属性name
 func (o *objectGoReflect) delete(name Value, throw bool) bool {
+	if s, ok := name.(*valueSymbol); ok {
+		return o.deleteSym(s, throw)
+	}
 	return o.deleteStr(name.String(), throw)
 }
 
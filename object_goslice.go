@@ -47,6 +47,9 @@ func (o *objectGoSlice) _getStr(name string) Value {
 }
 // 获取n位置的值
 func (o *objectGoSlice) get(n Value) Value {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.getSym(s)
+	}
 	if v := o._get(n); v != nil {
 		return v
 	}
@@ -61,6 +64,9 @@ func (o *objectGoSlice) getStr(name string) Value {
 }
 // 获取n位置的值
 func (o *objectGoSlice) getProp(n Value) Value {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.getPropSym(s)
+	}
 	if v := o._get(n); v != nil {
 		return v
 	}
@@ -123,6 +129,10 @@ func (o *objectGoSlice) putIdx(idx int64, v Value, throw bool) {
 }
 // 在n位置保存val值
 func (o *objectGoSlice) put(n Value, val Value, throw bool) {
+	if s, ok := n.(*valueSymbol); ok {
+		o.putSym(s, val, throw)
+		return
+	}
 	if idx := toIdx(n); idx >= 0 {
 		o.putIdx(idx, val, throw)
 		return
@@ -155,6 +165,9 @@ func (o *objectGoSlice) _hasStr(name string) bool {
 }
 // 判断n值是否存在
 func (o *objectGoSlice) hasProperty(n Value) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.getPropSym(s) != nil
+	}
 	if o._has(n) {
 		return true
 	}
@@ -169,6 +182,9 @@ func (o *objectGoSlice) hasPropertyStr(name string) bool {
 }
 // 判断n值是否存在
 func (o *objectGoSlice) hasOwnProperty(n Value) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.hasOwnPropertySym(s)
+	}
 	if o._has(n) {
 		return true
 	}
@@ -188,6 +204,9 @@ func (o *objectGoSlice) _putProp(name string, value Value, writable, enumerable,
 }
 // 在n位置保存descr.Value值
 func (o *objectGoSlice) defineOwnProperty(n Value, descr propertyDescr, throw bool) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.defineOwnPropertySym(s, descr, throw)
+	}
 	if idx := toIdx(n); idx >= 0 {
 		if !o.val.runtime.checkHostObjectPropertyDescr(n.String(), descr, throw) {
 			return false
@@ -225,6 +244,9 @@ func (o *objectGoSlice) deleteStr(name string, throw bool) bool {
 }
 // 删除name位置的值
 func (o *objectGoSlice) delete(name Value, throw bool) bool {
+	if s, ok := name.(*valueSymbol); ok {
+		return o.deleteSym(s, throw)
+	}
 	if idx := toIdx(name); idx >= 0 && idx < int64(len(*o.data)) {
 		(*o.data)[idx] = nil
 		return true
@@ -61,6 +61,9 @@ func (o *objectGoSliceReflect) _getStr(name string) Value {
 }
 // 获得n位置的值
 func (o *objectGoSliceReflect) get(n Value) Value {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.getSym(s)
+	}
 	if v := o._get(n); v != nil {
 		return v
 	}
@@ -75,6 +78,9 @@ func (o *objectGoSliceReflect) getStr(name string) Value {
 }
 // 获得n位置的值
 func (o *objectGoSliceReflect) getProp(n Value) Value {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.getPropSym(s)
+	}
 	if v := o._get(n); v != nil {
 		return v
 	}
@@ -138,6 +144,10 @@ func (o *objectGoSliceReflect) grow(size int) {
 }
 // 在n位置保存值v
 func (o *objectGoSliceReflect) put(n Value, val Value, throw bool) {
+	if s, ok := n.(*valueSymbol); ok {
+		o.putSym(s, val, throw)
+		return
+	}
 	if idx := toIdx(n); idx >= 0 {
 		o.putIdx(idx, val, throw)
 		return
@@ -159,6 +169,9 @@ func (o *objectGoSliceReflect) putStr(name string, val Value, throw bool) {
 }
 // 判断n位置是否有值
 func (o *objectGoSliceReflect) hasProperty(n Value) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.getPropSym(s) != nil
+	}
 	if o._has(n) {
 		return true
 	}
@@ -173,6 +186,9 @@ func (o *objectGoSliceReflect) hasPropertyStr(name string) bool {
 }
 // 判断n位置是否有值
 func (o *objectGoSliceReflect) hasOwnProperty(n Value) bool {
+	if s, ok := n.(*valueSymbol); ok {
+		return o.hasOwnPropertySym(s)
+	}
 	if o._has(n) {
 		return true
 	}
@@ -192,6 +208,9 @@ func (o *objectGoSliceReflect) _putProp(name string, value Value, writable, enum
 }
 // 在name位置保存值val
 func (o *objectGoSliceReflect) defineOwnProperty(name Value, descr propertyDescr, throw bool) bool {
+	if s, ok := name.(*valueSymbol); ok {
+		return o.defineOwnPropertySym(s, descr, throw)
+	}
 	if !o.val.runtime.checkHostObjectPropertyDescr(name.String(), descr, throw) {
 		return false
 	}
@@ -222,6 +241,9 @@ func (o *objectGoSliceReflect) deleteStr(name string, throw bool) bool {
 }
 // 删除name位置的值
 func (o *objectGoSliceReflect) delete(name Value, throw bool) bool {
+	if s, ok := name.(*valueSymbol); ok {
+		return o.deleteSym(s, throw)
+	}
 	if idx := toIdx(name); idx >= 0 && idx < int64(o.value.Len()) {
 		o.value.Index(int(idx)).Set(reflect.Zero(o.value.Type().Elem()))
 		return true
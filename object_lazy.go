@@ -42,6 +42,12 @@ func (o *lazyObject) getOwnProp(name string) Value {
 	o.val.self = obj
 	return obj.getOwnProp(name)
 }
+// 获取symbol属性s
+func (o *lazyObject) getOwnPropSym(s *valueSymbol) Value {
+	obj := o.create(o.val)
+	o.val.self = obj
+	return obj.getOwnPropSym(s)
+}
 // 保存n位置值val
 func (o *lazyObject) put(n Value, val Value, throw bool) {
 	obj := o.create(o.val)
@@ -84,6 +90,12 @@ func (o *lazyObject) _putProp(name string, value Value, writable, enumerable, co
 	o.val.self = obj
 	return obj._putProp(name, value, writable, enumerable, configurable)
 }
+// 保存symbol属性s的值为value
+func (o *lazyObject) _putSym(s *valueSymbol, value Value, writable, enumerable, configurable bool) Value {
+	obj := o.create(o.val)
+	o.val.self = obj
+	return obj._putSym(s, value, writable, enumerable, configurable)
+}
 // 保存name位置值为descr.value
 func (o *lazyObject) defineOwnProperty(name Value, descr propertyDescr, throw bool) bool {
 	obj := o.create(o.val)
@@ -133,6 +145,12 @@ func (o *lazyObject) proto() *Object {
 	return obj.proto()
 }
 
+func (o *lazyObject) setProto(proto *Object, throw bool) bool {
+	obj := o.create(o.val)
+	o.val.self = obj
+	return obj.setProto(proto, throw)
+}
+
 func (o *lazyObject) hasInstance(v Value) bool {
 	obj := o.create(o.val)
 	o.val.self = obj
@@ -145,10 +163,10 @@ func (o *lazyObject) isExtensible() bool {
 	return obj.isExtensible()
 }
 
-func (o *lazyObject) preventExtensions() {
+func (o *lazyObject) preventExtensions(throw bool) bool {
 	obj := o.create(o.val)
 	o.val.self = obj
-	obj.preventExtensions()
+	return obj.preventExtensions(throw)
 }
 // 构造枚举迭代
 func (o *lazyObject) enumerate(all, recusrive bool) iterNextFunc {
@@ -162,6 +180,12 @@ func (o *lazyObject) _enumerate(recursive bool) iterNextFunc {
 	o.val.self = obj
 	return obj._enumerate(recursive)
 }
+// 返回所有的symbol属性
+func (o *lazyObject) ownSymbols() []Value {
+	obj := o.create(o.val)
+	o.val.self = obj
+	return obj.ownSymbols()
+}
 // 导出数据
 func (o *lazyObject) export() interface{} {
 	obj := o.create(o.val)
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"math/big"
+
 	"github.com/oracle3/goja/ast"
 	"github.com/oracle3/goja/file"
 	"github.com/oracle3/goja/token"
@@ -21,6 +23,9 @@ func (self *_parser) parsePrimaryExpression() ast.Expression {
 	idx := self.idx
 	switch self.token {
 	case token.IDENTIFIER:
+		if literal == "async" && self.isAsyncFunction() {
+			return self.parseFunction(false)
+		}
 		self.next()
 		if len(literal) > 1 {
 			tkn, strict := token.IsKeyword(literal)
@@ -81,6 +86,8 @@ func (self *_parser) parsePrimaryExpression() ast.Expression {
 		}
 	case token.SLASH, token.QUOTIENT_ASSIGN:
 		return self.parseRegExpLiteral()
+	case token.BACKTICK:
+		return self.parseTemplateLiteral(false)
 	case token.LEFT_BRACE:
 		return self.parseObjectLiteral()
 	case token.LEFT_BRACKET:
@@ -97,12 +104,112 @@ func (self *_parser) parsePrimaryExpression() ast.Expression {
 		}
 	case token.FUNCTION:
 		return self.parseFunction(false)
+	case token.CLASS:
+		return self.parseClass(false)
+	case token.IMPORT:
+		return self.parseImportCall()
+	case token.SUPER:
+		self.next()
+		switch self.token {
+		case token.LEFT_PARENTHESIS, token.PERIOD, token.LEFT_BRACKET:
+		default:
+			self.error(idx, "'super' keyword unexpected here")
+		}
+		return &ast.SuperExpression{
+			Idx: idx,
+		}
 	}
 
 	self.errorUnexpectedToken(self.token)
 	self.nextStatement()
 	return &ast.BadExpression{From: idx, To: self.idx}
 }
+// 解析动态导入import(specifier)
+func (self *_parser) parseImportCall() ast.Expression {
+	node := &ast.ImportCall{
+		Import: self.expect(token.IMPORT),
+	}
+	if self.token != token.LEFT_PARENTHESIS {
+		self.errorUnexpectedToken(self.token)
+		self.nextStatement()
+		return &ast.BadExpression{From: node.Import, To: self.idx}
+	}
+	self.next()
+	node.Argument = self.parseAssignmentExpression()
+	node.RightParenthesis = self.expect(token.RIGHT_PARENTHESIS)
+	return node
+}
+// 解析模板字符串，当前标记为开头的`，tagged时允许无效的转义
+func (self *_parser) parseTemplateLiteral(tagged bool) *ast.TemplateLiteral {
+	node := &ast.TemplateLiteral{
+		OpenQuote: self.idx,
+	}
+	allowIn := self.scope.allowIn
+	self.scope.allowIn = true
+	defer func() {
+		self.scope.allowIn = allowIn
+	}()
+	for {
+		start := self.chrOffset
+		literal, finished, err := self.scanTemplateCharacters()
+		if err != nil {
+			self.error(node.OpenQuote, err.Error())
+			node.CloseQuote = self.idxOf(self.chrOffset)
+			break
+		}
+		parsed, err := parseStringLiteral(literal)
+		if err != nil && !tagged {
+			self.error(self.idxOf(start), err.Error())
+		}
+		node.Elements = append(node.Elements, &ast.TemplateElement{
+			Idx:     self.idxOf(start),
+			Literal: literal,
+			Parsed:  parsed,
+			Valid:   err == nil,
+		})
+		if finished {
+			node.CloseQuote = self.idxOf(self.chrOffset - 1)
+			break
+		}
+		self.next()
+		node.Expressions = append(node.Expressions, self.parseExpression())
+		if self.token != token.RIGHT_BRACE {
+			self.errorUnexpectedToken(self.token)
+			break
+		}
+	}
+	// 模板之后的换行可以自动插入分号
+	self.insertSemicolon = true
+	self.next()
+	return node
+}
+// 跳过模板字符串，只用于向前查看标记，结束后位于结尾的`之后
+func (self *_parser) skipTemplateLiteral() {
+	for {
+		_, finished, err := self.scanTemplateCharacters()
+		if finished || err != nil {
+			return
+		}
+		depth := 0
+	substitution:
+		for {
+			self.next()
+			switch self.token {
+			case token.LEFT_BRACE:
+				depth++
+			case token.RIGHT_BRACE:
+				if depth == 0 {
+					break substitution
+				}
+				depth--
+			case token.BACKTICK:
+				self.skipTemplateLiteral()
+			case token.EOF:
+				return
+			}
+		}
+	}
+}
 // 解析/=语句
 func (self *_parser) parseRegExpLiteral() *ast.RegExpLiteral {
 
@@ -145,6 +252,28 @@ func (self *_parser) parseRegExpLiteral() *ast.RegExpLiteral {
 // 分析变量声明
 func (self *_parser) parseVariableDeclaration(declarationList *[]*ast.VariableExpression) ast.Expression {
 
+	if self.token == token.LEFT_BRACKET || self.token == token.LEFT_BRACE {
+		var names []*ast.Identifier
+		node := &ast.VariableExpression{
+			Idx: self.idx,
+		}
+		node.Pattern = self.parsePattern(true, &names)
+		// 模式中绑定的每个名称分别登记为变量
+		if declarationList != nil {
+			for _, name := range names {
+				*declarationList = append(*declarationList, &ast.VariableExpression{
+					Name: name.Name,
+					Idx:  name.Idx,
+				})
+			}
+		}
+		if self.token == token.ASSIGN {
+			self.next()
+			node.Initializer = self.parseAssignmentExpression()
+		}
+		return node
+	}
+
 	if self.token != token.IDENTIFIER {
 		idx := self.expect(token.IDENTIFIER)
 		self.nextStatement()
@@ -170,6 +299,149 @@ func (self *_parser) parseVariableDeclaration(declarationList *[]*ast.VariableEx
 
 	return node
 }
+// 解构声明必须有初始值，for-in/of语句头中的声明除外
+func (self *_parser) checkPatternInitializer(node ast.Expression) {
+	if node, ok := node.(*ast.VariableExpression); ok && node.Pattern != nil && node.Initializer == nil {
+		self.error(node.Idx, "Missing initializer in destructuring declaration")
+	}
+}
+// 解析解构模式。binding为true时解析声明、参数或catch中的模式，目标只能是标识符，
+// 绑定的名称依次追加到names中；否则解析赋值中的模式，目标可以是成员表达式
+func (self *_parser) parsePattern(binding bool, names *[]*ast.Identifier) ast.Expression {
+	if self.token == token.LEFT_BRACKET {
+		return self.parseArrayPattern(binding, names)
+	}
+	return self.parseObjectPattern(binding, names)
+}
+// 解析数组解构模式
+func (self *_parser) parseArrayPattern(binding bool, names *[]*ast.Identifier) ast.Expression {
+	node := &ast.ArrayPattern{
+		LeftBracket: self.expect(token.LEFT_BRACKET),
+	}
+	for self.token != token.RIGHT_BRACKET && self.token != token.EOF {
+		if self.token == token.COMMA {
+			self.next()
+			node.Elements = append(node.Elements, nil)
+			continue
+		}
+		if self.token == token.ELLIPSIS {
+			// 剩余元素必须是最后一项
+			self.next()
+			node.Rest = self.parsePatternTarget(binding, names)
+			break
+		}
+		node.Elements = append(node.Elements, self.parsePatternElement(binding, names))
+		if self.token != token.RIGHT_BRACKET {
+			self.expect(token.COMMA)
+		}
+	}
+	node.RightBracket = self.expect(token.RIGHT_BRACKET)
+	return node
+}
+// 解析对象解构模式
+func (self *_parser) parseObjectPattern(binding bool, names *[]*ast.Identifier) ast.Expression {
+	node := &ast.ObjectPattern{
+		LeftBrace: self.expect(token.LEFT_BRACE),
+	}
+	for self.token != token.RIGHT_BRACE && self.token != token.EOF {
+		idx, tkn := self.idx, self.token
+		computed, literal, key := self.parsePropertyName()
+		prop := &ast.PatternProperty{
+			Key:      key,
+			Computed: computed,
+		}
+		if self.token == token.COLON {
+			self.next()
+			prop.Value = self.parsePatternElement(binding, names)
+		} else {
+			// {a}和{a = 1}是{a: a}和{a: a = 1}的简写
+			if tkn != token.IDENTIFIER {
+				self.expect(token.COLON)
+				self.nextStatement()
+				return &ast.BadExpression{From: idx, To: self.idx}
+			}
+			id := &ast.Identifier{
+				Name: literal,
+				Idx:  idx,
+			}
+			if binding && names != nil {
+				*names = append(*names, id)
+			}
+			prop.Value = &ast.Binding{
+				Target: id,
+			}
+			if self.token == token.ASSIGN {
+				self.next()
+				prop.Value.Initializer = self.parseAssignmentExpression()
+			}
+		}
+		node.Properties = append(node.Properties, prop)
+		if self.token != token.RIGHT_BRACE {
+			self.expect(token.COMMA)
+		}
+	}
+	node.RightBrace = self.expect(token.RIGHT_BRACE)
+	return node
+}
+// 解析模式中的一项：目标和可选的默认值
+func (self *_parser) parsePatternElement(binding bool, names *[]*ast.Identifier) *ast.Binding {
+	node := &ast.Binding{
+		Target: self.parsePatternTarget(binding, names),
+	}
+	if self.token == token.ASSIGN {
+		self.next()
+		node.Initializer = self.parseAssignmentExpression()
+	}
+	return node
+}
+// 解析模式中的目标：标识符、嵌套的模式，或者赋值中的成员表达式
+func (self *_parser) parsePatternTarget(binding bool, names *[]*ast.Identifier) ast.Expression {
+	if self.token == token.LEFT_BRACKET || self.token == token.LEFT_BRACE {
+		if binding || self.isNestedPattern() {
+			return self.parsePattern(binding, names)
+		}
+	}
+	if binding {
+		if self.token != token.IDENTIFIER {
+			idx := self.expect(token.IDENTIFIER)
+			self.nextStatement()
+			return &ast.BadExpression{From: idx, To: self.idx}
+		}
+		id := self.parseIdentifier()
+		if names != nil {
+			*names = append(*names, id)
+		}
+		return id
+	}
+	target := self.parseLeftHandSideExpressionAllowCall()
+	switch target.(type) {
+	case *ast.Identifier, *ast.DotExpression, *ast.BracketExpression:
+	default:
+		self.error(target.Idx0(), "Invalid destructuring assignment target")
+	}
+	return target
+}
+// 判断赋值模式中的[或{是否为嵌套的模式，而不是以字面量开始的成员表达式
+func (self *_parser) isNestedPattern() bool {
+	state := self.mark()
+	defer self.restore(&state)
+	self.skipBracketed()
+	switch self.token {
+	case token.COMMA, token.RIGHT_BRACKET, token.RIGHT_BRACE, token.ASSIGN:
+		return true
+	}
+	return false
+}
+// 判断当前的[或{是否为解构赋值的模式，即匹配的括号之后是=，在for语句头中还可以是in或of
+func (self *_parser) isAssignmentPattern() bool {
+	state := self.mark()
+	defer self.restore(&state)
+	self.skipBracketed()
+	if self.token == token.ASSIGN {
+		return true
+	}
+	return !self.scope.allowIn && (self.token == token.IN || self.isOf())
+}
 // 分析变量声明列表
 func (self *_parser) parseVariableDeclarationList(var_ file.Idx) []ast.Expression {
 
@@ -200,10 +472,11 @@ func (self *_parser) parseObjectPropertyKey() (string, string) {
 	case token.IDENTIFIER:
 		value = literal
 	case token.NUMBER:
-		var err error
-		_, err = parseNumberLiteral(literal)
+		num, err := parseNumberLiteral(literal)
 		if err != nil {
 			self.error(idx, err.Error())
+		} else if b, ok := num.(*big.Int); ok {
+			value = b.String()
 		} else {
 			value = literal
 		}
@@ -221,56 +494,112 @@ func (self *_parser) parseObjectPropertyKey() (string, string) {
 	}
 	return literal, value
 }
-// 分析对象属性
-func (self *_parser) parseObjectProperty() ast.Property {
-
+// 分析对象属性名，[expr]形式的计算属性名返回其表达式
+func (self *_parser) parsePropertyName() (ast.Expression, string, string) {
+	if self.token == token.LEFT_BRACKET {
+		self.next()
+		key := self.parseAssignmentExpression()
+		self.expect(token.RIGHT_BRACKET)
+		return key, "", ""
+	}
 	literal, value := self.parseObjectPropertyKey()
-	if literal == "get" && self.token != token.COLON {
-		idx := self.idx
-		_, value := self.parseObjectPropertyKey()
-		parameterList := self.parseFunctionParameterList()
-
-		node := &ast.FunctionLiteral{
-			Function:      idx,
-			ParameterList: parameterList,
+	if value == "" && literal == "" {
+		self.errorUnexpectedToken(self.token)
+	}
+	return nil, literal, value
+}
+// 分析对象属性，包括简写属性、方法和getter/setter
+func (self *_parser) parseObjectProperty() ast.Property {
+	kind := "value"
+	if self.token == token.IDENTIFIER && (self.literal == "get" || self.literal == "set") {
+		switch self.peek() {
+		case token.COLON, token.LEFT_PARENTHESIS, token.COMMA, token.RIGHT_BRACE:
+		default:
+			kind = self.literal
+			self.next()
 		}
-		self.parseFunctionBlock(node)
-		return ast.Property{
-			Key:   value,
-			Kind:  "get",
-			Value: node,
+	}
+	async := false
+	if kind == "value" && self.token == token.IDENTIFIER && self.literal == "async" && self.isAsyncMethod() {
+		switch self.peek() {
+		case token.COLON, token.COMMA, token.RIGHT_BRACE:
+		default:
+			self.next()
+			async = true
+			kind = "method"
 		}
-	} else if literal == "set" && self.token != token.COLON {
-		idx := self.idx
-		_, value := self.parseObjectPropertyKey()
-		parameterList := self.parseFunctionParameterList()
+	}
+	generator := false
+	if kind == "value" || async {
+		if self.token == token.MULTIPLY {
+			if async {
+				self.error(self.idx, "Async generator functions are not supported")
+			}
+			self.next()
+			generator = true
+			kind = "method"
+		}
+	}
 
-		node := &ast.FunctionLiteral{
-			Function:      idx,
-			ParameterList: parameterList,
+	idx, tkn := self.idx, self.token
+	computed, literal, value := self.parsePropertyName()
+	if kind == "value" {
+		switch self.token {
+		case token.LEFT_PARENTHESIS:
+			kind = "method"
+		case token.COMMA, token.RIGHT_BRACE:
+			if tkn == token.IDENTIFIER {
+				return ast.Property{
+					Key:       value,
+					Kind:      "value",
+					Shorthand: true,
+					Value: &ast.Identifier{
+						Name: literal,
+						Idx:  idx,
+					},
+				}
+			}
 		}
-		self.parseFunctionBlock(node)
+	}
+	if kind == "value" {
+		self.expect(token.COLON)
 		return ast.Property{
-			Key:   value,
-			Kind:  "set",
-			Value: node,
+			Key:      value,
+			Computed: computed,
+			Kind:     "value",
+			Value:    self.parseAssignmentExpression(),
 		}
 	}
 
-	self.expect(token.COLON)
-
+	node := &ast.FunctionLiteral{
+		Function:      idx,
+		ParameterList: self.parseFunctionParameterList(),
+		Generator:     generator,
+		Async:         async,
+	}
+	self.parseFunctionBlock(node)
+	node.Source = self.slice(node.Idx0(), node.Idx1())
 	return ast.Property{
-		Key:   value,
-		Kind:  "value",
-		Value: self.parseAssignmentExpression(),
+		Key:      value,
+		Computed: computed,
+		Kind:     kind,
+		Value:    node,
 	}
 }
 // 分析对象文本
 func (self *_parser) parseObjectLiteral() ast.Expression {
 	var value []ast.Property
 	idx0 := self.expect(token.LEFT_BRACE)
+	hasProto := false
 	for self.token != token.RIGHT_BRACE && self.token != token.EOF {
+		idx := self.idx
 		property := self.parseObjectProperty()
+		if property.Key == "__proto__" && property.Kind == "value" && property.Computed == nil && !property.Shorthand {
+			if hasProto {
+				self.error(idx, "Duplicate __proto__ fields are not allowed in object literals")
+			}
+			hasProto = true
+		}
 		value = append(value, property)
 		if self.token != token.RIGHT_BRACE {
 			self.expect(token.COMMA)
@@ -297,7 +626,11 @@ func (self *_parser) parseArrayLiteral() ast.Expression {
 			value = append(value, nil)
 			continue
 		}
-		value = append(value, self.parseAssignmentExpression())
+		if self.token == token.ELLIPSIS {
+			value = append(value, self.parseSpreadElement())
+		} else {
+			value = append(value, self.parseAssignmentExpression())
+		}
 		if self.token != token.RIGHT_BRACKET {
 			self.expect(token.COMMA)
 		}
@@ -315,7 +648,11 @@ func (self *_parser) parseArgumentList() (argumentList []ast.Expression, idx0, i
 	idx0 = self.expect(token.LEFT_PARENTHESIS)
 	if self.token != token.RIGHT_PARENTHESIS {
 		for {
-			argumentList = append(argumentList, self.parseAssignmentExpression())
+			if self.token == token.ELLIPSIS {
+				argumentList = append(argumentList, self.parseSpreadElement())
+			} else {
+				argumentList = append(argumentList, self.parseAssignmentExpression())
+			}
 			if self.token != token.COMMA {
 				break
 			}
@@ -325,6 +662,14 @@ func (self *_parser) parseArgumentList() (argumentList []ast.Expression, idx0, i
 	idx1 = self.expect(token.RIGHT_PARENTHESIS)
 	return
 }
+// 解析...expr
+func (self *_parser) parseSpreadElement() ast.Expression {
+	idx := self.expect(token.ELLIPSIS)
+	return &ast.SpreadElement{
+		Ellipsis:   idx,
+		Expression: self.parseAssignmentExpression(),
+	}
+}
 // 解析调用表达式
 func (self *_parser) parseCallExpression(left ast.Expression) ast.Expression {
 	argumentList, idx0, idx1 := self.parseArgumentList()
@@ -370,6 +715,13 @@ func (self *_parser) parseBracketMember(left ast.Expression) ast.Expression {
 		RightBracket: idx1,
 	}
 }
+// 解析tag`...`
+func (self *_parser) parseTaggedTemplate(tag ast.Expression) ast.Expression {
+	return &ast.TaggedTemplate{
+		Tag:      tag,
+		Template: self.parseTemplateLiteral(true),
+	}
+}
 // 解析new表达式
 func (self *_parser) parseNewExpression() ast.Expression {
 	idx := self.expect(token.NEW)
@@ -401,6 +753,8 @@ func (self *_parser) parseLeftHandSideExpression() ast.Expression {
 			left = self.parseDotMember(left)
 		} else if self.token == token.LEFT_BRACKET {
 			left = self.parseBracketMember(left)
+		} else if self.token == token.BACKTICK {
+			left = self.parseTaggedTemplate(left)
 		} else {
 			break
 		}
@@ -431,6 +785,8 @@ func (self *_parser) parseLeftHandSideExpressionAllowCall() ast.Expression {
 			left = self.parseBracketMember(left)
 		} else if self.token == token.LEFT_PARENTHESIS {
 			left = self.parseCallExpression(left)
+		} else if self.token == token.BACKTICK {
+			left = self.parseTaggedTemplate(left)
 		} else {
 			break
 		}
@@ -472,6 +828,15 @@ func (self *_parser) parsePostfixExpression() ast.Expression {
 func (self *_parser) parseUnaryExpression() ast.Expression {
 
 	switch self.token {
+	case token.IDENTIFIER:
+		if self.literal == "await" && self.scope.inAsync {
+			idx := self.idx
+			self.next()
+			return &ast.AwaitExpression{
+				Await:    idx,
+				Argument: self.parseUnaryExpression(),
+			}
+		}
 	case token.PLUS, token.MINUS, token.NOT, token.BITWISE_NOT:
 		fallthrough
 	case token.DELETE, token.VOID, token.TYPEOF:
@@ -722,8 +1087,59 @@ func (self *_parser) parseConditionlExpression() ast.Expression {
 
 	return left
 }
+// 解析生成器函数中的yield表达式
+func (self *_parser) parseYieldExpression() ast.Expression {
+	node := &ast.YieldExpression{
+		Yield: self.idx,
+	}
+	self.next()
+	if self.implicitSemicolon {
+		return node
+	}
+	switch self.token {
+	case token.MULTIPLY:
+		self.next()
+		node.Delegate = true
+		node.Argument = self.parseAssignmentExpression()
+	case token.SEMICOLON, token.RIGHT_PARENTHESIS, token.RIGHT_BRACKET, token.RIGHT_BRACE,
+		token.COMMA, token.COLON, token.IN, token.EOF:
+	default:
+		node.Argument = self.parseAssignmentExpression()
+	}
+	return node
+}
 // 解析赋值表达式
 func (self *_parser) parseAssignmentExpression() ast.Expression {
+	switch self.token {
+	case token.IDENTIFIER:
+		if self.literal == "yield" && self.scope.inGenerator {
+			return self.parseYieldExpression()
+		}
+		if self.literal == "async" && self.isAsyncArrow() {
+			return self.parseArrowFunction(true)
+		}
+		if self.isArrowIdentifier() {
+			return self.parseArrowFunction(false)
+		}
+	case token.LEFT_PARENTHESIS:
+		if self.isArrowParameterList() {
+			return self.parseArrowFunction(false)
+		}
+	case token.LEFT_BRACKET, token.LEFT_BRACE:
+		if self.isAssignmentPattern() {
+			pattern := self.parsePattern(false, nil)
+			if self.token != token.ASSIGN {
+				// for-in/of语句头中的模式
+				return pattern
+			}
+			self.next()
+			return &ast.AssignExpression{
+				Left:     pattern,
+				Operator: token.ASSIGN,
+				Right:    self.parseAssignmentExpression(),
+			}
+		}
+	}
 	left := self.parseConditionlExpression()
 	var operator token.Token
 	switch self.token {
@@ -774,6 +1190,96 @@ func (self *_parser) parseAssignmentExpression() ast.Expression {
 
 	return left
 }
+// 判断当前的标识符是否为箭头函数的参数，参数和=>之间不能换行
+func (self *_parser) isArrowIdentifier() bool {
+	state := self.mark()
+	defer self.restore(&state)
+	self.next()
+	return self.token == token.ARROW && !self.implicitSemicolon
+}
+// 判断当前的括号是否为箭头函数的参数列表，即匹配的右括号之后是=>，两者之间不能换行
+func (self *_parser) isArrowParameterList() bool {
+	state := self.mark()
+	defer self.restore(&state)
+	self.skipBracketed()
+	return self.token == token.ARROW && !self.implicitSemicolon
+}
+// 跳过当前的括号及其中的内容，停在匹配的右括号之后
+func (self *_parser) skipBracketed() {
+	depth := 0
+	for {
+		switch self.token {
+		case token.LEFT_PARENTHESIS, token.LEFT_BRACKET, token.LEFT_BRACE:
+			depth++
+		case token.RIGHT_PARENTHESIS, token.RIGHT_BRACKET, token.RIGHT_BRACE:
+			depth--
+		case token.BACKTICK:
+			self.skipTemplateLiteral()
+		case token.EOF:
+			return
+		}
+		self.next()
+		if depth == 0 {
+			return
+		}
+	}
+}
+// 当前的async是否为async箭头函数的开始，async和参数之间不能换行
+func (self *_parser) isAsyncArrow() bool {
+	state := self.mark()
+	defer self.restore(&state)
+	self.next()
+	if self.implicitSemicolon {
+		return false
+	}
+	switch self.token {
+	case token.IDENTIFIER:
+		self.next()
+		return self.token == token.ARROW && !self.implicitSemicolon
+	case token.LEFT_PARENTHESIS:
+		return self.isArrowParameterList()
+	}
+	return false
+}
+// 解析箭头函数
+func (self *_parser) parseArrowFunction(async bool) ast.Expression {
+	node := &ast.ArrowFunctionLiteral{
+		Start: self.idx,
+		Async: async,
+	}
+	if async {
+		self.next()
+	}
+	if self.token == token.IDENTIFIER {
+		param := self.parseIdentifier()
+		node.ParameterList = &ast.ParameterList{
+			Opening: param.Idx0(),
+			List:    []*ast.Binding{{Target: param}},
+			Closing: param.Idx1(),
+		}
+	} else {
+		node.ParameterList = self.parseFunctionParameterList()
+	}
+	self.expect(token.ARROW)
+
+	self.openScope()
+	inFunction := self.scope.inFunction
+	self.scope.inFunction = true
+	self.scope.inAsync = async
+	if self.token == token.LEFT_BRACE {
+		node.Body = self.parseBlockStatement()
+	} else {
+		node.Body = &ast.ExpressionBody{
+			Expression: self.parseAssignmentExpression(),
+		}
+	}
+	node.DeclarationList = self.scope.declarationList
+	self.scope.inFunction = inFunction
+	self.closeScope()
+
+	node.Source = self.slice(node.Idx0(), node.Idx1())
+	return node
+}
 // 解析表达式
 func (self *_parser) parseExpression() ast.Expression {
 	next := self.parseAssignmentExpression
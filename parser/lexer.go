@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"math/big"
 	"regexp"
 	"strconv"
 	"strings"
@@ -204,6 +205,10 @@ func (self *_parser) scan() (tkn token.Token, literal string, idx file.Idx) {
 				if digitValue(self.chr) < 10 {
 					insertSemicolon = true
 					tkn, literal = self.scanNumericLiteral(true)
+				} else if self.chr == '.' && self.offset < self.length && self.str[self.offset] == '.' {
+					self.read()
+					self.read()
+					tkn = token.ELLIPSIS
 				} else {
 					tkn = token.PERIOD
 				}
@@ -259,10 +264,15 @@ func (self *_parser) scan() (tkn token.Token, literal string, idx file.Idx) {
 			case '>':
 				tkn = self.switch6(token.GREATER, token.GREATER_OR_EQUAL, '>', token.SHIFT_RIGHT, token.SHIFT_RIGHT_ASSIGN, '>', token.UNSIGNED_SHIFT_RIGHT, token.UNSIGNED_SHIFT_RIGHT_ASSIGN)
 			case '=':
-				tkn = self.switch2(token.ASSIGN, token.EQUAL)
-				if tkn == token.EQUAL && self.chr == '=' {
+				if self.chr == '>' {
 					self.read()
-					tkn = token.STRICT_EQUAL
+					tkn = token.ARROW
+				} else {
+					tkn = self.switch2(token.ASSIGN, token.EQUAL)
+					if tkn == token.EQUAL && self.chr == '=' {
+						self.read()
+						tkn = token.STRICT_EQUAL
+					}
 				}
 			case '!':
 				tkn = self.switch2(token.NOT, token.NOT_EQUAL)
@@ -283,6 +293,9 @@ func (self *_parser) scan() (tkn token.Token, literal string, idx file.Idx) {
 				tkn = token.BITWISE_NOT
 			case '?':
 				tkn = token.QUESTION_MARK
+			case '`':
+				// 模板内容由parseTemplateLiteral直接扫描
+				tkn = token.BACKTICK
 			case '"', '\'':
 				insertSemicolon = true
 				tkn = token.STRING
@@ -558,6 +571,40 @@ newline:
 	}
 	return "", errors.New(err)
 }
+// 扫描模板字符串中的一段原始文本，直到结尾的`或者${为止，finished表示遇到了结尾的`
+func (self *_parser) scanTemplateCharacters() (literal string, finished bool, err error) {
+	offset := self.chrOffset
+	for {
+		switch self.chr {
+		case '`':
+			literal = self.str[offset:self.chrOffset]
+			self.read()
+			return normalizeTemplateNewlines(literal), true, nil
+		case '$':
+			if self.offset < self.length && self.str[self.offset] == '{' {
+				literal = self.str[offset:self.chrOffset]
+				self.read()
+				self.read()
+				return normalizeTemplateNewlines(literal), false, nil
+			}
+		case '\\':
+			self.read()
+			if self.chr == -1 {
+				continue
+			}
+		case -1:
+			return "", true, errors.New("Unterminated template literal")
+		}
+		self.read()
+	}
+}
+// 模板中的\r\n和\r都按\n处理
+func normalizeTemplateNewlines(literal string) string {
+	if !strings.ContainsRune(literal, '\r') {
+		return literal
+	}
+	return strings.Replace(strings.Replace(literal, "\r\n", "\n", -1), "\r", "\n", -1)
+}
 // 扫描换行
 func (self *_parser) scanNewline() {
 	if self.chr == '\r' {
@@ -585,6 +632,13 @@ func hex2decimal(chr byte) (value rune, ok bool) {
 }
 // 解析数字文本
 func parseNumberLiteral(literal string) (value interface{}, err error) {
+	if strings.HasSuffix(literal, "n") {
+		// BigInt，值为*big.Int
+		if b, ok := new(big.Int).SetString(literal[:len(literal)-1], 0); ok {
+			return b, nil
+		}
+		return nil, errors.New("Illegal numeric literal")
+	}
 	// TODO Is Uint okay? What about -MAX_UINT
 	value, err = strconv.ParseInt(literal, 0, 64)
 	if err == nil {
@@ -834,6 +888,18 @@ exponent:
 // 十六进制，八进制读取
 hexadecimal:
 octal:
+	// BigInt文本：整数后面跟n，不能是小数、指数或旧式八进制
+	if self.chr == 'n' {
+		literal := self.str[offset:self.chrOffset]
+		if literal == "0" || strings.HasPrefix(literal, "0x") || strings.HasPrefix(literal, "0X") ||
+			literal[0] != '0' && literal[0] != '.' && !strings.ContainsAny(literal, ".eE") {
+			self.read()
+			if isIdentifierStart(self.chr) || isDecimalDigit(self.chr) {
+				return token.ILLEGAL, self.str[offset:self.chrOffset]
+			}
+			return tkn, self.str[offset:self.chrOffset]
+		}
+	}
 	if isIdentifierStart(self.chr) || isDecimalDigit(self.chr) {
 		return token.ILLEGAL, self.str[offset:self.chrOffset]
 	}
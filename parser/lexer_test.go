@@ -51,6 +51,16 @@ func TestLexer(t *testing.T) {
 			token.EOF, "", 3,
 		)
 
+		test("123n",
+			token.NUMBER, "123n", 1,
+			token.EOF, "", 5,
+		)
+
+		test("0x1fn",
+			token.NUMBER, "0x1fn", 1,
+			token.EOF, "", 6,
+		)
+
 		test("abc",
 			token.IDENTIFIER, "abc", 1,
 			token.EOF, "", 4,
@@ -202,7 +212,7 @@ Second line \
 			token.VAR, "var", 1,
 			token.IF, "if", 5,
 			token.VAR, "var", 8,
-			token.KEYWORD, "class", 12,
+			token.CLASS, "class", 12,
 			token.EOF, "", 17,
 		)
 
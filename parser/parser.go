@@ -50,6 +50,7 @@ type Mode uint
 
 const (
 	IgnoreRegExpErrors Mode = 1 << iota // Ignore RegExp compatibility errors (allow backtracking) 忽略RegExp兼容性错误（允许回溯）
+	Module                              // Parse the source as an ES module (strict, allows import/export) 作为ES模块解析（严格模式，允许import/export）
 )
 
 type _parser struct {
@@ -207,6 +208,51 @@ func (self *_parser) parse() (*ast.Program, error) {
 func (self *_parser) next() {
 	self.token, self.literal, self.idx = self.scan()
 }
+
+// 解析器的扫描状态，用于向前查看标记后回退
+type _parserState struct {
+	tok                                token.Token
+	literal                            string
+	idx                                file.Idx
+	chr                                rune
+	chrOffset, offset                  int
+	insertSemicolon, implicitSemicolon bool
+	errorCount                         int
+}
+// 记录当前的扫描状态
+func (self *_parser) mark() _parserState {
+	return _parserState{
+		tok:               self.token,
+		literal:           self.literal,
+		idx:               self.idx,
+		chr:               self.chr,
+		chrOffset:         self.chrOffset,
+		offset:            self.offset,
+		insertSemicolon:   self.insertSemicolon,
+		implicitSemicolon: self.implicitSemicolon,
+		errorCount:        len(self.errors),
+	}
+}
+// 回退到记录的扫描状态
+func (self *_parser) restore(state *_parserState) {
+	self.token = state.tok
+	self.literal = state.literal
+	self.idx = state.idx
+	self.chr = state.chr
+	self.chrOffset = state.chrOffset
+	self.offset = state.offset
+	self.insertSemicolon = state.insertSemicolon
+	self.implicitSemicolon = state.implicitSemicolon
+	self.errors = self.errors[:state.errorCount]
+}
+// 查看下一个标记，不移动当前位置
+func (self *_parser) peek() token.Token {
+	state := self.mark()
+	self.next()
+	tok := self.token
+	self.restore(&state)
+	return tok
+}
 // 可选的分号解析
 func (self *_parser) optionalSemicolon() {
 	if self.token == token.SEMICOLON {
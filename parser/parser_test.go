@@ -2,12 +2,14 @@ package parser
 
 import (
 	"errors"
+	"math/big"
 	"regexp"
 	"strings"
 	"testing"
 
 	"github.com/oracle3/goja/ast"
 	"github.com/oracle3/goja/file"
+	"github.com/oracle3/goja/token"
 )
 
 // 获取第一个错误信息
@@ -54,6 +56,51 @@ func TestParseFile(t *testing.T) {
 		is(err, "(anonymous): Line 1:15 Illegal return statement")
 	})
 }
+// 模块测试
+func TestParseModule(t *testing.T) {
+	tt(t, func() {
+		test := func(src string, expect interface{}) *ast.Program {
+			program, err := ParseFile(nil, "", src, Module)
+			is(firstErr(err), expect)
+			return program
+		}
+
+		program := test(`import d, {a as b, if as c} from "m"; import * as ns from "n"; import "o"`, nil)
+		{
+			decl := program.Body[0].(*ast.ImportDeclaration)
+			is(decl.Default.Name, "d")
+			is(len(decl.Specifiers), 2)
+			is(decl.Specifiers[0].ImportName, "a")
+			is(decl.Specifiers[0].LocalName, "b")
+			is(decl.Specifiers[1].ImportName, "if")
+			is(decl.ModuleSpecifier, "m")
+			is(program.Body[1].(*ast.ImportDeclaration).Namespace.Name, "ns")
+			is(program.Body[2].(*ast.ImportDeclaration).ModuleSpecifier, "o")
+		}
+
+		program = test(`export default function () {}; export let x = 1; export {x as if}; export * as all from "m"`, nil)
+		{
+			decl := program.Body[0].(*ast.ExportDeclaration)
+			is(decl.Default, true)
+			is(decl.Function.Name, nil)
+			is(len(program.DeclarationList), 1)
+			is(program.Body[2].(*ast.ExportDeclaration).Declaration.(*ast.LexicalDeclaration).List[0].Name, "x")
+			is(program.Body[3].(*ast.ExportDeclaration).Specifiers[0].ExportName, "if")
+			is(program.Body[4].(*ast.ExportDeclaration).Namespace.Name, "all")
+		}
+
+		test(`export {if}`, "(anonymous): Line 1:9 Unexpected reserved word")
+		test(`import {if} from "m"`, "(anonymous): Line 1:9 Unexpected reserved word")
+		test(`import a from b`, "(anonymous): Line 1:15 Unexpected identifier")
+		test(`{ export var a }`, "(anonymous): Line 1:3 Unexpected token export")
+
+		_, err := ParseFile(nil, "", `import a from "m"`, 0)
+		is(err, "(anonymous): Line 1:1 Cannot use import statement outside a module")
+
+		_, err = ParseFile(nil, "", `import("m").then()`, 0)
+		is(err, nil)
+	})
+}
 // 函数测试
 func TestParseFunction(t *testing.T) {
 	tt(t, func() {
@@ -229,7 +276,7 @@ func TestParserErr(t *testing.T) {
 
 		test("a if", "(anonymous): Line 1:3 Unexpected token if")
 
-		test("a class", "(anonymous): Line 1:3 Unexpected reserved word")
+		test("a class", "(anonymous): Line 1:3 Unexpected token class")
 
 		test("break\n", "(anonymous): Line 1:1 Illegal break statement")
 
@@ -277,12 +324,75 @@ func TestParserErr(t *testing.T) {
 
 		test("\u203f = 1", "(anonymous): Line 1:1 Unexpected token ILLEGAL")
 
-		// TODO
-		// const x = 12, y;
-		// const x, y = 12;
-		// const x;
-		// if(true) let a = 1;
-		// if(true) const  a = 1;
+		test("const x = 12, y;", "(anonymous): Line 1:15 Missing initializer in const declaration")
+
+		test("const x, y = 12;", "(anonymous): Line 1:7 Missing initializer in const declaration")
+
+		test("const x;", "(anonymous): Line 1:7 Missing initializer in const declaration")
+
+		test("if(true) let a = 1;", "(anonymous): Line 1:14 Unexpected identifier")
+
+		test("(a, 1) => a", "(anonymous): Line 1:5 Unexpected number")
+
+		test("() => ", "(anonymous): Line 1:7 Unexpected end of input")
+
+		test("a\n=> a", "(anonymous): Line 2:1 Unexpected token =>")
+
+		test("(a, b)\n=> a", "(anonymous): Line 2:1 Unexpected token =>")
+
+		test("class A { constructor() {} constructor() {} }", "(anonymous): Line 1:28 A class may only have one constructor")
+
+		test("class A { get constructor() {} }", "(anonymous): Line 1:15 Class constructor may not be an accessor")
+
+		test("class A { static prototype() {} }", "(anonymous): Line 1:18 Classes may not have a static property named 'prototype'")
+
+		test("if (1) class A {}", "(anonymous): Line 1:8 Lexical declaration cannot appear in a single-statement context")
+
+		test("class {}", "(anonymous): Line 1:7 Unexpected token {")
+
+		test("if(true) const  a = 1;", "(anonymous): Line 1:10 Lexical declaration cannot appear in a single-statement context")
+
+		test("for (const x; x < 1;) {}", "(anonymous): Line 1:12 Missing initializer in const declaration")
+
+		test("for (1 of []) {}", "(anonymous): Line 1:1 Invalid left-hand side in for-of")
+
+		test("[...]", "(anonymous): Line 1:5 Unexpected token ]")
+
+		test("x...y", "(anonymous): Line 1:2 Unexpected token ...")
+
+		test("function* g() { yield\n* 1 }", "(anonymous): Line 2:1 Unexpected token *")
+
+		test("class A { *constructor() {} }", "(anonymous): Line 1:12 Class constructor may not be a generator")
+
+		test("class A { async constructor() {} }", "(anonymous): Line 1:17 Class constructor may not be an async method")
+
+		test("async function* g() {}", "(anonymous): Line 1:15 Async generator functions are not supported")
+
+		test("function f() { await x; }", "(anonymous): Line 1:22 Unexpected identifier")
+
+		test("`abc", "(anonymous): Line 1:1 Unterminated template literal")
+
+		test("`${a`", "(anonymous): Line 1:5 Unterminated template literal")
+
+		test("`${a b}`", "(anonymous): Line 1:6 Unexpected identifier")
+
+		test("var [a];", "(anonymous): Line 1:5 Missing initializer in destructuring declaration")
+
+		test("const {a};", "(anonymous): Line 1:7 Missing initializer in destructuring declaration")
+
+		test("({a: 1} = {})", "(anonymous): Line 1:6 Invalid destructuring assignment target")
+
+		test("var {1} = {}", "(anonymous): Line 1:7 Unexpected token }")
+
+		test("var {[a]} = b;", "(anonymous): Line 1:9 Unexpected token }")
+
+		test("function f(...a, b) {}", "(anonymous): Line 1:16 Rest parameter must be last formal parameter")
+
+		test("({__proto__: 1, __proto__: 2})", "(anonymous): Line 1:17 Duplicate __proto__ fields are not allowed in object literals")
+
+		test("({if})", "(anonymous): Line 1:5 Unexpected token }")
+
+		test("({[a]})", "(anonymous): Line 1:6 Unexpected token }")
 
 		test(`new abc()."def"`, "(anonymous): Line 1:11 Unexpected string")
 
@@ -376,7 +486,7 @@ func TestParserErr(t *testing.T) {
 
 		test("/*/.source", "(anonymous): Line 1:11 Unexpected end of input")
 
-		test("var class", "(anonymous): Line 1:5 Unexpected reserved word")
+		test("var class", "(anonymous): Line 1:5 Unexpected token class")
 
 		test("var if", "(anonymous): Line 1:5 Unexpected token if")
 
@@ -410,33 +520,33 @@ func TestParserErr(t *testing.T) {
 
 		{ // Reserved words
 
-			test("class", "(anonymous): Line 1:1 Unexpected reserved word")
+			test("class", "(anonymous): Line 1:6 Unexpected end of input")
 			test("abc.class = 1", nil)
-			test("var class;", "(anonymous): Line 1:5 Unexpected reserved word")
+			test("var class;", "(anonymous): Line 1:5 Unexpected token class")
 
-			test("const", "(anonymous): Line 1:1 Unexpected reserved word")
+			test("const", "(anonymous): Line 1:6 Unexpected end of input")
 			test("abc.const = 1", nil)
-			test("var const;", "(anonymous): Line 1:5 Unexpected reserved word")
+			test("var const;", "(anonymous): Line 1:5 Unexpected token const")
 
 			test("enum", "(anonymous): Line 1:1 Unexpected reserved word")
 			test("abc.enum = 1", nil)
 			test("var enum;", "(anonymous): Line 1:5 Unexpected reserved word")
 
-			test("export", "(anonymous): Line 1:1 Unexpected reserved word")
+			test("export", "(anonymous): Line 1:1 Unexpected token export")
 			test("abc.export = 1", nil)
-			test("var export;", "(anonymous): Line 1:5 Unexpected reserved word")
+			test("var export;", "(anonymous): Line 1:5 Unexpected token export")
 
-			test("extends", "(anonymous): Line 1:1 Unexpected reserved word")
+			test("extends", "(anonymous): Line 1:1 Unexpected token extends")
 			test("abc.extends = 1", nil)
-			test("var extends;", "(anonymous): Line 1:5 Unexpected reserved word")
+			test("var extends;", "(anonymous): Line 1:5 Unexpected token extends")
 
-			test("import", "(anonymous): Line 1:1 Unexpected reserved word")
+			test("import", "(anonymous): Line 1:1 Cannot use import statement outside a module")
 			test("abc.import = 1", nil)
-			test("var import;", "(anonymous): Line 1:5 Unexpected reserved word")
+			test("var import;", "(anonymous): Line 1:5 Unexpected token import")
 
-			test("super", "(anonymous): Line 1:1 Unexpected reserved word")
+			test("super", "(anonymous): Line 1:1 'super' keyword unexpected here")
 			test("abc.super = 1", nil)
-			test("var super;", "(anonymous): Line 1:5 Unexpected reserved word")
+			test("var super;", "(anonymous): Line 1:5 Unexpected token super")
 			test(`
 			obj = {
 			  aaa: 1
@@ -849,6 +959,232 @@ func TestParser(t *testing.T) {
 
 		test("var abc = 1;\ufeff", nil)
 
+		program = test("let abc = 1, def; const ghi = 2;", nil)
+		is(len(program.Body), 2)
+		{
+			decl := program.Body[0].(*ast.LexicalDeclaration)
+			is(decl.Token, token.LET)
+			is(len(decl.List), 2)
+			is(decl.List[0].Name, "abc")
+			is(decl.List[1].Initializer, nil)
+			decl = program.Body[1].(*ast.LexicalDeclaration)
+			is(decl.Token, token.CONST)
+			is(decl.List[0].Name, "ghi")
+		}
+		is(len(program.DeclarationList), 0)
+
+		program = test("let = 1", nil)
+		is(program.Body[0].(*ast.ExpressionStatement).Expression.(*ast.AssignExpression).Left.(*ast.Identifier).Name, "let")
+
+		program = test("for (let i = 0; i < 1; i++) {}", nil)
+		is(program.Body[0].(*ast.ForStatement).Declaration.List[0].Name, "i")
+
+		program = test("for (const k in {}) {}", nil)
+		is(program.Body[0].(*ast.ForInStatement).Declaration.Token, token.CONST)
+
+		program = test("x => x * 2", nil)
+		{
+			arrow := program.Body[0].(*ast.ExpressionStatement).Expression.(*ast.ArrowFunctionLiteral)
+			is(len(arrow.ParameterList.List), 1)
+			is(arrow.ParameterList.List[0].Target.(*ast.Identifier).Name, "x")
+			is(arrow.Body.(*ast.ExpressionBody).Expression.(*ast.BinaryExpression).Operator, token.MULTIPLY)
+			is(arrow.Source, "x => x * 2")
+		}
+
+		program = test("f((a, b) => { var c = a; return c + b; }, () => 1)", nil)
+		{
+			args := program.Body[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression).ArgumentList
+			is(len(args), 2)
+			arrow := args[0].(*ast.ArrowFunctionLiteral)
+			is(len(arrow.ParameterList.List), 2)
+			is(len(arrow.DeclarationList), 1)
+			_ = arrow.Body.(*ast.BlockStatement)
+			is(len(args[1].(*ast.ArrowFunctionLiteral).ParameterList.List), 0)
+		}
+
+		program = test("(a, b)", nil)
+		_ = program.Body[0].(*ast.ExpressionStatement).Expression.(*ast.SequenceExpression)
+
+		program = test("class B extends A { constructor(x) { super(x); } static get n() { return 1; } set v(x) {} get() {} }", nil)
+		{
+			class := program.Body[0].(*ast.ClassDeclaration).Class
+			is(class.Name.Name, "B")
+			is(class.SuperClass.(*ast.Identifier).Name, "A")
+			is(len(class.Body), 4)
+			is(class.Body[0].Kind, "constructor")
+			_ = class.Body[0].Body.Body.(*ast.BlockStatement).List[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression).Callee.(*ast.SuperExpression)
+			is(class.Body[1].Kind, "get")
+			is(class.Body[1].Key, "n")
+			is(class.Body[1].Static, true)
+			is(class.Body[2].Kind, "set")
+			is(class.Body[3].Kind, "method")
+			is(class.Body[3].Key, "get")
+		}
+
+		program = test("for (let x of [a, ...b]) f(...x)", nil)
+		{
+			node := program.Body[0].(*ast.ForOfStatement)
+			is(node.Declaration.Token, token.LET)
+			is(node.Source.(*ast.ArrayLiteral).Value[1].(*ast.SpreadElement).Expression.(*ast.Identifier).Name, "b")
+			_ = node.Body.(*ast.ExpressionStatement).Expression.(*ast.CallExpression).ArgumentList[0].(*ast.SpreadElement)
+		}
+
+		program = test("(function*() { yield; yield* a; var yield1 = yield b, c; })", nil)
+		{
+			fn := program.Body[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+			is(fn.Generator, true)
+			body := fn.Body.(*ast.BlockStatement).List
+			is(body[0].(*ast.ExpressionStatement).Expression.(*ast.YieldExpression).Argument == nil, true)
+			is(body[1].(*ast.ExpressionStatement).Expression.(*ast.YieldExpression).Delegate, true)
+			_ = body[2].(*ast.VariableStatement).List[0].(*ast.VariableExpression).Initializer.(*ast.YieldExpression).Argument.(*ast.Identifier)
+		}
+
+		program = test("function f() { var yield = 1; return yield; }", nil)
+
+		program = test("(async function() { await a; await b + 1; })", nil)
+		{
+			fn := program.Body[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+			is(fn.Async, true)
+			is(fn.Source, "async function() { await a; await b + 1; }")
+			body := fn.Body.(*ast.BlockStatement).List
+			_ = body[0].(*ast.ExpressionStatement).Expression.(*ast.AwaitExpression).Argument.(*ast.Identifier)
+			_ = body[1].(*ast.ExpressionStatement).Expression.(*ast.BinaryExpression).Left.(*ast.AwaitExpression)
+		}
+
+		program = test("async (a, b) => await a; async x => x; async\nfunction f() {}", nil)
+		{
+			is(program.Body[0].(*ast.ExpressionStatement).Expression.(*ast.ArrowFunctionLiteral).Async, true)
+			is(program.Body[1].(*ast.ExpressionStatement).Expression.(*ast.ArrowFunctionLiteral).Async, true)
+			_ = program.Body[2].(*ast.ExpressionStatement).Expression.(*ast.Identifier)
+		}
+
+		program = test("async(1); class A { async() {} async m() {} }", nil)
+
+		program = test("`a${b}c${d + `e${f}`}` + 1", nil)
+		{
+			tmpl := program.Body[0].(*ast.ExpressionStatement).Expression.(*ast.BinaryExpression).Left.(*ast.TemplateLiteral)
+			is(len(tmpl.Elements), 3)
+			is(tmpl.Elements[0].Parsed, "a")
+			is(tmpl.Elements[1].Parsed, "c")
+			is(tmpl.Elements[2].Parsed, "")
+			_ = tmpl.Expressions[0].(*ast.Identifier)
+			_ = tmpl.Expressions[1].(*ast.BinaryExpression).Right.(*ast.TemplateLiteral)
+		}
+
+		program = test("a.b`x\\u{`\n(() => `\\n`)", nil)
+		{
+			tagged := program.Body[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression).Callee.(*ast.TaggedTemplate)
+			_ = tagged.Tag.(*ast.DotExpression)
+			is(tagged.Template.Elements[0].Valid, false)
+			is(tagged.Template.Elements[0].Literal, "x\\u{")
+		}
+
+		test("var a = `x`\nvar b = (c) => `${c}`", nil)
+
+		program = test("var of = []; for (of of of) {}", nil)
+		_ = program.Body[1].(*ast.ForOfStatement).Into.(*ast.Identifier)
+
+		program = test("var {a, b: [c, , d = 1, ...e]} = f; let [g] = h, {i: {j}} = k;", nil)
+		{
+			decl := program.Body[0].(*ast.VariableStatement).List[0].(*ast.VariableExpression)
+			is(decl.Name, "")
+			pattern := decl.Pattern.(*ast.ObjectPattern)
+			is(len(pattern.Properties), 2)
+			is(pattern.Properties[0].Key, "a")
+			is(pattern.Properties[0].Value.Target.(*ast.Identifier).Name, "a")
+			inner := pattern.Properties[1].Value.Target.(*ast.ArrayPattern)
+			is(len(inner.Elements), 3)
+			is(inner.Elements[1] == nil, true)
+			_ = inner.Elements[2].Initializer.(*ast.NumberLiteral)
+			is(inner.Rest.(*ast.Identifier).Name, "e")
+			is(len(program.DeclarationList), 1)
+			vars := program.DeclarationList[0].(*ast.VariableDeclaration).List
+			is(len(vars), 4)
+			is(vars[3].Name, "e")
+			lex := program.Body[1].(*ast.LexicalDeclaration)
+			is(len(lex.List), 2)
+			_ = lex.List[1].Pattern.(*ast.ObjectPattern).Properties[0].Value.Target.(*ast.ObjectPattern)
+		}
+
+		program = test("var {[a + 1]: b, [c]: [d] = e} = f;", nil)
+		{
+			pattern := program.Body[0].(*ast.VariableStatement).List[0].(*ast.VariableExpression).Pattern.(*ast.ObjectPattern)
+			is(len(pattern.Properties), 2)
+			_ = pattern.Properties[0].Computed.(*ast.BinaryExpression)
+			is(pattern.Properties[0].Key, "")
+			is(pattern.Properties[0].Value.Target.(*ast.Identifier).Name, "b")
+			is(pattern.Properties[1].Computed.(*ast.Identifier).Name, "c")
+			_ = pattern.Properties[1].Value.Target.(*ast.ArrayPattern)
+			is(len(program.DeclarationList[0].(*ast.VariableDeclaration).List), 2)
+		}
+
+		program = test("[a.b, c[0] = 1] = d; ({e, f: g} = h); for ([i, j] of k) {}", nil)
+		{
+			assign := program.Body[0].(*ast.ExpressionStatement).Expression.(*ast.AssignExpression)
+			pattern := assign.Left.(*ast.ArrayPattern)
+			_ = pattern.Elements[0].Target.(*ast.DotExpression)
+			_ = pattern.Elements[1].Target.(*ast.BracketExpression)
+			_ = program.Body[1].(*ast.ExpressionStatement).Expression.(*ast.AssignExpression).Left.(*ast.ObjectPattern)
+			_ = program.Body[2].(*ast.ForOfStatement).Into.(*ast.ArrayPattern)
+		}
+
+		program = test("[[1, 2][0]]; function f({a}, [b]) {} try {} catch ({message}) {}", nil)
+		{
+			_ = program.Body[0].(*ast.ExpressionStatement).Expression.(*ast.ArrayLiteral)
+			_ = program.Body[2].(*ast.TryStatement).Catch.Pattern.(*ast.ObjectPattern)
+		}
+
+		program = test("function f(a, b = a, {c} = {}, ...d) {}", nil)
+		{
+			params := program.DeclarationList[0].(*ast.FunctionDeclaration).Function.ParameterList
+			is(len(params.List), 3)
+			is(params.List[0].Initializer, nil)
+			_ = params.List[1].Initializer.(*ast.Identifier)
+			_ = params.List[2].Target.(*ast.ObjectPattern)
+			is(params.Rest.(*ast.Identifier).Name, "d")
+		}
+
+		program = test("var C = class { static() { return super.x; } }", nil)
+		{
+			class := program.Body[0].(*ast.VariableStatement).List[0].(*ast.VariableExpression).Initializer.(*ast.ClassLiteral)
+			is(class.Name == nil, true)
+			is(class.Body[0].Key, "static")
+			is(class.Body[0].Static, false)
+			is(class.Source, "class { static() { return super.x; } }")
+		}
+
+		program = test("class A { *[Symbol.iterator]() {} static get [k]() {} ['constructor']() {} }", nil)
+		{
+			class := program.Body[0].(*ast.ClassDeclaration).Class
+			is(len(class.Body), 3)
+			_ = class.Body[0].Computed.(*ast.DotExpression)
+			is(class.Body[0].Body.Generator, true)
+			_ = class.Body[1].Computed.(*ast.Identifier)
+			is(class.Body[1].Kind, "get")
+			is(class.Body[1].Static, true)
+			_ = class.Body[2].Computed.(*ast.StringLiteral)
+			is(class.Body[2].Kind, "method")
+			is(class.Body[2].Key, "")
+		}
+
+		program = test("({a, get, m() {}, *g() {}, async n() {}, get [k]() {}, [k + 1]: 2, __proto__: null})", nil)
+		{
+			props := program.Body[0].(*ast.ExpressionStatement).Expression.(*ast.ObjectLiteral).Value
+			is(len(props), 8)
+			is(props[0].Shorthand, true)
+			is(props[0].Value.(*ast.Identifier).Name, "a")
+			is(props[1].Key, "get")
+			is(props[1].Shorthand, true)
+			is(props[2].Kind, "method")
+			is(props[3].Value.(*ast.FunctionLiteral).Generator, true)
+			is(props[4].Value.(*ast.FunctionLiteral).Async, true)
+			is(props[5].Kind, "get")
+			_ = props[5].Computed.(*ast.Identifier)
+			_ = props[6].Computed.(*ast.BinaryExpression)
+			is(props[6].Key, "")
+			is(props[7].Key, "__proto__")
+		}
+
 		test("\ufeff/* var abc = 1; */", nil)
 
 		test(`if (-0x8000000000000000<=abc&&abc<=0x8000000000000000) {}`, nil)
@@ -959,6 +1295,9 @@ func Test_parseNumberLiteral(t *testing.T) {
 		test("0", 0)
 
 		test("0x8000000000000000", float64(9.223372036854776e+18))
+
+		test("0x1Fn", big.NewInt(31))
+		test("9007199254740993n", big.NewInt(9007199254740993))
 	})
 }
 
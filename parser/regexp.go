@@ -24,6 +24,9 @@ type _RegExp_parser struct {
 	errors  []error
 	invalid bool // The input is an invalid JavaScript RegExp 输入是无效的JavaScript正则表达式
 
+	dotAll  bool // s标志，.匹配包括换行在内的任意字符
+	unicode bool // u标志，允许\u{...}转义，代理对作为一个码点
+
 	goRegexp *bytes.Buffer // 转换为go的正则表达式
 }
 
@@ -54,6 +57,13 @@ type _RegExp_parser struct {
 //如果模式有效但不兼容（包含前向或后向引用），
 //然后此函数返回转换（非空字符串）和错误。
 func TransformRegExp(pattern string) (string, error) {
+	return TransformRegExpFlags(pattern, false, false)
+}
+
+// TransformRegExpFlags is like TransformRegExp but honours the ES2018 `s` (dotAll)
+// and ES2015 `u` (unicode) flags. Named groups are converted to (?P<name>...);
+// lookbehind and named backreferences are reported as re2-incompatible.
+func TransformRegExpFlags(pattern string, dotAll, unicode bool) (string, error) {
 
 	if pattern == "" {
 		return "", nil
@@ -66,6 +76,8 @@ func TransformRegExp(pattern string) (string, error) {
 		str:      pattern,
 		length:   len(pattern),
 		goRegexp: bytes.NewBuffer(make([]byte, 0, 3*len(pattern)/2)),
+		dotAll:   dotAll,
+		unicode:  unicode,
 	}
 	parser.read() // Pull in the first character
 	parser.scan()
@@ -97,14 +109,23 @@ func (self *_RegExp_parser) scan() {
 			self.invalid = true
 			self.pass()
 		case '.':
-			self.goRegexp.WriteString("[^\\r\\n]")
-			self.read()
+			self.scanDot()
 		default:
 			self.pass()
 		}
 	}
 }
 
+// 处理.，dotAll时匹配任意字符
+func (self *_RegExp_parser) scanDot() {
+	if self.dotAll {
+		self.goRegexp.WriteString("(?s:.)")
+	} else {
+		self.goRegexp.WriteString("[^\\r\\n]")
+	}
+	self.read()
+}
+
 // (...) 处理圆括号内的内容
 func (self *_RegExp_parser) scanGroup() {
 	str := self.str[self.chrOffset:]
@@ -115,6 +136,33 @@ func (self *_RegExp_parser) scanGroup() {
 			}
 		}
 	}
+	if strings.HasPrefix(str, "?<=") || strings.HasPrefix(str, "?<!") {
+		self.error(-1, "re2: Invalid (%s) <lookbehind>", str[:3])
+	} else if strings.HasPrefix(str, "?<") {
+		// 命名捕获组(?<name>...)转换为(?P<name>...)
+		self.goRegexp.WriteString("?P<")
+		self.read()
+		self.read()
+		nameOffset := self.chrOffset
+		for self.chr != -1 && self.chr != '>' {
+			if !isIdentifierPart(self.chr) {
+				self.error(-1, "Invalid capture group name")
+				self.invalid = true
+				return
+			}
+			if self.chr == '$' || self.chr >= utf8.RuneSelf {
+				// re2只支持单词字符组成的组名
+				self.error(-1, "re2: Invalid capture group name %s", self.str[nameOffset:])
+			}
+			self.pass()
+		}
+		if self.chr != '>' || self.chrOffset == nameOffset {
+			self.error(-1, "Invalid capture group name")
+			self.invalid = true
+			return
+		}
+		self.pass()
+	}
 	for self.chr != -1 && self.chr != ')' {
 		switch self.chr {
 		case '\\':
@@ -126,8 +174,7 @@ func (self *_RegExp_parser) scanGroup() {
 		case '[':
 			self.scanBracket()
 		case '.':
-			self.goRegexp.WriteString("[^\\r\\n]")
-			self.read()
+			self.scanDot()
 		default:
 			self.pass()
 			continue
@@ -250,8 +297,19 @@ func (self *_RegExp_parser) scanEscape(inClass bool) {
 
 	case 'u':
 		self.read()
+		if self.unicode && self.chr == '{' {
+			self.scanUnicodeCodePoint(offset)
+			return
+		}
 		length, base = 4, 16
 
+	case 'k':
+		if self.offset < self.length && self.str[self.offset] == '<' {
+			self.error(-1, "re2: Invalid \\k<name> <backreference>")
+		}
+		self.pass()
+		return
+
 	case 'b':
 		if inClass {
 			_, err := self.goRegexp.Write([]byte{'\\', 'x', '0', '8'})
@@ -360,6 +418,21 @@ func (self *_RegExp_parser) scanEscape(inClass bool) {
 		}
 	}
 
+	if length == 4 && self.unicode && value >= 0xD800 && value < 0xDC00 {
+		// unicode模式下\uXXXX\uXXXX代理对表示一个码点
+		str := self.str[self.chrOffset:]
+		if len(str) >= 6 && self.chr == '\\' && str[1] == 'u' {
+			if low, err := strconv.ParseUint(str[2:6], 16, 32); err == nil && low >= 0xDC00 && low < 0xE000 {
+				for i := 0; i < 6; i++ {
+					self.read()
+				}
+				cp := (value-0xD800)<<10 + uint32(low-0xDC00) + 0x10000
+				self.goRegexp.WriteString(fmt.Sprintf("\\x{%x}", cp))
+				return
+			}
+		}
+	}
+
 	if length == 4 {
 		_, err := self.goRegexp.Write([]byte{
 			'\\',
@@ -398,6 +471,28 @@ skip:
 		self.errors = append(self.errors, err)
 	}
 }
+// \u{...}转义，当前字符为{
+func (self *_RegExp_parser) scanUnicodeCodePoint(offset int) {
+	self.read()
+	var value uint32
+	digits := 0
+	for self.chr != '}' {
+		digit := uint32(digitValue(self.chr))
+		if digit >= 16 || value > 0x10FFFF {
+			break
+		}
+		value = value*16 + digit
+		digits++
+		self.read()
+	}
+	if self.chr != '}' || digits == 0 || value > 0x10FFFF {
+		self.error(-1, "Invalid Unicode escape \\%s", self.str[offset:self.chrOffset])
+		self.invalid = true
+		return
+	}
+	self.read()
+	self.goRegexp.WriteString(fmt.Sprintf("\\x{%x}", value))
+}
 // 当前字符添加到正则表达式buf中，然后读取下一个字符
 func (self *_RegExp_parser) pass() {
 	if self.chr != -1 {
@@ -415,3 +510,123 @@ func (self *_RegExp_parser) error(offset int, msg string, msgValues ...interface
 	self.errors = append(self.errors, err)
 	return err
 }
+
+// TransformRegExp2 rewrites a JavaScript pattern for the regexp2 engine, which
+// understands neither named groups, \u{...} escapes nor the `s` flag.
+// Named groups become plain capturing groups and \k<name> becomes a numbered
+// backreference. The returned slice holds the group names indexed by group number,
+// or nil if the pattern has no named groups.
+func TransformRegExp2(pattern string, dotAll, unicode bool) (string, []string) {
+	names := regExpGroupNames(pattern)
+	var b strings.Builder
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		chr := pattern[i]
+		switch {
+		case chr == '\\' && i+1 < len(pattern):
+			next := pattern[i+1]
+			if next == 'k' && !inClass && names != nil && strings.HasPrefix(pattern[i+2:], "<") {
+				if end := strings.IndexByte(pattern[i+3:], '>'); end >= 0 {
+					if n := regExpGroupIndex(names, pattern[i+3:i+3+end]); n > 0 {
+						fmt.Fprintf(&b, "(?:\\%d)", n)
+						i += 3 + end
+						continue
+					}
+				}
+			}
+			if next == 'u' && unicode {
+				if cp, size := regExpCodePoint(pattern[i:]); size > 0 {
+					if cp <= 0xFFFF {
+						fmt.Fprintf(&b, "\\u%04X", cp)
+					} else {
+						b.WriteRune(rune(cp))
+					}
+					i += size - 1
+					continue
+				}
+			}
+			b.WriteString(pattern[i : i+2])
+			i++
+		case inClass:
+			if chr == ']' {
+				inClass = false
+			}
+			b.WriteByte(chr)
+		case chr == '[':
+			inClass = true
+			b.WriteByte(chr)
+		case chr == '(' && isRegExpNamedGroup(pattern[i+1:]):
+			b.WriteByte('(')
+			i += strings.IndexByte(pattern[i:], '>')
+		case chr == '.' && dotAll:
+			b.WriteString(`[\s\S]`)
+		default:
+			b.WriteByte(chr)
+		}
+	}
+	return b.String(), names
+}
+// 判断是否为命名捕获组(?<name>，不包括后行断言
+func isRegExpNamedGroup(str string) bool {
+	return strings.HasPrefix(str, "?<") && !strings.HasPrefix(str, "?<=") && !strings.HasPrefix(str, "?<!") &&
+		strings.IndexByte(str, '>') > 2
+}
+// 按捕获组编号收集组名，没有命名捕获组时返回nil
+func regExpGroupNames(pattern string) []string {
+	names := []string{""}
+	named := false
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		switch chr := pattern[i]; {
+		case chr == '\\':
+			i++
+		case inClass:
+			inClass = chr != ']'
+		case chr == '[':
+			inClass = true
+		case chr == '(':
+			str := pattern[i+1:]
+			if isRegExpNamedGroup(str) {
+				names = append(names, str[2:strings.IndexByte(str, '>')])
+				named = true
+			} else if !strings.HasPrefix(str, "?") {
+				names = append(names, "")
+			}
+		}
+	}
+	if !named {
+		return nil
+	}
+	return names
+}
+// 查找组名对应的捕获组编号
+func regExpGroupIndex(names []string, name string) int {
+	for i, n := range names {
+		if i > 0 && n == name {
+			return i
+		}
+	}
+	return -1
+}
+// 解析\u{...}或\uXXXX\uXXXX代理对，返回码点和消耗的字节数
+func regExpCodePoint(str string) (uint32, int) {
+	if strings.HasPrefix(str, "\\u{") {
+		end := strings.IndexByte(str, '}')
+		if end < 4 {
+			return 0, 0
+		}
+		cp, err := strconv.ParseUint(str[3:end], 16, 32)
+		if err != nil || cp > 0x10FFFF {
+			return 0, 0
+		}
+		return uint32(cp), end + 1
+	}
+	if len(str) >= 12 && str[6] == '\\' && str[7] == 'u' {
+		high, err1 := strconv.ParseUint(str[2:6], 16, 32)
+		low, err2 := strconv.ParseUint(str[8:12], 16, 32)
+		if err1 == nil && err2 == nil && high >= 0xD800 && high < 0xDC00 && low >= 0xDC00 && low < 0xE000 {
+			return uint32((high-0xD800)<<10+(low-0xDC00)) + 0x10000, 12
+		}
+	}
+	return 0, 0
+}
@@ -125,3 +125,40 @@ func TestTransformRegExp(t *testing.T) {
 		is(regexp.MustCompile(pattern).MatchString("\t abc def"), true)
 	})
 }
+// 带标志的正则表达式转换测试
+func TestTransformRegExpFlags(t *testing.T) {
+	tt(t, func() {
+		test := func(input string, dotAll, unicode bool, expect string) {
+			result, err := TransformRegExpFlags(input, dotAll, unicode)
+			is(err, nil)
+			is(result, expect)
+			_, err = regexp.Compile(result)
+			is(err, nil)
+		}
+
+		test(`a.b`, true, false, `a(?s:.)b`)
+		test(`(?<year>\d+)`, false, false, `(?P<year>\d+)`)
+		test(`\u{1F600}`, false, true, `\x{1f600}`)
+		test(`\uD83D\uDE00`, false, true, `\x{1f600}`)
+
+		_, err := TransformRegExpFlags(`(?<=a)b`, false, false)
+		is(err, "re2: Invalid (?<=) <lookbehind>")
+		_, err = TransformRegExpFlags(`(?<a>.)\k<a>`, false, false)
+		is(err, "re2: Invalid \\k<name> <backreference>")
+		_, err = TransformRegExpFlags(`\u{110000}`, false, true)
+		is(err, "Invalid Unicode escape \\u{110000")
+	})
+}
+// regexp2正则表达式转换测试
+func TestTransformRegExp2(t *testing.T) {
+	tt(t, func() {
+		result, names := TransformRegExp2(`(a)(?<n>b)(?:c)\k<n>[.]./`, true, false)
+		is(result, `(a)(b)(?:c)(?:\2)[.][\s\S]/`)
+		is(len(names), 3)
+		is(names[2], "n")
+
+		result, names = TransformRegExp2(`\u{1F600}\u{41}(?<=x)`, false, true)
+		is(result, "\U0001F600\\u0041(?<=x)")
+		is(names == nil, true)
+	})
+}
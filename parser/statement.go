@@ -28,11 +28,96 @@ func (self *_parser) parseEmptyStatement() ast.Statement {
 // 循环解析语句列表
 func (self *_parser) parseStatementList() (list []ast.Statement) {
 	for self.token != token.RIGHT_BRACE && self.token != token.EOF {
-		list = append(list, self.parseStatement())
+		list = append(list, self.parseStatementListItem())
 	}
 
 	return
 }
+// 解析语句列表中的一项，let/const声明只能出现在语句列表中
+func (self *_parser) parseStatementListItem() ast.Statement {
+	switch self.token {
+	case token.CONST:
+		return self.parseLexicalDeclaration(token.CONST)
+	case token.CLASS:
+		return &ast.ClassDeclaration{
+			Class: self.parseClass(true),
+		}
+	case token.FUNCTION:
+		return self.parseFunctionDeclaration()
+	case token.IDENTIFIER:
+		if self.isLetDeclaration() {
+			return self.parseLexicalDeclaration(token.LET)
+		}
+		if self.isAsyncFunction() {
+			return self.parseFunctionDeclaration()
+		}
+	}
+	return self.parseStatement()
+}
+// 解析函数声明语句
+func (self *_parser) parseFunctionDeclaration() *ast.FunctionDeclaration {
+	return &ast.FunctionDeclaration{
+		Function: self.parseFunction(true),
+	}
+}
+// 单语句位置(比如if的分支)的函数声明相当于放在一个块中
+func (self *_parser) parseFunctionStatementInBlock() ast.Statement {
+	decl := self.parseFunctionDeclaration()
+	return &ast.BlockStatement{
+		LeftBrace:  decl.Idx0(),
+		List:       []ast.Statement{decl},
+		RightBrace: decl.Idx1() - 1,
+	}
+}
+// 当前的let是否为声明的开始，否则let只是一个普通的标识符
+func (self *_parser) isLetDeclaration() bool {
+	if self.token != token.IDENTIFIER || self.literal != "let" {
+		return false
+	}
+	switch self.peek() {
+	case token.IDENTIFIER, token.LEFT_BRACKET, token.LEFT_BRACE:
+		return true
+	}
+	return false
+}
+// 解析let/const声明语句
+func (self *_parser) parseLexicalDeclaration(tok token.Token) ast.Statement {
+	node := self.parseLexicalDeclarationList(tok)
+	for _, item := range node.List {
+		self.checkPatternInitializer(item)
+	}
+	if tok == token.CONST {
+		self.checkConstInitializers(node)
+	}
+	self.semicolon()
+	return node
+}
+// 解析let/const声明的变量列表
+func (self *_parser) parseLexicalDeclarationList(tok token.Token) *ast.LexicalDeclaration {
+	node := &ast.LexicalDeclaration{
+		Idx:   self.idx,
+		Token: tok,
+	}
+	self.next()
+	for {
+		if item, ok := self.parseVariableDeclaration(nil).(*ast.VariableExpression); ok {
+			node.List = append(node.List, item)
+		}
+		if self.token != token.COMMA {
+			break
+		}
+		self.next()
+	}
+	return node
+}
+// const声明必须有初始值
+func (self *_parser) checkConstInitializers(node *ast.LexicalDeclaration) {
+	for _, item := range node.List {
+		if item.Initializer == nil && item.Pattern == nil {
+			self.error(item.Idx, "Missing initializer in const declaration")
+		}
+	}
+}
 // 语句解析
 func (self *_parser) parseStatement() ast.Statement {
 
@@ -64,10 +149,13 @@ func (self *_parser) parseStatement() ast.Statement {
 		return self.parseWithStatement()
 	case token.VAR:
 		return self.parseVariableStatement()
+	case token.CONST, token.CLASS:
+		idx := self.idx
+		self.error(idx, "Lexical declaration cannot appear in a single-statement context")
+		self.nextStatement()
+		return &ast.BadStatement{From: idx, To: self.idx}
 	case token.FUNCTION:
-		self.parseFunction(true)
-		// FIXME
-		return &ast.EmptyStatement{}
+		return self.parseFunctionStatementInBlock()
 	case token.SWITCH:
 		return self.parseSwitchStatement()
 	case token.RETURN:
@@ -78,6 +166,10 @@ func (self *_parser) parseStatement() ast.Statement {
 		return self.parseTryStatement()
 	}
 
+	if self.isAsyncFunction() {
+		return self.parseFunctionStatementInBlock()
+	}
+
 	expression := self.parseExpression()
 
 	if identifier, isIdentifier := expression.(*ast.Identifier); isIdentifier && self.token == token.COLON {
@@ -91,7 +183,13 @@ func (self *_parser) parseStatement() ast.Statement {
 			}
 		}
 		self.scope.labels = append(self.scope.labels, label) // Push the label
-		statement := self.parseStatement()
+		var statement ast.Statement
+		if self.token == token.FUNCTION {
+			// 标签后的函数声明与没有标签时相同
+			statement = self.parseFunctionDeclaration()
+		} else {
+			statement = self.parseStatement()
+		}
 		self.scope.labels = self.scope.labels[:len(self.scope.labels)-1] // Pop the label
 		return &ast.LabelledStatement{
 			Label:     identifier,
@@ -118,7 +216,15 @@ func (self *_parser) parseTryStatement() ast.Statement {
 		catch := self.idx
 		self.next()
 		self.expect(token.LEFT_PARENTHESIS)
-		if self.token != token.IDENTIFIER {
+		if self.token == token.LEFT_BRACKET || self.token == token.LEFT_BRACE {
+			pattern := self.parsePattern(true, nil)
+			self.expect(token.RIGHT_PARENTHESIS)
+			node.Catch = &ast.CatchStatement{
+				Catch:   catch,
+				Pattern: pattern,
+				Body:    self.parseBlockStatement(),
+			}
+		} else if self.token != token.IDENTIFIER {
 			self.expect(token.IDENTIFIER)
 			self.nextStatement()
 			return &ast.BadStatement{From: catch, To: self.idx}
@@ -148,12 +254,22 @@ func (self *_parser) parseTryStatement() ast.Statement {
 // 解析函数参数列表
 func (self *_parser) parseFunctionParameterList() *ast.ParameterList {
 	opening := self.expect(token.LEFT_PARENTHESIS)
-	var list []*ast.Identifier
+	var list []*ast.Binding
+	var rest ast.Expression
 	for self.token != token.RIGHT_PARENTHESIS && self.token != token.EOF {
-		if self.token != token.IDENTIFIER {
+		if self.token == token.ELLIPSIS {
+			self.next()
+			rest = self.parsePatternTarget(true, nil)
+			if self.token != token.RIGHT_PARENTHESIS {
+				self.error(self.idx, "Rest parameter must be last formal parameter")
+			}
+			break
+		}
+		switch self.token {
+		case token.IDENTIFIER, token.LEFT_BRACKET, token.LEFT_BRACE:
+			list = append(list, self.parsePatternElement(true, nil))
+		default:
 			self.expect(token.IDENTIFIER)
-		} else {
-			list = append(list, self.parseIdentifier())
 		}
 		if self.token != token.RIGHT_PARENTHESIS {
 			self.expect(token.COMMA)
@@ -164,6 +280,7 @@ func (self *_parser) parseFunctionParameterList() *ast.ParameterList {
 	return &ast.ParameterList{
 		Opening: opening,
 		List:    list,
+		Rest:    rest,
 		Closing: closing,
 	}
 }
@@ -185,7 +302,19 @@ func (self *_parser) parseParameterList() (list []string) {
 func (self *_parser) parseFunction(declaration bool) *ast.FunctionLiteral {
 
 	node := &ast.FunctionLiteral{
-		Function: self.expect(token.FUNCTION),
+		Function: self.idx,
+	}
+	if self.token == token.IDENTIFIER && self.literal == "async" {
+		self.next()
+		node.Async = true
+	}
+	self.expect(token.FUNCTION)
+	if self.token == token.MULTIPLY {
+		if node.Async {
+			self.error(self.idx, "Async generator functions are not supported")
+		}
+		self.next()
+		node.Generator = true
 	}
 
 	var name *ast.Identifier
@@ -207,12 +336,24 @@ func (self *_parser) parseFunction(declaration bool) *ast.FunctionLiteral {
 
 	return node
 }
+// 当前的async是否为async函数的开始，async和function之间不能换行
+func (self *_parser) isAsyncFunction() bool {
+	if self.token != token.IDENTIFIER || self.literal != "async" {
+		return false
+	}
+	state := self.mark()
+	defer self.restore(&state)
+	self.next()
+	return self.token == token.FUNCTION && !self.implicitSemicolon
+}
 // 解析函数体
 func (self *_parser) parseFunctionBlock(node *ast.FunctionLiteral) {
 	{
 		self.openScope()
 		inFunction := self.scope.inFunction
 		self.scope.inFunction = true
+		self.scope.inGenerator = node.Generator
+		self.scope.inAsync = node.Async
 		defer func() {
 			self.scope.inFunction = inFunction
 			self.closeScope()
@@ -221,6 +362,111 @@ func (self *_parser) parseFunctionBlock(node *ast.FunctionLiteral) {
 		node.DeclarationList = self.scope.declarationList
 	}
 }
+// 解析类声明或类表达式
+func (self *_parser) parseClass(declaration bool) *ast.ClassLiteral {
+	node := &ast.ClassLiteral{
+		Class: self.expect(token.CLASS),
+	}
+
+	if self.token == token.IDENTIFIER {
+		node.Name = self.parseIdentifier()
+	} else if declaration {
+		self.expect(token.IDENTIFIER)
+	}
+
+	if self.token == token.EXTENDS {
+		self.next()
+		node.SuperClass = self.parseLeftHandSideExpressionAllowCall()
+	}
+
+	self.expect(token.LEFT_BRACE)
+	hasConstructor := false
+	for self.token != token.RIGHT_BRACE && self.token != token.EOF {
+		if self.token == token.SEMICOLON {
+			self.next()
+			continue
+		}
+		method := self.parseMethodDefinition()
+		if method.Kind == "constructor" {
+			if hasConstructor {
+				self.error(method.Idx, "A class may only have one constructor")
+			}
+			hasConstructor = true
+		}
+		node.Body = append(node.Body, method)
+	}
+	node.RightBrace = self.expect(token.RIGHT_BRACE)
+	node.Source = self.slice(node.Idx0(), node.Idx1())
+
+	return node
+}
+// 解析类中的方法，包括static方法和getter/setter
+func (self *_parser) parseMethodDefinition() *ast.MethodDefinition {
+	node := &ast.MethodDefinition{
+		Idx:  self.idx,
+		Kind: "method",
+	}
+	if self.token == token.IDENTIFIER && self.literal == "static" && self.peek() != token.LEFT_PARENTHESIS {
+		self.next()
+		node.Static = true
+	}
+	if self.token == token.IDENTIFIER && (self.literal == "get" || self.literal == "set") && self.peek() != token.LEFT_PARENTHESIS {
+		node.Kind = self.literal
+		self.next()
+	}
+	async := false
+	if node.Kind == "method" && self.token == token.IDENTIFIER && self.literal == "async" && self.isAsyncMethod() {
+		self.next()
+		async = true
+	}
+	generator := false
+	if node.Kind == "method" && self.token == token.MULTIPLY {
+		if async {
+			self.error(self.idx, "Async generator functions are not supported")
+		}
+		self.next()
+		generator = true
+	}
+
+	idx := self.idx
+	computed, _, key := self.parsePropertyName()
+	node.Key = key
+	node.Computed = computed
+	// ["constructor"]()是普通方法
+	if computed == nil && key == "constructor" && !node.Static {
+		if node.Kind != "method" {
+			self.error(idx, "Class constructor may not be an accessor")
+		}
+		if generator {
+			self.error(idx, "Class constructor may not be a generator")
+		}
+		if async {
+			self.error(idx, "Class constructor may not be an async method")
+		}
+		node.Kind = "constructor"
+	}
+	if computed == nil && key == "prototype" && node.Static {
+		self.error(idx, "Classes may not have a static property named 'prototype'")
+	}
+
+	node.Body = &ast.FunctionLiteral{
+		Function:      idx,
+		ParameterList: self.parseFunctionParameterList(),
+		Generator:     generator,
+		Async:         async,
+	}
+	self.parseFunctionBlock(node.Body)
+	node.Body.Source = self.slice(node.Body.Idx0(), node.Body.Idx1())
+
+	return node
+}
+// async之后是方法名时为async方法，否则async本身是方法名
+func (self *_parser) isAsyncMethod() bool {
+	state := self.mark()
+	defer self.restore(&state)
+	self.next()
+	return self.token != token.LEFT_PARENTHESIS && !self.implicitSemicolon
+}
 // 分析调试语句
 func (self *_parser) parseDebuggerStatement() ast.Statement {
 	idx := self.expect(token.DEBUGGER)
@@ -350,7 +596,7 @@ func (self *_parser) parseCaseStatement() *ast.CaseStatement {
 			self.token == token.DEFAULT {
 			break
 		}
-		node.Consequent = append(node.Consequent, self.parseStatement())
+		node.Consequent = append(node.Consequent, self.parseStatementListItem())
 
 	}
 
@@ -381,6 +627,26 @@ func (self *_parser) parseForIn(idx file.Idx, into ast.Expression) *ast.ForInSta
 		Body:   self.parseIterationStatement(),
 	}
 }
+// for-of表达式解析
+// 类似：for (x of arr)
+func (self *_parser) parseForOf(idx file.Idx, into ast.Expression) *ast.ForOfStatement {
+
+	// Already have consumed "<into> of"
+
+	source := self.parseAssignmentExpression()
+	self.expect(token.RIGHT_PARENTHESIS)
+
+	return &ast.ForOfStatement{
+		For:    idx,
+		Into:   into,
+		Source: source,
+		Body:   self.parseIterationStatement(),
+	}
+}
+// 当前是否为上下文关键字of
+func (self *_parser) isOf() bool {
+	return self.token == token.IDENTIFIER && self.literal == "of"
+}
 // for表达式解析
 // 类似：for (语句 1; 语句 2; 语句 3)
 func (self *_parser) parseFor(idx file.Idx, initializer ast.Expression) *ast.ForStatement {
@@ -407,19 +673,42 @@ func (self *_parser) parseFor(idx file.Idx, initializer ast.Expression) *ast.For
 		Body:        self.parseIterationStatement(),
 	}
 }
-// for表达式解析，包括了for和forin
+// for表达式解析，包括了for、forin和forof
 func (self *_parser) parseForOrForInStatement() ast.Statement {
 	idx := self.expect(token.FOR)
 	self.expect(token.LEFT_PARENTHESIS)
 
 	var left []ast.Expression
+	var decl *ast.LexicalDeclaration
 
-	forIn := false
+	forIn, forOf := false, false
 	if self.token != token.SEMICOLON {
 
 		allowIn := self.scope.allowIn
 		self.scope.allowIn = false
-		if self.token == token.VAR {
+		if self.token == token.CONST || self.isLetDeclaration() {
+			tok := token.LET
+			if self.token == token.CONST {
+				tok = token.CONST
+			}
+			decl = self.parseLexicalDeclarationList(tok)
+			if len(decl.List) == 1 && self.token == token.IN {
+				self.next() // in
+				forIn = true
+				left = []ast.Expression{decl.List[0]}
+			} else if len(decl.List) == 1 && self.isOf() {
+				self.next() // of
+				forOf = true
+				left = []ast.Expression{decl.List[0]}
+			} else {
+				for _, item := range decl.List {
+					self.checkPatternInitializer(item)
+				}
+				if tok == token.CONST {
+					self.checkConstInitializers(decl)
+				}
+			}
+		} else if self.token == token.VAR {
 			var_ := self.idx
 			self.next()
 			list := self.parseVariableDeclarationList(var_)
@@ -427,7 +716,14 @@ func (self *_parser) parseForOrForInStatement() ast.Statement {
 				self.next() // in
 				forIn = true
 				left = []ast.Expression{list[0]} // There is only one declaration
+			} else if len(list) == 1 && self.isOf() {
+				self.next() // of
+				forOf = true
+				left = []ast.Expression{list[0]}
 			} else {
+				for _, item := range list {
+					self.checkPatternInitializer(item)
+				}
 				left = list
 			}
 		} else {
@@ -435,24 +731,44 @@ func (self *_parser) parseForOrForInStatement() ast.Statement {
 			if self.token == token.IN {
 				self.next()
 				forIn = true
+			} else if self.isOf() {
+				self.next()
+				forOf = true
 			}
 		}
 		self.scope.allowIn = allowIn
 	}
 
-	if forIn {
+	if forIn || forOf {
 		switch left[0].(type) {
-		case *ast.Identifier, *ast.DotExpression, *ast.BracketExpression, *ast.VariableExpression:
+		case *ast.Identifier, *ast.DotExpression, *ast.BracketExpression, *ast.VariableExpression,
+			*ast.ArrayPattern, *ast.ObjectPattern:
 			// These are all acceptable
 		default:
-			self.error(idx, "Invalid left-hand side in for-in")
+			if forOf {
+				self.error(idx, "Invalid left-hand side in for-of")
+			} else {
+				self.error(idx, "Invalid left-hand side in for-in")
+			}
 			self.nextStatement()
 			return &ast.BadStatement{From: idx, To: self.idx}
 		}
-		return self.parseForIn(idx, left[0])
+		if forOf {
+			node := self.parseForOf(idx, left[0])
+			node.Declaration = decl
+			return node
+		}
+		node := self.parseForIn(idx, left[0])
+		node.Declaration = decl
+		return node
 	}
 
 	self.expect(token.SEMICOLON)
+	if decl != nil {
+		node := self.parseFor(idx, nil)
+		node.Declaration = decl
+		return node
+	}
 	return self.parseFor(idx, &ast.SequenceExpression{Sequence: left})
 }
 // 变量定义解析
@@ -461,6 +777,9 @@ func (self *_parser) parseVariableStatement() *ast.VariableStatement {
 	idx := self.expect(token.VAR)
 
 	list := self.parseVariableDeclarationList(idx)
+	for _, item := range list {
+		self.checkPatternInitializer(item)
+	}
 	self.semicolon()
 
 	return &ast.VariableStatement{
@@ -528,9 +847,229 @@ func (self *_parser) parseIfStatement() ast.Statement {
 
 	return node
 }
-// 开始语句解析
+// 开始语句解析，import和export声明只能出现在顶层
 func (self *_parser) parseSourceElement() ast.Statement {
-	return self.parseStatement()
+	switch self.token {
+	case token.IMPORT:
+		if self.peek() != token.LEFT_PARENTHESIS {
+			return self.parseImportDeclaration()
+		}
+	case token.EXPORT:
+		return self.parseExportDeclaration()
+	}
+	return self.parseStatementListItem()
+}
+// 当前标记是否为上下文关键字，如as和from
+func (self *_parser) isContextual(word string) bool {
+	return self.token == token.IDENTIFIER && self.literal == word
+}
+// 跳过上下文关键字
+func (self *_parser) expectContextual(word string) {
+	if !self.isContextual(word) {
+		self.errorUnexpectedToken(self.token)
+	}
+	self.next()
+}
+// 解析模块名称字符串
+func (self *_parser) parseModuleSpecifier() string {
+	idx, literal := self.idx, self.literal
+	if self.token != token.STRING {
+		self.expect(token.STRING)
+		return ""
+	}
+	self.next()
+	value, err := parseStringLiteral(literal[1 : len(literal)-1])
+	if err != nil {
+		self.error(idx, err.Error())
+	}
+	return value
+}
+// 解析导入或导出的名称，关键字也可以作为名称
+func (self *_parser) parseModuleExportName() (file.Idx, string, bool) {
+	idx, literal, tkn := self.idx, self.literal, self.token
+	if !matchIdentifier.MatchString(literal) {
+		self.errorUnexpectedToken(self.token)
+	}
+	self.next()
+	return idx, literal, tkn == token.IDENTIFIER
+}
+// 解析导入的本地绑定名称
+func (self *_parser) parseImportedBinding() *ast.Identifier {
+	if self.token != token.IDENTIFIER {
+		self.expect(token.IDENTIFIER)
+		return &ast.Identifier{Idx: self.idx}
+	}
+	return self.parseIdentifier()
+}
+// 解析import声明
+func (self *_parser) parseImportDeclaration() ast.Statement {
+	idx := self.expect(token.IMPORT)
+	if self.mode&Module == 0 {
+		self.error(idx, "Cannot use import statement outside a module")
+		self.nextStatement()
+		return &ast.BadStatement{From: idx, To: self.idx}
+	}
+	node := &ast.ImportDeclaration{
+		Import: idx,
+	}
+	if self.token != token.STRING {
+		needBindings := true
+		if self.token == token.IDENTIFIER {
+			node.Default = self.parseImportedBinding()
+			needBindings = self.token == token.COMMA
+			if needBindings {
+				self.next()
+			}
+		}
+		if needBindings {
+			switch self.token {
+			case token.MULTIPLY:
+				self.next()
+				self.expectContextual("as")
+				node.Namespace = self.parseImportedBinding()
+			case token.LEFT_BRACE:
+				node.Specifiers = self.parseImportSpecifiers()
+			default:
+				self.errorUnexpectedToken(self.token)
+			}
+		}
+		self.expectContextual("from")
+	}
+	node.ModuleSpecifier = self.parseModuleSpecifier()
+	self.semicolon()
+	return node
+}
+// 解析import {a, b as c}中大括号内的列表
+func (self *_parser) parseImportSpecifiers() (list []*ast.ImportSpecifier) {
+	self.expect(token.LEFT_BRACE)
+	for self.token != token.RIGHT_BRACE && self.token != token.EOF {
+		idx, name, isIdent := self.parseModuleExportName()
+		spec := &ast.ImportSpecifier{
+			Idx:        idx,
+			ImportName: name,
+			LocalName:  name,
+		}
+		if self.isContextual("as") {
+			self.next()
+			local := self.parseImportedBinding()
+			spec.Idx = local.Idx
+			spec.LocalName = local.Name
+		} else if !isIdent {
+			self.error(idx, "Unexpected reserved word")
+		}
+		list = append(list, spec)
+		if self.token != token.RIGHT_BRACE {
+			self.expect(token.COMMA)
+		}
+	}
+	self.expect(token.RIGHT_BRACE)
+	return
+}
+// 解析export {a, b as c}中大括号内的列表，reserved为第一个不是标识符的本地名称
+func (self *_parser) parseExportSpecifiers() (list []*ast.ExportSpecifier, reserved *ast.ExportSpecifier) {
+	self.expect(token.LEFT_BRACE)
+	for self.token != token.RIGHT_BRACE && self.token != token.EOF {
+		idx, name, isIdent := self.parseModuleExportName()
+		spec := &ast.ExportSpecifier{
+			Idx:        idx,
+			LocalName:  name,
+			ExportName: name,
+		}
+		if !isIdent && reserved == nil {
+			reserved = spec
+		}
+		if self.isContextual("as") {
+			self.next()
+			_, spec.ExportName, _ = self.parseModuleExportName()
+		}
+		list = append(list, spec)
+		if self.token != token.RIGHT_BRACE {
+			self.expect(token.COMMA)
+		}
+	}
+	self.expect(token.RIGHT_BRACE)
+	return
+}
+// 解析export声明
+func (self *_parser) parseExportDeclaration() ast.Statement {
+	idx := self.expect(token.EXPORT)
+	if self.mode&Module == 0 {
+		self.error(idx, err_UnexpectedToken, token.EXPORT)
+		self.nextStatement()
+		return &ast.BadStatement{From: idx, To: self.idx}
+	}
+	node := &ast.ExportDeclaration{
+		Export: idx,
+	}
+	switch self.token {
+	case token.MULTIPLY:
+		self.next()
+		node.Star = true
+		if self.isContextual("as") {
+			self.next()
+			nameIdx, name, _ := self.parseModuleExportName()
+			node.Namespace = &ast.Identifier{Idx: nameIdx, Name: name}
+		}
+		self.expectContextual("from")
+		node.ModuleSpecifier = self.parseModuleSpecifier()
+		self.semicolon()
+	case token.LEFT_BRACE:
+		var reserved *ast.ExportSpecifier
+		node.Specifiers, reserved = self.parseExportSpecifiers()
+		if self.isContextual("from") {
+			self.next()
+			node.ModuleSpecifier = self.parseModuleSpecifier()
+		} else if reserved != nil {
+			// 没有from时导出的是本地绑定，必须是标识符
+			self.error(reserved.Idx, "Unexpected reserved word")
+		}
+		self.semicolon()
+	case token.DEFAULT:
+		self.next()
+		node.Default = true
+		switch {
+		case self.token == token.FUNCTION || self.isAsyncFunction():
+			// 匿名的默认导出函数没有名称，但仍然是声明
+			node.Function = self.parseFunction(false)
+			self.scope.declare(&ast.FunctionDeclaration{
+				Function: node.Function,
+			})
+		case self.token == token.CLASS:
+			class := self.parseClass(false)
+			if class.Name != nil {
+				node.Declaration = &ast.ClassDeclaration{
+					Class: class,
+				}
+			} else {
+				node.Expression = class
+			}
+		default:
+			node.Expression = self.parseAssignmentExpression()
+			self.semicolon()
+		}
+	case token.VAR:
+		node.Declaration = self.parseVariableStatement()
+	case token.CONST:
+		node.Declaration = self.parseLexicalDeclaration(token.CONST)
+	case token.CLASS:
+		node.Declaration = &ast.ClassDeclaration{
+			Class: self.parseClass(true),
+		}
+	case token.FUNCTION:
+		node.Function = self.parseFunction(true)
+	default:
+		switch {
+		case self.isLetDeclaration():
+			node.Declaration = self.parseLexicalDeclaration(token.LET)
+		case self.isAsyncFunction():
+			node.Function = self.parseFunction(true)
+		default:
+			self.errorUnexpectedToken(self.token)
+			self.nextStatement()
+			return &ast.BadStatement{From: idx, To: self.idx}
+		}
+	}
+	return node
 }
 // 开始解析
 func (self *_parser) parseSourceElements() []ast.Statement {
@@ -0,0 +1,1006 @@
+package goja
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var reflectTypeProxy = reflect.TypeOf((*Proxy)(nil))
+
+// Proxy is the implementation of a JavaScript Proxy object. It can be obtained with Export() from
+// a Proxy Value or with Runtime.NewProxy().
+type Proxy struct {
+	baseObject
+	target *Object
+	// 撤销后为nil
+	handler proxyHandler
+}
+
+// ProxyTrapConfig holds the Go implementations of the proxy traps passed to Runtime.NewProxy().
+// Traps that are nil are forwarded to the target, as are all operations on Symbol-keyed properties.
+// The results of the traps are subject to the same invariant checks as the results of JavaScript traps.
+type ProxyTrapConfig struct {
+	// A trap for Object.getPrototypeOf, Reflect.getPrototypeOf, __proto__, Object.prototype.isPrototypeOf, instanceof
+	GetPrototypeOf func(target *Object) (prototype *Object)
+
+	// A trap for Reflect.setPrototypeOf
+	SetPrototypeOf func(target *Object, prototype *Object) (success bool)
+
+	// A trap for Object.isExtensible, Reflect.isExtensible
+	IsExtensible func(target *Object) (success bool)
+
+	// A trap for Object.preventExtensions, Reflect.preventExtensions
+	PreventExtensions func(target *Object) (success bool)
+
+	// A trap for Object.getOwnPropertyDescriptor, Reflect.getOwnPropertyDescriptor. Return an empty
+	// PropertyDescriptor to report the property as absent.
+	GetOwnPropertyDescriptor func(target *Object, prop string) (propertyDescriptor PropertyDescriptor)
+
+	// A trap for Object.defineProperty, Reflect.defineProperty
+	DefineProperty func(target *Object, key string, propertyDescriptor PropertyDescriptor) (success bool)
+
+	// A trap for the in operator, with operator, Reflect.has
+	Has func(target *Object, property string) (available bool)
+
+	// A trap for getting property values, Reflect.get
+	Get func(target *Object, property string, receiver Value) (value Value)
+
+	// A trap for setting property values, Reflect.set
+	Set func(target *Object, property string, value Value, receiver Value) (success bool)
+
+	// A trap for the delete operator, Reflect.deleteProperty
+	DeleteProperty func(target *Object, property string) (success bool)
+
+	// A trap for Object.getOwnPropertyNames, Object.getOwnPropertySymbols, Object.keys, Reflect.ownKeys.
+	// The result must be an array-like object of strings and symbols.
+	OwnKeys func(target *Object) (object *Object)
+
+	// A trap for a function call, Function.prototype.apply, Function.prototype.call, Reflect.apply
+	Apply func(target *Object, this Value, argumentsList []Value) (value Value)
+
+	// A trap for the new operator, Reflect.construct
+	Construct func(target *Object, argumentsList []Value, newTarget *Object) (value *Object)
+}
+
+// PropertyDescriptor describes a property for the GetOwnPropertyDescriptor and DefineProperty traps.
+// Fields that are FLAG_NOT_SET or nil are absent from the descriptor.
+type PropertyDescriptor struct {
+	Value Value
+
+	Writable, Configurable, Enumerable Flag
+
+	Getter, Setter Value
+}
+
+// Empty returns true if none of the fields of the descriptor are set.
+func (p PropertyDescriptor) Empty() bool {
+	return p == PropertyDescriptor{}
+}
+
+// 代理的处理器，第二个返回值表示是否定义了对应的trap，未定义时操作转发给target
+type proxyHandler interface {
+	getPrototypeOf(target *Object) (Value, bool)
+	setPrototypeOf(target *Object, proto *Object) (bool, bool)
+	isExtensible(target *Object) (bool, bool)
+	preventExtensions(target *Object) (bool, bool)
+	getOwnPropertyDescriptor(target *Object, prop Value) (Value, bool)
+	defineProperty(target *Object, prop Value, descr propertyDescr) (bool, bool)
+	has(target *Object, prop Value) (bool, bool)
+	get(target *Object, prop Value, receiver Value) (Value, bool)
+	set(target *Object, prop Value, value Value, receiver Value) (bool, bool)
+	deleteProperty(target *Object, prop Value) (bool, bool)
+	ownKeys(target *Object) (Value, bool)
+	apply(target *Object, this Value, args []Value) (Value, bool)
+	construct(target *Object, args []Value, newTarget *Object) (Value, bool)
+}
+
+// JS对象作为处理器，trap从处理器对象的属性中获取
+type jsProxyHandler struct {
+	handler *Object
+}
+
+// Go函数作为处理器
+type nativeProxyHandler struct {
+	r       *Runtime
+	handler *ProxyTrapConfig
+}
+
+// 获取名为name的trap，undefined或null表示未定义
+func (h *jsProxyHandler) trap(name string) func(FunctionCall) Value {
+	v := h.handler.self.getStr(name)
+	if v == nil || v == _undefined || v == _null {
+		return nil
+	}
+	if o, ok := v.(*Object); ok {
+		if call, ok := o.self.assertCallable(); ok {
+			return call
+		}
+	}
+	h.handler.runtime.typeErrorResult(true, "'%s' on proxy: trap is not a function: %s", name, v.String())
+	return nil
+}
+// 调用名为name的trap
+func (h *jsProxyHandler) call(name string, args ...Value) (Value, bool) {
+	if f := h.trap(name); f != nil {
+		return f(FunctionCall{
+			This:      h.handler,
+			Arguments: args,
+		}), true
+	}
+	return nil, false
+}
+// 调用名为name的trap，结果转换为布尔值
+func (h *jsProxyHandler) boolCall(name string, args ...Value) (bool, bool) {
+	if v, ok := h.call(name, args...); ok {
+		return v.ToBoolean(), true
+	}
+	return false, false
+}
+
+func (h *jsProxyHandler) getPrototypeOf(target *Object) (Value, bool) {
+	return h.call("getPrototypeOf", target)
+}
+
+func (h *jsProxyHandler) setPrototypeOf(target *Object, proto *Object) (bool, bool) {
+	var p Value = _null
+	if proto != nil {
+		p = proto
+	}
+	return h.boolCall("setPrototypeOf", target, p)
+}
+
+func (h *jsProxyHandler) isExtensible(target *Object) (bool, bool) {
+	return h.boolCall("isExtensible", target)
+}
+
+func (h *jsProxyHandler) preventExtensions(target *Object) (bool, bool) {
+	return h.boolCall("preventExtensions", target)
+}
+
+func (h *jsProxyHandler) getOwnPropertyDescriptor(target *Object, prop Value) (Value, bool) {
+	return h.call("getOwnPropertyDescriptor", target, prop)
+}
+
+func (h *jsProxyHandler) defineProperty(target *Object, prop Value, descr propertyDescr) (bool, bool) {
+	if f := h.trap("defineProperty"); f != nil {
+		return f(FunctionCall{
+			This:      h.handler,
+			Arguments: []Value{target, prop, h.handler.runtime.fromPropertyDescr(descr)},
+		}).ToBoolean(), true
+	}
+	return false, false
+}
+
+func (h *jsProxyHandler) has(target *Object, prop Value) (bool, bool) {
+	return h.boolCall("has", target, prop)
+}
+
+func (h *jsProxyHandler) get(target *Object, prop Value, receiver Value) (Value, bool) {
+	return h.call("get", target, prop, receiver)
+}
+
+func (h *jsProxyHandler) set(target *Object, prop Value, value Value, receiver Value) (bool, bool) {
+	return h.boolCall("set", target, prop, value, receiver)
+}
+
+func (h *jsProxyHandler) deleteProperty(target *Object, prop Value) (bool, bool) {
+	return h.boolCall("deleteProperty", target, prop)
+}
+
+func (h *jsProxyHandler) ownKeys(target *Object) (Value, bool) {
+	return h.call("ownKeys", target)
+}
+
+func (h *jsProxyHandler) apply(target *Object, this Value, args []Value) (Value, bool) {
+	return h.call("apply", target, this, h.handler.runtime.newArrayValues(args))
+}
+
+func (h *jsProxyHandler) construct(target *Object, args []Value, newTarget *Object) (Value, bool) {
+	return h.call("construct", target, h.handler.runtime.newArrayValues(args), newTarget)
+}
+// symbol键的操作不交给Go处理器
+func nativeProxyKey(prop Value) (string, bool) {
+	if _, ok := prop.(*valueSymbol); ok {
+		return "", false
+	}
+	return prop.String(), true
+}
+
+func (h *nativeProxyHandler) getPrototypeOf(target *Object) (Value, bool) {
+	if h.handler.GetPrototypeOf != nil {
+		if proto := h.handler.GetPrototypeOf(target); proto != nil {
+			return proto, true
+		}
+		return _null, true
+	}
+	return nil, false
+}
+
+func (h *nativeProxyHandler) setPrototypeOf(target *Object, proto *Object) (bool, bool) {
+	if h.handler.SetPrototypeOf != nil {
+		return h.handler.SetPrototypeOf(target, proto), true
+	}
+	return false, false
+}
+
+func (h *nativeProxyHandler) isExtensible(target *Object) (bool, bool) {
+	if h.handler.IsExtensible != nil {
+		return h.handler.IsExtensible(target), true
+	}
+	return false, false
+}
+
+func (h *nativeProxyHandler) preventExtensions(target *Object) (bool, bool) {
+	if h.handler.PreventExtensions != nil {
+		return h.handler.PreventExtensions(target), true
+	}
+	return false, false
+}
+
+func (h *nativeProxyHandler) getOwnPropertyDescriptor(target *Object, prop Value) (Value, bool) {
+	if name, ok := nativeProxyKey(prop); ok && h.handler.GetOwnPropertyDescriptor != nil {
+		descr := h.handler.GetOwnPropertyDescriptor(target, name)
+		if descr.Empty() {
+			return _undefined, true
+		}
+		return h.r.fromPropertyDescr(propertyDescr(descr)), true
+	}
+	return nil, false
+}
+
+func (h *nativeProxyHandler) defineProperty(target *Object, prop Value, descr propertyDescr) (bool, bool) {
+	if name, ok := nativeProxyKey(prop); ok && h.handler.DefineProperty != nil {
+		return h.handler.DefineProperty(target, name, PropertyDescriptor(descr)), true
+	}
+	return false, false
+}
+
+func (h *nativeProxyHandler) has(target *Object, prop Value) (bool, bool) {
+	if name, ok := nativeProxyKey(prop); ok && h.handler.Has != nil {
+		return h.handler.Has(target, name), true
+	}
+	return false, false
+}
+
+func (h *nativeProxyHandler) get(target *Object, prop Value, receiver Value) (Value, bool) {
+	if name, ok := nativeProxyKey(prop); ok && h.handler.Get != nil {
+		return nilSafe(h.handler.Get(target, name, receiver)), true
+	}
+	return nil, false
+}
+
+func (h *nativeProxyHandler) set(target *Object, prop Value, value Value, receiver Value) (bool, bool) {
+	if name, ok := nativeProxyKey(prop); ok && h.handler.Set != nil {
+		return h.handler.Set(target, name, value, receiver), true
+	}
+	return false, false
+}
+
+func (h *nativeProxyHandler) deleteProperty(target *Object, prop Value) (bool, bool) {
+	if name, ok := nativeProxyKey(prop); ok && h.handler.DeleteProperty != nil {
+		return h.handler.DeleteProperty(target, name), true
+	}
+	return false, false
+}
+
+func (h *nativeProxyHandler) ownKeys(target *Object) (Value, bool) {
+	if h.handler.OwnKeys != nil {
+		if keys := h.handler.OwnKeys(target); keys != nil {
+			return keys, true
+		}
+		return _undefined, true
+	}
+	return nil, false
+}
+
+func (h *nativeProxyHandler) apply(target *Object, this Value, args []Value) (Value, bool) {
+	if h.handler.Apply != nil {
+		return nilSafe(h.handler.Apply(target, this, args)), true
+	}
+	return nil, false
+}
+
+func (h *nativeProxyHandler) construct(target *Object, args []Value, newTarget *Object) (Value, bool) {
+	if h.handler.Construct != nil {
+		if obj := h.handler.Construct(target, args, newTarget); obj != nil {
+			return obj, true
+		}
+		return _undefined, true
+	}
+	return nil, false
+}
+
+// Target returns the target of the Proxy.
+func (p *Proxy) Target() *Object {
+	return p.target
+}
+
+// Revoke revokes the Proxy. Any subsequent operation on it throws a TypeError.
+func (p *Proxy) Revoke() {
+	p.handler = nil
+}
+// 获取处理器，已撤销时抛出TypeError
+func (p *Proxy) checkHandler(op string) proxyHandler {
+	if p.handler == nil {
+		p.val.runtime.typeErrorResult(true, "Cannot perform '%s' on a proxy that has been revoked", op)
+	}
+	return p.handler
+}
+// 获取target的自有属性，统一为valueProperty
+func (p *Proxy) targetProp(key Value) *valueProperty {
+	return toValueProperty(getOwnPropValue(p.target, key))
+}
+// 把属性值统一为valueProperty，普通值视为可写、可枚举、可删除的数据属性
+func toValueProperty(v Value) *valueProperty {
+	if v == nil {
+		return nil
+	}
+	if prop, ok := v.(*valueProperty); ok {
+		return prop
+	}
+	return &valueProperty{
+		value:        v,
+		writable:     true,
+		enumerable:   true,
+		configurable: true,
+	}
+}
+// 把属性描述符补全为valueProperty
+func completePropertyDescr(descr propertyDescr) *valueProperty {
+	prop := &valueProperty{
+		enumerable:   descr.Enumerable.Bool(),
+		configurable: descr.Configurable.Bool(),
+	}
+	if descr.Getter != nil || descr.Setter != nil {
+		prop.accessor = true
+		prop.getterFunc, _ = descr.Getter.(*Object)
+		prop.setterFunc, _ = descr.Setter.(*Object)
+	} else {
+		prop.value = nilSafe(descr.Value)
+		prop.writable = descr.Writable.Bool()
+	}
+	return prop
+}
+// 判断描述符descr能否应用于现有属性current(IsCompatiblePropertyDescriptor)
+func isCompatiblePropertyDescr(extensible bool, descr propertyDescr, current *valueProperty) bool {
+	if current == nil {
+		return extensible
+	}
+	if !current.configurable {
+		if descr.Configurable == FLAG_TRUE {
+			return false
+		}
+		if descr.Enumerable != FLAG_NOT_SET && descr.Enumerable.Bool() != current.enumerable {
+			return false
+		}
+	}
+	isData := descr.Value != nil || descr.Writable != FLAG_NOT_SET
+	isAccessor := descr.Getter != nil || descr.Setter != nil
+	if !isData && !isAccessor {
+		return true
+	}
+	if isData == current.accessor {
+		return current.configurable
+	}
+	if !current.configurable {
+		if isData {
+			if !current.writable {
+				if descr.Writable == FLAG_TRUE {
+					return false
+				}
+				if descr.Value != nil && !descr.Value.SameAs(current.value) {
+					return false
+				}
+			}
+		} else {
+			getter, _ := descr.Getter.(*Object)
+			setter, _ := descr.Setter.(*Object)
+			if descr.Getter != nil && getter != current.getterFunc || descr.Setter != nil && setter != current.setterFunc {
+				return false
+			}
+		}
+	}
+	return true
+}
+// 属性键转换为字符串或symbol
+func toPropertyKey(key Value) Value {
+	if s, ok := key.(*valueSymbol); ok {
+		return s
+	}
+	return key.ToString()
+}
+// 属性键作为map的键
+func propertyKeyId(key Value) interface{} {
+	if s, ok := key.(*valueSymbol); ok {
+		return s
+	}
+	return key.String()
+}
+// 通过get trap获取属性值，receiver为getter的this
+func (p *Proxy) proxyGet(key, receiver Value) Value {
+	h := p.checkHandler("get")
+	if v, ok := h.get(p.target, key, receiver); ok {
+		if prop := p.targetProp(key); prop != nil && !prop.configurable {
+			if !prop.accessor {
+				if !prop.writable && !v.SameAs(prop.value) {
+					p.val.runtime.typeErrorResult(true, "'get' on proxy: property '%s' is a read-only and non-configurable data property on the proxy target but the proxy did not return its actual value", key.String())
+				}
+			} else if prop.getterFunc == nil && v != _undefined {
+				p.val.runtime.typeErrorResult(true, "'get' on proxy: property '%s' is a non-configurable accessor property on the proxy target and does not have a getter function, but the trap did not return 'undefined'", key.String())
+			}
+		}
+		return v
+	}
+	return p.val.runtime.getWithReceiver(p.target, key, receiver)
+}
+// 通过set trap设置属性值
+func (p *Proxy) proxySet(key, value, receiver Value, throw bool) bool {
+	h := p.checkHandler("set")
+	if ok, present := h.set(p.target, key, value, receiver); present {
+		if !ok {
+			p.val.runtime.typeErrorResult(throw, "'set' on proxy: trap returned falsish for property '%s'", key.String())
+			return false
+		}
+		if prop := p.targetProp(key); prop != nil && !prop.configurable {
+			if !prop.accessor {
+				if !prop.writable && !value.SameAs(prop.value) {
+					p.val.runtime.typeErrorResult(true, "'set' on proxy: trap returned truish for property '%s' which exists in the proxy target as a non-configurable and non-writable data property with a different value", key.String())
+				}
+			} else if prop.setterFunc == nil {
+				p.val.runtime.typeErrorResult(true, "'set' on proxy: trap returned truish for property '%s' which exists in the proxy target as a non-configurable and non-writable accessor property without a setter", key.String())
+			}
+		}
+		return true
+	}
+	if !p.val.runtime.setWithReceiver(p.target, key, value, receiver) {
+		p.val.runtime.typeErrorResult(throw, "Cannot assign to read only property '%s'", key.String())
+		return false
+	}
+	return true
+}
+// 通过has trap判断属性是否存在
+func (p *Proxy) proxyHas(key Value) bool {
+	h := p.checkHandler("has")
+	if b, ok := h.has(p.target, key); ok {
+		if !b {
+			if prop := p.targetProp(key); prop != nil {
+				if !prop.configurable {
+					p.val.runtime.typeErrorResult(true, "'has' on proxy: trap returned falsish for property '%s' which exists in the proxy target as non-configurable", key.String())
+				}
+				if !p.target.self.isExtensible() {
+					p.val.runtime.typeErrorResult(true, "'has' on proxy: trap returned falsish for property '%s' but the proxy target is not extensible", key.String())
+				}
+			}
+		}
+		return b
+	}
+	return p.target.self.hasProperty(key)
+}
+// 通过getOwnPropertyDescriptor trap获取自有属性，不存在时返回nil
+func (p *Proxy) proxyGetOwnProp(key Value) Value {
+	h := p.checkHandler("getOwnPropertyDescriptor")
+	v, ok := h.getOwnPropertyDescriptor(p.target, key)
+	if !ok {
+		return getOwnPropValue(p.target, key)
+	}
+	r := p.val.runtime
+	targetProp := p.targetProp(key)
+	if v == _undefined {
+		if targetProp != nil {
+			if !targetProp.configurable {
+				r.typeErrorResult(true, "'getOwnPropertyDescriptor' on proxy: trap returned undefined for property '%s' which is non-configurable in the proxy target", key.String())
+			}
+			if !p.target.self.isExtensible() {
+				r.typeErrorResult(true, "'getOwnPropertyDescriptor' on proxy: trap returned undefined for property '%s' which exists in the non-extensible proxy target", key.String())
+			}
+		}
+		return nil
+	}
+	if _, ok := v.(*Object); !ok {
+		r.typeErrorResult(true, "'getOwnPropertyDescriptor' on proxy: trap returned neither object nor undefined for property '%s'", key.String())
+	}
+	descr := r.toPropertyDescr(v)
+	if !isCompatiblePropertyDescr(p.target.self.isExtensible(), descr, targetProp) {
+		r.typeErrorResult(true, "'getOwnPropertyDescriptor' on proxy: trap returned descriptor for property '%s' that is incompatible with the existing property in the proxy target", key.String())
+	}
+	prop := completePropertyDescr(descr)
+	if !prop.configurable && (targetProp == nil || targetProp.configurable) {
+		r.typeErrorResult(true, "'getOwnPropertyDescriptor' on proxy: trap reported non-configurability for property '%s' which is either non-existent or configurable in the proxy target", key.String())
+	}
+	return prop
+}
+// 通过defineProperty trap定义属性
+func (p *Proxy) proxyDefineOwnProperty(key Value, descr propertyDescr, throw bool) bool {
+	h := p.checkHandler("defineProperty")
+	b, ok := h.defineProperty(p.target, key, descr)
+	if !ok {
+		return p.target.self.defineOwnProperty(key, descr, throw)
+	}
+	r := p.val.runtime
+	if !b {
+		r.typeErrorResult(throw, "'defineProperty' on proxy: trap returned falsish for property '%s'", key.String())
+		return false
+	}
+	targetProp := p.targetProp(key)
+	settingConfigFalse := descr.Configurable == FLAG_FALSE
+	if targetProp == nil {
+		if !p.target.self.isExtensible() {
+			r.typeErrorResult(true, "'defineProperty' on proxy: trap returned truish for adding property '%s' to the non-extensible proxy target", key.String())
+		}
+		if settingConfigFalse {
+			r.typeErrorResult(true, "'defineProperty' on proxy: trap returned truish for defining non-configurable property '%s' which is non-existent in the proxy target", key.String())
+		}
+	} else {
+		if !isCompatiblePropertyDescr(p.target.self.isExtensible(), descr, targetProp) {
+			r.typeErrorResult(true, "'defineProperty' on proxy: trap returned truish for adding property '%s' that is incompatible with the existing property in the proxy target", key.String())
+		}
+		if settingConfigFalse && targetProp.configurable {
+			r.typeErrorResult(true, "'defineProperty' on proxy: trap returned truish for defining non-configurable property '%s' which is configurable in the proxy target", key.String())
+		}
+	}
+	return true
+}
+// 通过deleteProperty trap删除属性
+func (p *Proxy) proxyDelete(key Value, throw bool) bool {
+	h := p.checkHandler("deleteProperty")
+	b, ok := h.deleteProperty(p.target, key)
+	if !ok {
+		return p.target.self.delete(key, throw)
+	}
+	r := p.val.runtime
+	if !b {
+		r.typeErrorResult(throw, "'deleteProperty' on proxy: trap returned falsish for property '%s'", key.String())
+		return false
+	}
+	if prop := p.targetProp(key); prop != nil {
+		if !prop.configurable {
+			r.typeErrorResult(true, "'deleteProperty' on proxy: trap returned truish for property '%s' which is non-configurable in the proxy target", key.String())
+		}
+		if !p.target.self.isExtensible() {
+			r.typeErrorResult(true, "'deleteProperty' on proxy: trap returned truish for property '%s' but the proxy target is non-extensible", key.String())
+		}
+	}
+	return true
+}
+// 通过ownKeys trap获取所有自有属性键，包括symbol
+func (p *Proxy) ownKeys() []Value {
+	h := p.checkHandler("ownKeys")
+	v, ok := h.ownKeys(p.target)
+	if !ok {
+		return ownKeys(p.target)
+	}
+	r := p.val.runtime
+	obj, ok := v.(*Object)
+	if !ok {
+		r.typeErrorResult(true, "CreateListFromArrayLike called on non-object")
+	}
+	l := toLength(obj.self.getStr("length"))
+	keys := make([]Value, 0, l)
+	unchecked := make(map[interface{}]bool, l)
+	for i := int64(0); i < l; i++ {
+		key := nilSafe(obj.self.get(intToValue(i)))
+		switch key.(type) {
+		case valueString, *valueSymbol:
+		default:
+			r.typeErrorResult(true, "%s is not a valid property name", key.String())
+		}
+		id := propertyKeyId(key)
+		if unchecked[id] {
+			r.typeErrorResult(true, "'ownKeys' on proxy: trap returned duplicate entries")
+		}
+		unchecked[id] = true
+		keys = append(keys, key)
+	}
+
+	extensible := p.target.self.isExtensible()
+	var configurableKeys, nonConfigurableKeys []Value
+	for _, key := range ownKeys(p.target) {
+		if prop := p.targetProp(key); prop != nil && !prop.configurable {
+			nonConfigurableKeys = append(nonConfigurableKeys, key)
+		} else {
+			configurableKeys = append(configurableKeys, key)
+		}
+	}
+	if extensible && len(nonConfigurableKeys) == 0 {
+		return keys
+	}
+	for _, key := range nonConfigurableKeys {
+		id := propertyKeyId(key)
+		if !unchecked[id] {
+			r.typeErrorResult(true, "'ownKeys' on proxy: trap result did not include '%s'", key.String())
+		}
+		delete(unchecked, id)
+	}
+	if extensible {
+		return keys
+	}
+	for _, key := range configurableKeys {
+		id := propertyKeyId(key)
+		if !unchecked[id] {
+			r.typeErrorResult(true, "'ownKeys' on proxy: trap result did not include '%s'", key.String())
+		}
+		delete(unchecked, id)
+	}
+	if len(unchecked) > 0 {
+		r.typeErrorResult(true, "'ownKeys' on proxy: trap returned extra keys but proxy target is non-extensible")
+	}
+	return keys
+}
+// 对象的所有自有属性键，字符串在前symbol在后
+func ownKeys(o *Object) []Value {
+	if p, ok := o.self.(*Proxy); ok {
+		return p.ownKeys()
+	}
+	var keys []Value
+	for item, f := o.self.enumerate(true, false)(); f != nil; item, f = f() {
+		keys = append(keys, newStringValue(item.name))
+	}
+	return append(keys, o.self.ownSymbols()...)
+}
+// 通过apply trap调用
+func (p *Proxy) apply(call FunctionCall) Value {
+	h := p.checkHandler("apply")
+	if v, ok := h.apply(p.target, call.This, call.Arguments); ok {
+		return v
+	}
+	return p.val.runtime.toCallable(p.target)(call)
+}
+// 通过construct trap构造对象
+func (p *Proxy) construct(args []Value, newTarget *Object) *Object {
+	h := p.checkHandler("construct")
+	if v, ok := h.construct(p.target, args, newTarget); ok {
+		if obj, ok := v.(*Object); ok {
+			return obj
+		}
+		p.val.runtime.typeErrorResult(true, "'construct' on proxy: trap returned non-object ('%s')", v.String())
+	}
+	return p.val.runtime.constructWith(p.target, args, newTarget)
+}
+
+func (p *Proxy) className() string {
+	if isArray(p.target) {
+		return classArray
+	}
+	if _, ok := p.target.self.assertCallable(); ok {
+		return classFunction
+	}
+	return classObject
+}
+
+func (p *Proxy) get(n Value) Value {
+	return p.proxyGet(toPropertyKey(n), p.val)
+}
+
+func (p *Proxy) getStr(name string) Value {
+	return p.proxyGet(newStringValue(name), p.val)
+}
+// 值为undefined时再用has trap确认属性是否存在，使原型链上的代理也能用于in运算
+func (p *Proxy) getProp(n Value) Value {
+	key := toPropertyKey(n)
+	v := p.proxyGet(key, p.val)
+	if v == _undefined && !p.proxyHas(key) {
+		return nil
+	}
+	return v
+}
+
+func (p *Proxy) getPropStr(name string) Value {
+	return p.getProp(newStringValue(name))
+}
+
+func (p *Proxy) getOwnProp(name string) Value {
+	return p.proxyGetOwnProp(newStringValue(name))
+}
+
+func (p *Proxy) getOwnPropSym(s *valueSymbol) Value {
+	return p.proxyGetOwnProp(s)
+}
+
+func (p *Proxy) put(n Value, val Value, throw bool) {
+	p.proxySet(toPropertyKey(n), val, p.val, throw)
+}
+
+func (p *Proxy) putStr(name string, val Value, throw bool) {
+	p.proxySet(newStringValue(name), val, p.val, throw)
+}
+
+func (p *Proxy) hasProperty(n Value) bool {
+	return p.proxyHas(toPropertyKey(n))
+}
+
+func (p *Proxy) hasPropertyStr(name string) bool {
+	return p.proxyHas(newStringValue(name))
+}
+
+func (p *Proxy) hasOwnProperty(n Value) bool {
+	return p.proxyGetOwnProp(toPropertyKey(n)) != nil
+}
+
+func (p *Proxy) hasOwnPropertyStr(name string) bool {
+	return p.proxyGetOwnProp(newStringValue(name)) != nil
+}
+
+func (p *Proxy) _putProp(name string, value Value, writable, enumerable, configurable bool) Value {
+	return p.target.self._putProp(name, value, writable, enumerable, configurable)
+}
+
+func (p *Proxy) _putSym(s *valueSymbol, value Value, writable, enumerable, configurable bool) Value {
+	return p.target.self._putSym(s, value, writable, enumerable, configurable)
+}
+
+func (p *Proxy) defineOwnProperty(n Value, descr propertyDescr, throw bool) bool {
+	return p.proxyDefineOwnProperty(toPropertyKey(n), descr, throw)
+}
+
+func (p *Proxy) assertCallable() (func(FunctionCall) Value, bool) {
+	if _, ok := p.target.self.assertCallable(); ok {
+		return p.apply, true
+	}
+	return nil, false
+}
+
+func (p *Proxy) deleteStr(name string, throw bool) bool {
+	return p.proxyDelete(newStringValue(name), throw)
+}
+
+func (p *Proxy) delete(n Value, throw bool) bool {
+	return p.proxyDelete(toPropertyKey(n), throw)
+}
+
+func (p *Proxy) proto() *Object {
+	h := p.checkHandler("getPrototypeOf")
+	v, ok := h.getPrototypeOf(p.target)
+	if !ok {
+		return p.target.self.proto()
+	}
+	var proto *Object
+	if v != _null {
+		if o, ok := v.(*Object); ok {
+			proto = o
+		} else {
+			p.val.runtime.typeErrorResult(true, "'getPrototypeOf' on proxy: trap returned neither object nor null")
+		}
+	}
+	if !p.target.self.isExtensible() && proto != p.target.self.proto() {
+		p.val.runtime.typeErrorResult(true, "'getPrototypeOf' on proxy: proxy target is non-extensible but the trap did not return its actual prototype")
+	}
+	return proto
+}
+
+func (p *Proxy) setProto(proto *Object, throw bool) bool {
+	h := p.checkHandler("setPrototypeOf")
+	b, ok := h.setPrototypeOf(p.target, proto)
+	if !ok {
+		return p.target.self.setProto(proto, throw)
+	}
+	if !b {
+		p.val.runtime.typeErrorResult(throw, "'setPrototypeOf' on proxy: trap returned falsish")
+		return false
+	}
+	if !p.target.self.isExtensible() && proto != p.target.self.proto() {
+		p.val.runtime.typeErrorResult(true, "'setPrototypeOf' on proxy: trap returned truish for setting a new prototype on the non-extensible proxy target")
+	}
+	return true
+}
+
+func (p *Proxy) isExtensible() bool {
+	h := p.checkHandler("isExtensible")
+	b, ok := h.isExtensible(p.target)
+	if !ok {
+		return p.target.self.isExtensible()
+	}
+	if b != p.target.self.isExtensible() {
+		p.val.runtime.typeErrorResult(true, "'isExtensible' on proxy: trap result does not reflect extensibility of proxy target (which is '%v')", !b)
+	}
+	return b
+}
+
+func (p *Proxy) preventExtensions(throw bool) bool {
+	h := p.checkHandler("preventExtensions")
+	b, ok := h.preventExtensions(p.target)
+	if !ok {
+		return p.target.self.preventExtensions(throw)
+	}
+	if !b {
+		p.val.runtime.typeErrorResult(throw, "'preventExtensions' on proxy: trap returned falsish")
+		return false
+	}
+	if p.target.self.isExtensible() {
+		p.val.runtime.typeErrorResult(true, "'preventExtensions' on proxy: trap returned truish but the proxy target is extensible")
+	}
+	return true
+}
+
+// 代理的属性迭代器，可枚举性由getOwnPropertyDescriptor trap决定
+type proxyPropIter struct {
+	p         *Proxy
+	names     []Value
+	all       bool
+	recursive bool
+	idx       int
+}
+
+func (i *proxyPropIter) next() (propIterItem, iterNextFunc) {
+	for i.idx < len(i.names) {
+		name := i.names[i.idx]
+		i.idx++
+		if i.all {
+			return propIterItem{name: name.String(), enumerable: _ENUM_TRUE}, i.next
+		}
+		prop := i.p.proxyGetOwnProp(name)
+		if prop == nil {
+			continue
+		}
+		enumerable := _ENUM_FALSE
+		if toValueProperty(prop).enumerable {
+			enumerable = _ENUM_TRUE
+		}
+		return propIterItem{name: name.String(), enumerable: enumerable}, i.next
+	}
+
+	if i.recursive {
+		if proto := i.p.proto(); proto != nil {
+			return proto.self._enumerate(i.recursive)()
+		}
+	}
+	return propIterItem{}, nil
+}
+// 字符串属性键对应的迭代器
+func (p *Proxy) propIter(all, recursive bool) iterNextFunc {
+	var names []Value
+	for _, key := range p.ownKeys() {
+		if _, ok := key.(*valueSymbol); !ok {
+			names = append(names, key)
+		}
+	}
+	return (&proxyPropIter{
+		p:         p,
+		names:     names,
+		all:       all,
+		recursive: recursive,
+	}).next
+}
+
+func (p *Proxy) enumerate(all, recursive bool) iterNextFunc {
+	return (&propFilterIter{
+		wrapped: p.propIter(all, recursive),
+		all:     all,
+		seen:    make(map[string]bool),
+	}).next
+}
+
+func (p *Proxy) _enumerate(recursive bool) iterNextFunc {
+	return p.propIter(false, recursive)
+}
+
+func (p *Proxy) ownSymbols() []Value {
+	var res []Value
+	for _, key := range p.ownKeys() {
+		if _, ok := key.(*valueSymbol); ok {
+			res = append(res, key)
+		}
+	}
+	return res
+}
+// 可调用的代理按prototype属性判断instanceof
+func (p *Proxy) hasInstance(v Value) bool {
+	if _, ok := p.assertCallable(); !ok {
+		return p.baseObject.hasInstance(v)
+	}
+	if v, ok := v.(*Object); ok {
+		proto, ok := p.getStr("prototype").(*Object)
+		if !ok {
+			p.val.runtime.typeErrorResult(true, "prototype is not an object")
+		}
+		for {
+			v = v.self.proto()
+			if v == nil {
+				return false
+			}
+			if v == proto {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *Proxy) export() interface{} {
+	return p
+}
+
+func (p *Proxy) exportType() reflect.Type {
+	return reflectTypeProxy
+}
+// 创建代理对象
+func (r *Runtime) newProxyObject(target *Object, handler proxyHandler) *Proxy {
+	v := &Object{runtime: r}
+	p := &Proxy{
+		target:  target,
+		handler: handler,
+	}
+	p.class = classObject
+	p.val = v
+	p.extensible = true
+	v.self = p
+	p.init()
+	return p
+}
+
+// NewProxy creates a new Proxy for target whose traps are implemented by the functions in handler.
+func (r *Runtime) NewProxy(target *Object, handler *ProxyTrapConfig) *Proxy {
+	if handler == nil {
+		panic(fmt.Errorf("NewProxy: handler is nil"))
+	}
+	return r.newProxyObject(target, &nativeProxyHandler{r: r, handler: handler})
+}
+// 用newTarget构造对象，newTarget决定新对象的原型
+func (r *Runtime) constructWith(c *Object, args []Value, newTarget *Object) *Object {
+	switch f := c.self.(type) {
+	case *funcObject:
+		return f.constructWith(args, newTarget)
+	case *Proxy:
+		return f.construct(args, newTarget)
+	}
+	obj := r.builtin_new(c, args)
+	if newTarget != c {
+		// 内置的构造函数不知道newTarget，需要修正原型
+		if proto, ok := newTarget.self.getStr("prototype").(*Object); ok {
+			obj.self.setProto(proto, true)
+		}
+	}
+	return obj
+}
+// 获取属性值，receiver作为getter的this
+func (r *Runtime) getWithReceiver(o *Object, key, receiver Value) Value {
+	if p, ok := o.self.(*Proxy); ok {
+		return p.proxyGet(key, receiver)
+	}
+	prop := o.self.getProp(key)
+	if prop, ok := prop.(*valueProperty); ok {
+		return prop.get(receiver)
+	}
+	return nilSafe(prop)
+}
+// 设置属性值，receiver作为setter的this，数据属性定义在receiver上。失败时返回false
+func (r *Runtime) setWithReceiver(o *Object, key, value, receiver Value) bool {
+	if p, ok := o.self.(*Proxy); ok {
+		return p.proxySet(key, value, receiver, false)
+	}
+	prop := o.self.getProp(key)
+	if prop, ok := prop.(*valueProperty); ok {
+		if prop.accessor {
+			if prop.setterFunc == nil {
+				return false
+			}
+			prop.set(receiver, value)
+			return true
+		}
+		if !prop.writable {
+			return false
+		}
+	}
+	if receiver == o {
+		if prop == nil && !o.self.isExtensible() {
+			return false
+		}
+		o.self.put(key, value, false)
+		return true
+	}
+	recv, ok := receiver.(*Object)
+	if !ok {
+		return false
+	}
+	if existing := toValueProperty(getOwnPropValue(recv, key)); existing != nil {
+		if existing.accessor || !existing.writable {
+			return false
+		}
+		return recv.self.defineOwnProperty(key, propertyDescr{Value: value}, false)
+	}
+	return recv.self.defineOwnProperty(key, propertyDescr{
+		Value:        value,
+		Writable:     FLAG_TRUE,
+		Enumerable:   FLAG_TRUE,
+		Configurable: FLAG_TRUE,
+	}, false)
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"github.com/dlclark/regexp2"
 	"regexp"
-	"unicode/utf16"
 	"unicode/utf8"
 )
 
@@ -14,28 +13,61 @@ type regexpPattern interface {
 	FindAllSubmatchIndexUTF8(string, int) [][]int
 	FindAllSubmatchIndexASCII(string, int) [][]int
 	MatchString(valueString) bool
+	groupNames() []string
 }
 
-type regexp2Wrapper regexp2.Regexp
+type regexp2Wrapper struct {
+	rx    *regexp2.Regexp
+	names []string // 命名捕获组的组名，按组编号排列
+}
 type regexpWrapper regexp.Regexp
 
+// 正则表达式标志
+type regexpFlags struct {
+	global, multiline, ignoreCase, dotAll, sticky, unicode bool
+}
+
 type regexpObject struct {
 	baseObject
 	pattern regexpPattern
 	source  valueString
 
-	global, multiline, ignoreCase bool
+	regexpFlags
 }
-//在输入字符串中搜索正则表达式匹配项
+// 将UTF-16字符串解码为rune，posMap记录每个rune在UTF-16中的位置
+func decodeUTF16Runes(s unicodeString) (runes []rune, posMap []int) {
+	rd := runeReaderReplace{s.reader(0)}
+	posMap = make([]int, 0, s.length()+1)
+	runes = make([]rune, 0, s.length())
+	curPos := 0
+	for {
+		rn, size, err := rd.ReadRune()
+		if err != nil {
+			break
+		}
+		runes = append(runes, rn)
+		posMap = append(posMap, curPos)
+		curPos += size
+	}
+	posMap = append(posMap, curPos)
+	return
+}
+//在输入字符串中从start处开始搜索正则表达式匹配项，返回的位置相对于start
 func (r *regexp2Wrapper) FindSubmatchIndex(s valueString, start int) (result []int) {
-	wrapped := (*regexp2.Regexp)(r)
 	var match *regexp2.Match
 	var err error
+	var posMap []int
 	switch s := s.(type) {
 	case asciiString:
-		match, err = wrapped.FindStringMatch(string(s)[start:])
+		match, err = r.rx.FindStringMatchStartingAt(string(s), start)
 	case unicodeString:
-		match, err = wrapped.FindRunesMatch(utf16.Decode(s[start:]))
+		var runes []rune
+		runes, posMap = decodeUTF16Runes(s)
+		runeStart := 0
+		for runeStart < len(runes) && posMap[runeStart] < start {
+			runeStart++
+		}
+		match, err = r.rx.FindRunesMatchStartingAt(runes, runeStart)
 	default:
 		panic(fmt.Errorf("Unknown string type: %T", s))
 	}
@@ -51,16 +83,24 @@ func (r *regexp2Wrapper) FindSubmatchIndex(s valueString, start int) (result []i
 	result = make([]int, 0, len(groups)<<1)
 	for _, group := range groups {
 		if len(group.Captures) > 0 {
-			result = append(result, group.Index, group.Index+group.Length)
+			begin, end := group.Index, group.Index+group.Length
+			if posMap != nil {
+				begin, end = posMap[begin], posMap[end]
+			}
+			result = append(result, begin-start, end-start)
 		} else {
 			result = append(result, -1, 0)
 		}
 	}
 	return
 }
+// 返回命名捕获组的组名
+func (r *regexp2Wrapper) groupNames() []string {
+	return r.names
+}
 //在输入字符串中搜索正则表达式匹配项
 func (r *regexp2Wrapper) FindAllSubmatchIndexUTF8(s string, n int) [][]int {
-	wrapped := (*regexp2.Regexp)(r)
+	wrapped := r.rx
 	if n < 0 {
 		n = len(s) + 1
 	}
@@ -103,7 +143,7 @@ func (r *regexp2Wrapper) FindAllSubmatchIndexUTF8(s string, n int) [][]int {
 }
 //在输入字符串中搜索正则表达式匹配项
 func (r *regexp2Wrapper) FindAllSubmatchIndexASCII(s string, n int) [][]int {
-	wrapped := (*regexp2.Regexp)(r)
+	wrapped := r.rx
 	if n < 0 {
 		n = len(s) + 1
 	}
@@ -138,28 +178,13 @@ func (r *regexp2Wrapper) FindAllSubmatchIndexASCII(s string, n int) [][]int {
 }
 //在输入字符串中搜索正则表达式匹配项
 func (r *regexp2Wrapper) findAllSubmatchIndexUTF16(s unicodeString, n int) [][]int {
-	wrapped := (*regexp2.Regexp)(r)
+	wrapped := r.rx
 	if n < 0 {
 		n = len(s) + 1
 	}
 	results := make([][]int, 0, n)
 
-	rd := runeReaderReplace{s.reader(0)}
-	posMap := make([]int, s.length()+1)
-	curPos := 0
-	curRuneIdx := 0
-	runes := make([]rune, 0, s.length())
-	for {
-		rn, size, err := rd.ReadRune()
-		if err != nil {
-			break
-		}
-		runes = append(runes, rn)
-		posMap[curRuneIdx] = curPos
-		curRuneIdx++
-		curPos += size
-	}
-	posMap[curRuneIdx] = curPos
+	runes, posMap := decodeUTF16Runes(s)
 
 	match, err := wrapped.FindRunesMatch(runes)
 	if err != nil {
@@ -201,14 +226,15 @@ func (r *regexp2Wrapper) FindAllSubmatchIndex(s valueString, n int) [][]int {
 }
 //如果字符串与正则表达式匹配，则MatchString返回true，如果发生超时则将设置错误
 func (r *regexp2Wrapper) MatchString(s valueString) bool {
-	wrapped := (*regexp2.Regexp)(r)
+	wrapped := r.rx
 
 	switch s := s.(type) {
 	case asciiString:
 		matched, _ := wrapped.MatchString(string(s))
 		return matched
 	case unicodeString:
-		matched, _ := wrapped.MatchRunes(utf16.Decode(s))
+		runes, _ := decodeUTF16Runes(s)
+		matched, _ := wrapped.MatchRunes(runes)
 		return matched
 	default:
 		panic(fmt.Errorf("Unknown string type: %T", s))
@@ -220,6 +246,16 @@ func (r *regexpWrapper) FindSubmatchIndex(s valueString, start int) (result []in
 	wrapped := (*regexp.Regexp)(r)
 	return wrapped.FindReaderSubmatchIndex(runeReaderReplace{s.reader(start)})
 }
+// 返回命名捕获组的组名，没有命名捕获组时返回nil
+func (r *regexpWrapper) groupNames() []string {
+	names := (*regexp.Regexp)(r).SubexpNames()
+	for _, name := range names {
+		if name != "" {
+			return names
+		}
+	}
+	return nil
+}
 // MatchReader报告RuneReader返回的文本是否包含正则表达式的任何匹配项。
 func (r *regexpWrapper) MatchString(s valueString) bool {
 	wrapped := (*regexp.Regexp)(r)
@@ -285,12 +321,10 @@ func (r *regexpObject) execResultToArray(target valueString, result []int) Value
 	captureCount := len(result) >> 1
 	valueArray := make([]Value, captureCount)
 	matchIndex := result[0]
-	lowerBound := matchIndex
 	for index := 0; index < captureCount; index++ {
 		offset := index << 1
-		if result[offset] >= lowerBound {
+		if result[offset] >= 0 {
 			valueArray[index] = target.substring(int64(result[offset]), int64(result[offset+1]))
-			lowerBound = result[offset]
 		} else {
 			valueArray[index] = _undefined
 		}
@@ -298,6 +332,17 @@ func (r *regexpObject) execResultToArray(target valueString, result []int) Value
 	match := r.val.runtime.newArrayValues(valueArray)
 	match.self.putStr("input", target, false)
 	match.self.putStr("index", intToValue(int64(matchIndex)), false)
+	var groups Value = _undefined
+	if names := r.pattern.groupNames(); names != nil {
+		o := r.val.runtime.newBaseObject(nil, classObject)
+		for i, name := range names {
+			if name != "" && i < captureCount {
+				o._putProp(name, valueArray[i], true, true, true)
+			}
+		}
+		groups = o.val
+	}
+	match.self.putStr("groups", groups, false)
 	return match
 }
 // 执行正则表达式匹配
@@ -310,25 +355,28 @@ func (r *regexpObject) execRegexp(target valueString) (match bool, result []int)
 		}
 	}
 	index := lastIndex
-	if !r.global {
+	if !r.global && !r.sticky {
 		index = 0
 	}
 	if index >= 0 && index <= target.length() {
 		result = r.pattern.FindSubmatchIndex(target, int(index))
 	}
-	if result == nil {
+	// sticky时匹配必须从lastIndex处开始
+	if result == nil || r.sticky && result[0] != 0 {
 		r.putStr("lastIndex", intToValue(0), true)
-		return
+		return false, nil
 	}
 	match = true
 	startIndex := index
-	endIndex := int(lastIndex) + result[1]
+	endIndex := int(startIndex) + result[1]
 	// We do this shift here because the .FindStringSubmatchIndex above
 	// was done on a local subordinate slice of the string, not the whole string
 	for index, _ := range result {
-		result[index] += int(startIndex)
+		if result[index] >= 0 {
+			result[index] += int(startIndex)
+		}
 	}
-	if r.global {
+	if r.global || r.sticky {
 		r.putStr("lastIndex", intToValue(int64(endIndex)), true)
 	}
 	return
@@ -351,9 +399,7 @@ func (r *regexpObject) clone() *Object {
 	r1 := r.val.runtime.newRegexpObject(r.prototype)
 	r1.source = r.source
 	r1.pattern = r.pattern
-	r1.global = r.global
-	r1.ignoreCase = r.ignoreCase
-	r1.multiline = r.multiline
+	r1.regexpFlags = r.regexpFlags
 	return r1.val
 }
 // 初始化
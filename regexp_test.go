@@ -190,6 +190,239 @@ func TestEscapeNonASCII(t *testing.T) {
 	testScript1(SCRIPT, valueTrue, t)
 }
 
+func TestRegexpSticky(t *testing.T) {
+	const SCRIPT = `
+	var re = /a/y;
+	assert.sameValue(re.sticky, true, "sticky");
+	assert.sameValue(re.test("ba"), false, "no match at 0");
+	assert.sameValue(re.lastIndex, 0, "lastIndex reset");
+	re.lastIndex = 1;
+	assert.sameValue(re.test("ba"), true, "match at 1");
+	assert.sameValue(re.lastIndex, 2, "lastIndex advanced");
+	var re2 = /\d+/y;
+	re2.lastIndex = 2;
+	var m = re2.exec("ab12cd34");
+	assert.sameValue(m[0], "12");
+	assert.sameValue(m.index, 2);
+	assert.sameValue(re2.exec("ab12cd34"), null, "no skipping ahead");
+	assert.sameValue(re2.lastIndex, 0);
+	var re3 = /^b/y;
+	re3.lastIndex = 1;
+	assert.sameValue(re3.test("ab"), false, "^ anchors to input start");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestRegexpUnicode(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(/^\u{1F600}$/u.test("😀"), true, "code point escape");
+	assert.sameValue(/^😀$/u.test("😀"), true, "surrogate pair escape");
+	assert.sameValue(/^.$/u.test("😀"), true, "dot matches a code point");
+	assert.sameValue(/^[\u{1F600}-\u{1F64F}]+$/u.test("😀🙏"), true, "code point range");
+	var m = /(\u{1F600})(x)/u.exec("a😀x");
+	assert.sameValue(m.index, 1);
+	assert.sameValue(m[2], "x");
+	var re = /(?<=\u{1F600})x/gu;
+	re.lastIndex = 1;
+	assert.sameValue(re.exec("a😀x").index, 3, "indices are UTF-16 based");
+	assert.sameValue(re.lastIndex, 4);
+	assert.sameValue(/x/u.unicode, true);
+	assert.throws(SyntaxError, function() { new RegExp("a", "uu"); });
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestRegexpDotAll(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(/^.$/.test("\n"), false);
+	assert.sameValue(/^.$/s.test("\n"), true);
+	assert.sameValue(/^a.b$/s.test("a\rb"), true);
+	assert.sameValue(/(?=a).$/s.test("a"), true, "regexp2 fallback");
+	assert.sameValue(/^[.]$/s.test("\n"), false, "dot in class is literal");
+	assert.sameValue(/a/s.dotAll, true);
+	assert.sameValue(/a/.dotAll, false);
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestRegexpNamedGroups(t *testing.T) {
+	const SCRIPT = `
+	var m = /(?<year>\d{4})-(?<month>\d{2})/.exec("on 2020-05");
+	assert.sameValue(m.groups.year, "2020");
+	assert.sameValue(m.groups.month, "05");
+	assert.sameValue(m[1], "2020");
+	assert.sameValue(Object.getPrototypeOf(m.groups), null);
+	assert.sameValue(/(a)/.exec("a").groups, undefined);
+
+	m = /(x)(?<n>y)(z)?/.exec("xy");
+	assert.sameValue(m[2], "y", "numbered in order");
+	assert.sameValue(m[3], undefined);
+	assert.sameValue(m.groups.n, "y");
+
+	m = /(?<q>['"]).*?\k<q>/.exec("say 'hi' now");
+	assert.sameValue(m[0], "'hi'", "named backreference");
+	assert.sameValue(m.groups.q, "'");
+
+	m = /(?<$d>\d)/.exec("a1");
+	assert.sameValue(m.groups.$d, "1", "non-re2 group name");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestRegexpLookbehind(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(/(?<=\$)\d+/.exec("cost $42")[0], "42");
+	assert.sameValue(/(?<!\$)\b\d+/.exec("$4 and 7")[0], "7");
+	var m = /(?<=(a))b/.exec("ab");
+	assert.sameValue(m[1], "a", "capture inside lookbehind");
+	var re = /(?<=a)b/g;
+	re.lastIndex = 1;
+	assert.sameValue(re.test("ab"), true, "lookbehind sees text before lastIndex");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestRegexpFlags(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(/a/ymsuig.flags, "gimsuy");
+	assert.sameValue(/a/.flags, "");
+	assert.sameValue(String(new RegExp("a", "yg")), "/a/gy");
+	var re = new RegExp(/(?<x>a)/su);
+	assert.sameValue(re.flags, "su", "clone keeps flags");
+	assert.sameValue(re.exec("a").groups.x, "a");
+	assert.throws(SyntaxError, function() { new RegExp("a", "x"); });
+	assert.throws(SyntaxError, function() { new RegExp("a", "ss"); });
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestRegexpFlagsGeneric(t *testing.T) {
+	const SCRIPT = `
+	var getFlags = Object.getOwnPropertyDescriptor(RegExp.prototype, "flags").get;
+	assert.sameValue(getFlags.call({global: true, sticky: 1, unicode: 0}), "gy");
+	assert.sameValue(getFlags.call({}), "");
+	assert.throws(TypeError, function() { getFlags.call("g"); });
+
+	var re = /a/m;
+	Object.defineProperty(re, "global", {value: true});
+	assert.sameValue(re.flags, "gm", "own global property");
+	assert.sameValue(RegExp.prototype.toString.call({source: "x", flags: "y"}), "/x/y");
+
+	assert.sameValue(RegExp.prototype.flags, "");
+	assert.sameValue(RegExp.prototype.source, "(?:)");
+	assert.sameValue(RegExp.prototype.toString(), "/(?:)/");
+	["global", "ignoreCase", "multiline", "dotAll", "unicode", "sticky"].forEach(function(name) {
+		assert.sameValue(RegExp.prototype[name], undefined, name);
+		var get = Object.getOwnPropertyDescriptor(RegExp.prototype, name).get;
+		assert.throws(TypeError, function() { get.call({}); }, name);
+		assert.throws(TypeError, function() { get.call(Object.create(RegExp.prototype)); }, name);
+	});
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestRegexpSymbolProtocol(t *testing.T) {
+	const SCRIPT = `
+	var matcher = {};
+	matcher[Symbol.match] = function(s) { return "match:" + s; };
+	matcher[Symbol.replace] = function(s, v) { return "replace:" + s + ":" + v; };
+	matcher[Symbol.search] = function(s) { return 42; };
+	matcher[Symbol.split] = function(s, limit) { return ["split", s, limit]; };
+	assert.sameValue("abc".match(matcher), "match:abc");
+	assert.sameValue("abc".replace(matcher, "x"), "replace:abc:x");
+	assert.sameValue("abc".search(matcher), 42);
+	assert.sameValue("abc".split(matcher, 2).join(), "split,abc,2");
+
+	var calls = 0;
+	var re = /b/g;
+	re.exec = function(s) {
+		calls++;
+		return RegExp.prototype.exec.call(this, s);
+	};
+	assert.sameValue("abcb".replace(re, "x"), "axcx");
+	assert.sameValue(calls, 3, "custom exec used by replace");
+	calls = 0;
+	assert.sameValue("abcb".match(re).length, 2);
+	assert.sameValue(calls, 3, "custom exec used by match");
+
+	class MyRE extends RegExp {
+		exec(s) {
+			var m = super.exec(s);
+			if (m) m[0] = m[0].toUpperCase();
+			return m;
+		}
+	}
+	assert.sameValue("a-b-c".split(new MyRE("-")).join("|"), "a|b|c");
+	assert.sameValue("abc".replace(new MyRE("b"), "[$&]"), "a[B]c");
+	assert.sameValue("xaxb".search(new MyRE("b")), 3);
+
+	var notRegexp = /a/;
+	notRegexp[Symbol.match] = false;
+	assert.sameValue("/a/".startsWith(notRegexp), true, "IsRegExp honours Symbol.match");
+	assert.throws(TypeError, function() { "a".includes(/a/); });
+	assert.sameValue(typeof Symbol.matchAll, "symbol");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestStringMatchAll(t *testing.T) {
+	const SCRIPT = `
+	var it = "a1b22c333".matchAll(/\d+/g);
+	assert.sameValue(Object.prototype.toString.call(it), "[object RegExp String Iterator]");
+	var res = [];
+	for (var m of it) {
+		res.push(m[0] + "@" + m.index);
+	}
+	assert.sameValue(res.join(), "1@1,22@3,333@6");
+
+	var re = /(?<k>\w)=(?<v>\d)/g;
+	var all = Array.from("a=1, b=2".matchAll(re), function(m) { return m.groups.k + m.groups.v; });
+	assert.sameValue(all.join(), "a1,b2");
+	assert.sameValue(re.lastIndex, 0, "original regexp not modified");
+
+	assert.sameValue(Array.from("aXa".matchAll("a")).length, 2, "string argument");
+	assert.sameValue(Array.from("😀😀".matchAll(/(?:)/gu)).length, 3, "empty matches advance by code point");
+	assert.throws(TypeError, function() { "a".matchAll(/a/); });
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestStringReplaceAll(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue("a.b.c".replaceAll(".", "-"), "a-b-c");
+	assert.sameValue("a.b.c".replace(".", "-"), "a-b.c");
+	assert.sameValue("abc".replaceAll("", "_"), "_a_b_c_");
+	assert.sameValue("aaa".replaceAll("aa", "b"), "ba");
+	assert.sameValue("x1y2".replaceAll(/\d/g, "[$&]"), "x[1]y[2]");
+	assert.sameValue("abab".replaceAll("b", function(m, pos, s) { return pos; }), "a1a3");
+	assert.sameValue("abc".replace("b", "$'$\x60$$"), "aca$c");
+	assert.throws(TypeError, function() { "a".replaceAll(/a/, "b"); });
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestRegexpReplaceNamedGroups(t *testing.T) {
+	const SCRIPT = `
+	var re = /(?<year>\d{4})-(?<month>\d{2})/;
+	assert.sameValue("2020-05".replace(re, "$<month>/$<year>"), "05/2020");
+	assert.sameValue("2020-05".replace(re, "$<nope>|$2"), "|05");
+	assert.sameValue("2020-05".replace(/(\d+)/, "$<year>"), "$<year>-05", "no named groups");
+	var groups;
+	var res = "on 2020-05".replace(re, function(m, y, mo, pos, s, g) {
+		groups = g;
+		return pos + ":" + g.month;
+	});
+	assert.sameValue(res, "on 3:05");
+	assert.sameValue(groups.year, "2020");
+	var args;
+	"ab".replace(/(a)/, function() { args = arguments.length; return ""; });
+	assert.sameValue(args, 4, "no groups argument without named groups");
+	assert.sameValue("aXbX".replace(/(?<x>X)/g, "[$<x>]"), "a[X]b[X]");
+	assert.sameValue("abc".replace(/(b)/, "$0$1$01$10"), "a$0bbb0c");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
 func BenchmarkRegexpSplitWithBackRef(b *testing.B) {
 	const SCRIPT = `
 	"aaaaaaaaaaaaaaaaaaaaaaaaa++bbbbbbbbbbbbbbbbbbbbbb+-ccccccccccccccccccccccc".split(/([+-])\1/)
@@ -2,16 +2,19 @@ package goja
 
 import (
 	"bytes"
+	go_context "context"
 	"errors"
 	"fmt"
 	"go/ast"
 	"math"
+	"math/big"
 	"math/rand"
 	"reflect"
 	"strconv"
 	"time"
 
 	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
 
 	js_ast "github.com/oracle3/goja/ast"
 	"github.com/oracle3/goja/parser"
@@ -25,6 +28,7 @@ var (
 	typeCallable = reflect.TypeOf(Callable(nil))
 	typeValue    = reflect.TypeOf((*Value)(nil)).Elem()
 	typeTime     = reflect.TypeOf(time.Time{})
+	typeBytes    = reflect.TypeOf([]byte(nil))
 )
 
 type global struct {
@@ -36,8 +40,18 @@ type global struct {
 	Boolean  *Object
 	RegExp   *Object
 	Date     *Object
+	Symbol   *Object
+	BigInt   *Object
+	Promise  *Object
+	Map      *Object
+	Set      *Object
+	WeakMap  *Object
+	WeakSet  *Object
+	Proxy    *Object
 
 	ArrayBuffer *Object
+	TypedArray  *Object
+	DataView    *Object
 
 	Error          *Object
 	TypeError      *Object
@@ -57,8 +71,34 @@ type global struct {
 	FunctionPrototype *Object
 	RegExpPrototype   *Object
 	DatePrototype     *Object
+	SymbolPrototype   *Object
+	BigIntPrototype   *Object
+	PromisePrototype  *Object
+	MapPrototype      *Object
+	SetPrototype      *Object
+	WeakMapPrototype  *Object
+	WeakSetPrototype  *Object
+
+	CollatorPrototype       *Object
+	DateTimeFormatPrototype *Object
+	NumberFormatPrototype   *Object
+
+	IteratorPrototype             *Object
+	ArrayIteratorPrototype        *Object
+	StringIteratorPrototype       *Object
+	RegExpStringIteratorPrototype *Object
+	MapIteratorPrototype          *Object
+	SetIteratorPrototype          *Object
+
+	GeneratorPrototype         *Object
+	GeneratorFunctionPrototype *Object
+	AsyncFunctionPrototype     *Object
 
 	ArrayBufferPrototype *Object
+	TypedArrayPrototype  *Object
+	DataViewPrototype    *Object
+
+	typedArrayCtors map[*typedArrayKind]*Object
 
 	ErrorPrototype          *Object
 	TypeErrorPrototype      *Object
@@ -72,6 +112,13 @@ type global struct {
 
 	Eval *Object
 
+	// Function.prototype[@@hasInstance]的默认实现
+	hasInstance *Object
+	// Array.prototype.values，也是arguments的Symbol.iterator
+	arrayValues *Object
+	// RegExp.prototype.exec，用于判断正则表达式是否可以走快速路径
+	regexpProtoExec *Object
+
 	thrower         *Object
 	throwerProperty Value
 }
@@ -106,10 +153,40 @@ type Runtime struct {
 	rand            RandSource
 	now             Now
 	_collator       *collate.Collator
+	// Intl及toLocaleString等方法使用的默认语言
+	locale language.Tag
 
 	typeInfoCache   map[reflect.Type]*reflectTypeInfo
 	fieldNameMapper FieldNameMapper
 
+	// 顶层的let/const声明，在所有脚本之间共享
+	globalLex *stash
+
+	// Symbol.for注册的symbol
+	symbolRegistry map[string]*valueSymbol
+
+	// 待执行的promise任务，在脚本或回调执行完后运行
+	jobQueue    []func()
+	runningJobs bool
+	hostJobs    hostJobQueue
+
+	// 最近分配的WeakMap/WeakSet标识
+	lastWeakMap weakMap
+
+	// 带标签模板的字符串数组缓存
+	templateObjects map[*getTemplateObject]*Object
+
+	// 模块加载器和已加载的模块，按解析后的名称索引
+	moduleLoader ModuleLoader
+	modules      map[string]*moduleRecord
+
+	// 脚本分配的近似内存和限制，0表示不限制
+	memUsage int64
+	memLimit int64
+
+	// 当前RunProgramContext等调用传入的context，没有时为nil
+	ctx go_context.Context
+
 	vm *vm
 }
 
@@ -157,8 +234,13 @@ func (f *stackFrame) write(b *bytes.Buffer) {
 type Exception struct {
 	val   Value
 	stack []stackFrame
+	// 栈溢出时记录的调用栈已截断，传播时不再追加
+	truncated bool
 }
 
+// 不依赖Runtime的地方抛出的TypeError，由vm.try转换为异常
+type typeError string
+
 type InterruptedError struct {
 	Exception
 	iface interface{}
@@ -181,6 +263,16 @@ func (e *InterruptedError) String() string {
 	return b.String()
 }
 
+// Unwrap returns the value passed to Interrupt() if it is an error, for example the error of the context passed to
+// RunProgramContext().
+//Unwrap在传给Interrupt()的值是error时返回它，例如传给RunProgramContext()的context的错误。
+func (e *InterruptedError) Unwrap() error {
+	if err, ok := e.iface.(error); ok {
+		return err
+	}
+	return nil
+}
+
 func (e *InterruptedError) Error() string {
 	if e == nil || e.iface == nil {
 		return "<nil>"
@@ -240,8 +332,14 @@ func (r *Runtime) addToGlobal(name string, value Value) {
 func (r *Runtime) init() {
 	r.rand = rand.Float64
 	r.now = time.Now
+	r.locale = language.AmericanEnglish
 	r.global.ObjectPrototype = r.newBaseObject(nil, classObject).val
 	r.globalObject = r.NewObject()
+	r.hostJobs.ready = make(chan struct{}, 1)
+	r.globalLex = &stash{
+		names:  make(map[string]uint32),
+		consts: make(map[string]bool),
+	}
 
 	r.vm = &vm{
 		r: r,
@@ -257,6 +355,18 @@ func (r *Runtime) init() {
 	r.initRegExp()
 	r.initDate()
 	r.initBoolean()
+	r.initSymbol()
+	r.initBigInt()
+	r.initIterators()
+	r.initGenerators()
+	r.initAsyncFunctions()
+	r.initPromise()
+	r.initMap()
+	r.initSet()
+	r.initWeakMap()
+	r.initWeakSet()
+	r.initProxy()
+	r.initReflect()
 
 	r.initErrors()
 
@@ -267,8 +377,9 @@ func (r *Runtime) init() {
 
 	r.initMath()
 	r.initJSON()
+	r.initIntl()
 
-	//r.initTypedArrays()
+	r.initTypedArrays()
 
 	r.global.thrower = r.newNativeFunc(r.builtin_thrower, nil, "thrower", nil, 0)
 	r.global.throwerProperty = &valueProperty{
@@ -277,6 +388,19 @@ func (r *Runtime) init() {
 		accessor:   true,
 	}
 }
+// instanceof运算，优先使用c[@@hasInstance]
+func (r *Runtime) instanceOf(v Value, c *Object) bool {
+	if h, ok := c.self.get(symHasInstance).(*Object); ok && h != r.global.hasInstance {
+		if call, ok := h.self.assertCallable(); ok {
+			return call(FunctionCall{
+				This:      c,
+				Arguments: []Value{v},
+			}).ToBoolean()
+		}
+		r.typeErrorResult(true, "Symbol.hasInstance is not a function")
+	}
+	return c.self.hasInstance(v)
+}
 // 异常退出
 func (r *Runtime) typeErrorResult(throw bool, args ...interface{}) {
 	if throw {
@@ -293,6 +417,28 @@ func (r *Runtime) throwReferenceError(name string) {
 	panic(r.newError(r.global.ReferenceError, "%s is not defined", name))
 }
 
+// 在初始化之前访问let/const变量
+func (r *Runtime) throwUninitializedError(name string) {
+	panic(r.newError(r.global.ReferenceError, "Cannot access '%s' before initialization", name))
+}
+
+func (r *Runtime) throwRedeclarationError(name string) {
+	panic(r.newError(r.global.SyntaxError, "Identifier '%s' has already been declared", name))
+}
+
+func (r *Runtime) throwConstAssignError() {
+	panic(r.newError(r.global.TypeError, "Assignment to constant variable."))
+}
+
+func (r *Runtime) throwClassCallError(f *funcObject) {
+	panic(r.newError(r.global.TypeError, "Class constructor %s cannot be invoked without 'new'", f.nameProp.value.String()))
+}
+
+// 派生类的构造函数中在调用super()之前访问this
+func (r *Runtime) throwThisBeforeSuperError() {
+	panic(r.newError(r.global.ReferenceError, "Must call super constructor in derived class before accessing 'this' or returning from derived constructor"))
+}
+
 func (r *Runtime) newSyntaxError(msg string, offset int) Value {
 	return r.builtin_new((r.global.SyntaxError), []Value{newStringValue(msg)})
 }
@@ -324,6 +470,7 @@ func (r *Runtime) newArrayValues(values []Value) *Object {
 	v.self = a
 	a.prototype = r.global.ArrayPrototype
 	a.init()
+	r.allocate(len(values) * sizeofValue)
 	a.values = values
 	a.length = int64(len(values))
 	a.objCount = a.length
@@ -528,10 +675,18 @@ func (r *Runtime) newPrimitiveObject(value Value, proto *Object, class string) *
 	o.init()
 	return v
 }
+// Number(value)的转换，BigInt转换为最接近的数字
+func numberValue(v Value) Value {
+	v = toNumeric(v)
+	if b, ok := v.(*valueBigInt); ok {
+		return floatToValue(bigIntToFloat((*big.Int)(b)))
+	}
+	return v
+}
 // 根据输入创建一个number值
 func (r *Runtime) builtin_Number(call FunctionCall) Value {
 	if len(call.Arguments) > 0 {
-		return call.Arguments[0].ToNumber()
+		return numberValue(call.Arguments[0])
 	} else {
 		return intToValue(0)
 	}
@@ -540,7 +695,7 @@ func (r *Runtime) builtin_Number(call FunctionCall) Value {
 func (r *Runtime) builtin_newNumber(args []Value) *Object {
 	var v Value
 	if len(args) > 0 {
-		v = args[0].ToNumber()
+		v = numberValue(args[0])
 	} else {
 		v = intToValue(0)
 	}
@@ -619,8 +774,9 @@ repeat:
 			panic("Not a constructor")
 		}
 	case *funcObject:
-		// TODO: implement
-		panic("Not implemented")
+		return f.construct(args)
+	case *Proxy:
+		return f.construct(args, construct)
 	case *lazyObject:
 		construct.self = f.create(construct)
 		goto repeat
@@ -692,6 +848,18 @@ func (r *Runtime) toCallable(v Value) func(FunctionCall) Value {
 	return nil
 }
 
+// GetMethod(v, sym)：v[sym]为undefined或null时返回nil，不可调用时抛出TypeError
+func (r *Runtime) getMethod(v Value, sym *valueSymbol) func(FunctionCall) Value {
+	m := nilSafe(v.ToObject(r).self.get(sym))
+	if m == _undefined || m == _null {
+		return nil
+	}
+	return r.toCallable(m)
+}
+// 调用o[sym](args...)
+func (r *Runtime) invokeSym(o *Object, sym *valueSymbol, args ...Value) Value {
+	return r.toCallable(nilSafe(o.self.get(sym)))(FunctionCall{This: o, Arguments: args})
+}
 func (r *Runtime) checkObjectCoercible(v Value) {
 	switch v.(type) {
 	case valueUndefined, valueNull:
@@ -762,6 +930,13 @@ func (r *Runtime) toBoolean(b bool) Value {
 		return valueFalse
 	}
 }
+// 不存在的值(nil)转换为undefined
+func nilSafe(v Value) Value {
+	if v != nil {
+		return v
+	}
+	return _undefined
+}
 
 // New creates an instance of a Javascript runtime that can be used to run code. Multiple instances may be created and
 // used simultaneously, however it is not possible to pass JS values across runtimes.
@@ -769,6 +944,7 @@ func (r *Runtime) toBoolean(b bool) Value {
 func New() *Runtime {
 	r := &Runtime{}
 	r.init()
+	r.memUsage = 0
 	return r
 }
 
@@ -852,19 +1028,24 @@ func compileAST(prg *js_ast.Program, strict, eval bool) (p *Program, err error)
 func (r *Runtime) compile(name, src string, strict, eval bool) (p *Program, err error) {
 	p, err = compile(name, src, strict, eval)
 	if err != nil {
-		switch x1 := err.(type) {
-		case *CompilerSyntaxError:
-			err = &Exception{
-				val: r.builtin_new(r.global.SyntaxError, []Value{newStringValue(x1.Error())}),
-			}
-		case *CompilerReferenceError:
-			err = &Exception{
-				val: r.newError(r.global.ReferenceError, x1.Message),
-			} // TODO proper message
-		}
+		err = r.compileError(err)
 	}
 	return
 }
+// 把编译错误转换为JS异常
+func (r *Runtime) compileError(err error) error {
+	switch x1 := err.(type) {
+	case *CompilerSyntaxError:
+		return &Exception{
+			val: r.builtin_new(r.global.SyntaxError, []Value{newStringValue(x1.Error())}),
+		}
+	case *CompilerReferenceError:
+		return &Exception{
+			val: r.newError(r.global.ReferenceError, x1.Message),
+		} // TODO proper message
+	}
+	return err
+}
 
 // RunString executes the given string in the global context.
 func (r *Runtime) RunString(str string) (Value, error) {
@@ -885,18 +1066,34 @@ func (r *Runtime) RunScript(name, src string) (Value, error) {
 // RunProgram executes a pre-compiled (see Compile()) code in the global context.
 //RunProgram在全局上下文中执行预编译（参见Compile（））代码。
 func (r *Runtime) RunProgram(p *Program) (result Value, err error) {
+	recursive := len(r.vm.callStack) > 0
 	defer func() {
 		if x := recover(); x != nil {
-			if intr, ok := x.(*InterruptedError); ok {
-				err = intr
-			} else {
+			switch x := x.(type) {
+			case *InterruptedError:
+				err = x
+			case *MemoryLimitError:
+				err = x
+			case *GasLimitError:
+				err = x
+			default:
 				panic(x)
 			}
+			// 中止可能发生在执行promise任务时，此时已经得到的结果不再返回
+			result = nil
+			if !recursive {
+				r.dropJobs()
+			}
+		}
+		if recursive {
+			r.vm.popCtx()
+			r.vm.halt = false
+			r.vm.clearStack()
+		} else {
+			r.vm.stack = nil
 		}
 	}()
-	recursive := false
-	if len(r.vm.callStack) > 0 {
-		recursive = true
+	if recursive {
 		r.vm.pushCtx()
 	}
 	r.vm.prg = p
@@ -907,12 +1104,8 @@ func (r *Runtime) RunProgram(p *Program) (result Value, err error) {
 	} else {
 		err = ex
 	}
-	if recursive {
-		r.vm.popCtx()
-		r.vm.halt = false
-		r.vm.clearStack()
-	} else {
-		r.vm.stack = nil
+	if !recursive {
+		r.leave()
 	}
 	return
 }
@@ -939,6 +1132,9 @@ func (r *Runtime) ClearInterrupt() {
 ToValue converts a Go value into JavaScript value.
 
 Primitive types (ints and uints, floats, string, bool) are converted to the corresponding JavaScript primitives.
+uint and uint64 values that do not fit into int64 as well as *big.Int are converted to a BigInt. Exporting a BigInt
+returns a *big.Int; a BigInt can also be exported into int64 and uint64 (and smaller integer types) as long as the value
+fits.
 
 func(FunctionCall) Value is treated as a native JavaScript function.
 
@@ -949,6 +1145,9 @@ because extending it can change the pointer so it becomes detached from the orig
 
 *[]interface{} same as above, but the array becomes extensible.
 
+[]byte is converted into a Uint8Array backed by the same memory, so changes made from either side are visible to
+the other. ArrayBuffer (see Runtime.NewArrayBuffer()) is converted into the JavaScript ArrayBuffer it wraps.
+
 A function is wrapped within a native JavaScript function. When called the arguments are automatically converted to
 the appropriate Go types. If conversion is not possible, a TypeError is thrown.
 
@@ -962,6 +1161,7 @@ reflect based types.
 ToValue将Go值转换为JavaScript值。
 
 基本类型（int和uint，float，string，bool）将转换为相应的JavaScript原语。
+超出int64范围的uint和uint64以及*big.Int转换为BigInt。BigInt导出为*big.Int，值在范围内时也可以导出为int64、uint64等整数类型。
 
 func（FunctionCall）值被视为本机JavaScript函数。
 
@@ -971,6 +1171,8 @@ map [string] interface {}被转换为一个宿主对象，该宿主对象在很
 
 * [] interface {}与上面相同，但是数组变得可扩展。
 
+[] byte被转换为共享同一内存的Uint8Array，任何一方的修改对另一方都可见。ArrayBuffer（参见Runtime.NewArrayBuffer()）被转换为它包装的JavaScript ArrayBuffer。
+
 函数包装在本机JavaScript函数中。调用时，参数将自动转换为适当的Go类型。如果无法进行转换，则抛出TypeError。
 
 切片类型将转换为行为类似于不可扩展Array的基于通用反射的宿主对象。
@@ -986,6 +1188,17 @@ func (r *Runtime) ToValue(i interface{}) Value {
 	case Value:
 		// TODO: prevent importing Objects from a different runtime
 		return i
+	case *Promise:
+		return i.val
+	case *Proxy:
+		return i.val
+	case ArrayBuffer:
+		return i.buf.val
+	case []byte:
+		if i == nil {
+			return _null
+		}
+		return r.NewArrayBuffer(i).Uint8Array()
 	case string:
 		return newStringValue(i)
 	case bool:
@@ -1012,7 +1225,7 @@ func (r *Runtime) ToValue(i interface{}) Value {
 		if uint64(i) <= math.MaxInt64 {
 			return intToValue(int64(i))
 		} else {
-			return floatToValue(float64(i))
+			return (*valueBigInt)(new(big.Int).SetUint64(uint64(i)))
 		}
 	case uint8:
 		return intToValue(int64(i))
@@ -1024,7 +1237,12 @@ func (r *Runtime) ToValue(i interface{}) Value {
 		if i <= math.MaxInt64 {
 			return intToValue(int64(i))
 		}
-		return floatToValue(float64(i))
+		return (*valueBigInt)(new(big.Int).SetUint64(i))
+	case *big.Int:
+		if i == nil {
+			return _null
+		}
+		return (*valueBigInt)(new(big.Int).Set(i))
 	case float32:
 		return floatToValue(float64(i))
 	case float64:
@@ -1238,6 +1456,11 @@ func (r *Runtime) wrapReflectFunc(value reflect.Value) func(FunctionCall) Value
 }
 // 获取v对应类型typ的值
 func (r *Runtime) toReflectValue(v Value, typ reflect.Type) (reflect.Value, error) {
+	if b, ok := v.(*valueBigInt); ok {
+		if rv, ok, err := bigIntToReflectValue((*big.Int)(b), typ); ok {
+			return rv, err
+		}
+	}
 	switch typ.Kind() {
 	case reflect.String:
 		return reflect.ValueOf(v.String()).Convert(typ), nil
@@ -1306,6 +1529,18 @@ func (r *Runtime) toReflectValue(v Value, typ reflect.Type) (reflect.Value, erro
 	switch typ.Kind() {
 	case reflect.Slice:
 		if o, ok := v.(*Object); ok {
+			if so, ok := o.self.(*setObject); ok {
+				s := reflect.MakeSlice(typ, 0, so.m.size)
+				elemTyp := typ.Elem()
+				for entry := so.m.iterFirst; entry != nil; entry = entry.iterNext {
+					itemval, err := r.toReflectValue(entry.key, elemTyp)
+					if err != nil {
+						return reflect.Value{}, fmt.Errorf("Could not convert set element %v to %v: %s", entry.key, typ, err)
+					}
+					s = reflect.Append(s, itemval)
+				}
+				return s, nil
+			}
 			if o.self.className() == classArray {
 				l := int(toLength(o.self.getStr("length")))
 				s := reflect.MakeSlice(typ, l, l)
@@ -1326,6 +1561,20 @@ func (r *Runtime) toReflectValue(v Value, typ reflect.Type) (reflect.Value, erro
 			m := reflect.MakeMap(typ)
 			keyTyp := typ.Key()
 			elemTyp := typ.Elem()
+			if mo, ok := o.self.(*mapObject); ok {
+				for entry := mo.m.iterFirst; entry != nil; entry = entry.iterNext {
+					kv, err := r.toReflectValue(entry.key, keyTyp)
+					if err != nil {
+						return reflect.Value{}, fmt.Errorf("Could not convert map key %v to %v: %s", entry.key, typ, err)
+					}
+					vv, err := r.toReflectValue(entry.value, elemTyp)
+					if err != nil {
+						return reflect.Value{}, fmt.Errorf("Could not convert map value %v to %v at key %v: %s", entry.value, typ, entry.key, err)
+					}
+					m.SetMapIndex(kv, vv)
+				}
+				return m, nil
+			}
 			needConvertKeys := !reflect.ValueOf("").Type().AssignableTo(keyTyp)
 			for item, f := o.self.enumerate(false, false)(); f != nil; item, f = f() {
 				var kv reflect.Value
@@ -1488,6 +1737,27 @@ func (r *Runtime) SetTimeSource(now Now) {
 	r.now = now
 }
 
+// SetMaxCallStackSize sets the maximum depth of the call stack. When a call would exceed it
+// a RangeError is thrown which can be caught by the script. The default is 10000. The nesting
+// of Go→JS re-entries (native functions calling back into JavaScript) is limited as well.
+//SetMaxCallStackSize设置调用栈的最大深度，超过时抛出脚本可以捕获的RangeError，默认为10000。原生函数回调JS的嵌套深度同样受限。
+func (r *Runtime) SetMaxCallStackSize(size int) {
+	r.vm.maxCallStackSize = size
+}
+
+// SetLocale sets the default locale used by the Intl objects and the toLocaleString family of methods,
+// for example "de-DE". If not called, "en-US" is used.
+//SetLocale设置Intl和toLocaleString等方法使用的默认语言，未调用时使用"en-US"。
+func (r *Runtime) SetLocale(locale string) error {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return err
+	}
+	r.locale = tag
+	r._collator = nil
+	return nil
+}
+
 // New is an equivalent of the 'new' operator allowing to call it directly from Go.
 //New相当于“New”运算符，允许从Go直接调用它。
 func (r *Runtime) New(construct Value, args ...Value) (o *Object, err error) {
@@ -1498,6 +1768,10 @@ func (r *Runtime) New(construct Value, args ...Value) (o *Object, err error) {
 				err = x
 			case *InterruptedError:
 				err = x
+			case *MemoryLimitError:
+				err = x
+			case *GasLimitError:
+				err = x
 			default:
 				panic(x)
 			}
@@ -1516,16 +1790,28 @@ func AssertFunction(v Value) (Callable, bool) {
 	if obj, ok := v.(*Object); ok {
 		if f, ok := obj.self.assertCallable(); ok {
 			return func(this Value, args ...Value) (ret Value, err error) {
+				vm := obj.runtime.vm
+				toplevel := len(vm.callStack) == 0
 				defer func() {
 					if x := recover(); x != nil {
-						if ex, ok := x.(*InterruptedError); ok {
-							err = ex
-						} else {
+						switch x := x.(type) {
+						case *InterruptedError:
+							err = x
+						case *MemoryLimitError:
+							err = x
+						case *GasLimitError:
+							err = x
+						default:
 							panic(x)
 						}
+						ret = nil
+						if toplevel {
+							obj.runtime.dropJobs()
+						}
 					}
+					vm.clearStack()
 				}()
-				ex := obj.runtime.vm.try(func() {
+				ex := vm.try(func() {
 					ret = f(FunctionCall{
 						This:      this,
 						Arguments: args,
@@ -1534,7 +1820,9 @@ func AssertFunction(v Value) (Callable, bool) {
 				if ex != nil {
 					err = ex
 				}
-				obj.runtime.vm.clearStack()
+				if toplevel {
+					obj.runtime.leave()
+				}
 				return
 			}, true
 		}
@@ -1606,6 +1894,10 @@ func tryFunc(f func()) (err error) {
 				err = x
 			case *InterruptedError:
 				err = x
+			case *MemoryLimitError:
+				err = x
+			case *GasLimitError:
+				err = x
 			case Value:
 				err = &Exception{
 					val: x,
@@ -0,0 +1,87 @@
+package goja
+
+import (
+	go_context "context"
+)
+
+// RunProgramContext is like RunProgram() but interrupts the execution when ctx is done. In that case an
+// *InterruptedError wrapping ctx.Err() is returned, so errors.Is(err, context.Canceled) and
+// errors.Is(err, context.DeadlineExceeded) can be used to check the reason. The interrupt flag is cleared
+// automatically and the context is available to native functions through Context().
+//RunProgramContext与RunProgram()相同，但ctx结束时中断执行，返回包装了ctx.Err()的*InterruptedError。
+//中断标志会自动清除，原生函数可以通过Context()取得ctx。
+func (r *Runtime) RunProgramContext(ctx go_context.Context, p *Program) (Value, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &InterruptedError{iface: err}
+	}
+	defer r.watchContext(ctx)()
+	return r.RunProgram(p)
+}
+// RunStringContext is like RunString() but interrupts the execution when ctx is done, see RunProgramContext().
+//RunStringContext与RunString()相同，但ctx结束时中断执行，参见RunProgramContext()。
+func (r *Runtime) RunStringContext(ctx go_context.Context, str string) (Value, error) {
+	p, err := Compile("", str, false)
+	if err != nil {
+		return nil, err
+	}
+	return r.RunProgramContext(ctx, p)
+}
+// RunModuleContext is like RunModule() but interrupts the execution when ctx is done, see RunProgramContext().
+//RunModuleContext与RunModule()相同，但ctx结束时中断执行，参见RunProgramContext()。
+func (r *Runtime) RunModuleContext(ctx go_context.Context, specifier string) (*Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &InterruptedError{iface: err}
+	}
+	defer r.watchContext(ctx)()
+	return r.RunModule(specifier)
+}
+// CallContext calls fn (see AssertFunction()) and interrupts the execution when ctx is done, see RunProgramContext().
+//CallContext调用fn(参见AssertFunction())，ctx结束时中断执行，参见RunProgramContext()。
+func (r *Runtime) CallContext(ctx go_context.Context, fn Callable, this Value, args ...Value) (Value, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &InterruptedError{iface: err}
+	}
+	defer r.watchContext(ctx)()
+	return fn(this, args...)
+}
+// Context returns the context passed to the innermost RunProgramContext(), RunStringContext() or CallContext()
+// call that is currently running, or context.Background() if there is none. Native functions can use it to
+// cancel blocking operations.
+//Context返回当前正在执行的最内层RunProgramContext()、RunStringContext()或CallContext()调用传入的context，
+//没有时返回context.Background()。原生函数可以用它取消阻塞的操作。
+func (r *Runtime) Context() go_context.Context {
+	if r.ctx == nil {
+		return go_context.Background()
+	}
+	return r.ctx
+}
+// 执行期间ctx结束时中断运行时，返回的函数停止监视，恢复之前的context，中断没有生效时清除中断标志
+func (r *Runtime) watchContext(ctx go_context.Context) (stop func()) {
+	prev := r.ctx
+	r.ctx = ctx
+	if ctx.Done() == nil {
+		return func() {
+			r.ctx = prev
+		}
+	}
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	interrupted := false
+	go func() {
+		defer close(exited)
+		select {
+		case <-ctx.Done():
+			r.Interrupt(ctx.Err())
+			interrupted = true
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+		if interrupted {
+			r.ClearInterrupt()
+		}
+		r.ctx = prev
+	}
+}
@@ -0,0 +1,86 @@
+package goja
+
+import (
+	go_context "context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type testContextKey struct{}
+
+func TestRunStringContext(t *testing.T) {
+	vm := New()
+	ctx, cancel := go_context.WithTimeout(go_context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := vm.RunStringContext(ctx, `for (;;) {}`)
+	if _, ok := err.(*InterruptedError); !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !errors.Is(err, go_context.DeadlineExceeded) {
+		t.Fatalf("Error does not wrap the context error: %v", err)
+	}
+
+	// ctx已经结束，脚本不会执行
+	_, err = vm.RunStringContext(ctx, `var executed = true;`)
+	if !errors.Is(err, go_context.DeadlineExceeded) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if vm.Get("executed") != nil {
+		t.Fatal("Script was executed with a done context")
+	}
+
+	ctx, cancel = go_context.WithCancel(go_context.Background())
+	v, err := vm.RunStringContext(ctx, `1 + 2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.ToInteger() != 3 {
+		t.Fatalf("Unexpected result: %v", v)
+	}
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	if _, err := vm.RunString(`for (var i = 0; i < 100000; i++) {}`); err != nil {
+		t.Fatalf("Runtime was interrupted after the context call had finished: %v", err)
+	}
+}
+
+func TestCallContext(t *testing.T) {
+	vm := New()
+	vm.Set("fromContext", func(call FunctionCall) Value {
+		return vm.ToValue(vm.Context().Value(testContextKey{}))
+	})
+	v, err := vm.RunString(`
+	(function(loop) {
+		var v = fromContext();
+		while (loop) {}
+		return v;
+	})
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn, ok := AssertFunction(v)
+	if !ok {
+		t.Fatal("Not a function")
+	}
+
+	ctx := go_context.WithValue(go_context.Background(), testContextKey{}, "value")
+	v, err = vm.CallContext(ctx, fn, nil, valueFalse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.String() != "value" {
+		t.Fatalf("Unexpected result: %v", v)
+	}
+	if vm.Context() != go_context.Background() {
+		t.Fatal("Context was not restored")
+	}
+
+	ctx, cancel := go_context.WithCancel(ctx)
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err = vm.CallContext(ctx, fn, nil, valueTrue)
+	if !errors.Is(err, go_context.Canceled) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
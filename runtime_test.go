@@ -1279,6 +1279,88 @@ func TestRunLoopPreempt(t *testing.T) {
 	}
 }
 
+func TestStackOverflow(t *testing.T) {
+	const SCRIPT = `
+	var depth = 0;
+	function f() {
+		depth++;
+		f();
+	}
+	try {
+		f();
+	} catch (e) {
+		assert(e instanceof RangeError, "RangeError");
+	}
+	assert.sameValue(depth, 50);
+
+	var nested = 0;
+	function g() {
+		nested++;
+		[0].forEach(g);
+	}
+	assert.throws(RangeError, g);
+
+	function h() {
+		try {
+			h();
+		} finally {
+		}
+	}
+	try {
+		h();
+	} catch (e) {
+		assert.sameValue(e.message, "Maximum call stack size exceeded");
+	}
+	`
+	vm := New()
+	vm.SetMaxCallStackSize(50)
+	if _, err := vm.RunString(TESTLIB + SCRIPT); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := vm.RunString(`(function f() { f(); })()`)
+	ex, ok := err.(*Exception)
+	if !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if s := ex.Value().String(); s != "RangeError: Maximum call stack size exceeded" {
+		t.Fatalf("Unexpected error: %s", s)
+	}
+	if len(ex.stack) != maxOverflowStackFrames {
+		t.Fatalf("Stack is not truncated: %d frames", len(ex.stack))
+	}
+
+	v, err := vm.RunString(`(function f(n) { return n > 0 ? f(n - 1) : "done"; })(20)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.String() != "done" {
+		t.Fatalf("Unexpected result: %v", v)
+	}
+}
+
+func TestStackOverflowNative(t *testing.T) {
+	vm := New()
+	var f func(FunctionCall) Value
+	f = func(call FunctionCall) Value {
+		fn, _ := AssertFunction(call.Argument(0))
+		v, err := fn(nil, call.Argument(0))
+		if err != nil {
+			panic(err)
+		}
+		return v
+	}
+	vm.Set("reenter", f)
+	_, err := vm.RunString(`reenter(function self(fn) { return reenter(fn); })`)
+	ex, ok := err.(*Exception)
+	if !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if s := ex.Value().String(); s != "RangeError: Maximum call stack size exceeded" {
+		t.Fatalf("Unexpected error: %s", s)
+	}
+}
+
 func TestNaN(t *testing.T) {
 	if !IsNaN(_NaN) {
 		t.Fatal("IsNaN() doesn't detect NaN")
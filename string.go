@@ -21,6 +21,8 @@ var (
 	stringBoolean      valueString = asciiString("boolean")
 	stringString       valueString = asciiString("string")
 	stringNumber       valueString = asciiString("number")
+	stringSymbol       valueString = asciiString("symbol")
+	stringBigInt       valueString = asciiString("bigint")
 	stringNaN          valueString = asciiString("NaN")
 	stringInfinity                 = asciiString("Infinity")
 	stringPlusInfinity             = asciiString("+Infinity")
@@ -93,6 +95,9 @@ func (s *stringObject) setLength() {
 }
 // 获取指定位置的字符
 func (s *stringObject) get(n Value) Value {
+	if sym, ok := n.(*valueSymbol); ok {
+		return s.getSym(sym)
+	}
 	if idx := toIdx(n); idx >= 0 && idx < s.length {
 		return s.getIdx(idx)
 	}
@@ -114,6 +119,9 @@ func (s *stringObject) getPropStr(name string) Value {
 }
 // 获取指定位置的字符或者属性
 func (s *stringObject) getProp(n Value) Value {
+	if sym, ok := n.(*valueSymbol); ok {
+		return s.getPropSym(sym)
+	}
 	if i := toIdx(n); i >= 0 && i < s.length {
 		return s.getIdx(i)
 	}
@@ -137,6 +145,10 @@ func (s *stringObject) getIdx(idx int64) Value {
 }
 // 对字符串put会异常
 func (s *stringObject) put(n Value, val Value, throw bool) {
+	if sym, ok := n.(*valueSymbol); ok {
+		s.putSym(sym, val, throw)
+		return
+	}
 	if i := toIdx(n); i >= 0 && i < s.length {
 		s.val.runtime.typeErrorResult(throw, "Cannot assign to read only property '%d' of a String", i)
 		return
@@ -155,6 +167,9 @@ func (s *stringObject) putStr(name string, val Value, throw bool) {
 }
 // 对字符串定义属性会异常
 func (s *stringObject) defineOwnProperty(n Value, descr propertyDescr, throw bool) bool {
+	if sym, ok := n.(*valueSymbol); ok {
+		return s.defineOwnPropertySym(sym, descr, throw)
+	}
 	if i := toIdx(n); i >= 0 && i < s.length {
 		s.val.runtime.typeErrorResult(throw, "Cannot redefine property: %d", i)
 		return false
@@ -207,6 +222,9 @@ func (s *stringObject) deleteStr(name string, throw bool) bool {
 }
 // 不允许删除字符串
 func (s *stringObject) delete(n Value, throw bool) bool {
+	if sym, ok := n.(*valueSymbol); ok {
+		return s.deleteSym(sym, throw)
+	}
 	if i := toIdx(n); i >= 0 && i < s.length {
 		s.val.runtime.typeErrorResult(throw, "Cannot delete property '%d' of a String", i)
 		return false
@@ -216,6 +234,9 @@ func (s *stringObject) delete(n Value, throw bool) bool {
 }
 // 获取指定位置的属性都是true
 func (s *stringObject) hasOwnProperty(n Value) bool {
+	if sym, ok := n.(*valueSymbol); ok {
+		return s.hasOwnPropertySym(sym)
+	}
 	if i := toIdx(n); i >= 0 && i < s.length {
 		return true
 	}
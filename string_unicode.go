@@ -140,6 +140,10 @@ func (s unicodeString) Equals(other Value) bool {
 		return false
 	}
 
+	if _, ok := other.(*valueBigInt); ok {
+		return false
+	}
+
 	if o, ok := other.(*Object); ok {
 		return s.Equals(o.self.toPrimitive())
 	}
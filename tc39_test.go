@@ -55,7 +55,66 @@ var (
 		"test/annexB/built-ins/unescape/two-ignore-non-hex.js":  true,
 	}
 
-	es6IdWhiteList = []string{}
+	es6IdWhiteList = []string{
+		"19.1.2.1",  // Object.assign
+		"19.1.2.10", // Object.is
+		"19.1.2.18", // Object.setPrototypeOf
+		"20.1.2.1",  // Number.EPSILON
+		"20.1.2.2",  // Number.isFinite
+		"20.1.2.3",  // Number.isInteger
+		"20.1.2.4",  // Number.isNaN
+		"20.1.2.5",  // Number.isSafeInteger
+		"20.1.2.6",  // Number.MAX_SAFE_INTEGER
+		"20.1.2.8",  // Number.MIN_SAFE_INTEGER
+		"20.1.2.12", // Number.parseFloat
+		"20.1.2.13", // Number.parseInt
+		"20.2.2.3",  // Math.acosh
+		"20.2.2.5",  // Math.asinh
+		"20.2.2.7",  // Math.atanh
+		"20.2.2.9",  // Math.cbrt
+		"20.2.2.11", // Math.clz32
+		"20.2.2.13", // Math.cosh
+		"20.2.2.15", // Math.expm1
+		"20.2.2.17", // Math.fround
+		"20.2.2.18", // Math.hypot
+		"20.2.2.19", // Math.imul
+		"20.2.2.21", // Math.log1p
+		"20.2.2.22", // Math.log10
+		"20.2.2.23", // Math.log2
+		"20.2.2.29", // Math.sign
+		"20.2.2.31", // Math.sinh
+		"20.2.2.34", // Math.tanh
+		"20.2.2.35", // Math.trunc
+		"21.1.2.2",  // String.fromCodePoint
+		"21.1.2.4",  // String.raw
+		"21.1.3.3",  // String.prototype.codePointAt
+		"21.1.3.6",  // String.prototype.endsWith
+		"21.1.3.7",  // String.prototype.includes
+		"21.1.3.12", // String.prototype.normalize
+		"21.1.3.13", // String.prototype.repeat
+		"21.1.3.18", // String.prototype.startsWith
+		"22.1.2.1",  // Array.from
+		"22.1.2.3",  // Array.of
+		"22.1.3.3",  // Array.prototype.copyWithin
+		"22.1.3.6",  // Array.prototype.fill
+		"22.1.3.8",  // Array.prototype.find
+		"22.1.3.9",  // Array.prototype.findIndex
+	}
+
+	// ES2016以后的测试只有esid
+	esIdWhiteList = []string{
+		"sec-array.prototype.includes",
+		"sec-array.prototype.flat",
+		"sec-array.prototype.flatmap",
+		"sec-object.entries",
+		"sec-object.values",
+		"sec-object.fromentries",
+		"sec-object.getownpropertydescriptors",
+		"sec-string.prototype.padstart",
+		"sec-string.prototype.padend",
+		"sec-string.prototype.trimstart",
+		"sec-string.prototype.trimend",
+	}
 )
 
 type tc39Test struct {
@@ -187,6 +246,18 @@ func (ctx *tc39TestCtx) runTC39Test(name, src string, meta *tc39Meta, t testing.
 	}
 }
 
+// es6id是section章节本身或者它的子章节，19.1.2.1不匹配19.1.2.10
+func matchSectionId(es6id, section string) bool {
+	if !strings.HasPrefix(es6id, section) {
+		return false
+	}
+	if len(es6id) == len(section) {
+		return true
+	}
+	c := es6id[len(section)]
+	return c < '0' || c > '9'
+}
+
 func (ctx *tc39TestCtx) runTC39File(name string, t testing.TB) {
 	if skipList[name] {
 		t.Skip("Excluded")
@@ -205,8 +276,16 @@ func (ctx *tc39TestCtx) runTC39File(name string, t testing.TB) {
 			skip = false
 		} else {
 			if meta.Es6id != "" {
-				for _, prefix := range es6IdWhiteList {
-					if strings.HasPrefix(meta.Es6id, prefix) {
+				for _, id := range es6IdWhiteList {
+					if matchSectionId(meta.Es6id, id) {
+						skip = false
+						break
+					}
+				}
+			}
+			if meta.Es6id == "" && meta.Esid != "" {
+				for _, id := range esIdWhiteList {
+					if meta.Esid == id {
 						skip = false
 						break
 					}
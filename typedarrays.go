@@ -0,0 +1,528 @@
+package goja
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+const (
+	classArrayBuffer = "ArrayBuffer"
+	classDataView    = "DataView"
+)
+
+// 类型化数组的元素类型，DataView也使用同样的读写函数
+type typedArrayKind struct {
+	name string
+	size int
+	// 从长度为size的b中读取元素
+	get func(b []byte, order binary.ByteOrder) Value
+	// 把已转换为数字的v写入长度为size的b
+	set func(b []byte, v Value, order binary.ByteOrder)
+	// 导出时使用的Go类型
+	exportType reflect.Type
+	// 元素是BigInt而不是数字
+	bigInt bool
+}
+
+// 类型化数组对象，是ArrayBuffer中一段数据的视图
+type typedArrayObject struct {
+	baseObject
+	kind       *typedArrayKind
+	buffer     *objectArrayBuffer
+	byteOffset int
+	length     int
+}
+
+// DataView对象
+type dataViewObject struct {
+	baseObject
+	buffer     *objectArrayBuffer
+	byteOffset int
+	byteLength int
+}
+
+// ArrayBuffer wraps a JavaScript ArrayBuffer. It can be obtained with Runtime.NewArrayBuffer() and passed
+// to JavaScript using Runtime.ToValue().
+type ArrayBuffer struct {
+	buf *objectArrayBuffer
+}
+
+// 类型化数组的元素按小端字节序保存
+var typedArrayByteOrder binary.ByteOrder = binary.LittleEndian
+
+var (
+	typedArrayInt8 = &typedArrayKind{
+		name: "Int8Array",
+		size: 1,
+		get: func(b []byte, order binary.ByteOrder) Value {
+			return intToValue(int64(int8(b[0])))
+		},
+		set: func(b []byte, v Value, order binary.ByteOrder) {
+			b[0] = byte(toInt32(v))
+		},
+		exportType: reflect.TypeOf([]int8(nil)),
+	}
+	typedArrayUint8 = &typedArrayKind{
+		name: "Uint8Array",
+		size: 1,
+		get: func(b []byte, order binary.ByteOrder) Value {
+			return intToValue(int64(b[0]))
+		},
+		set: func(b []byte, v Value, order binary.ByteOrder) {
+			b[0] = byte(toInt32(v))
+		},
+		exportType: reflect.TypeOf([]byte(nil)),
+	}
+	typedArrayUint8Clamped = &typedArrayKind{
+		name: "Uint8ClampedArray",
+		size: 1,
+		get: func(b []byte, order binary.ByteOrder) Value {
+			return intToValue(int64(b[0]))
+		},
+		set: func(b []byte, v Value, order binary.ByteOrder) {
+			f := v.ToFloat()
+			switch {
+			case math.IsNaN(f) || f <= 0:
+				b[0] = 0
+			case f >= 255:
+				b[0] = 255
+			default:
+				b[0] = byte(math.RoundToEven(f))
+			}
+		},
+		exportType: reflect.TypeOf([]byte(nil)),
+	}
+	typedArrayInt16 = &typedArrayKind{
+		name: "Int16Array",
+		size: 2,
+		get: func(b []byte, order binary.ByteOrder) Value {
+			return intToValue(int64(int16(order.Uint16(b))))
+		},
+		set: func(b []byte, v Value, order binary.ByteOrder) {
+			order.PutUint16(b, uint16(toInt32(v)))
+		},
+		exportType: reflect.TypeOf([]int16(nil)),
+	}
+	typedArrayUint16 = &typedArrayKind{
+		name: "Uint16Array",
+		size: 2,
+		get: func(b []byte, order binary.ByteOrder) Value {
+			return intToValue(int64(order.Uint16(b)))
+		},
+		set: func(b []byte, v Value, order binary.ByteOrder) {
+			order.PutUint16(b, uint16(toInt32(v)))
+		},
+		exportType: reflect.TypeOf([]uint16(nil)),
+	}
+	typedArrayInt32 = &typedArrayKind{
+		name: "Int32Array",
+		size: 4,
+		get: func(b []byte, order binary.ByteOrder) Value {
+			return intToValue(int64(int32(order.Uint32(b))))
+		},
+		set: func(b []byte, v Value, order binary.ByteOrder) {
+			order.PutUint32(b, uint32(toInt32(v)))
+		},
+		exportType: reflect.TypeOf([]int32(nil)),
+	}
+	typedArrayUint32 = &typedArrayKind{
+		name: "Uint32Array",
+		size: 4,
+		get: func(b []byte, order binary.ByteOrder) Value {
+			return intToValue(int64(order.Uint32(b)))
+		},
+		set: func(b []byte, v Value, order binary.ByteOrder) {
+			order.PutUint32(b, toUInt32(v))
+		},
+		exportType: reflect.TypeOf([]uint32(nil)),
+	}
+	typedArrayFloat32 = &typedArrayKind{
+		name: "Float32Array",
+		size: 4,
+		get: func(b []byte, order binary.ByteOrder) Value {
+			return floatToValue(float64(math.Float32frombits(order.Uint32(b))))
+		},
+		set: func(b []byte, v Value, order binary.ByteOrder) {
+			order.PutUint32(b, math.Float32bits(float32(v.ToFloat())))
+		},
+		exportType: reflect.TypeOf([]float32(nil)),
+	}
+	typedArrayFloat64 = &typedArrayKind{
+		name: "Float64Array",
+		size: 8,
+		get: func(b []byte, order binary.ByteOrder) Value {
+			return floatToValue(math.Float64frombits(order.Uint64(b)))
+		},
+		set: func(b []byte, v Value, order binary.ByteOrder) {
+			order.PutUint64(b, math.Float64bits(v.ToFloat()))
+		},
+		exportType: reflect.TypeOf([]float64(nil)),
+	}
+	typedArrayBigInt64 = &typedArrayKind{
+		name: "BigInt64Array",
+		size: 8,
+		get: func(b []byte, order binary.ByteOrder) Value {
+			return (*valueBigInt)(big.NewInt(int64(order.Uint64(b))))
+		},
+		set: func(b []byte, v Value, order binary.ByteOrder) {
+			order.PutUint64(b, bigIntToUint64((*big.Int)(v.(*valueBigInt))))
+		},
+		exportType: reflect.TypeOf([]int64(nil)),
+		bigInt:     true,
+	}
+	typedArrayBigUint64 = &typedArrayKind{
+		name: "BigUint64Array",
+		size: 8,
+		get: func(b []byte, order binary.ByteOrder) Value {
+			return (*valueBigInt)(new(big.Int).SetUint64(order.Uint64(b)))
+		},
+		set: func(b []byte, v Value, order binary.ByteOrder) {
+			order.PutUint64(b, bigIntToUint64((*big.Int)(v.(*valueBigInt))))
+		},
+		exportType: reflect.TypeOf([]uint64(nil)),
+		bigInt:     true,
+	}
+)
+
+var typedArrayKinds = []*typedArrayKind{
+	typedArrayInt8,
+	typedArrayUint8,
+	typedArrayUint8Clamped,
+	typedArrayInt16,
+	typedArrayUint16,
+	typedArrayInt32,
+	typedArrayUint32,
+	typedArrayFloat32,
+	typedArrayFloat64,
+	typedArrayBigInt64,
+	typedArrayBigUint64,
+}
+// 取BigInt的低64位
+func bigIntToUint64(b *big.Int) uint64 {
+	if b.IsUint64() {
+		return b.Uint64()
+	}
+	return new(big.Int).And(b, maxUint64BigInt).Uint64()
+}
+
+var maxUint64BigInt = new(big.Int).SetUint64(math.MaxUint64)
+
+// 把v转换为kind的元素类型要求的值：BigInt数组使用ToBigInt，其它使用ToNumber
+func (r *Runtime) toTypedArrayValue(kind *typedArrayKind, v Value) Value {
+	if kind.bigInt {
+		return (*valueBigInt)(r.toBigInt(v))
+	}
+	return v.ToNumber()
+}
+
+// Bytes returns the underlying byte slice of the ArrayBuffer. Changes made to it are visible to JavaScript
+// and vice versa.
+func (a ArrayBuffer) Bytes() []byte {
+	return a.buf.data
+}
+
+// Uint8Array returns a new Uint8Array that covers the whole buffer.
+func (a ArrayBuffer) Uint8Array() *Object {
+	r := a.buf.val.runtime
+	return r.newTypedArrayObject(typedArrayUint8, a.buf, 0, len(a.buf.data), r.typedArrayProto(typedArrayUint8)).val
+}
+
+// NewArrayBuffer creates a new ArrayBuffer backed by data. The data is not copied, so changes made from
+// Go and from JavaScript (for example through a Uint8Array) are visible to both sides.
+func (r *Runtime) NewArrayBuffer(data []byte) ArrayBuffer {
+	b := r._newArrayBuffer(r.global.ArrayBufferPrototype, nil)
+	b.data = data
+	return ArrayBuffer{buf: b}
+}
+// 判断属性名是否为数字(CanonicalNumericIndexString)，不是有效整数索引时idx为-1
+func typedArrayIndex(n Value) (idx int64, numeric bool) {
+	switch n := n.(type) {
+	case valueInt:
+		if n >= 0 {
+			return int64(n), true
+		}
+		return -1, true
+	case valueFloat:
+		return -1, true
+	case *valueSymbol:
+		return -1, false
+	}
+	return typedArrayIndexStr(n.String())
+}
+// 同typedArrayIndex，参数为字符串
+func typedArrayIndexStr(s string) (idx int64, numeric bool) {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil && strconv.FormatInt(i, 10) == s {
+		if i >= 0 {
+			return i, true
+		}
+		return -1, true
+	}
+	if s == "-0" {
+		return -1, true
+	}
+	if num := newStringValue(s).ToNumber(); num.String() == s {
+		return -1, true
+	}
+	return -1, false
+}
+// 第idx个元素所在的字节
+func (a *typedArrayObject) elem(idx int) []byte {
+	offset := a.byteOffset + idx*a.kind.size
+	return a.buffer.data[offset : offset+a.kind.size]
+}
+// 获取第idx个元素
+func (a *typedArrayObject) getIdx(idx int) Value {
+	return a.kind.get(a.elem(idx), typedArrayByteOrder)
+}
+// 设置第idx个元素，v必须已转换为数字
+func (a *typedArrayObject) setIdx(idx int, v Value) {
+	a.kind.set(a.elem(idx), v, typedArrayByteOrder)
+}
+// 按数字属性获取元素，越界时返回nil
+func (a *typedArrayObject) _getIdx(idx int64) Value {
+	if idx >= 0 && idx < int64(a.length) {
+		return a.getIdx(int(idx))
+	}
+	return nil
+}
+// 按数字属性设置元素，越界时忽略
+func (a *typedArrayObject) _putIdx(idx int64, v Value) {
+	num := a.val.runtime.toTypedArrayValue(a.kind, v)
+	if idx >= 0 && idx < int64(a.length) {
+		a.setIdx(int(idx), num)
+	}
+}
+
+func (a *typedArrayObject) get(n Value) Value {
+	if idx, ok := typedArrayIndex(n); ok {
+		return a._getIdx(idx)
+	}
+	return a.baseObject.get(n)
+}
+
+func (a *typedArrayObject) getStr(name string) Value {
+	if idx, ok := typedArrayIndexStr(name); ok {
+		return a._getIdx(idx)
+	}
+	return a.baseObject.getStr(name)
+}
+
+func (a *typedArrayObject) getProp(n Value) Value {
+	if idx, ok := typedArrayIndex(n); ok {
+		return a._getIdx(idx)
+	}
+	return a.baseObject.getProp(n)
+}
+
+func (a *typedArrayObject) getPropStr(name string) Value {
+	if idx, ok := typedArrayIndexStr(name); ok {
+		return a._getIdx(idx)
+	}
+	return a.baseObject.getPropStr(name)
+}
+
+func (a *typedArrayObject) getOwnProp(name string) Value {
+	if idx, ok := typedArrayIndexStr(name); ok {
+		if v := a._getIdx(idx); v != nil {
+			return &valueProperty{
+				value:        v,
+				writable:     true,
+				enumerable:   true,
+				configurable: true,
+			}
+		}
+		return nil
+	}
+	return a.baseObject.getOwnProp(name)
+}
+
+func (a *typedArrayObject) put(n Value, val Value, throw bool) {
+	if idx, ok := typedArrayIndex(n); ok {
+		a._putIdx(idx, val)
+		return
+	}
+	a.baseObject.put(n, val, throw)
+}
+
+func (a *typedArrayObject) putStr(name string, val Value, throw bool) {
+	if idx, ok := typedArrayIndexStr(name); ok {
+		a._putIdx(idx, val)
+		return
+	}
+	a.baseObject.putStr(name, val, throw)
+}
+
+func (a *typedArrayObject) hasProperty(n Value) bool {
+	if idx, ok := typedArrayIndex(n); ok {
+		return idx >= 0 && idx < int64(a.length)
+	}
+	return a.baseObject.hasProperty(n)
+}
+
+func (a *typedArrayObject) hasPropertyStr(name string) bool {
+	if idx, ok := typedArrayIndexStr(name); ok {
+		return idx >= 0 && idx < int64(a.length)
+	}
+	return a.baseObject.hasPropertyStr(name)
+}
+
+func (a *typedArrayObject) hasOwnProperty(n Value) bool {
+	if idx, ok := typedArrayIndex(n); ok {
+		return idx >= 0 && idx < int64(a.length)
+	}
+	return a.baseObject.hasOwnProperty(n)
+}
+
+func (a *typedArrayObject) hasOwnPropertyStr(name string) bool {
+	if idx, ok := typedArrayIndexStr(name); ok {
+		return idx >= 0 && idx < int64(a.length)
+	}
+	return a.baseObject.hasOwnPropertyStr(name)
+}
+// 元素只能定义为可写、可枚举、可删除的数据属性
+func (a *typedArrayObject) defineOwnProperty(n Value, descr propertyDescr, throw bool) bool {
+	if idx, ok := typedArrayIndex(n); ok {
+		if idx < 0 || idx >= int64(a.length) {
+			a.val.runtime.typeErrorResult(throw, "Invalid typed array index")
+			return false
+		}
+		if descr.Getter != nil || descr.Setter != nil || descr.Configurable == FLAG_FALSE ||
+			descr.Enumerable == FLAG_FALSE || descr.Writable == FLAG_FALSE {
+			a.val.runtime.typeErrorResult(throw, "Cannot redefine property: %s", n.String())
+			return false
+		}
+		if descr.Value != nil {
+			a._putIdx(idx, descr.Value)
+		}
+		return true
+	}
+	return a.baseObject.defineOwnProperty(n, descr, throw)
+}
+
+func (a *typedArrayObject) deleteStr(name string, throw bool) bool {
+	if idx, ok := typedArrayIndexStr(name); ok {
+		if idx >= 0 && idx < int64(a.length) {
+			a.val.runtime.typeErrorResult(throw, "Cannot delete property '%s' of %s", name, a.val.ToString())
+			return false
+		}
+		return true
+	}
+	return a.baseObject.deleteStr(name, throw)
+}
+
+func (a *typedArrayObject) delete(n Value, throw bool) bool {
+	if _, ok := n.(*valueSymbol); ok {
+		return a.baseObject.delete(n, throw)
+	}
+	return a.deleteStr(n.String(), throw)
+}
+
+// 类型化数组的属性迭代器，先遍历元素
+type typedArrayPropIter struct {
+	a         *typedArrayObject
+	recursive bool
+	idx       int
+}
+
+func (i *typedArrayPropIter) next() (propIterItem, iterNextFunc) {
+	if i.idx < i.a.length {
+		name := strconv.Itoa(i.idx)
+		i.idx++
+		return propIterItem{name: name, enumerable: _ENUM_TRUE}, i.next
+	}
+
+	return i.a.baseObject._enumerate(i.recursive)()
+}
+
+func (a *typedArrayObject) enumerate(all, recursive bool) iterNextFunc {
+	return (&propFilterIter{
+		wrapped: a._enumerate(recursive),
+		all:     all,
+		seen:    make(map[string]bool),
+	}).next
+}
+
+func (a *typedArrayObject) _enumerate(recursive bool) iterNextFunc {
+	return (&typedArrayPropIter{
+		a:         a,
+		recursive: recursive,
+	}).next
+}
+// Uint8Array和Uint8ClampedArray导出为共享内存的[]byte，其它类型导出为复制的切片
+func (a *typedArrayObject) export() interface{} {
+	if a.kind.size == 1 && a.kind != typedArrayInt8 {
+		return a.buffer.data[a.byteOffset : a.byteOffset+a.length]
+	}
+	s := reflect.MakeSlice(a.kind.exportType, a.length, a.length)
+	if a.kind.bigInt {
+		// 直接按位读取，不经过*big.Int
+		for i := 0; i < a.length; i++ {
+			bits := typedArrayByteOrder.Uint64(a.elem(i))
+			if a.kind == typedArrayBigInt64 {
+				s.Index(i).SetInt(int64(bits))
+			} else {
+				s.Index(i).SetUint(bits)
+			}
+		}
+		return s.Interface()
+	}
+	for i := 0; i < a.length; i++ {
+		s.Index(i).Set(reflect.ValueOf(a.getIdx(i).Export()).Convert(a.kind.exportType.Elem()))
+	}
+	return s.Interface()
+}
+
+func (a *typedArrayObject) exportType() reflect.Type {
+	return a.kind.exportType
+}
+
+func (a *typedArrayObject) sortLen() int64 {
+	return int64(a.length)
+}
+
+func (a *typedArrayObject) sortGet(i int64) Value {
+	return a.getIdx(int(i))
+}
+
+func (a *typedArrayObject) swap(i, j int64) {
+	x, y := a.elem(int(i)), a.elem(int(j))
+	for k := range x {
+		x[k], y[k] = y[k], x[k]
+	}
+}
+// 创建类型化数组，使用buffer中从byteOffset开始的length个元素
+func (r *Runtime) newTypedArrayObject(kind *typedArrayKind, buffer *objectArrayBuffer, byteOffset, length int, proto *Object) *typedArrayObject {
+	o := &Object{runtime: r}
+	a := &typedArrayObject{
+		kind:       kind,
+		buffer:     buffer,
+		byteOffset: byteOffset,
+		length:     length,
+	}
+	a.class = kind.name
+	a.val = o
+	a.extensible = true
+	o.self = a
+	a.prototype = proto
+	a.init()
+	return a
+}
+// 创建DataView对象
+func (r *Runtime) newDataViewObject(buffer *objectArrayBuffer, byteOffset, byteLength int, proto *Object) *dataViewObject {
+	o := &Object{runtime: r}
+	d := &dataViewObject{
+		buffer:     buffer,
+		byteOffset: byteOffset,
+		byteLength: byteLength,
+	}
+	d.class = classDataView
+	d.val = o
+	d.extensible = true
+	o.self = d
+	d.prototype = proto
+	d.init()
+	return d
+}
@@ -2,6 +2,7 @@ package goja
 
 import (
 	"math"
+	"math/big"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -28,6 +29,7 @@ var (
 	reflectTypeMap    = reflect.TypeOf(map[string]interface{}{})
 	reflectTypeArray  = reflect.TypeOf([]interface{}{})
 	reflectTypeString = reflect.TypeOf("")
+	reflectTypeBigInt = reflect.TypeOf((*big.Int)(nil))
 )
 
 var intCache [256]Value
@@ -61,6 +63,13 @@ type valueUndefined struct {
 	valueNull
 }
 
+// BigInt值，创建后不再修改
+type valueBigInt big.Int
+
+type valueSymbol struct {
+	descr string // 描述，仅用于显示
+}
+
 type valueUnresolved struct {
 	r   *Runtime
 	ref string
@@ -154,6 +163,9 @@ func (i valueInt) Equals(other Value) bool {
 	if o, ok := other.(valueBool); ok {
 		return int64(i) == o.ToInteger()
 	}
+	if o, ok := other.(*valueBigInt); ok {
+		return o.Equals(i)
+	}
 	if o, ok := other.(*Object); ok {
 		return i.Equals(o.self.toPrimitiveNumber())
 	}
@@ -575,6 +587,10 @@ func (f valueFloat) Equals(other Value) bool {
 		return float64(f) == o.ToFloat()
 	}
 
+	if o, ok := other.(*valueBigInt); ok {
+		return o.Equals(f)
+	}
+
 	if o, ok := other.(*Object); ok {
 		return f.Equals(o.self.toPrimitiveNumber())
 	}
@@ -670,6 +686,14 @@ func (o *Object) Equals(other Value) bool {
 	if _, ok := other.assertString(); ok {
 		return o.self.toPrimitive().Equals(other)
 	}
+
+	if _, ok := other.(*valueSymbol); ok {
+		return o.self.toPrimitive().Equals(other)
+	}
+
+	if _, ok := other.(*valueBigInt); ok {
+		return o.self.toPrimitive().Equals(other)
+	}
 	return false
 }
 
@@ -748,6 +772,27 @@ func (o *Object) Set(name string, value interface{}) error {
 	})
 }
 
+// GetSymbol returns the value of the property keyed by the given symbol (e.g. SymIterator).
+// Returns nil if sym is not a symbol or the property does not exist.
+func (o *Object) GetSymbol(sym Value) Value {
+	if s, ok := sym.(*valueSymbol); ok {
+		return o.self.get(s)
+	}
+	return nil
+}
+
+// SetSymbol sets the property keyed by the given symbol, e.g. o.SetSymbol(goja.SymIterator, fn)
+// makes a Go-backed object iterable.
+func (o *Object) SetSymbol(sym Value, value interface{}) error {
+	return tryFunc(func() {
+		s, ok := sym.(*valueSymbol)
+		if !ok {
+			panic(o.runtime.NewTypeError("%s is not a symbol", sym))
+		}
+		o.self.put(s, o.runtime.ToValue(value), true)
+	})
+}
+
 // MarshalJSON returns JSON representation of the Object. It is equivalent to JSON.stringify(o).
 // Note, this implements json.Marshaler so that json.Marshal() can be used without the need to Export().
 func (o *Object) MarshalJSON() ([]byte, error) {
@@ -854,6 +899,165 @@ func (o valueUnresolved) ExportType() reflect.Type {
 	return nil
 }
 
+// symbol不能隐式转换为数字或字符串
+func (s *valueSymbol) ToInteger() int64 {
+	panic(typeError("Cannot convert a Symbol value to a number"))
+}
+
+func (s *valueSymbol) ToString() valueString {
+	panic(typeError("Cannot convert a Symbol value to a string"))
+}
+// 返回Symbol(描述)的形式，用于显示
+func (s *valueSymbol) String() string {
+	return "Symbol(" + s.descr + ")"
+}
+
+func (s *valueSymbol) ToFloat() float64 {
+	panic(typeError("Cannot convert a Symbol value to a number"))
+}
+
+func (s *valueSymbol) ToNumber() Value {
+	panic(typeError("Cannot convert a Symbol value to a number"))
+}
+
+func (s *valueSymbol) ToBoolean() bool {
+	return true
+}
+
+func (s *valueSymbol) ToObject(r *Runtime) *Object {
+	return r.newPrimitiveObject(s, r.global.SymbolPrototype, classSymbol)
+}
+// symbol只和自身相等
+func (s *valueSymbol) SameAs(other Value) bool {
+	if s1, ok := other.(*valueSymbol); ok {
+		return s == s1
+	}
+	return false
+}
+
+func (s *valueSymbol) Equals(other Value) bool {
+	if o, ok := other.(*Object); ok {
+		if p, ok := o.self.(*primitiveValueObject); ok {
+			return s.SameAs(p.pValue)
+		}
+	}
+	return s.SameAs(other)
+}
+
+func (s *valueSymbol) StrictEquals(other Value) bool {
+	return s.SameAs(other)
+}
+
+func (s *valueSymbol) assertInt() (int64, bool) {
+	return 0, false
+}
+
+func (s *valueSymbol) assertFloat() (float64, bool) {
+	return 0, false
+}
+
+func (s *valueSymbol) assertString() (valueString, bool) {
+	return nil, false
+}
+
+func (s *valueSymbol) baseObject(r *Runtime) *Object {
+	return r.global.SymbolPrototype
+}
+
+func (s *valueSymbol) Export() interface{} {
+	return s.String()
+}
+
+func (s *valueSymbol) ExportType() reflect.Type {
+	return reflectTypeString
+}
+
+// BigInt不能隐式转换为数字
+func (b *valueBigInt) ToInteger() int64 {
+	panic(typeError("Cannot convert a BigInt value to a number"))
+}
+
+func (b *valueBigInt) ToString() valueString {
+	return asciiString(b.String())
+}
+// 转10进制字符串
+func (b *valueBigInt) String() string {
+	return (*big.Int)(b).String()
+}
+
+func (b *valueBigInt) ToFloat() float64 {
+	panic(typeError("Cannot convert a BigInt value to a number"))
+}
+
+func (b *valueBigInt) ToNumber() Value {
+	panic(typeError("Cannot convert a BigInt value to a number"))
+}
+
+func (b *valueBigInt) ToBoolean() bool {
+	return (*big.Int)(b).Sign() != 0
+}
+
+func (b *valueBigInt) ToObject(r *Runtime) *Object {
+	return r.newPrimitiveObject(b, r.global.BigIntPrototype, classBigInt)
+}
+// 值相同的BigInt相等
+func (b *valueBigInt) SameAs(other Value) bool {
+	if o, ok := other.(*valueBigInt); ok {
+		return (*big.Int)(b).Cmp((*big.Int)(o)) == 0
+	}
+	return false
+}
+// 与数字比较数学上的值，与字符串比较时先把字符串转换为BigInt
+func (b *valueBigInt) Equals(other Value) bool {
+	switch o := other.(type) {
+	case *valueBigInt:
+		return b.SameAs(o)
+	case valueInt:
+		return (*big.Int)(b).IsInt64() && (*big.Int)(b).Int64() == int64(o)
+	case valueFloat:
+		c, ok := compareBigIntFloat((*big.Int)(b), float64(o))
+		return ok && c == 0
+	case valueBool:
+		return b.Equals(o.ToNumber())
+	case valueString:
+		if v := stringToBigInt(o.String()); v != nil {
+			return (*big.Int)(b).Cmp(v) == 0
+		}
+		return false
+	case *Object:
+		return b.Equals(o.self.toPrimitive())
+	}
+	return false
+}
+
+func (b *valueBigInt) StrictEquals(other Value) bool {
+	return b.SameAs(other)
+}
+
+func (b *valueBigInt) assertInt() (int64, bool) {
+	return 0, false
+}
+
+func (b *valueBigInt) assertFloat() (float64, bool) {
+	return 0, false
+}
+
+func (b *valueBigInt) assertString() (valueString, bool) {
+	return nil, false
+}
+
+func (b *valueBigInt) baseObject(r *Runtime) *Object {
+	return r.global.BigIntPrototype
+}
+// 导出为*big.Int的副本
+func (b *valueBigInt) Export() interface{} {
+	return new(big.Int).Set((*big.Int)(b))
+}
+
+func (b *valueBigInt) ExportType() reflect.Type {
+	return reflectTypeBigInt
+}
+
 func init() {
 	for i := 0; i < 256; i++ {
 		intCache[i] = valueInt(i - 128)
@@ -3,6 +3,7 @@ package goja
 import (
 	"fmt"
 	"math"
+	"math/big"
 	"runtime"
 	"strconv"
 	"sync"
@@ -11,6 +12,13 @@ import (
 
 const (
 	maxInt = 1 << 53
+
+	// 调用栈默认的最大深度
+	defaultMaxCallStackSize = 10000
+	// vm.run嵌套深度的上限，每层都占用Go栈，不受SetMaxCallStackSize影响
+	maxRunDepth = 1 << 15
+	// 栈溢出异常记录的最大帧数
+	maxOverflowStackFrames = 10
 )
 
 type valueStack []Value
@@ -21,20 +29,27 @@ type stash struct {
 	names     map[string]uint32
 	obj       objectImpl
 
+	// let/const块级作用域的stash，var声明不会绑定到这里
+	block bool
+	// 其中的const绑定
+	consts map[string]bool
+
 	outer *stash
 }
 
 type context struct {
-	prg      *Program
-	funcName string
-	stash    *stash
-	pc, sb   int
-	args     int
+	prg       *Program
+	funcName  string
+	stash     *stash
+	pc, sb    int
+	args      int
+	newTarget *Object
 }
 
 type iterStackItem struct {
-	val Value
-	f   iterNextFunc
+	val  Value
+	f    iterNextFunc
+	iter *Object // for-of使用的迭代器
 }
 
 type ref interface {
@@ -60,6 +75,31 @@ func (r *stashRef) refname() string {
 	return r.n
 }
 
+// let/const绑定的引用，读写时检查暂时性死区，并且不允许给const赋值
+type lexicalRef struct {
+	stashRef
+	r        *Runtime
+	constant bool
+}
+
+func (r *lexicalRef) get() Value {
+	v := *r.v
+	if v == nil {
+		r.r.throwUninitializedError(r.n)
+	}
+	return v
+}
+
+func (r *lexicalRef) set(v Value) {
+	if *r.v == nil {
+		r.r.throwUninitializedError(r.n)
+	}
+	if r.constant {
+		r.r.throwConstAssignError()
+	}
+	*r.v = v
+}
+
 type objRef struct {
 	base   objectImpl
 	name   string
@@ -103,15 +143,32 @@ type vm struct {
 	pc           int
 	stack        valueStack
 	sp, sb, args int
+	// 通过new调用时为被构造的函数，派生类的super()用它确定新对象的原型
+	newTarget *Object
 
 	stash     *stash
 	callStack []context
 	iterStack []iterStackItem
 	refStack  []ref
+	tryStack  []*tryFrame
+
+	// 正在执行的生成器，suspended表示生成器执行了yield，正在退出各层try
+	gen       *generatorObject
+	suspended bool
 
 	stashAllocs int
 	halt        bool
 
+	// 调用栈的最大深度，超过时抛出RangeError
+	maxCallStackSize int
+	// vm.run在Go栈上的嵌套深度(原生函数回调JS、try语句等)
+	runDepth int
+
+	// 已使用的gas和限制，0表示不限制
+	gasUsed    int64
+	gasLimit   int64
+	gasHandler GasHandler
+
 	interrupted   uint32
 	interruptVal  interface{}
 	interruptLock sync.Mutex
@@ -167,6 +224,18 @@ func toInt(v Value) (int64, bool) {
 	}
 	return 0, false
 }
+// 二元运算的操作数都是BigInt时返回它们的值，只有一个是BigInt时抛出TypeError
+func bigIntOperands(left, right Value) (x, y *big.Int, ok bool) {
+	lb, lok := left.(*valueBigInt)
+	rb, rok := right.(*valueBigInt)
+	if lok && rok {
+		return (*big.Int)(lb), (*big.Int)(rb), true
+	}
+	if lok || rok {
+		panic(typeError("Cannot mix BigInt and other types, use explicit conversions"))
+	}
+	return nil, nil, false
+}
 // value转int，忽略负0
 func toIntIgnoreNegZero(v Value) (int64, bool) {
 	num := v.ToNumber()
@@ -200,7 +269,7 @@ func (s *valueStack) expand(idx int) {
 // 更新name的值为v，这里比较奇怪，为啥不是添加数据 elikong
 func (s *stash) put(name string, v Value) bool {
 	if s.obj != nil {
-		if found := s.obj.getStr(name); found != nil {
+		if s.obj.hasPropertyStr(name) {
 			s.obj.putStr(name, v, false)
 			return true
 		}
@@ -232,11 +301,15 @@ func (s *stash) getByIdx(idx uint32) Value {
 // 按name获取
 func (s *stash) getByName(name string, _ *vm) (v Value, exists bool) {
 	if s.obj != nil {
-		v = s.obj.getStr(name)
-		if v == nil {
+		// 先确认属性存在，proxy的get trap对不存在的属性也返回undefined
+		if !s.obj.hasPropertyStr(name) {
 			return nil, false
 			//return valueUnresolved{r: vm.r, ref: name}, false
 		}
+		v = s.obj.getStr(name)
+		if v == nil {
+			v = _undefined
+		}
 		return v, true
 	}
 	if idx, exists := s.names[name]; exists {
@@ -267,8 +340,27 @@ func (s *stash) deleteBinding(name string) bool {
 	}
 	return false
 }
+// 构造stash中idx位置绑定的引用
+func (s *stash) ref(idx uint32, name string, r *Runtime) ref {
+	v := &s.values[idx]
+	if *v == nil || s.consts[name] {
+		return &lexicalRef{
+			stashRef: stashRef{
+				v: v,
+				n: name,
+			},
+			r:        r,
+			constant: s.consts[name],
+		}
+	}
+	return &stashRef{
+		v: v,
+		n: name,
+	}
+}
 // 创建一个存储
 func (vm *vm) newStash() {
+	vm.r.allocate(sizeofStash)
 	vm.stash = &stash{
 		outer: vm.stash,
 	}
@@ -276,6 +368,7 @@ func (vm *vm) newStash() {
 }
 
 func (vm *vm) init() {
+	vm.maxCallStackSize = defaultMaxCallStackSize
 }
 
 func (vm *vm) SetProgram(prg *Program)  {
@@ -288,6 +381,10 @@ func (vm *vm) Run()  {
 
 // 虚拟机执行，会被中断
 func (vm *vm) run() {
+	if vm.runDepth >= maxRunDepth || vm.runDepth >= vm.maxCallStackSize {
+		vm.throwStackOverflow()
+	}
+	vm.runDepth++
 	vm.halt = false
 	interrupted := false
 	ticks := 0
@@ -295,6 +392,7 @@ func (vm *vm) run() {
 		if interrupted = atomic.LoadUint32(&vm.interrupted) != 0; interrupted {
 			break
 		}
+		vm.useGas(1)
 		vm.prg.code[vm.pc].exec(vm)
 		ticks++
 		if ticks > 10000 {
@@ -303,6 +401,7 @@ func (vm *vm) run() {
 			ticks = 0
 		}
 	}
+	vm.runDepth--
 
 	if interrupted {
 		vm.interruptLock.Lock()
@@ -337,34 +436,92 @@ func (vm *vm) captureStack(stack []stackFrame, ctxOffset int) []stackFrame {
 	}
 	return stack
 }
+// 调用栈溢出时抛出RangeError，记录的调用栈只保留最近的maxOverflowStackFrames帧
+func (vm *vm) throwStackOverflow() {
+	ex := &Exception{
+		val:       vm.r.newError(vm.r.global.RangeError, "Maximum call stack size exceeded"),
+		truncated: true,
+	}
+	ctxOffset := len(vm.callStack) - maxOverflowStackFrames + 1
+	if ctxOffset < 0 {
+		ctxOffset = 0
+	}
+	ex.stack = vm.captureStack(make([]stackFrame, 0, maxOverflowStackFrames), ctxOffset)
+	panic(ex)
+}
+// 发生异常时vm.try恢复的状态
+type tryScope struct {
+	ctx       context
+	ctxOffset int
+	sp        int
+	iterLen   int
+	refLen    int
+	tryLen    int
+}
+// 记录当前状态
+func (vm *vm) saveTryScope(s *tryScope) {
+	vm.saveCtx(&s.ctx)
+	s.ctxOffset = len(vm.callStack)
+	s.sp = vm.sp
+	s.iterLen = len(vm.iterStack)
+	s.refLen = len(vm.refStack)
+	s.tryLen = len(vm.tryStack)
+}
+// 生成器的栈帧移动位置时调整记录的状态
+func (s *tryScope) shift(sp, ctxOffset, iterLen, refLen, tryLen int) {
+	s.ctx.sb += sp
+	s.sp += sp
+	s.ctxOffset += ctxOffset
+	s.iterLen += iterLen
+	s.refLen += refLen
+	s.tryLen += tryLen
+}
 // try的执行，捕获异常
 func (vm *vm) try(f func()) (ex *Exception) {
-	var ctx context
-	vm.saveCtx(&ctx)
-
-	ctxOffset := len(vm.callStack)
-	sp := vm.sp
-	iterLen := len(vm.iterStack)
-	refLen := len(vm.refStack)
+	var s tryScope
+	vm.saveTryScope(&s)
+	return vm.tryIn(&s, f)
+}
+// 执行f，发生异常时恢复到s记录的状态
+func (vm *vm) tryIn(s *tryScope, f func()) (ex *Exception) {
+	ctxOffset := s.ctxOffset
+	runDepth := vm.runDepth
 
 	defer func() {
 		if x := recover(); x != nil {
 			defer func() {
+				vm.runDepth = runDepth
 				vm.callStack = vm.callStack[:ctxOffset]
-				vm.restoreCtx(&ctx)
-				vm.sp = sp
+				vm.restoreCtx(&s.ctx)
+				vm.sp = s.sp
 
 				// Restore other stacks
-				iterTail := vm.iterStack[iterLen:]
-				for i := range iterTail {
+				iterTail := vm.iterStack[s.iterLen:]
+				var iters []*Object
+				for i := len(iterTail) - 1; i >= 0; i-- {
+					if iter := iterTail[i].iter; iter != nil && ex != nil {
+						iters = append(iters, iter)
+					}
 					iterTail[i] = iterStackItem{}
 				}
-				vm.iterStack = vm.iterStack[:iterLen]
-				refTail := vm.refStack[refLen:]
+				vm.iterStack = vm.iterStack[:s.iterLen]
+				refTail := vm.refStack[s.refLen:]
 				for i := range refTail {
 					refTail[i] = nil
 				}
-				vm.refStack = vm.refStack[:refLen]
+				vm.refStack = vm.refStack[:s.refLen]
+				tryTail := vm.tryStack[s.tryLen:]
+				for i := range tryTail {
+					tryTail[i] = nil
+				}
+				vm.tryStack = vm.tryStack[:s.tryLen]
+
+				// 异常跳出for-of循环或数组解构时由内向外关闭迭代器，关闭时的异常被忽略
+				for _, iter := range iters {
+					vm.try(func() {
+						vm.r.iteratorClose(iter)
+					})
+				}
 			}()
 			switch x1 := x.(type) {
 			case Value:
@@ -374,8 +531,18 @@ func (vm *vm) try(f func()) (ex *Exception) {
 			case *InterruptedError:
 				x1.stack = vm.captureStack(x1.stack, ctxOffset)
 				panic(x1)
+			case *MemoryLimitError:
+				x1.stack = vm.captureStack(x1.stack, ctxOffset)
+				panic(x1)
+			case *GasLimitError:
+				x1.stack = vm.captureStack(x1.stack, ctxOffset)
+				panic(x1)
 			case *Exception:
 				ex = x1
+			case typeError:
+				ex = &Exception{
+					val: vm.r.NewTypeError(string(x1)),
+				}
 			default:
 				/*
 					if vm.prg != nil {
@@ -386,7 +553,9 @@ func (vm *vm) try(f func()) (ex *Exception) {
 				*/
 				panic(x)
 			}
-			ex.stack = vm.captureStack(ex.stack, ctxOffset)
+			if !ex.truncated {
+				ex.stack = vm.captureStack(ex.stack, ctxOffset)
+			}
 		}
 	}()
 
@@ -420,9 +589,13 @@ func (vm *vm) saveCtx(ctx *context) {
 	ctx.pc = vm.pc
 	ctx.sb = vm.sb
 	ctx.args = vm.args
+	ctx.newTarget = vm.newTarget
 }
 // ctx入栈
 func (vm *vm) pushCtx() {
+	if len(vm.callStack) >= vm.maxCallStackSize {
+		vm.throwStackOverflow()
+	}
 	/*
 		vm.ctxStack = append(vm.ctxStack, context{
 			prg: vm.prg,
@@ -442,6 +615,7 @@ func (vm *vm) restoreCtx(ctx *context) {
 	vm.stash = ctx.stash
 	vm.sb = ctx.sb
 	vm.args = ctx.args
+	vm.newTarget = ctx.newTarget
 }
 // 出栈ctx，同时恢复虚拟机
 func (vm *vm) popCtx() {
@@ -454,6 +628,8 @@ func (vm *vm) popCtx() {
 	vm.callStack[l].stash = nil
 	vm.sb = vm.callStack[l].sb
 	vm.args = vm.callStack[l].args
+	vm.newTarget = vm.callStack[l].newTarget
+	vm.callStack[l].newTarget = nil
 
 	vm.callStack = vm.callStack[:l]
 }
@@ -485,6 +661,24 @@ func (r *Runtime) toCallee(v Value) *Object {
 	r.typeErrorResult(true, "Value is not an object: %s", v.ToString())
 	panic("Unreachable")
 }
+// 对象是否可以作为构造函数
+func (r *Runtime) isConstructor(obj *Object) bool {
+repeat:
+	switch f := obj.self.(type) {
+	case *funcObject:
+		return f.isConstructor()
+	case *nativeFuncObject:
+		return f.construct != nil
+	case *boundFuncObject:
+		return f.construct != nil
+	case *Proxy:
+		return r.isConstructor(f.target)
+	case *lazyObject:
+		obj.self = f.create(obj)
+		goto repeat
+	}
+	return false
+}
 
 type _newStash struct{}
 
@@ -591,9 +785,41 @@ func (s storeStackP) exec(vm *vm) {
 type _toNumber struct{}
 
 var toNumber _toNumber
-// toNumber指令执行
+// toNumber指令执行，用于++和--，BigInt保持不变
 func (_toNumber) exec(vm *vm) {
-	vm.stack[vm.sp-1] = vm.stack[vm.sp-1].ToNumber()
+	vm.stack[vm.sp-1] = toNumeric(vm.stack[vm.sp-1])
+	vm.pc++
+}
+
+type _toStringVal struct{}
+
+var toStringVal _toStringVal
+// toStringVal指令执行，用于模板字符串中的替换值
+func (_toStringVal) exec(vm *vm) {
+	vm.stack[vm.sp-1] = vm.stack[vm.sp-1].ToString()
+	vm.pc++
+}
+
+// 带标签模板的字符串数组，每个模板在每个Runtime中只创建一次
+type getTemplateObject struct {
+	cooked, raw []Value
+}
+// getTemplateObject指令执行
+func (t *getTemplateObject) exec(vm *vm) {
+	r := vm.r
+	obj := r.templateObjects[t]
+	if obj == nil {
+		rawObj := r.newArrayValues(append([]Value(nil), t.raw...))
+		r.object_freeze(FunctionCall{Arguments: []Value{rawObj}})
+		obj = r.newArrayValues(append([]Value(nil), t.cooked...))
+		obj.self._putProp("raw", rawObj, false, false, false)
+		r.object_freeze(FunctionCall{Arguments: []Value{obj}})
+		if r.templateObjects == nil {
+			r.templateObjects = make(map[*getTemplateObject]*Object)
+		}
+		r.templateObjects[t] = obj
+	}
+	vm.push(obj)
 	vm.pc++
 }
 
@@ -625,7 +851,10 @@ func (_add) exec(vm *vm) {
 		if !isRightString {
 			rightString = right.ToString()
 		}
+		vm.r.allocateString(leftString.length() + rightString.length())
 		ret = leftString.concat(rightString)
+	} else if x, y, ok := bigIntOperands(left, right); ok {
+		ret = vm.r.checkBigIntSize(new(big.Int).Add(x, y))
 	} else {
 		if leftInt, ok := left.assertInt(); ok {
 			if rightInt, ok := right.assertInt(); ok {
@@ -660,6 +889,12 @@ func (_sub) exec(vm *vm) {
 		}
 	}
 
+	left, right = toNumeric(left), toNumeric(right)
+	if x, y, ok := bigIntOperands(left, right); ok {
+		result = vm.r.checkBigIntSize(new(big.Int).Sub(x, y))
+		goto end
+	}
+
 	result = floatToValue(left.ToFloat() - right.ToFloat())
 end:
 	vm.sp--
@@ -672,11 +907,16 @@ type _mul struct{}
 var mul _mul
 // mul指令执行
 func (_mul) exec(vm *vm) {
-	left := vm.stack[vm.sp-2]
-	right := vm.stack[vm.sp-1]
+	left := toNumeric(vm.stack[vm.sp-2])
+	right := toNumeric(vm.stack[vm.sp-1])
 
 	var result Value
 
+	if x, y, ok := bigIntOperands(left, right); ok {
+		result = vm.r.checkBigIntSize(new(big.Int).Mul(x, y))
+		goto end
+	}
+
 	if left, ok := toInt(left); ok {
 		if right, ok := toInt(right); ok {
 			if left == 0 && right == -1 || left == -1 && right == 0 {
@@ -706,10 +946,22 @@ type _div struct{}
 var div _div
 // div指令执行
 func (_div) exec(vm *vm) {
-	left := vm.stack[vm.sp-2].ToFloat()
-	right := vm.stack[vm.sp-1].ToFloat()
+	leftNum := toNumeric(vm.stack[vm.sp-2])
+	rightNum := toNumeric(vm.stack[vm.sp-1])
 
 	var result Value
+	var left, right float64
+
+	if x, y, ok := bigIntOperands(leftNum, rightNum); ok {
+		if y.Sign() == 0 {
+			panic(vm.r.newError(vm.r.global.RangeError, "Division by zero"))
+		}
+		result = (*valueBigInt)(new(big.Int).Quo(x, y))
+		goto end
+	}
+
+	left = leftNum.ToFloat()
+	right = rightNum.ToFloat()
 
 	if math.IsNaN(left) || math.IsNaN(right) {
 		result = _NaN
@@ -765,11 +1017,19 @@ type _mod struct{}
 var mod _mod
 // mod指令执行
 func (_mod) exec(vm *vm) {
-	left := vm.stack[vm.sp-2]
-	right := vm.stack[vm.sp-1]
+	left := toNumeric(vm.stack[vm.sp-2])
+	right := toNumeric(vm.stack[vm.sp-1])
 
 	var result Value
 
+	if x, y, ok := bigIntOperands(left, right); ok {
+		if y.Sign() == 0 {
+			panic(vm.r.newError(vm.r.global.RangeError, "Division by zero"))
+		}
+		result = (*valueBigInt)(new(big.Int).Rem(x, y))
+		goto end
+	}
+
 	if leftInt, ok := toInt(left); ok {
 		if rightInt, ok := toInt(right); ok {
 			if rightInt == 0 {
@@ -798,11 +1058,13 @@ type _neg struct{}
 var neg _neg
 // neg指令执行
 func (_neg) exec(vm *vm) {
-	operand := vm.stack[vm.sp-1]
+	operand := toNumeric(vm.stack[vm.sp-1])
 
 	var result Value
 
-	if i, ok := toInt(operand); ok {
+	if b, ok := operand.(*valueBigInt); ok {
+		result = (*valueBigInt)(new(big.Int).Neg((*big.Int)(b)))
+	} else if i, ok := toInt(operand); ok {
 		if i == 0 {
 			result = _negativeZero
 		} else {
@@ -836,6 +1098,11 @@ var inc _inc
 func (_inc) exec(vm *vm) {
 	v := vm.stack[vm.sp-1]
 
+	if b, ok := v.(*valueBigInt); ok {
+		v = (*valueBigInt)(new(big.Int).Add((*big.Int)(b), bigIntOne))
+		goto end
+	}
+
 	if i, ok := toInt(v); ok {
 		v = intToValue(i + 1)
 		goto end
@@ -855,6 +1122,11 @@ var dec _dec
 func (_dec) exec(vm *vm) {
 	v := vm.stack[vm.sp-1]
 
+	if b, ok := v.(*valueBigInt); ok {
+		v = (*valueBigInt)(new(big.Int).Sub((*big.Int)(b), bigIntOne))
+		goto end
+	}
+
 	if i, ok := toInt(v); ok {
 		v = intToValue(i - 1)
 		goto end
@@ -872,9 +1144,13 @@ type _and struct{}
 var and _and
 // and指令执行
 func (_and) exec(vm *vm) {
-	left := toInt32(vm.stack[vm.sp-2])
-	right := toInt32(vm.stack[vm.sp-1])
-	vm.stack[vm.sp-2] = intToValue(int64(left & right))
+	left := toNumeric(vm.stack[vm.sp-2])
+	right := toNumeric(vm.stack[vm.sp-1])
+	if x, y, ok := bigIntOperands(left, right); ok {
+		vm.stack[vm.sp-2] = (*valueBigInt)(new(big.Int).And(x, y))
+	} else {
+		vm.stack[vm.sp-2] = intToValue(int64(toInt32(left) & toInt32(right)))
+	}
 	vm.sp--
 	vm.pc++
 }
@@ -884,9 +1160,13 @@ type _or struct{}
 var or _or
 // or指令执行
 func (_or) exec(vm *vm) {
-	left := toInt32(vm.stack[vm.sp-2])
-	right := toInt32(vm.stack[vm.sp-1])
-	vm.stack[vm.sp-2] = intToValue(int64(left | right))
+	left := toNumeric(vm.stack[vm.sp-2])
+	right := toNumeric(vm.stack[vm.sp-1])
+	if x, y, ok := bigIntOperands(left, right); ok {
+		vm.stack[vm.sp-2] = (*valueBigInt)(new(big.Int).Or(x, y))
+	} else {
+		vm.stack[vm.sp-2] = intToValue(int64(toInt32(left) | toInt32(right)))
+	}
 	vm.sp--
 	vm.pc++
 }
@@ -896,9 +1176,13 @@ type _xor struct{}
 var xor _xor
 // xor指令执行
 func (_xor) exec(vm *vm) {
-	left := toInt32(vm.stack[vm.sp-2])
-	right := toInt32(vm.stack[vm.sp-1])
-	vm.stack[vm.sp-2] = intToValue(int64(left ^ right))
+	left := toNumeric(vm.stack[vm.sp-2])
+	right := toNumeric(vm.stack[vm.sp-1])
+	if x, y, ok := bigIntOperands(left, right); ok {
+		vm.stack[vm.sp-2] = (*valueBigInt)(new(big.Int).Xor(x, y))
+	} else {
+		vm.stack[vm.sp-2] = intToValue(int64(toInt32(left) ^ toInt32(right)))
+	}
 	vm.sp--
 	vm.pc++
 }
@@ -908,8 +1192,12 @@ type _bnot struct{}
 var bnot _bnot
 // bnot指令执行
 func (_bnot) exec(vm *vm) {
-	op := toInt32(vm.stack[vm.sp-1])
-	vm.stack[vm.sp-1] = intToValue(int64(^op))
+	op := toNumeric(vm.stack[vm.sp-1])
+	if b, ok := op.(*valueBigInt); ok {
+		vm.stack[vm.sp-1] = (*valueBigInt)(new(big.Int).Not((*big.Int)(b)))
+	} else {
+		vm.stack[vm.sp-1] = intToValue(int64(^toInt32(op)))
+	}
 	vm.pc++
 }
 
@@ -918,9 +1206,13 @@ type _sal struct{}
 var sal _sal
 // sal指令执行
 func (_sal) exec(vm *vm) {
-	left := toInt32(vm.stack[vm.sp-2])
-	right := toUInt32(vm.stack[vm.sp-1])
-	vm.stack[vm.sp-2] = intToValue(int64(left << (right & 0x1F)))
+	left := toNumeric(vm.stack[vm.sp-2])
+	right := toNumeric(vm.stack[vm.sp-1])
+	if x, y, ok := bigIntOperands(left, right); ok {
+		vm.stack[vm.sp-2] = vm.r.bigIntShift(x, y, true)
+	} else {
+		vm.stack[vm.sp-2] = intToValue(int64(toInt32(left) << (toUInt32(right) & 0x1F)))
+	}
 	vm.sp--
 	vm.pc++
 }
@@ -930,9 +1222,13 @@ type _sar struct{}
 var sar _sar
 // sar指令执行
 func (_sar) exec(vm *vm) {
-	left := toInt32(vm.stack[vm.sp-2])
-	right := toUInt32(vm.stack[vm.sp-1])
-	vm.stack[vm.sp-2] = intToValue(int64(left >> (right & 0x1F)))
+	left := toNumeric(vm.stack[vm.sp-2])
+	right := toNumeric(vm.stack[vm.sp-1])
+	if x, y, ok := bigIntOperands(left, right); ok {
+		vm.stack[vm.sp-2] = vm.r.bigIntShift(x, y, false)
+	} else {
+		vm.stack[vm.sp-2] = intToValue(int64(toInt32(left) >> (toUInt32(right) & 0x1F)))
+	}
 	vm.sp--
 	vm.pc++
 }
@@ -942,9 +1238,12 @@ type _shr struct{}
 var shr _shr
 // shr指令执行
 func (_shr) exec(vm *vm) {
-	left := toUInt32(vm.stack[vm.sp-2])
-	right := toUInt32(vm.stack[vm.sp-1])
-	vm.stack[vm.sp-2] = intToValue(int64(left >> (right & 0x1F)))
+	left := toNumeric(vm.stack[vm.sp-2])
+	right := toNumeric(vm.stack[vm.sp-1])
+	if _, _, ok := bigIntOperands(left, right); ok {
+		panic(typeError("BigInts have no unsigned right shift, use >> instead"))
+	}
+	vm.stack[vm.sp-2] = intToValue(int64(toUInt32(left) >> (toUInt32(right) & 0x1F)))
 	vm.sp--
 	vm.pc++
 }
@@ -1003,7 +1302,10 @@ var deleteElem _deleteElem
 func (_deleteElem) exec(vm *vm) {
 	obj := vm.r.toObject(vm.stack[vm.sp-2])
 	propName := vm.stack[vm.sp-1]
-	if !obj.self.hasProperty(propName) || obj.self.delete(propName, false) {
+	// proxy只调用deleteProperty trap，结果由trap决定
+	if _, ok := obj.self.(*Proxy); ok {
+		vm.stack[vm.sp-2] = vm.r.toBoolean(obj.self.delete(propName, false))
+	} else if !obj.self.hasProperty(propName) || obj.self.delete(propName, false) {
 		vm.stack[vm.sp-2] = valueTrue
 	} else {
 		vm.stack[vm.sp-2] = valueFalse
@@ -1029,7 +1331,9 @@ type deleteProp string
 // deleteProp指令执行
 func (d deleteProp) exec(vm *vm) {
 	obj := vm.r.toObject(vm.stack[vm.sp-1])
-	if !obj.self.hasPropertyStr(string(d)) || obj.self.deleteStr(string(d), false) {
+	if _, ok := obj.self.(*Proxy); ok {
+		vm.stack[vm.sp-1] = vm.r.toBoolean(obj.self.deleteStr(string(d), false))
+	} else if !obj.self.hasPropertyStr(string(d)) || obj.self.deleteStr(string(d), false) {
 		vm.stack[vm.sp-1] = valueTrue
 	} else {
 		vm.stack[vm.sp-1] = valueFalse
@@ -1125,6 +1429,70 @@ func (s setPropSetter) exec(vm *vm) {
 	vm.pc++
 }
 
+// 计算属性名的对象属性，栈上依次为对象、属性名和值
+type _setElem1 struct{}
+
+var setElem1 _setElem1
+// setElem1指令执行
+func (_setElem1) exec(vm *vm) {
+	obj := vm.r.toObject(vm.stack[vm.sp-3])
+	descr := propertyDescr{
+		Value:        vm.stack[vm.sp-1],
+		Writable:     FLAG_TRUE,
+		Enumerable:   FLAG_TRUE,
+		Configurable: FLAG_TRUE,
+	}
+	obj.self.defineOwnProperty(vm.stack[vm.sp-2], descr, true)
+
+	vm.sp -= 2
+	vm.pc++
+}
+
+type _setElemGetter struct{}
+
+var setElemGetter _setElemGetter
+// setElemGetter指令执行
+func (_setElemGetter) exec(vm *vm) {
+	obj := vm.r.toObject(vm.stack[vm.sp-3])
+	descr := propertyDescr{
+		Getter:       vm.stack[vm.sp-1],
+		Configurable: FLAG_TRUE,
+		Enumerable:   FLAG_TRUE,
+	}
+	obj.self.defineOwnProperty(vm.stack[vm.sp-2], descr, false)
+
+	vm.sp -= 2
+	vm.pc++
+}
+
+type _setElemSetter struct{}
+
+var setElemSetter _setElemSetter
+// setElemSetter指令执行
+func (_setElemSetter) exec(vm *vm) {
+	obj := vm.r.toObject(vm.stack[vm.sp-3])
+	descr := propertyDescr{
+		Setter:       vm.stack[vm.sp-1],
+		Configurable: FLAG_TRUE,
+		Enumerable:   FLAG_TRUE,
+	}
+	obj.self.defineOwnProperty(vm.stack[vm.sp-2], descr, false)
+
+	vm.sp -= 2
+	vm.pc++
+}
+
+// 把栈顶的函数标记为对象字面量的方法，对象位于函数下方第n个位置
+type setHomeObject int32
+// setHomeObject指令执行
+func (s setHomeObject) exec(vm *vm) {
+	if f, ok := vm.stack[vm.sp-1].(*Object).self.(*funcObject); ok {
+		f.method = true
+		f.homeObject = vm.r.toObject(vm.stack[vm.sp-1-int(s)])
+	}
+	vm.pc++
+}
+
 type getProp string
 // getProp指令执行
 func (g getProp) exec(vm *vm) {
@@ -1273,11 +1641,11 @@ type newRegexp struct {
 	pattern regexpPattern
 	src     valueString
 
-	global, ignoreCase, multiline bool
+	flags regexpFlags
 }
 // newRegexp指令执行
 func (n *newRegexp) exec(vm *vm) {
-	vm.push(vm.r.newRegExpp(n.pattern, n.src, n.global, n.ignoreCase, n.multiline, vm.r.global.RegExpPrototype))
+	vm.push(vm.r.newRegExpp(n.pattern, n.src, n.flags, vm.r.global.RegExpPrototype))
 	vm.pc++
 }
 
@@ -1327,7 +1695,7 @@ func (s setVar) exec(vm *vm) {
 
 	if stash != nil {
 		stash.putByIdx(idx, v)
-	} else {
+	} else if !vm.putGlobalLex(name, v) {
 		vm.r.globalObject.self.putStr(name, v, false)
 	}
 
@@ -1351,14 +1719,17 @@ func (s resolveVar1) exec(vm *vm) {
 			}
 		} else {
 			if idx, exists := stash.names[name]; exists {
-				ref = &stashRef{
-					v: &stash.values[idx],
-				}
+				ref = stash.ref(idx, name, vm.r)
 				goto end
 			}
 		}
 	}
 
+	if idx, exists := vm.r.globalLex.names[name]; exists {
+		ref = vm.r.globalLex.ref(idx, name, vm.r)
+		goto end
+	}
+
 	ref = &objRef{
 		base: vm.r.globalObject.self,
 		name: name,
@@ -1388,6 +1759,11 @@ func (d deleteVar) exec(vm *vm) {
 		}
 	}
 
+	if _, exists := vm.r.globalLex.names[name]; exists {
+		ret = false
+		goto end
+	}
+
 	if vm.r.globalObject.self.hasPropertyStr(name) {
 		ret = vm.r.globalObject.self.deleteStr(name, false)
 	}
@@ -1406,7 +1782,9 @@ type deleteGlobal string
 func (d deleteGlobal) exec(vm *vm) {
 	name := string(d)
 	var ret bool
-	if vm.r.globalObject.self.hasPropertyStr(name) {
+	if _, exists := vm.r.globalLex.names[name]; exists {
+		ret = false
+	} else if vm.r.globalObject.self.hasPropertyStr(name) {
 		ret = vm.r.globalObject.self.deleteStr(name, false)
 	} else {
 		ret = true
@@ -1436,14 +1814,17 @@ func (s resolveVar1Strict) exec(vm *vm) {
 			}
 		} else {
 			if idx, exists := stash.names[name]; exists {
-				ref = &stashRef{
-					v: &stash.values[idx],
-				}
+				ref = stash.ref(idx, name, vm.r)
 				goto end
 			}
 		}
 	}
 
+	if idx, exists := vm.r.globalLex.names[name]; exists {
+		ref = vm.r.globalLex.ref(idx, name, vm.r)
+		goto end
+	}
+
 	if vm.r.globalObject.self.hasPropertyStr(name) {
 		ref = &objRef{
 			base:   vm.r.globalObject.self,
@@ -1468,7 +1849,9 @@ type setGlobal string
 func (s setGlobal) exec(vm *vm) {
 	v := vm.peek()
 
-	vm.r.globalObject.self.putStr(string(s), v, false)
+	if !vm.putGlobalLex(string(s), v) {
+		vm.r.globalObject.self.putStr(string(s), v, false)
+	}
 	vm.pc++
 }
 
@@ -1478,11 +1861,13 @@ func (s setGlobalStrict) exec(vm *vm) {
 	v := vm.peek()
 
 	name := string(s)
-	o := vm.r.globalObject.self
-	if o.hasOwnPropertyStr(name) {
-		o.putStr(name, v, true)
-	} else {
-		vm.r.throwReferenceError(name)
+	if !vm.putGlobalLex(name, v) {
+		o := vm.r.globalObject.self
+		if o.hasOwnPropertyStr(name) {
+			o.putStr(name, v, true)
+		} else {
+			vm.r.throwReferenceError(name)
+		}
 	}
 	vm.pc++
 }
@@ -1514,13 +1899,22 @@ func (g getVar) exec(vm *vm) {
 	name := g.name
 	for i := 0; i < level; i++ {
 		if v, found := stash.getByName(name, vm); found {
+			if v == nil {
+				vm.r.throwUninitializedError(name)
+			}
 			vm.push(v)
 			goto end
 		}
 		stash = stash.outer
 	}
 	if stash != nil {
-		vm.push(stash.getByIdx(idx))
+		v := stash.getByIdx(idx)
+		if v == nil {
+			vm.r.throwUninitializedError(name)
+		}
+		vm.push(v)
+	} else if v, found := vm.getGlobalLex(name); found {
+		vm.push(v)
 	} else {
 		v := vm.r.globalObject.self.getStr(name)
 		if v == nil {
@@ -1559,9 +1953,7 @@ func (r resolveVar) exec(vm *vm) {
 			}
 		} else {
 			if idx, exists := stash.names[r.name]; exists {
-				ref = &stashRef{
-					v: &stash.values[idx],
-				}
+				ref = stash.ref(idx, r.name, vm.r)
 				goto end
 			}
 		}
@@ -1569,9 +1961,11 @@ func (r resolveVar) exec(vm *vm) {
 	}
 
 	if stash != nil {
-		ref = &stashRef{
-			v: &stash.values[idx],
-		}
+		ref = stash.ref(idx, r.name, vm.r)
+		goto end
+	}
+	if idx, exists := vm.r.globalLex.names[r.name]; exists {
+		ref = vm.r.globalLex.ref(idx, r.name, vm.r)
 		goto end
 	} /*else {
 		if vm.r.globalObject.self.hasProperty(nameVal) {
@@ -1628,10 +2022,16 @@ func (n getVar1) exec(vm *vm) {
 	var val Value
 	for stash := vm.stash; stash != nil; stash = stash.outer {
 		if v, exists := stash.getByName(name, vm); exists {
+			if v == nil {
+				vm.r.throwUninitializedError(name)
+			}
 			val = v
 			break
 		}
 	}
+	if val == nil {
+		val, _ = vm.getGlobalLex(name)
+	}
 	if val == nil {
 		val = vm.r.globalObject.self.getStr(name)
 		if val == nil {
@@ -1649,10 +2049,16 @@ func (n getVar1Callee) exec(vm *vm) {
 	var val Value
 	for stash := vm.stash; stash != nil; stash = stash.outer {
 		if v, exists := stash.getByName(name, vm); exists {
+			if v == nil {
+				vm.r.throwUninitializedError(name)
+			}
 			val = v
 			break
 		}
 	}
+	if val == nil {
+		val, _ = vm.getGlobalLex(name)
+	}
 	if val == nil {
 		val = vm.r.globalObject.self.getStr(name)
 		if val == nil {
@@ -1663,6 +2069,28 @@ func (n getVar1Callee) exec(vm *vm) {
 	vm.pc++
 }
 
+// 在顶层的let/const绑定中查找name
+func (vm *vm) getGlobalLex(name string) (Value, bool) {
+	lex := vm.r.globalLex
+	if idx, exists := lex.names[name]; exists {
+		v := lex.values[idx]
+		if v == nil {
+			vm.r.throwUninitializedError(name)
+		}
+		return v, true
+	}
+	return nil, false
+}
+// 给顶层的let/const绑定赋值，不存在时返回false
+func (vm *vm) putGlobalLex(name string, v Value) bool {
+	lex := vm.r.globalLex
+	if idx, exists := lex.names[name]; exists {
+		lex.ref(idx, name, vm.r).set(v)
+		return true
+	}
+	return false
+}
+
 type _pop struct{}
 
 var pop _pop
@@ -1739,18 +2167,30 @@ func (numargs call) exec(vm *vm) {
 repeat:
 	switch f := obj.self.(type) {
 	case *funcObject:
+		if f.classCtor {
+			vm.r.throwClassCallError(f)
+		}
 		vm.pc++
 		vm.pushCtx()
 		vm.args = n
+		vm.newTarget = nil
 		vm.prg = f.prg
 		vm.stash = f.stash
 		vm.pc = 0
 		vm.stack[vm.sp-n-1], vm.stack[vm.sp-n-2] = vm.stack[vm.sp-n-2], vm.stack[vm.sp-n-1]
+		if f.arrow {
+			vm.stack[vm.sp-n-1] = f.this
+		}
 		return
 	case *nativeFuncObject:
 		vm._nativeCall(f, n)
 	case *boundFuncObject:
 		vm._nativeCall(&f.nativeFuncObject, n)
+	case *Proxy:
+		if _, ok := f.assertCallable(); !ok {
+			vm.r.typeErrorResult(true, "Not a function: %s", obj.ToString())
+		}
+		vm._proxyCall(f, n)
 	case *lazyObject:
 		obj.self = f.create(obj)
 		goto repeat
@@ -1758,6 +2198,20 @@ repeat:
 		vm.r.typeErrorResult(true, "Not a function: %s", obj.ToString())
 	}
 }
+// 调用可调用的代理对象
+func (vm *vm) _proxyCall(f *Proxy, n int) {
+	vm.pushCtx()
+	vm.prg = nil
+	vm.funcName = ""
+	ret := f.apply(FunctionCall{
+		Arguments: vm.stack[vm.sp-n : vm.sp],
+		This:      vm.stack[vm.sp-n-2],
+	})
+	vm.stack[vm.sp-n-2] = nilSafe(ret)
+	vm.popCtx()
+	vm.sp -= n + 1
+	vm.pc++
+}
 
 func (vm *vm) _nativeCall(f *nativeFuncObject, n int) {
 	if f.f != nil {
@@ -1807,9 +2261,11 @@ func (e enterFunc) exec(vm *vm) {
 
 	vm.newStash()
 	offset := vm.args - int(e)
+	vm.r.allocate(int(e) * sizeofValue)
 	vm.stash.values = make([]Value, e)
 	if offset > 0 {
 		copy(vm.stash.values, vm.stack[vm.sp-vm.args:])
+		vm.r.allocate(offset * sizeofValue)
 		vm.stash.extraArgs = make([]Value, offset)
 		copy(vm.stash.extraArgs, vm.stack[vm.sp-offset:])
 	} else {
@@ -1883,10 +2339,12 @@ func (_retStashless) exec(vm *vm) {
 }
 
 type newFunc struct {
-	prg    *Program
-	name   string
-	length uint32
-	strict bool
+	prg       *Program
+	name      string
+	length    uint32
+	strict    bool
+	generator bool
+	async     bool
 
 	srcStart, srcEnd uint32
 }
@@ -1896,15 +2354,335 @@ func (n *newFunc) exec(vm *vm) {
 	obj.prg = n.prg
 	obj.stash = vm.stash
 	obj.src = n.prg.src.src[n.srcStart:n.srcEnd]
+	if n.generator {
+		obj.generator = true
+		obj.prototype = vm.r.global.GeneratorFunctionPrototype
+	} else if n.async {
+		obj.async = true
+		obj.prototype = vm.r.global.AsyncFunctionPrototype
+	}
+	vm.push(obj.val)
+	vm.pc++
+}
+
+type _genStart struct{}
+
+var genStart _genStart
+// genStart指令执行，生成器函数在初始化参数后暂停，返回生成器对象
+func (_genStart) exec(vm *vm) {
+	g := vm.r.newGenerator(vm.callee())
+	g.iterBase = len(vm.iterStack)
+	g.refBase = len(vm.refStack)
+	g.tryBase = len(vm.tryStack)
+	g.suspend(vm)
+	vm.push(g.val)
+	if vm.pc < 0 {
+		vm.halt = true
+	}
+}
+
+type _asyncStart struct{}
+
+var asyncStart _asyncStart
+// asyncStart指令执行，async函数在初始化参数后暂停，然后立即恢复执行到第一个await，返回promise
+func (_asyncStart) exec(vm *vm) {
+	a := vm.r.newAsyncFunction()
+	g := a.gen
+	g.iterBase = len(vm.iterStack)
+	g.refBase = len(vm.refStack)
+	g.tryBase = len(vm.tryStack)
+	g.suspend(vm)
+	a.step(resumeNext, _undefined, nil)
+	vm.push(a.promise.val)
+	if vm.pc < 0 {
+		vm.halt = true
+	}
+}
+
+type _await struct{}
+
+var await _await
+// await指令执行，暂停async函数直到栈顶的值被决议
+func (_await) exec(vm *vm) {
+	g := vm.gen
+	g.yielded = vm.pop()
+	g.suspend(vm)
+	vm.halt = true
+	vm.suspended = true
+}
+
+type _yield struct{}
+
+var yield _yield
+// yield指令执行，暂停生成器并把栈顶的值交给调用next()的代码
+func (_yield) exec(vm *vm) {
+	g := vm.gen
+	g.yielded = vm.pop()
+	g.suspend(vm)
+	vm.halt = true
+	vm.suspended = true
+}
+
+type _yieldDelegate struct{}
+
+var yieldDelegate _yieldDelegate
+// yieldDelegate指令执行，yield*把栈顶对象的迭代器的结果依次交出，直到迭代结束
+func (_yieldDelegate) exec(vm *vm) {
+	g := vm.gen
+	iter := vm.r.getIterator(vm.pop())
+	res, ok := vm.r.invoke(iter, "next", _undefined).(*Object)
+	if !ok {
+		vm.r.typeErrorResult(true, "Iterator result is not an object")
+	}
+	if res.self.getStr("done").ToBoolean() {
+		v := res.self.getStr("value")
+		if v == nil {
+			v = _undefined
+		}
+		vm.push(v)
+		vm.pc++
+		return
+	}
+	g.delegate = iter
+	g.yielded = res
+	g.suspend(vm)
+	vm.halt = true
+	vm.suspended = true
+}
+
+type newArrowFunc struct {
+	newFunc
+	// 在全局代码中创建，this为全局对象
+	globalThis bool
+}
+// newArrowFunc指令执行，箭头函数在创建时记录外层的this
+func (n *newArrowFunc) exec(vm *vm) {
+	obj := vm.r.newFunc(n.name, int(n.length), n.strict)
+	obj.prg = n.prg
+	obj.stash = vm.stash
+	obj.src = n.prg.src.src[n.srcStart:n.srcEnd]
+	obj.arrow = true
+	if n.async {
+		obj.async = true
+		obj.prototype = vm.r.global.AsyncFunctionPrototype
+	}
+	if n.globalThis {
+		obj.this = vm.r.globalObject
+	} else {
+		obj.this = vm.stack[vm.sb]
+		// 方法中的箭头函数使用方法的super
+		if f := vm.callee(); f != nil {
+			obj.homeObject = f.homeObject
+		}
+	}
+	vm.push(obj.val)
+	vm.pc++
+}
+
+// 当前正在执行的函数，不在函数中时返回nil
+func (vm *vm) callee() *funcObject {
+	if vm.sb > 0 {
+		if obj, ok := vm.stack[vm.sb-1].(*Object); ok {
+			if f, ok := obj.self.(*funcObject); ok {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+type newClass struct {
+	newFunc
+	derived bool
+}
+// newClass指令执行，创建类的构造函数和原型对象。有extends时父类在栈顶
+func (n *newClass) exec(vm *vm) {
+	protoParent := vm.r.global.ObjectPrototype
+	ctorParent := vm.r.global.FunctionPrototype
+	if n.derived {
+		parent := vm.pop()
+		if parent == _null {
+			protoParent = nil
+		} else {
+			obj, ok := parent.(*Object)
+			if !ok || !vm.r.isConstructor(obj) {
+				vm.r.typeErrorResult(true, "Class extends value %s is not a constructor or null", parent.String())
+			}
+			switch p := obj.self.getStr("prototype").(type) {
+			case *Object:
+				protoParent = p
+			case valueNull:
+				protoParent = nil
+			default:
+				vm.r.typeErrorResult(true, "Class extends value does not have valid prototype property %s", p.String())
+			}
+			ctorParent = obj
+		}
+	}
+
+	obj := vm.r.newFunc(n.name, int(n.length), n.strict)
+	obj.prg = n.prg
+	obj.stash = vm.stash
+	obj.src = n.prg.src.src[n.srcStart:n.srcEnd]
+	obj.classCtor = true
+	obj.derived = n.derived
+	obj.prototype = ctorParent
+
+	proto := vm.r.newBaseObject(protoParent, classObject)
+	proto._putProp("constructor", obj.val, true, false, true)
+	obj._putProp("prototype", proto.val, false, false, false)
+	obj.homeObject = proto.val
+
 	vm.push(obj.val)
+	vm.push(proto.val)
+	vm.pc++
+}
+
+// 定义类的方法，栈上依次为构造函数、原型对象和方法
+type defineMethod struct {
+	name   string
+	kind   string // "method"、"get"或"set"
+	static bool
+	// 计算方法名在方法下面的栈中
+	computed bool
+}
+// defineMethod指令执行，方法是不可枚举的
+func (d *defineMethod) exec(vm *vm) {
+	sp := vm.sp
+	var key Value
+	if d.computed {
+		key = toPropertyKey(vm.stack[sp-2])
+		sp--
+	} else {
+		key = newStringValue(d.name)
+	}
+	var target *Object
+	if d.static {
+		target = vm.r.toObject(vm.stack[sp-3])
+		if _, ok := key.(*valueSymbol); !ok && d.computed && key.String() == "prototype" {
+			panic(vm.r.NewTypeError("Classes may not have a static property named 'prototype'"))
+		}
+	} else {
+		target = vm.r.toObject(vm.stack[sp-2])
+	}
+	val := vm.stack[vm.sp-1]
+	if f, ok := val.(*Object).self.(*funcObject); ok {
+		f.method = true
+		f.homeObject = target
+	}
+
+	descr := propertyDescr{
+		Configurable: FLAG_TRUE,
+		Enumerable:   FLAG_FALSE,
+	}
+	switch d.kind {
+	case "get":
+		descr.Getter = val
+	case "set":
+		descr.Setter = val
+	default:
+		descr.Value = val
+		descr.Writable = FLAG_TRUE
+	}
+	target.self.defineOwnProperty(key, descr, true)
+
+	vm.sp = sp - 1
+	vm.pc++
+}
+
+type _loadSuper struct{}
+
+var loadSuper _loadSuper
+// loadSuper指令执行，取得当前方法所属对象的原型
+func (_loadSuper) exec(vm *vm) {
+	var proto *Object
+	if f := vm.callee(); f != nil && f.homeObject != nil {
+		proto = f.homeObject.self.proto()
+	}
+	if proto == nil {
+		vm.push(_undefined)
+	} else {
+		vm.push(proto)
+	}
+	vm.pc++
+}
+
+type superCall uint32
+// superCall指令执行，调用父类的构造函数
+func (n superCall) exec(vm *vm) {
+	args := make([]Value, n)
+	copy(args, vm.stack[vm.sp-int(n):])
+	vm.sp -= int(n)
+	vm.push(vm.superConstruct(args))
+	vm.pc++
+}
+
+type _superCallAll struct{}
+
+var superCallAll _superCallAll
+// superCallAll指令执行，派生类的默认构造函数把所有参数传给父类。只用于不需要stash的函数，参数仍在栈上
+func (_superCallAll) exec(vm *vm) {
+	args := make([]Value, vm.args)
+	copy(args, vm.stack[vm.sb+1:])
+	vm.push(vm.superConstruct(args))
+	vm.pc++
+}
+// 在派生类的构造函数中调用父类的构造函数，创建的对象成为this
+func (vm *vm) superConstruct(args []Value) Value {
+	if vm.stack[vm.sb] != _undefined {
+		panic(vm.r.newError(vm.r.global.ReferenceError, "Super constructor may only be called once"))
+	}
+	newTarget := vm.newTarget
+	parent := vm.callee().proto()
+	if parent == nil || !vm.r.isConstructor(parent) {
+		vm.r.typeErrorResult(true, "Super constructor is not a constructor")
+	}
+	obj := vm.r.constructWith(parent, args, newTarget)
+	vm.stack[vm.sb] = obj
+	return obj
+}
+
+type _loadDerivedThis struct{}
+
+var loadDerivedThis _loadDerivedThis
+// loadDerivedThis指令执行，派生类构造函数中的this在super()之前不能访问
+func (_loadDerivedThis) exec(vm *vm) {
+	this := vm.stack[vm.sb]
+	if this == _undefined {
+		vm.r.throwThisBeforeSuperError()
+	}
+	vm.push(this)
+	vm.pc++
+}
+
+type _derivedResult struct{}
+
+var derivedResult _derivedResult
+// derivedResult指令执行，派生类构造函数的返回值只能是对象或undefined，undefined时返回this
+func (_derivedResult) exec(vm *vm) {
+	switch v := vm.stack[vm.sp-1]; v.(type) {
+	case *Object:
+	case valueUndefined:
+		this := vm.stack[vm.sb]
+		if this == _undefined {
+			vm.r.throwThisBeforeSuperError()
+		}
+		vm.stack[vm.sp-1] = this
+	default:
+		vm.r.typeErrorResult(true, "Derived constructors may only return object or undefined")
+	}
 	vm.pc++
 }
 
 type bindName string
 // bindName指令执行
 func (d bindName) exec(vm *vm) {
-	if vm.stash != nil {
-		vm.stash.createBinding(string(d))
+	stash := vm.stash
+	for stash != nil && stash.block {
+		stash = stash.outer
+	}
+	if stash != nil {
+		stash.createBinding(string(d))
 	} else {
 		vm.r.globalObject.self._putProp(string(d), _undefined, true, true, false)
 	}
@@ -1991,6 +2769,24 @@ func cmp(px, py Value) Value {
 		}
 	}
 
+	if xb, ok := px.(*valueBigInt); ok {
+		c, ok := compareBigInt((*big.Int)(xb), py)
+		if !ok {
+			return _undefined
+		}
+		ret = c < 0
+		goto end
+	}
+
+	if yb, ok := py.(*valueBigInt); ok {
+		c, ok := compareBigInt((*big.Int)(yb), px)
+		if !ok {
+			return _undefined
+		}
+		ret = c > 0
+		goto end
+	}
+
 	nx = px.ToFloat()
 	ny = py.ToFloat()
 
@@ -2146,7 +2942,7 @@ func (_op_instanceof) exec(vm *vm) {
 	left := vm.stack[vm.sp-2]
 	right := vm.r.toObject(vm.stack[vm.sp-1])
 
-	if right.self.hasInstance(left) {
+	if vm.r.instanceOf(left, right) {
 		vm.stack[vm.sp-2] = valueTrue
 	} else {
 		vm.stack[vm.sp-2] = valueFalse
@@ -2179,44 +2975,95 @@ type try struct {
 	finallyOffset int32
 	dynamic       bool
 }
+
+type tryState int
+
+const (
+	tryStateTry tryState = iota
+	tryStateCatch
+	tryStateFinally
+)
+
+// 正在执行的try语句，生成器暂停时保存起来，恢复时据此重新进入
+type tryFrame struct {
+	t     try
+	pc    int // try指令的位置
+	state tryState
+	// 需要在finally之后重新抛出的异常
+	ex *Exception
+	// finally执行完后继续执行的位置
+	finallyRet int
+	scope      tryScope
+}
 // try指令执行
 func (t try) exec(vm *vm) {
-	o := vm.pc
-	vm.pc++
-	ex := vm.runTry()
-	if ex != nil && t.catchOffset > 0 {
-		// run the catch block (in try)
-		vm.pc = o + int(t.catchOffset)
-		// TODO: if ex.val is an Error, set the stack property
-		if t.dynamic {
-			vm.newStash()
-			vm.stash.putByIdx(0, ex.val)
-		} else {
-			vm.push(ex.val)
+	f := &tryFrame{
+		t:  t,
+		pc: vm.pc,
+	}
+	vm.pc++
+	vm.tryStack = append(vm.tryStack, f)
+	vm.saveTryScope(&f.scope)
+	vm.runTryFrame(f, vm.run)
+}
+// 执行try语句的各个部分，run用于执行当前所在的部分(恢复生成器时从中间继续)
+func (vm *vm) runTryFrame(f *tryFrame, run func()) {
+	if f.state == tryStateTry {
+		f.ex = vm.tryIn(&f.scope, run)
+		if vm.suspended {
+			return
+		}
+		run = vm.run
+		if f.ex != nil && f.t.catchOffset > 0 {
+			// run the catch block (in try)
+			vm.pc = f.pc + int(f.t.catchOffset)
+			// TODO: if ex.val is an Error, set the stack property
+			if f.t.dynamic {
+				vm.newStash()
+				vm.stash.putByIdx(0, f.ex.val)
+			} else {
+				vm.push(f.ex.val)
+			}
+			f.state = tryStateCatch
+			vm.saveTryScope(&f.scope)
+		}
+	}
+	if f.state == tryStateCatch {
+		f.ex = vm.tryIn(&f.scope, run)
+		if vm.suspended {
+			return
 		}
-		ex = vm.runTry()
-		if t.dynamic {
+		run = vm.run
+		if f.t.dynamic {
 			vm.stash = vm.stash.outer
 		}
 	}
 
-	if t.finallyOffset > 0 {
-		pc := vm.pc
-		// Run finally
-		vm.pc = o + int(t.finallyOffset)
-		vm.run()
+	if f.t.finallyOffset > 0 {
+		if f.state != tryStateFinally {
+			f.finallyRet = vm.pc
+			// Run finally
+			vm.pc = f.pc + int(f.t.finallyOffset)
+			f.state = tryStateFinally
+		}
+		run()
+		if vm.suspended {
+			return
+		}
 		if vm.prg.code[vm.pc] == retFinally {
-			vm.pc = pc
+			vm.pc = f.finallyRet
 		} else {
 			// break or continue out of finally, dropping exception
-			ex = nil
+			f.ex = nil
 		}
 	}
 
+	vm.tryStack[len(vm.tryStack)-1] = nil
+	vm.tryStack = vm.tryStack[:len(vm.tryStack)-1]
 	vm.halt = false
 
-	if ex != nil {
-		panic(ex)
+	if f.ex != nil {
+		panic(f.ex)
 	}
 }
 
@@ -2234,6 +3081,8 @@ func (varName enterCatch) exec(vm *vm) {
 	vm.stash.names = map[string]uint32{
 		string(varName): 0,
 	}
+	// catch参数的作用域与let相同，eval中的var不绑定到这里
+	vm.stash.block = true
 	vm.pc++
 }
 
@@ -2260,6 +3109,14 @@ repeat:
 		vm._nativeNew(f, int(n))
 	case *boundFuncObject:
 		vm._nativeNew(&f.nativeFuncObject, int(n))
+	case *Proxy:
+		if !vm.r.isConstructor(f.target) {
+			vm.r.typeErrorResult(true, "Not a constructor")
+		}
+		args := make([]Value, n)
+		copy(args, vm.stack[vm.sp-int(n):])
+		vm.sp -= int(n)
+		vm.stack[vm.sp-1] = f.construct(args, obj)
 	case *lazyObject:
 		obj.self = f.create(obj)
 		goto repeat
@@ -2297,6 +3154,12 @@ func (_typeof) exec(vm *vm) {
 		switch s := v.self.(type) {
 		case *funcObject, *nativeFuncObject, *boundFuncObject:
 			r = stringFunction
+		case *Proxy:
+			if _, ok := s.assertCallable(); ok {
+				r = stringFunction
+			} else {
+				r = stringObjectC
+			}
 		case *lazyObject:
 			v.self = s.create(v)
 			goto repeat
@@ -2309,6 +3172,10 @@ func (_typeof) exec(vm *vm) {
 		r = stringString
 	case valueInt, valueFloat:
 		r = stringNumber
+	case *valueSymbol:
+		r = stringSymbol
+	case *valueBigInt:
+		r = stringBigInt
 	default:
 		panic(fmt.Errorf("Unknown type: %T", v))
 	}
@@ -2350,10 +3217,30 @@ func (formalArgs createArgs) exec(vm *vm) {
 	}
 
 	args._putProp("callee", vm.stack[vm.sb-1], true, false, true)
+	args._putSym(symIterator, vm.r.global.arrayValues, true, false, true)
 	vm.push(v)
 	vm.pc++
 }
 
+type createRest uint32
+// createRest指令执行，把多于形参个数的实参收集到数组中，作为剩余参数
+func (formalArgs createRest) exec(vm *vm) {
+	vm.push(vm.r.newArrayValues(append([]Value(nil), vm.stash.extraArgs...)))
+	vm.pc++
+}
+
+type createRestStashless uint32
+// createRestStashless指令执行，没有stash的函数的实参保存在栈中
+func (formalArgs createRestStashless) exec(vm *vm) {
+	var values []Value
+	if n := vm.args - int(formalArgs); n > 0 {
+		values = make([]Value, n)
+		copy(values, vm.stack[vm.sb+1+int(formalArgs):])
+	}
+	vm.push(vm.r.newArrayValues(values))
+	vm.pc++
+}
+
 type createArgsStrict uint32
 // createArgsStrict指令执行
 func (formalArgs createArgsStrict) exec(vm *vm) {
@@ -2376,6 +3263,7 @@ func (formalArgs createArgsStrict) exec(vm *vm) {
 	args._putProp("length", intToValue(int64(vm.args)), true, false, true)
 	args._put("callee", vm.r.global.throwerProperty)
 	args._put("caller", vm.r.global.throwerProperty)
+	args._putSym(symIterator, vm.r.global.arrayValues, true, false, true)
 	vm.push(args.val)
 	vm.pc++
 }
@@ -2400,6 +3288,134 @@ func (_leaveWith) exec(vm *vm) {
 	vm.pc++
 }
 
+type enterBlock struct {
+	names  map[string]uint32
+	consts map[string]bool
+	size   uint32
+}
+// enterBlock指令执行，为let/const创建块级stash，绑定的值在初始化之前为nil
+func (e *enterBlock) exec(vm *vm) {
+	vm.newStash()
+	vm.stash.block = true
+	vm.stash.names = e.names
+	vm.stash.consts = e.consts
+	vm.r.allocate(int(e.size) * sizeofValue)
+	vm.stash.values = make([]Value, e.size)
+	vm.pc++
+}
+
+type setBlockFuncVar string
+// setBlockFuncVar指令执行，把栈顶的值写入最近的函数作用域或全局对象中的同名var，用于块中的函数声明
+func (s setBlockFuncVar) exec(vm *vm) {
+	name := string(s)
+	vm.sp--
+	v := vm.stack[vm.sp]
+	stash := vm.stash
+	for stash != nil && (stash.block || stash.obj != nil) {
+		stash = stash.outer
+	}
+	if stash != nil {
+		if idx, exists := stash.names[name]; exists {
+			stash.values[idx] = v
+		}
+	} else {
+		vm.r.globalObject.self.putStr(name, v, false)
+	}
+	vm.pc++
+}
+
+type _exitBlock struct{}
+
+var exitBlock _exitBlock
+// exitBlock指令执行
+func (_exitBlock) exec(vm *vm) {
+	vm.stash = vm.stash.outer
+	vm.pc++
+}
+
+type _copyStash struct{}
+
+var copyStash _copyStash
+// copyStash指令执行，for循环每次迭代使用新的let绑定
+func (_copyStash) exec(vm *vm) {
+	s := vm.stash
+	vm.r.allocate(sizeofStash + len(s.values)*sizeofValue)
+	values := make([]Value, len(s.values))
+	copy(values, s.values)
+	vm.stash = &stash{
+		values: values,
+		names:  s.names,
+		consts: s.consts,
+		block:  true,
+		outer:  s.outer,
+	}
+	vm.stashAllocs++
+	vm.pc++
+}
+
+type checkInit string
+// checkInit指令执行，检查栈顶的let/const绑定是否已经初始化
+func (c checkInit) exec(vm *vm) {
+	if vm.stack[vm.sp-1] == nil {
+		vm.r.throwUninitializedError(string(c))
+	}
+	vm.pc++
+}
+
+type _throwConstAssign struct{}
+
+var throwConstAssign _throwConstAssign
+// throwConstAssign指令执行
+func (_throwConstAssign) exec(vm *vm) {
+	vm.r.throwConstAssignError()
+}
+
+type bindGlobalLex struct {
+	vars   []string
+	lets   []string
+	consts []string
+}
+// bindGlobalLex指令执行，声明脚本顶层的let/const，并检查与已有声明的冲突
+func (b *bindGlobalLex) exec(vm *vm) {
+	lex := vm.r.globalLex
+	for _, name := range b.vars {
+		if _, exists := lex.names[name]; exists {
+			vm.r.throwRedeclarationError(name)
+		}
+	}
+	check := func(names []string) {
+		for _, name := range names {
+			if _, exists := lex.names[name]; exists {
+				vm.r.throwRedeclarationError(name)
+			}
+			if p, ok := vm.r.globalObject.self.getOwnProp(name).(*valueProperty); ok && !p.configurable {
+				vm.r.throwRedeclarationError(name)
+			}
+		}
+	}
+	check(b.lets)
+	check(b.consts)
+	for _, name := range b.lets {
+		lex.names[name] = uint32(len(lex.values))
+		lex.values = append(lex.values, nil)
+	}
+	for _, name := range b.consts {
+		lex.names[name] = uint32(len(lex.values))
+		lex.values = append(lex.values, nil)
+		lex.consts[name] = true
+	}
+	vm.pc++
+}
+
+type initGlobalLex string
+// initGlobalLex指令执行
+func (i initGlobalLex) exec(vm *vm) {
+	lex := vm.r.globalLex
+	lex.values[lex.names[string(i)]] = vm.stack[vm.sp-1]
+	vm.sp--
+	vm.pc++
+}
+
 func emptyIter() (propIterItem, iterNextFunc) {
 	return propIterItem{}, nil
 }
@@ -2449,7 +3465,175 @@ var enumPop _enumPop
 // enumPop指令执行
 func (_enumPop) exec(vm *vm) {
 	l := len(vm.iterStack) - 1
+	if iter := vm.iterStack[l].iter; iter != nil {
+		// 提前退出for-of，关闭迭代器
+		vm.r.iteratorClose(iter)
+	}
 	vm.iterStack[l] = iterStackItem{}
 	vm.iterStack = vm.iterStack[:l]
 	vm.pc++
 }
+
+type _iterate struct{}
+
+var iterate _iterate
+// iterate指令执行，取出for-of的迭代器
+func (_iterate) exec(vm *vm) {
+	iter := vm.r.getIterator(vm.stack[vm.sp-1])
+	vm.iterStack = append(vm.iterStack, iterStackItem{iter: iter})
+	vm.sp--
+	vm.pc++
+}
+
+type iterNext int32
+// iterNext指令执行，迭代结束时弹出迭代器并跳转
+func (jmp iterNext) exec(vm *vm) {
+	l := len(vm.iterStack) - 1
+	iter := vm.iterStack[l].iter
+	// next()抛出异常时不关闭迭代器
+	vm.iterStack[l].iter = nil
+	value, done := vm.r.iteratorNext(iter)
+	vm.iterStack[l].iter = iter
+	if done {
+		vm.iterStack[l] = iterStackItem{}
+		vm.iterStack = vm.iterStack[:l]
+		vm.pc += int(jmp)
+		return
+	}
+	vm.iterStack[l].val = value
+	vm.pc++
+}
+
+type _destructNext struct{}
+
+var destructNext _destructNext
+// destructNext指令执行，数组解构时把迭代器的下一个值压栈，迭代结束后压入undefined
+func (_destructNext) exec(vm *vm) {
+	l := len(vm.iterStack) - 1
+	var value Value = _undefined
+	if iter := vm.iterStack[l].iter; iter != nil {
+		// 迭代已经结束或next()抛出异常时，不需要再关闭迭代器
+		vm.iterStack[l].iter = nil
+		if v, done := vm.r.iteratorNext(iter); !done {
+			vm.iterStack[l].iter = iter
+			value = v
+		}
+	}
+	vm.push(value)
+	vm.pc++
+}
+
+type _destructRest struct{}
+
+var destructRest _destructRest
+// destructRest指令执行，把迭代器剩余的值收集到数组中并压栈
+func (_destructRest) exec(vm *vm) {
+	l := len(vm.iterStack) - 1
+	var values []Value
+	if iter := vm.iterStack[l].iter; iter != nil {
+		vm.iterStack[l].iter = nil
+		for {
+			v, done := vm.r.iteratorNext(iter)
+			if done {
+				break
+			}
+			values = append(values, v)
+		}
+	}
+	vm.push(vm.r.newArrayValues(values))
+	vm.pc++
+}
+
+type _checkObjectCoercible struct{}
+
+var checkObjectCoercible _checkObjectCoercible
+// checkObjectCoercible指令执行，对象解构的源值不能是null或undefined
+func (_checkObjectCoercible) exec(vm *vm) {
+	switch v := vm.stack[vm.sp-1]; v {
+	case _undefined, _null:
+		panic(vm.r.NewTypeError("Cannot destructure '%s' as it is %s.", v.String(), v.String()))
+	}
+	vm.pc++
+}
+
+type jdef int32
+// jdef指令执行，栈顶的值不是undefined时跳转，否则弹出它，用于解构的默认值
+func (j jdef) exec(vm *vm) {
+	if vm.stack[vm.sp-1] != _undefined {
+		vm.pc += int(j)
+	} else {
+		vm.sp--
+		vm.pc++
+	}
+}
+
+type _pushArrayItem struct{}
+
+var pushArrayItem _pushArrayItem
+// pushArrayItem指令执行，把栈顶的值追加到数组
+func (_pushArrayItem) exec(vm *vm) {
+	arr := vm.stack[vm.sp-2].(*Object).self.(*arrayObject)
+	arr.appendValue(vm.stack[vm.sp-1])
+	vm.sp--
+	vm.pc++
+}
+
+type _pushArraySpread struct{}
+
+var pushArraySpread _pushArraySpread
+// pushArraySpread指令执行，把栈顶可迭代对象的所有值追加到数组
+func (_pushArraySpread) exec(vm *vm) {
+	arr := vm.stack[vm.sp-2].(*Object).self.(*arrayObject)
+	vm.r.iterate(vm.stack[vm.sp-1], func(v Value) {
+		arr.appendValue(v)
+	})
+	vm.sp--
+	vm.pc++
+}
+// 把栈顶的参数数组展开到栈上，返回参数个数
+func (vm *vm) expandArgs() int {
+	arr := vm.stack[vm.sp-1].(*Object).self.(*arrayObject)
+	vm.sp--
+	n := int(arr.length)
+	vm.stack.expand(vm.sp + n - 1)
+	copy(vm.stack[vm.sp:], arr.values[:n])
+	vm.sp += n
+	return n
+}
+
+type _callSpread struct{}
+
+var callSpread _callSpread
+// callSpread指令执行，f(...args)
+func (_callSpread) exec(vm *vm) {
+	call(vm.expandArgs()).exec(vm)
+}
+
+type _newSpread struct{}
+
+var newSpread _newSpread
+// newSpread指令执行，new F(...args)
+func (_newSpread) exec(vm *vm) {
+	_new(vm.expandArgs()).exec(vm)
+}
+
+type _superCallSpread struct{}
+
+var superCallSpread _superCallSpread
+// superCallSpread指令执行，super(...args)
+func (_superCallSpread) exec(vm *vm) {
+	superCall(vm.expandArgs()).exec(vm)
+}
+
+type _importDynamic struct{}
+
+var importDynamic _importDynamic
+// importDynamic指令执行，import(specifier)，栈顶的模块名称替换为promise
+func (_importDynamic) exec(vm *vm) {
+	referrer := ""
+	if vm.prg.src != nil {
+		referrer = vm.prg.src.name
+	}
+	vm.stack[vm.sp-1] = vm.r.importModuleDynamically(referrer, vm.stack[vm.sp-1])
+	vm.pc++
+}